@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -30,10 +31,18 @@ type Client interface {
 type RequestOptions struct {
 	Body   io.Reader
 	Header http.Header
+
+	// Context bounds the lifetime of the request, if provided
+	Context context.Context
+
+	// Idempotent marks a request which only reads data as safe to retry, even though it is not made
+	// with an idempotent method
+	Idempotent bool
 }
 
 type basicAPIClient struct {
-	baseURL string
+	baseURL   string
+	transport http.RoundTripper
 }
 
 const (
@@ -53,20 +62,31 @@ func (apiClient *basicAPIClient) ExecuteRequest(method, path string, options Req
 		return nil, err
 	}
 
+	if options.Context != nil {
+		req = req.WithContext(options.Context)
+	}
+
 	req.Header = options.Header
 	if req.Header == nil {
 		req.Header = http.Header{}
 	}
 	req.Header.Set(RealmRequestOriginHeader, RealmCLIHeaderValue)
 
-	client := &http.Client{}
+	client := &http.Client{Transport: apiClient.transport}
 	return client.Do(req)
 }
 
 // NewClient returns a new Client
 func NewClient(baseURL string) Client {
+	return NewClientWithTransport(baseURL, nil)
+}
+
+// NewClientWithTransport returns a new Client which makes its requests with the transport given, such as one
+// returned by NewTransport, or with http.DefaultTransport if it is nil
+func NewClientWithTransport(baseURL string, transport http.RoundTripper) Client {
 	return &basicAPIClient{
-		baseURL: baseURL,
+		baseURL:   baseURL,
+		transport: transport,
 	}
 }
 
@@ -134,6 +154,8 @@ func (ac *AuthClient) ExecuteRequest(method, path string, options RequestOptions
 			Header: http.Header{
 				"Authorization": []string{"Bearer " + authResponse.AccessToken},
 			},
+			Context:    options.Context,
+			Idempotent: options.Idempotent,
 		})
 	}
 
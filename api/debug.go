@@ -0,0 +1,115 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// redactedHeaders are the headers whose values are never logged, as they carry credentials
+var redactedHeaders = map[string]bool{
+	"Authorization": true,
+	"Cookie":        true,
+	"Set-Cookie":    true,
+}
+
+// NewDebugClient returns a new *DebugClient which logs the requests made with client against baseURL
+func NewDebugClient(client Client, baseURL string, log func(message string)) *DebugClient {
+	return &DebugClient{
+		Client:  client,
+		BaseURL: baseURL,
+		Log:     log,
+		now:     time.Now,
+	}
+}
+
+// DebugClient is a Client that logs the method, URL, headers, status and timing of each request, along with
+// the sizes of the bodies sent and received. Bodies are never logged, since they may hold secret values or,
+// as for imports and exports, whole apps, and the values of headers carrying credentials are redacted
+type DebugClient struct {
+	Client
+
+	// BaseURL is the base URL of the wrapped client, which the logged paths are relative to
+	BaseURL string
+
+	// Log is called with each message
+	Log func(message string)
+
+	now func() time.Time
+}
+
+// ExecuteRequest makes an HTTP request to the provided path, logging it once it is done
+func (dc *DebugClient) ExecuteRequest(method, path string, options RequestOptions) (*http.Response, error) {
+	// bodies whose length is known are not wrapped, so that the length is still sent along with the request
+	var size func() int64
+	switch body := options.Body.(type) {
+	case nil:
+	case interface{ Len() int }:
+		n := int64(body.Len())
+		size = func() int64 { return n }
+	default:
+		counted := &countingReader{Reader: body}
+		options.Body = counted
+		size = func() int64 { return counted.n }
+	}
+
+	start := dc.now()
+	res, err := dc.Client.ExecuteRequest(method, path, options)
+	elapsed := dc.now().Sub(start).Round(time.Millisecond)
+
+	request := fmt.Sprintf("%s %s%s", method, dc.BaseURL, path)
+	if headers := formatHeaders(options.Header); headers != "" {
+		request += " [" + headers + "]"
+	}
+
+	if err != nil {
+		dc.Log(fmt.Sprintf("DEBUG: %s failed after %s: %s", request, elapsed, err))
+		return res, err
+	}
+
+	sent := "no body"
+	if size != nil {
+		sent = fmt.Sprintf("%d bytes", size())
+	}
+	received := "unknown size"
+	if res.ContentLength >= 0 {
+		received = fmt.Sprintf("%d bytes", res.ContentLength)
+	}
+
+	dc.Log(fmt.Sprintf("DEBUG: %s -> %s in %s (sent %s, received %s)", request, res.Status, elapsed, sent, received))
+	return res, nil
+}
+
+// formatHeaders formats the headers sorted by name, redacting the values of those carrying credentials
+func formatHeaders(header http.Header) string {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	formatted := make([]string, 0, len(names))
+	for _, name := range names {
+		value := strings.Join(header[name], ", ")
+		if redactedHeaders[http.CanonicalHeaderKey(name)] {
+			value = "[redacted]"
+		}
+		formatted = append(formatted, fmt.Sprintf("%s: %s", name, value))
+	}
+	return strings.Join(formatted, "; ")
+}
+
+// countingReader counts the bytes read from the wrapped reader
+type countingReader struct {
+	io.Reader
+	n int64
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.Reader.Read(p)
+	cr.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,78 @@
+package api_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/10gen/realm-cli/api"
+
+	u "github.com/10gen/realm-cli/utils/test"
+	gc "github.com/smartystreets/goconvey/convey"
+)
+
+type failingClient struct{}
+
+func (failingClient) ExecuteRequest(method, path string, options api.RequestOptions) (*http.Response, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestDebugClient(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+		w.Write(append([]byte("received "), body...))
+	}))
+	defer testServer.Close()
+
+	setup := func(client api.Client) (api.Client, *[]string) {
+		var messages []string
+		return api.NewDebugClient(client, "https://realm.example.com", func(message string) {
+			messages = append(messages, message)
+		}), &messages
+	}
+
+	t.Run("should log the request with its sizes and redacted credentials", func(t *testing.T) {
+		client, messages := setup(api.NewClient(testServer.URL))
+
+		res, err := client.ExecuteRequest(http.MethodPost, "/api/admin/v3.0/groups/group-id/apps/app-id/secrets", api.RequestOptions{
+			Body: strings.NewReader(`{"name":"password","value":"hunter2"}`),
+			Header: http.Header{
+				"Authorization": []string{"Bearer my.access.token"},
+				"Content-Type":  []string{"application/json"},
+			},
+		})
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, res.StatusCode, gc.ShouldEqual, http.StatusCreated)
+
+		u.So(t, *messages, gc.ShouldHaveLength, 1)
+		message := (*messages)[0]
+		u.So(t, message, gc.ShouldStartWith, "DEBUG: POST https://realm.example.com/api/admin/v3.0/groups/group-id/apps/app-id/secrets "+
+			"[Authorization: [redacted]; Content-Type: application/json; X-Baas-Request-Origin: mongodb-baas-cli] -> 201 Created in ")
+		u.So(t, message, gc.ShouldEndWith, "(sent 37 bytes, received 46 bytes)")
+		u.So(t, message, gc.ShouldNotContainSubstring, "my.access.token")
+		u.So(t, message, gc.ShouldNotContainSubstring, "hunter2")
+	})
+
+	t.Run("should count the bytes of a body whose length is not known", func(t *testing.T) {
+		client, messages := setup(api.NewClient(testServer.URL))
+
+		_, err := client.ExecuteRequest(http.MethodPost, "/import", api.RequestOptions{
+			Body: ioutil.NopCloser(strings.NewReader("app data")),
+		})
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, (*messages)[0], gc.ShouldEndWith, "(sent 8 bytes, received 17 bytes)")
+	})
+
+	t.Run("should log a request which failed", func(t *testing.T) {
+		client, messages := setup(failingClient{})
+
+		_, err := client.ExecuteRequest(http.MethodGet, "/api/admin/v3.0/auth/profile", api.RequestOptions{})
+		u.So(t, err, gc.ShouldNotBeNil)
+		u.So(t, (*messages)[0], gc.ShouldStartWith, "DEBUG: GET https://realm.example.com/api/admin/v3.0/auth/profile failed after ")
+		u.So(t, (*messages)[0], gc.ShouldEndWith, ": connection refused")
+	})
+}
@@ -43,20 +43,31 @@ type Client interface {
 
 type simpleClient struct {
 	transport       *digest.Transport
+	baseTransport   http.RoundTripper
 	atlasAPIBaseURL string
 }
 
 // NewClient constructs and returns a new Client given a username, API key,
 // the public Cloud API base URL, and the atlas API base url
 func NewClient(atlasAPIBaseURL string) Client {
+	return NewClientWithTransport(atlasAPIBaseURL, nil)
+}
+
+// NewClientWithTransport constructs and returns a new Client which makes its requests with the transport
+// given, or with http.DefaultTransport if it is nil
+func NewClientWithTransport(atlasAPIBaseURL string, transport http.RoundTripper) Client {
 	return &simpleClient{
 		atlasAPIBaseURL: atlasAPIBaseURL,
+		baseTransport:   transport,
 	}
 }
 
 func (client simpleClient) WithAuth(username, apiKey string) Client {
 	// digest.NewTransport will use http.DefaultTransport
 	client.transport = digest.NewTransport(username, apiKey)
+	if client.baseTransport != nil {
+		client.transport.Transport = client.baseTransport
+	}
 	return &client
 }
 
@@ -164,7 +175,7 @@ func (client *simpleClient) do(
 
 	req.Header.Add("User-Agent", "MongoDB-BaaS-CLI")
 
-	cl := http.Client{}
+	cl := http.Client{Transport: client.baseTransport}
 	cl.Timeout = time.Second * 20
 	if client.transport == nil {
 		if needAuth {
@@ -5,6 +5,7 @@
 package mock_api
 
 import (
+	context "context"
 	api "github.com/10gen/realm-cli/api"
 	auth "github.com/10gen/realm-cli/auth"
 	hosting "github.com/10gen/realm-cli/hosting"
@@ -111,6 +112,34 @@ func (mr *MockRealmClientMockRecorder) CreateEmptyApp(groupID, appName, location
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEmptyApp", reflect.TypeOf((*MockRealmClient)(nil).CreateEmptyApp), groupID, appName, location, deploymentModel)
 }
 
+// CreateTrigger mocks base method
+func (m *MockRealmClient) CreateTrigger(groupID, appID string, trigger models.Trigger) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTrigger", groupID, appID, trigger)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateTrigger indicates an expected call of CreateTrigger
+func (mr *MockRealmClientMockRecorder) CreateTrigger(groupID, appID, trigger interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTrigger", reflect.TypeOf((*MockRealmClient)(nil).CreateTrigger), groupID, appID, trigger)
+}
+
+// DeleteApp mocks base method
+func (m *MockRealmClient) DeleteApp(groupID, appID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteApp", groupID, appID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteApp indicates an expected call of DeleteApp
+func (mr *MockRealmClientMockRecorder) DeleteApp(groupID, appID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteApp", reflect.TypeOf((*MockRealmClient)(nil).DeleteApp), groupID, appID)
+}
+
 // DeleteAsset mocks base method
 func (m *MockRealmClient) DeleteAsset(groupID, appID, path string) error {
 	m.ctrl.T.Helper()
@@ -184,6 +213,21 @@ func (mr *MockRealmClientMockRecorder) DraftDiff(groupID, appID, draftID interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DraftDiff", reflect.TypeOf((*MockRealmClient)(nil).DraftDiff), groupID, appID, draftID)
 }
 
+// ExecuteFunction mocks base method
+func (m *MockRealmClient) ExecuteFunction(ctx context.Context, groupID, appID, name string, args []interface{}) (*models.FunctionExecution, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExecuteFunction", ctx, groupID, appID, name, args)
+	ret0, _ := ret[0].(*models.FunctionExecution)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExecuteFunction indicates an expected call of ExecuteFunction
+func (mr *MockRealmClientMockRecorder) ExecuteFunction(ctx, groupID, appID, name, args interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteFunction", reflect.TypeOf((*MockRealmClient)(nil).ExecuteFunction), ctx, groupID, appID, name, args)
+}
+
 // Export mocks base method
 func (m *MockRealmClient) Export(groupID, appID string, strategy api.ExportStrategy) (string, io.ReadCloser, error) {
 	m.ctrl.T.Helper()
@@ -261,6 +305,81 @@ func (mr *MockRealmClientMockRecorder) FetchAppsByGroupID(groupID interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchAppsByGroupID", reflect.TypeOf((*MockRealmClient)(nil).FetchAppsByGroupID), groupID)
 }
 
+// FindApps mocks base method
+func (m *MockRealmClient) FindApps(filter api.AppFilter) ([]*models.App, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindApps", filter)
+	ret0, _ := ret[0].([]*models.App)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindApps indicates an expected call of FindApps
+func (mr *MockRealmClientMockRecorder) FindApps(filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindApps", reflect.TypeOf((*MockRealmClient)(nil).FindApps), filter)
+}
+
+// GetApp mocks base method
+func (m *MockRealmClient) GetApp(groupID, appID string) (*models.App, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetApp", groupID, appID)
+	ret0, _ := ret[0].(*models.App)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetApp indicates an expected call of GetApp
+func (mr *MockRealmClientMockRecorder) GetApp(groupID, appID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetApp", reflect.TypeOf((*MockRealmClient)(nil).GetApp), groupID, appID)
+}
+
+// GetCacheInvalidation mocks base method
+func (m *MockRealmClient) GetCacheInvalidation(groupID, appID, jobID string) (*models.CacheInvalidation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCacheInvalidation", groupID, appID, jobID)
+	ret0, _ := ret[0].(*models.CacheInvalidation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCacheInvalidation indicates an expected call of GetCacheInvalidation
+func (mr *MockRealmClientMockRecorder) GetCacheInvalidation(groupID, appID, jobID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCacheInvalidation", reflect.TypeOf((*MockRealmClient)(nil).GetCacheInvalidation), groupID, appID, jobID)
+}
+
+// GetCollectionSchema mocks base method
+func (m *MockRealmClient) GetCollectionSchema(groupID, appID, serviceID, database, collection string) (interface{}, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCollectionSchema", groupID, appID, serviceID, database, collection)
+	ret0, _ := ret[0].(interface{})
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCollectionSchema indicates an expected call of GetCollectionSchema
+func (mr *MockRealmClientMockRecorder) GetCollectionSchema(groupID, appID, serviceID, database, collection interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCollectionSchema", reflect.TypeOf((*MockRealmClient)(nil).GetCollectionSchema), groupID, appID, serviceID, database, collection)
+}
+
+// GetDependencies mocks base method
+func (m *MockRealmClient) GetDependencies(groupID, appID string) (*models.Dependencies, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDependencies", groupID, appID)
+	ret0, _ := ret[0].(*models.Dependencies)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDependencies indicates an expected call of GetDependencies
+func (mr *MockRealmClientMockRecorder) GetDependencies(groupID, appID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDependencies", reflect.TypeOf((*MockRealmClient)(nil).GetDependencies), groupID, appID)
+}
+
 // GetDeployment mocks base method
 func (m *MockRealmClient) GetDeployment(groupID, appID, deploymentID string) (*models.Deployment, error) {
 	m.ctrl.T.Helper()
@@ -291,26 +410,42 @@ func (mr *MockRealmClientMockRecorder) GetDrafts(groupID, appID interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDrafts", reflect.TypeOf((*MockRealmClient)(nil).GetDrafts), groupID, appID)
 }
 
+// GetUserProfile mocks base method
+func (m *MockRealmClient) GetUserProfile() (*models.UserProfile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserProfile")
+	ret0, _ := ret[0].(*models.UserProfile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserProfile indicates an expected call of GetUserProfile
+func (mr *MockRealmClientMockRecorder) GetUserProfile() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserProfile", reflect.TypeOf((*MockRealmClient)(nil).GetUserProfile))
+}
+
 // Import mocks base method
-func (m *MockRealmClient) Import(groupID, appID string, appData []byte, strategy string) error {
+func (m *MockRealmClient) Import(ctx context.Context, groupID, appID string, appData []byte, strategy string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Import", groupID, appID, appData, strategy)
+	ret := m.ctrl.Call(m, "Import", ctx, groupID, appID, appData, strategy)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Import indicates an expected call of Import
-func (mr *MockRealmClientMockRecorder) Import(groupID, appID, appData, strategy interface{}) *gomock.Call {
+func (mr *MockRealmClientMockRecorder) Import(ctx, groupID, appID, appData, strategy interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Import", reflect.TypeOf((*MockRealmClient)(nil).Import), groupID, appID, appData, strategy)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Import", reflect.TypeOf((*MockRealmClient)(nil).Import), ctx, groupID, appID, appData, strategy)
 }
 
 // InvalidateCache mocks base method
-func (m *MockRealmClient) InvalidateCache(groupID, appID, path string) error {
+func (m *MockRealmClient) InvalidateCache(groupID, appID, path string) (string, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "InvalidateCache", groupID, appID, path)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // InvalidateCache indicates an expected call of InvalidateCache
@@ -334,6 +469,82 @@ func (mr *MockRealmClientMockRecorder) ListAssetsForAppID(groupID, appID interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAssetsForAppID", reflect.TypeOf((*MockRealmClient)(nil).ListAssetsForAppID), groupID, appID)
 }
 
+// ListAssetsForAppIDIfChanged mocks base method
+func (m *MockRealmClient) ListAssetsForAppIDIfChanged(groupID, appID, etag string) ([]hosting.AssetMetadata, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAssetsForAppIDIfChanged", groupID, appID, etag)
+	ret0, _ := ret[0].([]hosting.AssetMetadata)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListAssetsForAppIDIfChanged indicates an expected call of ListAssetsForAppIDIfChanged
+func (mr *MockRealmClientMockRecorder) ListAssetsForAppIDIfChanged(groupID, appID, etag interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAssetsForAppIDIfChanged", reflect.TypeOf((*MockRealmClient)(nil).ListAssetsForAppIDIfChanged), groupID, appID, etag)
+}
+
+// ListAuthProviders mocks base method
+func (m *MockRealmClient) ListAuthProviders(groupID, appID string) ([]models.AuthProvider, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAuthProviders", groupID, appID)
+	ret0, _ := ret[0].([]models.AuthProvider)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAuthProviders indicates an expected call of ListAuthProviders
+func (mr *MockRealmClientMockRecorder) ListAuthProviders(groupID, appID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAuthProviders", reflect.TypeOf((*MockRealmClient)(nil).ListAuthProviders), groupID, appID)
+}
+
+// ListDeployments mocks base method
+func (m *MockRealmClient) ListDeployments(groupID, appID string) ([]models.Deployment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDeployments", groupID, appID)
+	ret0, _ := ret[0].([]models.Deployment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDeployments indicates an expected call of ListDeployments
+func (mr *MockRealmClientMockRecorder) ListDeployments(groupID, appID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeployments", reflect.TypeOf((*MockRealmClient)(nil).ListDeployments), groupID, appID)
+}
+
+// ListFunctions mocks base method
+func (m *MockRealmClient) ListFunctions(groupID, appID string) ([]models.Function, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFunctions", groupID, appID)
+	ret0, _ := ret[0].([]models.Function)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFunctions indicates an expected call of ListFunctions
+func (mr *MockRealmClientMockRecorder) ListFunctions(groupID, appID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFunctions", reflect.TypeOf((*MockRealmClient)(nil).ListFunctions), groupID, appID)
+}
+
+// ListLogs mocks base method
+func (m *MockRealmClient) ListLogs(groupID, appID string, options api.LogsOptions) ([]models.Log, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListLogs", groupID, appID, options)
+	ret0, _ := ret[0].([]models.Log)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListLogs indicates an expected call of ListLogs
+func (mr *MockRealmClientMockRecorder) ListLogs(groupID, appID, options interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListLogs", reflect.TypeOf((*MockRealmClient)(nil).ListLogs), groupID, appID, options)
+}
+
 // ListSecrets mocks base method
 func (m *MockRealmClient) ListSecrets(groupID, appID string) ([]secrets.Secret, error) {
 	m.ctrl.T.Helper()
@@ -349,6 +560,51 @@ func (mr *MockRealmClientMockRecorder) ListSecrets(groupID, appID interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSecrets", reflect.TypeOf((*MockRealmClient)(nil).ListSecrets), groupID, appID)
 }
 
+// ListServices mocks base method
+func (m *MockRealmClient) ListServices(groupID, appID string) ([]models.Service, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListServices", groupID, appID)
+	ret0, _ := ret[0].([]models.Service)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListServices indicates an expected call of ListServices
+func (mr *MockRealmClientMockRecorder) ListServices(groupID, appID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListServices", reflect.TypeOf((*MockRealmClient)(nil).ListServices), groupID, appID)
+}
+
+// ListTemplates mocks base method
+func (m *MockRealmClient) ListTemplates() ([]models.Template, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTemplates")
+	ret0, _ := ret[0].([]models.Template)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTemplates indicates an expected call of ListTemplates
+func (mr *MockRealmClientMockRecorder) ListTemplates() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTemplates", reflect.TypeOf((*MockRealmClient)(nil).ListTemplates))
+}
+
+// ListTriggers mocks base method
+func (m *MockRealmClient) ListTriggers(groupID, appID string) ([]models.Trigger, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTriggers", groupID, appID)
+	ret0, _ := ret[0].([]models.Trigger)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTriggers indicates an expected call of ListTriggers
+func (mr *MockRealmClientMockRecorder) ListTriggers(groupID, appID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTriggers", reflect.TypeOf((*MockRealmClient)(nil).ListTriggers), groupID, appID)
+}
+
 // MoveAsset mocks base method
 func (m *MockRealmClient) MoveAsset(groupID, appID, fromPath, toPath string) error {
 	m.ctrl.T.Helper()
@@ -391,6 +647,20 @@ func (mr *MockRealmClientMockRecorder) RemoveSecretByName(groupID, appID, secret
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveSecretByName", reflect.TypeOf((*MockRealmClient)(nil).RemoveSecretByName), groupID, appID, secretName)
 }
 
+// RenameApp mocks base method
+func (m *MockRealmClient) RenameApp(groupID, appID, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenameApp", groupID, appID, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RenameApp indicates an expected call of RenameApp
+func (mr *MockRealmClientMockRecorder) RenameApp(groupID, appID, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenameApp", reflect.TypeOf((*MockRealmClient)(nil).RenameApp), groupID, appID, name)
+}
+
 // SetAssetAttributes mocks base method
 func (m *MockRealmClient) SetAssetAttributes(groupID, appID, path string, attributes ...hosting.AssetAttribute) error {
 	m.ctrl.T.Helper()
@@ -410,6 +680,20 @@ func (mr *MockRealmClientMockRecorder) SetAssetAttributes(groupID, appID, path i
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAssetAttributes", reflect.TypeOf((*MockRealmClient)(nil).SetAssetAttributes), varargs...)
 }
 
+// SetTriggerDisabled mocks base method
+func (m *MockRealmClient) SetTriggerDisabled(groupID, appID, triggerID string, disabled bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTriggerDisabled", groupID, appID, triggerID, disabled)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetTriggerDisabled indicates an expected call of SetTriggerDisabled
+func (mr *MockRealmClientMockRecorder) SetTriggerDisabled(groupID, appID, triggerID, disabled interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTriggerDisabled", reflect.TypeOf((*MockRealmClient)(nil).SetTriggerDisabled), groupID, appID, triggerID, disabled)
+}
+
 // UpdateSecretByID mocks base method
 func (m *MockRealmClient) UpdateSecretByID(groupID, appID, secretID, secretValue string) error {
 	m.ctrl.T.Helper()
@@ -438,6 +722,20 @@ func (mr *MockRealmClientMockRecorder) UpdateSecretByName(groupID, appID, secret
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSecretByName", reflect.TypeOf((*MockRealmClient)(nil).UpdateSecretByName), groupID, appID, secretName, secretValue)
 }
 
+// UpdateTrigger mocks base method
+func (m *MockRealmClient) UpdateTrigger(groupID, appID, triggerID string, trigger models.Trigger) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTrigger", groupID, appID, triggerID, trigger)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTrigger indicates an expected call of UpdateTrigger
+func (mr *MockRealmClientMockRecorder) UpdateTrigger(groupID, appID, triggerID, trigger interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTrigger", reflect.TypeOf((*MockRealmClient)(nil).UpdateTrigger), groupID, appID, triggerID, trigger)
+}
+
 // UploadAsset mocks base method
 func (m *MockRealmClient) UploadAsset(groupID, appID, path, hash string, size int64, body io.Reader, attributes ...hosting.AssetAttribute) error {
 	m.ctrl.T.Helper()
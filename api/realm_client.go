@@ -2,16 +2,22 @@ package api
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"hash"
 	"io"
 	"io/ioutil"
 	"mime"
 	"mime/multipart"
 	"net/http"
+	"net/url"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/10gen/realm-cli/auth"
 	"github.com/10gen/realm-cli/hosting"
@@ -24,8 +30,8 @@ import (
 type ExportStrategy string
 
 const (
-	// default to the current realm config version
-	configVersion = "20200603"
+	// ConfigVersion is the version of the app configuration exchanged with Realm
+	ConfigVersion = "20200603"
 
 	// ExportStrategyNone will result in no extra configuration into the call to Export
 	ExportStrategyNone ExportStrategy = "none"
@@ -33,11 +39,15 @@ const (
 	ExportStrategyTemplate ExportStrategy = "template"
 	// ExportStrategySourceControl will result in the `source_control` querystring parameter getting added to the call to Export
 	ExportStrategySourceControl ExportStrategy = "source_control"
+	// ExportStrategyDeployedVersion will result in the `version` querystring parameter being left out of the call to Export,
+	// so that the app is exported in the config version it is deployed with
+	ExportStrategyDeployedVersion ExportStrategy = "deployed_version"
 
 	userProfileRoute       = adminBaseURL + "/auth/profile"
 	authProviderLoginRoute = adminBaseURL + "/auth/providers/%s/login"
 
 	appsByGroupIDRoute      = adminBaseURL + "/groups/%s/apps"
+	appByIDRoute            = adminBaseURL + "/groups/%s/apps/%s"
 	atlasAppsByGroupIDRoute = appsByGroupIDRoute + "?product=atlas"
 	appImportRoute          = adminBaseURL + "/groups/%s/apps/%s/import"
 	appExportRoute          = adminBaseURL + "/groups/%s/apps/%s/export?%s"
@@ -47,22 +57,54 @@ const (
 	deployDraftRoute = adminBaseURL + "/groups/%s/apps/%s/drafts/%s/deployment"
 	diffDraftRoute   = adminBaseURL + "/groups/%s/apps/%s/drafts/%s/diff"
 
+	deploymentsRoute    = adminBaseURL + "/groups/%s/apps/%s/deployments"
 	deploymentByIDRoute = adminBaseURL + "/groups/%s/apps/%s/deployments/%s"
 
-	hostingInvalidateCacheRoute = adminBaseURL + "/groups/%s/apps/%s/hosting/cache"
-	hostingAssetsRoute          = adminBaseURL + "/groups/%s/apps/%s/hosting/assets"
-	hostingAssetRoute           = adminBaseURL + "/groups/%s/apps/%s/hosting/assets/asset"
+	executeFunctionRoute = adminBaseURL + "/groups/%s/apps/%s/debug/execute_function?run_as_system=true"
+
+	hostingInvalidateCacheRoute   = adminBaseURL + "/groups/%s/apps/%s/hosting/cache"
+	hostingCacheInvalidationRoute = adminBaseURL + "/groups/%s/apps/%s/hosting/cache/invalidations/%s"
+	hostingAssetsRoute            = adminBaseURL + "/groups/%s/apps/%s/hosting/assets"
+	hostingAssetRoute             = adminBaseURL + "/groups/%s/apps/%s/hosting/assets/asset"
+
+	triggersRoute = adminBaseURL + "/groups/%s/apps/%s/triggers"
+	triggerRoute  = adminBaseURL + "/groups/%s/apps/%s/triggers/%s"
+
+	functionsRoute = adminBaseURL + "/groups/%s/apps/%s/functions"
+
+	authProvidersRoute = adminBaseURL + "/groups/%s/apps/%s/auth_providers"
+	servicesRoute      = adminBaseURL + "/groups/%s/apps/%s/services"
+	serviceRulesRoute  = adminBaseURL + "/groups/%s/apps/%s/services/%s/rules"
+	serviceRuleRoute   = adminBaseURL + "/groups/%s/apps/%s/services/%s/rules/%s"
+
+	logsRoute = adminBaseURL + "/groups/%s/apps/%s/logs"
 
 	secretsRoute = adminBaseURL + "/groups/%s/apps/%s/secrets"
 	secretRoute  = adminBaseURL + "/groups/%s/apps/%s/secrets/%s"
 
 	dependenciesRoute              = adminBaseURL + "/groups/%s/apps/%s/dependencies"
 	dependenciesExportArchiveRoute = dependenciesRoute + "/archive"
+
+	templatesRoute = adminBaseURL + "/templates"
 )
 
 var (
 	errExportMissingFilename = errors.New("the app export response did not specify a filename")
 	errGroupNotFound         = errors.New("group could not be found")
+
+	// ErrNotModified is returned by conditional requests when the resource still has the ETag given
+	ErrNotModified = errors.New("the resource has not been modified")
+
+	// ErrNoCollectionSchema is returned when fetching the schema of a collection which has no rules, or whose
+	// rules do not define a schema
+	ErrNoCollectionSchema = errors.New("the collection has no schema")
+
+	// ErrNoDependencies is returned when exporting the dependencies of an app which has none uploaded
+	ErrNoDependencies = errors.New("the app has no dependencies")
+
+	// ErrExportChecksumMismatch is returned when reading an app export whose content does not match the
+	// digest sent along with it, e.g. because the download was cut short
+	ErrExportChecksumMismatch = errors.New("the app export does not match its checksum, the download may be incomplete")
 )
 
 const (
@@ -85,6 +127,19 @@ type setAttributesPayload struct {
 	Attributes []hosting.AssetAttribute `json:"attributes"`
 }
 
+type executeFunctionPayload struct {
+	Name      string        `json:"name"`
+	Arguments []interface{} `json:"arguments"`
+}
+
+type renameAppPayload struct {
+	Name string `json:"name"`
+}
+
+type setTriggerDisabledPayload struct {
+	Disabled bool `json:"disabled"`
+}
+
 type invalidateCachePayload struct {
 	Invalidate bool   `json:"invalidate"`
 	Path       string `json:"path"`
@@ -97,28 +152,48 @@ type RealmClient interface {
 	CopyAsset(groupID, appID, fromPath, toPath string) error
 	CreateDraft(groupID, appID string) (*models.AppDraft, error)
 	CreateEmptyApp(groupID, appName, location, deploymentModel string) (*models.App, error)
+	CreateTrigger(groupID, appID string, trigger models.Trigger) error
+	DeleteApp(groupID, appID string) error
 	DeleteAsset(groupID, appID, path string) error
 	DeployDraft(groupID, appID, draftID string) (*models.Deployment, error)
 	Diff(groupID, appID string, appData []byte, strategy string) ([]string, error)
 	DiscardDraft(groupID, appID, draftID string) error
 	DraftDiff(groupID, appID, draftID string) (*models.DraftDiff, error)
+	ExecuteFunction(ctx context.Context, groupID, appID, name string, args []interface{}) (*models.FunctionExecution, error)
 	Export(groupID, appID string, strategy ExportStrategy) (string, io.ReadCloser, error)
 	ExportDependencies(groupID, appID string) (string, io.ReadCloser, error)
 	FetchAppByClientAppID(clientAppID string) (*models.App, error)
 	FetchAppByGroupIDAndClientAppID(groupID, clientAppID string) (*models.App, error)
 	FetchAppsByGroupID(groupID string) ([]*models.App, error)
+	FindApps(filter AppFilter) ([]*models.App, error)
+	GetApp(groupID, appID string) (*models.App, error)
+	GetCacheInvalidation(groupID, appID, jobID string) (*models.CacheInvalidation, error)
+	GetCollectionSchema(groupID, appID, serviceID, database, collection string) (interface{}, error)
+	GetDependencies(groupID, appID string) (*models.Dependencies, error)
 	GetDeployment(groupID, appID, deploymentID string) (*models.Deployment, error)
 	GetDrafts(groupID, appID string) ([]models.AppDraft, error)
-	Import(groupID, appID string, appData []byte, strategy string) error
-	InvalidateCache(groupID, appID, path string) error
+	GetUserProfile() (*models.UserProfile, error)
+	Import(ctx context.Context, groupID, appID string, appData []byte, strategy string) error
+	InvalidateCache(groupID, appID, path string) (string, error)
 	ListAssetsForAppID(groupID, appID string) ([]hosting.AssetMetadata, error)
+	ListAssetsForAppIDIfChanged(groupID, appID, etag string) ([]hosting.AssetMetadata, string, error)
+	ListAuthProviders(groupID, appID string) ([]models.AuthProvider, error)
+	ListDeployments(groupID, appID string) ([]models.Deployment, error)
+	ListFunctions(groupID, appID string) ([]models.Function, error)
+	ListLogs(groupID, appID string, options LogsOptions) ([]models.Log, error)
 	ListSecrets(groupID, appID string) ([]secrets.Secret, error)
+	ListServices(groupID, appID string) ([]models.Service, error)
+	ListTemplates() ([]models.Template, error)
+	ListTriggers(groupID, appID string) ([]models.Trigger, error)
 	MoveAsset(groupID, appID, fromPath, toPath string) error
 	RemoveSecretByID(groupID, appID, secretID string) error
 	RemoveSecretByName(groupID, appID, secretName string) error
+	RenameApp(groupID, appID, name string) error
 	SetAssetAttributes(groupID, appID, path string, attributes ...hosting.AssetAttribute) error
+	SetTriggerDisabled(groupID, appID, triggerID string, disabled bool) error
 	UpdateSecretByID(groupID, appID, secretID, secretValue string) error
 	UpdateSecretByName(groupID, appID, secretName, secretValue string) error
+	UpdateTrigger(groupID, appID, triggerID string, trigger models.Trigger) error
 	UploadAsset(groupID, appID, path, hash string, size int64, body io.Reader, attributes ...hosting.AssetAttribute) error
 	UploadDependencies(groupID, appID, fullPath string) error
 }
@@ -168,7 +243,10 @@ func (sc *basicRealmClient) Authenticate(authProvider auth.AuthenticationProvide
 
 // Export will download a Realm app as a .zip
 func (sc *basicRealmClient) Export(groupID, appID string, strategy ExportStrategy) (string, io.ReadCloser, error) {
-	queryParams := []string{fmt.Sprintf("version=%s", configVersion)}
+	var queryParams []string
+	if strategy != ExportStrategyDeployedVersion {
+		queryParams = append(queryParams, fmt.Sprintf("version=%s", ConfigVersion))
+	}
 	if strategy == ExportStrategyTemplate {
 		queryParams = append(queryParams, "template=true")
 	} else if strategy == ExportStrategySourceControl {
@@ -198,7 +276,52 @@ func (sc *basicRealmClient) Export(groupID, appID string, strategy ExportStrateg
 		return "", nil, errExportMissingFilename
 	}
 
-	return filename, res.Body, nil
+	checksum, err := parseSHA256Digest(res.Header.Get(digestHeader))
+	if err != nil {
+		res.Body.Close()
+		return "", nil, err
+	}
+	if checksum == nil {
+		return filename, res.Body, nil
+	}
+
+	return filename, &checksumReadCloser{ReadCloser: res.Body, hash: sha256.New(), checksum: checksum}, nil
+}
+
+// digestHeader carries the checksums of a response body, e.g. "SHA-256=X48E9qOokqqrvdts8nOJRJN3OWDUoyWxBf7kbu9DBPE="
+const digestHeader = "Digest"
+
+// parseSHA256Digest returns the SHA-256 checksum of the Digest header, or nil if it does not have one
+func parseSHA256Digest(header string) ([]byte, error) {
+	for _, digest := range strings.Split(header, ",") {
+		parts := strings.SplitN(strings.TrimSpace(digest), "=", 2)
+		if len(parts) != 2 || !strings.EqualFold(parts[0], "SHA-256") {
+			continue
+		}
+
+		checksum, err := base64.StdEncoding.DecodeString(parts[1])
+		if err != nil || len(checksum) != sha256.Size {
+			return nil, fmt.Errorf("the app export response has an invalid %s header: %q", digestHeader, header)
+		}
+		return checksum, nil
+	}
+	return nil, nil
+}
+
+// checksumReadCloser checks the body read through it against checksum once it has been read in full
+type checksumReadCloser struct {
+	io.ReadCloser
+	hash     hash.Hash
+	checksum []byte
+}
+
+func (r *checksumReadCloser) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.hash.Write(p[:n])
+	if err == io.EOF && !bytes.Equal(r.hash.Sum(nil), r.checksum) {
+		return n, ErrExportChecksumMismatch
+	}
+	return n, err
 }
 
 // Export will download the installed dependencies as a zip
@@ -210,6 +333,11 @@ func (sc *basicRealmClient) ExportDependencies(groupID, appID string) (string, i
 		return "", nil, err
 	}
 
+	if res.StatusCode == http.StatusNotFound {
+		res.Body.Close()
+		return "", nil, ErrNoDependencies
+	}
+
 	if res.StatusCode != http.StatusOK {
 		defer res.Body.Close()
 		return "", nil, UnmarshalRealmError(res)
@@ -233,7 +361,7 @@ func (sc *basicRealmClient) ExportDependencies(groupID, appID string) (string, i
 
 // Diff will execute a dry-run of an import, returning a diff of proposed changes
 func (sc *basicRealmClient) Diff(groupID, appID string, appData []byte, strategy string) ([]string, error) {
-	res, err := sc.invokeImportRoute(groupID, appID, appData, strategy, true)
+	res, err := sc.invokeImportRoute(context.Background(), groupID, appID, appData, strategy, true)
 	if err != nil {
 		return nil, err
 	}
@@ -252,9 +380,9 @@ func (sc *basicRealmClient) Diff(groupID, appID string, appData []byte, strategy
 	return diffs, nil
 }
 
-// Import will push a local Realm app to the server
-func (sc *basicRealmClient) Import(groupID, appID string, appData []byte, strategy string) error {
-	res, err := sc.invokeImportRoute(groupID, appID, appData, strategy, false)
+// Import will push a local Realm app to the server, giving up once ctx is done
+func (sc *basicRealmClient) Import(ctx context.Context, groupID, appID string, appData []byte, strategy string) error {
+	res, err := sc.invokeImportRoute(ctx, groupID, appID, appData, strategy, false)
 	if err != nil {
 		return err
 	}
@@ -361,6 +489,78 @@ func (sc *basicRealmClient) GetDeployment(groupID, appID, deploymentID string) (
 	return &deployment, nil
 }
 
+// LogsOptions filters the logs of an app. Zero values do not filter
+type LogsOptions struct {
+	// Types are the log types to keep, e.g. "FUNCTION" or "AUTH"
+	Types      []string
+	ErrorsOnly bool
+	Start      time.Time
+	End        time.Time
+}
+
+// ListLogs returns the most recent logs of the app matching the options, newest first
+func (sc *basicRealmClient) ListLogs(groupID, appID string, options LogsOptions) ([]models.Log, error) {
+	query := url.Values{}
+	if len(options.Types) > 0 {
+		query.Set("type", strings.Join(options.Types, ","))
+	}
+	if options.ErrorsOnly {
+		query.Set("errors_only", "true")
+	}
+	if !options.Start.IsZero() {
+		query.Set("start_date", options.Start.UTC().Format(time.RFC3339Nano))
+	}
+	if !options.End.IsZero() {
+		query.Set("end_date", options.End.UTC().Format(time.RFC3339Nano))
+	}
+
+	route := fmt.Sprintf(logsRoute, groupID, appID)
+	if len(query) > 0 {
+		route += "?" + query.Encode()
+	}
+
+	res, err := sc.ExecuteRequest(http.MethodGet, route, RequestOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, UnmarshalRealmError(res)
+	}
+
+	var logs struct {
+		Logs []models.Log `json:"logs"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&logs); err != nil {
+		return nil, err
+	}
+
+	return logs.Logs, nil
+}
+
+// ListDeployments returns the deployments of the app, as many as the Realm Admin API keeps
+func (sc *basicRealmClient) ListDeployments(groupID, appID string) ([]models.Deployment, error) {
+	res, err := sc.ExecuteRequest(http.MethodGet, fmt.Sprintf(deploymentsRoute, groupID, appID), RequestOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, UnmarshalRealmError(res)
+	}
+
+	var deployments []models.Deployment
+	if err := json.NewDecoder(res.Body).Decode(&deployments); err != nil {
+		return nil, err
+	}
+
+	return deployments, nil
+}
+
 func (sc *basicRealmClient) GetDrafts(groupID, appID string) ([]models.AppDraft, error) {
 	res, err := sc.ExecuteRequest(http.MethodGet, fmt.Sprintf(draftsRoute, groupID, appID), RequestOptions{})
 	if err != nil {
@@ -413,7 +613,37 @@ func (sc *basicRealmClient) DraftDiff(groupID, appID, draftID string) (*models.D
 	return &diff, nil
 }
 
-func (sc *basicRealmClient) invokeImportRoute(groupID, appID string, appData []byte, strategy string, diff bool) (*http.Response, error) {
+// ExecuteFunction runs the named function of the app as the system user, giving up once ctx is done
+func (sc *basicRealmClient) ExecuteFunction(ctx context.Context, groupID, appID, name string, args []interface{}) (*models.FunctionExecution, error) {
+	if args == nil {
+		args = []interface{}{}
+	}
+
+	payload, err := json.Marshal(executeFunctionPayload{name, args})
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := sc.ExecuteRequest(http.MethodPost, fmt.Sprintf(executeFunctionRoute, groupID, appID), RequestOptions{Body: bytes.NewReader(payload), Context: ctx})
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, UnmarshalRealmError(res)
+	}
+
+	var execution models.FunctionExecution
+	if err := json.NewDecoder(res.Body).Decode(&execution); err != nil {
+		return nil, err
+	}
+
+	return &execution, nil
+}
+
+func (sc *basicRealmClient) invokeImportRoute(ctx context.Context, groupID, appID string, appData []byte, strategy string, diff bool) (*http.Response, error) {
 	url := fmt.Sprintf(appImportRoute, groupID, appID)
 
 	url += fmt.Sprintf("?strategy=%s", strategy)
@@ -421,7 +651,8 @@ func (sc *basicRealmClient) invokeImportRoute(groupID, appID string, appData []b
 		url += "&diff=true"
 	}
 
-	return sc.ExecuteRequest(http.MethodPost, url, RequestOptions{Body: bytes.NewReader(appData)})
+	// a diff only reads the app, so it is safe to retry unlike an import
+	return sc.ExecuteRequest(http.MethodPost, url, RequestOptions{Body: bytes.NewReader(appData), Context: ctx, Idempotent: diff})
 }
 
 func (sc *basicRealmClient) FetchAppsByGroupID(groupID string) ([]*models.App, error) {
@@ -439,6 +670,71 @@ func (sc *basicRealmClient) FetchAppByGroupIDAndClientAppID(groupID, clientAppID
 
 // FetchAppByClientAppID fetches a Realm app given a clientAppID
 func (sc *basicRealmClient) FetchAppByClientAppID(clientAppID string) (*models.App, error) {
+	profileData, err := sc.GetUserProfile()
+	if err != nil {
+		return nil, err
+	}
+
+	return sc.findProjectAppByClientAppID(profileData.AllGroupIDs(), clientAppID)
+}
+
+// AppFilter narrows down the apps FindApps returns. Zero values do not filter
+type AppFilter struct {
+	// GroupID is the project to look in, instead of every project the user can access
+	GroupID     string
+	AppID       string
+	ClientAppID string
+	Name        string
+}
+
+func (filter AppFilter) matches(app *models.App) bool {
+	return (filter.AppID == "" || app.ID == filter.AppID) &&
+		(filter.ClientAppID == "" || app.ClientAppID == filter.ClientAppID) &&
+		(filter.Name == "" || app.Name == filter.Name)
+}
+
+// FindApps returns every app matching the filter, including the apps backing Atlas triggers, in the order
+// of the projects they belong to. Unlike FetchAppByClientAppID, which stops at the first app found, it tells
+// when several apps match
+func (sc *basicRealmClient) FindApps(filter AppFilter) ([]*models.App, error) {
+	groupIDs := []string{filter.GroupID}
+	if filter.GroupID == "" {
+		profileData, err := sc.GetUserProfile()
+		if err != nil {
+			return nil, err
+		}
+		groupIDs = profileData.AllGroupIDs()
+	}
+
+	found := []*models.App{}
+	seenGroups, seenApps := map[string]bool{}, map[string]bool{}
+	for _, groupID := range groupIDs {
+		// a user may have several roles in a project
+		if seenGroups[groupID] {
+			continue
+		}
+		seenGroups[groupID] = true
+
+		for _, fetchApps := range []func(string) ([]*models.App, error){sc.FetchAppsByGroupID, sc.FetchAtlasAppsByGroupID} {
+			apps, err := fetchApps(groupID)
+			if err != nil && err != errGroupNotFound {
+				return nil, err
+			}
+
+			for _, app := range apps {
+				if filter.matches(app) && !seenApps[app.ID] {
+					seenApps[app.ID] = true
+					found = append(found, app)
+				}
+			}
+		}
+	}
+
+	return found, nil
+}
+
+// GetUserProfile fetches the profile of the user the client is authenticated as
+func (sc *basicRealmClient) GetUserProfile() (*models.UserProfile, error) {
 	res, err := sc.ExecuteRequest(http.MethodGet, userProfileRoute, RequestOptions{})
 	if err != nil {
 		return nil, err
@@ -456,7 +752,7 @@ func (sc *basicRealmClient) FetchAppByClientAppID(clientAppID string) (*models.A
 		return nil, err
 	}
 
-	return sc.findProjectAppByClientAppID(profileData.AllGroupIDs(), clientAppID)
+	return &profileData, nil
 }
 
 // UploadAsset creates a pipe and writes the asset to an http.POST along with its metadata
@@ -636,37 +932,112 @@ func (sc *basicRealmClient) CreateEmptyApp(groupID, appName, location, deploymen
 	return &app, nil
 }
 
+// GetApp fetches the app, along with its location and deployment model
+func (sc *basicRealmClient) GetApp(groupID, appID string) (*models.App, error) {
+	res, err := sc.ExecuteRequest(http.MethodGet, fmt.Sprintf(appByIDRoute, groupID, appID), RequestOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, UnmarshalRealmError(res)
+	}
+
+	var app models.App
+	if err := json.NewDecoder(res.Body).Decode(&app); err != nil {
+		return nil, err
+	}
+
+	return &app, nil
+}
+
+// DeleteApp deletes the app along with everything it contains
+func (sc *basicRealmClient) DeleteApp(groupID, appID string) error {
+	res, err := sc.ExecuteRequest(http.MethodDelete, fmt.Sprintf(appByIDRoute, groupID, appID), RequestOptions{})
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusNoContent {
+		return UnmarshalRealmError(res)
+	}
+
+	return nil
+}
+
+// RenameApp changes the name of the app, leaving its Client App ID as it is
+func (sc *basicRealmClient) RenameApp(groupID, appID, name string) error {
+	payload, err := json.Marshal(renameAppPayload{name})
+	if err != nil {
+		return err
+	}
+
+	res, err := sc.ExecuteRequest(http.MethodPatch, fmt.Sprintf(appByIDRoute, groupID, appID), RequestOptions{Body: bytes.NewReader(payload)})
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusNoContent && res.StatusCode != http.StatusOK {
+		return UnmarshalRealmError(res)
+	}
+
+	return nil
+}
+
 func (sc *basicRealmClient) ListAssetsForAppID(groupID, appID string) ([]hosting.AssetMetadata, error) {
+	assetMetadata, _, err := sc.ListAssetsForAppIDIfChanged(groupID, appID, "")
+	return assetMetadata, err
+}
+
+// ListAssetsForAppIDIfChanged lists the hosting assets of the app along with the ETag of the listing. If etag is
+// given and the listing still has it, ErrNotModified is returned instead, so that a listing kept from before
+// can be used without downloading it again
+func (sc *basicRealmClient) ListAssetsForAppIDIfChanged(groupID, appID, etag string) ([]hosting.AssetMetadata, string, error) {
+	header := http.Header{}
+	if etag != "" {
+		header.Set("If-None-Match", etag)
+	}
+
 	res, err := sc.ExecuteRequest(
 		http.MethodGet,
 		fmt.Sprintf(hostingAssetsRoute+"?recursive=true", groupID, appID),
-		RequestOptions{},
+		RequestOptions{Header: header},
 	)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	defer res.Body.Close()
 
+	if etag != "" && res.StatusCode == http.StatusNotModified {
+		return nil, etag, ErrNotModified
+	}
+
 	if res.StatusCode != http.StatusOK {
-		return nil, UnmarshalRealmError(res)
+		return nil, "", UnmarshalRealmError(res)
 	}
 
 	dec := json.NewDecoder(res.Body)
 	var assetMetadata []hosting.AssetMetadata
 	if err := dec.Decode(&assetMetadata); err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
-	return assetMetadata, nil
+	return assetMetadata, res.Header.Get("ETag"), nil
 }
 
 // InvalidateCache requests cache invalidation for the resource at the given
 // path in the app's CloudFront distribution
-func (sc *basicRealmClient) InvalidateCache(groupID, appID, path string) error {
+func (sc *basicRealmClient) InvalidateCache(groupID, appID, path string) (string, error) {
 	payload, err := json.Marshal(invalidateCachePayload{Invalidate: true, Path: path})
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	res, err := sc.ExecuteRequest(
@@ -676,7 +1047,128 @@ func (sc *basicRealmClient) InvalidateCache(groupID, appID, path string) error {
 			Body: bytes.NewReader(payload),
 		},
 	)
-	return checkStatusNoContent(res, err, "failed to invalidate cache")
+	if err != nil {
+		return "", err
+	}
+
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case http.StatusNoContent:
+		// the invalidation is not tracked, so there is no job to wait for
+		return "", nil
+	case http.StatusOK, http.StatusAccepted:
+	default:
+		return "", fmt.Errorf("%s: failed to invalidate cache: %s", res.Status, UnmarshalRealmError(res))
+	}
+
+	var invalidation models.CacheInvalidation
+	if err := json.NewDecoder(res.Body).Decode(&invalidation); err != nil {
+		return "", err
+	}
+
+	return invalidation.ID, nil
+}
+
+// GetCacheInvalidation returns the job invalidating the CDN cache with the given ID
+func (sc *basicRealmClient) GetCacheInvalidation(groupID, appID, jobID string) (*models.CacheInvalidation, error) {
+	res, err := sc.ExecuteRequest(http.MethodGet, fmt.Sprintf(hostingCacheInvalidationRoute, groupID, appID, jobID), RequestOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, UnmarshalRealmError(res)
+	}
+
+	var invalidation models.CacheInvalidation
+	if err := json.NewDecoder(res.Body).Decode(&invalidation); err != nil {
+		return nil, err
+	}
+
+	return &invalidation, nil
+}
+
+// GetDependencies returns the dependencies deployed to the app, or ErrNoDependencies if none were ever uploaded
+func (sc *basicRealmClient) GetDependencies(groupID, appID string) (*models.Dependencies, error) {
+	res, err := sc.ExecuteRequest(http.MethodGet, fmt.Sprintf(dependenciesRoute, groupID, appID), RequestOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return nil, ErrNoDependencies
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return nil, UnmarshalRealmError(res)
+	}
+
+	var dependencies models.Dependencies
+	if err := json.NewDecoder(res.Body).Decode(&dependencies); err != nil {
+		return nil, err
+	}
+
+	return &dependencies, nil
+}
+
+// GetCollectionSchema fetches the JSON schema the rules of the data source with the given ID define for a collection
+func (sc *basicRealmClient) GetCollectionSchema(groupID, appID, serviceID, database, collection string) (interface{}, error) {
+	res, err := sc.ExecuteRequest(http.MethodGet, fmt.Sprintf(serviceRulesRoute, groupID, appID, serviceID), RequestOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, UnmarshalRealmError(res)
+	}
+
+	var rules []struct {
+		ID         string `json:"_id"`
+		Database   string `json:"database"`
+		Collection string `json:"collection"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&rules); err != nil {
+		return nil, err
+	}
+
+	var ruleID string
+	for _, rule := range rules {
+		if rule.Database == database && rule.Collection == collection {
+			ruleID = rule.ID
+			break
+		}
+	}
+	if ruleID == "" {
+		return nil, ErrNoCollectionSchema
+	}
+
+	ruleRes, err := sc.ExecuteRequest(http.MethodGet, fmt.Sprintf(serviceRuleRoute, groupID, appID, serviceID, ruleID), RequestOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer ruleRes.Body.Close()
+
+	if ruleRes.StatusCode != http.StatusOK {
+		return nil, UnmarshalRealmError(ruleRes)
+	}
+
+	var rule struct {
+		Schema interface{} `json:"schema"`
+	}
+	if err := json.NewDecoder(ruleRes.Body).Decode(&rule); err != nil {
+		return nil, err
+	}
+	if rule.Schema == nil {
+		return nil, ErrNoCollectionSchema
+	}
+
+	return rule.Schema, nil
 }
 
 // ListSecrets list secrets for the app
@@ -844,6 +1336,158 @@ func (sc *basicRealmClient) RemoveSecretByName(groupID, appID, secretName string
 	return sc.RemoveSecretByID(groupID, appID, secretID)
 }
 
+// ListFunctions lists the functions of the app
+func (sc *basicRealmClient) ListFunctions(groupID, appID string) ([]models.Function, error) {
+	res, err := sc.ExecuteRequest(http.MethodGet, fmt.Sprintf(functionsRoute, groupID, appID), RequestOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, UnmarshalRealmError(res)
+	}
+
+	var functions []models.Function
+	if err := json.NewDecoder(res.Body).Decode(&functions); err != nil {
+		return nil, err
+	}
+
+	return functions, nil
+}
+
+// ListAuthProviders lists the auth providers of an app
+func (sc *basicRealmClient) ListAuthProviders(groupID, appID string) ([]models.AuthProvider, error) {
+	res, err := sc.ExecuteRequest(http.MethodGet, fmt.Sprintf(authProvidersRoute, groupID, appID), RequestOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, UnmarshalRealmError(res)
+	}
+
+	var authProviders []models.AuthProvider
+	if err := json.NewDecoder(res.Body).Decode(&authProviders); err != nil {
+		return nil, err
+	}
+
+	return authProviders, nil
+}
+
+// ListServices lists the services of an app, including its data sources
+func (sc *basicRealmClient) ListServices(groupID, appID string) ([]models.Service, error) {
+	res, err := sc.ExecuteRequest(http.MethodGet, fmt.Sprintf(servicesRoute, groupID, appID), RequestOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, UnmarshalRealmError(res)
+	}
+
+	var services []models.Service
+	if err := json.NewDecoder(res.Body).Decode(&services); err != nil {
+		return nil, err
+	}
+
+	return services, nil
+}
+
+// ListTemplates lists the templates which new apps can be initialized from
+func (sc *basicRealmClient) ListTemplates() ([]models.Template, error) {
+	res, err := sc.ExecuteRequest(http.MethodGet, templatesRoute, RequestOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, UnmarshalRealmError(res)
+	}
+
+	var templates []models.Template
+	if err := json.NewDecoder(res.Body).Decode(&templates); err != nil {
+		return nil, err
+	}
+
+	return templates, nil
+}
+
+// ListTriggers lists the definitions of the triggers of the app
+func (sc *basicRealmClient) ListTriggers(groupID, appID string) ([]models.Trigger, error) {
+	res, err := sc.ExecuteRequest(http.MethodGet, fmt.Sprintf(triggersRoute, groupID, appID), RequestOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, UnmarshalRealmError(res)
+	}
+
+	var triggers []models.Trigger
+	if err := json.NewDecoder(res.Body).Decode(&triggers); err != nil {
+		return nil, err
+	}
+
+	return triggers, nil
+}
+
+// CreateTrigger creates a trigger for the app
+func (sc *basicRealmClient) CreateTrigger(groupID, appID string, trigger models.Trigger) error {
+	payload, err := json.Marshal(trigger)
+	if err != nil {
+		return err
+	}
+
+	res, err := sc.ExecuteRequest(http.MethodPost, fmt.Sprintf(triggersRoute, groupID, appID), RequestOptions{Body: bytes.NewReader(payload)})
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusCreated {
+		return UnmarshalRealmError(res)
+	}
+
+	return nil
+}
+
+// UpdateTrigger replaces the definition of a trigger of the app
+func (sc *basicRealmClient) UpdateTrigger(groupID, appID, triggerID string, trigger models.Trigger) error {
+	payload, err := json.Marshal(trigger)
+	if err != nil {
+		return err
+	}
+
+	res, err := sc.ExecuteRequest(http.MethodPut, fmt.Sprintf(triggerRoute, groupID, appID, triggerID), RequestOptions{Body: bytes.NewReader(payload)})
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	return checkStatusNoContent(res, err, "failed to update trigger")
+}
+
+// SetTriggerDisabled disables or enables a trigger of the app, leaving the rest of its definition alone
+func (sc *basicRealmClient) SetTriggerDisabled(groupID, appID, triggerID string, disabled bool) error {
+	payload, err := json.Marshal(setTriggerDisabledPayload{disabled})
+	if err != nil {
+		return err
+	}
+
+	res, err := sc.ExecuteRequest(http.MethodPatch, fmt.Sprintf(triggerRoute, groupID, appID, triggerID), RequestOptions{Body: bytes.NewReader(payload)})
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	return checkStatusNoContent(res, err, "failed to update trigger")
+}
+
 func checkStatusNoContent(res *http.Response, requestErr error, errMessage string) error {
 	if requestErr != nil {
 		return requestErr
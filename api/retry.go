@@ -0,0 +1,189 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// Defaults for how a RetryClient retries requests which failed with a transient error
+const (
+	DefaultRetryAttempts = 3
+	DefaultRetryBackoff  = time.Second
+)
+
+// NewRetryClient returns a new *RetryClient. Unless idempotentOnly is false, requests which are not idempotent
+// are only retried when they could not be sent, since retrying them once the server may have received them,
+// e.g. when the connection dropped before the response, may apply their operation more than once
+func NewRetryClient(client Client, idempotentOnly bool) *RetryClient {
+	return &RetryClient{
+		Client:         client,
+		IdempotentOnly: idempotentOnly,
+		MaxAttempts:    DefaultRetryAttempts,
+		Backoff:        DefaultRetryBackoff,
+	}
+}
+
+// RetryClient is a Client that retries requests which failed with a transient error,
+// such as a dropped connection or a temporarily unavailable server
+type RetryClient struct {
+	Client
+
+	// IdempotentOnly restricts retries of requests which are neither made with an idempotent method nor
+	// marked as RequestOptions.Idempotent to those which failed before they were written to the connection
+	IdempotentOnly bool
+
+	// MaxAttempts is how many times a request is made at most
+	MaxAttempts int
+
+	// Backoff is how long to wait before retrying a request, doubled after each retry.
+	// Each wait is randomized between half and all of it, so that clients failing together do not retry together
+	Backoff time.Duration
+}
+
+// ExecuteRequest makes an HTTP request to the provided path, retrying it if it fails with a transient error
+func (rc *RetryClient) ExecuteRequest(method, path string, options RequestOptions) (*http.Response, error) {
+	if rc.MaxAttempts <= 1 {
+		return rc.Client.ExecuteRequest(method, path, options)
+	}
+	connectionFailuresOnly := rc.IdempotentOnly && !options.Idempotent && !isIdempotent(method)
+
+	// the body is buffered so that it can be sent again
+	var body []byte
+	if options.Body != nil {
+		var err error
+		if body, err = ioutil.ReadAll(options.Body); err != nil {
+			return nil, err
+		}
+	}
+
+	backoff := rc.Backoff
+	for attempt := 1; ; attempt++ {
+		if body != nil {
+			options.Body = bytes.NewReader(body)
+		}
+
+		attemptOptions := options
+		var sent *wroteRequest
+		if connectionFailuresOnly {
+			attemptOptions.Context, sent = traceWroteRequest(options.Context)
+		}
+
+		res, err := rc.Client.ExecuteRequest(method, path, attemptOptions)
+		if attempt >= rc.MaxAttempts || !isTransientFailure(res, err) || (connectionFailuresOnly && (err == nil || sent.load())) || contextDone(options.Context) {
+			return res, err
+		}
+
+		if res != nil {
+			res.Body.Close()
+		}
+
+		if !sleepContext(options.Context, jitter(backoff)) {
+			return nil, options.Context.Err()
+		}
+		backoff *= 2
+	}
+}
+
+// wroteRequest records whether a request was written to its connection, in part or in full
+type wroteRequest struct {
+	mu    sync.Mutex
+	wrote bool
+}
+
+func (w *wroteRequest) set() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.wrote = true
+}
+
+func (w *wroteRequest) load() bool {
+	if w == nil {
+		return false
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.wrote
+}
+
+// traceWroteRequest returns ctx along with what records whether the request made with it was written to its
+// connection. Once it was, the server may have received it even if the request failed
+func traceWroteRequest(ctx context.Context) (context.Context, *wroteRequest) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	sent := &wroteRequest{}
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		WroteHeaders: sent.set,
+		WroteRequest: func(httptrace.WroteRequestInfo) { sent.set() },
+	}), sent
+}
+
+// isIdempotent reports whether making a request with the method more than once has the same effect as making it once
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// isTransientFailure reports whether a request failed in a way that making it again may fix
+func isTransientFailure(res *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+
+	switch res.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// jitterRand randomizes the waits between retries. It is seeded so that separate runs of realm-cli
+// do not wait in step, and guarded as retries may happen concurrently, e.g. when uploading hosting assets
+var jitterRand = struct {
+	sync.Mutex
+	*rand.Rand
+}{Rand: rand.New(rand.NewSource(time.Now().UnixNano()))}
+
+// jitter returns a random duration between half of d and d
+func jitter(d time.Duration) time.Duration {
+	if d <= 1 {
+		return d
+	}
+
+	jitterRand.Lock()
+	defer jitterRand.Unlock()
+	return d/2 + time.Duration(jitterRand.Int63n(int64(d/2)+1))
+}
+
+func contextDone(ctx context.Context) bool {
+	return ctx != nil && ctx.Err() != nil
+}
+
+// sleepContext waits for the duration to pass, returning false if ctx is done first
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	if ctx == nil {
+		time.Sleep(d)
+		return true
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,168 @@
+package api_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/10gen/realm-cli/api"
+
+	u "github.com/10gen/realm-cli/utils/test"
+	gc "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRetryClient(t *testing.T) {
+	// newTestServer fails the first failures requests with the status, recording the body of every request
+	newTestServer := func(failures, status int) (*httptest.Server, *[]string) {
+		var bodies []string
+		testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := ioutil.ReadAll(r.Body)
+			bodies = append(bodies, string(body))
+			if len(bodies) <= failures {
+				w.WriteHeader(status)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		return testServer, &bodies
+	}
+
+	newRetryClient := func(baseURL string, idempotentOnly bool) *api.RetryClient {
+		client := api.NewRetryClient(api.NewClient(baseURL), idempotentOnly)
+		client.Backoff = 0
+		return client
+	}
+
+	for _, tc := range []struct {
+		Description      string
+		Method           string
+		IdempotentOnly   bool
+		Idempotent       bool
+		Failures         int
+		Status           int
+		ExpectedStatus   int
+		ExpectedRequests int
+	}{
+		{
+			Description:      "it retries idempotent requests which fail with a transient error",
+			Method:           http.MethodPut,
+			IdempotentOnly:   true,
+			Failures:         2,
+			Status:           http.StatusServiceUnavailable,
+			ExpectedStatus:   http.StatusOK,
+			ExpectedRequests: 3,
+		},
+		{
+			Description:      "it gives up after the maximum number of attempts",
+			Method:           http.MethodGet,
+			IdempotentOnly:   true,
+			Failures:         5,
+			Status:           http.StatusBadGateway,
+			ExpectedStatus:   http.StatusBadGateway,
+			ExpectedRequests: 3,
+		},
+		{
+			Description:      "it does not retry requests which fail with any other error",
+			Method:           http.MethodGet,
+			IdempotentOnly:   true,
+			Failures:         1,
+			Status:           http.StatusInternalServerError,
+			ExpectedStatus:   http.StatusInternalServerError,
+			ExpectedRequests: 1,
+		},
+		{
+			Description:      "it does not retry requests which are not idempotent by default",
+			Method:           http.MethodPost,
+			IdempotentOnly:   true,
+			Failures:         1,
+			Status:           http.StatusServiceUnavailable,
+			ExpectedStatus:   http.StatusServiceUnavailable,
+			ExpectedRequests: 1,
+		},
+		{
+			Description:      "it retries requests which only read data even though their method is not idempotent",
+			Method:           http.MethodPost,
+			IdempotentOnly:   true,
+			Idempotent:       true,
+			Failures:         1,
+			Status:           http.StatusServiceUnavailable,
+			ExpectedStatus:   http.StatusOK,
+			ExpectedRequests: 2,
+		},
+		{
+			Description:      "it retries requests which are not idempotent if told to",
+			Method:           http.MethodPost,
+			Failures:         1,
+			Status:           http.StatusServiceUnavailable,
+			ExpectedStatus:   http.StatusOK,
+			ExpectedRequests: 2,
+		},
+	} {
+		t.Run(tc.Description, func(t *testing.T) {
+			testServer, bodies := newTestServer(tc.Failures, tc.Status)
+			defer testServer.Close()
+
+			res, err := newRetryClient(testServer.URL, tc.IdempotentOnly).ExecuteRequest(tc.Method, "/", api.RequestOptions{Body: strings.NewReader("payload"), Idempotent: tc.Idempotent})
+			u.So(t, err, gc.ShouldBeNil)
+			u.So(t, res.StatusCode, gc.ShouldEqual, tc.ExpectedStatus)
+			u.So(t, *bodies, gc.ShouldHaveLength, tc.ExpectedRequests)
+			for _, body := range *bodies {
+				u.So(t, body, gc.ShouldEqual, "payload")
+			}
+		})
+	}
+
+	t.Run("it does not retry requests which are not idempotent once the server received them", func(t *testing.T) {
+		var bodies []string
+		testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := ioutil.ReadAll(r.Body)
+			bodies = append(bodies, string(body))
+
+			// apply the request, then drop the connection without responding
+			conn, _, err := w.(http.Hijacker).Hijack()
+			u.So(t, err, gc.ShouldBeNil)
+			conn.Close()
+		}))
+		defer testServer.Close()
+
+		_, err := newRetryClient(testServer.URL, true).ExecuteRequest(http.MethodPost, "/", api.RequestOptions{Body: strings.NewReader("payload")})
+		u.So(t, err, gc.ShouldNotBeNil)
+		u.So(t, bodies, gc.ShouldResemble, []string{"payload"})
+	})
+
+	t.Run("it retries requests which are not idempotent when they could not be sent", func(t *testing.T) {
+		var bodies []string
+		testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := ioutil.ReadAll(r.Body)
+			bodies = append(bodies, string(body))
+			w.WriteHeader(http.StatusCreated)
+		}))
+		defer testServer.Close()
+
+		var dials int
+		var dialer net.Dialer
+		transport := &http.Transport{
+			DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+				dials++
+				if dials == 1 {
+					return nil, &net.OpError{Op: "dial", Net: network, Err: errors.New("connection refused")}
+				}
+				return dialer.DialContext(ctx, network, addr)
+			},
+		}
+
+		client := api.NewRetryClient(api.NewClientWithTransport(testServer.URL, transport), true)
+		client.Backoff = 0
+
+		res, err := client.ExecuteRequest(http.MethodPost, "/", api.RequestOptions{Body: strings.NewReader("payload")})
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, res.StatusCode, gc.ShouldEqual, http.StatusCreated)
+		u.So(t, dials, gc.ShouldEqual, 2)
+		u.So(t, bodies, gc.ShouldResemble, []string{"payload"})
+	})
+}
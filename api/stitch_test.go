@@ -2,18 +2,24 @@ package api_test
 
 import (
 	"bytes"
+	"context"
 	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"mime"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/10gen/realm-cli/api"
 	"github.com/10gen/realm-cli/hosting"
@@ -178,6 +184,40 @@ func TestListAssetsForAppID(t *testing.T) {
 	})
 }
 
+func TestListAssetsForAppIDIfChanged(t *testing.T) {
+	testHandler := func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"listing-1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"listing-1"`)
+		w.Write([]byte(`[{"path":"/foo.txt","hash":"OWEJFOWEF","size":20}]`))
+	}
+	testServer := httptest.NewServer(http.HandlerFunc(testHandler))
+	testClient := api.NewRealmClient(api.NewClient(testServer.URL))
+
+	t.Run("it returns the listing along with its ETag", func(t *testing.T) {
+		assetMetadata, etag, err := testClient.ListAssetsForAppIDIfChanged(groupID, appID, "")
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, etag, gc.ShouldEqual, `"listing-1"`)
+		u.So(t, assetMetadata, gc.ShouldHaveLength, 1)
+		u.So(t, assetMetadata[0].FilePath, gc.ShouldEqual, "/foo.txt")
+	})
+
+	t.Run("it returns ErrNotModified if the listing still has the ETag", func(t *testing.T) {
+		assetMetadata, _, err := testClient.ListAssetsForAppIDIfChanged(groupID, appID, `"listing-1"`)
+		u.So(t, err, gc.ShouldEqual, api.ErrNotModified)
+		u.So(t, assetMetadata, gc.ShouldBeNil)
+	})
+
+	t.Run("it returns the listing if the ETag has changed", func(t *testing.T) {
+		assetMetadata, etag, err := testClient.ListAssetsForAppIDIfChanged(groupID, appID, `"listing-0"`)
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, etag, gc.ShouldEqual, `"listing-1"`)
+		u.So(t, assetMetadata, gc.ShouldHaveLength, 1)
+	})
+}
+
 func TestSetAssetAttributes(t *testing.T) {
 	t.Run("setting app attributes should work", func(t *testing.T) {
 		testContents := []hosting.AssetAttribute{
@@ -305,11 +345,181 @@ func TestInvalidateCache(t *testing.T) {
 		path := "foo"
 
 		testClient := api.NewRealmClient(api.NewClient(testServer.URL))
-		err := testClient.InvalidateCache(groupID, appID, path)
+		jobID, err := testClient.InvalidateCache(groupID, appID, path)
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, jobID, gc.ShouldBeEmpty)
+	})
+
+	t.Run("cache invalidation should return the id of the invalidation job", func(t *testing.T) {
+		testHandler := func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+			w.Write([]byte(`{"_id":"job-id","path":"/*","status":"in_progress"}`))
+		}
+		testServer := httptest.NewServer(http.HandlerFunc(testHandler))
+
+		testClient := api.NewRealmClient(api.NewClient(testServer.URL))
+		jobID, err := testClient.InvalidateCache(groupID, appID, "/*")
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, jobID, gc.ShouldEqual, "job-id")
+	})
+}
+
+func TestGetCacheInvalidation(t *testing.T) {
+	t.Run("should return the cache invalidation job", func(t *testing.T) {
+		testHandler := func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet || r.URL.Path != fmt.Sprintf("/api/admin/v3.0/groups/%s/apps/%s/hosting/cache/invalidations/job-id", groupID, appID) {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write([]byte(`{"_id":"job-id","path":"/*","status":"completed"}`))
+		}
+		testServer := httptest.NewServer(http.HandlerFunc(testHandler))
+
+		testClient := api.NewRealmClient(api.NewClient(testServer.URL))
+		invalidation, err := testClient.GetCacheInvalidation(groupID, appID, "job-id")
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, *invalidation, gc.ShouldResemble, models.CacheInvalidation{ID: "job-id", Path: "/*", Status: models.CacheInvalidationStatusCompleted})
+	})
+}
+
+func TestGetDependencies(t *testing.T) {
+	t.Run("should return the deployed dependencies", func(t *testing.T) {
+		testHandler := func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet || r.URL.Path != fmt.Sprintf("/api/admin/v3.0/groups/%s/apps/%s/dependencies", groupID, appID) {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte(`{"_id":"deps-id","last_modified":1600000000,"dependencies_list":[{"name":"axios","version":"0.19.2"}]}`))
+		}
+		testServer := httptest.NewServer(http.HandlerFunc(testHandler))
+
+		testClient := api.NewRealmClient(api.NewClient(testServer.URL))
+		dependencies, err := testClient.GetDependencies(groupID, appID)
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, *dependencies, gc.ShouldResemble, models.Dependencies{
+			ID:           "deps-id",
+			LastModified: 1600000000,
+			Packages:     []models.DependencyPackage{{Name: "axios", Version: "0.19.2"}},
+		})
+	})
+
+	t.Run("should report an app which never had dependencies uploaded", func(t *testing.T) {
+		testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}))
+
+		testClient := api.NewRealmClient(api.NewClient(testServer.URL))
+		_, err := testClient.GetDependencies(groupID, appID)
+		u.So(t, err, gc.ShouldEqual, api.ErrNoDependencies)
+	})
+}
+
+func TestListTemplates(t *testing.T) {
+	testHandler := func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/api/admin/v3.0/templates" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`[{"id":"todo-abcde","name":"Todo"},{"id":"chat-fghij","name":"Chat"}]`))
+	}
+	testServer := httptest.NewServer(http.HandlerFunc(testHandler))
+
+	testClient := api.NewRealmClient(api.NewClient(testServer.URL))
+	templates, err := testClient.ListTemplates()
+	u.So(t, err, gc.ShouldBeNil)
+	u.So(t, templates, gc.ShouldResemble, []models.Template{{ID: "todo-abcde", Name: "Todo"}, {ID: "chat-fghij", Name: "Chat"}})
+}
+
+func TestAppSummaryGetters(t *testing.T) {
+	testHandler := func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		switch r.URL.Path {
+		case "/api/admin/v3.0/groups/group-id/apps/app-id":
+			w.Write([]byte(`{"_id":"app-id","name":"my-app","location":"IE","deployment_model":"LOCAL"}`))
+		case "/api/admin/v3.0/groups/group-id/apps/app-id/auth_providers":
+			w.Write([]byte(`[{"_id":"1","name":"api-key","type":"api-key","disabled":true}]`))
+		case "/api/admin/v3.0/groups/group-id/apps/app-id/services":
+			w.Write([]byte(`[{"_id":"2","name":"mongodb-atlas","type":"mongodb-atlas"},{"_id":"3","name":"http","type":"http"}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}
+	testServer := httptest.NewServer(http.HandlerFunc(testHandler))
+	testClient := api.NewRealmClient(api.NewClient(testServer.URL))
+
+	app, err := testClient.GetApp("group-id", "app-id")
+	u.So(t, err, gc.ShouldBeNil)
+	u.So(t, *app, gc.ShouldResemble, models.App{ID: "app-id", Name: "my-app", Location: "IE", DeploymentModel: "LOCAL"})
+
+	authProviders, err := testClient.ListAuthProviders("group-id", "app-id")
+	u.So(t, err, gc.ShouldBeNil)
+	u.So(t, authProviders, gc.ShouldResemble, []models.AuthProvider{{ID: "1", Name: "api-key", Type: "api-key", Disabled: true}})
+
+	services, err := testClient.ListServices("group-id", "app-id")
+	u.So(t, err, gc.ShouldBeNil)
+	u.So(t, services, gc.ShouldHaveLength, 2)
+	u.So(t, services[0].IsDataSource(), gc.ShouldBeTrue)
+	u.So(t, services[1].IsDataSource(), gc.ShouldBeFalse)
+}
+
+func TestGetCollectionSchema(t *testing.T) {
+	testHandler := func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		switch r.URL.Path {
+		case "/api/admin/v3.0/groups/group-id/apps/app-id/services/svc-id/rules":
+			w.Write([]byte(`[{"_id":"rule-1","database":"store","collection":"items"},{"_id":"rule-2","database":"store","collection":"orders"}]`))
+		case "/api/admin/v3.0/groups/group-id/apps/app-id/services/svc-id/rules/rule-1":
+			w.Write([]byte(`{"_id":"rule-1","database":"store","collection":"items","schema":{"properties":{"name":{"bsonType":"string"}}}}`))
+		case "/api/admin/v3.0/groups/group-id/apps/app-id/services/svc-id/rules/rule-2":
+			w.Write([]byte(`{"_id":"rule-2","database":"store","collection":"orders"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}
+	testServer := httptest.NewServer(http.HandlerFunc(testHandler))
+	testClient := api.NewRealmClient(api.NewClient(testServer.URL))
+
+	t.Run("should fetch the schema from the rules of the collection", func(t *testing.T) {
+		schema, err := testClient.GetCollectionSchema("group-id", "app-id", "svc-id", "store", "items")
 		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, schema, gc.ShouldResemble, map[string]interface{}{
+			"properties": map[string]interface{}{"name": map[string]interface{}{"bsonType": "string"}},
+		})
+	})
+
+	t.Run("should report a collection whose rules define no schema", func(t *testing.T) {
+		_, err := testClient.GetCollectionSchema("group-id", "app-id", "svc-id", "store", "orders")
+		u.So(t, err, gc.ShouldEqual, api.ErrNoCollectionSchema)
+	})
+
+	t.Run("should report a collection without rules", func(t *testing.T) {
+		_, err := testClient.GetCollectionSchema("group-id", "app-id", "svc-id", "store", "customers")
+		u.So(t, err, gc.ShouldEqual, api.ErrNoCollectionSchema)
 	})
 }
 
+func TestGetUserProfile(t *testing.T) {
+	testHandler := func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/api/admin/v3.0/auth/profile" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"roles":[{"group_id":"group-1"},{"role_name":"GROUP_OWNER"},{"group_id":"group-2"}]}`))
+	}
+	testServer := httptest.NewServer(http.HandlerFunc(testHandler))
+
+	testClient := api.NewRealmClient(api.NewClient(testServer.URL))
+	profile, err := testClient.GetUserProfile()
+	u.So(t, err, gc.ShouldBeNil)
+	u.So(t, profile.AllGroupIDs(), gc.ShouldResemble, []string{"group-1", "group-2"})
+}
+
 func TestRequestOrigin(t *testing.T) {
 	t.Run("the request origin header should be set", func(t *testing.T) {
 		testHandler := func(w http.ResponseWriter, r *http.Request) {
@@ -358,6 +568,75 @@ func TestFetchAppByGroupIDAndClientAppID(t *testing.T) {
 	})
 }
 
+func TestFindApps(t *testing.T) {
+	testHandler := func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/admin/v3.0/auth/profile":
+			w.Write([]byte(`{"roles":[{"group_id":"group-1"},{"group_id":"group-2"},{"group_id":"group-1"}]}`))
+		case "/api/admin/v3.0/groups/group-1/apps":
+			if r.URL.Query().Get("product") == "atlas" {
+				w.Write([]byte(`[{"_id":"app-3","client_app_id":"triggers-abcde","group_id":"group-1"}]`))
+				return
+			}
+			w.Write([]byte(`[{"_id":"app-1","client_app_id":"my-app-abcde","group_id":"group-1","name":"my-app"},{"_id":"app-2","client_app_id":"other-app-abcde","group_id":"group-1","name":"other-app"}]`))
+		case "/api/admin/v3.0/groups/group-2/apps":
+			if r.URL.Query().Get("product") == "atlas" {
+				w.Write([]byte(`[]`))
+				return
+			}
+			w.Write([]byte(`[{"_id":"app-4","client_app_id":"my-app-abcde","group_id":"group-2"}]`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}
+	testServer := httptest.NewServer(http.HandlerFunc(testHandler))
+	testClient := api.NewRealmClient(api.NewClient(testServer.URL))
+
+	appIDs := func(apps []*models.App) []string {
+		ids := make([]string, len(apps))
+		for i, app := range apps {
+			ids[i] = app.ID
+		}
+		return ids
+	}
+
+	t.Run("should find every app with the client app id across the projects of the user", func(t *testing.T) {
+		apps, err := testClient.FindApps(api.AppFilter{ClientAppID: "my-app-abcde"})
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, appIDs(apps), gc.ShouldResemble, []string{"app-1", "app-4"})
+	})
+
+	t.Run("should only look in the given project", func(t *testing.T) {
+		apps, err := testClient.FindApps(api.AppFilter{GroupID: "group-2", ClientAppID: "my-app-abcde"})
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, appIDs(apps), gc.ShouldResemble, []string{"app-4"})
+	})
+
+	t.Run("should include the apps of Atlas triggers and match every app without a client app id", func(t *testing.T) {
+		apps, err := testClient.FindApps(api.AppFilter{GroupID: "group-1"})
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, appIDs(apps), gc.ShouldResemble, []string{"app-1", "app-2", "app-3"})
+	})
+
+	t.Run("should find an app by its id", func(t *testing.T) {
+		apps, err := testClient.FindApps(api.AppFilter{AppID: "app-4"})
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, appIDs(apps), gc.ShouldResemble, []string{"app-4"})
+	})
+
+	t.Run("should find an app by its name", func(t *testing.T) {
+		apps, err := testClient.FindApps(api.AppFilter{GroupID: "group-1", Name: "other-app"})
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, appIDs(apps), gc.ShouldResemble, []string{"app-2"})
+	})
+
+	t.Run("should find nothing for an unknown client app id", func(t *testing.T) {
+		apps, err := testClient.FindApps(api.AppFilter{ClientAppID: "missing-abcde"})
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, apps, gc.ShouldBeEmpty)
+	})
+}
+
 func TestCreateDraft(t *testing.T) {
 	t.Run("CreateDraft should work", func(t *testing.T) {
 		testHandler := func(w http.ResponseWriter, r *http.Request) {
@@ -425,6 +704,52 @@ func TestGetDeployment(t *testing.T) {
 	})
 }
 
+func TestListDeployments(t *testing.T) {
+	t.Run("ListDeployments should work", func(t *testing.T) {
+		testHandler := func(w http.ResponseWriter, r *http.Request) {
+			u.So(t, r.URL.Path, gc.ShouldEqual, "/api/admin/v3.0/groups/groupID/apps/appID/deployments")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`[{ "_id": "test", "status": "successful", "draft_id": "draft", "created_at": 1600000000 }]`))
+		}
+
+		testServer := httptest.NewServer(http.HandlerFunc(testHandler))
+		testClient := api.NewRealmClient(api.NewClient(testServer.URL))
+		deployments, err := testClient.ListDeployments(groupID, appID)
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, deployments, gc.ShouldResemble, []models.Deployment{
+			{ID: "test", Status: models.DeploymentStatusSuccessful, DraftID: "draft", CreatedAt: 1600000000},
+		})
+	})
+}
+
+func TestListLogs(t *testing.T) {
+	t.Run("ListLogs should filter the logs as told", func(t *testing.T) {
+		testHandler := func(w http.ResponseWriter, r *http.Request) {
+			u.So(t, r.URL.Path, gc.ShouldEqual, "/api/admin/v3.0/groups/groupID/apps/appID/logs")
+			u.So(t, r.URL.Query(), gc.ShouldResemble, url.Values{
+				"type":        {"FUNCTION,AUTH"},
+				"errors_only": {"true"},
+				"start_date":  {"2020-09-13T12:26:40Z"},
+			})
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{ "logs": [{ "_id": "test", "type": "FUNCTION", "function_name": "sum", "started": "2020-09-13T12:30:00.5Z", "logs": ["adding"] }] }`))
+		}
+
+		testServer := httptest.NewServer(http.HandlerFunc(testHandler))
+		testClient := api.NewRealmClient(api.NewClient(testServer.URL))
+		logs, err := testClient.ListLogs(groupID, appID, api.LogsOptions{
+			Types:      []string{"FUNCTION", "AUTH"},
+			ErrorsOnly: true,
+			Start:      time.Unix(1600000000, 0),
+		})
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, logs, gc.ShouldHaveLength, 1)
+		u.So(t, logs[0].FunctionName, gc.ShouldEqual, "sum")
+		u.So(t, logs[0].Messages, gc.ShouldResemble, []string{"adding"})
+		u.So(t, logs[0].Started.Equal(time.Date(2020, 9, 13, 12, 30, 0, 5e8, time.UTC)), gc.ShouldBeTrue)
+	})
+}
+
 func TestGetDrafts(t *testing.T) {
 	t.Run("GetDrafts should work", func(t *testing.T) {
 		testHandler := func(w http.ResponseWriter, r *http.Request) {
@@ -461,6 +786,107 @@ func TestDraftDiff(t *testing.T) {
 	})
 }
 
+func TestExecuteFunction(t *testing.T) {
+	t.Run("ExecuteFunction should work", func(t *testing.T) {
+		testHandler := func(w http.ResponseWriter, r *http.Request) {
+			u.So(t, r.URL.Path, gc.ShouldEqual, "/api/admin/v3.0/groups/groupID/apps/appID/debug/execute_function")
+			u.So(t, r.URL.Query().Get("run_as_system"), gc.ShouldEqual, "true")
+
+			var payload map[string]interface{}
+			u.So(t, json.NewDecoder(r.Body).Decode(&payload), gc.ShouldBeNil)
+			u.So(t, payload, gc.ShouldResemble, map[string]interface{}{"name": "sum", "arguments": []interface{}{1.0, 2.0}})
+
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{ "result": { "total": 3 }, "logs": ["adding"], "error_logs": null }`))
+		}
+
+		testServer := httptest.NewServer(http.HandlerFunc(testHandler))
+		testClient := api.NewRealmClient(api.NewClient(testServer.URL))
+		execution, err := testClient.ExecuteFunction(context.Background(), groupID, appID, "sum", []interface{}{1, 2})
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, execution.Result, gc.ShouldResemble, map[string]interface{}{"total": 3.0})
+		u.So(t, execution.Logs, gc.ShouldResemble, []string{"adding"})
+	})
+
+	t.Run("ExecuteFunction should decode the error of a function which throws", func(t *testing.T) {
+		testHandler := func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{ "result": null, "logs": null, "error": "out of stock", "error_code": "FunctionExecutionError" }`))
+		}
+
+		testServer := httptest.NewServer(http.HandlerFunc(testHandler))
+		testClient := api.NewRealmClient(api.NewClient(testServer.URL))
+		execution, err := testClient.ExecuteFunction(context.Background(), groupID, appID, "checkout", nil)
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, execution.Error, gc.ShouldEqual, "out of stock")
+		u.So(t, execution.ErrorCode, gc.ShouldEqual, "FunctionExecutionError")
+	})
+}
+
+func TestTriggers(t *testing.T) {
+	t.Run("ListTriggers should work", func(t *testing.T) {
+		testHandler := func(w http.ResponseWriter, r *http.Request) {
+			u.So(t, r.Method, gc.ShouldEqual, http.MethodGet)
+			u.So(t, r.URL.Path, gc.ShouldEqual, "/api/admin/v3.0/groups/groupID/apps/appID/triggers")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`[{ "_id": "123", "name": "nightly", "function_name": "cleanup", "event_processors": {} }]`))
+		}
+
+		testServer := httptest.NewServer(http.HandlerFunc(testHandler))
+		testClient := api.NewRealmClient(api.NewClient(testServer.URL))
+		triggers, err := testClient.ListTriggers(groupID, appID)
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, triggers, gc.ShouldHaveLength, 1)
+		u.So(t, triggers[0].ID(), gc.ShouldEqual, "123")
+		u.So(t, triggers[0].FunctionName(), gc.ShouldEqual, "cleanup")
+		u.So(t, triggers[0], gc.ShouldContainKey, "event_processors")
+	})
+
+	t.Run("CreateTrigger should work", func(t *testing.T) {
+		testHandler := func(w http.ResponseWriter, r *http.Request) {
+			u.So(t, r.Method, gc.ShouldEqual, http.MethodPost)
+			u.So(t, r.URL.Path, gc.ShouldEqual, "/api/admin/v3.0/groups/groupID/apps/appID/triggers")
+
+			var trigger models.Trigger
+			u.So(t, json.NewDecoder(r.Body).Decode(&trigger), gc.ShouldBeNil)
+			u.So(t, trigger.Name(), gc.ShouldEqual, "nightly")
+			w.WriteHeader(http.StatusCreated)
+		}
+
+		testServer := httptest.NewServer(http.HandlerFunc(testHandler))
+		testClient := api.NewRealmClient(api.NewClient(testServer.URL))
+		u.So(t, testClient.CreateTrigger(groupID, appID, models.Trigger{"name": "nightly"}), gc.ShouldBeNil)
+	})
+
+	t.Run("UpdateTrigger should work", func(t *testing.T) {
+		testHandler := func(w http.ResponseWriter, r *http.Request) {
+			u.So(t, r.Method, gc.ShouldEqual, http.MethodPut)
+			u.So(t, r.URL.Path, gc.ShouldEqual, "/api/admin/v3.0/groups/groupID/apps/appID/triggers/123")
+			w.WriteHeader(http.StatusNoContent)
+		}
+
+		testServer := httptest.NewServer(http.HandlerFunc(testHandler))
+		testClient := api.NewRealmClient(api.NewClient(testServer.URL))
+		u.So(t, testClient.UpdateTrigger(groupID, appID, "123", models.Trigger{"_id": "123", "name": "nightly"}), gc.ShouldBeNil)
+	})
+
+	t.Run("SetTriggerDisabled should only send the disabled field", func(t *testing.T) {
+		testHandler := func(w http.ResponseWriter, r *http.Request) {
+			u.So(t, r.Method, gc.ShouldEqual, http.MethodPatch)
+			u.So(t, r.URL.Path, gc.ShouldEqual, "/api/admin/v3.0/groups/groupID/apps/appID/triggers/123")
+
+			body, err := ioutil.ReadAll(r.Body)
+			u.So(t, err, gc.ShouldBeNil)
+			u.So(t, string(body), gc.ShouldEqual, `{"disabled":true}`)
+			w.WriteHeader(http.StatusNoContent)
+		}
+
+		testServer := httptest.NewServer(http.HandlerFunc(testHandler))
+		testClient := api.NewRealmClient(api.NewClient(testServer.URL))
+		u.So(t, testClient.SetTriggerDisabled(groupID, appID, "123", true), gc.ShouldBeNil)
+	})
+}
+
 func TestUploadDependencies(t *testing.T) {
 	t.Run("uploading dependencies should work", func(t *testing.T) {
 		path, pathErr := filepath.Abs("../testdata/app_with_dependencies/functions/node_modules.tar")
@@ -497,3 +923,137 @@ func TestUploadDependencies(t *testing.T) {
 		u.So(t, uploadedFileData, gc.ShouldResemble, expectedFileData)
 	})
 }
+
+func TestExport(t *testing.T) {
+	archive := []byte("archive")
+	checksum := sha256.Sum256(archive)
+
+	export := func(t *testing.T, digest string) (string, []byte, error) {
+		testHandler := func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Disposition", `attachment; filename="my-app_20200101.zip"`)
+			if digest != "" {
+				w.Header().Set("Digest", digest)
+			}
+			w.WriteHeader(http.StatusOK)
+			w.Write(archive)
+		}
+
+		testServer := httptest.NewServer(http.HandlerFunc(testHandler))
+		testClient := api.NewRealmClient(api.NewClient(testServer.URL))
+		filename, body, err := testClient.Export(groupID, appID, api.ExportStrategyNone)
+		if err != nil {
+			return "", nil, err
+		}
+		defer body.Close()
+
+		data, err := ioutil.ReadAll(body)
+		return filename, data, err
+	}
+
+	t.Run("Export should return the archive along with its filename", func(t *testing.T) {
+		filename, data, err := export(t, "")
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, filename, gc.ShouldEqual, "my-app_20200101.zip")
+		u.So(t, data, gc.ShouldResemble, archive)
+	})
+
+	t.Run("Export should accept an archive matching its checksum", func(t *testing.T) {
+		_, data, err := export(t, "MD5=bm90IGNoZWNrZWQ=, SHA-256="+base64.StdEncoding.EncodeToString(checksum[:]))
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, data, gc.ShouldResemble, archive)
+	})
+
+	t.Run("Export should reject an archive which does not match its checksum", func(t *testing.T) {
+		otherChecksum := sha256.Sum256([]byte("arch"))
+		_, _, err := export(t, "SHA-256="+base64.StdEncoding.EncodeToString(otherChecksum[:]))
+		u.So(t, err, gc.ShouldEqual, api.ErrExportChecksumMismatch)
+	})
+
+	t.Run("Export should reject an invalid checksum", func(t *testing.T) {
+		_, _, err := export(t, "SHA-256=nope")
+		u.So(t, err, gc.ShouldBeError, `the app export response has an invalid Digest header: "SHA-256=nope"`)
+	})
+}
+
+func TestExportDependencies(t *testing.T) {
+	t.Run("ExportDependencies should return the archive along with its filename", func(t *testing.T) {
+		testHandler := func(w http.ResponseWriter, r *http.Request) {
+			u.So(t, r.Method, gc.ShouldEqual, http.MethodGet)
+			u.So(t, r.URL.Path, gc.ShouldEqual, "/api/admin/v3.0/groups/groupID/apps/appID/dependencies/archive")
+
+			w.Header().Set("Content-Disposition", `attachment; filename="node_modules.tar.gz"`)
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("archive"))
+		}
+
+		testServer := httptest.NewServer(http.HandlerFunc(testHandler))
+		testClient := api.NewRealmClient(api.NewClient(testServer.URL))
+		filename, body, err := testClient.ExportDependencies(groupID, appID)
+		u.So(t, err, gc.ShouldBeNil)
+		defer body.Close()
+
+		data, err := ioutil.ReadAll(body)
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, filename, gc.ShouldEqual, "node_modules.tar.gz")
+		u.So(t, string(data), gc.ShouldEqual, "archive")
+	})
+
+	t.Run("ExportDependencies should report an app without dependencies", func(t *testing.T) {
+		testHandler := func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": "no dependencies found"}`))
+		}
+
+		testServer := httptest.NewServer(http.HandlerFunc(testHandler))
+		testClient := api.NewRealmClient(api.NewClient(testServer.URL))
+		_, _, err := testClient.ExportDependencies(groupID, appID)
+		u.So(t, err, gc.ShouldEqual, api.ErrNoDependencies)
+	})
+}
+
+func TestDeleteApp(t *testing.T) {
+	t.Run("DeleteApp should work", func(t *testing.T) {
+		testHandler := func(w http.ResponseWriter, r *http.Request) {
+			u.So(t, r.Method, gc.ShouldEqual, http.MethodDelete)
+			u.So(t, r.URL.Path, gc.ShouldEqual, "/api/admin/v3.0/groups/groupID/apps/appID")
+			w.WriteHeader(http.StatusNoContent)
+		}
+
+		testServer := httptest.NewServer(http.HandlerFunc(testHandler))
+		testClient := api.NewRealmClient(api.NewClient(testServer.URL))
+		err := testClient.DeleteApp(groupID, appID)
+		u.So(t, err, gc.ShouldBeNil)
+	})
+
+	t.Run("DeleteApp should return the error of a failed deletion", func(t *testing.T) {
+		testHandler := func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"error": "not allowed"}`))
+		}
+
+		testServer := httptest.NewServer(http.HandlerFunc(testHandler))
+		testClient := api.NewRealmClient(api.NewClient(testServer.URL))
+		err := testClient.DeleteApp(groupID, appID)
+		u.So(t, err, gc.ShouldNotBeNil)
+		u.So(t, err.Error(), gc.ShouldContainSubstring, "not allowed")
+	})
+}
+
+func TestRenameApp(t *testing.T) {
+	t.Run("RenameApp should send the new name", func(t *testing.T) {
+		testHandler := func(w http.ResponseWriter, r *http.Request) {
+			u.So(t, r.Method, gc.ShouldEqual, http.MethodPatch)
+			u.So(t, r.URL.Path, gc.ShouldEqual, "/api/admin/v3.0/groups/groupID/apps/appID")
+
+			body, err := ioutil.ReadAll(r.Body)
+			u.So(t, err, gc.ShouldBeNil)
+			u.So(t, string(body), gc.ShouldEqual, `{"name":"new-name"}`)
+			w.WriteHeader(http.StatusNoContent)
+		}
+
+		testServer := httptest.NewServer(http.HandlerFunc(testHandler))
+		testClient := api.NewRealmClient(api.NewClient(testServer.URL))
+		err := testClient.RenameApp(groupID, appID, "new-name")
+		u.So(t, err, gc.ShouldBeNil)
+	})
+}
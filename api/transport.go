@@ -0,0 +1,35 @@
+package api
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// NewTransport returns the http.RoundTripper requests are made with. Requests go through the proxy at proxyURL
+// if it is given, or else through the one set by the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment
+// variables. TLS certificates are verified unless insecure is set, e.g. for a proxy with a self-signed certificate
+func NewTransport(proxyURL string, insecure bool) (http.RoundTripper, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+
+	if proxyURL != "" {
+		proxy, err := url.Parse(proxyURL)
+		if err != nil || proxy.Host == "" {
+			return nil, fmt.Errorf("%q is not a valid proxy URL", proxyURL)
+		}
+		switch proxy.Scheme {
+		case "http", "https", "socks5":
+		default:
+			return nil, fmt.Errorf("%q is not a valid proxy URL; accepted schemes are [http|https|socks5]", proxyURL)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	if insecure {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} // nolint: gosec
+	}
+
+	return transport, nil
+}
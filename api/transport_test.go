@@ -0,0 +1,58 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/10gen/realm-cli/api"
+
+	u "github.com/10gen/realm-cli/utils/test"
+	gc "github.com/smartystreets/goconvey/convey"
+)
+
+func TestNewTransport(t *testing.T) {
+	t.Run("should make requests through the proxy given", func(t *testing.T) {
+		var proxiedHost string
+		proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			proxiedHost = r.URL.Host
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer proxy.Close()
+
+		transport, err := api.NewTransport(proxy.URL, false)
+		u.So(t, err, gc.ShouldBeNil)
+
+		res, err := api.NewClientWithTransport("http://realm.example.com", transport).ExecuteRequest(http.MethodGet, "/api/admin/v3.0/auth/profile", api.RequestOptions{})
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, res.StatusCode, gc.ShouldEqual, http.StatusNoContent)
+		u.So(t, proxiedHost, gc.ShouldEqual, "realm.example.com")
+	})
+
+	t.Run("should reject an invalid proxy URL", func(t *testing.T) {
+		_, err := api.NewTransport("ftp://proxy.example.com", false)
+		u.So(t, err, gc.ShouldNotBeNil)
+		u.So(t, err.Error(), gc.ShouldContainSubstring, `"ftp://proxy.example.com" is not a valid proxy URL`)
+
+		_, err = api.NewTransport("proxy.example.com:3128", false)
+		u.So(t, err, gc.ShouldNotBeNil)
+	})
+
+	t.Run("should verify TLS certificates unless insecure is set", func(t *testing.T) {
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		transport, err := api.NewTransport("", false)
+		u.So(t, err, gc.ShouldBeNil)
+		_, err = api.NewClientWithTransport(server.URL, transport).ExecuteRequest(http.MethodGet, "/", api.RequestOptions{})
+		u.So(t, err, gc.ShouldNotBeNil)
+
+		transport, err = api.NewTransport("", true)
+		u.So(t, err, gc.ShouldBeNil)
+		res, err := api.NewClientWithTransport(server.URL, transport).ExecuteRequest(http.MethodGet, "/", api.RequestOptions{})
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, res.StatusCode, gc.ShouldEqual, http.StatusNoContent)
+	})
+}
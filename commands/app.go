@@ -0,0 +1,1110 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/10gen/realm-cli/api"
+	"github.com/10gen/realm-cli/models"
+	u "github.com/10gen/realm-cli/user"
+	"github.com/10gen/realm-cli/utils"
+
+	"github.com/mitchellh/cli"
+	"github.com/mitchellh/go-homedir"
+)
+
+const (
+	appFlagDryRun        = "dry-run"
+	appFlagTo            = "to"
+	appFlagFrom          = "from"
+	appFlagListTemplates = "list-templates"
+	appFlagLocal         = "local"
+	appFlagGlobal        = "global"
+	appFlagOutput        = "output"
+	appFlagName          = "name"
+	appFlagFromGit       = "from-git"
+	appFlagGitPath       = "git-path"
+
+	appOutputText = "text"
+	appOutputJSON = "json"
+
+	// emptyAppTemplate is the template to choose for an app with nothing in it yet
+	emptyAppTemplate = "empty"
+
+	maxAppNameLength = 32
+)
+
+var (
+	errAppDeleteAppIDRequired   = fmt.Errorf("an App ID (--%s=[string]) must be supplied to delete an app", flagAppIDName)
+	errAppRenameAppIDRequired   = fmt.Errorf("an App ID (--%s=[string]) must be supplied to rename an app", flagAppIDName)
+	errAppRenameToRequired      = fmt.Errorf("a new app name (--%s=[string]) must be supplied", appFlagTo)
+	errAppDescribeAppIDRequired = fmt.Errorf("an App ID (--%s=[string]) must be supplied to describe an app", flagAppIDName)
+	errAppInitNameRequired      = fmt.Errorf("an app name (--%s=[string]) must be supplied to initialize an empty app with --yes", importFlagAppName)
+
+	appNamePattern = regexp.MustCompile("^[a-zA-Z0-9_-]+$")
+)
+
+// validateAppName checks the name against the rules Realm applies to app names
+func validateAppName(name string) error {
+	if len(name) > maxAppNameLength {
+		return fmt.Errorf("app name %q is too long, it can be at most %d characters", name, maxAppNameLength)
+	}
+	if !appNamePattern.MatchString(name) {
+		return fmt.Errorf("app name %q is invalid, it can only contain ASCII letters, numbers, underscores and hyphens", name)
+	}
+	return nil
+}
+
+// NewAppCommandFactory returns a new cli.CommandFactory given a cli.Ui
+func NewAppCommandFactory(ui cli.Ui) cli.CommandFactory {
+	return func() (cli.Command, error) {
+		return &AppCommand{
+			BaseCommand: &BaseCommand{
+				Name: "app",
+				UI:   ui,
+			},
+		}, nil
+	}
+}
+
+// AppCommand is used to manage Realm Apps
+type AppCommand struct {
+	*BaseCommand
+}
+
+// Synopsis returns a one-liner description for this command
+func (ac *AppCommand) Synopsis() string {
+	return "Manage your Realm Apps."
+}
+
+// Help returns long-form help information for this command
+func (ac *AppCommand) Help() string {
+	return ac.Synopsis()
+}
+
+// Run executes the command
+func (ac *AppCommand) Run(args []string) int {
+	return cli.RunResultHelp
+}
+
+// NewAppDeleteCommandFactory returns a new cli.CommandFactory given a cli.Ui
+func NewAppDeleteCommandFactory(ui cli.Ui) cli.CommandFactory {
+	return func() (cli.Command, error) {
+		workingDirectory, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+
+		return &AppDeleteCommand{
+			BaseCommand: &BaseCommand{
+				Name: "delete",
+				UI:   ui,
+			},
+			workingDirectory: workingDirectory,
+		}, nil
+	}
+}
+
+// AppDeleteCommand is used to delete a deployed Realm App
+type AppDeleteCommand struct {
+	*BaseCommand
+
+	workingDirectory string
+
+	flagAppID     string
+	flagAppPath   string
+	flagProjectID string
+	flagDryRun    bool
+}
+
+// Synopsis returns a one-liner description for this command
+func (adc *AppDeleteCommand) Synopsis() string {
+	return "Delete a Realm App."
+}
+
+// Help returns long-form help information for this command
+func (adc *AppDeleteCommand) Help() string {
+	return `Delete a deployed Realm Application along with all of its configuration, hosted files and dependencies.
+This cannot be undone. You are asked to confirm deleting the app unless -y is set.
+
+Usage: realm-cli app delete [options]
+
+REQUIRED:
+  --app-id [string]
+	The App ID for your app (i.e. the name of your app followed by a unique suffix, like "my-app-nysja").
+	Required if not being run from within a realm project directory.
+
+OPTIONS:
+  --path [string]
+	A path to the local directory containing your app, used to look up its App ID.
+
+  --project-id [string]
+	The Atlas Project ID.
+
+  --dry-run
+	Print the app which would be deleted without deleting it.
+	` +
+		adc.BaseCommand.Help()
+}
+
+// Run executes the command
+func (adc *AppDeleteCommand) Run(args []string) int {
+	flags := adc.NewFlagSet()
+
+	flags.StringVar(&adc.flagAppID, flagAppIDName, "", "")
+	flags.StringVar(&adc.flagAppPath, importFlagPath, "", "")
+	flags.StringVar(&adc.flagProjectID, flagProjectIDName, "", "")
+	flags.BoolVar(&adc.flagDryRun, appFlagDryRun, false, "")
+
+	if err := adc.BaseCommand.run(args); err != nil {
+		adc.UI.Error(err.Error())
+		return 1
+	}
+
+	if err := adc.deleteApp(); err != nil {
+		adc.UI.Error(err.Error())
+		return 1
+	}
+
+	return 0
+}
+
+func (adc *AppDeleteCommand) deleteApp() error {
+	_, app, realmClient, err := adc.resolveApp(appTarget{
+		appID:            adc.flagAppID,
+		appPath:          adc.flagAppPath,
+		workingDirectory: adc.workingDirectory,
+		projectID:        adc.flagProjectID,
+		errAppIDRequired: errAppDeleteAppIDRequired,
+	})
+	if err != nil {
+		return err
+	}
+
+	description := fmt.Sprintf("app %s (%s) in project %s", app.Name, app.ClientAppID, app.GroupID)
+	if adc.flagDryRun {
+		adc.UI.Info(fmt.Sprintf("Would delete the %s", description))
+		return nil
+	}
+
+	confirm, err := adc.AskYesNo(fmt.Sprintf("Are you sure you want to delete the %s? This cannot be undone", description))
+	if err != nil {
+		return err
+	}
+	if !confirm {
+		return nil
+	}
+
+	if err := realmClient.DeleteApp(app.GroupID, app.ID); err != nil {
+		return fmt.Errorf("failed to delete app %s: %s", app.ClientAppID, err)
+	}
+
+	adc.UI.Info(fmt.Sprintf("Deleted the %s", description))
+	return nil
+}
+
+// NewAppRenameCommandFactory returns a new cli.CommandFactory given a cli.Ui
+func NewAppRenameCommandFactory(ui cli.Ui) cli.CommandFactory {
+	return func() (cli.Command, error) {
+		workingDirectory, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+
+		return &AppRenameCommand{
+			BaseCommand: &BaseCommand{
+				Name: "rename",
+				UI:   ui,
+			},
+			workingDirectory: workingDirectory,
+			writeAppConfigToFile: func(dest string, app models.AppInstanceData) error {
+				return app.MarshalFile(dest)
+			},
+		}, nil
+	}
+}
+
+// AppRenameCommand is used to change the name of a deployed Realm App
+type AppRenameCommand struct {
+	*BaseCommand
+
+	workingDirectory     string
+	writeAppConfigToFile func(dest string, app models.AppInstanceData) error
+
+	flagAppID     string
+	flagAppPath   string
+	flagProjectID string
+	flagTo        string
+}
+
+// Synopsis returns a one-liner description for this command
+func (arc *AppRenameCommand) Synopsis() string {
+	return "Change the name of a Realm App."
+}
+
+// Help returns long-form help information for this command
+func (arc *AppRenameCommand) Help() string {
+	return `Change the name of a deployed Realm Application. Its App ID stays the same.
+When run for the app in a local directory, the name in its config is changed as well, so that
+the local app and the deployed app keep matching.
+
+Usage: realm-cli app rename --to [string] [options]
+
+REQUIRED:
+  --to [string]
+	The new name of the app. It can contain up to 32 ASCII letters, numbers, underscores and hyphens.
+
+  --app-id [string]
+	The App ID for your app (i.e. the name of your app followed by a unique suffix, like "my-app-nysja").
+	Required if not being run from within a realm project directory.
+
+OPTIONS:
+  --path [string]
+	A path to the local directory containing your app, used to look up its App ID.
+
+  --project-id [string]
+	The Atlas Project ID.
+	` +
+		arc.BaseCommand.Help()
+}
+
+// Run executes the command
+func (arc *AppRenameCommand) Run(args []string) int {
+	flags := arc.NewFlagSet()
+
+	flags.StringVar(&arc.flagAppID, flagAppIDName, "", "")
+	flags.StringVar(&arc.flagAppPath, importFlagPath, "", "")
+	flags.StringVar(&arc.flagProjectID, flagProjectIDName, "", "")
+	flags.StringVar(&arc.flagTo, appFlagTo, "", "")
+
+	if err := arc.BaseCommand.run(args); err != nil {
+		arc.UI.Error(err.Error())
+		return 1
+	}
+
+	if err := arc.renameApp(); err != nil {
+		arc.UI.Error(err.Error())
+		return 1
+	}
+
+	return 0
+}
+
+func (arc *AppRenameCommand) renameApp() error {
+	if arc.flagTo == "" {
+		return errAppRenameToRequired
+	}
+
+	if err := validateAppName(arc.flagTo); err != nil {
+		return err
+	}
+
+	user, err := arc.User()
+	if err != nil {
+		return err
+	}
+
+	if !user.LoggedIn() {
+		return u.ErrNotLoggedIn
+	}
+
+	// the local app is only renamed along with the deployed app it belongs to
+	var appPath string
+	var appInstanceData models.AppInstanceData
+	if path, err := utils.ResolveAppDirectory(arc.flagAppPath, arc.workingDirectory); err == nil {
+		if data, err := utils.ResolveAppInstanceData("", path); err == nil {
+			if arc.flagAppID == "" || arc.flagAppID == data.AppID() {
+				appPath, appInstanceData = path, data
+			}
+		}
+	}
+
+	appID := arc.flagAppID
+	if appID == "" {
+		appID = appInstanceData.AppID()
+	}
+	if appID == "" {
+		return errAppRenameAppIDRequired
+	}
+
+	realmClient, err := arc.RealmClient()
+	if err != nil {
+		return err
+	}
+
+	app, err := fetchApp(realmClient, arc.flagProjectID, appID)
+	if err != nil {
+		return err
+	}
+
+	if err := realmClient.RenameApp(app.GroupID, app.ID, arc.flagTo); err != nil {
+		return fmt.Errorf("failed to rename app %s: %s", app.ClientAppID, err)
+	}
+	arc.UI.Info(fmt.Sprintf("Renamed app %s from %s to %s", app.ClientAppID, app.Name, arc.flagTo))
+
+	if appPath == "" {
+		return nil
+	}
+
+	appInstanceData[models.AppNameField] = arc.flagTo
+	if err := arc.writeAppConfigToFile(appPath, appInstanceData); err != nil {
+		return fmt.Errorf("failed to update the name of the app in %s: %s", appPath, err)
+	}
+	arc.UI.Info(fmt.Sprintf("Updated the name of the app in %s", appPath))
+	return nil
+}
+
+// NewAppInitCommandFactory returns a new cli.CommandFactory given a cli.Ui
+func NewAppInitCommandFactory(ui cli.Ui) cli.CommandFactory {
+	return func() (cli.Command, error) {
+		workingDirectory, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+
+		return &AppInitCommand{
+			BaseCommand: &BaseCommand{
+				Name: "init",
+				UI:   ui,
+			},
+			workingDirectory:  workingDirectory,
+			exportToDirectory: utils.WriteZipToDir,
+			gitClone:          shallowCloneGit,
+		}, nil
+	}
+}
+
+// AppInitCommand is used to start a new local Realm App from a template
+type AppInitCommand struct {
+	*BaseCommand
+
+	workingDirectory  string
+	exportToDirectory func(dest string, zipData io.Reader, overwrite bool) error
+	gitClone          func(url, ref, dest string) error
+
+	flagFrom            string
+	flagFromGit         string
+	flagGitPath         string
+	flagAppPath         string
+	flagAppName         string
+	flagLocation        string
+	flagDeploymentModel string
+	flagLocal           bool
+	flagGlobal          bool
+	flagProjectID       string
+	flagListTemplates   bool
+}
+
+// Synopsis returns a one-liner description for this command
+func (aic *AppInitCommand) Synopsis() string {
+	return "Start a new local Realm App from a template."
+}
+
+// Help returns long-form help information for this command
+func (aic *AppInitCommand) Help() string {
+	return `Start a new local Realm App from a template, i.e. an existing app exported without its
+identifying details, or an empty app laying out the directories of every kind of resource.
+Import the local app to create it.
+
+Usage: realm-cli app init [options]
+
+OPTIONS:
+  --from [string]
+	The ID of a template listed by --list-templates, or the App ID of an existing app to use as the template.
+	If not set, the available templates are listed to choose from, or an empty app is initialized if -y is set.
+
+  --from-git [string]
+	A git repository containing the app to use as the template instead of --from, e.g. a template
+	versioned by your team. Append "#<ref>" to use a branch or tag other than the default branch.
+	The repository is shallow-cloned, and the clone is removed once the app is copied out of it.
+
+  --git-path [string]
+	The directory containing the app within the --from-git repository, which must not lead outside of it.
+	Defaults to its root.
+
+  --path [string]
+	The directory to write the new app into, which must not exist yet. Defaults to a directory
+	named after the app, or after the --from-git repository, in the current directory.
+
+  --app-name [string]
+	The name of the new app. Defaults to the name of the template, or else the name of the directory.
+
+  --location [US-VA|US-OR|IE|AU]
+	The location of a new empty app. Prompted for if not set, unless -y is set.
+
+  --deployment-model [GLOBAL|LOCAL]
+	The deployment model of a new empty app. Prompted for if not set, unless -y is set.
+
+  --local
+	Short for --deployment-model=LOCAL.
+
+  --global
+	Short for --deployment-model=GLOBAL.
+
+  --project-id [string]
+	The Atlas Project ID of the app used as the template.
+
+  --list-templates
+	List the available templates and exit.
+	` +
+		aic.BaseCommand.Help()
+}
+
+// Run executes the command
+func (aic *AppInitCommand) Run(args []string) int {
+	flags := aic.NewFlagSet()
+
+	flags.StringVar(&aic.flagFrom, appFlagFrom, "", "")
+	flags.StringVar(&aic.flagFromGit, appFlagFromGit, "", "")
+	flags.StringVar(&aic.flagGitPath, appFlagGitPath, "", "")
+	flags.StringVar(&aic.flagAppPath, importFlagPath, "", "")
+	flags.StringVar(&aic.flagAppName, importFlagAppName, "", "")
+	flags.StringVar(&aic.flagLocation, importFlagLocation, "", "")
+	flags.StringVar(&aic.flagDeploymentModel, importFlagDeploymentModel, "", "")
+	flags.BoolVar(&aic.flagLocal, appFlagLocal, false, "")
+	flags.BoolVar(&aic.flagGlobal, appFlagGlobal, false, "")
+	flags.StringVar(&aic.flagProjectID, flagProjectIDName, "", "")
+	flags.BoolVar(&aic.flagListTemplates, appFlagListTemplates, false, "")
+
+	if err := aic.BaseCommand.run(args); err != nil {
+		aic.UI.Error(err.Error())
+		return 1
+	}
+
+	if err := aic.initApp(); err != nil {
+		aic.UI.Error(err.Error())
+		return 1
+	}
+
+	return 0
+}
+
+func (aic *AppInitCommand) initApp() error {
+	if aic.flagAppName != "" {
+		if err := validateAppName(aic.flagAppName); err != nil {
+			return err
+		}
+	}
+	if aic.flagLocal || aic.flagGlobal {
+		if aic.flagLocal && aic.flagGlobal {
+			return fmt.Errorf("--%s cannot be used with --%s", appFlagLocal, appFlagGlobal)
+		}
+		if aic.flagDeploymentModel != "" {
+			return fmt.Errorf("--%s and --%s cannot be used with --%s", appFlagLocal, appFlagGlobal, importFlagDeploymentModel)
+		}
+
+		aic.flagDeploymentModel = "GLOBAL"
+		if aic.flagLocal {
+			aic.flagDeploymentModel = "LOCAL"
+		}
+	}
+	if aic.flagLocation != "" && !isOneOf(aic.flagLocation, locationOptions) {
+		return errUnknownOption("location", aic.flagLocation, locationOptions)
+	}
+	if aic.flagDeploymentModel != "" && !isOneOf(aic.flagDeploymentModel, deploymentModelOptions) {
+		return errUnknownOption("deployment model", aic.flagDeploymentModel, deploymentModelOptions)
+	}
+
+	if aic.flagGitPath != "" && aic.flagFromGit == "" {
+		return fmt.Errorf("--%s requires --%s", appFlagGitPath, appFlagFromGit)
+	}
+	if aic.flagFromGit != "" {
+		if aic.flagFrom != "" || aic.flagListTemplates {
+			return fmt.Errorf("--%s cannot be used with --%s or --%s", appFlagFromGit, appFlagFrom, appFlagListTemplates)
+		}
+		return aic.initFromGit()
+	}
+
+	// an empty app is written without looking anything up
+	if aic.flagFrom == "" && !aic.flagListTemplates && aic.flagYes {
+		return aic.initEmptyApp()
+	}
+
+	user, err := aic.User()
+	if err != nil {
+		return err
+	}
+
+	if !user.LoggedIn() {
+		return u.ErrNotLoggedIn
+	}
+
+	realmClient, err := aic.RealmClient()
+	if err != nil {
+		return err
+	}
+
+	if aic.flagListTemplates {
+		templates, err := realmClient.ListTemplates()
+		if err != nil {
+			return fmt.Errorf("failed to list templates: %s", err)
+		}
+		if len(templates) == 0 {
+			aic.UI.Info("No templates found.")
+			return nil
+		}
+		aic.UI.Output(templatesTable(templates))
+		return nil
+	}
+
+	from := aic.flagFrom
+	if from == "" {
+		if from, err = aic.askTemplate(realmClient); err != nil {
+			return err
+		}
+		if from == emptyAppTemplate {
+			return aic.initEmptyApp()
+		}
+	}
+
+	app, err := fetchApp(realmClient, aic.flagProjectID, from)
+	if err != nil {
+		return err
+	}
+
+	dest := aic.flagAppPath
+	if dest == "" {
+		name := aic.flagAppName
+		if name == "" {
+			name = app.Name
+		}
+		dest = filepath.Join(aic.workingDirectory, name)
+	}
+	if dest, err = homedir.Expand(dest); err != nil {
+		return err
+	}
+	if _, err := os.Stat(dest); err == nil {
+		return fmt.Errorf("failed to create directory %q: directory already exists", dest)
+	}
+
+	_, body, err := realmClient.Export(app.GroupID, app.ID, api.ExportStrategyTemplate)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	if err := aic.exportToDirectory(dest, body, false); err != nil {
+		return err
+	}
+
+	// the new app is created by importing it, so it must not point at the template
+	appInstanceData := models.AppInstanceData{}
+	if err := appInstanceData.UnmarshalFile(dest); err != nil {
+		return err
+	}
+	delete(appInstanceData, models.AppIDField)
+	if aic.flagAppName != "" {
+		appInstanceData[models.AppNameField] = aic.flagAppName
+	}
+	if err := appInstanceData.MarshalFile(dest); err != nil {
+		return err
+	}
+
+	aic.UI.Info(fmt.Sprintf("Initialized the app from %q in %q, import it to create the app", from, dest))
+	return nil
+}
+
+// askTemplate lists the available templates and asks which one to initialize the app from,
+// defaulting to an empty app
+func (aic *AppInitCommand) askTemplate(realmClient api.RealmClient) (string, error) {
+	templates, err := realmClient.ListTemplates()
+	if err != nil {
+		return "", fmt.Errorf("failed to list templates: %s", err)
+	}
+	if len(templates) == 0 {
+		return emptyAppTemplate, nil
+	}
+
+	templates = append([]models.Template{{ID: emptyAppTemplate, Name: "An empty app"}}, templates...)
+	ids := make([]string, 0, len(templates))
+	for _, template := range templates {
+		ids = append(ids, template.ID)
+	}
+
+	aic.UI.Info(templatesTable(templates))
+	return aic.AskWithOptions("Template", emptyAppTemplate, ids)
+}
+
+// initEmptyApp writes an app with nothing in it yet, named after --app-name or else its directory
+func (aic *AppInitCommand) initEmptyApp() error {
+	name := aic.flagAppName
+	if name == "" && aic.flagAppPath != "" {
+		name = filepath.Base(aic.flagAppPath)
+	}
+	if name == "" {
+		if aic.flagYes {
+			return errAppInitNameRequired
+		}
+
+		var err error
+		if name, err = aic.Ask("App name", ""); err != nil {
+			return err
+		}
+	}
+	if err := validateAppName(name); err != nil {
+		return err
+	}
+
+	// without prompting, the location and deployment model are left to be chosen when the app is imported
+	location, deploymentModel := aic.flagLocation, aic.flagDeploymentModel
+	if location == "" && !aic.flagYes {
+		var err error
+		if location, err = aic.AskWithOptions("Location", models.DefaultLocation, locationOptions); err != nil {
+			return err
+		}
+	}
+	if deploymentModel == "" && !aic.flagYes {
+		var err error
+		if deploymentModel, err = aic.AskWithOptions("Deployment Model", models.DefaultDeploymentModel, deploymentModelOptions); err != nil {
+			return err
+		}
+	}
+
+	dest := aic.flagAppPath
+	if dest == "" {
+		dest = filepath.Join(aic.workingDirectory, name)
+	}
+	dest, err := homedir.Expand(dest)
+	if err != nil {
+		return err
+	}
+
+	if err := utils.WriteAppSkeleton(dest, name, location, deploymentModel); err != nil {
+		return err
+	}
+
+	aic.UI.Info(fmt.Sprintf("Initialized an empty app in %q, import it to create the app", dest))
+	return nil
+}
+
+// templatesTable formats the templates as a table, in the order they are listed
+func templatesTable(templates []models.Template) string {
+	var table strings.Builder
+	w := tabwriter.NewWriter(&table, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME")
+	for _, template := range templates {
+		fmt.Fprintf(w, "%s\t%s\n", template.ID, template.Name)
+	}
+	w.Flush()
+
+	return strings.TrimSuffix(table.String(), "\n")
+}
+
+// NewAppDescribeCommandFactory returns a new cli.CommandFactory given a cli.Ui
+func NewAppDescribeCommandFactory(ui cli.Ui) cli.CommandFactory {
+	return func() (cli.Command, error) {
+		workingDirectory, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+
+		return &AppDescribeCommand{
+			BaseCommand: &BaseCommand{
+				Name: "describe",
+				UI:   ui,
+			},
+			workingDirectory: workingDirectory,
+		}, nil
+	}
+}
+
+// AppDescribeCommand is used to summarize a deployed Realm App
+type AppDescribeCommand struct {
+	*BaseCommand
+
+	workingDirectory string
+
+	flagAppID     string
+	flagAppPath   string
+	flagProjectID string
+	flagOutput    string
+}
+
+// appDescription summarizes a deployed app
+type appDescription struct {
+	Name            string              `json:"name"`
+	ClientAppID     string              `json:"client_app_id"`
+	ID              string              `json:"id"`
+	GroupID         string              `json:"group_id"`
+	Location        string              `json:"location"`
+	DeploymentModel string              `json:"deployment_model"`
+	AuthProviders   []appDescribedEntry `json:"auth_providers"`
+	DataSources     []appDescribedEntry `json:"data_sources"`
+	Functions       int                 `json:"functions"`
+	Triggers        int                 `json:"triggers"`
+	Services        int                 `json:"services"`
+}
+
+// appDescribedEntry is an auth provider or data source of a described app
+type appDescribedEntry struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Disabled bool   `json:"disabled,omitempty"`
+}
+
+// Synopsis returns a one-liner description for this command
+func (adc *AppDescribeCommand) Synopsis() string {
+	return "Summarize a deployed Realm App."
+}
+
+// Help returns long-form help information for this command
+func (adc *AppDescribeCommand) Help() string {
+	return `Summarize a deployed Realm Application: its IDs, name, location and deployment model, its auth
+providers and data sources, and how many functions, triggers and services it has. Only the summary is
+fetched, not the whole app as with export.
+
+Usage: realm-cli app describe [options]
+
+OPTIONS:
+  --app-id [string]
+	The App ID for your app (i.e. the name of your app followed by a unique suffix, like "my-app-nysja").
+	Required if not being run from within a realm project directory.
+
+  --path [string]
+	A path to the local directory containing your app, used to look up its App ID.
+
+  --project-id [string]
+	The Atlas Project ID.
+
+  -o [text|json], --output [text|json] (default: text)
+	How the summary should be printed.
+	json - print the summary as a JSON object, e.g. for scripts to read.
+	` +
+		adc.BaseCommand.Help()
+}
+
+// Run executes the command
+func (adc *AppDescribeCommand) Run(args []string) int {
+	flags := adc.NewFlagSet()
+
+	flags.StringVar(&adc.flagAppID, flagAppIDName, "", "")
+	flags.StringVar(&adc.flagAppPath, importFlagPath, "", "")
+	flags.StringVar(&adc.flagProjectID, flagProjectIDName, "", "")
+	flags.StringVar(&adc.flagOutput, appFlagOutput, appOutputText, "")
+	flags.StringVar(&adc.flagOutput, "o", appOutputText, "")
+
+	if err := adc.BaseCommand.run(args); err != nil {
+		adc.UI.Error(err.Error())
+		return 1
+	}
+
+	if err := adc.describeApp(); err != nil {
+		adc.UI.Error(err.Error())
+		return 1
+	}
+
+	return 0
+}
+
+func (adc *AppDescribeCommand) describeApp() error {
+	switch adc.flagOutput {
+	case appOutputText, appOutputJSON:
+	default:
+		return fmt.Errorf("unknown output format %q; accepted values are [%s|%s]", adc.flagOutput, appOutputText, appOutputJSON)
+	}
+
+	_, app, realmClient, err := adc.resolveApp(appTarget{
+		appID:            adc.flagAppID,
+		appPath:          adc.flagAppPath,
+		workingDirectory: adc.workingDirectory,
+		projectID:        adc.flagProjectID,
+		errAppIDRequired: errAppDescribeAppIDRequired,
+	})
+	if err != nil {
+		return err
+	}
+
+	description, err := describeApp(realmClient, app)
+	if err != nil {
+		return fmt.Errorf("failed to describe app %s: %s", app.ClientAppID, err)
+	}
+
+	if adc.flagOutput == appOutputJSON {
+		data, err := json.MarshalIndent(description, "", "  ")
+		if err != nil {
+			return err
+		}
+		adc.UI.Output(string(data))
+		return nil
+	}
+
+	adc.UI.Output(description.String())
+	return nil
+}
+
+// describeApp fetches the summary of the app
+func describeApp(realmClient api.RealmClient, app *models.App) (*appDescription, error) {
+	deployed, err := realmClient.GetApp(app.GroupID, app.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	authProviders, err := realmClient.ListAuthProviders(app.GroupID, app.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	services, err := realmClient.ListServices(app.GroupID, app.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	functions, err := realmClient.ListFunctions(app.GroupID, app.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	triggers, err := realmClient.ListTriggers(app.GroupID, app.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	description := &appDescription{
+		Name:            app.Name,
+		ClientAppID:     app.ClientAppID,
+		ID:              app.ID,
+		GroupID:         app.GroupID,
+		Location:        deployed.Location,
+		DeploymentModel: deployed.DeploymentModel,
+		AuthProviders:   []appDescribedEntry{},
+		DataSources:     []appDescribedEntry{},
+		Functions:       len(functions),
+		Triggers:        len(triggers),
+		Services:        len(services),
+	}
+	if deployed.Name != "" {
+		description.Name = deployed.Name
+	}
+
+	for _, authProvider := range authProviders {
+		description.AuthProviders = append(description.AuthProviders, appDescribedEntry{
+			Name:     authProvider.Name,
+			Type:     authProvider.Type,
+			Disabled: authProvider.Disabled,
+		})
+	}
+	for _, service := range services {
+		if service.IsDataSource() {
+			description.DataSources = append(description.DataSources, appDescribedEntry{Name: service.Name, Type: service.Type})
+		}
+	}
+	for _, entries := range [][]appDescribedEntry{description.AuthProviders, description.DataSources} {
+		sort.SliceStable(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	}
+
+	return description, nil
+}
+
+// String formats the summary as aligned lines of names and values
+func (ad *appDescription) String() string {
+	var table strings.Builder
+	w := tabwriter.NewWriter(&table, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Name:\t%s\n", ad.Name)
+	fmt.Fprintf(w, "App ID:\t%s\n", ad.ClientAppID)
+	fmt.Fprintf(w, "ID:\t%s\n", ad.ID)
+	fmt.Fprintf(w, "Project ID:\t%s\n", ad.GroupID)
+	fmt.Fprintf(w, "Location:\t%s\n", valueOrDash(ad.Location))
+	fmt.Fprintf(w, "Deployment Model:\t%s\n", valueOrDash(ad.DeploymentModel))
+	fmt.Fprintf(w, "Auth Providers:\t%s\n", describedEntries(ad.AuthProviders))
+	fmt.Fprintf(w, "Data Sources:\t%s\n", describedEntries(ad.DataSources))
+	fmt.Fprintf(w, "Functions:\t%d\n", ad.Functions)
+	fmt.Fprintf(w, "Triggers:\t%d\n", ad.Triggers)
+	fmt.Fprintf(w, "Services:\t%d\n", ad.Services)
+	w.Flush()
+
+	return strings.TrimSuffix(table.String(), "\n")
+}
+
+// describedEntries lists the entries by name along with their type, e.g. "mongodb-atlas (mongodb-atlas)"
+func describedEntries(entries []appDescribedEntry) string {
+	if len(entries) == 0 {
+		return "-"
+	}
+
+	described := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		text := fmt.Sprintf("%s (%s)", entry.Name, entry.Type)
+		if entry.Disabled {
+			text += " [disabled]"
+		}
+		described = append(described, text)
+	}
+	return strings.Join(described, ", ")
+}
+
+// NewAppListCommandFactory returns a new cli.CommandFactory given a cli.Ui
+func NewAppListCommandFactory(ui cli.Ui) cli.CommandFactory {
+	return func() (cli.Command, error) {
+		return &AppListCommand{
+			BaseCommand: &BaseCommand{
+				Name: "list",
+				UI:   ui,
+			},
+		}, nil
+	}
+}
+
+// AppListCommand is used to list the Realm Apps of the user
+type AppListCommand struct {
+	*BaseCommand
+
+	flagProjectID string
+	flagName      string
+	flagOutput    string
+}
+
+// appSummary is a listed app
+type appSummary struct {
+	Name            string `json:"name"`
+	ClientAppID     string `json:"client_app_id"`
+	ID              string `json:"id"`
+	GroupID         string `json:"group_id"`
+	Location        string `json:"location"`
+	DeploymentModel string `json:"deployment_model"`
+}
+
+// Synopsis returns a one-liner description for this command
+func (alc *AppListCommand) Synopsis() string {
+	return "List your Realm Apps."
+}
+
+// Help returns long-form help information for this command
+func (alc *AppListCommand) Help() string {
+	return `List the Realm Applications in your Atlas Projects by name, along with their App ID and location,
+e.g. to find the App ID to import to or diff against.
+
+Usage: realm-cli app list [options]
+
+OPTIONS:
+  --project-id [string]
+	The Atlas Project ID to list the apps of. Defaults to all of your projects.
+
+  --name [string]
+	Only list the apps whose name contains the given text, ignoring case.
+
+  -o [text|json], --output [text|json] (default: text)
+	How the apps should be printed.
+	json - print the apps as a JSON array, along with their ID, Project ID and deployment model.
+	` +
+		alc.BaseCommand.Help()
+}
+
+// Run executes the command
+func (alc *AppListCommand) Run(args []string) int {
+	flags := alc.NewFlagSet()
+
+	flags.StringVar(&alc.flagProjectID, flagProjectIDName, "", "")
+	flags.StringVar(&alc.flagName, appFlagName, "", "")
+	flags.StringVar(&alc.flagOutput, appFlagOutput, appOutputText, "")
+	flags.StringVar(&alc.flagOutput, "o", appOutputText, "")
+
+	if err := alc.BaseCommand.run(args); err != nil {
+		alc.UI.Error(err.Error())
+		return 1
+	}
+
+	if err := alc.listApps(); err != nil {
+		alc.UI.Error(err.Error())
+		return 1
+	}
+
+	return 0
+}
+
+func (alc *AppListCommand) listApps() error {
+	switch alc.flagOutput {
+	case appOutputText, appOutputJSON:
+	default:
+		return fmt.Errorf("unknown output format %q; accepted values are [%s|%s]", alc.flagOutput, appOutputText, appOutputJSON)
+	}
+
+	user, err := alc.User()
+	if err != nil {
+		return err
+	}
+
+	if !user.LoggedIn() {
+		return u.ErrNotLoggedIn
+	}
+
+	realmClient, err := alc.RealmClient()
+	if err != nil {
+		return err
+	}
+
+	apps, err := realmClient.FindApps(api.AppFilter{GroupID: alc.flagProjectID})
+	if err != nil {
+		return fmt.Errorf("failed to list apps: %s", err)
+	}
+
+	summaries := []appSummary{}
+	for _, app := range apps {
+		if !strings.Contains(strings.ToLower(app.Name), strings.ToLower(alc.flagName)) {
+			continue
+		}
+		summaries = append(summaries, appSummary{
+			Name:            app.Name,
+			ClientAppID:     app.ClientAppID,
+			ID:              app.ID,
+			GroupID:         app.GroupID,
+			Location:        app.Location,
+			DeploymentModel: app.DeploymentModel,
+		})
+	}
+	sort.SliceStable(summaries, func(i, j int) bool {
+		if summaries[i].Name != summaries[j].Name {
+			return summaries[i].Name < summaries[j].Name
+		}
+		return summaries[i].ClientAppID < summaries[j].ClientAppID
+	})
+
+	if alc.flagOutput == appOutputJSON {
+		data, err := json.MarshalIndent(summaries, "", "  ")
+		if err != nil {
+			return err
+		}
+		alc.UI.Output(string(data))
+		return nil
+	}
+
+	if len(summaries) == 0 {
+		switch {
+		case alc.flagName != "":
+			alc.UI.Info(fmt.Sprintf("No apps have a name containing %q.", alc.flagName))
+		case alc.flagProjectID != "":
+			alc.UI.Info(fmt.Sprintf("No apps were found in the project %s.", alc.flagProjectID))
+		default:
+			alc.UI.Info(`No apps were found, create one with "app init" followed by "import".`)
+		}
+		return nil
+	}
+
+	alc.UI.Output(appsTable(summaries))
+	return nil
+}
+
+func appsTable(summaries []appSummary) string {
+	var table strings.Builder
+	w := tabwriter.NewWriter(&table, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tAPP ID\tLOCATION")
+	for _, summary := range summaries {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", summary.Name, summary.ClientAppID, valueOrDash(summary.Location))
+	}
+	w.Flush()
+
+	return strings.TrimSuffix(table.String(), "\n")
+}
+
+func valueOrDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
@@ -0,0 +1,204 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/10gen/realm-cli/models"
+
+	"github.com/mitchellh/go-homedir"
+)
+
+const gitDirectory = ".git"
+
+// parseGitSource splits a --from-git source of the form "<url>[#ref]"
+func parseGitSource(source string) (url, ref string) {
+	if idx := strings.LastIndex(source, "#"); idx != -1 {
+		return source[:idx], source[idx+1:]
+	}
+	return source, ""
+}
+
+// gitRepositoryName returns the directory name git would clone the repository into
+func gitRepositoryName(url string) string {
+	name := path.Base(strings.TrimRight(strings.Replace(url, ":", "/", -1), "/"))
+	return strings.TrimSuffix(name, ".git")
+}
+
+func errGitPathOutsideRepository(gitPath string) error {
+	return fmt.Errorf("--%s %q must be a directory within the repository", appFlagGitPath, gitPath)
+}
+
+// validateGitPath checks that the --git-path cannot lead outside of the repository it is joined to
+func validateGitPath(gitPath string) error {
+	cleaned := path.Clean(filepath.ToSlash(gitPath))
+	if path.IsAbs(cleaned) || filepath.IsAbs(gitPath) || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return errGitPathOutsideRepository(gitPath)
+	}
+	return nil
+}
+
+// gitTemplateDir returns the directory at the --git-path within the cloned repository, which must still be
+// within the repository once its symbolic links are followed
+func gitTemplateDir(cloneDir, gitPath string) (string, error) {
+	if err := validateGitPath(gitPath); err != nil {
+		return "", err
+	}
+
+	templateDir := filepath.Join(cloneDir, filepath.FromSlash(gitPath))
+	resolvedTemplateDir, err := filepath.EvalSymlinks(templateDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return templateDir, nil
+		}
+		return "", err
+	}
+	resolvedCloneDir, err := filepath.EvalSymlinks(cloneDir)
+	if err != nil {
+		return "", err
+	}
+
+	rel, err := filepath.Rel(resolvedCloneDir, resolvedTemplateDir)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", errGitPathOutsideRepository(gitPath)
+	}
+	return templateDir, nil
+}
+
+// shallowCloneGit clones only the latest commit of the ref, or of the default branch, into dest
+func shallowCloneGit(url, ref, dest string) error {
+	args := []string{"clone", "--quiet", "--depth", "1"}
+	if ref != "" {
+		args = append(args, "--branch", ref)
+	}
+	args = append(args, "--", url, dest)
+
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%s: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
+
+// initFromGit copies the app found in the --from-git repository into a new local directory. As with
+// a template exported from Realm, the app ID is removed from the copied app config so that importing
+// it creates a new app, and the app is renamed after --app-name if set
+func (aic *AppInitCommand) initFromGit() error {
+	if err := validateGitPath(aic.flagGitPath); err != nil {
+		return err
+	}
+
+	url, ref := parseGitSource(aic.flagFromGit)
+
+	dest := aic.flagAppPath
+	if dest == "" {
+		name := aic.flagAppName
+		if name == "" {
+			name = gitRepositoryName(url)
+		}
+		dest = filepath.Join(aic.workingDirectory, name)
+	}
+	dest, err := homedir.Expand(dest)
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(dest); err == nil {
+		return fmt.Errorf("failed to create directory %q: directory already exists", dest)
+	}
+
+	cloneDir, err := ioutil.TempDir("", "realm-git-template-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(cloneDir)
+
+	if err := aic.gitClone(url, ref, cloneDir); err != nil {
+		return fmt.Errorf("failed to clone %q: %s", aic.flagFromGit, err)
+	}
+
+	templateDir, err := gitTemplateDir(cloneDir, aic.flagGitPath)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(filepath.Join(templateDir, models.AppConfigFileName)); err != nil {
+		return fmt.Errorf("no app found at %q in %q: missing %s", aic.flagGitPath, aic.flagFromGit, models.AppConfigFileName)
+	}
+
+	if err := copyDirectory(templateDir, dest); err != nil {
+		return err
+	}
+
+	appInstanceData := models.AppInstanceData{}
+	if err := appInstanceData.UnmarshalFile(dest); err != nil {
+		return err
+	}
+	delete(appInstanceData, models.AppIDField)
+	if aic.flagAppName != "" {
+		appInstanceData[models.AppNameField] = aic.flagAppName
+	}
+	if err := appInstanceData.MarshalFile(dest); err != nil {
+		return err
+	}
+
+	aic.UI.Info(fmt.Sprintf("Initialized the app from %q in %q, import it to create the app", aic.flagFromGit, dest))
+	return nil
+}
+
+// copyDirectory recursively copies the files of src into dest, leaving out git metadata
+func copyDirectory(src, dest string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dest, relPath)
+
+		if info.IsDir() {
+			if info.Name() == gitDirectory {
+				return filepath.SkipDir
+			}
+			return os.MkdirAll(target, os.ModePerm)
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		return copyFile(path, target, info.Mode())
+	})
+}
+
+func copyFile(src, dest string, mode os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return fmt.Errorf("failed to create file %q: %s", dest, err)
+	}
+	defer out.Close()
+
+	if _, err := io.Copy(out, in); err != nil {
+		return fmt.Errorf("failed to copy file %q: %s", dest, err)
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/10gen/realm-cli/models"
+	u "github.com/10gen/realm-cli/utils/test"
+	gc "github.com/smartystreets/goconvey/convey"
+
+	"github.com/mitchellh/cli"
+)
+
+func TestParseGitSource(t *testing.T) {
+	for _, tc := range []struct {
+		Source       string
+		ExpectedURL  string
+		ExpectedRef  string
+		ExpectedName string
+	}{
+		{"https://github.com/acme/realm-templates.git", "https://github.com/acme/realm-templates.git", "", "realm-templates"},
+		{"https://github.com/acme/realm-templates#v1.2.0", "https://github.com/acme/realm-templates", "v1.2.0", "realm-templates"},
+		{"git@github.com:acme/realm-templates.git#main", "git@github.com:acme/realm-templates.git", "main", "realm-templates"},
+	} {
+		t.Run(tc.Source, func(t *testing.T) {
+			url, ref := parseGitSource(tc.Source)
+			u.So(t, url, gc.ShouldEqual, tc.ExpectedURL)
+			u.So(t, ref, gc.ShouldEqual, tc.ExpectedRef)
+			u.So(t, gitRepositoryName(url), gc.ShouldEqual, tc.ExpectedName)
+		})
+	}
+}
+
+func TestAppInitFromGit(t *testing.T) {
+	workingDirectory, err := ioutil.TempDir("", "realm-app-init-git-")
+	u.So(t, err, gc.ShouldBeNil)
+	defer os.RemoveAll(workingDirectory)
+
+	setup := func(t *testing.T) (*AppInitCommand, *cli.MockUi, *[]string) {
+		mockUI := cli.NewMockUi()
+		cmd, err := NewAppInitCommandFactory(mockUI)()
+		u.So(t, err, gc.ShouldBeNil)
+
+		initCommand := cmd.(*AppInitCommand)
+		initCommand.workingDirectory = workingDirectory
+		initCommand.storage = u.NewEmptyStorage()
+
+		var cloned []string
+		initCommand.gitClone = func(url, ref, dest string) error {
+			cloned = []string{url, ref}
+			if err := os.MkdirAll(filepath.Join(dest, ".git"), os.ModePerm); err != nil {
+				return err
+			}
+			return copyDirectory("../testdata/simple_app_with_instance_data", filepath.Join(dest, "templates", "simple"))
+		}
+		return initCommand, mockUI, &cloned
+	}
+
+	t.Run("copies the app found in the repository without its app ID", func(t *testing.T) {
+		initCommand, mockUI, cloned := setup(t)
+
+		exitCode := initCommand.Run([]string{"--from-git=https://github.com/acme/realm-templates.git#v1", "--git-path=templates/simple"})
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+		u.So(t, *cloned, gc.ShouldResemble, []string{"https://github.com/acme/realm-templates.git", "v1"})
+
+		appDir := filepath.Join(workingDirectory, "realm-templates")
+		u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, fmt.Sprintf("Initialized the app from %q in %q", "https://github.com/acme/realm-templates.git#v1", appDir))
+		_, err := os.Stat(filepath.Join(appDir, "environments", "qa.json"))
+		u.So(t, err, gc.ShouldBeNil)
+		_, err = os.Stat(filepath.Join(appDir, ".git"))
+		u.So(t, os.IsNotExist(err), gc.ShouldBeTrue)
+
+		appInstanceData := models.AppInstanceData{}
+		u.So(t, appInstanceData.UnmarshalFile(appDir), gc.ShouldBeNil)
+		u.So(t, appInstanceData, gc.ShouldNotContainKey, models.AppIDField)
+		u.So(t, appInstanceData.AppName(), gc.ShouldEqual, "simple-app")
+
+		t.Run("and refuses to overwrite the directory it created", func(t *testing.T) {
+			initCommand, mockUI, _ := setup(t)
+
+			exitCode := initCommand.Run([]string{"--from-git=https://github.com/acme/realm-templates.git", "--git-path=templates/simple"})
+			u.So(t, exitCode, gc.ShouldEqual, 1)
+			u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, "directory already exists")
+		})
+	})
+
+	t.Run("names the app and its directory after --app-name", func(t *testing.T) {
+		initCommand, mockUI, _ := setup(t)
+
+		exitCode := initCommand.Run([]string{"--from-git=https://github.com/acme/realm-templates.git", "--git-path=templates/simple", "--app-name=renamed"})
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+
+		appInstanceData := models.AppInstanceData{}
+		u.So(t, appInstanceData.UnmarshalFile(filepath.Join(workingDirectory, "renamed")), gc.ShouldBeNil)
+		u.So(t, appInstanceData.AppName(), gc.ShouldEqual, "renamed")
+	})
+
+	t.Run("fails if there is no app at the git path", func(t *testing.T) {
+		initCommand, mockUI, _ := setup(t)
+
+		exitCode := initCommand.Run([]string{"--from-git=https://github.com/acme/other.git", "--path=" + filepath.Join(workingDirectory, "other")})
+		u.So(t, exitCode, gc.ShouldEqual, 1)
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, `no app found at "" in "https://github.com/acme/other.git"`)
+	})
+
+	t.Run("fails if the clone fails", func(t *testing.T) {
+		initCommand, mockUI, _ := setup(t)
+		initCommand.gitClone = func(url, ref, dest string) error {
+			return errors.New("repository not found")
+		}
+
+		exitCode := initCommand.Run([]string{"--from-git=https://github.com/acme/missing.git"})
+		u.So(t, exitCode, gc.ShouldEqual, 1)
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, `failed to clone "https://github.com/acme/missing.git": repository not found`)
+	})
+
+	t.Run("rejects a git path leading outside of the repository before cloning it", func(t *testing.T) {
+		for _, gitPath := range []string{"..", "../other", "templates/../../other", "/etc"} {
+			initCommand, mockUI, cloned := setup(t)
+
+			exitCode := initCommand.Run([]string{"--from-git=https://github.com/acme/realm-templates.git", "--git-path=" + gitPath})
+			u.So(t, exitCode, gc.ShouldEqual, 1)
+			u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, fmt.Sprintf("--git-path %q must be a directory within the repository", gitPath))
+			u.So(t, *cloned, gc.ShouldBeEmpty)
+		}
+	})
+
+	t.Run("rejects a git path linking outside of the repository", func(t *testing.T) {
+		initCommand, mockUI, _ := setup(t)
+		initCommand.gitClone = func(url, ref, dest string) error {
+			return os.Symlink(workingDirectory, filepath.Join(dest, "outside"))
+		}
+
+		exitCode := initCommand.Run([]string{"--from-git=https://github.com/acme/linked.git", "--git-path=outside"})
+		u.So(t, exitCode, gc.ShouldEqual, 1)
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, `--git-path "outside" must be a directory within the repository`)
+		_, err := os.Stat(filepath.Join(workingDirectory, "linked"))
+		u.So(t, os.IsNotExist(err), gc.ShouldBeTrue)
+	})
+
+	t.Run("requires --from-git with --git-path", func(t *testing.T) {
+		initCommand, mockUI, _ := setup(t)
+
+		exitCode := initCommand.Run([]string{"--git-path=templates/simple"})
+		u.So(t, exitCode, gc.ShouldEqual, 1)
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, "--git-path requires --from-git")
+	})
+
+	t.Run("cannot be used with --from", func(t *testing.T) {
+		initCommand, mockUI, cloned := setup(t)
+
+		exitCode := initCommand.Run([]string{"--from-git=https://github.com/acme/realm-templates.git", "--from=todo-abcde"})
+		u.So(t, exitCode, gc.ShouldEqual, 1)
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, "--from-git cannot be used with --from or --list-templates")
+		u.So(t, *cloned, gc.ShouldBeEmpty)
+	})
+}
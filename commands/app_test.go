@@ -0,0 +1,609 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/10gen/realm-cli/api"
+	"github.com/10gen/realm-cli/models"
+	"github.com/10gen/realm-cli/user"
+	u "github.com/10gen/realm-cli/utils/test"
+	gc "github.com/smartystreets/goconvey/convey"
+
+	"github.com/mitchellh/cli"
+)
+
+func TestAppDeleteCommand(t *testing.T) {
+	setup := func(deleted *[]string) (*AppDeleteCommand, *cli.MockUi) {
+		mockUI := cli.NewMockUi()
+		cmd, err := NewAppDeleteCommandFactory(mockUI)()
+		if err != nil {
+			panic(err)
+		}
+
+		deleteCommand := cmd.(*AppDeleteCommand)
+		deleteCommand.storage = u.NewEmptyStorage()
+		deleteCommand.user = &user.User{
+			APIKey:      "my-api-key",
+			AccessToken: u.GenerateValidAccessToken(),
+		}
+		deleteCommand.realmClient = &u.MockRealmClient{
+			FetchAppByClientAppIDFn: func(clientAppID string) (*models.App, error) {
+				return &models.App{GroupID: "group-id", ID: "app-id", ClientAppID: clientAppID, Name: "my-app"}, nil
+			},
+			DeleteAppFn: func(groupID, appID string) error {
+				*deleted = append(*deleted, groupID+"/"+appID)
+				return nil
+			},
+		}
+		return deleteCommand, mockUI
+	}
+
+	t.Run("should require the user to be logged in", func(t *testing.T) {
+		var deleted []string
+		deleteCommand, mockUI := setup(&deleted)
+		deleteCommand.user = nil
+
+		exitCode := deleteCommand.Run([]string{"--app-id=my-app-abcdef"})
+		u.So(t, exitCode, gc.ShouldEqual, 1)
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, user.ErrNotLoggedIn.Error())
+		u.So(t, deleted, gc.ShouldBeEmpty)
+	})
+
+	t.Run("should require an app id outside of an app directory", func(t *testing.T) {
+		var deleted []string
+		deleteCommand, mockUI := setup(&deleted)
+
+		exitCode := deleteCommand.Run([]string{"--path=../testdata/missing_app"})
+		u.So(t, exitCode, gc.ShouldEqual, 1)
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, errAppDeleteAppIDRequired.Error())
+	})
+
+	t.Run("should delete the app once confirmed", func(t *testing.T) {
+		var deleted []string
+		deleteCommand, mockUI := setup(&deleted)
+		mockUI.InputReader = strings.NewReader("y\n")
+
+		exitCode := deleteCommand.Run([]string{"--app-id=my-app-abcdef"})
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+		u.So(t, deleted, gc.ShouldResemble, []string{"group-id/app-id"})
+		u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, "Deleted the app my-app (my-app-abcdef) in project group-id")
+	})
+
+	t.Run("should keep the app if not confirmed", func(t *testing.T) {
+		var deleted []string
+		deleteCommand, mockUI := setup(&deleted)
+		mockUI.InputReader = strings.NewReader("n\n")
+
+		exitCode := deleteCommand.Run([]string{"--app-id=my-app-abcdef"})
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+		u.So(t, deleted, gc.ShouldBeEmpty)
+	})
+
+	t.Run("should only print the app with --dry-run", func(t *testing.T) {
+		var deleted []string
+		deleteCommand, mockUI := setup(&deleted)
+
+		exitCode := deleteCommand.Run([]string{"--app-id=my-app-abcdef", "--dry-run", "-y"})
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+		u.So(t, deleted, gc.ShouldBeEmpty)
+		u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, "Would delete the app my-app (my-app-abcdef) in project group-id")
+	})
+}
+
+func TestAppRenameCommand(t *testing.T) {
+	type rename struct {
+		GroupID, AppID, Name string
+	}
+
+	setup := func(renamed *[]rename, written *models.AppInstanceData) (*AppRenameCommand, *cli.MockUi) {
+		mockUI := cli.NewMockUi()
+		cmd, err := NewAppRenameCommandFactory(mockUI)()
+		if err != nil {
+			panic(err)
+		}
+
+		renameCommand := cmd.(*AppRenameCommand)
+		renameCommand.storage = u.NewEmptyStorage()
+		renameCommand.user = &user.User{
+			APIKey:      "my-api-key",
+			AccessToken: u.GenerateValidAccessToken(),
+		}
+		renameCommand.writeAppConfigToFile = func(dest string, app models.AppInstanceData) error {
+			*written = app
+			return nil
+		}
+		renameCommand.realmClient = &u.MockRealmClient{
+			FetchAppByClientAppIDFn: func(clientAppID string) (*models.App, error) {
+				return &models.App{GroupID: "group-id", ID: "app-id", ClientAppID: clientAppID, Name: "simple-app"}, nil
+			},
+			RenameAppFn: func(groupID, appID, name string) error {
+				*renamed = append(*renamed, rename{groupID, appID, name})
+				return nil
+			},
+		}
+		return renameCommand, mockUI
+	}
+
+	t.Run("should rename the deployed app and the local app", func(t *testing.T) {
+		var renamed []rename
+		var written models.AppInstanceData
+		renameCommand, mockUI := setup(&renamed, &written)
+
+		exitCode := renameCommand.Run([]string{"--path=../testdata/simple_app_with_instance_data", "--to=renamed-app"})
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+		u.So(t, renamed, gc.ShouldResemble, []rename{{"group-id", "app-id", "renamed-app"}})
+		u.So(t, written.AppName(), gc.ShouldEqual, "renamed-app")
+		u.So(t, written.AppID(), gc.ShouldEqual, "my-app-abcdef")
+		u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, "Renamed app my-app-abcdef from simple-app to renamed-app")
+	})
+
+	t.Run("should leave a local app alone when renaming another app", func(t *testing.T) {
+		var renamed []rename
+		var written models.AppInstanceData
+		renameCommand, mockUI := setup(&renamed, &written)
+
+		exitCode := renameCommand.Run([]string{"--path=../testdata/simple_app_with_instance_data", "--app-id=other-app-abcdef", "--to=renamed-app"})
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+		u.So(t, renamed, gc.ShouldHaveLength, 1)
+		u.So(t, written, gc.ShouldBeNil)
+	})
+
+	for _, tc := range []struct {
+		Description string
+		Args        []string
+		Err         string
+	}{
+		{
+			Description: "a new name",
+			Args:        []string{"--app-id=my-app-abcdef"},
+			Err:         errAppRenameToRequired.Error(),
+		},
+		{
+			Description: "a name Realm accepts",
+			Args:        []string{"--app-id=my-app-abcdef", "--to=my app"},
+			Err:         `app name "my app" is invalid, it can only contain ASCII letters, numbers, underscores and hyphens`,
+		},
+		{
+			Description: "a name which is not too long",
+			Args:        []string{"--app-id=my-app-abcdef", "--to=" + strings.Repeat("a", 33)},
+			Err:         "is too long, it can be at most 32 characters",
+		},
+	} {
+		t.Run("should require "+tc.Description, func(t *testing.T) {
+			var renamed []rename
+			var written models.AppInstanceData
+			renameCommand, mockUI := setup(&renamed, &written)
+
+			exitCode := renameCommand.Run(tc.Args)
+			u.So(t, exitCode, gc.ShouldEqual, 1)
+			u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, tc.Err)
+			u.So(t, renamed, gc.ShouldBeEmpty)
+		})
+	}
+}
+
+func TestAppInitCommand(t *testing.T) {
+	templates := []models.Template{{ID: "todo-abcde", Name: "Todo"}, {ID: "chat-fghij", Name: "Chat"}}
+
+	setup := func(t *testing.T, exported *[]string) (*AppInitCommand, *cli.MockUi, string) {
+		dir, err := ioutil.TempDir("", "realm-app-init-")
+		u.So(t, err, gc.ShouldBeNil)
+
+		templateDir, err := ioutil.TempDir("", "realm-app-template-")
+		u.So(t, err, gc.ShouldBeNil)
+		defer os.RemoveAll(templateDir)
+		u.So(t, ioutil.WriteFile(
+			filepath.Join(templateDir, models.AppConfigFileName),
+			[]byte(`{"app_id":"todo-abcde","name":"todo"}`),
+			0644,
+		), gc.ShouldBeNil)
+		exportBody := u.NewZipResponseBody(templateDir)
+
+		mockUI := cli.NewMockUi()
+		cmd, err := NewAppInitCommandFactory(mockUI)()
+		u.So(t, err, gc.ShouldBeNil)
+
+		initCommand := cmd.(*AppInitCommand)
+		initCommand.workingDirectory = dir
+		initCommand.storage = u.NewEmptyStorage()
+		initCommand.user = &user.User{
+			APIKey:      "my-api-key",
+			AccessToken: u.GenerateValidAccessToken(),
+		}
+		initCommand.realmClient = &u.MockRealmClient{
+			ListTemplatesFn: func() ([]models.Template, error) {
+				return templates, nil
+			},
+			FetchAppByClientAppIDFn: func(clientAppID string) (*models.App, error) {
+				return &models.App{GroupID: "group-id", ID: "app-id", ClientAppID: clientAppID, Name: "todo"}, nil
+			},
+			ExportFn: func(groupID, appID string, strategy api.ExportStrategy) (string, io.ReadCloser, error) {
+				*exported = append(*exported, string(strategy))
+				return "todo_20200101", exportBody, nil
+			},
+		}
+		return initCommand, mockUI, dir
+	}
+
+	readConfig := func(t *testing.T, dir string) models.AppInstanceData {
+		appInstanceData := models.AppInstanceData{}
+		u.So(t, appInstanceData.UnmarshalFile(dir), gc.ShouldBeNil)
+		return appInstanceData
+	}
+
+	t.Run("should list the templates", func(t *testing.T) {
+		var exported []string
+		initCommand, mockUI, dir := setup(t, &exported)
+		defer os.RemoveAll(dir)
+
+		exitCode := initCommand.Run([]string{"--list-templates"})
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+		u.So(t, mockUI.OutputWriter.String(), gc.ShouldEqual, strings.Join([]string{
+			"ID          NAME",
+			"todo-abcde  Todo",
+			"chat-fghij  Chat",
+			"",
+		}, "\n"))
+		u.So(t, exported, gc.ShouldBeEmpty)
+	})
+
+	t.Run("should initialize the app from the template without its app id", func(t *testing.T) {
+		var exported []string
+		initCommand, mockUI, dir := setup(t, &exported)
+		defer os.RemoveAll(dir)
+
+		exitCode := initCommand.Run([]string{"--from=todo-abcde", "--app-name=my-todo"})
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+		u.So(t, exported, gc.ShouldResemble, []string{string(api.ExportStrategyTemplate)})
+
+		appInstanceData := readConfig(t, filepath.Join(dir, "my-todo"))
+		u.So(t, appInstanceData.AppID(), gc.ShouldBeEmpty)
+		u.So(t, appInstanceData.AppName(), gc.ShouldEqual, "my-todo")
+	})
+
+	t.Run("should ask which template to initialize the app from", func(t *testing.T) {
+		var exported []string
+		initCommand, mockUI, dir := setup(t, &exported)
+		defer os.RemoveAll(dir)
+		mockUI.InputReader = strings.NewReader("todo-abcde\n")
+
+		exitCode := initCommand.Run([]string{})
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+		u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, "todo-abcde  Todo")
+		u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, "Template [empty]:")
+		u.So(t, readConfig(t, filepath.Join(dir, "todo")).AppName(), gc.ShouldEqual, "todo")
+	})
+
+	t.Run("should initialize an empty app with --yes", func(t *testing.T) {
+		var exported []string
+		initCommand, mockUI, dir := setup(t, &exported)
+		defer os.RemoveAll(dir)
+		initCommand.user = nil
+
+		exitCode := initCommand.Run([]string{"-y", "--app-name=my-app", "--location=IE", "--deployment-model=LOCAL"})
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+		u.So(t, exported, gc.ShouldBeEmpty)
+
+		appInstanceData := readConfig(t, filepath.Join(dir, "my-app"))
+		u.So(t, appInstanceData.AppName(), gc.ShouldEqual, "my-app")
+		u.So(t, appInstanceData.AppLocation(), gc.ShouldEqual, "IE")
+		_, err := os.Stat(filepath.Join(dir, "my-app", "functions"))
+		u.So(t, err, gc.ShouldBeNil)
+	})
+
+	t.Run("should require an app name for an empty app with --yes", func(t *testing.T) {
+		var exported []string
+		initCommand, mockUI, dir := setup(t, &exported)
+		defer os.RemoveAll(dir)
+
+		exitCode := initCommand.Run([]string{"-y"})
+		u.So(t, exitCode, gc.ShouldEqual, 1)
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, errAppInitNameRequired.Error())
+	})
+
+	t.Run("should offer an empty app along with the templates", func(t *testing.T) {
+		var exported []string
+		initCommand, mockUI, dir := setup(t, &exported)
+		defer os.RemoveAll(dir)
+		mockUI.InputReader = strings.NewReader("empty\nmy-app\nAU\nGLOBAL\n")
+
+		exitCode := initCommand.Run([]string{})
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+		u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, "empty       An empty app")
+		u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, "Template [empty]:")
+		u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, "Location [US-VA]:")
+		u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, "Deployment Model [GLOBAL]:")
+		u.So(t, exported, gc.ShouldBeEmpty)
+
+		appInstanceData := readConfig(t, filepath.Join(dir, "my-app"))
+		u.So(t, appInstanceData.AppName(), gc.ShouldEqual, "my-app")
+		u.So(t, appInstanceData.AppLocation(), gc.ShouldEqual, "AU")
+		u.So(t, appInstanceData.AppDeploymentModel(), gc.ShouldEqual, "GLOBAL")
+	})
+
+	t.Run("should set the deployment model with --local", func(t *testing.T) {
+		var exported []string
+		initCommand, _, dir := setup(t, &exported)
+		defer os.RemoveAll(dir)
+
+		exitCode := initCommand.Run([]string{"-y", "--app-name=my-app", "--local"})
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+		u.So(t, readConfig(t, filepath.Join(dir, "my-app")).AppDeploymentModel(), gc.ShouldEqual, "LOCAL")
+	})
+
+	for _, tc := range []struct {
+		desc          string
+		args          []string
+		expectedError string
+	}{
+		{
+			desc:          "an unknown location",
+			args:          []string{"--location=MARS"},
+			expectedError: `unknown location "MARS"; accepted values are [US-VA|US-OR|IE|AU]`,
+		},
+		{
+			desc:          "an unknown deployment model",
+			args:          []string{"--deployment-model=REGIONAL"},
+			expectedError: `unknown deployment model "REGIONAL"; accepted values are [GLOBAL|LOCAL]`,
+		},
+		{
+			desc:          "both --local and --global",
+			args:          []string{"--local", "--global"},
+			expectedError: "--local cannot be used with --global",
+		},
+		{
+			desc:          "--local along with --deployment-model",
+			args:          []string{"--local", "--deployment-model=GLOBAL"},
+			expectedError: "--local and --global cannot be used with --deployment-model",
+		},
+	} {
+		t.Run("should reject "+tc.desc, func(t *testing.T) {
+			var exported []string
+			initCommand, mockUI, dir := setup(t, &exported)
+			defer os.RemoveAll(dir)
+
+			exitCode := initCommand.Run(append([]string{"-y", "--app-name=my-app"}, tc.args...))
+			u.So(t, exitCode, gc.ShouldEqual, 1)
+			u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, tc.expectedError)
+
+			_, err := os.Stat(filepath.Join(dir, "my-app"))
+			u.So(t, os.IsNotExist(err), gc.ShouldBeTrue)
+		})
+	}
+
+	t.Run("should not overwrite an existing directory", func(t *testing.T) {
+		var exported []string
+		initCommand, mockUI, dir := setup(t, &exported)
+		defer os.RemoveAll(dir)
+
+		exitCode := initCommand.Run([]string{"--from=todo-abcde", "--path=" + dir})
+		u.So(t, exitCode, gc.ShouldEqual, 1)
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, "directory already exists")
+		u.So(t, exported, gc.ShouldBeEmpty)
+	})
+}
+
+func TestAppDescribeCommand(t *testing.T) {
+	setup := func() (*AppDescribeCommand, *cli.MockUi) {
+		mockUI := cli.NewMockUi()
+		cmd, err := NewAppDescribeCommandFactory(mockUI)()
+		if err != nil {
+			panic(err)
+		}
+
+		describeCommand := cmd.(*AppDescribeCommand)
+		describeCommand.storage = u.NewEmptyStorage()
+		describeCommand.user = &user.User{
+			APIKey:      "my-api-key",
+			AccessToken: u.GenerateValidAccessToken(),
+		}
+		describeCommand.realmClient = &u.MockRealmClient{
+			FetchAppByClientAppIDFn: func(clientAppID string) (*models.App, error) {
+				return &models.App{GroupID: "group-id", ID: "app-id", ClientAppID: clientAppID, Name: "my-app"}, nil
+			},
+			GetAppFn: func(groupID, appID string) (*models.App, error) {
+				return &models.App{GroupID: groupID, ID: appID, Name: "my-app", Location: "IE", DeploymentModel: "LOCAL"}, nil
+			},
+			ListAuthProvidersFn: func(groupID, appID string) ([]models.AuthProvider, error) {
+				return []models.AuthProvider{
+					{Name: "api-key", Type: "api-key", Disabled: true},
+					{Name: "anon-user", Type: "anon-user"},
+				}, nil
+			},
+			ListServicesFn: func(groupID, appID string) ([]models.Service, error) {
+				return []models.Service{
+					{Name: "mongodb-atlas", Type: "mongodb-atlas"},
+					{Name: "http", Type: "http"},
+				}, nil
+			},
+			ListFunctionsFn: func(groupID, appID string) ([]models.Function, error) {
+				return []models.Function{{Name: "sum"}, {Name: "yell"}}, nil
+			},
+			ListTriggersFn: func(groupID, appID string) ([]models.Trigger, error) {
+				return []models.Trigger{{"name": "nightly"}}, nil
+			},
+		}
+		return describeCommand, mockUI
+	}
+
+	t.Run("should summarize the deployed app", func(t *testing.T) {
+		describeCommand, mockUI := setup()
+
+		exitCode := describeCommand.Run([]string{"--app-id=my-app-abcdef"})
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+		u.So(t, mockUI.OutputWriter.String(), gc.ShouldEqual, strings.Join([]string{
+			"Name:              my-app",
+			"App ID:            my-app-abcdef",
+			"ID:                app-id",
+			"Project ID:        group-id",
+			"Location:          IE",
+			"Deployment Model:  LOCAL",
+			"Auth Providers:    anon-user (anon-user), api-key (api-key) [disabled]",
+			"Data Sources:      mongodb-atlas (mongodb-atlas)",
+			"Functions:         2",
+			"Triggers:          1",
+			"Services:          2",
+			"",
+		}, "\n"))
+	})
+
+	t.Run("should print the summary as JSON with --output=json", func(t *testing.T) {
+		describeCommand, mockUI := setup()
+
+		exitCode := describeCommand.Run([]string{"--path=../testdata/simple_app_with_instance_data", "-o", "json"})
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+
+		var description map[string]interface{}
+		u.So(t, json.Unmarshal(mockUI.OutputWriter.Bytes(), &description), gc.ShouldBeNil)
+		u.So(t, description["client_app_id"], gc.ShouldEqual, "my-app-abcdef")
+		u.So(t, description["location"], gc.ShouldEqual, "IE")
+		u.So(t, description["data_sources"], gc.ShouldResemble, []interface{}{
+			map[string]interface{}{"name": "mongodb-atlas", "type": "mongodb-atlas"},
+		})
+		u.So(t, description["functions"], gc.ShouldEqual, 2)
+	})
+
+	t.Run("should reject an unknown output format", func(t *testing.T) {
+		describeCommand, mockUI := setup()
+
+		exitCode := describeCommand.Run([]string{"--app-id=my-app-abcdef", "--output=yaml"})
+		u.So(t, exitCode, gc.ShouldEqual, 1)
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, `unknown output format "yaml"`)
+	})
+
+	t.Run("should report a failure to fetch the summary", func(t *testing.T) {
+		describeCommand, mockUI := setup()
+		describeCommand.realmClient.(*u.MockRealmClient).ListServicesFn = func(groupID, appID string) ([]models.Service, error) {
+			return nil, errors.New("something bad happened")
+		}
+
+		exitCode := describeCommand.Run([]string{"--app-id=my-app-abcdef"})
+		u.So(t, exitCode, gc.ShouldEqual, 1)
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, "failed to describe app my-app-abcdef: something bad happened")
+	})
+}
+
+func TestAppListCommand(t *testing.T) {
+	setup := func(apps []*models.App) (*AppListCommand, *cli.MockUi, *[]api.AppFilter) {
+		mockUI := cli.NewMockUi()
+		cmd, err := NewAppListCommandFactory(mockUI)()
+		if err != nil {
+			panic(err)
+		}
+
+		var filters []api.AppFilter
+		listCommand := cmd.(*AppListCommand)
+		listCommand.storage = u.NewEmptyStorage()
+		listCommand.user = &user.User{
+			APIKey:      "my-api-key",
+			AccessToken: u.GenerateValidAccessToken(),
+		}
+		listCommand.realmClient = &u.MockRealmClient{
+			FindAppsFn: func(filter api.AppFilter) ([]*models.App, error) {
+				filters = append(filters, filter)
+				return apps, nil
+			},
+		}
+		return listCommand, mockUI, &filters
+	}
+
+	apps := []*models.App{
+		{GroupID: "group-1", ID: "app-1", ClientAppID: "store-abcde", Name: "store", Location: "IE", DeploymentModel: "LOCAL"},
+		{GroupID: "group-2", ID: "app-2", ClientAppID: "blog-abcde", Name: "blog"},
+		{GroupID: "group-2", ID: "app-3", ClientAppID: "store-admin-abcde", Name: "Store-Admin", Location: "US-VA", DeploymentModel: "GLOBAL"},
+	}
+
+	t.Run("should require the user to be logged in", func(t *testing.T) {
+		listCommand, mockUI, _ := setup(apps)
+		listCommand.user = nil
+
+		exitCode := listCommand.Run([]string{})
+		u.So(t, exitCode, gc.ShouldEqual, 1)
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, user.ErrNotLoggedIn.Error())
+	})
+
+	t.Run("should list the apps of every project by name", func(t *testing.T) {
+		listCommand, mockUI, filters := setup(apps)
+
+		exitCode := listCommand.Run([]string{})
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+		u.So(t, *filters, gc.ShouldResemble, []api.AppFilter{{}})
+		u.So(t, mockUI.OutputWriter.String(), gc.ShouldEqual, strings.Join([]string{
+			"NAME         APP ID             LOCATION",
+			"Store-Admin  store-admin-abcde  US-VA",
+			"blog         blog-abcde         -",
+			"store        store-abcde        IE",
+			"",
+		}, "\n"))
+	})
+
+	t.Run("should only list the apps of the project whose name contains the text", func(t *testing.T) {
+		listCommand, mockUI, filters := setup(apps)
+
+		exitCode := listCommand.Run([]string{"--project-id=group-2", "--name=STORE"})
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+		u.So(t, *filters, gc.ShouldResemble, []api.AppFilter{{GroupID: "group-2"}})
+		u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, "Store-Admin")
+		u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, "store-abcde")
+		u.So(t, mockUI.OutputWriter.String(), gc.ShouldNotContainSubstring, "blog")
+	})
+
+	t.Run("should print the apps as JSON with --output=json", func(t *testing.T) {
+		listCommand, mockUI, _ := setup(apps)
+
+		exitCode := listCommand.Run([]string{"-o", "json", "--name=blog"})
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+
+		var listed []map[string]interface{}
+		u.So(t, json.Unmarshal(mockUI.OutputWriter.Bytes(), &listed), gc.ShouldBeNil)
+		u.So(t, listed, gc.ShouldResemble, []map[string]interface{}{{
+			"name":             "blog",
+			"client_app_id":    "blog-abcde",
+			"id":               "app-2",
+			"group_id":         "group-2",
+			"location":         "",
+			"deployment_model": "",
+		}})
+	})
+
+	t.Run("should say when no apps match", func(t *testing.T) {
+		listCommand, mockUI, _ := setup(apps)
+
+		exitCode := listCommand.Run([]string{"--name=shop"})
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+		u.So(t, mockUI.OutputWriter.String(), gc.ShouldEqual, "No apps have a name containing \"shop\".\n")
+	})
+
+	t.Run("should print an empty JSON array when there are no apps", func(t *testing.T) {
+		listCommand, mockUI, _ := setup([]*models.App{})
+
+		exitCode := listCommand.Run([]string{"--output=json"})
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+		u.So(t, mockUI.OutputWriter.String(), gc.ShouldEqual, "[]\n")
+	})
+
+	t.Run("should report a failure to list the apps", func(t *testing.T) {
+		listCommand, mockUI, _ := setup(nil)
+		listCommand.realmClient.(*u.MockRealmClient).FindAppsFn = func(filter api.AppFilter) ([]*models.App, error) {
+			return nil, errors.New("something bad happened")
+		}
+
+		exitCode := listCommand.Run([]string{})
+		u.So(t, exitCode, gc.ShouldEqual, 1)
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, "failed to list apps: something bad happened")
+	})
+}
@@ -0,0 +1,130 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/10gen/realm-cli/models"
+	"github.com/10gen/realm-cli/utils"
+	"github.com/mitchellh/cli"
+)
+
+const applyFlagPlan = "plan"
+
+var (
+	errApplyPlanRequired = fmt.Errorf("a plan file (--%s=[string]) must be supplied", applyFlagPlan)
+)
+
+// NewApplyCommandFactory returns a new cli.CommandFactory given a cli.Ui
+func NewApplyCommandFactory(ui cli.Ui) cli.CommandFactory {
+	return func() (cli.Command, error) {
+		workingDirectory, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+
+		return &ApplyCommand{
+			BaseCommand: &BaseCommand{
+				Name: "apply",
+				UI:   ui,
+			},
+			workingDirectory:   workingDirectory,
+			draftRetryInterval: draftConflictRetryInterval,
+			writeToDirectory:   utils.WriteZipToDir,
+			writeAppConfigToFile: func(dest string, app models.AppInstanceData) error {
+				return app.MarshalFile(dest)
+			},
+		}, nil
+	}
+}
+
+// ApplyCommand is used to import a Realm App as planned by "import --plan-file"
+type ApplyCommand struct {
+	*BaseCommand
+
+	writeToDirectory     func(dest string, zipData io.Reader, overwrite bool) error
+	writeAppConfigToFile func(dest string, app models.AppInstanceData) error
+	workingDirectory     string
+	draftRetryInterval   time.Duration
+
+	flagPlan string
+}
+
+// Synopsis returns a one-liner description for this command
+func (ac *ApplyCommand) Synopsis() string {
+	return "Apply the changes planned by an import with --plan-file."
+}
+
+// Help returns long-form help information for this command
+func (ac *ApplyCommand) Help() string {
+	return `Import and deploy a realm application as planned by "realm-cli import --plan-file", without asking
+for confirmation. The changes are computed again first, and nothing is applied unless they are the same
+as the planned ones, i.e. neither the local directory nor the deployed app changed since planning.
+
+Usage: realm-cli apply --plan [string] [options]
+
+REQUIRED:
+  --plan [string]
+	A path to the plan file to apply.
+	` +
+		ac.BaseCommand.Help()
+}
+
+// Run executes the command
+func (ac *ApplyCommand) Run(args []string) int {
+	flags := ac.NewFlagSet()
+
+	flags.StringVar(&ac.flagPlan, applyFlagPlan, "", "")
+
+	if err := ac.BaseCommand.run(args); err != nil {
+		ac.UI.Error(err.Error())
+		return 1
+	}
+
+	if err := ac.apply(); err != nil {
+		ac.UI.Error(err.Error())
+		return 1
+	}
+
+	return 0
+}
+
+func (ac *ApplyCommand) apply() error {
+	if ac.flagPlan == "" {
+		return errApplyPlanRequired
+	}
+
+	plan, err := readImportPlan(ac.flagPlan)
+	if err != nil {
+		return err
+	}
+
+	ic := plan.importCommand(&ImportCommand{
+		BaseCommand: ac.BaseCommand,
+
+		writeToDirectory:     ac.writeToDirectory,
+		writeAppConfigToFile: ac.writeAppConfigToFile,
+		workingDirectory:     ac.workingDirectory,
+		draftRetryInterval:   ac.draftRetryInterval,
+		deployPollInterval:   deployPollInterval,
+
+		cacheInvalidationPollInterval: cacheInvalidationPollInterval,
+
+		flagHostingConcurrency: numWorkers,
+		flagCacheThreshold:     defaultCacheInvalidationThreshold,
+	})
+
+	if isAppArchive(ic.flagAppPath) {
+		appPath, cleanup, err := extractAppArchive(ic.flagAppPath)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+		ic.appArchivePath, ic.flagAppPath = ic.flagAppPath, appPath
+	}
+
+	dryRun := false
+	return ic.importApp(dryRun)
+}
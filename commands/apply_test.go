@@ -0,0 +1,213 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/10gen/realm-cli/api"
+	"github.com/10gen/realm-cli/models"
+	"github.com/10gen/realm-cli/user"
+	u "github.com/10gen/realm-cli/utils/test"
+	gc "github.com/smartystreets/goconvey/convey"
+
+	"github.com/mitchellh/cli"
+)
+
+func TestApplyCommand(t *testing.T) {
+	loggedInUser := &user.User{
+		APIKey:      "my-api-key",
+		AccessToken: u.GenerateValidAccessToken(),
+	}
+
+	newRealmClient := func(diffs ...string) *u.MockRealmClient {
+		realmClient := setUpBasicRealmClient()
+		realmClient.DiffFn = func(groupID, appID string, appData []byte, strategy string) ([]string, error) {
+			return diffs, nil
+		}
+		realmClient.FetchAppByGroupIDAndClientAppIDFn = func(groupID, clientAppID string) (*models.App, error) {
+			return &models.App{
+				GroupID: groupID,
+				ID:      "app-id",
+			}, nil
+		}
+		realmClient.ExportFn = func(groupID, appID string, strategy api.ExportStrategy) (string, io.ReadCloser, error) {
+			return "", u.NewZipResponseBody("../testdata/simple_app"), nil
+		}
+		return realmClient
+	}
+
+	planDir, err := ioutil.TempDir("", "realm-plan-")
+	u.So(t, err, gc.ShouldBeNil)
+	defer os.RemoveAll(planDir)
+
+	makePlan := func(t *testing.T, args ...string) string {
+		planPath := filepath.Join(planDir, strings.Replace(t.Name(), "/", "_", -1)+".json")
+
+		importCommand, mockUI := setUpBasicCommand()
+		importCommand.user = loggedInUser
+		realmClient := newRealmClient("sample-diff-contents")
+		importCommand.realmClient = realmClient
+
+		exitCode := importCommand.Run(append([]string{"--app-id=my-app-abcdef", "--path=../testdata/full_app", "--plan-file=" + planPath}, args...))
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+		u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, "Saved the plan to "+planPath)
+		u.So(t, realmClient.ImportFnCalls, gc.ShouldBeEmpty)
+
+		return planPath
+	}
+
+	setup := func(realmClient *u.MockRealmClient) (*ApplyCommand, *cli.MockUi) {
+		mockUI := cli.NewMockUi()
+		cmd, err := NewApplyCommandFactory(mockUI)()
+		u.So(t, err, gc.ShouldBeNil)
+
+		applyCommand := cmd.(*ApplyCommand)
+		applyCommand.storage = u.NewEmptyStorage()
+		applyCommand.user = loggedInUser
+		applyCommand.realmClient = realmClient
+		applyCommand.writeToDirectory = func(dest string, r io.Reader, overwrite bool) error {
+			return nil
+		}
+		applyCommand.writeAppConfigToFile = func(dest string, app models.AppInstanceData) error {
+			return nil
+		}
+		return applyCommand, mockUI
+	}
+
+	t.Run("it saves the planned changes", func(t *testing.T) {
+		plan, err := readImportPlan(makePlan(t))
+		u.So(t, err, gc.ShouldBeNil)
+
+		u.So(t, plan.GroupID, gc.ShouldEqual, "group-id")
+		u.So(t, plan.AppID, gc.ShouldEqual, "app-id")
+		u.So(t, plan.Strategy, gc.ShouldEqual, importStrategyMerge)
+		u.So(t, plan.Diffs, gc.ShouldResemble, []string{"sample-diff-contents"})
+		u.So(t, plan.Steps, gc.ShouldContain, "Deploy the draft")
+	})
+
+	t.Run("it applies a plan without confirmation if nothing changed since planning", func(t *testing.T) {
+		planPath := makePlan(t)
+
+		realmClient := newRealmClient("sample-diff-contents")
+		applyCommand, mockUI := setup(realmClient)
+
+		exitCode := applyCommand.Run([]string{"--plan=" + planPath})
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+		u.So(t, realmClient.ImportFnCalls, gc.ShouldHaveLength, 1)
+		u.So(t, mockUI.OutputWriter.String(), gc.ShouldNotContainSubstring, "Please confirm")
+	})
+
+	t.Run("it applies a plan to import only some entity groups", func(t *testing.T) {
+		planPath := makePlan(t, "--only=functions")
+
+		plan, err := readImportPlan(planPath)
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, plan.Only, gc.ShouldEqual, "functions")
+
+		realmClient := newRealmClient("sample-diff-contents")
+		applyCommand, mockUI := setup(realmClient)
+
+		exitCode := applyCommand.Run([]string{"--plan=" + planPath})
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+		u.So(t, realmClient.ImportFnCalls, gc.ShouldHaveLength, 1)
+	})
+
+	t.Run("it applies a plan to import the app as another config version", func(t *testing.T) {
+		planPath := makePlan(t, "--path=../testdata/simple_app", "--config-version=20180301", "--strict", "--strict-config-version")
+
+		plan, err := readImportPlan(planPath)
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, plan.ConfigVersion, gc.ShouldEqual, "20180301")
+		u.So(t, plan.Strict, gc.ShouldBeTrue)
+		u.So(t, plan.StrictConfigVersion, gc.ShouldBeTrue)
+
+		realmClient := newRealmClient("sample-diff-contents")
+		var imported map[string]interface{}
+		realmClient.ImportFn = func(ctx context.Context, groupID, appID string, appData []byte, strategy string) error {
+			return json.Unmarshal(appData, &imported)
+		}
+		applyCommand, mockUI := setup(realmClient)
+
+		exitCode := applyCommand.Run([]string{"--plan=" + planPath})
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+		u.So(t, imported["config_version"], gc.ShouldEqual, float64(20180301))
+	})
+
+	t.Run("it applies a plan made for a zipped app", func(t *testing.T) {
+		archivePath := filepath.Join(planDir, "full_app.zip")
+		archive, err := ioutil.ReadAll(u.NewZipResponseBody("../testdata/full_app"))
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, ioutil.WriteFile(archivePath, archive, 0600), gc.ShouldBeNil)
+
+		planPath := makePlan(t, "--path="+archivePath)
+
+		plan, err := readImportPlan(planPath)
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, plan.AppPath, gc.ShouldEqual, archivePath)
+
+		realmClient := newRealmClient("sample-diff-contents")
+		applyCommand, mockUI := setup(realmClient)
+
+		exitCode := applyCommand.Run([]string{"--plan=" + planPath})
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+		u.So(t, realmClient.ImportFnCalls, gc.ShouldHaveLength, 1)
+	})
+
+	t.Run("it fails if the deployed app changed since planning", func(t *testing.T) {
+		planPath := makePlan(t)
+
+		realmClient := newRealmClient("sample-diff-contents", "another-diff")
+		applyCommand, mockUI := setup(realmClient)
+
+		exitCode := applyCommand.Run([]string{"--plan=" + planPath})
+		u.So(t, exitCode, gc.ShouldEqual, 1)
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, "the deployed app has changed since the plan was made")
+		u.So(t, realmClient.ImportFnCalls, gc.ShouldBeEmpty)
+	})
+
+	t.Run("it fails if the local app changed since planning", func(t *testing.T) {
+		planPath := makePlan(t)
+
+		plan, err := readImportPlan(planPath)
+		u.So(t, err, gc.ShouldBeNil)
+		plan.AppPath, err = filepath.Abs("../testdata/simple_app")
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, writeImportPlan(planPath, plan), gc.ShouldBeNil)
+
+		realmClient := newRealmClient("sample-diff-contents")
+		applyCommand, mockUI := setup(realmClient)
+
+		exitCode := applyCommand.Run([]string{"--plan=" + planPath})
+		u.So(t, exitCode, gc.ShouldEqual, 1)
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, "has changed since the plan was made")
+		u.So(t, realmClient.ImportFnCalls, gc.ShouldBeEmpty)
+	})
+
+	t.Run("it requires a plan", func(t *testing.T) {
+		applyCommand, mockUI := setup(newRealmClient())
+
+		exitCode := applyCommand.Run(nil)
+		u.So(t, exitCode, gc.ShouldEqual, 1)
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, errApplyPlanRequired.Error())
+	})
+
+	t.Run("it does not plan an import confirmed up front", func(t *testing.T) {
+		importCommand, mockUI := setUpBasicCommand()
+		importCommand.user = loggedInUser
+
+		exitCode := importCommand.Run([]string{"--app-id=my-app-abcdef", "--path=../testdata/full_app", "--plan-file=plan.json", "-y"})
+		u.So(t, exitCode, gc.ShouldEqual, 1)
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, "--plan-file cannot be used with --yes")
+	})
+}
@@ -0,0 +1,123 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/10gen/realm-cli/models"
+
+	"github.com/mitchellh/cli"
+)
+
+const (
+	bulkFlagOutput      = "output"
+	bulkFlagConcurrency = "concurrency"
+	bulkOutputText      = "text"
+	bulkOutputJSON      = "json"
+)
+
+// The statuses of a bulkAppResult
+const (
+	bulkStatusSucceeded = "succeeded"
+	bulkStatusFailed    = "failed"
+)
+
+// bulkAppResult is the outcome of a bulk operation for a single app
+type bulkAppResult struct {
+	App     string      `json:"app"`
+	GroupID string      `json:"group_id"`
+	AppID   string      `json:"app_id"`
+	Status  string      `json:"status"`
+	Error   string      `json:"error,omitempty"`
+	Result  interface{} `json:"result,omitempty"`
+}
+
+// bulkReport is the outcome of a bulk operation across apps, as printed with --output=json
+type bulkReport struct {
+	Apps      []bulkAppResult `json:"apps"`
+	Succeeded int             `json:"succeeded"`
+	Failed    int             `json:"failed"`
+}
+
+// validateBulkFlags checks the flags shared by the commands which operate on many apps at once.
+// Both JSON output and concurrency rule out prompting for each app, so they require -y
+func validateBulkFlags(output string, concurrency int, yes bool) error {
+	switch output {
+	case bulkOutputText, bulkOutputJSON:
+	default:
+		return fmt.Errorf("unknown output format %q; accepted values are [%s|%s]", output, bulkOutputText, bulkOutputJSON)
+	}
+
+	if concurrency < 1 {
+		return fmt.Errorf("--%s must be at least 1", bulkFlagConcurrency)
+	}
+
+	if !yes && (output == bulkOutputJSON || concurrency > 1) {
+		return fmt.Errorf("--%s=%s and --%s greater than 1 require --yes, since apps cannot be confirmed one by one", bulkFlagOutput, bulkOutputJSON, bulkFlagConcurrency)
+	}
+
+	return nil
+}
+
+// processApps runs process for each app, processing up to concurrency apps at once, and reports the outcome
+// for each app in the order the apps were given, regardless of the order they finished in.
+// The result returned by process is reported even if it fails
+func processApps(apps []*models.App, concurrency int, process func(app *models.App) (interface{}, error)) *bulkReport {
+	report := &bulkReport{Apps: make([]bulkAppResult, len(apps))}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency && i < len(apps); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				app := apps[index]
+				result, err := process(app)
+
+				appResult := bulkAppResult{App: app.ClientAppID, GroupID: app.GroupID, AppID: app.ID, Status: bulkStatusSucceeded, Result: result}
+				if err != nil {
+					appResult.Status = bulkStatusFailed
+					appResult.Error = err.Error()
+				}
+				report.Apps[index] = appResult
+			}
+		}()
+	}
+
+	for index := range apps {
+		indexes <- index
+	}
+	close(indexes)
+	wg.Wait()
+
+	for _, appResult := range report.Apps {
+		if appResult.Status == bulkStatusFailed {
+			report.Failed++
+		} else {
+			report.Succeeded++
+		}
+	}
+
+	return report
+}
+
+// print writes the report as JSON, returning an error if any app failed so that the command exits with a failure
+func (br *bulkReport) print(ui cli.Ui) error {
+	data, err := json.MarshalIndent(br, "", "  ")
+	if err != nil {
+		return err
+	}
+	ui.Output(string(data))
+
+	return br.err()
+}
+
+// err returns an error summarizing the apps which failed, if any
+func (br *bulkReport) err() error {
+	if br.Failed == 0 {
+		return nil
+	}
+	return fmt.Errorf("failed for %d of %d apps", br.Failed, len(br.Apps))
+}
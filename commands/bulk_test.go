@@ -0,0 +1,94 @@
+package commands
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/10gen/realm-cli/models"
+	u "github.com/10gen/realm-cli/utils/test"
+	gc "github.com/smartystreets/goconvey/convey"
+)
+
+func TestProcessApps(t *testing.T) {
+	apps := []*models.App{
+		{GroupID: "group-id", ID: "app-1-id", ClientAppID: "app-1"},
+		{GroupID: "group-id", ID: "app-2-id", ClientAppID: "app-2"},
+		{GroupID: "group-id", ID: "app-3-id", ClientAppID: "app-3"},
+	}
+
+	t.Run("it reports each app in the order given regardless of when it finished", func(t *testing.T) {
+		// earlier apps take longer, so that they finish last
+		delays := map[string]time.Duration{"app-1": 30 * time.Millisecond, "app-2": 15 * time.Millisecond}
+
+		report := processApps(apps, 3, func(app *models.App) (interface{}, error) {
+			time.Sleep(delays[app.ClientAppID])
+			if app.ClientAppID == "app-2" {
+				return "partial", errors.New("oh noes")
+			}
+			return app.ID, nil
+		})
+
+		u.So(t, report, gc.ShouldResemble, &bulkReport{
+			Apps: []bulkAppResult{
+				{App: "app-1", GroupID: "group-id", AppID: "app-1-id", Status: bulkStatusSucceeded, Result: "app-1-id"},
+				{App: "app-2", GroupID: "group-id", AppID: "app-2-id", Status: bulkStatusFailed, Error: "oh noes", Result: "partial"},
+				{App: "app-3", GroupID: "group-id", AppID: "app-3-id", Status: bulkStatusSucceeded, Result: "app-3-id"},
+			},
+			Succeeded: 2,
+			Failed:    1,
+		})
+		u.So(t, report.err(), gc.ShouldResemble, errors.New("failed for 1 of 3 apps"))
+	})
+
+	t.Run("it processes no more apps at once than the concurrency", func(t *testing.T) {
+		var mu sync.Mutex
+		var running, maxRunning int
+
+		processApps(apps, 2, func(app *models.App) (interface{}, error) {
+			mu.Lock()
+			running++
+			if running > maxRunning {
+				maxRunning = running
+			}
+			mu.Unlock()
+
+			time.Sleep(10 * time.Millisecond)
+
+			mu.Lock()
+			running--
+			mu.Unlock()
+			return nil, nil
+		})
+
+		u.So(t, maxRunning, gc.ShouldBeLessThanOrEqualTo, 2)
+	})
+}
+
+func TestValidateBulkFlags(t *testing.T) {
+	for _, tc := range []struct {
+		Description   string
+		Output        string
+		Concurrency   int
+		Yes           bool
+		ExpectedError string
+	}{
+		{Description: "it accepts text output one app at a time", Output: bulkOutputText, Concurrency: 1},
+		{Description: "it accepts JSON output with -y", Output: bulkOutputJSON, Concurrency: 4, Yes: true},
+		{Description: "it rejects unknown output formats", Output: "yaml", Concurrency: 1, ExpectedError: `unknown output format "yaml"`},
+		{Description: "it rejects a concurrency below 1", Output: bulkOutputText, Concurrency: 0, ExpectedError: "--concurrency must be at least 1"},
+		{Description: "it requires -y for JSON output", Output: bulkOutputJSON, Concurrency: 1, ExpectedError: "require --yes"},
+		{Description: "it requires -y for concurrency", Output: bulkOutputText, Concurrency: 2, ExpectedError: "require --yes"},
+	} {
+		t.Run(tc.Description, func(t *testing.T) {
+			err := validateBulkFlags(tc.Output, tc.Concurrency, tc.Yes)
+			if tc.ExpectedError == "" {
+				u.So(t, err, gc.ShouldBeNil)
+			} else {
+				u.So(t, err, gc.ShouldNotBeNil)
+				u.So(t, err.Error(), gc.ShouldContainSubstring, tc.ExpectedError)
+			}
+		})
+	}
+}
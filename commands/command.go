@@ -9,10 +9,13 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
+	"time"
 
 	"github.com/10gen/realm-cli/api"
 	"github.com/10gen/realm-cli/api/mdbcloud"
+	"github.com/10gen/realm-cli/models"
 	"github.com/10gen/realm-cli/storage"
 	"github.com/10gen/realm-cli/user"
 	"github.com/10gen/realm-cli/utils"
@@ -23,11 +26,29 @@ import (
 )
 
 const (
-	flagAppIDName = "app-id"
+	flagAppIDName               = "app-id"
+	flagRetryIdempotentOnlyName = "retry-idempotent-only"
+	flagRetryAttemptsName       = "retry-attempts"
+	flagRetryBackoffName        = "retry-backoff"
+	flagProfileName             = "profile"
+	flagProxyName               = "proxy"
+	flagInsecureName            = "insecure"
+	flagDebugName               = "debug"
+
+	// noColorEnv disables colors when set to anything, as described at https://no-color.org
+	noColorEnv = "NO_COLOR"
+
+	// baseURLEnv and atlasBaseURLEnv are used for the base URLs when their flags are not given
+	baseURLEnv      = "REALM_BASE_URL"
+	atlasBaseURLEnv = "REALM_ATLAS_BASE_URL"
 )
 
 var (
 	errAppIDRequired = fmt.Errorf("an App ID (--%s=[string]) must be supplied to export an app", flagAppIDName)
+
+	errProfileWithConfigPath = fmt.Errorf("--%s cannot be used with --config-path", flagProfileName)
+
+	profileNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
 )
 
 // BaseCommand handles the parsing and execution of a command.
@@ -44,12 +65,35 @@ type BaseCommand struct {
 	realmClient api.RealmClient
 	user        *user.User
 	storage     *storage.Storage
+	transport   http.RoundTripper
 
 	flagConfigPath    string
+	flagProfile       string
 	flagColorDisabled bool
+	flagLogFormat     string
 	flagBaseURL       string
 	flagAtlasBaseURL  string
+	flagProxy         string
+	flagInsecure      bool
+	flagDebug         bool
 	flagYes           bool
+
+	flagRetryIdempotentOnly bool
+	flagRetryAttempts       int
+	flagRetryBackoff        time.Duration
+}
+
+// stringSliceFlag is a flag.Value collecting every value of a repeatable flag
+type stringSliceFlag []string
+
+func (s *stringSliceFlag) String() string {
+	return strings.Join(*s, ",")
+}
+
+// Set appends the value to the flag's values
+func (s *stringSliceFlag) Set(value string) error {
+	*s = append(*s, value)
+	return nil
 }
 
 // NewFlagSet builds and returns the default set of flags for all commands
@@ -58,11 +102,20 @@ func (c *BaseCommand) NewFlagSet() *flag.FlagSet {
 	set.Usage = func() {}
 
 	set.BoolVar(&c.flagColorDisabled, "disable-color", false, "")
+	set.BoolVar(&c.flagColorDisabled, "no-color", false, "")
+	set.StringVar(&c.flagLogFormat, flagLogFormatName, logFormatText, "")
 	set.BoolVar(&c.flagYes, "yes", false, "")
 	set.BoolVar(&c.flagYes, "y", false, "")
 	set.StringVar(&c.flagBaseURL, "base-url", api.DefaultBaseURL, "")
 	set.StringVar(&c.flagAtlasBaseURL, "atlas-base-url", api.DefaultAtlasBaseURL, "")
 	set.StringVar(&c.flagConfigPath, "config-path", "", "")
+	set.StringVar(&c.flagProfile, flagProfileName, "", "")
+	set.StringVar(&c.flagProxy, flagProxyName, "", "")
+	set.BoolVar(&c.flagInsecure, flagInsecureName, false, "")
+	set.BoolVar(&c.flagDebug, flagDebugName, false, "")
+	set.BoolVar(&c.flagRetryIdempotentOnly, flagRetryIdempotentOnlyName, true, "")
+	set.IntVar(&c.flagRetryAttempts, flagRetryAttemptsName, api.DefaultRetryAttempts, "")
+	set.DurationVar(&c.flagRetryBackoff, flagRetryBackoffName, api.DefaultRetryBackoff, "")
 
 	c.FlagSet = set
 
@@ -75,7 +128,16 @@ func (c *BaseCommand) Client() (api.Client, error) {
 		return c.client, nil
 	}
 
-	c.client = api.NewClient(c.flagBaseURL)
+	client := api.NewClientWithTransport(c.flagBaseURL, c.transport)
+	if c.flagDebug {
+		// each attempt of a retried request is logged
+		client = api.NewDebugClient(client, c.flagBaseURL, c.UI.Warn)
+	}
+
+	retryClient := api.NewRetryClient(client, c.flagRetryIdempotentOnly)
+	retryClient.MaxAttempts = c.flagRetryAttempts
+	retryClient.Backoff = c.flagRetryBackoff
+	c.client = retryClient
 
 	return c.client, nil
 }
@@ -91,7 +153,7 @@ func (c *BaseCommand) AtlasClient() (mdbcloud.Client, error) {
 		return nil, err
 	}
 
-	c.atlasClient = mdbcloud.NewClient(c.flagAtlasBaseURL).WithAuth(user.PublicAPIKey, user.PrivateAPIKey)
+	c.atlasClient = mdbcloud.NewClientWithTransport(c.flagAtlasBaseURL, c.transport).WithAuth(user.PublicAPIKey, user.PrivateAPIKey)
 
 	return c.atlasClient, nil
 }
@@ -164,6 +226,77 @@ func (c *BaseCommand) User() (*user.User, error) {
 	return u, nil
 }
 
+// appTarget is how a command finds the app it acts on, from its --app-id, --path and --project-id flags
+type appTarget struct {
+	appID            string
+	appPath          string
+	workingDirectory string
+	projectID        string
+
+	// localApp is set for the commands which act on the local app along with the deployed one,
+	// which need the local app even when the App ID is given
+	localApp bool
+
+	// errAppIDRequired is returned when no App ID is given and there is no local app declaring one
+	errAppIDRequired error
+}
+
+// resolveApp makes sure the user is logged in, then finds the deployed app given by the App ID of the target,
+// or else the one the local app belongs to. The directory of the local app is returned along with it, which
+// is left empty when the App ID is given and the target does not need the local app
+func (c *BaseCommand) resolveApp(target appTarget) (string, *models.App, api.RealmClient, error) {
+	currentUser, err := c.User()
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	if !currentUser.LoggedIn() {
+		return "", nil, nil, user.ErrNotLoggedIn
+	}
+
+	var appPath string
+	appID := target.appID
+	if appID == "" || target.localApp {
+		appPath, err = utils.ResolveAppDirectory(target.appPath, target.workingDirectory)
+		if err != nil {
+			if target.localApp {
+				return "", nil, nil, err
+			}
+			return "", nil, nil, target.errAppIDRequired
+		}
+
+		appInstanceData, err := utils.ResolveAppInstanceData(target.appID, appPath)
+		if err != nil {
+			return "", nil, nil, err
+		}
+		appID = appInstanceData.AppID()
+	}
+	if appID == "" {
+		return "", nil, nil, target.errAppIDRequired
+	}
+
+	realmClient, err := c.RealmClient()
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	app, err := fetchApp(realmClient, target.projectID, appID)
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	return appPath, app, realmClient, nil
+}
+
+// fetchApp finds the app with the given client App ID in the project given by projectID,
+// or else in any project of the user
+func fetchApp(realmClient api.RealmClient, projectID, clientAppID string) (*models.App, error) {
+	if projectID == "" {
+		return realmClient.FetchAppByClientAppID(clientAppID)
+	}
+	return realmClient.FetchAppByGroupIDAndClientAppID(projectID, clientAppID)
+}
+
 func (c *BaseCommand) run(args []string) error {
 	if c.FlagSet == nil {
 		c.NewFlagSet()
@@ -175,18 +308,57 @@ func (c *BaseCommand) run(args []string) error {
 		return err
 	}
 
-	if !c.flagColorDisabled && isatty.IsTerminal(os.Stdout.Fd()) {
-		c.UI = &cli.ColoredUi{
-			ErrorColor: cli.UiColorRed,
-			WarnColor:  cli.UiColorYellow,
-			Ui:         c.UI,
+	switch c.flagLogFormat {
+	case logFormatText:
+		if colorEnabled(c.flagColorDisabled, isatty.IsTerminal(os.Stdout.Fd())) {
+			c.UI = &cli.ColoredUi{
+				ErrorColor: cli.UiColorRed,
+				WarnColor:  cli.UiColorYellow,
+				Ui:         c.UI,
+			}
 		}
+	case logFormatJSON:
+		c.UI = &jsonUi{Ui: c.UI, now: time.Now}
+	default:
+		return errUnknownOption("log format", c.flagLogFormat, logFormats)
+	}
+
+	if !c.flagRetryIdempotentOnly {
+		c.UI.Warn(fmt.Sprintf(
+			"WARNING: --%s=false is set, so requests which are not idempotent are retried after transient errors as well. "+
+				"A retried request may apply its operation more than once, e.g. creating a resource twice.",
+			flagRetryIdempotentOnlyName,
+		))
 	}
 
-	if url := utils.CheckForNewCLIVersion(http.DefaultClient); url != "" {
+	if c.flagInsecure {
+		c.UI.Warn(fmt.Sprintf(
+			"WARNING: --%s is set, so TLS certificates are not verified. Only use it against servers you trust, "+
+				"e.g. a proxy with a self-signed certificate in a test environment.",
+			flagInsecureName,
+		))
+	}
+
+	if c.transport == nil {
+		transport, err := api.NewTransport(c.flagProxy, c.flagInsecure)
+		if err != nil {
+			return err
+		}
+		c.transport = transport
+	}
+
+	if url := utils.CheckForNewCLIVersion(c.httpClient()); url != "" {
 		c.UI.Info(url)
 	}
 
+	if c.flagProfile != "" {
+		configPath, err := profileConfigPath(c.flagProfile, c.flagConfigPath)
+		if err != nil {
+			return err
+		}
+		c.flagConfigPath = configPath
+	}
+
 	if c.storage == nil {
 		path, err := homedir.Expand(c.flagConfigPath)
 		if err != nil {
@@ -209,9 +381,92 @@ func (c *BaseCommand) run(args []string) error {
 		c.storage = storage.New(fileStrategy)
 	}
 
+	if err := c.applyBaseURLEnv(); err != nil {
+		return err
+	}
+
+	if err := c.applyConfigDefaults(); err != nil {
+		return err
+	}
+
+	return c.validateBaseURLs()
+}
+
+// applyBaseURLEnv sets the base URL flags which were not given from the environment, which takes precedence
+// over the defaults set with "config set"
+func (c *BaseCommand) applyBaseURLEnv() error {
+	given := map[string]bool{}
+	c.FlagSet.Visit(func(f *flag.Flag) {
+		given[f.Name] = true
+	})
+
+	for flagName, env := range map[string]string{"base-url": baseURLEnv, "atlas-base-url": atlasBaseURLEnv} {
+		value := os.Getenv(env)
+		if given[flagName] || value == "" {
+			continue
+		}
+		if err := c.FlagSet.Set(flagName, value); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
+// validateBaseURLs checks the base URLs are absolute http(s) URLs, trimming any trailing slash as the API
+// routes are appended to them
+func (c *BaseCommand) validateBaseURLs() error {
+	if err := validateBaseURL(c.flagBaseURL); err != nil {
+		return fmt.Errorf("invalid --base-url: %s", err)
+	}
+	if err := validateBaseURL(c.flagAtlasBaseURL); err != nil {
+		return fmt.Errorf("invalid --atlas-base-url: %s", err)
+	}
+
+	c.flagBaseURL = strings.TrimSuffix(c.flagBaseURL, "/")
+	c.flagAtlasBaseURL = strings.TrimSuffix(c.flagAtlasBaseURL, "/")
+	return nil
+}
+
+// httpClient returns an *http.Client for requests made outside of the API clients, such as downloads of hosting
+// assets, which goes through the same proxy as the API clients
+func (c *BaseCommand) httpClient() *http.Client {
+	return &http.Client{Transport: c.transport}
+}
+
+// getAssetAtURL downloads the asset at url
+func (c *BaseCommand) getAssetAtURL(url string) (io.ReadCloser, error) {
+	resp, err := c.httpClient().Get(url)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("downloading asset (url: %s) failed: response status code was %d", url, resp.StatusCode)
+	}
+	return resp.Body, nil
+}
+
+// profileConfigPath returns the path of the user configuration of the named profile. Each profile keeps its
+// configuration, and the caches kept alongside it, in a directory of its own, so logging in with one profile
+// leaves the session of every other profile as it is
+func profileConfigPath(profile, configPath string) (string, error) {
+	if configPath != "" {
+		return "", errProfileWithConfigPath
+	}
+
+	if !profileNamePattern.MatchString(profile) {
+		return "", fmt.Errorf("invalid profile name %q: only letters, digits, '_' and '-' are allowed", profile)
+	}
+
+	home, err := homedir.Dir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(home, ".config", "realm", "profiles", profile, "realm"), nil
+}
+
 // AskYesNo is used to prompt the user for yes/no input
 func (c *BaseCommand) AskYesNo(query string) (bool, error) {
 	if c.flagYes {
@@ -329,11 +584,62 @@ func (c *BaseCommand) Help() string {
   --config-path [string]
 	File to write user configuration data to (defaults to ~/.config/realm/realm)
 
-  --disable-color
-	Disable the use of colors in terminal output.
+  --profile [string]
+	The named profile to use, e.g. one per Realm account or environment. Each profile keeps its own login,
+	defaults and caches in ~/.config/realm/profiles/<name>, so a profile can be logged in to a different
+	account, and set a different base-url with "config set", without affecting the others. Cannot be used
+	with --config-path.
+
+  --base-url [string] (default: https://realm.mongodb.com)
+	The base URL of the Realm API, e.g. to target another region or a local server. Taken from the
+	REALM_BASE_URL environment variable, or else the base-url set with "config set", when not given.
+
+  --atlas-base-url [string] (default: https://cloud.mongodb.com)
+	The base URL of the Atlas API. Taken from the REALM_ATLAS_BASE_URL environment variable, or else
+	the atlas-base-url set with "config set", when not given.
+
+  --proxy [string]
+	The URL of the proxy to make requests through, e.g. http://proxy.example.com:3128. When not given, the
+	HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables are used.
+
+  --insecure
+	Do not verify TLS certificates, e.g. for a proxy with a self-signed certificate in a test environment.
+
+  --debug
+	Log the method, URL, status and timing of each request made to the Realm API. The values of headers
+	carrying credentials are redacted, and only the sizes of the bodies sent and received are logged.
+
+  --disable-color, --no-color
+	Disable the use of colors in terminal output. Colors are also disabled when the NO_COLOR environment
+	variable is set, or when the output is not a terminal, e.g. when it is redirected to a file.
+
+  --log-format [text|json] (default: text)
+	How messages are printed.
+	json - print each message as a JSON object on its own line, with its level ("info", "warn" or "error"),
+	timestamp and message, e.g. for log systems to ingest. Messages which are JSON themselves are
+	printed as the "data" of the object instead. Colors are disabled.
 
   -y, --yes
-	Bypass prompts. Provide this parameter if you do not want to be prompted for input.`
+	Bypass prompts. Provide this parameter if you do not want to be prompted for input.
+
+  --retry-idempotent-only [true|false] (default: true)
+	Only retry requests which failed with a transient error if making them again is safe. Other requests
+	are only retried when they could not be sent, e.g. when connecting failed. Set to false to retry every
+	request against a tolerant backend, at the risk of duplicate operations.
+
+  --retry-attempts [int] (default: 3)
+	How many times a request which failed with a transient error is made at most. Set to 1 to never retry.
+
+  --retry-backoff [duration] (default: 1s)
+	How long to wait before retrying a request, doubled after each retry and randomized to spread out retries.`
+}
+
+// colorEnabled reports whether output to a terminal should be colored
+func colorEnabled(flagColorDisabled, isTerminal bool) bool {
+	if flagColorDisabled || os.Getenv(noColorEnv) != "" {
+		return false
+	}
+	return isTerminal
 }
 
 func yay(s string) bool {
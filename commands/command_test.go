@@ -1,11 +1,17 @@
 package commands
 
 import (
+	"errors"
+	"io/ioutil"
 	"net/http"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/10gen/realm-cli/api"
 	"github.com/10gen/realm-cli/auth"
+	"github.com/10gen/realm-cli/models"
 	"github.com/10gen/realm-cli/user"
 	u "github.com/10gen/realm-cli/utils/test"
 	gc "github.com/smartystreets/goconvey/convey"
@@ -30,6 +36,114 @@ func TestBaseCommandClient(t *testing.T) {
 
 		u.So(t, base.client, gc.ShouldNotBeNil)
 	})
+
+	t.Run("should only retry idempotent requests by default", func(t *testing.T) {
+		mockUI := cli.NewMockUi()
+		base := &BaseCommand{UI: mockUI, storage: u.NewEmptyStorage()}
+		u.So(t, base.run([]string{}), gc.ShouldBeNil)
+
+		client, err := base.Client()
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, client.(*api.RetryClient).IdempotentOnly, gc.ShouldBeTrue)
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+	})
+
+	t.Run("should log each request with --debug", func(t *testing.T) {
+		base := &BaseCommand{UI: cli.NewMockUi(), storage: u.NewEmptyStorage()}
+		u.So(t, base.run([]string{"--debug"}), gc.ShouldBeNil)
+
+		client, err := base.Client()
+		u.So(t, err, gc.ShouldBeNil)
+		_, isDebugClient := client.(*api.RetryClient).Client.(*api.DebugClient)
+		u.So(t, isDebugClient, gc.ShouldBeTrue)
+	})
+
+	t.Run("should warn that every request is retried with --retry-idempotent-only=false", func(t *testing.T) {
+		mockUI := cli.NewMockUi()
+		base := &BaseCommand{UI: mockUI, storage: u.NewEmptyStorage()}
+		u.So(t, base.run([]string{"--retry-idempotent-only=false"}), gc.ShouldBeNil)
+
+		client, err := base.Client()
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, client.(*api.RetryClient).IdempotentOnly, gc.ShouldBeFalse)
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, "requests which are not idempotent are retried")
+	})
+}
+
+func TestBaseCommandBaseURL(t *testing.T) {
+	setup := func(defaults map[string]string) *BaseCommand {
+		storage := u.NewEmptyStorage()
+		u.So(t, storage.WriteUserConfig(&user.User{Defaults: defaults}), gc.ShouldBeNil)
+		return &BaseCommand{UI: cli.NewMockUi(), storage: storage}
+	}
+
+	t.Run("should use the environment over the default set with config set", func(t *testing.T) {
+		os.Setenv(baseURLEnv, "https://realm-staging.mongodb.com")
+		defer os.Unsetenv(baseURLEnv)
+
+		base := setup(map[string]string{"base-url": "https://realm-dev.mongodb.com"})
+		u.So(t, base.run([]string{}), gc.ShouldBeNil)
+		u.So(t, base.flagBaseURL, gc.ShouldEqual, "https://realm-staging.mongodb.com")
+	})
+
+	t.Run("should use the flag over the environment", func(t *testing.T) {
+		os.Setenv(baseURLEnv, "https://realm-staging.mongodb.com")
+		defer os.Unsetenv(baseURLEnv)
+
+		base := setup(nil)
+		u.So(t, base.run([]string{"--base-url=http://localhost:8080/"}), gc.ShouldBeNil)
+		u.So(t, base.flagBaseURL, gc.ShouldEqual, "http://localhost:8080")
+	})
+
+	t.Run("should reject a base URL which is not an http URL", func(t *testing.T) {
+		base := setup(nil)
+		err := base.run([]string{"--atlas-base-url=cloud.mongodb.com"})
+		u.So(t, err, gc.ShouldNotBeNil)
+		u.So(t, err.Error(), gc.ShouldContainSubstring, `invalid --atlas-base-url: "cloud.mongodb.com" is not a valid URL`)
+	})
+}
+
+func TestBaseCommandProfile(t *testing.T) {
+	t.Run("should keep the configuration of a profile in a directory of its own", func(t *testing.T) {
+		base := &BaseCommand{UI: cli.NewMockUi(), storage: u.NewEmptyStorage()}
+		u.So(t, base.run([]string{"--profile=prod"}), gc.ShouldBeNil)
+
+		u.So(t, filepath.ToSlash(base.flagConfigPath), gc.ShouldEndWith, ".config/realm/profiles/prod/realm")
+
+		cachePath, err := getCacheFilePath(base.flagConfigPath, "cache")
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, filepath.ToSlash(cachePath), gc.ShouldEndWith, ".config/realm/profiles/prod/cache")
+	})
+
+	t.Run("should not allow --profile with --config-path", func(t *testing.T) {
+		base := &BaseCommand{UI: cli.NewMockUi(), storage: u.NewEmptyStorage()}
+		err := base.run([]string{"--profile=prod", "--config-path=/tmp/realm"})
+		u.So(t, err, gc.ShouldResemble, errProfileWithConfigPath)
+	})
+
+	t.Run("should reject profile names which are not a single directory name", func(t *testing.T) {
+		base := &BaseCommand{UI: cli.NewMockUi(), storage: u.NewEmptyStorage()}
+		err := base.run([]string{"--profile=../prod"})
+		u.So(t, err, gc.ShouldNotBeNil)
+		u.So(t, err.Error(), gc.ShouldContainSubstring, `invalid profile name "../prod"`)
+	})
+}
+
+func TestBaseCommandProxy(t *testing.T) {
+	t.Run("should reject an invalid proxy URL", func(t *testing.T) {
+		base := &BaseCommand{UI: cli.NewMockUi(), storage: u.NewEmptyStorage()}
+		err := base.run([]string{"--proxy=proxy.example.com"})
+		u.So(t, err, gc.ShouldNotBeNil)
+		u.So(t, err.Error(), gc.ShouldContainSubstring, `"proxy.example.com" is not a valid proxy URL`)
+	})
+
+	t.Run("should warn that certificates are not verified with --insecure", func(t *testing.T) {
+		mockUI := cli.NewMockUi()
+		base := &BaseCommand{UI: mockUI, storage: u.NewEmptyStorage()}
+		u.So(t, base.run([]string{"--proxy=http://proxy.example.com:3128", "--insecure"}), gc.ShouldBeNil)
+		u.So(t, base.transport, gc.ShouldNotBeNil)
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, "TLS certificates are not verified")
+	})
 }
 
 func TestBaseCommandUser(t *testing.T) {
@@ -62,6 +176,73 @@ func TestBaseCommandUser(t *testing.T) {
 	})
 }
 
+func TestBaseCommandResolveApp(t *testing.T) {
+	errAppIDRequired := errors.New("an App ID is required")
+
+	appPath, err := ioutil.TempDir("", "realm-resolve-app-")
+	u.So(t, err, gc.ShouldBeNil)
+	defer os.RemoveAll(appPath)
+	u.So(t, ioutil.WriteFile(filepath.Join(appPath, "config.json"), []byte(`{"app_id":"local-app-abcde","name":"local-app"}`), 0600), gc.ShouldBeNil)
+
+	emptyDir, err := ioutil.TempDir("", "realm-resolve-app-")
+	u.So(t, err, gc.ShouldBeNil)
+	defer os.RemoveAll(emptyDir)
+
+	setup := func() *BaseCommand {
+		return &BaseCommand{
+			user: &user.User{APIKey: "my-api-key", AccessToken: u.GenerateValidAccessToken()},
+			realmClient: &u.MockRealmClient{
+				FetchAppByClientAppIDFn: func(clientAppID string) (*models.App, error) {
+					return &models.App{GroupID: "group-id", ID: "app-id", ClientAppID: clientAppID}, nil
+				},
+				FetchAppByGroupIDAndClientAppIDFn: func(groupID, clientAppID string) (*models.App, error) {
+					return &models.App{GroupID: groupID, ID: "app-id", ClientAppID: clientAppID}, nil
+				},
+			},
+		}
+	}
+
+	t.Run("should require the user to be logged in", func(t *testing.T) {
+		base := setup()
+		base.user = &user.User{}
+
+		_, _, _, err := base.resolveApp(appTarget{appID: "my-app-abcde", errAppIDRequired: errAppIDRequired})
+		u.So(t, err, gc.ShouldEqual, user.ErrNotLoggedIn)
+	})
+
+	t.Run("should find the app given by its App ID without a local app", func(t *testing.T) {
+		path, app, _, err := setup().resolveApp(appTarget{appID: "my-app-abcde", workingDirectory: emptyDir, errAppIDRequired: errAppIDRequired})
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, path, gc.ShouldBeEmpty)
+		u.So(t, app, gc.ShouldResemble, &models.App{GroupID: "group-id", ID: "app-id", ClientAppID: "my-app-abcde"})
+	})
+
+	t.Run("should find the app in the project given", func(t *testing.T) {
+		_, app, _, err := setup().resolveApp(appTarget{appID: "my-app-abcde", projectID: "project-id", errAppIDRequired: errAppIDRequired})
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, app.GroupID, gc.ShouldEqual, "project-id")
+	})
+
+	t.Run("should find the app the local app belongs to", func(t *testing.T) {
+		path, app, _, err := setup().resolveApp(appTarget{appPath: appPath, errAppIDRequired: errAppIDRequired})
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, path, gc.ShouldEqual, appPath)
+		u.So(t, app.ClientAppID, gc.ShouldEqual, "local-app-abcde")
+	})
+
+	t.Run("should return the local app along with the app given by its App ID", func(t *testing.T) {
+		path, app, _, err := setup().resolveApp(appTarget{appID: "my-app-abcde", appPath: appPath, localApp: true, errAppIDRequired: errAppIDRequired})
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, path, gc.ShouldEqual, appPath)
+		u.So(t, app.ClientAppID, gc.ShouldEqual, "my-app-abcde")
+	})
+
+	t.Run("should require an App ID without a local app", func(t *testing.T) {
+		_, _, _, err := setup().resolveApp(appTarget{workingDirectory: emptyDir, errAppIDRequired: errAppIDRequired})
+		u.So(t, err, gc.ShouldEqual, errAppIDRequired)
+	})
+}
+
 func TestBaseCommandAuthClient(t *testing.T) {
 	t.Run("with an empty token", func(t *testing.T) {
 		setup := func() *BaseCommand {
@@ -237,3 +418,32 @@ func TestBaseCommandAsk(t *testing.T) {
 		}
 	})
 }
+
+func TestColorEnabled(t *testing.T) {
+	noColor, hadNoColor := os.LookupEnv(noColorEnv)
+	defer func() {
+		if hadNoColor {
+			os.Setenv(noColorEnv, noColor)
+		} else {
+			os.Unsetenv(noColorEnv)
+		}
+	}()
+
+	for _, tc := range []struct {
+		description       string
+		noColor           string
+		flagColorDisabled bool
+		isTerminal        bool
+		expected          bool
+	}{
+		{description: "colors a terminal", isTerminal: true, expected: true},
+		{description: "does not color output which is not a terminal"},
+		{description: "does not color with --no-color", flagColorDisabled: true, isTerminal: true},
+		{description: "does not color with NO_COLOR", noColor: "1", isTerminal: true},
+	} {
+		t.Run(tc.description, func(t *testing.T) {
+			os.Setenv(noColorEnv, tc.noColor)
+			u.So(t, colorEnabled(tc.flagColorDisabled, tc.isTerminal), gc.ShouldEqual, tc.expected)
+		})
+	}
+}
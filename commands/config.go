@@ -0,0 +1,362 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/10gen/realm-cli/api"
+
+	"github.com/mitchellh/cli"
+)
+
+// configKey is a default which can be set with "config set", standing in for a flag of the same meaning
+type configKey struct {
+	name        string
+	flag        string
+	description string
+	validate    func(value string) error
+}
+
+var configKeys = []configKey{
+	{
+		name:        "diff-algorithm",
+		flag:        importFlagDiffAlgorithm,
+		description: "The diff algorithm used when --diff-algorithm is not given.",
+		validate: func(value string) error {
+			switch value {
+			case diffAlgorithmServer, diffAlgorithmClient:
+				return nil
+			}
+			return fmt.Errorf("unknown diff algorithm %q; accepted values are [%s|%s]", value, diffAlgorithmServer, diffAlgorithmClient)
+		},
+	},
+	{
+		name:        "project",
+		flag:        flagProjectIDName,
+		description: "The Atlas Project ID used when --project-id is not given.",
+		validate: func(value string) error {
+			if !isObjectIDHex(value) {
+				return fmt.Errorf("%q is not a valid Atlas Project ID", value)
+			}
+			return nil
+		},
+	},
+	{
+		name:        "base-url",
+		flag:        "base-url",
+		description: "The base URL of the Realm API used when --base-url is not given.",
+		validate:    validateBaseURL,
+	},
+	{
+		name:        "atlas-base-url",
+		flag:        "atlas-base-url",
+		description: "The base URL of the Atlas API used when --atlas-base-url is not given.",
+		validate:    validateBaseURL,
+	},
+}
+
+// validateBaseURL checks the value is an absolute http(s) URL
+func validateBaseURL(value string) error {
+	parsed, err := url.Parse(value)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("%q is not a valid URL; expected an http or https URL such as %s", value, api.DefaultBaseURL)
+	}
+	return nil
+}
+
+func findConfigKey(name string) (configKey, error) {
+	names := make([]string, 0, len(configKeys))
+	for _, key := range configKeys {
+		if key.name == name {
+			return key, nil
+		}
+		names = append(names, key.name)
+	}
+	sort.Strings(names)
+	return configKey{}, fmt.Errorf("unknown config key %q; known keys are [%s]", name, strings.Join(names, "|"))
+}
+
+// applyConfigDefaults sets each flag of the command which was not given to the default set for it, if any
+func (c *BaseCommand) applyConfigDefaults() error {
+	given := map[string]bool{}
+	c.FlagSet.Visit(func(f *flag.Flag) {
+		given[f.Name] = true
+	})
+
+	for _, key := range configKeys {
+		if given[key.flag] || c.FlagSet.Lookup(key.flag) == nil {
+			continue
+		}
+
+		user, err := c.User()
+		if err != nil {
+			return err
+		}
+
+		value, ok := user.Defaults[key.name]
+		if !ok {
+			continue
+		}
+
+		if err := c.FlagSet.Set(key.flag, value); err != nil {
+			return fmt.Errorf("failed to use the default %s: %s", key.name, err)
+		}
+	}
+
+	return nil
+}
+
+// NewConfigCommandFactory returns a new cli.CommandFactory given a cli.Ui
+func NewConfigCommandFactory(ui cli.Ui) cli.CommandFactory {
+	return func() (cli.Command, error) {
+		return &ConfigCommand{
+			BaseCommand: &BaseCommand{
+				Name: "config",
+				UI:   ui,
+			},
+		}, nil
+	}
+}
+
+// ConfigCommand is used to manage the defaults kept in the user configuration
+type ConfigCommand struct {
+	*BaseCommand
+}
+
+// Synopsis returns a one-liner description for this command
+func (cc *ConfigCommand) Synopsis() string {
+	return "Manage the defaults used when flags are not given."
+}
+
+// Help returns long-form help information for this command
+func (cc *ConfigCommand) Help() string {
+	return cc.Synopsis()
+}
+
+// Run executes the command
+func (cc *ConfigCommand) Run(args []string) int {
+	return cli.RunResultHelp
+}
+
+// configKeysHelp describes every config key for the help of the config commands
+func configKeysHelp() string {
+	var help strings.Builder
+	help.WriteString("KEYS:")
+	for _, key := range configKeys {
+		help.WriteString(fmt.Sprintf("\n  %s\n\t%s\n", key.name, key.description))
+	}
+	return help.String()
+}
+
+// NewConfigSetCommandFactory returns a new cli.CommandFactory given a cli.Ui
+func NewConfigSetCommandFactory(ui cli.Ui) cli.CommandFactory {
+	return func() (cli.Command, error) {
+		return &ConfigSetCommand{
+			BaseCommand: &BaseCommand{
+				Name: "set",
+				UI:   ui,
+			},
+		}, nil
+	}
+}
+
+// ConfigSetCommand is used to set a default in the user configuration
+type ConfigSetCommand struct {
+	*BaseCommand
+}
+
+// Synopsis returns a one-liner description for this command
+func (csc *ConfigSetCommand) Synopsis() string {
+	return "Set a default used when its flag is not given."
+}
+
+// Help returns long-form help information for this command
+func (csc *ConfigSetCommand) Help() string {
+	return `Set a default in your user configuration, used by every command which takes the flag it stands for
+when the flag is not given. Defaults are kept when you log out.
+
+Usage: realm-cli config set [key] [value] [options]
+
+` + configKeysHelp() + `
+OPTIONS:` +
+		csc.BaseCommand.Help()
+}
+
+// Run executes the command
+func (csc *ConfigSetCommand) Run(args []string) int {
+	if err := csc.BaseCommand.run(args); err != nil {
+		csc.UI.Error(err.Error())
+		return 1
+	}
+
+	if err := csc.set(); err != nil {
+		csc.UI.Error(err.Error())
+		return 1
+	}
+
+	return 0
+}
+
+func (csc *ConfigSetCommand) set() error {
+	if csc.NArg() != 2 {
+		return fmt.Errorf("expected a key and a value, e.g. 'realm-cli config set project <id>'")
+	}
+	name, value := csc.Arg(0), csc.Arg(1)
+
+	key, err := findConfigKey(name)
+	if err != nil {
+		return err
+	}
+
+	if err := key.validate(value); err != nil {
+		return err
+	}
+
+	user, err := csc.User()
+	if err != nil {
+		return err
+	}
+
+	if user.Defaults == nil {
+		user.Defaults = map[string]string{}
+	}
+	user.Defaults[key.name] = value
+
+	if err := csc.storage.WriteUserConfig(user); err != nil {
+		return err
+	}
+
+	csc.UI.Info(fmt.Sprintf("Set %s to %s", key.name, value))
+	return nil
+}
+
+// NewConfigGetCommandFactory returns a new cli.CommandFactory given a cli.Ui
+func NewConfigGetCommandFactory(ui cli.Ui) cli.CommandFactory {
+	return func() (cli.Command, error) {
+		return &ConfigGetCommand{
+			BaseCommand: &BaseCommand{
+				Name: "get",
+				UI:   ui,
+			},
+		}, nil
+	}
+}
+
+// ConfigGetCommand is used to print a default from the user configuration
+type ConfigGetCommand struct {
+	*BaseCommand
+}
+
+// Synopsis returns a one-liner description for this command
+func (cgc *ConfigGetCommand) Synopsis() string {
+	return "Print a default used when its flag is not given."
+}
+
+// Help returns long-form help information for this command
+func (cgc *ConfigGetCommand) Help() string {
+	return `Print a default from your user configuration. Fails if the default is not set.
+
+Usage: realm-cli config get [key] [options]
+
+` + configKeysHelp() + `
+OPTIONS:` +
+		cgc.BaseCommand.Help()
+}
+
+// Run executes the command
+func (cgc *ConfigGetCommand) Run(args []string) int {
+	if err := cgc.BaseCommand.run(args); err != nil {
+		cgc.UI.Error(err.Error())
+		return 1
+	}
+
+	if err := cgc.get(); err != nil {
+		cgc.UI.Error(err.Error())
+		return 1
+	}
+
+	return 0
+}
+
+func (cgc *ConfigGetCommand) get() error {
+	if cgc.NArg() != 1 {
+		return fmt.Errorf("expected a key, e.g. 'realm-cli config get project'")
+	}
+
+	key, err := findConfigKey(cgc.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	user, err := cgc.User()
+	if err != nil {
+		return err
+	}
+
+	value, ok := user.Defaults[key.name]
+	if !ok {
+		return fmt.Errorf("%s is not set", key.name)
+	}
+
+	cgc.UI.Output(value)
+	return nil
+}
+
+// NewConfigListCommandFactory returns a new cli.CommandFactory given a cli.Ui
+func NewConfigListCommandFactory(ui cli.Ui) cli.CommandFactory {
+	return func() (cli.Command, error) {
+		return &ConfigListCommand{
+			BaseCommand: &BaseCommand{
+				Name: "list",
+				UI:   ui,
+			},
+		}, nil
+	}
+}
+
+// ConfigListCommand is used to print every default of the user configuration
+type ConfigListCommand struct {
+	*BaseCommand
+}
+
+// Synopsis returns a one-liner description for this command
+func (clc *ConfigListCommand) Synopsis() string {
+	return "List the defaults used when flags are not given."
+}
+
+// Help returns long-form help information for this command
+func (clc *ConfigListCommand) Help() string {
+	return `List every key which can be set with "config set", along with its value if it is set.
+
+Usage: realm-cli config list [options]
+
+OPTIONS:` +
+		clc.BaseCommand.Help()
+}
+
+// Run executes the command
+func (clc *ConfigListCommand) Run(args []string) int {
+	if err := clc.BaseCommand.run(args); err != nil {
+		clc.UI.Error(err.Error())
+		return 1
+	}
+
+	user, err := clc.User()
+	if err != nil {
+		clc.UI.Error(err.Error())
+		return 1
+	}
+
+	for _, key := range configKeys {
+		if value, ok := user.Defaults[key.name]; ok {
+			clc.UI.Output(fmt.Sprintf("%s=%s", key.name, value))
+		} else {
+			clc.UI.Output(fmt.Sprintf("%s (not set)", key.name))
+		}
+	}
+
+	return 0
+}
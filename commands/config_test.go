@@ -0,0 +1,148 @@
+package commands
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/10gen/realm-cli/api"
+	"github.com/10gen/realm-cli/models"
+	"github.com/10gen/realm-cli/storage"
+	"github.com/10gen/realm-cli/user"
+	u "github.com/10gen/realm-cli/utils/test"
+	gc "github.com/smartystreets/goconvey/convey"
+
+	"github.com/mitchellh/cli"
+)
+
+func TestConfigCommands(t *testing.T) {
+	run := func(factory func(cli.Ui) cli.CommandFactory, storage *storage.Storage, args ...string) (int, *cli.MockUi) {
+		mockUI := cli.NewMockUi()
+		cmd, err := factory(mockUI)()
+		u.So(t, err, gc.ShouldBeNil)
+
+		switch c := cmd.(type) {
+		case *ConfigSetCommand:
+			c.storage = storage
+		case *ConfigGetCommand:
+			c.storage = storage
+		case *ConfigListCommand:
+			c.storage = storage
+		}
+
+		return cmd.Run(args), mockUI
+	}
+
+	t.Run("it sets and gets a default", func(t *testing.T) {
+		storage := u.NewEmptyStorage()
+
+		exitCode, mockUI := run(NewConfigSetCommandFactory, storage, "project", "5f3c2b1a0d9e8f7a6b5c4d3e")
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+
+		exitCode, mockUI = run(NewConfigGetCommandFactory, storage, "project")
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+		u.So(t, mockUI.OutputWriter.String(), gc.ShouldEqual, "5f3c2b1a0d9e8f7a6b5c4d3e\n")
+
+		exitCode, mockUI = run(NewConfigListCommandFactory, storage)
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+		u.So(t, mockUI.OutputWriter.String(), gc.ShouldEqual, "diff-algorithm (not set)\nproject=5f3c2b1a0d9e8f7a6b5c4d3e\nbase-url (not set)\natlas-base-url (not set)\n")
+	})
+
+	t.Run("it fails to get a default which is not set", func(t *testing.T) {
+		exitCode, mockUI := run(NewConfigGetCommandFactory, u.NewEmptyStorage(), "diff-algorithm")
+		u.So(t, exitCode, gc.ShouldEqual, 1)
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, "diff-algorithm is not set")
+	})
+
+	for _, tc := range []struct {
+		Description   string
+		Args          []string
+		ExpectedError string
+	}{
+		{
+			Description:   "it rejects unknown keys",
+			Args:          []string{"region", "US-VA"},
+			ExpectedError: `unknown config key "region"; known keys are [atlas-base-url|base-url|diff-algorithm|project]`,
+		},
+		{
+			Description:   "it rejects invalid values",
+			Args:          []string{"diff-algorithm", "fastest"},
+			ExpectedError: `unknown diff algorithm "fastest"`,
+		},
+		{
+			Description:   "it rejects invalid project IDs",
+			Args:          []string{"project", "my-project"},
+			ExpectedError: `"my-project" is not a valid Atlas Project ID`,
+		},
+		{
+			Description:   "it rejects invalid base URLs",
+			Args:          []string{"base-url", "realm.mongodb.com"},
+			ExpectedError: `"realm.mongodb.com" is not a valid URL`,
+		},
+		{
+			Description:   "it requires a key and a value",
+			Args:          []string{"project"},
+			ExpectedError: "expected a key and a value",
+		},
+	} {
+		t.Run(tc.Description, func(t *testing.T) {
+			storage := u.NewEmptyStorage()
+
+			exitCode, mockUI := run(NewConfigSetCommandFactory, storage, tc.Args...)
+			u.So(t, exitCode, gc.ShouldEqual, 1)
+			u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, tc.ExpectedError)
+
+			storedUser, err := storage.ReadUserConfig()
+			u.So(t, err, gc.ShouldBeNil)
+			u.So(t, storedUser.Defaults, gc.ShouldBeEmpty)
+		})
+	}
+}
+
+func TestConfigDefaults(t *testing.T) {
+	setup := func() *DiffCommand {
+		diffCommand, _ := setUpBasicDiffCommand()
+		diffCommand.user = &user.User{
+			APIKey:      "my-api-key",
+			AccessToken: u.GenerateValidAccessToken(),
+			Defaults:    map[string]string{"diff-algorithm": diffAlgorithmClient},
+		}
+
+		diffCommand.realmClient = &u.MockRealmClient{
+			DiffFn: func(groupID, appID string, appData []byte, strategy string) ([]string, error) {
+				return nil, errors.New("the server diff should not be called")
+			},
+			ExportFn: func(groupID, appID string, strategy api.ExportStrategy) (string, io.ReadCloser, error) {
+				return "full_app.zip", u.NewZipResponseBody("../testdata/full_app"), nil
+			},
+			FetchAppByClientAppIDFn: func(clientAppID string) (*models.App, error) {
+				return &models.App{GroupID: "group-id", ID: "app-id"}, nil
+			},
+		}
+		return diffCommand
+	}
+
+	t.Run("it uses a default for a flag which is not given", func(t *testing.T) {
+		diffCommand := setup()
+
+		exitCode := diffCommand.Run([]string{"--app-id=my-app-abcdef", "--path=../testdata/full_app"})
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+		u.So(t, diffCommand.flagDiffAlgorithm, gc.ShouldEqual, diffAlgorithmClient)
+	})
+
+	t.Run("it uses the default base URL for the client", func(t *testing.T) {
+		diffCommand := setup()
+		diffCommand.user.Defaults["base-url"] = "https://realm-staging.mongodb.com"
+
+		diffCommand.Run([]string{"--app-id=my-app-abcdef", "--path=../testdata/full_app"})
+		u.So(t, diffCommand.flagBaseURL, gc.ShouldEqual, "https://realm-staging.mongodb.com")
+	})
+
+	t.Run("it prefers the flag over the default", func(t *testing.T) {
+		diffCommand := setup()
+
+		diffCommand.Run([]string{"--app-id=my-app-abcdef", "--path=../testdata/full_app", "--diff-algorithm=server"})
+		u.So(t, diffCommand.flagDiffAlgorithm, gc.ShouldEqual, diffAlgorithmServer)
+	})
+}
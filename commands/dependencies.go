@@ -0,0 +1,176 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/10gen/realm-cli/api"
+	"github.com/10gen/realm-cli/models"
+
+	"github.com/mitchellh/cli"
+)
+
+var (
+	errDependenciesAppIDRequired = fmt.Errorf("an App ID (--%s=[string]) must be supplied to list dependencies", flagAppIDName)
+)
+
+// NewDependenciesCommandFactory returns a new cli.CommandFactory given a cli.Ui
+func NewDependenciesCommandFactory(ui cli.Ui) cli.CommandFactory {
+	return func() (cli.Command, error) {
+		return &DependenciesCommand{
+			BaseCommand: &BaseCommand{
+				Name: "dependencies",
+				UI:   ui,
+			},
+		}, nil
+	}
+}
+
+// DependenciesCommand is used to look into the dependencies deployed to Realm Apps
+type DependenciesCommand struct {
+	*BaseCommand
+}
+
+// Synopsis returns a one-liner description for this command
+func (dc *DependenciesCommand) Synopsis() string {
+	return "View the dependencies deployed to your Realm App."
+}
+
+// Help returns long-form help information for this command
+func (dc *DependenciesCommand) Help() string {
+	return dc.Synopsis()
+}
+
+// Run executes the command
+func (dc *DependenciesCommand) Run(args []string) int {
+	return cli.RunResultHelp
+}
+
+// NewDependenciesListCommandFactory returns a new cli.CommandFactory given a cli.Ui
+func NewDependenciesListCommandFactory(ui cli.Ui) cli.CommandFactory {
+	return func() (cli.Command, error) {
+		workingDirectory, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+
+		return &DependenciesListCommand{
+			BaseCommand: &BaseCommand{
+				Name: "list",
+				UI:   ui,
+			},
+			workingDirectory: workingDirectory,
+		}, nil
+	}
+}
+
+// DependenciesListCommand is used to list the packages deployed to a Realm App
+type DependenciesListCommand struct {
+	*BaseCommand
+
+	workingDirectory string
+
+	flagAppID     string
+	flagAppPath   string
+	flagProjectID string
+}
+
+// Synopsis returns a one-liner description for this command
+func (dlc *DependenciesListCommand) Synopsis() string {
+	return "List the dependencies deployed to your Realm App."
+}
+
+// Help returns long-form help information for this command
+func (dlc *DependenciesListCommand) Help() string {
+	return `List the names and versions of the packages deployed to your Realm App, e.g. to check what
+an import with --include-dependencies uploaded.
+
+Usage: realm-cli dependencies list [options]
+
+OPTIONS:
+  --path [string]
+	A path to the local directory containing your app.
+
+  --app-id [string]
+	The App ID for your app (i.e. the name of your app followed by a unique suffix, like "my-app-nysja").
+	Required if the local app does not specify it.
+
+  --project-id [string]
+	The Atlas Project ID.
+	` +
+		dlc.BaseCommand.Help()
+}
+
+// Run executes the command
+func (dlc *DependenciesListCommand) Run(args []string) int {
+	flags := dlc.NewFlagSet()
+
+	flags.StringVar(&dlc.flagAppID, flagAppIDName, "", "")
+	flags.StringVar(&dlc.flagAppPath, importFlagPath, "", "")
+	flags.StringVar(&dlc.flagProjectID, flagProjectIDName, "", "")
+
+	if err := dlc.BaseCommand.run(args); err != nil {
+		dlc.UI.Error(err.Error())
+		return 1
+	}
+
+	if err := dlc.list(); err != nil {
+		dlc.UI.Error(err.Error())
+		return 1
+	}
+
+	return 0
+}
+
+func (dlc *DependenciesListCommand) list() error {
+	_, app, realmClient, err := dlc.resolveApp(appTarget{
+		appID:            dlc.flagAppID,
+		appPath:          dlc.flagAppPath,
+		workingDirectory: dlc.workingDirectory,
+		projectID:        dlc.flagProjectID,
+		errAppIDRequired: errDependenciesAppIDRequired,
+	})
+	if err != nil {
+		return err
+	}
+
+	dependencies, err := realmClient.GetDependencies(app.GroupID, app.ID)
+	if err == api.ErrNoDependencies {
+		dlc.UI.Info(fmt.Sprintf("No dependencies have been uploaded to %s.", app.ClientAppID))
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to list dependencies: %s", err)
+	}
+
+	if len(dependencies.Packages) == 0 {
+		dlc.UI.Info(fmt.Sprintf("No dependencies found for %s.", app.ClientAppID))
+		return nil
+	}
+
+	dlc.UI.Output(dependenciesTable(dependencies.Packages))
+	return nil
+}
+
+// dependenciesTable formats the packages as a table, sorted by name
+func dependenciesTable(packages []models.DependencyPackage) string {
+	sorted := append([]models.DependencyPackage(nil), packages...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	var table strings.Builder
+	w := tabwriter.NewWriter(&table, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tVERSION")
+	for _, pkg := range sorted {
+		version := pkg.Version
+		if version == "" {
+			version = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\n", pkg.Name, version)
+	}
+	w.Flush()
+
+	return strings.TrimSuffix(table.String(), "\n")
+}
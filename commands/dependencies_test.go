@@ -0,0 +1,96 @@
+package commands
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/10gen/realm-cli/api"
+	"github.com/10gen/realm-cli/models"
+	"github.com/10gen/realm-cli/user"
+	u "github.com/10gen/realm-cli/utils/test"
+	gc "github.com/smartystreets/goconvey/convey"
+
+	"github.com/mitchellh/cli"
+)
+
+func TestDependenciesListCommand(t *testing.T) {
+	setup := func() (*DependenciesListCommand, *cli.MockUi) {
+		mockUI := cli.NewMockUi()
+		cmd, err := NewDependenciesListCommandFactory(mockUI)()
+		if err != nil {
+			panic(err)
+		}
+
+		listCommand := cmd.(*DependenciesListCommand)
+		listCommand.storage = u.NewEmptyStorage()
+		return listCommand, mockUI
+	}
+
+	t.Run("should require the user to be logged in", func(t *testing.T) {
+		listCommand, mockUI := setup()
+		exitCode := listCommand.Run([]string{"--app-id=my-app-abcdef"})
+		u.So(t, exitCode, gc.ShouldEqual, 1)
+
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, user.ErrNotLoggedIn.Error())
+	})
+
+	t.Run("when the user is logged in", func(t *testing.T) {
+		setupLoggedIn := func(getDependencies func(groupID, appID string) (*models.Dependencies, error)) (*DependenciesListCommand, *cli.MockUi) {
+			listCommand, mockUI := setup()
+			listCommand.user = &user.User{
+				APIKey:      "my-api-key",
+				AccessToken: u.GenerateValidAccessToken(),
+			}
+			listCommand.realmClient = &u.MockRealmClient{
+				FetchAppByClientAppIDFn: func(clientAppID string) (*models.App, error) {
+					return &models.App{GroupID: "group-id", ID: "app-id", ClientAppID: clientAppID}, nil
+				},
+				GetDependenciesFn: getDependencies,
+			}
+			return listCommand, mockUI
+		}
+
+		t.Run("should list the deployed packages by name", func(t *testing.T) {
+			listCommand, mockUI := setupLoggedIn(func(groupID, appID string) (*models.Dependencies, error) {
+				u.So(t, groupID, gc.ShouldEqual, "group-id")
+				u.So(t, appID, gc.ShouldEqual, "app-id")
+				return &models.Dependencies{Packages: []models.DependencyPackage{
+					{Name: "is-buffer", Version: "2.0.4"},
+					{Name: "axios", Version: "0.19.2"},
+					{Name: "@scope/pkg"},
+				}}, nil
+			})
+
+			exitCode := listCommand.Run([]string{"--app-id=my-app-abcdef"})
+			u.So(t, exitCode, gc.ShouldEqual, 0)
+			u.So(t, mockUI.OutputWriter.String(), gc.ShouldEqual, strings.Join([]string{
+				"NAME        VERSION",
+				"@scope/pkg  -",
+				"axios       0.19.2",
+				"is-buffer   2.0.4",
+				"",
+			}, "\n"))
+		})
+
+		t.Run("should report an app which never had dependencies uploaded", func(t *testing.T) {
+			listCommand, mockUI := setupLoggedIn(func(groupID, appID string) (*models.Dependencies, error) {
+				return nil, api.ErrNoDependencies
+			})
+
+			exitCode := listCommand.Run([]string{"--app-id=my-app-abcdef"})
+			u.So(t, exitCode, gc.ShouldEqual, 0)
+			u.So(t, mockUI.OutputWriter.String(), gc.ShouldEqual, "No dependencies have been uploaded to my-app-abcdef.\n")
+		})
+
+		t.Run("should report a failure to fetch the dependencies", func(t *testing.T) {
+			listCommand, mockUI := setupLoggedIn(func(groupID, appID string) (*models.Dependencies, error) {
+				return nil, errors.New("something bad happened")
+			})
+
+			exitCode := listCommand.Run([]string{"--app-id=my-app-abcdef"})
+			u.So(t, exitCode, gc.ShouldEqual, 1)
+			u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, "failed to list dependencies: something bad happened")
+		})
+	})
+}
@@ -0,0 +1,203 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/10gen/realm-cli/models"
+
+	"github.com/mitchellh/cli"
+)
+
+const (
+	deploymentsFlagLimit = "limit"
+
+	defaultDeploymentsLimit = 10
+
+	// deploymentTimeFormat is how the time each deployment was created at is printed
+	deploymentTimeFormat = "2006-01-02 15:04:05 MST"
+)
+
+var (
+	errDeploymentsAppIDRequired = fmt.Errorf("an App ID (--%s=[string]) must be supplied to list deployments", flagAppIDName)
+)
+
+// NewDeploymentsCommandFactory returns a new cli.CommandFactory given a cli.Ui
+func NewDeploymentsCommandFactory(ui cli.Ui) cli.CommandFactory {
+	return func() (cli.Command, error) {
+		return &DeploymentsCommand{
+			BaseCommand: &BaseCommand{
+				Name: "deployments",
+				UI:   ui,
+			},
+		}, nil
+	}
+}
+
+// DeploymentsCommand is used to look into the deployments of Realm Apps
+type DeploymentsCommand struct {
+	*BaseCommand
+}
+
+// Synopsis returns a one-liner description for this command
+func (dc *DeploymentsCommand) Synopsis() string {
+	return "View the deployments of your Realm App."
+}
+
+// Help returns long-form help information for this command
+func (dc *DeploymentsCommand) Help() string {
+	return dc.Synopsis()
+}
+
+// Run executes the command
+func (dc *DeploymentsCommand) Run(args []string) int {
+	return cli.RunResultHelp
+}
+
+// NewDeploymentsListCommandFactory returns a new cli.CommandFactory given a cli.Ui
+func NewDeploymentsListCommandFactory(ui cli.Ui) cli.CommandFactory {
+	return func() (cli.Command, error) {
+		workingDirectory, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+
+		return &DeploymentsListCommand{
+			BaseCommand: &BaseCommand{
+				Name: "list",
+				UI:   ui,
+			},
+			workingDirectory: workingDirectory,
+		}, nil
+	}
+}
+
+// DeploymentsListCommand is used to list the history of deployments of a Realm App
+type DeploymentsListCommand struct {
+	*BaseCommand
+
+	workingDirectory string
+
+	flagAppID     string
+	flagAppPath   string
+	flagProjectID string
+	flagLimit     int
+}
+
+// Synopsis returns a one-liner description for this command
+func (dlc *DeploymentsListCommand) Synopsis() string {
+	return "List the deployments of your Realm App."
+}
+
+// Help returns long-form help information for this command
+func (dlc *DeploymentsListCommand) Help() string {
+	return `List the deployments of your Realm App, newest first, along with their status,
+when they were created, and the draft they deployed.
+
+Usage: realm-cli deployments list [options]
+
+OPTIONS:
+  --path [string]
+	A path to the local directory containing your app.
+
+  --app-id [string]
+	The App ID for your app (i.e. the name of your app followed by a unique suffix, like "my-app-nysja").
+	Required if the local app does not specify it.
+
+  --project-id [string]
+	The Atlas Project ID.
+
+  --limit [int] (default: 10)
+	How many of the most recent deployments to list. Set to 0 to list every deployment.
+	` +
+		dlc.BaseCommand.Help()
+}
+
+// Run executes the command
+func (dlc *DeploymentsListCommand) Run(args []string) int {
+	flags := dlc.NewFlagSet()
+
+	flags.StringVar(&dlc.flagAppID, flagAppIDName, "", "")
+	flags.StringVar(&dlc.flagAppPath, importFlagPath, "", "")
+	flags.StringVar(&dlc.flagProjectID, flagProjectIDName, "", "")
+	flags.IntVar(&dlc.flagLimit, deploymentsFlagLimit, defaultDeploymentsLimit, "")
+
+	if err := dlc.BaseCommand.run(args); err != nil {
+		dlc.UI.Error(err.Error())
+		return 1
+	}
+
+	if err := dlc.list(); err != nil {
+		dlc.UI.Error(err.Error())
+		return 1
+	}
+
+	return 0
+}
+
+func (dlc *DeploymentsListCommand) list() error {
+	if dlc.flagLimit < 0 {
+		return fmt.Errorf("--%s must not be negative", deploymentsFlagLimit)
+	}
+
+	_, app, realmClient, err := dlc.resolveApp(appTarget{
+		appID:            dlc.flagAppID,
+		appPath:          dlc.flagAppPath,
+		workingDirectory: dlc.workingDirectory,
+		projectID:        dlc.flagProjectID,
+		errAppIDRequired: errDeploymentsAppIDRequired,
+	})
+	if err != nil {
+		return err
+	}
+
+	deployments, err := realmClient.ListDeployments(app.GroupID, app.ID)
+	if err != nil {
+		return fmt.Errorf("failed to list deployments: %s", err)
+	}
+
+	if len(deployments) == 0 {
+		dlc.UI.Info(fmt.Sprintf("No deployments found for %s.", app.ClientAppID))
+		return nil
+	}
+
+	dlc.UI.Output(deploymentsTable(deployments, dlc.flagLimit))
+	return nil
+}
+
+// deploymentsTable formats the deployments as a table, newest first, keeping at most limit of them unless it is 0
+func deploymentsTable(deployments []models.Deployment, limit int) string {
+	sorted := append([]models.Deployment(nil), deployments...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].CreatedAt != sorted[j].CreatedAt {
+			return sorted[i].CreatedAt > sorted[j].CreatedAt
+		}
+		// IDs are ObjectIDs, which start with the time they were generated at
+		return sorted[i].ID > sorted[j].ID
+	})
+	if limit > 0 && len(sorted) > limit {
+		sorted = sorted[:limit]
+	}
+
+	var table strings.Builder
+	w := tabwriter.NewWriter(&table, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tSTATUS\tCREATED\tDRAFT ID")
+	for _, deployment := range sorted {
+		created := "-"
+		if deployment.CreatedAt > 0 {
+			created = time.Unix(deployment.CreatedAt, 0).UTC().Format(deploymentTimeFormat)
+		}
+		draftID := deployment.DraftID
+		if draftID == "" {
+			draftID = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", deployment.ID, deployment.Status, created, draftID)
+	}
+	w.Flush()
+
+	return strings.TrimSuffix(table.String(), "\n")
+}
@@ -0,0 +1,104 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/10gen/realm-cli/models"
+	"github.com/10gen/realm-cli/user"
+	u "github.com/10gen/realm-cli/utils/test"
+	gc "github.com/smartystreets/goconvey/convey"
+
+	"github.com/mitchellh/cli"
+)
+
+func TestDeploymentsListCommand(t *testing.T) {
+	setup := func() (*DeploymentsListCommand, *cli.MockUi) {
+		mockUI := cli.NewMockUi()
+		cmd, err := NewDeploymentsListCommandFactory(mockUI)()
+		if err != nil {
+			panic(err)
+		}
+
+		listCommand := cmd.(*DeploymentsListCommand)
+		listCommand.storage = u.NewEmptyStorage()
+		return listCommand, mockUI
+	}
+
+	t.Run("should require the user to be logged in", func(t *testing.T) {
+		listCommand, mockUI := setup()
+		exitCode := listCommand.Run([]string{"--app-id=my-app-abcdef"})
+		u.So(t, exitCode, gc.ShouldEqual, 1)
+
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, user.ErrNotLoggedIn.Error())
+	})
+
+	t.Run("when the user is logged in", func(t *testing.T) {
+		deployments := []models.Deployment{
+			{ID: "5f0000000000000000000001", Status: models.DeploymentStatusSuccessful, DraftID: "draft-1", CreatedAt: 1600000000},
+			{ID: "5f0000000000000000000003", Status: models.DeploymentStatusFailed, DraftID: "draft-3", CreatedAt: 1600000200},
+			{ID: "5f0000000000000000000002", Status: models.DeploymentStatusSuccessful, CreatedAt: 1600000100},
+		}
+
+		setupLoggedIn := func() (*DeploymentsListCommand, *cli.MockUi) {
+			listCommand, mockUI := setup()
+			listCommand.user = &user.User{
+				APIKey:      "my-api-key",
+				AccessToken: u.GenerateValidAccessToken(),
+			}
+			listCommand.realmClient = &u.MockRealmClient{
+				FetchAppByClientAppIDFn: func(clientAppID string) (*models.App, error) {
+					return &models.App{GroupID: "group-id", ID: "app-id", ClientAppID: clientAppID}, nil
+				},
+				ListDeploymentsFn: func(groupID, appID string) ([]models.Deployment, error) {
+					u.So(t, groupID, gc.ShouldEqual, "group-id")
+					u.So(t, appID, gc.ShouldEqual, "app-id")
+					return deployments, nil
+				},
+			}
+			return listCommand, mockUI
+		}
+
+		t.Run("should list the deployments newest first", func(t *testing.T) {
+			listCommand, mockUI := setupLoggedIn()
+			exitCode := listCommand.Run([]string{"--app-id=my-app-abcdef"})
+			u.So(t, exitCode, gc.ShouldEqual, 0)
+
+			u.So(t, mockUI.OutputWriter.String(), gc.ShouldEqual, strings.Join([]string{
+				"ID                        STATUS      CREATED                  DRAFT ID",
+				"5f0000000000000000000003  failed      2020-09-13 12:30:00 UTC  draft-3",
+				"5f0000000000000000000002  successful  2020-09-13 12:28:20 UTC  -",
+				"5f0000000000000000000001  successful  2020-09-13 12:26:40 UTC  draft-1",
+				"",
+			}, "\n"))
+		})
+
+		t.Run("should list no more than the limit", func(t *testing.T) {
+			listCommand, mockUI := setupLoggedIn()
+			exitCode := listCommand.Run([]string{"--app-id=my-app-abcdef", "--limit=1"})
+			u.So(t, exitCode, gc.ShouldEqual, 0)
+
+			output := mockUI.OutputWriter.String()
+			u.So(t, output, gc.ShouldContainSubstring, "5f0000000000000000000003")
+			u.So(t, output, gc.ShouldNotContainSubstring, "5f0000000000000000000002")
+			u.So(t, output, gc.ShouldNotContainSubstring, "5f0000000000000000000001")
+		})
+
+		t.Run("should reject a negative limit", func(t *testing.T) {
+			listCommand, mockUI := setupLoggedIn()
+			exitCode := listCommand.Run([]string{"--app-id=my-app-abcdef", "--limit=-1"})
+			u.So(t, exitCode, gc.ShouldEqual, 1)
+
+			u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, "--limit must not be negative")
+		})
+
+		t.Run("should say when the app has no deployments", func(t *testing.T) {
+			listCommand, mockUI := setupLoggedIn()
+			deployments = nil
+			exitCode := listCommand.Run([]string{"--app-id=my-app-abcdef"})
+			u.So(t, exitCode, gc.ShouldEqual, 0)
+
+			u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, "No deployments found for my-app-abcdef.")
+		})
+	})
+}
@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"fmt"
 	"io"
 	"os"
 
@@ -39,12 +40,25 @@ type DiffCommand struct {
 	writeAppConfigToFile func(dest string, app models.AppInstanceData) error
 	workingDirectory     string
 
-	flagAppID          string
-	flagAppPath        string
-	flagAppName        string
-	flagGroupID        string
-	flagStrategy       string
-	flagIncludeHosting bool
+	flagAppID             string
+	flagAppPath           string
+	flagAppName           string
+	flagGroupID           string
+	flagToApp             string
+	flagStrategy          string
+	flagIncludeHosting    bool
+	flagIncludeDeps       bool
+	flagDiffAlgorithm     string
+	flagNoRenameDetection bool
+	flagRenameThreshold   float64
+	flagAllowMismatch     bool
+	flagStrictVersion     bool
+	flagOutput            string
+	flagTimings           bool
+	flagExitCode          bool
+	flagBaseline          string
+	flagIgnoreFields      stringSliceFlag
+	flagOnly              string
 }
 
 // Help returns long-form help information for this command
@@ -57,13 +71,81 @@ REQUIRED:
 
 OPTIONS:
   --path [string]
-	A path to the local directory containing your app.
+	A path to the local directory containing your app, or to a zipped app such as one written by "export --format=zip".
 
   --project-id [string]
 	The Atlas Project ID.
 
+  --to-app [string]
+	The app to diff against, given by either its App ID or its internal ObjectID, instead of --app-id.
+
   --include-hosting
-	Upload static assets from "/hosting" directory.
+	Also diff the static assets in the "/hosting" directory, listing the files to add, remove and modify
+	followed by how many of each there are.
+
+  --include-dependencies
+	Include the node_modules archive within the "/functions" directory in the diff if it changed since the last import.
+
+  --diff-algorithm [server|client] (default: server)
+	How the changes to your app are computed.
+	server - ask Realm for a dry-run import. Cheap to transfer, but can be slow to compute for large apps.
+	client - export the deployed app and compare it against the local directory resource by resource.
+	Avoids the server-side dry-run at the cost of downloading the full app configuration.
+	Changes to the Sync configuration of the app and its services are also listed field by field,
+	along with how they may disrupt the clients syncing with the app.
+	With either algorithm, removing the schema of a collection, removing a property from a schema or
+	changing the type of a property is marked with "!!" as a destructive change which may delete data.
+
+  --no-rename-detection
+	With --diff-algorithm=client, show a renamed resource as one resource removed and another added,
+	instead of pairing up removed and added resources with similar contents as a single rename.
+
+  --rename-threshold [float] (default: 0.5)
+	With --diff-algorithm=client, how similar a removed and an added resource must be, from just above 0
+	to 1 for identical, to be shown as a rename. Functions are compared by their source, line by line.
+	A rename whose contents changed as well is marked as modified.
+
+  --strict-config-version
+	Fail unless the app declares its "config_version", as well as when it differs from the version the
+	deployed app is deployed with. The version is not checked otherwise.
+
+  --allow-version-mismatch
+	With --strict-config-version, diff the app even if its "config_version" differs from the version of
+	the deployed app, warning about it instead of failing.
+
+  -o [text|json], --output [text|json] (default: text)
+	How the diff should be printed.
+	json - print the diff as a JSON object, listing the app configuration, hosting and dependency changes
+	apart as well as together. With --diff-algorithm=client, each change also lists the local file
+	that produced it, relative to the app directory. With --include-hosting, the hosting changes are
+	also broken down by path.
+
+  --baseline [string]
+	A path to an exported app archive (e.g. from "export --format=zip") that both the local
+	directory and the deployed app started from. Changes are then split into those made locally,
+	those made to the deployed app, and those made on both sides in conflicting ways.
+	Always downloads the deployed app, as with --diff-algorithm=client.
+
+  --ignore-field [string]
+	A field to leave out of the changes, such as a timestamp the server rewrites on every deploy.
+	Can be given more than once. Fields can also be listed one per line in a ".diffignore" file
+	in the app directory, where blank lines and lines starting with "#" are skipped.
+	A field is a dot-separated path into the app configuration, where "*" matches any single part.
+	Resources are matched by name, and the fields of functions and services are also looked up
+	in their config, e.g. "functions.*.last_modified" or "services.mongodb-atlas.config.clusterName".
+	Requires --diff-algorithm=client or --baseline.
+
+  --only [string]
+	A comma-separated list of the groups of entities to diff, e.g. "functions,triggers", as "import --only" would import them.
+	Accepted groups are auth_providers, functions, graphql, services, triggers and values.
+
+  --exit-code
+	Exit with code 3 when the deployed app differs from the local one, including its hosting assets and dependencies
+	with --include-hosting and --include-dependencies, and with code 0 when they are identical, e.g. to detect drift in CI.
+	Errors still exit with code 1. The diff is printed either way.
+
+  --timings
+	Print how long computing the diff took. With --output=json, the timings are included in the output.
 	` +
 		dc.BaseCommand.Help()
 }
@@ -80,14 +162,55 @@ func (dc *DiffCommand) Run(args []string) int {
 	flags.StringVar(&dc.flagAppID, flagAppIDName, "", "")
 	flags.StringVar(&dc.flagAppPath, importFlagPath, "", "")
 	flags.StringVar(&dc.flagGroupID, flagProjectIDName, "", "")
+	flags.StringVar(&dc.flagToApp, importFlagToApp, "", "")
 	flags.BoolVar(&dc.flagIncludeHosting, importFlagIncludeHosting, false, "")
+	flags.BoolVar(&dc.flagIncludeDeps, importFlagIncludeDependencies, false, "")
 	flags.StringVar(&dc.flagStrategy, importFlagStrategy, importStrategyMerge, "")
+	flags.StringVar(&dc.flagDiffAlgorithm, importFlagDiffAlgorithm, diffAlgorithmServer, "")
+	flags.BoolVar(&dc.flagNoRenameDetection, importFlagNoRenameDetection, false, "")
+	flags.Float64Var(&dc.flagRenameThreshold, importFlagRenameThreshold, utils.DefaultRenameThreshold, "")
+	flags.BoolVar(&dc.flagStrictVersion, importFlagStrictConfigVersion, false, "")
+	flags.BoolVar(&dc.flagAllowMismatch, importFlagVersionMismatch, false, "")
+	flags.StringVar(&dc.flagOutput, diffFlagOutput, diffOutputText, "")
+	flags.StringVar(&dc.flagOutput, "o", diffOutputText, "")
+	flags.BoolVar(&dc.flagTimings, importFlagTimings, false, "")
+	flags.BoolVar(&dc.flagExitCode, diffFlagExitCode, false, "")
+	flags.StringVar(&dc.flagBaseline, diffFlagBaseline, "", "")
+	flags.Var(&dc.flagIgnoreFields, diffFlagIgnoreField, "")
+	flags.StringVar(&dc.flagOnly, importFlagOnly, "", "")
 
 	if err := dc.BaseCommand.run(args); err != nil {
 		dc.UI.Error(err.Error())
 		return 1
 	}
 
+	switch dc.flagOutput {
+	case diffOutputText, diffOutputJSON:
+	default:
+		dc.UI.Error(fmt.Sprintf("unknown output format %q; accepted values are [%s|%s]", dc.flagOutput, diffOutputText, diffOutputJSON))
+		return 1
+	}
+
+	if len(dc.flagIgnoreFields) > 0 && dc.flagDiffAlgorithm != diffAlgorithmClient && dc.flagBaseline == "" {
+		dc.UI.Error(fmt.Sprintf("--%s requires --%s=%s", diffFlagIgnoreField, importFlagDiffAlgorithm, diffAlgorithmClient))
+		return 1
+	}
+
+	if dc.flagToApp != "" && dc.flagAppID != "" {
+		dc.UI.Error(fmt.Sprintf("--%s cannot be used with --%s", importFlagToApp, flagAppIDName))
+		return 1
+	}
+
+	if isAppArchive(dc.flagAppPath) {
+		appPath, cleanup, err := extractAppArchive(dc.flagAppPath)
+		if err != nil {
+			dc.UI.Error(err.Error())
+			return 1
+		}
+		defer cleanup()
+		dc.flagAppPath = appPath
+	}
+
 	ic := &ImportCommand{
 		BaseCommand: dc.BaseCommand,
 
@@ -95,18 +218,38 @@ func (dc *DiffCommand) Run(args []string) int {
 		writeAppConfigToFile: dc.writeAppConfigToFile,
 		workingDirectory:     dc.workingDirectory,
 
-		flagAppID:          dc.flagAppID,
-		flagAppPath:        dc.flagAppPath,
-		flagAppName:        dc.flagAppName,
-		flagGroupID:        dc.flagGroupID,
-		flagStrategy:       dc.flagStrategy,
-		flagIncludeHosting: dc.flagIncludeHosting,
+		flagAppID:               dc.flagAppID,
+		flagAppPath:             dc.flagAppPath,
+		flagAppName:             dc.flagAppName,
+		flagGroupID:             dc.flagGroupID,
+		flagToApp:               dc.flagToApp,
+		flagStrategy:            dc.flagStrategy,
+		flagIncludeHosting:      dc.flagIncludeHosting,
+		flagIncludeDependencies: dc.flagIncludeDeps,
+		flagDiffAlgorithm:       dc.flagDiffAlgorithm,
+		flagNoRenameDetection:   dc.flagNoRenameDetection,
+		flagRenameThreshold:     dc.flagRenameThreshold,
+		flagStrictConfigVersion: dc.flagStrictVersion,
+		flagAllowMismatch:       dc.flagAllowMismatch,
+		flagDiffOutput:          dc.flagOutput,
+		flagTimings:             dc.flagTimings,
+		flagBaseline:            dc.flagBaseline,
+		flagIgnoreFields:        dc.flagIgnoreFields,
+		flagOnly:                dc.flagOnly,
 	}
 
 	dryRun := true
-	if err := ic.importApp(dryRun); err != nil {
+	err := ic.importApp(dryRun)
+	if dc.flagTimings && dc.flagOutput == diffOutputText {
+		ic.timings.print(dc.UI)
+	}
+	if err != nil {
 		dc.UI.Error(err.Error())
 		return 1
 	}
+
+	if dc.flagExitCode && !ic.noChanges {
+		return exitCodeChanges
+	}
 	return 0
 }
@@ -1,11 +1,20 @@
 package commands
 
 import (
+	"encoding/json"
+	"errors"
 	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/10gen/realm-cli/api"
+	"github.com/10gen/realm-cli/hosting"
 	"github.com/10gen/realm-cli/models"
 	"github.com/10gen/realm-cli/user"
+	"github.com/10gen/realm-cli/utils"
 	u "github.com/10gen/realm-cli/utils/test"
 	"github.com/mitchellh/cli"
 	gc "github.com/smartystreets/goconvey/convey"
@@ -65,11 +74,25 @@ func TestDiffCommand(t *testing.T) {
 			return diffCommand, mockUI
 		}
 
+		baselineDir, err := ioutil.TempDir("", "realm-baseline-")
+		u.So(t, err, gc.ShouldBeNil)
+		defer os.RemoveAll(baselineDir)
+
+		writeBaseline := func(appDir string) string {
+			path := filepath.Join(baselineDir, filepath.Base(appDir)+".zip")
+			data, err := ioutil.ReadAll(u.NewZipResponseBody(appDir))
+			u.So(t, err, gc.ShouldBeNil)
+			u.So(t, ioutil.WriteFile(path, data, 0600), gc.ShouldBeNil)
+			return path
+		}
+		fullAppBaseline, simpleAppBaseline := writeBaseline("../testdata/full_app"), writeBaseline("../testdata/simple_app")
+
 		type testCase struct {
 			Description      string
 			Args             []string
 			ExpectedExitCode int
 			ExpectedError    string
+			ExpectedOutput   string
 			WorkingDirectory string
 			RealmClient      u.MockRealmClient
 		}
@@ -97,6 +120,291 @@ func TestDiffCommand(t *testing.T) {
 					},
 				},
 			},
+			{
+				Description:      "it fails if given an unknown diff algorithm",
+				Args:             append([]string{"--path=../testdata/full_app", "--diff-algorithm=fastest"}, validArgs...),
+				ExpectedExitCode: 1,
+				ExpectedError:    `unknown diff algorithm "fastest"`,
+			},
+			{
+				Description:      "it diffs against an export of the app with the client algorithm",
+				Args:             append([]string{"--path=../testdata/full_app", "--diff-algorithm=client"}, validArgs...),
+				ExpectedExitCode: 0,
+				ExpectedOutput:   "Deployed app is identical to proposed version",
+				RealmClient: u.MockRealmClient{
+					DiffFn: func(groupID, appID string, appData []byte, strategy string) ([]string, error) {
+						return nil, errors.New("the server diff should not be called")
+					},
+					ExportFn: func(groupID, appID string, strategy api.ExportStrategy) (string, io.ReadCloser, error) {
+						return "full_app.zip", u.NewZipResponseBody("../testdata/full_app"), nil
+					},
+					FetchAppByClientAppIDFn: func(clientAppID string) (*models.App, error) {
+						return &models.App{
+							GroupID: "group-id",
+							ID:      "app-id",
+						}, nil
+					},
+				},
+			},
+			{
+				Description:      "it prints the diff as JSON linking each change to its local file",
+				Args:             append([]string{"--path=../testdata/full_app", "--diff-algorithm=client", "-o", "json"}, validArgs...),
+				ExpectedExitCode: 0,
+				ExpectedOutput:   `"path": "functions/function_a/source.js"`,
+				RealmClient: u.MockRealmClient{
+					ExportFn: func(groupID, appID string, strategy api.ExportStrategy) (string, io.ReadCloser, error) {
+						return "simple_app.zip", u.NewZipResponseBody("../testdata/simple_app"), nil
+					},
+					FetchAppByClientAppIDFn: func(clientAppID string) (*models.App, error) {
+						return &models.App{
+							GroupID: "group-id",
+							ID:      "app-id",
+						}, nil
+					},
+				},
+			},
+			{
+				Description:      "it lists the app configuration and dependency changes apart in the JSON output",
+				Args:             append([]string{"--path=../testdata/app_with_dependencies", "--include-dependencies", "--config-path=../testdata/configs/tmp/config.json", "-o", "json"}, validArgs...),
+				ExpectedExitCode: 0,
+				ExpectedOutput:   "\"app_diffs\": [\n    \"sample-diff-contents\"\n  ],\n  \"hosting_diffs\": [],\n  \"dependency_diffs\": [\n    \"New Dependencies:\",\n    \"\\t+ dependency: axios@0.19.0\",",
+				RealmClient: u.MockRealmClient{
+					DiffFn: func(groupID, appID string, appData []byte, strategy string) ([]string, error) {
+						return []string{"sample-diff-contents"}, nil
+					},
+					FetchAppByClientAppIDFn: func(clientAppID string) (*models.App, error) {
+						return &models.App{
+							GroupID: "group-id",
+							ID:      "app-id",
+						}, nil
+					},
+				},
+			},
+			{
+				Description:      "it lists every dependency as new when the app has none uploaded",
+				Args:             append([]string{"--path=../testdata/app_with_dependencies", "--include-dependencies", "--config-path=../testdata/configs/tmp/config.json", "-o", "json"}, validArgs...),
+				ExpectedExitCode: 0,
+				ExpectedOutput:   "\"dependency_diffs\": [\n    \"New Dependencies:\",\n    \"\\t+ dependency: axios@0.19.0\",",
+				RealmClient: u.MockRealmClient{
+					DiffFn: func(groupID, appID string, appData []byte, strategy string) ([]string, error) {
+						return []string{"sample-diff-contents"}, nil
+					},
+					ExportDependencyFn: func(groupID, appID string) (string, io.ReadCloser, error) {
+						return "", nil, api.ErrNoDependencies
+					},
+					FetchAppByClientAppIDFn: func(clientAppID string) (*models.App, error) {
+						return &models.App{
+							GroupID: "group-id",
+							ID:      "app-id",
+						}, nil
+					},
+				},
+			},
+			{
+				Description:      "it fails when the dependencies of the app cannot be exported",
+				Args:             append([]string{"--path=../testdata/app_with_dependencies", "--include-dependencies", "--config-path=../testdata/configs/tmp/config.json"}, validArgs...),
+				ExpectedExitCode: 1,
+				ExpectedError:    "failed to export dependencies",
+				RealmClient: u.MockRealmClient{
+					DiffFn: func(groupID, appID string, appData []byte, strategy string) ([]string, error) {
+						return []string{"sample-diff-contents"}, nil
+					},
+					ExportDependencyFn: func(groupID, appID string) (string, io.ReadCloser, error) {
+						return "", nil, errors.New("failed to export dependencies")
+					},
+					FetchAppByClientAppIDFn: func(clientAppID string) (*models.App, error) {
+						return &models.App{
+							GroupID: "group-id",
+							ID:      "app-id",
+						}, nil
+					},
+				},
+			},
+			{
+				Description:      "it reports that nothing changed in the JSON output",
+				Args:             append([]string{"--path=../testdata/full_app", "--diff-algorithm=client", "-o", "json"}, validArgs...),
+				ExpectedExitCode: 0,
+				ExpectedOutput:   `"changed": false`,
+				RealmClient: u.MockRealmClient{
+					ExportFn: func(groupID, appID string, strategy api.ExportStrategy) (string, io.ReadCloser, error) {
+						return "full_app.zip", u.NewZipResponseBody("../testdata/full_app"), nil
+					},
+					FetchAppByClientAppIDFn: func(clientAppID string) (*models.App, error) {
+						return &models.App{
+							GroupID: "group-id",
+							ID:      "app-id",
+						}, nil
+					},
+				},
+			},
+			{
+				Description:      "it does not report changes made the same way locally and remotely since the baseline",
+				Args:             append([]string{"--path=../testdata/full_app", "--baseline=" + simpleAppBaseline}, validArgs...),
+				ExpectedExitCode: 0,
+				ExpectedOutput:   "Deployed app is identical to proposed version",
+				RealmClient: u.MockRealmClient{
+					ExportFn: func(groupID, appID string, strategy api.ExportStrategy) (string, io.ReadCloser, error) {
+						return "full_app.zip", u.NewZipResponseBody("../testdata/full_app"), nil
+					},
+					FetchAppByClientAppIDFn: func(clientAppID string) (*models.App, error) {
+						return &models.App{
+							GroupID: "group-id",
+							ID:      "app-id",
+						}, nil
+					},
+				},
+			},
+			{
+				Description:      "it reports changes made only remotely since the baseline",
+				Args:             append([]string{"--path=../testdata/full_app", "--baseline=" + fullAppBaseline}, validArgs...),
+				ExpectedExitCode: 0,
+				ExpectedOutput:   "Remote Changes:\n\tRemoved Resources:\n\t\t- app config: environments\n",
+				RealmClient: u.MockRealmClient{
+					ExportFn: func(groupID, appID string, strategy api.ExportStrategy) (string, io.ReadCloser, error) {
+						return "simple_app.zip", u.NewZipResponseBody("../testdata/simple_app"), nil
+					},
+					FetchAppByClientAppIDFn: func(clientAppID string) (*models.App, error) {
+						return &models.App{
+							GroupID: "group-id",
+							ID:      "app-id",
+						}, nil
+					},
+				},
+			},
+			{
+				Description:      "it splits the JSON output by the side that made each change since the baseline",
+				Args:             append([]string{"--path=../testdata/full_app", "--baseline=" + fullAppBaseline, "-o", "json"}, validArgs...),
+				ExpectedExitCode: 0,
+				ExpectedOutput:   `"theirs": [`,
+				RealmClient: u.MockRealmClient{
+					ExportFn: func(groupID, appID string, strategy api.ExportStrategy) (string, io.ReadCloser, error) {
+						return "simple_app.zip", u.NewZipResponseBody("../testdata/simple_app"), nil
+					},
+					FetchAppByClientAppIDFn: func(clientAppID string) (*models.App, error) {
+						return &models.App{
+							GroupID: "group-id",
+							ID:      "app-id",
+						}, nil
+					},
+				},
+			},
+			{
+				Description:      "it fails if the baseline cannot be opened",
+				Args:             append([]string{"--path=../testdata/full_app", "--baseline=/somewhere/bogus.zip"}, validArgs...),
+				ExpectedExitCode: 1,
+				ExpectedError:    "failed to open baseline",
+				RealmClient: u.MockRealmClient{
+					FetchAppByClientAppIDFn: func(clientAppID string) (*models.App, error) {
+						return &models.App{
+							GroupID: "group-id",
+							ID:      "app-id",
+						}, nil
+					},
+				},
+			},
+			{
+				Description:      "it leaves ignored fields out of the diff",
+				Args:             append([]string{"--path=../testdata/full_app", "--diff-algorithm=client", "--ignore-field=name"}, validArgs...),
+				ExpectedExitCode: 0,
+				ExpectedOutput:   "Modified Resources:\n\t* app config: security\n",
+				RealmClient: u.MockRealmClient{
+					ExportFn: func(groupID, appID string, strategy api.ExportStrategy) (string, io.ReadCloser, error) {
+						return "simple_app.zip", u.NewZipResponseBody("../testdata/simple_app"), nil
+					},
+					FetchAppByClientAppIDFn: func(clientAppID string) (*models.App, error) {
+						return &models.App{
+							GroupID: "group-id",
+							ID:      "app-id",
+						}, nil
+					},
+				},
+			},
+			{
+				Description:      "it fails to ignore fields with the server diff algorithm",
+				Args:             append([]string{"--path=../testdata/full_app", "--ignore-field=name"}, validArgs...),
+				ExpectedExitCode: 1,
+				ExpectedError:    "--ignore-field requires --diff-algorithm=client",
+			},
+			{
+				Description:      "it fails if given a rename threshold out of range",
+				Args:             append([]string{"--path=../testdata/full_app", "--diff-algorithm=client", "--rename-threshold=1.5"}, validArgs...),
+				ExpectedExitCode: 1,
+				ExpectedError:    "--rename-threshold must be greater than 0 and at most 1",
+			},
+			{
+				Description:      "it exits with a distinct code with --exit-code when the app differs",
+				Args:             append([]string{"--path=../testdata/full_app", "--exit-code"}, validArgs...),
+				ExpectedExitCode: exitCodeChanges,
+				ExpectedOutput:   "sample-diff-contents",
+				RealmClient: u.MockRealmClient{
+					DiffFn: func(groupID, appID string, appData []byte, strategy string) ([]string, error) {
+						return []string{"sample-diff-contents"}, nil
+					},
+					FetchAppByClientAppIDFn: func(clientAppID string) (*models.App, error) {
+						return &models.App{
+							GroupID: "group-id",
+							ID:      "app-id",
+						}, nil
+					},
+				},
+			},
+			{
+				Description:      "it exits with code 0 with --exit-code when the app is identical",
+				Args:             append([]string{"--path=../testdata/full_app", "--exit-code", "-o", "json"}, validArgs...),
+				ExpectedExitCode: 0,
+				ExpectedOutput:   `"changed": false`,
+				RealmClient: u.MockRealmClient{
+					DiffFn: func(groupID, appID string, appData []byte, strategy string) ([]string, error) {
+						return nil, nil
+					},
+					FetchAppByClientAppIDFn: func(clientAppID string) (*models.App, error) {
+						return &models.App{
+							GroupID: "group-id",
+							ID:      "app-id",
+						}, nil
+					},
+				},
+			},
+			{
+				Description:      "it exits with code 0 with --exit-code and -y when the app is identical",
+				Args:             append([]string{"--path=../testdata/full_app", "--exit-code", "-y"}, validArgs...),
+				ExpectedExitCode: 0,
+				ExpectedOutput:   "Deployed app is identical to proposed version",
+				RealmClient: u.MockRealmClient{
+					DiffFn: func(groupID, appID string, appData []byte, strategy string) ([]string, error) {
+						return nil, nil
+					},
+					FetchAppByClientAppIDFn: func(clientAppID string) (*models.App, error) {
+						return &models.App{
+							GroupID: "group-id",
+							ID:      "app-id",
+						}, nil
+					},
+				},
+			},
+			{
+				Description:      "it exits with code 1 with --exit-code when the diff fails",
+				Args:             append([]string{"--path=../testdata/full_app", "--exit-code"}, validArgs...),
+				ExpectedExitCode: 1,
+				ExpectedError:    "failed to diff app",
+				RealmClient: u.MockRealmClient{
+					DiffFn: func(groupID, appID string, appData []byte, strategy string) ([]string, error) {
+						return nil, errors.New("server unavailable")
+					},
+					FetchAppByClientAppIDFn: func(clientAppID string) (*models.App, error) {
+						return &models.App{
+							GroupID: "group-id",
+							ID:      "app-id",
+						}, nil
+					},
+				},
+			},
+			{
+				Description:      "it fails if given an unknown output format",
+				Args:             append([]string{"--path=../testdata/full_app", "--output=yaml"}, validArgs...),
+				ExpectedExitCode: 1,
+				ExpectedError:    `unknown output format "yaml"`,
+			},
 		} {
 			t.Run(tc.Description, func(t *testing.T) {
 				diffCommand, mockUI := setup()
@@ -107,9 +415,82 @@ func TestDiffCommand(t *testing.T) {
 				exitCode := diffCommand.Run(tc.Args)
 				u.So(t, exitCode, gc.ShouldEqual, tc.ExpectedExitCode)
 				u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, tc.ExpectedError)
+				u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, tc.ExpectedOutput)
 			})
 		}
 
 	})
 
 }
+
+func TestDiffCommandHostingBreakdown(t *testing.T) {
+	// the hosting asset cache is kept next to the config
+	configDir, err := ioutil.TempDir("", "realm-diff-hosting-")
+	u.So(t, err, gc.ShouldBeNil)
+	defer os.RemoveAll(configDir)
+
+	args := []string{"--app-id=my-app-abcdef", "--path=../testdata/full_app", "--include-hosting", "--strategy=replace", "--config-path=" + filepath.Join(configDir, "realm")}
+
+	setup := func() (*DiffCommand, *cli.MockUi) {
+		diffCommand, mockUI := setUpBasicDiffCommand()
+		diffCommand.user = &user.User{
+			APIKey:      "my-api-key",
+			AccessToken: u.GenerateValidAccessToken(),
+		}
+
+		hash, err := utils.GenerateFileHashStr("../testdata/full_app/hosting/files/asset_file0.json")
+		u.So(t, err, gc.ShouldBeNil)
+
+		realmClient := diffCommand.realmClient.(*u.MockRealmClient)
+		realmClient.ListAssetsForAppIDFn = func(groupID, appID string) ([]hosting.AssetMetadata, error) {
+			return []hosting.AssetMetadata{
+				{FilePath: "/asset_file0.json", FileHash: hash, Attrs: []hosting.AssetAttribute{{Name: hosting.AttributeContentType, Value: "text/plain"}}},
+				{FilePath: "/asset_file1.html", FileHash: "stale"},
+				{FilePath: "/old.js"},
+			}, nil
+		}
+		// replacing the app checks its schemas against the deployed ones
+		realmClient.ExportFn = func(groupID, appID string, strategy api.ExportStrategy) (string, io.ReadCloser, error) {
+			return "", u.NewZipResponseBody("../testdata/full_app"), nil
+		}
+		return diffCommand, mockUI
+	}
+
+	t.Run("it sums up the hosting changes after listing them", func(t *testing.T) {
+		diffCommand, mockUI := setup()
+
+		exitCode := diffCommand.Run(args)
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+		u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, strings.Join([]string{
+			"New Files:",
+			"\t+ /ships/nostromo.json",
+			"Removed Files:",
+			"\t- /old.js",
+			"Modified Files:",
+		}, "\n"))
+		u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, "\t* /asset_file0.json (attributes only)\n")
+		u.So(t, mockUI.OutputWriter.String(), gc.ShouldEndWith, "Hosting: 1 to add, 1 to remove, 2 to modify (1 attributes only).\n")
+	})
+
+	t.Run("it breaks the hosting changes down by path in the JSON output", func(t *testing.T) {
+		diffCommand, mockUI := setup()
+
+		exitCode := diffCommand.Run(append([]string{"-o", "json"}, args...))
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+
+		var output struct {
+			HostingChanges hostingChanges `json:"hosting_changes"`
+		}
+		u.So(t, json.Unmarshal(mockUI.OutputWriter.Bytes(), &output), gc.ShouldBeNil)
+		u.So(t, output.HostingChanges, gc.ShouldResemble, hostingChanges{
+			Added:   []string{"/ships/nostromo.json"},
+			Removed: []string{"/old.js"},
+			Modified: []modifiedHostingAsset{
+				{Path: "/asset_file0.json", Attributes: true},
+				{Path: "/asset_file1.html", Body: true, Attributes: true},
+			},
+		})
+	})
+}
@@ -0,0 +1,238 @@
+package commands
+
+import (
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/10gen/realm-cli/api"
+	"github.com/10gen/realm-cli/models"
+	u "github.com/10gen/realm-cli/user"
+
+	"github.com/mitchellh/cli"
+)
+
+const (
+	draftsFlagOlderThan = "older-than"
+	draftsFlagProject   = "project"
+
+	defaultDraftMaxAge = 24 * time.Hour
+)
+
+var (
+	errDraftsProjectIDRequired = fmt.Errorf("a Project ID (--%s=[string]) must be supplied to prune drafts", flagProjectIDName)
+)
+
+// NewAppPruneDraftsCommandFactory returns a new cli.CommandFactory given a cli.Ui
+func NewAppPruneDraftsCommandFactory(ui cli.Ui) cli.CommandFactory {
+	return func() (cli.Command, error) {
+		return &AppPruneDraftsCommand{
+			BaseCommand: &BaseCommand{
+				Name: "prune-drafts",
+				UI:   ui,
+			},
+			now: time.Now,
+		}, nil
+	}
+}
+
+// AppPruneDraftsCommand is used to discard the drafts left behind by interrupted imports
+type AppPruneDraftsCommand struct {
+	*BaseCommand
+
+	now func() time.Time
+
+	flagProjectID   string
+	flagOlderThan   time.Duration
+	flagOutput      string
+	flagConcurrency int
+}
+
+// draftsPruneResult is what pruning the drafts of a single app did
+type draftsPruneResult struct {
+	Found     int `json:"drafts_found"`
+	Discarded int `json:"drafts_discarded"`
+}
+
+// Synopsis returns a one-liner description for this command
+func (apdc *AppPruneDraftsCommand) Synopsis() string {
+	return "Discard stale drafts of the Realm Apps in a project."
+}
+
+// Help returns long-form help information for this command
+func (apdc *AppPruneDraftsCommand) Help() string {
+	return `Discard the drafts left behind by interrupted imports across the Realm Apps in a project.
+A draft is stale if it has no changes, or if it was created longer ago than --older-than.
+You are asked to confirm discarding each stale draft unless -y is set. A failure to prune the drafts
+of one app does not stop the others from being pruned.
+
+Usage: realm-cli app prune-drafts --project-id [string] [options]
+
+REQUIRED:
+  --project-id, --project [string]
+	The Atlas Project ID.
+
+OPTIONS:
+  --older-than [duration] (default: 24h)
+	How long ago a draft with changes must have been created to be discarded, e.g. "90m" or "48h".
+
+  --concurrency [int] (default: 1)
+	How many apps to prune the drafts of at once. Requires -y when greater than 1.
+
+  -o [text|json], --output [text|json] (default: text)
+	How the outcome should be printed.
+	json - print a single JSON report once every app is done, listing for each app in turn its status,
+	any error, and how many drafts were found and discarded. Requires -y.
+	` +
+		apdc.BaseCommand.Help()
+}
+
+// Run executes the command
+func (apdc *AppPruneDraftsCommand) Run(args []string) int {
+	flags := apdc.NewFlagSet()
+
+	flags.StringVar(&apdc.flagProjectID, flagProjectIDName, "", "")
+	flags.StringVar(&apdc.flagProjectID, draftsFlagProject, "", "")
+	flags.DurationVar(&apdc.flagOlderThan, draftsFlagOlderThan, defaultDraftMaxAge, "")
+	flags.IntVar(&apdc.flagConcurrency, bulkFlagConcurrency, 1, "")
+	flags.StringVar(&apdc.flagOutput, bulkFlagOutput, bulkOutputText, "")
+	flags.StringVar(&apdc.flagOutput, "o", bulkOutputText, "")
+
+	if err := apdc.BaseCommand.run(args); err != nil {
+		apdc.UI.Error(err.Error())
+		return 1
+	}
+
+	if err := apdc.prune(); err != nil {
+		apdc.UI.Error(err.Error())
+		return 1
+	}
+
+	return 0
+}
+
+func (apdc *AppPruneDraftsCommand) prune() error {
+	if apdc.flagProjectID == "" {
+		return errDraftsProjectIDRequired
+	}
+
+	if err := validateBulkFlags(apdc.flagOutput, apdc.flagConcurrency, apdc.flagYes); err != nil {
+		return err
+	}
+
+	user, err := apdc.User()
+	if err != nil {
+		return err
+	}
+
+	if !user.LoggedIn() {
+		return u.ErrNotLoggedIn
+	}
+
+	realmClient, err := apdc.RealmClient()
+	if err != nil {
+		return err
+	}
+
+	apps, err := realmClient.FetchAppsByGroupID(apdc.flagProjectID)
+	if err != nil {
+		return err
+	}
+
+	// the JSON report is the only output, and concurrent apps must not garble each other's output
+	ui := apdc.UI
+	if apdc.flagOutput == bulkOutputJSON {
+		ui = &cli.BasicUi{Writer: ioutil.Discard, ErrorWriter: ioutil.Discard}
+	} else if apdc.flagConcurrency > 1 {
+		ui = &cli.ConcurrentUi{Ui: ui}
+	}
+
+	report := processApps(apps, apdc.flagConcurrency, func(app *models.App) (interface{}, error) {
+		return apdc.pruneApp(ui, realmClient, app)
+	})
+
+	if apdc.flagOutput == bulkOutputJSON {
+		return report.print(apdc.UI)
+	}
+
+	var found, discarded int
+	for _, appResult := range report.Apps {
+		result := appResult.Result.(*draftsPruneResult)
+		found += result.Found
+		discarded += result.Discarded
+
+		if appResult.Error != "" {
+			apdc.UI.Error(appResult.Error)
+		}
+	}
+
+	apdc.UI.Info(fmt.Sprintf("Discarded %d of %d drafts found across %d apps", discarded, found, len(apps)))
+	return report.err()
+}
+
+// pruneApp discards the stale drafts of a single app
+func (apdc *AppPruneDraftsCommand) pruneApp(ui cli.Ui, realmClient api.RealmClient, app *models.App) (*draftsPruneResult, error) {
+	result := &draftsPruneResult{}
+
+	drafts, err := realmClient.GetDrafts(app.GroupID, app.ID)
+	if err != nil {
+		return result, fmt.Errorf("failed to fetch drafts of %s: %s", app.ClientAppID, err)
+	}
+
+	for _, draft := range drafts {
+		result.Found++
+
+		discard, err := apdc.confirmDiscard(ui, realmClient, app, draft)
+		if err != nil {
+			return result, err
+		}
+		if !discard {
+			continue
+		}
+
+		if err := realmClient.DiscardDraft(app.GroupID, app.ID, draft.ID); err != nil {
+			return result, fmt.Errorf("failed to discard draft %s of %s: %s", draft.ID, app.ClientAppID, err)
+		}
+		result.Discarded++
+	}
+
+	return result, nil
+}
+
+// confirmDiscard reports whether the draft is stale and the user wants it discarded
+func (apdc *AppPruneDraftsCommand) confirmDiscard(ui cli.Ui, realmClient api.RealmClient, app *models.App, draft models.AppDraft) (bool, error) {
+	diff, err := realmClient.DraftDiff(app.GroupID, app.ID, draft.ID)
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch diff of draft %s of %s: %s", draft.ID, app.ClientAppID, err)
+	}
+
+	var age time.Duration
+	createdAt, hasCreatedAt := draft.CreatedAt()
+	if hasCreatedAt {
+		age = apdc.now().Sub(createdAt).Round(time.Second)
+	}
+
+	switch {
+	case !diff.HasChanges():
+		return apdc.confirm(ui, fmt.Sprintf("%s has an empty draft, would you like to discard it?", app.ClientAppID))
+
+	case hasCreatedAt && age >= apdc.flagOlderThan:
+		ui.Info(fmt.Sprintf("%s has a draft created %s ago with the following changes...\n", app.ClientAppID, age))
+		for _, d := range diff.Diffs {
+			ui.Info(d)
+		}
+		return apdc.confirm(ui, "Would you like to discard these changes?")
+	}
+
+	ui.Info(fmt.Sprintf("Keeping the draft of %s, which has changes and is not older than %s", app.ClientAppID, apdc.flagOlderThan))
+	return false, nil
+}
+
+// confirm asks the question unless -y is set, in which case the answer is printed to ui
+func (apdc *AppPruneDraftsCommand) confirm(ui cli.Ui, query string) (bool, error) {
+	if apdc.flagYes {
+		ui.Info(fmt.Sprintf("%s [y/n]: y", query))
+		return true, nil
+	}
+	return apdc.AskYesNo(query)
+}
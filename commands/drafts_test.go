@@ -0,0 +1,221 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/10gen/realm-cli/models"
+	"github.com/10gen/realm-cli/user"
+	u "github.com/10gen/realm-cli/utils/test"
+	gc "github.com/smartystreets/goconvey/convey"
+
+	"github.com/mitchellh/cli"
+)
+
+func TestAppPruneDraftsCommand(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	draftCreated := func(ago time.Duration) models.AppDraft {
+		return models.AppDraft{ID: fmt.Sprintf("%08x%016x", now.Add(-ago).Unix(), 0)}
+	}
+
+	validArgs := []string{"--project-id=group-id"}
+
+	setup := func() (*AppPruneDraftsCommand, *cli.MockUi) {
+		mockUI := cli.NewMockUi()
+		cmd, err := NewAppPruneDraftsCommandFactory(mockUI)()
+		if err != nil {
+			panic(err)
+		}
+
+		pruneCommand := cmd.(*AppPruneDraftsCommand)
+		pruneCommand.storage = u.NewEmptyStorage()
+		pruneCommand.now = func() time.Time { return now }
+		return pruneCommand, mockUI
+	}
+
+	t.Run("should require the user to be logged in", func(t *testing.T) {
+		pruneCommand, mockUI := setup()
+		exitCode := pruneCommand.Run(validArgs)
+		u.So(t, exitCode, gc.ShouldEqual, 1)
+
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, user.ErrNotLoggedIn.Error())
+	})
+
+	t.Run("when the user is logged in", func(t *testing.T) {
+		drafts := map[string]models.AppDraft{
+			"empty-app-id":  draftCreated(time.Minute),
+			"stale-app-id":  draftCreated(48 * time.Hour),
+			"recent-app-id": draftCreated(time.Hour),
+		}
+
+		newRealmClient := func(discarded *[]string) *u.MockRealmClient {
+			return &u.MockRealmClient{
+				FetchAppsByGroupIDFn: func(groupID string) ([]*models.App, error) {
+					return []*models.App{
+						{GroupID: groupID, ID: "empty-app-id", ClientAppID: "empty-app"},
+						{GroupID: groupID, ID: "stale-app-id", ClientAppID: "stale-app"},
+						{GroupID: groupID, ID: "recent-app-id", ClientAppID: "recent-app"},
+						{GroupID: groupID, ID: "no-draft-app-id", ClientAppID: "no-draft-app"},
+					}, nil
+				},
+				GetDraftsFn: func(groupID, appID string) ([]models.AppDraft, error) {
+					if draft, ok := drafts[appID]; ok {
+						return []models.AppDraft{draft}, nil
+					}
+					return []models.AppDraft{}, nil
+				},
+				DraftDiffFn: func(groupID, appID, draftID string) (*models.DraftDiff, error) {
+					if appID == "empty-app-id" {
+						return &models.DraftDiff{}, nil
+					}
+					return &models.DraftDiff{Diffs: []string{"+ function: " + appID}}, nil
+				},
+				DiscardDraftFn: func(groupID, appID, draftID string) error {
+					u.So(t, draftID, gc.ShouldEqual, drafts[appID].ID)
+					*discarded = append(*discarded, appID)
+					return nil
+				},
+			}
+		}
+
+		for _, tc := range []struct {
+			Description       string
+			Args              []string
+			Input             string
+			ExpectedDiscarded []string
+			ExpectedOutput    string
+		}{
+			{
+				Description:       "it discards empty drafts and drafts older than a day with -y",
+				Args:              append([]string{"-y"}, validArgs...),
+				ExpectedDiscarded: []string{"empty-app-id", "stale-app-id"},
+				ExpectedOutput:    "Discarded 2 of 3 drafts found across 4 apps",
+			},
+			{
+				Description:       "it discards recent drafts with --older-than",
+				Args:              append([]string{"-y", "--older-than=30m"}, validArgs...),
+				ExpectedDiscarded: []string{"empty-app-id", "stale-app-id", "recent-app-id"},
+				ExpectedOutput:    "Discarded 3 of 3 drafts found across 4 apps",
+			},
+			{
+				Description:       "it takes the project ID as --project",
+				Args:              []string{"-y", "--project=group-id"},
+				ExpectedDiscarded: []string{"empty-app-id", "stale-app-id"},
+				ExpectedOutput:    "Discarded 2 of 3 drafts found across 4 apps",
+			},
+			{
+				Description:       "it asks before discarding each draft",
+				Args:              validArgs,
+				Input:             "n\ny\n",
+				ExpectedDiscarded: []string{"stale-app-id"},
+				ExpectedOutput:    "stale-app has a draft created 48h0m0s ago with the following changes",
+			},
+		} {
+			t.Run(tc.Description, func(t *testing.T) {
+				pruneCommand, mockUI := setup()
+				pruneCommand.user = &user.User{APIKey: "my-api-key", AccessToken: u.GenerateValidAccessToken()}
+				mockUI.InputReader = strings.NewReader(tc.Input)
+
+				var discarded []string
+				pruneCommand.realmClient = newRealmClient(&discarded)
+
+				exitCode := pruneCommand.Run(tc.Args)
+				u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+				u.So(t, exitCode, gc.ShouldEqual, 0)
+				u.So(t, discarded, gc.ShouldResemble, tc.ExpectedDiscarded)
+				u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, tc.ExpectedOutput)
+			})
+		}
+
+		t.Run("it reports the outcome for each app as JSON", func(t *testing.T) {
+			pruneCommand, mockUI := setup()
+			pruneCommand.user = &user.User{APIKey: "my-api-key", AccessToken: u.GenerateValidAccessToken()}
+
+			var discarded []string
+			var mu sync.Mutex
+			realmClient := newRealmClient(&discarded)
+			discardDraft := realmClient.DiscardDraftFn
+			realmClient.DiscardDraftFn = func(groupID, appID, draftID string) error {
+				mu.Lock()
+				defer mu.Unlock()
+				if appID == "stale-app-id" {
+					return errors.New("oh noes")
+				}
+				return discardDraft(groupID, appID, draftID)
+			}
+			pruneCommand.realmClient = realmClient
+
+			exitCode := pruneCommand.Run(append([]string{"-y", "-o", "json", "--concurrency=3"}, validArgs...))
+			u.So(t, exitCode, gc.ShouldEqual, 1)
+			u.So(t, mockUI.ErrorWriter.String(), gc.ShouldEqual, "failed for 1 of 4 apps\n")
+
+			var report struct {
+				Apps []struct {
+					App    string `json:"app"`
+					Status string `json:"status"`
+					Error  string `json:"error"`
+					Result struct {
+						Found     int `json:"drafts_found"`
+						Discarded int `json:"drafts_discarded"`
+					} `json:"result"`
+				} `json:"apps"`
+				Succeeded int `json:"succeeded"`
+				Failed    int `json:"failed"`
+			}
+			u.So(t, json.Unmarshal(mockUI.OutputWriter.Bytes(), &report), gc.ShouldBeNil)
+
+			u.So(t, report.Succeeded, gc.ShouldEqual, 3)
+			u.So(t, report.Failed, gc.ShouldEqual, 1)
+			u.So(t, report.Apps, gc.ShouldHaveLength, 4)
+			for i, app := range []string{"empty-app", "stale-app", "recent-app", "no-draft-app"} {
+				u.So(t, report.Apps[i].App, gc.ShouldEqual, app)
+			}
+			u.So(t, report.Apps[0].Status, gc.ShouldEqual, bulkStatusSucceeded)
+			u.So(t, report.Apps[0].Result.Discarded, gc.ShouldEqual, 1)
+			u.So(t, report.Apps[1].Status, gc.ShouldEqual, bulkStatusFailed)
+			u.So(t, report.Apps[1].Error, gc.ShouldContainSubstring, "oh noes")
+			u.So(t, report.Apps[1].Result.Found, gc.ShouldEqual, 1)
+			u.So(t, report.Apps[2].Result.Discarded, gc.ShouldEqual, 0)
+		})
+
+		t.Run("it requires -y to report as JSON", func(t *testing.T) {
+			pruneCommand, mockUI := setup()
+			pruneCommand.user = &user.User{APIKey: "my-api-key", AccessToken: u.GenerateValidAccessToken()}
+
+			exitCode := pruneCommand.Run(append([]string{"-o", "json"}, validArgs...))
+			u.So(t, exitCode, gc.ShouldEqual, 1)
+			u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, "require --yes")
+		})
+
+		t.Run("it fails without a project id", func(t *testing.T) {
+			pruneCommand, mockUI := setup()
+			pruneCommand.user = &user.User{APIKey: "my-api-key", AccessToken: u.GenerateValidAccessToken()}
+
+			exitCode := pruneCommand.Run([]string{"-y"})
+			u.So(t, exitCode, gc.ShouldEqual, 1)
+			u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, errDraftsProjectIDRequired.Error())
+		})
+
+		t.Run("it fails if a draft cannot be discarded", func(t *testing.T) {
+			pruneCommand, mockUI := setup()
+			pruneCommand.user = &user.User{APIKey: "my-api-key", AccessToken: u.GenerateValidAccessToken()}
+
+			var discarded []string
+			realmClient := newRealmClient(&discarded)
+			realmClient.DiscardDraftFn = func(groupID, appID, draftID string) error {
+				return errors.New("oh noes")
+			}
+			pruneCommand.realmClient = realmClient
+
+			exitCode := pruneCommand.Run(append([]string{"-y"}, validArgs...))
+			u.So(t, exitCode, gc.ShouldEqual, 1)
+			u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, "failed to discard draft")
+			u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, "oh noes")
+		})
+	})
+}
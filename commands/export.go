@@ -1,8 +1,14 @@
 package commands
 
 import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strings"
@@ -18,6 +24,20 @@ import (
 
 const numWorkers = 4
 
+// Set of formats an exported app can be written in
+const (
+	exportFormatDir = "dir"
+	exportFormatZip = "zip"
+)
+
+func errUnknownExportFormat(format string) error {
+	return fmt.Errorf("unknown export format %q; accepted values are [%s|%s]", format, exportFormatDir, exportFormatZip)
+}
+
+func errExportZipWithAssets(flagName string) error {
+	return fmt.Errorf("--%s cannot be used with --format=%s", flagName, exportFormatZip)
+}
+
 // NewExportCommandFactory returns a new cli.CommandFactory given a cli.Ui
 func NewExportCommandFactory(ui cli.Ui) cli.CommandFactory {
 	return func() (cli.Command, error) {
@@ -26,15 +46,17 @@ func NewExportCommandFactory(ui cli.Ui) cli.CommandFactory {
 			return nil, err
 		}
 
+		base := &BaseCommand{
+			Name: "export",
+			UI:   ui,
+		}
+
 		return &ExportCommand{
 			workingDirectory:     workingDirectory,
 			exportToDirectory:    utils.WriteZipToDir,
 			writeFileToDirectory: utils.WriteFileToDir,
-			getAssetAtURL:        getAssetAtURL,
-			BaseCommand: &BaseCommand{
-				Name: "export",
-				UI:   ui,
-			},
+			getAssetAtURL:        base.getAssetAtURL,
+			BaseCommand:          base,
 		}, nil
 	}
 }
@@ -51,10 +73,14 @@ type ExportCommand struct {
 	flagProjectID           string
 	flagAppID               string
 	flagOutput              string
+	flagFormat              string
 	flagAsTemplate          bool
 	flagIncludeHosting      bool
 	flagIncludeDependencies bool
 	flagForSourceControl    bool
+	flagRedactConfig        bool
+	flagIncremental         bool
+	flagAgainst             string
 }
 
 // Help returns long-form help information for this command
@@ -72,6 +98,11 @@ OPTIONS:
   -o [string], --output [string]
 	Directory to write the exported configuration. Defaults to "<app_name>_<timestamp>"
 
+  --format [dir|zip] (default: dir)
+	How the exported configuration should be written.
+	dir - unpack the configuration into the output directory.
+	zip - write the configuration archive as-is to "<output>.zip".
+
   --as-template
 	Indicate that the application should be exported as a template.
 
@@ -79,10 +110,27 @@ OPTIONS:
 	Indicate that the application should be exported for source control.
 
   --include-dependencies
-	Download dependencies associated with this project
+	Download the dependencies archive of the app into its "/functions" directory, from where
+	"realm-cli import --include-dependencies" uploads it again. Skipped if the app has no dependencies.
 
   --include-hosting
-	Download static assets associated with this project` +
+	Download the static hosting assets of the app into its "/hosting/files" directory, streaming each one to disk,
+	and their attributes into "/hosting/metadata.json", so that "realm-cli import --include-hosting" finds no changes.
+
+  --redact-config
+	Mask secret values, values and environments, allowed request origins and service connection details
+	in the exported configuration, so that it can be shared safely (e.g. in a support ticket). The values
+	which refer to a secret keep its name. Every redacted field is listed in "redacted.json" at the root
+	of the export
+
+  --incremental
+	Only export the files which changed since the export given by --against. Requires --format=zip.
+	The archive also contains an "incremental.json" manifest which references the baseline archive
+	and lists the files changed and deleted since. Without --against, the full app is exported.
+
+  --against [string]
+	A path to a previous export archive (e.g. from "export --format=zip") to compare against
+	with --incremental.` +
 		ec.BaseCommand.Help()
 }
 
@@ -99,10 +147,14 @@ func (ec *ExportCommand) Run(args []string) int {
 	set.StringVar(&ec.flagAppID, flagAppIDName, "", "")
 	set.StringVar(&ec.flagOutput, "output", "", "")
 	set.StringVar(&ec.flagOutput, "o", "", "")
+	set.StringVar(&ec.flagFormat, "format", exportFormatDir, "")
 	set.BoolVar(&ec.flagAsTemplate, "as-template", false, "")
 	set.BoolVar(&ec.flagForSourceControl, "for-source-control", false, "")
 	set.BoolVar(&ec.flagIncludeDependencies, "include-dependencies", false, "")
 	set.BoolVar(&ec.flagIncludeHosting, "include-hosting", false, "")
+	set.BoolVar(&ec.flagRedactConfig, "redact-config", false, "")
+	set.BoolVar(&ec.flagIncremental, "incremental", false, "")
+	set.StringVar(&ec.flagAgainst, "against", "", "")
 
 	if err := ec.BaseCommand.run(args); err != nil {
 		ec.UI.Error(err.Error())
@@ -122,6 +174,29 @@ func (ec *ExportCommand) run() error {
 		return errAppIDRequired
 	}
 
+	switch ec.flagFormat {
+	case exportFormatDir:
+	case exportFormatZip:
+		if ec.flagIncludeDependencies {
+			return errExportZipWithAssets("include-dependencies")
+		}
+		if ec.flagIncludeHosting {
+			return errExportZipWithAssets("include-hosting")
+		}
+		if ec.flagRedactConfig {
+			return errExportZipWithAssets("redact-config")
+		}
+	default:
+		return errUnknownExportFormat(ec.flagFormat)
+	}
+
+	if ec.flagIncremental && ec.flagFormat != exportFormatZip {
+		return fmt.Errorf("--incremental requires --format=%s", exportFormatZip)
+	}
+	if ec.flagAgainst != "" && !ec.flagIncremental {
+		return errors.New("--against requires --incremental")
+	}
+
 	user, err := ec.User()
 	if err != nil {
 		return err
@@ -140,17 +215,9 @@ func (ec *ExportCommand) run() error {
 		return err
 	}
 
-	var app *models.App
-	if ec.flagProjectID == "" {
-		app, err = realmClient.FetchAppByClientAppID(ec.flagAppID)
-		if err != nil {
-			return err
-		}
-	} else {
-		app, err = realmClient.FetchAppByGroupIDAndClientAppID(ec.flagProjectID, ec.flagAppID)
-		if err != nil {
-			return err
-		}
+	app, err := fetchApp(realmClient, ec.flagProjectID, ec.flagAppID)
+	if err != nil {
+		return err
 	}
 
 	exportStrategy := api.ExportStrategyNone
@@ -175,22 +242,29 @@ func (ec *ExportCommand) run() error {
 		filename = filename[:lastUnderscoreIdx]
 	}
 
+	if ec.flagFormat == exportFormatZip {
+		if ec.flagAgainst != "" {
+			return ec.exportIncrementalZipFile(filename, body)
+		}
+		return ec.exportToZipFile(filename, body)
+	}
+
 	if err := ec.exportToDirectory(filename, body, false); err != nil {
 		return err
 	}
 
-	if ec.flagIncludeDependencies {
-		depArchive, depBody, err := realmClient.ExportDependencies(app.GroupID, app.ID)
+	if ec.flagRedactConfig {
+		redacted, err := utils.RedactAppConfig(filename)
 		if err != nil {
-			return err
+			return fmt.Errorf("failed to redact the exported configuration: %s", err)
 		}
-		defer depBody.Close()
-		functionsDir := filepath.Join(filename, utils.FunctionsRoot, depArchive)
-		err = ec.writeFileToDirectory(functionsDir, depBody)
-		if err != nil {
+		ec.UI.Info(fmt.Sprintf("Redacted %d values, see %s", len(redacted), filepath.Join(filename, utils.RedactionManifestName)))
+	}
+
+	if ec.flagIncludeDependencies {
+		if err := ec.exportDependencies(realmClient, app, filename); err != nil {
 			return err
 		}
-
 	}
 
 	if ec.flagIncludeHosting {
@@ -200,3 +274,83 @@ func (ec *ExportCommand) run() error {
 	}
 	return nil
 }
+
+// exportDependencies writes the dependencies archive of the app into the functions directory of the exported app,
+// where "realm-cli import --include-dependencies" picks it up. Apps without dependencies are skipped
+func (ec *ExportCommand) exportDependencies(realmClient api.RealmClient, app *models.App, appPath string) error {
+	depArchive, depBody, err := realmClient.ExportDependencies(app.GroupID, app.ID)
+	if err == api.ErrNoDependencies {
+		ec.UI.Info("The app has no dependencies to export")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to export dependencies: %s", err)
+	}
+	defer depBody.Close()
+
+	return ec.writeFileToDirectory(filepath.Join(appPath, utils.FunctionsRoot, depArchive), depBody)
+}
+
+func (ec *ExportCommand) exportToZipFile(filename string, body io.Reader) error {
+	if !strings.HasSuffix(filename, ".zip") {
+		filename += ".zip"
+	}
+
+	if _, err := os.Stat(filename); err == nil {
+		return fmt.Errorf("failed to create file %q: file already exists", filename)
+	}
+
+	data, err := ioutil.ReadAll(body)
+	if err != nil {
+		return err
+	}
+
+	if _, err := utils.VerifyZip(data); err != nil {
+		return err
+	}
+
+	return ec.writeFileToDirectory(filename, bytes.NewReader(data))
+}
+
+// exportIncrementalZipFile writes only the files of the export which changed since the --against archive
+func (ec *ExportCommand) exportIncrementalZipFile(filename string, body io.Reader) error {
+	againstPath, err := homedir.Expand(ec.flagAgainst)
+	if err != nil {
+		return err
+	}
+
+	baselineData, err := ioutil.ReadFile(againstPath)
+	if err != nil {
+		return fmt.Errorf("failed to open baseline: %s", err)
+	}
+
+	baseline, err := zip.NewReader(bytes.NewReader(baselineData), int64(len(baselineData)))
+	if err != nil {
+		return fmt.Errorf("failed to read baseline %q: %s", ec.flagAgainst, err)
+	}
+
+	exportData, err := ioutil.ReadAll(body)
+	if err != nil {
+		return err
+	}
+
+	export, err := zip.NewReader(bytes.NewReader(exportData), int64(len(exportData)))
+	if err != nil {
+		return err
+	}
+
+	baselineHash := sha256.Sum256(baselineData)
+
+	var buf bytes.Buffer
+	manifest, err := utils.WriteIncrementalZip(&buf, export, baseline, filepath.Base(againstPath), hex.EncodeToString(baselineHash[:]))
+	if err != nil {
+		return err
+	}
+
+	if err := ec.exportToZipFile(filename, &buf); err != nil {
+		return err
+	}
+
+	ec.UI.Info(fmt.Sprintf("Exported %d changed and %d deleted files since %s", len(manifest.Changed), len(manifest.Deleted), ec.flagAgainst))
+	return nil
+}
@@ -4,8 +4,6 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
 	"os"
 	"path"
 	"sync"
@@ -16,18 +14,6 @@ import (
 	"github.com/10gen/realm-cli/utils"
 )
 
-func getAssetAtURL(url string) (io.ReadCloser, error) {
-	resp, err := http.Get(url)
-	if err != nil {
-		return nil, err
-	}
-	if resp.StatusCode != http.StatusOK {
-		resp.Body.Close()
-		return nil, fmt.Errorf("downloading asset (url: %s) failed: response status code was %d", url, resp.StatusCode)
-	}
-	return resp.Body, nil
-}
-
 func exportStaticHostingAssets(realmClient api.RealmClient, ec *ExportCommand, appPath string, app *models.App) error {
 	assetMetadatas, err := realmClient.ListAssetsForAppID(app.GroupID, app.ID)
 	if err != nil {
@@ -1,11 +1,15 @@
 package commands
 
 import (
+	"archive/zip"
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -89,20 +93,14 @@ func TestExportCommand(t *testing.T) {
 			appID := "my-cool-app-123456"
 
 			assetDescriptions := []hosting.AssetDescription{
-				{
-					FilePath: "/bar/shouldRemainSame.txt",
-					Attrs: []hosting.AssetAttribute{
-						{Name: "Content-Type", Value: "html"},
-					},
-				},
 				{
 					FilePath: "/bar/attrsShouldAllRemain.html",
 					Attrs: []hosting.AssetAttribute{
+						{Name: "Cache-Control", Value: "true"},
 						{Name: "Content-Disposition", Value: "inline"},
-						{Name: "Content-Type", Value: "htmp"},
-						{Name: "Content-Language", Value: "fr"},
 						{Name: "Content-Encoding", Value: "utf-8"},
-						{Name: "Cache-Control", Value: "true"},
+						{Name: "Content-Language", Value: "fr"},
+						{Name: "Content-Type", Value: "htmp"},
 					},
 				},
 				{
@@ -114,8 +112,19 @@ func TestExportCommand(t *testing.T) {
 				{
 					FilePath: "/bar/shouldBeRemoved",
 					Attrs: []hosting.AssetAttribute{
-						{Name: "Content-Type", Value: "htmp"},
 						{Name: "Content-Language", Value: "fr"},
+						{Name: "Content-Type", Value: "htmp"},
+					},
+				},
+				{
+					// kept without attributes, rather than given the default Content-Type of .html when imported
+					FilePath: "/bar/shouldBeRemoved.html",
+					Attrs:    []hosting.AssetAttribute{},
+				},
+				{
+					FilePath: "/bar/shouldRemainSame.txt",
+					Attrs: []hosting.AssetAttribute{
+						{Name: "Content-Type", Value: "html"},
 					},
 				},
 			}
@@ -283,6 +292,308 @@ func TestExportCommand(t *testing.T) {
 			}
 		})
 
+		t.Run("--format", func(t *testing.T) {
+			mockRealmClient := u.MockRealmClient{
+				FetchAppByClientAppIDFn: func(clientAppID string) (*models.App, error) {
+					return &models.App{
+						ClientAppID: clientAppID,
+						GroupID:     "group-id",
+						ID:          "app-id",
+					}, nil
+				},
+				ExportFn: func(groupID, appID string, strategy api.ExportStrategy) (string, io.ReadCloser, error) {
+					return "my_app_123456.zip", u.NewResponseBody(strings.NewReader("myZipData")), nil
+				},
+			}
+
+			t.Run("writes the archive to a zip file instead of unpacking it", func(t *testing.T) {
+				archive, err := ioutil.ReadAll(u.NewZipResponseBody("../testdata/simple_app"))
+				u.So(t, err, gc.ShouldBeNil)
+
+				exportCommand, mockUI := setup()
+				exportCommand.realmClient = &u.MockRealmClient{
+					FetchAppByClientAppIDFn: mockRealmClient.FetchAppByClientAppIDFn,
+					ExportFn: func(groupID, appID string, strategy api.ExportStrategy) (string, io.ReadCloser, error) {
+						return "my_app_123456.zip", u.NewResponseBody(bytes.NewReader(archive)), nil
+					},
+				}
+				exportCommand.user = &user.User{APIKey: "my-api-key", AccessToken: u.GenerateValidAccessToken()}
+
+				var unpacked bool
+				exportCommand.exportToDirectory = func(dest string, r io.Reader, overwrite bool) error {
+					unpacked = true
+					return nil
+				}
+
+				var destination, zipData string
+				exportCommand.writeFileToDirectory = func(dest string, data io.Reader) error {
+					b, err := ioutil.ReadAll(data)
+					if err != nil {
+						return err
+					}
+					destination, zipData = dest, string(b)
+					return nil
+				}
+
+				exitCode := exportCommand.Run([]string{"--app-id=my-cool-app", "--format=zip", "-o", "some/directory/my_app"})
+				u.So(t, exitCode, gc.ShouldEqual, 0)
+				u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+				u.So(t, unpacked, gc.ShouldBeFalse)
+				u.So(t, destination, gc.ShouldEqual, "some/directory/my_app.zip")
+				u.So(t, zipData, gc.ShouldEqual, string(archive))
+			})
+
+			t.Run("rejects an incomplete archive without writing it", func(t *testing.T) {
+				exportCommand, mockUI := setup()
+				exportCommand.realmClient = &mockRealmClient
+				exportCommand.user = &user.User{APIKey: "my-api-key", AccessToken: u.GenerateValidAccessToken()}
+
+				var written bool
+				exportCommand.writeFileToDirectory = func(dest string, data io.Reader) error {
+					written = true
+					return nil
+				}
+
+				exitCode := exportCommand.Run([]string{"--app-id=my-cool-app", "--format=zip", "-o", "some/directory/my_app"})
+				u.So(t, exitCode, gc.ShouldEqual, 1)
+				u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, "failed to read the zip archive, it may be incomplete")
+				u.So(t, written, gc.ShouldBeFalse)
+			})
+
+			for _, tc := range []struct {
+				Description   string
+				Args          []string
+				ExpectedError string
+			}{
+				{
+					Description:   "rejects an unknown format",
+					Args:          []string{"--app-id=my-cool-app", "--format=tar"},
+					ExpectedError: `unknown export format "tar"`,
+				},
+				{
+					Description:   "rejects including hosting assets in a zip export",
+					Args:          []string{"--app-id=my-cool-app", "--format=zip", "--include-hosting"},
+					ExpectedError: "--include-hosting cannot be used with --format=zip",
+				},
+				{
+					Description:   "rejects including dependencies in a zip export",
+					Args:          []string{"--app-id=my-cool-app", "--format=zip", "--include-dependencies"},
+					ExpectedError: "--include-dependencies cannot be used with --format=zip",
+				},
+				{
+					Description:   "rejects redacting a zip export",
+					Args:          []string{"--app-id=my-cool-app", "--format=zip", "--redact-config"},
+					ExpectedError: "--redact-config cannot be used with --format=zip",
+				},
+				{
+					Description:   "rejects an incremental export to a directory",
+					Args:          []string{"--app-id=my-cool-app", "--incremental"},
+					ExpectedError: "--incremental requires --format=zip",
+				},
+				{
+					Description:   "rejects --against without --incremental",
+					Args:          []string{"--app-id=my-cool-app", "--format=zip", "--against=prev.zip"},
+					ExpectedError: "--against requires --incremental",
+				},
+			} {
+				t.Run(tc.Description, func(t *testing.T) {
+					exportCommand, mockUI := setup()
+					exportCommand.realmClient = &mockRealmClient
+					exportCommand.user = &user.User{APIKey: "my-api-key", AccessToken: u.GenerateValidAccessToken()}
+
+					exitCode := exportCommand.Run(tc.Args)
+					u.So(t, exitCode, gc.ShouldEqual, 1)
+					u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, tc.ExpectedError)
+				})
+			}
+		})
+
+		t.Run("--redact-config masks sensitive values in the exported configuration", func(t *testing.T) {
+			outputDir, err := ioutil.TempDir("", "realm-export-")
+			u.So(t, err, gc.ShouldBeNil)
+			defer os.RemoveAll(outputDir)
+			output := filepath.Join(outputDir, "my_app")
+
+			exportCommand, mockUI := setup()
+			exportCommand.user = &user.User{APIKey: "my-api-key", AccessToken: u.GenerateValidAccessToken()}
+			exportCommand.exportToDirectory = utils.WriteZipToDir
+			exportCommand.realmClient = &u.MockRealmClient{
+				FetchAppByClientAppIDFn: func(clientAppID string) (*models.App, error) {
+					return &models.App{ClientAppID: clientAppID, GroupID: "group-id", ID: "app-id"}, nil
+				},
+				ExportFn: func(groupID, appID string, strategy api.ExportStrategy) (string, io.ReadCloser, error) {
+					return "my_app_123456.zip", u.NewZipResponseBody("../testdata/full_app"), nil
+				},
+			}
+
+			exitCode := exportCommand.Run([]string{"--app-id=my-cool-app", "--redact-config", "-o", output})
+			u.So(t, exitCode, gc.ShouldEqual, 0)
+			u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+			u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, filepath.Join(output, utils.RedactionManifestName))
+
+			secretsData, err := ioutil.ReadFile(filepath.Join(output, "secrets.json"))
+			u.So(t, err, gc.ShouldBeNil)
+			u.So(t, string(secretsData), gc.ShouldNotContainSubstring, "my-auth-token")
+			u.So(t, string(secretsData), gc.ShouldContainSubstring, utils.RedactedValue)
+
+			_, err = os.Stat(filepath.Join(output, utils.RedactionManifestName))
+			u.So(t, err, gc.ShouldBeNil)
+		})
+
+		t.Run("--include-dependencies", func(t *testing.T) {
+			setupWithDependencies := func(exportDependencies func(groupID, appID string) (string, io.ReadCloser, error)) (*ExportCommand, *cli.MockUi, map[string]string) {
+				exportCommand, mockUI := setup()
+				exportCommand.user = &user.User{APIKey: "my-api-key", AccessToken: u.GenerateValidAccessToken()}
+				exportCommand.exportToDirectory = func(dest string, r io.Reader, overwrite bool) error { return nil }
+
+				written := map[string]string{}
+				exportCommand.writeFileToDirectory = func(dest string, data io.Reader) error {
+					b, err := ioutil.ReadAll(data)
+					if err != nil {
+						return err
+					}
+					written[dest] = string(b)
+					return nil
+				}
+
+				exportCommand.realmClient = &u.MockRealmClient{
+					FetchAppByClientAppIDFn: func(clientAppID string) (*models.App, error) {
+						return &models.App{ClientAppID: clientAppID, GroupID: "group-id", ID: "app-id"}, nil
+					},
+					ExportFn: func(groupID, appID string, strategy api.ExportStrategy) (string, io.ReadCloser, error) {
+						return "my_app_123456.zip", u.NewResponseBody(strings.NewReader("myZipData")), nil
+					},
+					ExportDependencyFn: exportDependencies,
+				}
+				return exportCommand, mockUI, written
+			}
+
+			t.Run("writes the dependencies archive into the functions directory", func(t *testing.T) {
+				exportCommand, mockUI, written := setupWithDependencies(func(groupID, appID string) (string, io.ReadCloser, error) {
+					u.So(t, groupID, gc.ShouldEqual, "group-id")
+					u.So(t, appID, gc.ShouldEqual, "app-id")
+					return "node_modules.tar.gz", u.NewResponseBody(strings.NewReader("myDependencies")), nil
+				})
+
+				exitCode := exportCommand.Run([]string{"--app-id=my-cool-app", "--include-dependencies", "-o", "my_app"})
+				u.So(t, exitCode, gc.ShouldEqual, 0)
+				u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+				u.So(t, written, gc.ShouldResemble, map[string]string{
+					filepath.Join("my_app", utils.FunctionsRoot, "node_modules.tar.gz"): "myDependencies",
+				})
+			})
+
+			t.Run("skips an app without dependencies", func(t *testing.T) {
+				exportCommand, mockUI, written := setupWithDependencies(func(groupID, appID string) (string, io.ReadCloser, error) {
+					return "", nil, api.ErrNoDependencies
+				})
+
+				exitCode := exportCommand.Run([]string{"--app-id=my-cool-app", "--include-dependencies", "-o", "my_app"})
+				u.So(t, exitCode, gc.ShouldEqual, 0)
+				u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+				u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, "The app has no dependencies to export")
+				u.So(t, written, gc.ShouldBeEmpty)
+			})
+
+			t.Run("fails if the dependencies cannot be exported", func(t *testing.T) {
+				exportCommand, mockUI, _ := setupWithDependencies(func(groupID, appID string) (string, io.ReadCloser, error) {
+					return "", nil, errors.New("oh no")
+				})
+
+				exitCode := exportCommand.Run([]string{"--app-id=my-cool-app", "--include-dependencies", "-o", "my_app"})
+				u.So(t, exitCode, gc.ShouldEqual, 1)
+				u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, "failed to export dependencies: oh no")
+			})
+		})
+
+		t.Run("--incremental", func(t *testing.T) {
+			outputDir, err := ioutil.TempDir("", "realm-export-")
+			u.So(t, err, gc.ShouldBeNil)
+			defer os.RemoveAll(outputDir)
+
+			baselineData, err := ioutil.ReadAll(u.NewZipResponseBody("../testdata/full_app"))
+			u.So(t, err, gc.ShouldBeNil)
+			baselinePath := filepath.Join(outputDir, "baseline.zip")
+			u.So(t, ioutil.WriteFile(baselinePath, baselineData, 0644), gc.ShouldBeNil)
+
+			exportIncremental := func(t *testing.T, appDir string, args ...string) (*cli.MockUi, int, *zip.Reader) {
+				exportCommand, mockUI := setup()
+				exportCommand.user = &user.User{APIKey: "my-api-key", AccessToken: u.GenerateValidAccessToken()}
+				exportCommand.realmClient = &u.MockRealmClient{
+					FetchAppByClientAppIDFn: func(clientAppID string) (*models.App, error) {
+						return &models.App{ClientAppID: clientAppID, GroupID: "group-id", ID: "app-id"}, nil
+					},
+					ExportFn: func(groupID, appID string, strategy api.ExportStrategy) (string, io.ReadCloser, error) {
+						return "my_app_123456.zip", u.NewZipResponseBody(appDir), nil
+					},
+				}
+
+				var zipData []byte
+				exportCommand.writeFileToDirectory = func(dest string, data io.Reader) error {
+					b, err := ioutil.ReadAll(data)
+					zipData = b
+					return err
+				}
+
+				exitCode := exportCommand.Run(append([]string{"--app-id=my-cool-app", "--format=zip", "--incremental", "-o", filepath.Join(outputDir, "my_app")}, args...))
+				if exitCode != 0 {
+					return mockUI, exitCode, nil
+				}
+
+				return mockUI, exitCode, readZip(t, zipData)
+			}
+
+			readManifest := func(t *testing.T, r *zip.Reader) utils.IncrementalManifest {
+				var manifest utils.IncrementalManifest
+				for _, file := range r.File {
+					if file.Name != utils.IncrementalManifestName {
+						continue
+					}
+					rc, err := file.Open()
+					u.So(t, err, gc.ShouldBeNil)
+					defer rc.Close()
+					u.So(t, json.NewDecoder(rc).Decode(&manifest), gc.ShouldBeNil)
+				}
+				return manifest
+			}
+
+			t.Run("exports the full app without --against", func(t *testing.T) {
+				mockUI, exitCode, r := exportIncremental(t, "../testdata/full_app")
+				u.So(t, exitCode, gc.ShouldEqual, 0)
+				u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+				u.So(t, len(r.File), gc.ShouldEqual, len(readZip(t, baselineData).File))
+			})
+
+			t.Run("exports nothing but the manifest when nothing changed", func(t *testing.T) {
+				mockUI, exitCode, r := exportIncremental(t, "../testdata/full_app", "--against="+baselinePath)
+				u.So(t, exitCode, gc.ShouldEqual, 0)
+				u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, "Exported 0 changed and 0 deleted files")
+				u.So(t, len(r.File), gc.ShouldEqual, 1)
+
+				manifest := readManifest(t, r)
+				u.So(t, manifest.Baseline, gc.ShouldEqual, "baseline.zip")
+				u.So(t, manifest.BaselineSHA256, gc.ShouldNotBeEmpty)
+			})
+
+			t.Run("exports the changed files and lists the deleted ones", func(t *testing.T) {
+				mockUI, exitCode, r := exportIncremental(t, "../testdata/simple_app", "--against="+baselinePath)
+				u.So(t, exitCode, gc.ShouldEqual, 0)
+				u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+				u.So(t, len(r.File), gc.ShouldEqual, 2)
+
+				manifest := readManifest(t, r)
+				u.So(t, manifest.Changed, gc.ShouldResemble, []string{"config.json"})
+				u.So(t, manifest.Deleted, gc.ShouldContain, "secrets.json")
+				u.So(t, manifest.Deleted, gc.ShouldContain, "functions/function_a/source.js")
+			})
+
+			t.Run("fails if the baseline cannot be opened", func(t *testing.T) {
+				mockUI, exitCode, _ := exportIncremental(t, "../testdata/full_app", "--against=/somewhere/bogus.zip")
+				u.So(t, exitCode, gc.ShouldEqual, 1)
+				u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, "failed to open baseline")
+			})
+		})
+
 		t.Run("--for-source-control", func(t *testing.T) {
 			t.Run("calls RealmClient.Export properly", func(t *testing.T) {
 				exportCommand, _ := setup()
@@ -334,3 +645,10 @@ func TestExportCommand(t *testing.T) {
 		})
 	})
 }
+
+func readZip(t *testing.T, data []byte) *zip.Reader {
+	t.Helper()
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	u.So(t, err, gc.ShouldBeNil)
+	return r
+}
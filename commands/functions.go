@@ -0,0 +1,302 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/10gen/realm-cli/utils"
+
+	"github.com/mitchellh/cli"
+)
+
+const (
+	functionsFlagName       = "name"
+	functionsFlagArgs       = "args"
+	functionsFlagArgsFile   = "args-file"
+	functionsFlagExpect     = "expect"
+	functionsFlagExpectFile = "expect-file"
+	functionsFlagTimeout    = "timeout"
+)
+
+var (
+	errFunctionsAppIDRequired = fmt.Errorf("an App ID (--%s=[string]) must be supplied to run a function", flagAppIDName)
+	errFunctionsNameRequired  = fmt.Errorf("a function name (--%s=[string]) must be supplied", functionsFlagName)
+	errFunctionsExpectBoth    = fmt.Errorf("only one of --%s and --%s may be supplied", functionsFlagExpect, functionsFlagExpectFile)
+	errFunctionsArgsBoth      = fmt.Errorf("only one of --%s and --%s may be supplied", functionsFlagArgs, functionsFlagArgsFile)
+)
+
+func errFunctionTimeout(timeout time.Duration) error {
+	return fmt.Errorf("failed to run function: timed out after %s (--%s)", timeout, functionsFlagTimeout)
+}
+
+// NewFunctionsCommandFactory returns a new cli.CommandFactory given a cli.Ui
+func NewFunctionsCommandFactory(ui cli.Ui) cli.CommandFactory {
+	return func() (cli.Command, error) {
+		return &FunctionsCommand{
+			BaseCommand: &BaseCommand{
+				Name: "functions",
+				UI:   ui,
+			},
+		}, nil
+	}
+}
+
+// FunctionsCommand is used to interact with the functions of a Realm App
+type FunctionsCommand struct {
+	*BaseCommand
+}
+
+// Synopsis returns a one-liner description for this command
+func (fc *FunctionsCommand) Synopsis() string {
+	return "Interact with the functions of your Realm App."
+}
+
+// Help returns long-form help information for this command
+func (fc *FunctionsCommand) Help() string {
+	return fc.Synopsis()
+}
+
+// Run executes the command
+func (fc *FunctionsCommand) Run(args []string) int {
+	return cli.RunResultHelp
+}
+
+// NewFunctionsRunCommandFactory returns a new cli.CommandFactory given a cli.Ui
+func NewFunctionsRunCommandFactory(ui cli.Ui) cli.CommandFactory {
+	return func() (cli.Command, error) {
+		workingDirectory, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+
+		return &FunctionsRunCommand{
+			BaseCommand: &BaseCommand{
+				Name: "run",
+				UI:   ui,
+			},
+			workingDirectory: workingDirectory,
+		}, nil
+	}
+}
+
+// FunctionsRunCommand is used to run a function of a deployed Realm App
+type FunctionsRunCommand struct {
+	*BaseCommand
+
+	workingDirectory string
+
+	flagAppID      string
+	flagAppPath    string
+	flagProjectID  string
+	flagName       string
+	flagArgs       string
+	flagArgsFile   string
+	flagExpect     string
+	flagExpectFile string
+	flagTimeout    time.Duration
+}
+
+// Synopsis returns a one-liner description for this command
+func (frc *FunctionsRunCommand) Synopsis() string {
+	return "Run a function of your Realm App."
+}
+
+// Help returns long-form help information for this command
+func (frc *FunctionsRunCommand) Help() string {
+	return `Run a function of your deployed Realm Application as the system user and print its result,
+along with anything it logged. Fails with the error of the function if it throws.
+With --expect or --expect-file, the result is compared against an expected value and the command
+fails with a diff if they differ, e.g. to smoke test an app after deploying it.
+
+Usage: realm-cli functions run --name [string] [options]
+
+REQUIRED:
+  --name [string]
+	The name of the function to run.
+
+  --app-id [string]
+	The App ID for your app (i.e. the name of your app followed by a unique suffix, like "my-app-nysja").
+	Required if not being run from within a realm project directory.
+
+OPTIONS:
+  --path [string]
+	A path to the local directory containing your app, used to look up its App ID.
+
+  --project-id [string]
+	The Atlas Project ID.
+
+  --args [JSON array]
+	The arguments to pass to the function, e.g. '[1, "two", {"three": 3}]'.
+
+  --args-file [string]
+	A path to a file containing the JSON array of arguments to pass to the function.
+
+  --expect [JSON]
+	The value the function is expected to return. Objects are compared regardless of the order of their keys.
+
+  --expect-file [string]
+	A path to a file containing the JSON value the function is expected to return.
+
+  --timeout [duration]
+	How long to wait for the function to return before giving up, e.g. "30s". Waits indefinitely by default.
+	` +
+		frc.BaseCommand.Help()
+}
+
+// Run executes the command
+func (frc *FunctionsRunCommand) Run(args []string) int {
+	flags := frc.NewFlagSet()
+
+	flags.StringVar(&frc.flagAppID, flagAppIDName, "", "")
+	flags.StringVar(&frc.flagAppPath, importFlagPath, "", "")
+	flags.StringVar(&frc.flagProjectID, flagProjectIDName, "", "")
+	flags.StringVar(&frc.flagName, functionsFlagName, "", "")
+	flags.StringVar(&frc.flagArgs, functionsFlagArgs, "", "")
+	flags.StringVar(&frc.flagArgsFile, functionsFlagArgsFile, "", "")
+	flags.StringVar(&frc.flagExpect, functionsFlagExpect, "", "")
+	flags.StringVar(&frc.flagExpectFile, functionsFlagExpectFile, "", "")
+	flags.DurationVar(&frc.flagTimeout, functionsFlagTimeout, 0, "")
+
+	if err := frc.BaseCommand.run(args); err != nil {
+		frc.UI.Error(err.Error())
+		return 1
+	}
+
+	if err := frc.runFunction(); err != nil {
+		frc.UI.Error(err.Error())
+		return 1
+	}
+
+	return 0
+}
+
+func (frc *FunctionsRunCommand) runFunction() error {
+	if frc.flagName == "" {
+		return errFunctionsNameRequired
+	}
+
+	functionArgs, err := frc.functionArgs()
+	if err != nil {
+		return err
+	}
+
+	expected, hasExpected, err := frc.expectedResult()
+	if err != nil {
+		return err
+	}
+
+	_, app, realmClient, err := frc.resolveApp(appTarget{
+		appID:            frc.flagAppID,
+		appPath:          frc.flagAppPath,
+		workingDirectory: frc.workingDirectory,
+		projectID:        frc.flagProjectID,
+		errAppIDRequired: errFunctionsAppIDRequired,
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.Background(), func() {}
+	if frc.flagTimeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, frc.flagTimeout)
+	}
+	execution, err := realmClient.ExecuteFunction(ctx, app.GroupID, app.ID, frc.flagName, functionArgs)
+	timedOut := ctx.Err() == context.DeadlineExceeded
+	cancel()
+	if err != nil {
+		if timedOut {
+			return errFunctionTimeout(frc.flagTimeout)
+		}
+		return fmt.Errorf("failed to run function: %s", err)
+	}
+
+	for _, log := range execution.Logs {
+		frc.UI.Info(log)
+	}
+	for _, log := range execution.ErrorLogs {
+		frc.UI.Warn(log)
+	}
+
+	if execution.Error != "" {
+		if execution.ErrorCode != "" {
+			return fmt.Errorf("%s failed with %s: %s", frc.flagName, execution.ErrorCode, execution.Error)
+		}
+		return fmt.Errorf("%s failed: %s", frc.flagName, execution.Error)
+	}
+
+	result, err := json.MarshalIndent(execution.Result, "", "  ")
+	if err != nil {
+		return err
+	}
+	frc.UI.Output(string(result))
+
+	if !hasExpected {
+		return nil
+	}
+
+	diffs := utils.DiffJSON(expected, execution.Result)
+	if len(diffs) > 0 {
+		for _, diff := range diffs {
+			frc.UI.Error(diff)
+		}
+		return fmt.Errorf("the result of %s does not match the expected value", frc.flagName)
+	}
+
+	frc.UI.Info(fmt.Sprintf("The result of %s matches the expected value", frc.flagName))
+	return nil
+}
+
+// functionArgs parses the arguments set by --args or --args-file, if any
+func (frc *FunctionsRunCommand) functionArgs() ([]interface{}, error) {
+	if frc.flagArgs != "" && frc.flagArgsFile != "" {
+		return nil, errFunctionsArgsBoth
+	}
+
+	flagName, data := functionsFlagArgs, []byte(frc.flagArgs)
+	if frc.flagArgsFile != "" {
+		var err error
+		if data, err = ioutil.ReadFile(frc.flagArgsFile); err != nil {
+			return nil, fmt.Errorf("failed to read --%s: %s", functionsFlagArgsFile, err)
+		}
+		flagName = functionsFlagArgsFile
+	}
+
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	var args []interface{}
+	if err := json.Unmarshal(data, &args); err != nil {
+		return nil, fmt.Errorf("--%s must be a JSON array: %s", flagName, err)
+	}
+	return args, nil
+}
+
+// expectedResult parses the value set by --expect or --expect-file, if any
+func (frc *FunctionsRunCommand) expectedResult() (interface{}, bool, error) {
+	if frc.flagExpect != "" && frc.flagExpectFile != "" {
+		return nil, false, errFunctionsExpectBoth
+	}
+
+	data := []byte(frc.flagExpect)
+	if frc.flagExpectFile != "" {
+		var err error
+		if data, err = ioutil.ReadFile(frc.flagExpectFile); err != nil {
+			return nil, false, fmt.Errorf("failed to read --%s: %s", functionsFlagExpectFile, err)
+		}
+	}
+
+	if len(data) == 0 {
+		return nil, false, nil
+	}
+
+	var expected interface{}
+	if err := json.Unmarshal(data, &expected); err != nil {
+		return nil, false, fmt.Errorf("the expected result must be valid JSON: %s", err)
+	}
+	return expected, true, nil
+}
@@ -0,0 +1,223 @@
+package commands
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/10gen/realm-cli/models"
+	"github.com/10gen/realm-cli/user"
+	u "github.com/10gen/realm-cli/utils/test"
+	gc "github.com/smartystreets/goconvey/convey"
+
+	"github.com/mitchellh/cli"
+)
+
+func TestFunctionsRunCommand(t *testing.T) {
+	validArgs := []string{"--app-id=my-app-abcdef", "--name=checkout", `--args=[{"sku": "abc", "qty": 2}]`}
+
+	setup := func() (*FunctionsRunCommand, *cli.MockUi) {
+		mockUI := cli.NewMockUi()
+		cmd, err := NewFunctionsRunCommandFactory(mockUI)()
+		if err != nil {
+			panic(err)
+		}
+
+		runCommand := cmd.(*FunctionsRunCommand)
+		runCommand.storage = u.NewEmptyStorage()
+		return runCommand, mockUI
+	}
+
+	t.Run("should require the user to be logged in", func(t *testing.T) {
+		runCommand, mockUI := setup()
+		exitCode := runCommand.Run(validArgs)
+		u.So(t, exitCode, gc.ShouldEqual, 1)
+
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, user.ErrNotLoggedIn.Error())
+	})
+
+	t.Run("when the user is logged in", func(t *testing.T) {
+		expectFile, err := ioutil.TempFile("", "realm-expect-")
+		u.So(t, err, gc.ShouldBeNil)
+		defer os.Remove(expectFile.Name())
+		_, err = expectFile.WriteString(`{"total": 20, "status": "ok"}`)
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, expectFile.Close(), gc.ShouldBeNil)
+
+		argsFile, err := ioutil.TempFile("", "realm-args-")
+		u.So(t, err, gc.ShouldBeNil)
+		defer os.Remove(argsFile.Name())
+		_, err = argsFile.WriteString(`[{"sku": "abc", "qty": 2}]`)
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, argsFile.Close(), gc.ShouldBeNil)
+
+		newRealmClient := func(calls *[][]interface{}) *u.MockRealmClient {
+			return &u.MockRealmClient{
+				FetchAppByClientAppIDFn: func(clientAppID string) (*models.App, error) {
+					return &models.App{GroupID: "group-id", ID: "app-id", ClientAppID: clientAppID}, nil
+				},
+				ExecuteFunctionFn: func(ctx context.Context, groupID, appID, name string, args []interface{}) (*models.FunctionExecution, error) {
+					*calls = append(*calls, []interface{}{groupID, appID, name, args})
+					return &models.FunctionExecution{
+						Result: map[string]interface{}{"status": "ok", "total": 20.0},
+						Logs:   []string{"checking out abc"},
+					}, nil
+				},
+			}
+		}
+
+		for _, tc := range []struct {
+			Description      string
+			Args             []string
+			ExpectedExitCode int
+			ExpectedOutput   string
+			ExpectedErrors   []string
+		}{
+			{
+				Description:    "it prints the logs and the result of the function",
+				Args:           validArgs,
+				ExpectedOutput: "checking out abc\n{\n  \"status\": \"ok\",\n  \"total\": 20\n}\n",
+			},
+			{
+				Description:    "it reads the arguments from --args-file",
+				Args:           []string{"--app-id=my-app-abcdef", "--name=checkout", "--args-file=" + argsFile.Name()},
+				ExpectedOutput: "checking out abc\n{\n  \"status\": \"ok\",\n  \"total\": 20\n}\n",
+			},
+			{
+				Description:    "it succeeds if the result matches --expect regardless of key order",
+				Args:           append([]string{`--expect={"total": 20, "status": "ok"}`}, validArgs...),
+				ExpectedOutput: "The result of checkout matches the expected value",
+			},
+			{
+				Description:    "it succeeds if the result matches --expect-file",
+				Args:           append([]string{"--expect-file=" + expectFile.Name()}, validArgs...),
+				ExpectedOutput: "The result of checkout matches the expected value",
+			},
+			{
+				Description:      "it fails with a diff if the result does not match --expect",
+				Args:             append([]string{`--expect={"total": 25, "status": "ok", "coupon": null}`}, validArgs...),
+				ExpectedExitCode: 1,
+				ExpectedErrors: []string{
+					"- $.coupon: null",
+					"- $.total: 25\n+ $.total: 20",
+					"the result of checkout does not match the expected value",
+				},
+			},
+		} {
+			t.Run(tc.Description, func(t *testing.T) {
+				runCommand, mockUI := setup()
+				runCommand.user = &user.User{APIKey: "my-api-key", AccessToken: u.GenerateValidAccessToken()}
+
+				var calls [][]interface{}
+				runCommand.realmClient = newRealmClient(&calls)
+
+				exitCode := runCommand.Run(tc.Args)
+				u.So(t, exitCode, gc.ShouldEqual, tc.ExpectedExitCode)
+				u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, tc.ExpectedOutput)
+				if len(tc.ExpectedErrors) == 0 {
+					u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+				}
+				for _, expectedError := range tc.ExpectedErrors {
+					u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, expectedError)
+				}
+
+				u.So(t, calls, gc.ShouldResemble, [][]interface{}{
+					{"group-id", "app-id", "checkout", []interface{}{map[string]interface{}{"sku": "abc", "qty": 2.0}}},
+				})
+			})
+		}
+
+		for _, tc := range []struct {
+			Description   string
+			Args          []string
+			ExpectedError string
+		}{
+			{
+				Description:   "it requires a function name",
+				Args:          []string{"--app-id=my-app-abcdef"},
+				ExpectedError: errFunctionsNameRequired.Error(),
+			},
+			{
+				Description:   "it requires an app id outside of a project directory",
+				Args:          []string{"--name=checkout", "--path=" + filepath.Join(os.TempDir(), "missing-app")},
+				ExpectedError: errFunctionsAppIDRequired.Error(),
+			},
+			{
+				Description:   "it fails if --args is not a JSON array",
+				Args:          []string{"--app-id=my-app-abcdef", "--name=checkout", `--args={"sku": "abc"}`},
+				ExpectedError: "--args must be a JSON array",
+			},
+			{
+				Description:   "it fails if the expected result is not valid JSON",
+				Args:          append([]string{"--expect={total: 20}"}, validArgs...),
+				ExpectedError: "the expected result must be valid JSON",
+			},
+			{
+				Description:   "it fails with both --expect and --expect-file",
+				Args:          append([]string{"--expect=20", "--expect-file=" + expectFile.Name()}, validArgs...),
+				ExpectedError: errFunctionsExpectBoth.Error(),
+			},
+			{
+				Description:   "it fails with both --args and --args-file",
+				Args:          append([]string{"--args-file=" + argsFile.Name()}, validArgs...),
+				ExpectedError: errFunctionsArgsBoth.Error(),
+			},
+		} {
+			t.Run(tc.Description, func(t *testing.T) {
+				runCommand, mockUI := setup()
+				runCommand.user = &user.User{APIKey: "my-api-key", AccessToken: u.GenerateValidAccessToken()}
+
+				var calls [][]interface{}
+				runCommand.realmClient = newRealmClient(&calls)
+
+				exitCode := runCommand.Run(tc.Args)
+				u.So(t, exitCode, gc.ShouldEqual, 1)
+				u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, tc.ExpectedError)
+				u.So(t, calls, gc.ShouldBeEmpty)
+			})
+		}
+
+		t.Run("it fails with the error of the function if it throws", func(t *testing.T) {
+			runCommand, mockUI := setup()
+			runCommand.user = &user.User{APIKey: "my-api-key", AccessToken: u.GenerateValidAccessToken()}
+			runCommand.realmClient = &u.MockRealmClient{
+				FetchAppByClientAppIDFn: func(clientAppID string) (*models.App, error) {
+					return &models.App{GroupID: "group-id", ID: "app-id", ClientAppID: clientAppID}, nil
+				},
+				ExecuteFunctionFn: func(ctx context.Context, groupID, appID, name string, args []interface{}) (*models.FunctionExecution, error) {
+					return &models.FunctionExecution{
+						Logs:      []string{"checking out abc"},
+						Error:     "out of stock",
+						ErrorCode: "FunctionExecutionError",
+					}, nil
+				},
+			}
+
+			exitCode := runCommand.Run(validArgs)
+			u.So(t, exitCode, gc.ShouldEqual, 1)
+			u.So(t, mockUI.OutputWriter.String(), gc.ShouldEqual, "checking out abc\n")
+			u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, "checkout failed with FunctionExecutionError: out of stock")
+		})
+
+		t.Run("it gives up on the function after --timeout", func(t *testing.T) {
+			runCommand, mockUI := setup()
+			runCommand.user = &user.User{APIKey: "my-api-key", AccessToken: u.GenerateValidAccessToken()}
+			runCommand.realmClient = &u.MockRealmClient{
+				FetchAppByClientAppIDFn: func(clientAppID string) (*models.App, error) {
+					return &models.App{GroupID: "group-id", ID: "app-id", ClientAppID: clientAppID}, nil
+				},
+				ExecuteFunctionFn: func(ctx context.Context, groupID, appID, name string, args []interface{}) (*models.FunctionExecution, error) {
+					<-ctx.Done()
+					return nil, ctx.Err()
+				},
+			}
+
+			exitCode := runCommand.Run(append([]string{"--timeout=10ms"}, validArgs...))
+			u.So(t, exitCode, gc.ShouldEqual, 1)
+			u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, errFunctionTimeout(10*time.Millisecond).Error())
+		})
+	})
+}
@@ -0,0 +1,635 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/10gen/realm-cli/hosting"
+	"github.com/10gen/realm-cli/utils"
+
+	"github.com/mitchellh/cli"
+)
+
+const (
+	hostingFlagPrune  = "prune"
+	hostingFlagPrefix = "prefix"
+	hostingFlagOutput = "output"
+
+	hostingOutputText = "text"
+	hostingOutputJSON = "json"
+)
+
+var (
+	errHostingAppIDRequired     = fmt.Errorf("an App ID (--%s=[string]) must be supplied to upload hosting assets", flagAppIDName)
+	errHostingListAppIDRequired = fmt.Errorf("an App ID (--%s=[string]) must be supplied to list hosting assets", flagAppIDName)
+
+	errHostingRemoveAppIDRequired = fmt.Errorf("an App ID (--%s=[string]) must be supplied to remove hosting assets", flagAppIDName)
+	errHostingRemovePathRequired  = errors.New("the path of at least one hosting asset to remove must be supplied")
+)
+
+// NewHostingCommandFactory returns a new cli.CommandFactory given a cli.Ui
+func NewHostingCommandFactory(ui cli.Ui) cli.CommandFactory {
+	return func() (cli.Command, error) {
+		return &HostingCommand{
+			BaseCommand: &BaseCommand{
+				Name: "hosting",
+				UI:   ui,
+			},
+		}, nil
+	}
+}
+
+// HostingCommand is used to manage a Realm App's static hosting assets
+type HostingCommand struct {
+	*BaseCommand
+}
+
+// Synopsis returns a one-liner description for this command
+func (hc *HostingCommand) Synopsis() string {
+	return "Manage the static hosting assets of your Realm App."
+}
+
+// Help returns long-form help information for this command
+func (hc *HostingCommand) Help() string {
+	return hc.Synopsis()
+}
+
+// Run executes the command
+func (hc *HostingCommand) Run(args []string) int {
+	return cli.RunResultHelp
+}
+
+// NewHostingUploadCommandFactory returns a new cli.CommandFactory given a cli.Ui
+func NewHostingUploadCommandFactory(ui cli.Ui) cli.CommandFactory {
+	return func() (cli.Command, error) {
+		workingDirectory, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+
+		return &HostingUploadCommand{
+			BaseCommand: &BaseCommand{
+				Name: "upload",
+				UI:   ui,
+			},
+			workingDirectory:              workingDirectory,
+			cacheInvalidationPollInterval: cacheInvalidationPollInterval,
+		}, nil
+	}
+}
+
+// HostingUploadCommand is used to upload static hosting assets without importing the app configuration
+type HostingUploadCommand struct {
+	*BaseCommand
+
+	workingDirectory              string
+	cacheInvalidationPollInterval time.Duration
+
+	flagAppID               string
+	flagAppPath             string
+	flagProjectID           string
+	flagPrune               bool
+	flagResetCDNCache       bool
+	flagCachePaths          string
+	flagCacheThreshold      int
+	flagRebuildHostingCache bool
+	flagConcurrency         int
+	flagFailFast            bool
+}
+
+// Synopsis returns a one-liner description for this command
+func (huc *HostingUploadCommand) Synopsis() string {
+	return "Upload static hosting assets to your Realm App."
+}
+
+// Help returns long-form help information for this command
+func (huc *HostingUploadCommand) Help() string {
+	return `Upload the static assets from the "/hosting" directory of a local app to your Realm Application,
+without importing the rest of the app configuration.
+
+Usage: realm-cli hosting upload [options]
+
+REQUIRED:
+  --app-id [string]
+	The App ID for your app (i.e. the name of your app followed by a unique suffix, like "my-app-nysja").
+	Required if not being run from within a realm project directory.
+
+OPTIONS:
+  --path [string]
+	A path to the local directory containing your app.
+
+  --project-id [string]
+	The Atlas Project ID.
+
+  --prune
+	Remove assets from your app that do not exist in the local "/hosting" directory.
+
+  --reset-cdn-cache
+	Invalidate cdn cache for modified files.
+
+  --cache-paths [string]
+	A comma separated list of paths to invalidate in the cdn cache instead of the modified files, like "/index.html,/static/*".
+	Implies --reset-cdn-cache.
+
+  --cache-invalidation-threshold [int] (default: 20)
+	How many modified files to invalidate one by one with --reset-cdn-cache before the whole cdn cache is invalidated instead.
+
+  --rebuild-hosting-cache
+	Discard the local hosting asset cache and hash every asset in "/hosting" again.
+
+  --concurrency [int] (default: 4)
+	How many assets to upload, modify or remove at once.
+
+  --fail-fast
+	Stop uploading, modifying or removing assets once one of them fails.
+	` +
+		huc.BaseCommand.Help()
+}
+
+// Run executes the command
+func (huc *HostingUploadCommand) Run(args []string) int {
+	flags := huc.NewFlagSet()
+
+	flags.StringVar(&huc.flagAppID, flagAppIDName, "", "")
+	flags.StringVar(&huc.flagAppPath, importFlagPath, "", "")
+	flags.StringVar(&huc.flagProjectID, flagProjectIDName, "", "")
+	flags.BoolVar(&huc.flagPrune, hostingFlagPrune, false, "")
+	flags.BoolVar(&huc.flagResetCDNCache, importFlagResetCDNCache, false, "")
+	flags.StringVar(&huc.flagCachePaths, importFlagCachePaths, "", "")
+	flags.IntVar(&huc.flagCacheThreshold, importFlagCacheThreshold, defaultCacheInvalidationThreshold, "")
+	flags.BoolVar(&huc.flagRebuildHostingCache, importFlagRebuildHostingCache, false, "")
+	flags.IntVar(&huc.flagConcurrency, importFlagHostingConcurrency, numWorkers, "")
+	flags.BoolVar(&huc.flagFailFast, importFlagHostingFailFast, false, "")
+
+	if err := huc.BaseCommand.run(args); err != nil {
+		huc.UI.Error(err.Error())
+		return 1
+	}
+
+	if err := huc.upload(); err != nil {
+		huc.UI.Error(err.Error())
+		return 1
+	}
+
+	return 0
+}
+
+func (huc *HostingUploadCommand) upload() error {
+	if huc.flagConcurrency < 1 {
+		return fmt.Errorf("--%s must be at least 1", importFlagHostingConcurrency)
+	}
+
+	cachePaths, err := cdnCachePaths(huc.flagCachePaths)
+	if err != nil {
+		return err
+	}
+
+	if huc.flagCacheThreshold < 0 {
+		return fmt.Errorf("--%s must not be negative", importFlagCacheThreshold)
+	}
+
+	appPath, app, realmClient, err := huc.resolveApp(appTarget{
+		appID:            huc.flagAppID,
+		appPath:          huc.flagAppPath,
+		workingDirectory: huc.workingDirectory,
+		projectID:        huc.flagProjectID,
+		localApp:         true,
+		errAppIDRequired: errHostingAppIDRequired,
+	})
+	if err != nil {
+		return err
+	}
+
+	rootDir, err := filepath.Abs(filepath.Join(appPath, utils.HostingFilesDirectory))
+	if err != nil {
+		return err
+	}
+
+	assetMetadataDiffs, err := diffHostingAssets(rootDir, appPath, app.ClientAppID, huc.flagConfigPath, app, !huc.flagPrune, huc.flagRebuildHostingCache, realmClient, huc.UI)
+	if err != nil {
+		return err
+	}
+
+	diffs := assetMetadataDiffs.Diff()
+	if len(diffs) == 0 {
+		huc.UI.Info("Deployed hosting assets are identical to the local ones, nothing to do.")
+		return nil
+	}
+
+	for _, diff := range diffs {
+		huc.UI.Info(diff)
+	}
+
+	if !huc.flagYes {
+		confirm, err := huc.AskYesNo("Please confirm the changes shown above:")
+		if err != nil {
+			return err
+		}
+
+		if !confirm {
+			return nil
+		}
+	}
+
+	huc.UI.Info("Uploading hosting assets...")
+	uploadErr := ImportHosting(app.GroupID, app.ID, rootDir, assetMetadataDiffs, huc.flagConcurrency, huc.flagFailFast, realmClient, huc.UI)
+	forgetRemoteAssets(huc.flagConfigPath, app, huc.UI)
+	if uploadErr != nil {
+		return fmt.Errorf("failed to upload hosting assets: %s", uploadErr)
+	}
+
+	if len(cachePaths) == 0 && huc.flagResetCDNCache {
+		cachePaths = changedCDNCachePaths(assetMetadataDiffs, huc.flagCacheThreshold)
+	}
+	if len(cachePaths) > 0 {
+		if err := InvalidateHostingCache(app.GroupID, app.ID, cachePaths, huc.cacheInvalidationPollInterval, cacheInvalidationTimeout, realmClient, huc.UI); err != nil {
+			return err
+		}
+	}
+	huc.UI.Info("Done.")
+
+	return nil
+}
+
+// NewHostingListCommandFactory returns a new cli.CommandFactory given a cli.Ui
+func NewHostingListCommandFactory(ui cli.Ui) cli.CommandFactory {
+	return func() (cli.Command, error) {
+		workingDirectory, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+
+		return &HostingListCommand{
+			BaseCommand: &BaseCommand{
+				Name: "list",
+				UI:   ui,
+			},
+			workingDirectory: workingDirectory,
+		}, nil
+	}
+}
+
+// HostingListCommand is used to list the static hosting assets deployed to a Realm App
+type HostingListCommand struct {
+	*BaseCommand
+
+	workingDirectory string
+
+	flagAppID     string
+	flagAppPath   string
+	flagProjectID string
+	flagPrefix    string
+	flagOutput    string
+}
+
+// hostingAsset is a static hosting asset as it is listed
+type hostingAsset struct {
+	Path         string `json:"path"`
+	Size         int64  `json:"size"`
+	LastModified string `json:"last_modified,omitempty"`
+	Hash         string `json:"hash,omitempty"`
+}
+
+// Synopsis returns a one-liner description for this command
+func (hlc *HostingListCommand) Synopsis() string {
+	return "List the static hosting assets deployed to your Realm App."
+}
+
+// Help returns long-form help information for this command
+func (hlc *HostingListCommand) Help() string {
+	return `List the path, size and last modified time of each static hosting asset deployed to your Realm
+Application, i.e. what is actually served, without diffing against a local app.
+
+Usage: realm-cli hosting list [options]
+
+OPTIONS:
+  --app-id [string]
+	The App ID for your app (i.e. the name of your app followed by a unique suffix, like "my-app-nysja").
+	Required if not being run from within a realm project directory.
+
+  --path [string]
+	A path to the local directory containing your app, used to look up its App ID.
+
+  --project-id [string]
+	The Atlas Project ID.
+
+  --prefix [string]
+	Only list the assets whose path starts with this prefix, like "/static/".
+
+  -o [text|json], --output [text|json] (default: text)
+	How the assets should be printed.
+	json - print the assets as a JSON array, e.g. for scripts to read.
+	` +
+		hlc.BaseCommand.Help()
+}
+
+// Run executes the command
+func (hlc *HostingListCommand) Run(args []string) int {
+	flags := hlc.NewFlagSet()
+
+	flags.StringVar(&hlc.flagAppID, flagAppIDName, "", "")
+	flags.StringVar(&hlc.flagAppPath, importFlagPath, "", "")
+	flags.StringVar(&hlc.flagProjectID, flagProjectIDName, "", "")
+	flags.StringVar(&hlc.flagPrefix, hostingFlagPrefix, "", "")
+	flags.StringVar(&hlc.flagOutput, hostingFlagOutput, hostingOutputText, "")
+	flags.StringVar(&hlc.flagOutput, "o", hostingOutputText, "")
+
+	if err := hlc.BaseCommand.run(args); err != nil {
+		hlc.UI.Error(err.Error())
+		return 1
+	}
+
+	if err := hlc.list(); err != nil {
+		hlc.UI.Error(err.Error())
+		return 1
+	}
+
+	return 0
+}
+
+func (hlc *HostingListCommand) list() error {
+	switch hlc.flagOutput {
+	case hostingOutputText, hostingOutputJSON:
+	default:
+		return fmt.Errorf("unknown output format %q; accepted values are [%s|%s]", hlc.flagOutput, hostingOutputText, hostingOutputJSON)
+	}
+
+	_, app, realmClient, err := hlc.resolveApp(appTarget{
+		appID:            hlc.flagAppID,
+		appPath:          hlc.flagAppPath,
+		workingDirectory: hlc.workingDirectory,
+		projectID:        hlc.flagProjectID,
+		errAppIDRequired: errHostingListAppIDRequired,
+	})
+	if err != nil {
+		return err
+	}
+
+	assetMetadata, err := realmClient.ListAssetsForAppID(app.GroupID, app.ID)
+	if err != nil {
+		return fmt.Errorf("failed to list hosting assets: %s", err)
+	}
+
+	assets := listedHostingAssets(assetMetadata, hlc.flagPrefix)
+
+	if hlc.flagOutput == hostingOutputJSON {
+		data, err := json.MarshalIndent(assets, "", "  ")
+		if err != nil {
+			return err
+		}
+		hlc.UI.Output(string(data))
+		return nil
+	}
+
+	if len(assets) == 0 {
+		if hlc.flagPrefix != "" {
+			hlc.UI.Info(fmt.Sprintf("No hosting assets found under %s for %s.", hlc.flagPrefix, app.ClientAppID))
+		} else {
+			hlc.UI.Info(fmt.Sprintf("No hosting assets found for %s.", app.ClientAppID))
+		}
+		return nil
+	}
+
+	hlc.UI.Output(hostingAssetsTable(assets))
+	return nil
+}
+
+// listedHostingAssets returns the files among the assets whose path starts with prefix, sorted by path
+func listedHostingAssets(assetMetadata []hosting.AssetMetadata, prefix string) []hostingAsset {
+	if prefix != "" && !strings.HasPrefix(prefix, "/") {
+		prefix = "/" + prefix
+	}
+
+	assets := []hostingAsset{}
+	for _, amd := range assetMetadata {
+		if amd.IsDir() || !strings.HasPrefix(amd.FilePath, prefix) {
+			continue
+		}
+
+		asset := hostingAsset{Path: amd.FilePath, Size: amd.FileSize, Hash: amd.FileHash}
+		if amd.LastModified > 0 {
+			asset.LastModified = time.Unix(amd.LastModified, 0).UTC().Format(time.RFC3339)
+		}
+		assets = append(assets, asset)
+	}
+
+	sort.SliceStable(assets, func(i, j int) bool { return assets[i].Path < assets[j].Path })
+	return assets
+}
+
+// hostingAssetsTable formats the assets as a table
+func hostingAssetsTable(assets []hostingAsset) string {
+	var table strings.Builder
+	w := tabwriter.NewWriter(&table, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PATH\tSIZE\tLAST MODIFIED")
+	for _, asset := range assets {
+		fmt.Fprintf(w, "%s\t%d\t%s\n", asset.Path, asset.Size, valueOrDash(asset.LastModified))
+	}
+	w.Flush()
+
+	return strings.TrimSuffix(table.String(), "\n")
+}
+
+// NewHostingRemoveCommandFactory returns a new cli.CommandFactory given a cli.Ui
+func NewHostingRemoveCommandFactory(ui cli.Ui) cli.CommandFactory {
+	return func() (cli.Command, error) {
+		workingDirectory, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+
+		return &HostingRemoveCommand{
+			BaseCommand: &BaseCommand{
+				Name: "remove",
+				UI:   ui,
+			},
+			workingDirectory:              workingDirectory,
+			cacheInvalidationPollInterval: cacheInvalidationPollInterval,
+		}, nil
+	}
+}
+
+// HostingRemoveCommand is used to remove individual static hosting assets from a Realm App
+type HostingRemoveCommand struct {
+	*BaseCommand
+
+	workingDirectory              string
+	cacheInvalidationPollInterval time.Duration
+
+	flagAppID          string
+	flagAppPath        string
+	flagProjectID      string
+	flagResetCDNCache  bool
+	flagCacheThreshold int
+}
+
+// Synopsis returns a one-liner description for this command
+func (hrc *HostingRemoveCommand) Synopsis() string {
+	return "Remove static hosting assets from your Realm App."
+}
+
+// Help returns long-form help information for this command
+func (hrc *HostingRemoveCommand) Help() string {
+	return `Remove the static hosting assets at the given paths from your Realm Application, without uploading
+the rest of the "/hosting" directory. A path may be a glob, like "/static/*.js", where "*" does not match
+"/", or end with "/" to remove every asset under it.
+
+Usage: realm-cli hosting remove [options] <path>...
+
+OPTIONS:
+  --app-id [string]
+	The App ID for your app (i.e. the name of your app followed by a unique suffix, like "my-app-nysja").
+	Required if not being run from within a realm project directory.
+
+  --path [string]
+	A path to the local directory containing your app, used to look up its App ID.
+
+  --project-id [string]
+	The Atlas Project ID.
+
+  --reset-cdn-cache
+	Invalidate cdn cache for the removed files.
+
+  --cache-invalidation-threshold [int] (default: 20)
+	How many removed files to invalidate one by one with --reset-cdn-cache before the whole cdn cache is invalidated instead.
+	` +
+		hrc.BaseCommand.Help()
+}
+
+// Run executes the command
+func (hrc *HostingRemoveCommand) Run(args []string) int {
+	flags := hrc.NewFlagSet()
+
+	flags.StringVar(&hrc.flagAppID, flagAppIDName, "", "")
+	flags.StringVar(&hrc.flagAppPath, importFlagPath, "", "")
+	flags.StringVar(&hrc.flagProjectID, flagProjectIDName, "", "")
+	flags.BoolVar(&hrc.flagResetCDNCache, importFlagResetCDNCache, false, "")
+	flags.IntVar(&hrc.flagCacheThreshold, importFlagCacheThreshold, defaultCacheInvalidationThreshold, "")
+
+	if err := hrc.BaseCommand.run(args); err != nil {
+		hrc.UI.Error(err.Error())
+		return 1
+	}
+
+	if err := hrc.remove(flags.Args()); err != nil {
+		hrc.UI.Error(err.Error())
+		return 1
+	}
+
+	return 0
+}
+
+func (hrc *HostingRemoveCommand) remove(patterns []string) error {
+	if len(patterns) == 0 {
+		return errHostingRemovePathRequired
+	}
+
+	if hrc.flagCacheThreshold < 0 {
+		return fmt.Errorf("--%s must not be negative", importFlagCacheThreshold)
+	}
+
+	_, app, realmClient, err := hrc.resolveApp(appTarget{
+		appID:            hrc.flagAppID,
+		appPath:          hrc.flagAppPath,
+		workingDirectory: hrc.workingDirectory,
+		projectID:        hrc.flagProjectID,
+		errAppIDRequired: errHostingRemoveAppIDRequired,
+	})
+	if err != nil {
+		return err
+	}
+
+	assetMetadata, err := realmClient.ListAssetsForAppID(app.GroupID, app.ID)
+	if err != nil {
+		return fmt.Errorf("failed to list hosting assets: %s", err)
+	}
+
+	removed, err := matchHostingAssets(assetMetadata, patterns)
+	if err != nil {
+		return err
+	}
+
+	hrc.UI.Info("Removed Files:")
+	for _, asset := range removed {
+		hrc.UI.Info(fmt.Sprintf("\t- %s", asset.FilePath))
+	}
+
+	if !hrc.flagYes {
+		confirm, err := hrc.AskYesNo("Please confirm the changes shown above:")
+		if err != nil {
+			return err
+		}
+
+		if !confirm {
+			return nil
+		}
+	}
+
+	hrc.UI.Info("Removing hosting assets...")
+	for _, asset := range removed {
+		if err := realmClient.DeleteAsset(app.GroupID, app.ID, asset.FilePath); err != nil {
+			return fmt.Errorf("failed to remove %s: %s", asset.FilePath, err)
+		}
+	}
+	forgetRemoteAssets(hrc.flagConfigPath, app, hrc.UI)
+
+	if hrc.flagResetCDNCache {
+		cachePaths := changedCDNCachePaths(&hosting.AssetMetadataDiffs{DeletedLocally: removed}, hrc.flagCacheThreshold)
+		if err := InvalidateHostingCache(app.GroupID, app.ID, cachePaths, hrc.cacheInvalidationPollInterval, cacheInvalidationTimeout, realmClient, hrc.UI); err != nil {
+			return err
+		}
+	}
+	hrc.UI.Info("Done.")
+
+	return nil
+}
+
+// matchHostingAssets returns the files among the assets matched by any of the patterns, sorted by path.
+// Each pattern must match at least one file, so that a mistyped path is not silently ignored
+func matchHostingAssets(assetMetadata []hosting.AssetMetadata, patterns []string) ([]hosting.AssetMetadata, error) {
+	matched := map[string]hosting.AssetMetadata{}
+	for _, pattern := range patterns {
+		if !strings.HasPrefix(pattern, "/") {
+			pattern = "/" + pattern
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid path %q: %s", pattern, err)
+		}
+
+		var found bool
+		for _, amd := range assetMetadata {
+			if amd.IsDir() {
+				continue
+			}
+
+			var ok bool
+			if strings.HasSuffix(pattern, "/") {
+				ok = strings.HasPrefix(amd.FilePath, pattern)
+			} else {
+				ok, _ = path.Match(pattern, amd.FilePath)
+			}
+			if ok {
+				matched[amd.FilePath] = amd
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("no hosting assets found at %s", pattern)
+		}
+	}
+
+	assets := make([]hosting.AssetMetadata, 0, len(matched))
+	for _, amd := range matched {
+		assets = append(assets, amd)
+	}
+	sort.SliceStable(assets, func(i, j int) bool { return assets[i].FilePath < assets[j].FilePath })
+	return assets, nil
+}
@@ -0,0 +1,439 @@
+package commands
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/10gen/realm-cli/hosting"
+	"github.com/10gen/realm-cli/models"
+	"github.com/10gen/realm-cli/user"
+	"github.com/10gen/realm-cli/utils"
+	u "github.com/10gen/realm-cli/utils/test"
+	gc "github.com/smartystreets/goconvey/convey"
+
+	"github.com/mitchellh/cli"
+)
+
+func TestHostingUploadCommand(t *testing.T) {
+	configPath := "../testdata/configs/tmp/config.json"
+	validArgs := []string{"--app-id=my-app-abcdef", "--path=../testdata/full_app", "--config-path=" + configPath}
+
+	setup := func() (*HostingUploadCommand, *cli.MockUi) {
+		mockUI := cli.NewMockUi()
+		cmd, err := NewHostingUploadCommandFactory(mockUI)()
+		if err != nil {
+			panic(err)
+		}
+
+		uploadCommand := cmd.(*HostingUploadCommand)
+		uploadCommand.storage = u.NewEmptyStorage()
+		return uploadCommand, mockUI
+	}
+
+	t.Run("should require the user to be logged in", func(t *testing.T) {
+		uploadCommand, mockUI := setup()
+		exitCode := uploadCommand.Run(validArgs)
+		u.So(t, exitCode, gc.ShouldEqual, 1)
+
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, user.ErrNotLoggedIn.Error())
+	})
+
+	t.Run("should reject a concurrency below 1", func(t *testing.T) {
+		uploadCommand, mockUI := setup()
+		exitCode := uploadCommand.Run(append([]string{"--concurrency=0"}, validArgs...))
+		u.So(t, exitCode, gc.ShouldEqual, 1)
+
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, "--concurrency must be at least 1")
+	})
+
+	t.Run("when the user is logged in", func(t *testing.T) {
+		defer os.Remove(filepath.Join(filepath.Dir(configPath), utils.HostingCacheFileName))
+
+		type testCase struct {
+			Description        string
+			Args               []string
+			ExpectedUploads    []string
+			ExpectedDeletes    []string
+			ExpectedResetPaths []string
+		}
+
+		for _, tc := range []testCase{
+			{
+				Description:     "it uploads the local assets missing from the app",
+				Args:            append([]string{"-y"}, validArgs...),
+				ExpectedUploads: []string{"/asset_file0.json", "/asset_file1.html", "/ships/nostromo.json"},
+			},
+			{
+				Description:     "it removes the assets missing locally with --prune",
+				Args:            append([]string{"-y", "--prune"}, validArgs...),
+				ExpectedUploads: []string{"/asset_file0.json", "/asset_file1.html", "/ships/nostromo.json"},
+				ExpectedDeletes: []string{
+					"/bar/attrsShouldAllRemain.html",
+					"/bar/attrsShouldRemoveAllButOne.html",
+					"/bar/shouldBeRemoved",
+					"/bar/shouldBeRemoved.html",
+					"/bar/shouldBeRemoved.txt",
+					"/bar/shouldRemainSame.txt",
+				},
+			},
+			{
+				Description:        "it invalidates the cdn cache of the changed assets with --reset-cdn-cache",
+				Args:               append([]string{"-y", "--reset-cdn-cache"}, validArgs...),
+				ExpectedUploads:    []string{"/asset_file0.json", "/asset_file1.html", "/ships/nostromo.json"},
+				ExpectedResetPaths: []string{"/asset_file0.json", "/asset_file1.html", "/ships/nostromo.json"},
+			},
+			{
+				Description:        "it invalidates the whole cdn cache when more assets changed than --cache-invalidation-threshold",
+				Args:               append([]string{"-y", "--reset-cdn-cache", "--cache-invalidation-threshold=2"}, validArgs...),
+				ExpectedUploads:    []string{"/asset_file0.json", "/asset_file1.html", "/ships/nostromo.json"},
+				ExpectedResetPaths: []string{"/*"},
+			},
+			{
+				Description:        "it invalidates the cdn cache of the paths given with --cache-paths",
+				Args:               append([]string{"-y", "--cache-paths=/index.html, /static/*"}, validArgs...),
+				ExpectedUploads:    []string{"/asset_file0.json", "/asset_file1.html", "/ships/nostromo.json"},
+				ExpectedResetPaths: []string{"/index.html", "/static/*"},
+			},
+		} {
+			t.Run(tc.Description, func(t *testing.T) {
+				uploadCommand, mockUI := setup()
+				uploadCommand.user = &user.User{
+					APIKey:      "my-api-key",
+					AccessToken: u.GenerateValidAccessToken(),
+				}
+
+				var mu sync.Mutex
+				var uploads, deletes []string
+				var resetPaths []string
+				uploadCommand.realmClient = &u.MockRealmClient{
+					FetchAppByClientAppIDFn: func(clientAppID string) (*models.App, error) {
+						return &models.App{GroupID: "group-id", ID: "app-id"}, nil
+					},
+					UploadAssetFn: func(groupID, appID, path, hash string, size int64, body io.Reader, attributes ...hosting.AssetAttribute) error {
+						mu.Lock()
+						defer mu.Unlock()
+						uploads = append(uploads, path)
+						return nil
+					},
+					DeleteAssetFn: func(groupID, appID, path string) error {
+						mu.Lock()
+						defer mu.Unlock()
+						deletes = append(deletes, path)
+						return nil
+					},
+					InvalidateCacheFn: func(groupID, appID, path string) (string, error) {
+						resetPaths = append(resetPaths, path)
+						return "", nil
+					},
+				}
+
+				exitCode := uploadCommand.Run(tc.Args)
+				u.So(t, exitCode, gc.ShouldEqual, 0)
+				u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+				u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, "Uploading hosting assets...")
+
+				sort.Strings(uploads)
+				sort.Strings(deletes)
+				u.So(t, uploads, gc.ShouldResemble, tc.ExpectedUploads)
+				u.So(t, deletes, gc.ShouldResemble, tc.ExpectedDeletes)
+				u.So(t, resetPaths, gc.ShouldResemble, tc.ExpectedResetPaths)
+			})
+		}
+
+		t.Run("with a corrupt asset cache", func(t *testing.T) {
+			cachePath := filepath.Join(filepath.Dir(configPath), utils.HostingCacheFileName)
+
+			for _, tc := range []struct {
+				Description     string
+				Args            []string
+				ExpectedWarning string
+			}{
+				{
+					Description:     "it warns and rebuilds the cache",
+					Args:            append([]string{"-y"}, validArgs...),
+					ExpectedWarning: "Failed to read the hosting asset cache at " + cachePath + ", rebuilding it",
+				},
+				{
+					Description: "it rebuilds the cache without warning with --rebuild-hosting-cache",
+					Args:        append([]string{"-y", "--rebuild-hosting-cache"}, validArgs...),
+				},
+			} {
+				t.Run(tc.Description, func(t *testing.T) {
+					u.So(t, ioutil.WriteFile(cachePath, []byte(`{"my-app-abcdef": {"/asset_file0.json": `), 0600), gc.ShouldBeNil)
+
+					uploadCommand, mockUI := setup()
+					uploadCommand.user = &user.User{
+						APIKey:      "my-api-key",
+						AccessToken: u.GenerateValidAccessToken(),
+					}
+
+					var mu sync.Mutex
+					var uploads []string
+					uploadCommand.realmClient = &u.MockRealmClient{
+						FetchAppByClientAppIDFn: func(clientAppID string) (*models.App, error) {
+							return &models.App{GroupID: "group-id", ID: "app-id", ClientAppID: clientAppID}, nil
+						},
+						UploadAssetFn: func(groupID, appID, path, hash string, size int64, body io.Reader, attributes ...hosting.AssetAttribute) error {
+							mu.Lock()
+							defer mu.Unlock()
+							uploads = append(uploads, path)
+							return nil
+						},
+					}
+
+					exitCode := uploadCommand.Run(tc.Args)
+					u.So(t, exitCode, gc.ShouldEqual, 0)
+					if tc.ExpectedWarning == "" {
+						u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+					} else {
+						u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, tc.ExpectedWarning)
+					}
+
+					sort.Strings(uploads)
+					u.So(t, uploads, gc.ShouldResemble, []string{"/asset_file0.json", "/asset_file1.html", "/ships/nostromo.json"})
+
+					assetCache, err := hosting.CacheFileToAssetCache(cachePath)
+					u.So(t, err, gc.ShouldBeNil)
+					_, ok := assetCache.Get("my-app-abcdef", "/asset_file0.json")
+					u.So(t, ok, gc.ShouldBeTrue)
+				})
+			}
+		})
+
+		t.Run("it does not upload anything if the user does not confirm the changes", func(t *testing.T) {
+			uploadCommand, mockUI := setup()
+			mockUI.InputReader = strings.NewReader("n\n")
+			uploadCommand.user = &user.User{
+				APIKey:      "my-api-key",
+				AccessToken: u.GenerateValidAccessToken(),
+			}
+			uploadCommand.realmClient = &u.MockRealmClient{
+				FetchAppByClientAppIDFn: func(clientAppID string) (*models.App, error) {
+					return &models.App{GroupID: "group-id", ID: "app-id"}, nil
+				},
+				UploadAssetFn: func(groupID, appID, path, hash string, size int64, body io.Reader, attributes ...hosting.AssetAttribute) error {
+					t.Errorf("unexpected upload of %s", path)
+					return nil
+				},
+			}
+
+			exitCode := uploadCommand.Run(validArgs)
+			u.So(t, exitCode, gc.ShouldEqual, 0)
+			u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, "New Files:")
+			u.So(t, mockUI.OutputWriter.String(), gc.ShouldNotContainSubstring, "Uploading hosting assets...")
+		})
+	})
+}
+
+func TestHostingListCommand(t *testing.T) {
+	setup := func() (*HostingListCommand, *cli.MockUi) {
+		mockUI := cli.NewMockUi()
+		cmd, err := NewHostingListCommandFactory(mockUI)()
+		if err != nil {
+			panic(err)
+		}
+
+		listCommand := cmd.(*HostingListCommand)
+		listCommand.storage = u.NewEmptyStorage()
+		return listCommand, mockUI
+	}
+
+	t.Run("should require the user to be logged in", func(t *testing.T) {
+		listCommand, mockUI := setup()
+		exitCode := listCommand.Run([]string{"--app-id=my-app-abcdef"})
+		u.So(t, exitCode, gc.ShouldEqual, 1)
+
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, user.ErrNotLoggedIn.Error())
+	})
+
+	t.Run("should reject an unknown output format", func(t *testing.T) {
+		listCommand, mockUI := setup()
+		exitCode := listCommand.Run([]string{"--app-id=my-app-abcdef", "--output=yaml"})
+		u.So(t, exitCode, gc.ShouldEqual, 1)
+
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, `unknown output format "yaml"; accepted values are [text|json]`)
+	})
+
+	t.Run("when the user is logged in", func(t *testing.T) {
+		setupLoggedIn := func() (*HostingListCommand, *cli.MockUi) {
+			listCommand, mockUI := setup()
+			listCommand.user = &user.User{
+				APIKey:      "my-api-key",
+				AccessToken: u.GenerateValidAccessToken(),
+			}
+			listCommand.realmClient = &u.MockRealmClient{
+				FetchAppByClientAppIDFn: func(clientAppID string) (*models.App, error) {
+					return &models.App{GroupID: "group-id", ID: "app-id", ClientAppID: clientAppID}, nil
+				},
+				ListAssetsForAppIDFn: func(groupID, appID string) ([]hosting.AssetMetadata, error) {
+					u.So(t, groupID, gc.ShouldEqual, "group-id")
+					u.So(t, appID, gc.ShouldEqual, "app-id")
+					return []hosting.AssetMetadata{
+						{FilePath: "/"},
+						{FilePath: "/static/"},
+						{FilePath: "/static/app.js", FileSize: 2048, FileHash: "abc", LastModified: 1600000000},
+						{FilePath: "/index.html", FileSize: 512, FileHash: "def"},
+					}, nil
+				},
+			}
+			return listCommand, mockUI
+		}
+
+		t.Run("should list the deployed files by path", func(t *testing.T) {
+			listCommand, mockUI := setupLoggedIn()
+
+			exitCode := listCommand.Run([]string{"--app-id=my-app-abcdef"})
+			u.So(t, exitCode, gc.ShouldEqual, 0)
+			u.So(t, mockUI.OutputWriter.String(), gc.ShouldEqual, strings.Join([]string{
+				"PATH            SIZE  LAST MODIFIED",
+				"/index.html     512   -",
+				"/static/app.js  2048  2020-09-13T12:26:40Z",
+				"",
+			}, "\n"))
+		})
+
+		t.Run("should only list the files under the prefix", func(t *testing.T) {
+			listCommand, mockUI := setupLoggedIn()
+
+			exitCode := listCommand.Run([]string{"--app-id=my-app-abcdef", "--prefix=static/", "-o", "json"})
+			u.So(t, exitCode, gc.ShouldEqual, 0)
+			u.So(t, mockUI.OutputWriter.String(), gc.ShouldEqual, strings.Join([]string{
+				"[",
+				"  {",
+				`    "path": "/static/app.js",`,
+				`    "size": 2048,`,
+				`    "last_modified": "2020-09-13T12:26:40Z",`,
+				`    "hash": "abc"`,
+				"  }",
+				"]",
+				"",
+			}, "\n"))
+		})
+
+		t.Run("should report when no files are under the prefix", func(t *testing.T) {
+			listCommand, mockUI := setupLoggedIn()
+
+			exitCode := listCommand.Run([]string{"--app-id=my-app-abcdef", "--prefix=/images/"})
+			u.So(t, exitCode, gc.ShouldEqual, 0)
+			u.So(t, mockUI.OutputWriter.String(), gc.ShouldEqual, "No hosting assets found under /images/ for my-app-abcdef.\n")
+		})
+	})
+}
+
+func TestHostingRemoveCommand(t *testing.T) {
+	setup := func() (*HostingRemoveCommand, *cli.MockUi) {
+		mockUI := cli.NewMockUi()
+		cmd, err := NewHostingRemoveCommandFactory(mockUI)()
+		if err != nil {
+			panic(err)
+		}
+
+		removeCommand := cmd.(*HostingRemoveCommand)
+		removeCommand.storage = u.NewEmptyStorage()
+		removeCommand.cacheInvalidationPollInterval = 0
+		return removeCommand, mockUI
+	}
+
+	t.Run("should require a path", func(t *testing.T) {
+		removeCommand, mockUI := setup()
+		exitCode := removeCommand.Run([]string{"--app-id=my-app-abcdef"})
+		u.So(t, exitCode, gc.ShouldEqual, 1)
+
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, errHostingRemovePathRequired.Error())
+	})
+
+	t.Run("should require the user to be logged in", func(t *testing.T) {
+		removeCommand, mockUI := setup()
+		exitCode := removeCommand.Run([]string{"--app-id=my-app-abcdef", "/index.html"})
+		u.So(t, exitCode, gc.ShouldEqual, 1)
+
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, user.ErrNotLoggedIn.Error())
+	})
+
+	t.Run("when the user is logged in", func(t *testing.T) {
+		setupLoggedIn := func() (*HostingRemoveCommand, *u.MockRealmClient, *cli.MockUi) {
+			removeCommand, mockUI := setup()
+			removeCommand.user = &user.User{
+				APIKey:      "my-api-key",
+				AccessToken: u.GenerateValidAccessToken(),
+			}
+			realmClient := &u.MockRealmClient{
+				FetchAppByClientAppIDFn: func(clientAppID string) (*models.App, error) {
+					return &models.App{GroupID: "group-id", ID: "app-id", ClientAppID: clientAppID}, nil
+				},
+				ListAssetsForAppIDFn: func(groupID, appID string) ([]hosting.AssetMetadata, error) {
+					return []hosting.AssetMetadata{
+						{FilePath: "/"},
+						{FilePath: "/index.html"},
+						{FilePath: "/static/"},
+						{FilePath: "/static/app.js"},
+						{FilePath: "/static/app.css"},
+						{FilePath: "/static/vendor/lib.js"},
+					}, nil
+				},
+			}
+			removeCommand.realmClient = realmClient
+			return removeCommand, realmClient, mockUI
+		}
+
+		t.Run("should remove the files matched by globs and directories once confirmed", func(t *testing.T) {
+			removeCommand, realmClient, mockUI := setupLoggedIn()
+			var deleted []string
+			realmClient.DeleteAssetFn = func(groupID, appID, path string) error {
+				u.So(t, groupID, gc.ShouldEqual, "group-id")
+				u.So(t, appID, gc.ShouldEqual, "app-id")
+				deleted = append(deleted, path)
+				return nil
+			}
+			mockUI.InputReader = strings.NewReader("y\n")
+
+			exitCode := removeCommand.Run([]string{"--app-id=my-app-abcdef", "static/*.js", "/static/vendor/"})
+			u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+			u.So(t, exitCode, gc.ShouldEqual, 0)
+			u.So(t, deleted, gc.ShouldResemble, []string{"/static/app.js", "/static/vendor/lib.js"})
+			u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, "Removed Files:\n\t- /static/app.js\n\t- /static/vendor/lib.js\n")
+		})
+
+		t.Run("should not remove anything unless confirmed", func(t *testing.T) {
+			removeCommand, realmClient, mockUI := setupLoggedIn()
+			realmClient.DeleteAssetFn = func(groupID, appID, path string) error {
+				t.Errorf("unexpected removal of %s", path)
+				return nil
+			}
+			mockUI.InputReader = strings.NewReader("n\n")
+
+			exitCode := removeCommand.Run([]string{"--app-id=my-app-abcdef", "/index.html"})
+			u.So(t, exitCode, gc.ShouldEqual, 0)
+		})
+
+		t.Run("should report a path which matches no files", func(t *testing.T) {
+			removeCommand, _, mockUI := setupLoggedIn()
+
+			exitCode := removeCommand.Run([]string{"--app-id=my-app-abcdef", "/index.html", "/missing/*.png"})
+			u.So(t, exitCode, gc.ShouldEqual, 1)
+			u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, "no hosting assets found at /missing/*.png")
+		})
+
+		t.Run("should invalidate the cdn cache of the removed files", func(t *testing.T) {
+			removeCommand, realmClient, mockUI := setupLoggedIn()
+			realmClient.DeleteAssetFn = func(groupID, appID, path string) error { return nil }
+
+			var invalidated []string
+			realmClient.InvalidateCacheFn = func(groupID, appID, path string) (string, error) {
+				invalidated = append(invalidated, path)
+				return "", nil
+			}
+
+			exitCode := removeCommand.Run([]string{"--app-id=my-app-abcdef", "--yes", "--reset-cdn-cache", "/index.html", "/static/app.css"})
+			u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+			u.So(t, exitCode, gc.ShouldEqual, 0)
+			u.So(t, invalidated, gc.ShouldResemble, []string{"/index.html", "/static/app.css"})
+		})
+	})
+}
@@ -1,22 +1,28 @@
 package commands
 
 import (
+	"context"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/10gen/realm-cli/api"
+	"github.com/10gen/realm-cli/dependency/transpiler"
 	"github.com/10gen/realm-cli/hosting"
 	"github.com/10gen/realm-cli/models"
 	u "github.com/10gen/realm-cli/user"
 	"github.com/10gen/realm-cli/utils"
 
 	"github.com/mitchellh/cli"
+	"github.com/mitchellh/go-homedir"
 )
 
 const (
@@ -25,10 +31,75 @@ const (
 	importFlagAppName             = "app-name"
 	importFlagIncludeHosting      = "include-hosting"
 	importFlagResetCDNCache       = "reset-cdn-cache"
+	importFlagCachePaths          = "cache-paths"
+	importFlagCacheThreshold      = "cache-invalidation-threshold"
+	importFlagRebuildHostingCache = "rebuild-hosting-cache"
+	importFlagHostingConcurrency  = "concurrency"
+	importFlagHostingFailFast     = "fail-fast"
 	importStrategyMerge           = "merge"
 	importStrategyReplace         = "replace"
 	importStrategyReplaceByName   = "replace-by-name"
 	importFlagIncludeDependencies = "include-dependencies"
+	importFlagForceDependencies   = "force-dependencies"
+	importFlagNoTranspileDeps     = "no-transpile-dependencies"
+	importFlagNoTranspilePackages = "no-transpile-packages"
+	importFlagImportTimeout       = "import-timeout"
+	importFlagDeployTimeout       = "deploy-timeout"
+	importFlagDiffAlgorithm       = "diff-algorithm"
+	diffAlgorithmServer           = "server"
+	diffAlgorithmClient           = "client"
+	importFlagNoRenameDetection   = "no-rename-detection"
+	importFlagRenameThreshold     = "rename-threshold"
+	importFlagRetryOnConflict     = "retry-on-conflict"
+	importFlagDetailedExitCode    = "detailed-exit-code"
+	diffFlagExitCode              = "exit-code"
+	importFlagNoSyntaxCheck       = "no-syntax-check"
+	importFlagTimings             = "timings"
+	diffFlagBaseline              = "baseline"
+	importFlagCreateIfMissing     = "create-if-missing"
+	importFlagLocation            = "location"
+	importFlagDeploymentModel     = "deployment-model"
+	importFlagAsNew               = "as-new"
+	importFlagToApp               = "to-app"
+	importFlagToName              = "to-name"
+	diffFlagIgnoreField           = "ignore-field"
+	importFlagPlanFile            = "plan-file"
+	importFlagStrictConfigVersion = "strict-config-version"
+	importFlagStrict              = "strict"
+	importFlagConfigVersion       = "config-version"
+	importFlagVersionMismatch     = "allow-version-mismatch"
+	importFlagAllowDestructive    = "allow-destructive"
+	importFlagWatch               = "watch"
+	importFlagFollow              = "follow"
+	importFlagOnly                = "only"
+	importFlagRefreshProject      = "refresh-project"
+	importFlagOutput              = "output"
+	importOutputText              = "text"
+	importOutputJSON              = "json"
+	diffFlagOutput                = "output"
+	diffOutputText                = "text"
+	diffOutputJSON                = "json"
+)
+
+// exitCodeNoChanges is the exit code used with --detailed-exit-code when the deployed app
+// is identical to the local one and nothing was imported
+const exitCodeNoChanges = 2
+
+// exitCodeChanges is the exit code used by diff with --exit-code when the deployed app differs
+// from the local one, distinct from both success and the exit code of errors
+const exitCodeChanges = 3
+
+// Bounds on how long to wait for a draft created by someone else when --retry-on-conflict is set
+const (
+	draftConflictRetries       = 5
+	draftConflictRetryInterval = 5 * time.Second
+)
+
+// How often the status of a deployment is checked, and how long to wait before noting how long the
+// deployment has been going on for
+const (
+	deployPollInterval    = time.Second
+	deployWaitedThreshold = 30 * time.Second
 )
 
 // Set of location and deployment model options supported by Realm backend
@@ -37,6 +108,14 @@ var (
 	deploymentModelOptions = []string{"GLOBAL", "LOCAL"}
 )
 
+func errCreateIfMissingRequires(flagName, description, createFlagName string) error {
+	return fmt.Errorf("%s must be supplied with --%s or in the app config to create the app with --%s", description, flagName, createFlagName)
+}
+
+func errUnknownOption(description, value string, options []string) error {
+	return fmt.Errorf("unknown %s %q; accepted values are [%s]", description, value, strings.Join(options, "|"))
+}
+
 func errCreateAppSyncFailure(err error) error {
 	return fmt.Errorf("failed to sync app with local directory after creation: %s", err)
 }
@@ -49,6 +128,42 @@ func errIncludeHosting(err error) error {
 	return fmt.Errorf("--include-hosting error: %s", err)
 }
 
+func errDestructiveSchemaChanges(count int) error {
+	return fmt.Errorf("the import makes %d destructive schema change(s) which may delete data, import anyway with --%s", count, importFlagAllowDestructive)
+}
+
+func errDraftConflict(attempts int) error {
+	return fmt.Errorf("another draft still exists after %d attempts and was left in place", attempts)
+}
+
+func errImportTimeout(timeout time.Duration) error {
+	return fmt.Errorf("failed to import app: import phase timed out after %s (--%s)", timeout, importFlagImportTimeout)
+}
+
+func errStrictUnknownKeys(problems []utils.AppProblem) error {
+	lines := make([]string, 0, len(problems))
+	for _, problem := range problems {
+		lines = append(lines, problem.String())
+	}
+	return fmt.Errorf("found keys which realm-cli does not recognize, which --%s rejects:\n\t%s", importFlagStrict, strings.Join(lines, "\n\t"))
+}
+
+func errAppEnvironments(problems []utils.AppProblem) error {
+	lines := make([]string, 0, len(problems))
+	for _, problem := range problems {
+		lines = append(lines, problem.String())
+	}
+	return fmt.Errorf("failed to resolve the values of the environments:\n\t%s", strings.Join(lines, "\n\t"))
+}
+
+func errDeployTimeout(timeout time.Duration) error {
+	return fmt.Errorf(
+		`stopped waiting for the deployment after %s (--%s), it may still finish: run "realm-cli deployments list" to check its status`,
+		timeout,
+		importFlagDeployTimeout,
+	)
+}
+
 // NewImportCommandFactory returns a new cli.CommandFactory given a cli.Ui
 func NewImportCommandFactory(ui cli.Ui) cli.CommandFactory {
 	return func() (cli.Command, error) {
@@ -62,11 +177,17 @@ func NewImportCommandFactory(ui cli.Ui) cli.CommandFactory {
 				Name: "import",
 				UI:   ui,
 			},
-			workingDirectory: workingDirectory,
-			writeToDirectory: utils.WriteZipToDir,
+			workingDirectory:   workingDirectory,
+			draftRetryInterval: draftConflictRetryInterval,
+			deployPollInterval: deployPollInterval,
+			followInterval:     logsPollInterval,
+			syntaxChecker:      transpiler.NewExternalTranspiler(transpiler.DefaultTranspilerCommand),
+			writeToDirectory:   utils.WriteZipToDir,
 			writeAppConfigToFile: func(dest string, app models.AppInstanceData) error {
 				return app.MarshalFile(dest)
 			},
+
+			cacheInvalidationPollInterval: cacheInvalidationPollInterval,
 		}, nil
 	}
 }
@@ -78,6 +199,14 @@ type ImportCommand struct {
 	writeToDirectory     func(dest string, zipData io.Reader, overwrite bool) error
 	writeAppConfigToFile func(dest string, app models.AppInstanceData) error
 	workingDirectory     string
+	draftRetryInterval   time.Duration
+	deployPollInterval   time.Duration
+	followInterval       time.Duration
+	syntaxChecker        transpiler.Transpiler
+	diffCache            appDiffCache
+	timings              importTimings
+
+	cacheInvalidationPollInterval time.Duration
 
 	flagAppID               string
 	flagAppPath             string
@@ -86,13 +215,113 @@ type ImportCommand struct {
 	flagStrategy            string
 	flagIncludeHosting      bool
 	flagResetCDNCache       bool
+	flagCachePaths          string
+	flagCacheThreshold      int
+	flagRebuildHostingCache bool
+	flagHostingConcurrency  int
+	flagHostingFailFast     bool
 	flagIncludeDependencies bool
+	flagForceDependencies   bool
+	flagNoTranspileDeps     bool
+	flagNoTranspilePackages string
+	flagImportTimeout       time.Duration
+	flagDeployTimeout       time.Duration
+	flagDiffAlgorithm       string
+	flagDiffOutput          string
+	flagNoRenameDetection   bool
+	flagRenameThreshold     float64
+	flagRetryOnConflict     bool
+	flagDetailedExitCode    bool
+	flagNoSyntaxCheck       bool
+	flagTimings             bool
+	flagBaseline            string
+	flagCreateIfMissing     bool
+	flagLocation            string
+	flagDeploymentModel     string
+	flagAsNew               bool
+	flagToApp               string
+	flagToName              string
+	flagOutput              string
+	flagIgnoreFields        []string
+	flagPlanFile            string
+	flagStrictConfigVersion bool
+	flagStrict              bool
+	flagConfigVersion       string
+	flagAllowMismatch       bool
+	flagAllowDestructive    bool
+	flagWatch               bool
+	flagFollow              bool
+	flagOnly                string
+	flagRefreshProject      bool
+
+	// ignoredFields are the --ignore-field patterns along with those listed in the app's .diffignore file
+	ignoredFields []string
+
+	// threeWayDiffs is set once the app is diffed against the --baseline
+	threeWayDiffs *utils.AppThreeWayDiffs
+
+	// plan is the plan being applied, if any
+	plan *importPlan
+
+	// appArchivePath is the zipped app given by --path, if any, which a plan refers to instead of the
+	// temporary directory it is extracted into
+	appArchivePath string
+
+	// watching is set once the app is redeployed on changes with --watch
+	watching bool
+
+	// noChanges is set once the app is found to be identical to the deployed version
+	noChanges bool
+
+	// deployedApp and deployedAt are set once a draft of the app is deployed
+	deployedApp *models.App
+	deployedAt  time.Time
+
+	// importedApp is the app imported to once the import succeeded, or which was found to be identical,
+	// and createdApp is set if it was created
+	importedApp *models.App
+	createdApp  bool
+
+	// stopFollowing ends following the logs with --follow, which otherwise goes on until interrupted
+	stopFollowing chan struct{}
+}
+
+// importResult is the result of the import printed with --output=json
+type importResult struct {
+	GroupID     string `json:"group_id"`
+	AppID       string `json:"app_id"`
+	ClientAppID string `json:"client_app_id"`
+	Created     bool   `json:"created"`
+	// Changed is false when the app was already identical to the deployed version
+	Changed bool          `json:"changed"`
+	Timings []phaseTiming `json:"timings,omitempty"`
+}
+
+// errorWriterUi writes the information and output of the wrapped Ui to its error writer instead, leaving
+// the output writer to a result which can be read by a script
+type errorWriterUi struct {
+	cli.Ui
+}
+
+func (ui errorWriterUi) Info(message string) {
+	ui.Ui.Warn(message)
+}
+
+func (ui errorWriterUi) Output(message string) {
+	ui.Ui.Warn(message)
 }
 
 // Help returns long-form help information for this command
 func (ic *ImportCommand) Help() string {
 	return `Import and deploy a realm application from a local directory.
 
+Files matching the patterns listed in a .realmignore file at the root of the app directory,
+in the .gitignore syntax, are left out of the app and its hosting assets.
+
+The values of the environments in the "environments" directory may reference environment variables
+as ${NAME}, which are replaced with their values from the shell when importing, e.g. to inject secrets.
+The import fails before anything is imported if a variable is not set. Write $${ for a literal ${.
+
 REQUIRED:
   --app-id [string]
 	The App ID for your app (i.e. the name of your app followed by a unique suffix, like "my-app-nysja").
@@ -102,10 +331,38 @@ REQUIRED:
 
 OPTIONS:
   --path [string]
-	A path to the local directory containing your app.
+	A path to the local directory containing your app, or to a zipped app such as one written by "export --format=zip".
+	The config of a zipped app is not updated, e.g. with the App ID of an app made with --create-if-missing.
 
   --project-id [string]
-	The Atlas Project ID.
+	The Atlas Project ID. Without it, the app is looked up in all of your projects, and if several apps
+	match you are asked to choose one, or the import fails listing them when run with --yes.
+
+  --to-app [string]
+	The app to import to, given by either its App ID (e.g. "my-app-nysja") or its internal ObjectID, instead
+	of --app-id or the App ID in the app config. Fails if the app does not exist rather than creating it.
+
+  --to-name [string]
+	The name of the app to import to, in the project given by --project-id or chosen as when creating an app.
+	The app is created if no app has the name yet, without prompting when run with --yes, taking the location
+	and deployment model from the flags below or else from the app config, so that running the same import
+	again updates the app instead. Cannot be used with --app-id, --to-app, --as-new or --app-name.
+
+  --create-if-missing
+	Create the app without prompting if it does not exist yet. Requires --project-id, and an app name,
+	location and deployment model, either from the flags below or from the app config.
+	Fails instead of prompting if any of them is missing.
+
+  --as-new
+	Create a new app from the local one even if it already exists, e.g. to clone an app. The app name, location
+	and deployment model are taken from the flags, or else prompted for, defaulting to the ones in the app config.
+	The app config is left pointing at the existing app.
+
+  --location [US-VA|US-OR|IE|AU]
+	The location of the app to create with --create-if-missing or --as-new.
+
+  --deployment-model [GLOBAL|LOCAL]
+	The deployment model of the app to create with --create-if-missing or --as-new.
 
   --strategy [merge|replace|replace-by-name] (default: merge, recommended: replace-by-name)
 	How your app should be imported.
@@ -119,10 +376,131 @@ OPTIONS:
   --reset-cdn-cache
 	Invalidate cdn cache for modified files.
 
+  --cache-paths [string]
+	A comma separated list of paths to invalidate in the cdn cache instead of the modified files, like "/index.html,/static/*".
+	Implies --reset-cdn-cache.
+
+  --cache-invalidation-threshold [int] (default: 20)
+	How many modified files to invalidate one by one with --reset-cdn-cache before the whole cdn cache is invalidated instead.
+
+  --rebuild-hosting-cache
+	Discard the local hosting asset cache and hash every asset in "/hosting" again.
+
+  --concurrency [int] (default: 4)
+	How many hosting assets to upload, modify or remove at once with --include-hosting.
+
+  --fail-fast
+	Stop uploading, modifying or removing hosting assets with --include-hosting once one of them fails.
 
   --include-dependencies
 	Upload the node_modules archive within the "/functions" directory.
 	The supported formats are: TAR, GZIP, and ZIP
+	The upload is skipped if neither the archive nor "/functions/package.json" changed since the last import.
+
+  --force-dependencies
+	Upload the dependencies with --include-dependencies even if they have not changed since the last import.
+
+  --no-transpile-dependencies
+	Upload the files of the node_modules archive as they are with --include-dependencies, rather than transpiling them.
+
+  --no-transpile-packages [string]
+	A comma-separated list of packages whose files are uploaded as they are with --include-dependencies, rather
+	than transpiled, e.g. "lodash,@aws-sdk/*". Patterns are matched against the package names as with shell globs.
+
+  --import-timeout [duration]
+	How long to wait for the app configuration to be imported into the draft before giving up, e.g. "90s" or "5m".
+	The draft is discarded if the import phase times out. Defaults to no timeout.
+
+  --deploy-timeout [duration]
+	How long to wait for the draft to be deployed before giving up, e.g. "90s" or "10m".
+	The deployment may still finish after realm-cli stops waiting for it. Defaults to no timeout.
+
+  --diff-algorithm [server|client] (default: server)
+	How the changes to your app are computed before they are confirmed.
+	server - ask Realm for a dry-run import. Cheap to transfer, but can be slow to compute for large apps.
+	client - export the deployed app and compare it against the local directory resource by resource.
+	Avoids the server-side dry-run at the cost of downloading the full app configuration.
+	Changes to the Sync configuration of the app and its services are also listed field by field,
+	along with how they may disrupt the clients syncing with the app.
+
+  --no-rename-detection
+	With --diff-algorithm=client, show a renamed resource as one resource removed and another added,
+	instead of pairing up removed and added resources with similar contents as a single rename.
+
+  --rename-threshold [float] (default: 0.5)
+	With --diff-algorithm=client, how similar a removed and an added resource must be, from just above 0
+	to 1 for identical, to be shown as a rename. Functions are compared by their source, line by line.
+	A rename whose contents changed as well is marked as modified.
+
+  --allow-version-mismatch
+	With --strict-config-version, import the app even if its "config_version" differs from the version
+	of the deployed app, warning about it instead of failing.
+
+  --allow-destructive
+	Import the app even if it removes the schema of a collection, removes a property from a schema or
+	changes the type of a property, which may delete data. Such changes are marked with "!!" in the diff
+	and are refused without this flag, even with --yes.
+
+  --strict-config-version
+	Fail unless the app declares its "config_version", as well as when it differs from the version the
+	deployed app is deployed with, e.g. to migrate the app with --config-version first. The version is
+	not checked otherwise, nor when the app is created.
+
+  --config-version [20180301|20200603|20210101]
+	Import the app as the given config version instead of the one it declares, e.g. for a Realm deployment
+	which expects an older one. Fails if the app uses anything the version cannot express, such as GraphQL
+	with 20180301. Skips the checks of --strict-config-version and --allow-version-mismatch.
+
+  --strict
+	Fail if a function, incoming webhook, service or trigger sets a key which realm-cli does not recognize,
+	e.g. a misspelled option that Realm would otherwise silently drop, naming the file and key.
+	The config of a service is not checked, since its keys depend on the type of the service.
+
+  --plan-file [string]
+	Instead of importing, save the changes along with the steps to make them to a plan file, e.g. to have them
+	approved before they are applied with "realm-cli apply --plan". Cannot be used with --yes.
+
+  --watch
+	Once the app is imported, keep watching its directory and import it again whenever its files change,
+	until interrupted with Ctrl-C. Redeploys are not confirmed, and are skipped when nothing changed.
+	The "/hosting" directory and the dependencies are only watched with --include-hosting and --include-dependencies.
+	The directory is checked for changes every second rather than through file system notifications, so that it
+	can be watched on network and container mounts, and the app is redeployed once it stayed unchanged for 500ms.
+
+  --follow
+	Once the app is deployed, print the function and trigger logs of the app as they come in,
+	starting from the deployment, until interrupted with Ctrl-C. Cannot be used with --watch or --plan-file.
+
+  --only [string]
+	A comma-separated list of the groups of entities to import, e.g. "functions,triggers", leaving the others
+	as they are deployed whatever the strategy. The changes shown before importing only cover these groups.
+	Accepted groups are auth_providers, functions, graphql, services, triggers and values.
+
+  --refresh-project
+	When asked to create the app, choose its Atlas Project again instead of reusing the one chosen the last time.
+	The chosen project is only reused while logged in as the same account, and is forgotten on logout.
+
+  --retry-on-conflict
+	If a draft already exists for your app, e.g. one created by a concurrent import, wait for it to be
+	deployed or discarded and retry creating a draft, giving up after a few attempts.
+	The existing draft is never discarded, even when -y is set.
+
+  --detailed-exit-code
+	Exit with code 2 instead of 0 when the deployed app is already identical to the local one
+	and nothing was imported. Errors still exit with code 1. With -y, the app is diffed to tell, and the
+	changes are shown before they are imported without confirmation.
+
+  --no-syntax-check
+	Skip checking that the source of each function and incoming webhook is valid JavaScript before importing.
+	The check uses the "transpiler" installed alongside realm-cli, and is skipped if it is not available.
+
+  --timings
+	Print how long each phase of the import took (diff, draft, import, deploy, hosting, dependencies) once it finishes.
+
+  --output, -o [text|json] (default: text)
+	With json, print the Project ID, ObjectID and App ID of the imported app, whether it was created and
+	whether it changed, as the only output once the import succeeds, e.g. to use a new app in the next steps
+	of a pipeline. The timings of --timings are part of the result. Everything else is written to stderr. Cannot be used with --watch or --follow.
 	` +
 		ic.BaseCommand.Help()
 }
@@ -143,7 +521,42 @@ func (ic *ImportCommand) Run(args []string) int {
 	flags.StringVar(&ic.flagStrategy, importFlagStrategy, importStrategyMerge, "")
 	flags.BoolVar(&ic.flagIncludeHosting, importFlagIncludeHosting, false, "")
 	flags.BoolVar(&ic.flagResetCDNCache, importFlagResetCDNCache, false, "")
+	flags.StringVar(&ic.flagCachePaths, importFlagCachePaths, "", "")
+	flags.IntVar(&ic.flagCacheThreshold, importFlagCacheThreshold, defaultCacheInvalidationThreshold, "")
+	flags.BoolVar(&ic.flagRebuildHostingCache, importFlagRebuildHostingCache, false, "")
+	flags.IntVar(&ic.flagHostingConcurrency, importFlagHostingConcurrency, numWorkers, "")
+	flags.BoolVar(&ic.flagHostingFailFast, importFlagHostingFailFast, false, "")
 	flags.BoolVar(&ic.flagIncludeDependencies, importFlagIncludeDependencies, false, "")
+	flags.BoolVar(&ic.flagForceDependencies, importFlagForceDependencies, false, "")
+	flags.BoolVar(&ic.flagNoTranspileDeps, importFlagNoTranspileDeps, false, "")
+	flags.StringVar(&ic.flagNoTranspilePackages, importFlagNoTranspilePackages, "", "")
+	flags.DurationVar(&ic.flagImportTimeout, importFlagImportTimeout, 0, "")
+	flags.DurationVar(&ic.flagDeployTimeout, importFlagDeployTimeout, 0, "")
+	flags.StringVar(&ic.flagDiffAlgorithm, importFlagDiffAlgorithm, diffAlgorithmServer, "")
+	flags.BoolVar(&ic.flagNoRenameDetection, importFlagNoRenameDetection, false, "")
+	flags.Float64Var(&ic.flagRenameThreshold, importFlagRenameThreshold, utils.DefaultRenameThreshold, "")
+	flags.BoolVar(&ic.flagRetryOnConflict, importFlagRetryOnConflict, false, "")
+	flags.BoolVar(&ic.flagDetailedExitCode, importFlagDetailedExitCode, false, "")
+	flags.BoolVar(&ic.flagNoSyntaxCheck, importFlagNoSyntaxCheck, false, "")
+	flags.BoolVar(&ic.flagTimings, importFlagTimings, false, "")
+	flags.BoolVar(&ic.flagCreateIfMissing, importFlagCreateIfMissing, false, "")
+	flags.StringVar(&ic.flagLocation, importFlagLocation, "", "")
+	flags.StringVar(&ic.flagDeploymentModel, importFlagDeploymentModel, "", "")
+	flags.BoolVar(&ic.flagAsNew, importFlagAsNew, false, "")
+	flags.StringVar(&ic.flagToApp, importFlagToApp, "", "")
+	flags.StringVar(&ic.flagToName, importFlagToName, "", "")
+	flags.StringVar(&ic.flagOutput, importFlagOutput, importOutputText, "")
+	flags.StringVar(&ic.flagOutput, "o", importOutputText, "")
+	flags.StringVar(&ic.flagPlanFile, importFlagPlanFile, "", "")
+	flags.BoolVar(&ic.flagStrictConfigVersion, importFlagStrictConfigVersion, false, "")
+	flags.BoolVar(&ic.flagStrict, importFlagStrict, false, "")
+	flags.StringVar(&ic.flagConfigVersion, importFlagConfigVersion, "", "")
+	flags.BoolVar(&ic.flagAllowMismatch, importFlagVersionMismatch, false, "")
+	flags.BoolVar(&ic.flagAllowDestructive, importFlagAllowDestructive, false, "")
+	flags.BoolVar(&ic.flagWatch, importFlagWatch, false, "")
+	flags.BoolVar(&ic.flagFollow, importFlagFollow, false, "")
+	flags.StringVar(&ic.flagOnly, importFlagOnly, "", "")
+	flags.BoolVar(&ic.flagRefreshProject, importFlagRefreshProject, false, "")
 
 	if err := ic.BaseCommand.run(args); err != nil {
 		ic.UI.Error(err.Error())
@@ -157,16 +570,200 @@ func (ic *ImportCommand) Run(args []string) int {
 		return 1
 	}
 
+	if ic.flagHostingConcurrency < 1 {
+		ic.UI.Error(fmt.Sprintf("--%s must be at least 1", importFlagHostingConcurrency))
+		return 1
+	}
+
+	if ic.flagNoTranspileDeps && !ic.flagIncludeDependencies {
+		ic.UI.Error(fmt.Sprintf("--%s requires --%s", importFlagNoTranspileDeps, importFlagIncludeDependencies))
+		return 1
+	}
+
+	if ic.flagNoTranspilePackages != "" && !ic.flagIncludeDependencies {
+		ic.UI.Error(fmt.Sprintf("--%s requires --%s", importFlagNoTranspilePackages, importFlagIncludeDependencies))
+		return 1
+	}
+
+	if ic.flagPlanFile != "" && ic.flagYes {
+		ic.UI.Error(fmt.Sprintf("--%s cannot be used with --yes, since the plan is made from the changes to confirm", importFlagPlanFile))
+		return 1
+	}
+
+	if ic.flagWatch && ic.flagPlanFile != "" {
+		ic.UI.Error(fmt.Sprintf("--%s cannot be used with --%s", importFlagWatch, importFlagPlanFile))
+		return 1
+	}
+
+	if ic.flagFollow && (ic.flagWatch || ic.flagPlanFile != "") {
+		ic.UI.Error(fmt.Sprintf("--%s cannot be used with --%s or --%s", importFlagFollow, importFlagWatch, importFlagPlanFile))
+		return 1
+	}
+
+	if ic.flagAsNew && (ic.flagWatch || ic.flagPlanFile != "") {
+		ic.UI.Error(fmt.Sprintf("--%s cannot be used with --%s or --%s, since every import would create another app", importFlagAsNew, importFlagWatch, importFlagPlanFile))
+		return 1
+	}
+
+	switch ic.flagOutput {
+	case importOutputText, importOutputJSON:
+	default:
+		ic.UI.Error(fmt.Sprintf("unknown output format %q; accepted values are [%s|%s]", ic.flagOutput, importOutputText, importOutputJSON))
+		return 1
+	}
+
+	if ic.flagOutput == importOutputJSON && (ic.flagWatch || ic.flagFollow) {
+		ic.UI.Error(fmt.Sprintf("--%s=%s cannot be used with --%s or --%s", importFlagOutput, importOutputJSON, importFlagWatch, importFlagFollow))
+		return 1
+	}
+
+	if ic.flagToApp != "" && (ic.flagAppID != "" || ic.flagAsNew) {
+		ic.UI.Error(fmt.Sprintf("--%s cannot be used with --%s or --%s", importFlagToApp, flagAppIDName, importFlagAsNew))
+		return 1
+	}
+
+	if ic.flagToName != "" {
+		if ic.flagAppID != "" || ic.flagToApp != "" || ic.flagAsNew || ic.flagAppName != "" {
+			ic.UI.Error(fmt.Sprintf("--%s cannot be used with --%s, --%s, --%s or --%s", importFlagToName, flagAppIDName, importFlagToApp, importFlagAsNew, importFlagAppName))
+			return 1
+		}
+		// an app made with --to-name is named after it
+		ic.flagAppName = ic.flagToName
+	}
+
+	if isAppArchive(ic.flagAppPath) {
+		if ic.flagWatch {
+			ic.UI.Error(fmt.Sprintf("--%s cannot be used with a zipped app", importFlagWatch))
+			return 1
+		}
+
+		appPath, cleanup, err := extractAppArchive(ic.flagAppPath)
+		if err != nil {
+			ic.UI.Error(err.Error())
+			return 1
+		}
+		defer cleanup()
+		ic.appArchivePath, ic.flagAppPath = ic.flagAppPath, appPath
+	}
+
+	// the result is the only output, so that it can be read by a script
+	resultUI := ic.UI
+	if ic.flagOutput == importOutputJSON {
+		ic.UI = errorWriterUi{ic.UI}
+	}
+
+	ic.timings = importTimings{}
 	dryRun := false
-	if err := ic.importApp(dryRun); err != nil {
+	err := ic.importApp(dryRun)
+	if ic.flagTimings && (ic.flagOutput == importOutputText || err != nil) {
+		ic.timings.print(ic.UI)
+	}
+	if err != nil {
 		ic.UI.Error(err.Error())
 		return 1
 	}
 
+	if ic.flagOutput == importOutputJSON && ic.importedApp != nil {
+		result := importResult{
+			GroupID:     ic.importedApp.GroupID,
+			AppID:       ic.importedApp.ID,
+			ClientAppID: ic.importedApp.ClientAppID,
+			Created:     ic.createdApp,
+			Changed:     !ic.noChanges,
+		}
+		if ic.flagTimings {
+			result.Timings = ic.timings.phases
+		}
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			ic.UI.Error(err.Error())
+			return 1
+		}
+		resultUI.Output(string(data))
+	}
+
+	if ic.flagWatch {
+		if err := ic.watch(); err != nil {
+			ic.UI.Error(err.Error())
+			return 1
+		}
+		return 0
+	}
+
+	if ic.flagFollow && ic.deployedApp != nil {
+		if err := ic.follow(); err != nil {
+			ic.UI.Error(err.Error())
+			return 1
+		}
+		return 0
+	}
+
+	if ic.noChanges && ic.flagDetailedExitCode {
+		return exitCodeNoChanges
+	}
+
 	return 0
 }
 
+// follow prints the function and trigger logs of the deployed app which started after its deployment,
+// until interrupted
+func (ic *ImportCommand) follow() error {
+	realmClient, err := ic.RealmClient()
+	if err != nil {
+		return err
+	}
+
+	var stop <-chan struct{} = ic.stopFollowing
+	if stop == nil {
+		var release func()
+		stop, release = stopOnInterrupt()
+		defer release()
+	}
+
+	options := api.LogsOptions{
+		Types: append(append([]string{}, logTypes["function"]...), logTypes["trigger"]...),
+		Start: ic.deployedAt,
+	}
+
+	ic.UI.Info("Following function and trigger logs, press Ctrl-C to stop...")
+	return followLogs(ic.UI, realmClient, ic.deployedApp, options, ic.followInterval, stop)
+}
+
 func (ic *ImportCommand) importApp(dryRun bool) error {
+	switch ic.flagDiffAlgorithm {
+	case diffAlgorithmServer, diffAlgorithmClient:
+	default:
+		return fmt.Errorf("unknown diff algorithm %q; accepted values are [%s|%s]", ic.flagDiffAlgorithm, diffAlgorithmServer, diffAlgorithmClient)
+	}
+
+	if ic.flagRenameThreshold <= 0 || ic.flagRenameThreshold > 1 {
+		return fmt.Errorf("--%s must be greater than 0 and at most 1", importFlagRenameThreshold)
+	}
+
+	cachePaths, err := cdnCachePaths(ic.flagCachePaths)
+	if err != nil {
+		return err
+	}
+
+	if ic.flagCacheThreshold < 0 {
+		return fmt.Errorf("--%s must not be negative", importFlagCacheThreshold)
+	}
+
+	transpiling, err := newDependencyTranspiling(ic.flagNoTranspileDeps, ic.flagNoTranspilePackages)
+	if err != nil {
+		return err
+	}
+
+	var onlyGroups []string
+	if ic.flagOnly != "" {
+		onlyGroups = strings.Split(ic.flagOnly, ",")
+		for _, group := range onlyGroups {
+			if !utils.IsAppGroup(group) {
+				return errUnknownOption("entity group", group, utils.AppGroups)
+			}
+		}
+	}
+
 	user, err := ic.User()
 	if err != nil {
 		return err
@@ -176,6 +773,12 @@ func (ic *ImportCommand) importApp(dryRun bool) error {
 		return u.ErrNotLoggedIn
 	}
 
+	if ic.flagToApp != "" {
+		if err := ic.resolveToApp(); err != nil {
+			return err
+		}
+	}
+
 	appPath, err := utils.ResolveAppDirectory(ic.flagAppPath, ic.workingDirectory)
 	if err != nil {
 		return err
@@ -191,6 +794,42 @@ func (ic *ImportCommand) importApp(dryRun bool) error {
 		return err
 	}
 
+	if ic.flagConfigVersion != "" {
+		if loadedApp, err = convertConfigVersion(loadedApp, ic.flagConfigVersion); err != nil {
+			return err
+		}
+	} else if _, ok := appConfigVersion(loadedApp); !ok && ic.flagStrictConfigVersion {
+		// fail before contacting Realm, the version is compared once the deployed app is found
+		return fmt.Errorf("the app does not declare its %s, which is required with --%s", appConfigVersionField, importFlagStrictConfigVersion)
+	}
+
+	if ic.flagStrict {
+		problems, err := utils.FindUnknownKeys(appPath)
+		if err != nil {
+			return err
+		}
+		if len(problems) > 0 {
+			return errStrictUnknownKeys(problems)
+		}
+	}
+
+	ignoredFields, err := utils.ReadDiffIgnoreFile(appPath)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %s", utils.DiffIgnoreFileName, err)
+	}
+	ic.ignoredFields = append(append([]string(nil), ic.flagIgnoreFields...), ignoredFields...)
+
+	if ic.syntaxChecker != nil && !ic.flagNoSyntaxCheck {
+		if err := checkFunctionSyntax(ic.syntaxChecker, loadedApp, ic.UI); err != nil {
+			return err
+		}
+	}
+
+	expandedEnvironments, problems := utils.ExpandAppEnvironments(loadedApp, os.LookupEnv)
+	if len(problems) > 0 {
+		return errAppEnvironments(problems)
+	}
+
 	appData, err := json.Marshal(loadedApp)
 	if err != nil {
 		return err
@@ -201,9 +840,19 @@ func (ic *ImportCommand) importApp(dryRun bool) error {
 		return err
 	}
 
-	app, err := ic.fetchAppByClientAppID(appInstanceData.AppID())
+	var app *models.App
 	var appNotFound bool
-	if err != nil {
+	if ic.flagAsNew {
+		appNotFound = true
+		err = fmt.Errorf("importing with --%s", importFlagAsNew)
+	} else if ic.flagToName != "" {
+		if app, err = ic.findAppByName(ic.flagToName); err != nil {
+			return err
+		}
+		if appNotFound = app == nil; appNotFound {
+			err = fmt.Errorf("no app named %q exists in project %s", ic.flagToName, ic.flagGroupID)
+		}
+	} else if app, err = ic.fetchAppByClientAppID(appInstanceData.AppID()); err != nil {
 		switch err.(type) {
 		case api.ErrAppNotFound:
 			appNotFound = true
@@ -223,88 +872,171 @@ func (ic *ImportCommand) importApp(dryRun bool) error {
 			return nil
 		}
 
+		if ic.flagPlanFile != "" || ic.plan != nil {
+			return fmt.Errorf("%s. Imports can only be planned for existing apps", err.Error())
+		}
+
 		skipDiff = true
 		ic.flagStrategy = importStrategyReplace
 
-		var wantedNewApp bool
-		app, wantedNewApp, err = ic.askCreateEmptyApp(err.Error(), appInstanceData.AppName(), appInstanceData.AppLocation(), appInstanceData.AppDeploymentModel(), realmClient)
+		wantedNewApp := true
+		if ic.flagCreateIfMissing || (ic.flagToName != "" && ic.flagYes) {
+			// unlike the prompts, do not fall back to the default location and deployment model
+			location, _ := appInstanceData[models.AppLocationField].(string)
+			deploymentModel, _ := appInstanceData[models.AppDeploymentModelField].(string)
+			app, err = ic.createEmptyApp(appInstanceData.AppName(), location, deploymentModel, realmClient)
+		} else {
+			app, wantedNewApp, err = ic.askCreateEmptyApp(err.Error(), appInstanceData.AppName(), appInstanceData.AppLocation(), appInstanceData.AppDeploymentModel(), realmClient)
+		}
 		if err != nil {
 			return err
 		}
 		if !wantedNewApp {
 			return nil
 		}
+		ic.createdApp = true
+
+		// the app config of an app imported --as-new keeps pointing at the app it was cloned from
+		if !ic.flagAsNew {
+			appInstanceData[models.AppIDField] = app.ClientAppID
+			appInstanceData[models.AppNameField] = app.Name
+
+			if writeErr := ic.writeAppConfigToFile(appPath, appInstanceData); writeErr != nil {
+				return errCreateAppSyncFailure(writeErr)
+			}
+		}
+	}
+
+	if ic.flagStrictConfigVersion && ic.flagConfigVersion == "" && !appNotFound {
+		deployedVersion, err := deployedConfigVersion(realmClient, app)
+		if err != nil {
+			return err
+		}
+		if err := checkConfigVersion(loadedApp, deployedVersion, ic.flagAllowMismatch, ic.UI); err != nil {
+			return err
+		}
+	}
 
-		appInstanceData[models.AppIDField] = app.ClientAppID
-		appInstanceData[models.AppNameField] = app.Name
+	if len(onlyGroups) > 0 {
+		var deployedApp map[string]interface{}
+		if !appNotFound {
+			if deployedApp, err = exportDeployedApp(realmClient, app); err != nil {
+				return fmt.Errorf("failed to export the deployed app to import only %s into: %s", ic.flagOnly, err)
+			}
+		}
 
-		if writeErr := ic.writeAppConfigToFile(appPath, appInstanceData); writeErr != nil {
-			return errCreateAppSyncFailure(writeErr)
+		loadedApp = utils.SelectAppGroups(loadedApp, deployedApp, onlyGroups)
+		if appData, err = json.Marshal(loadedApp); err != nil {
+			return err
 		}
 	}
 
+	// the hosting assets are diffed while the app is, as both wait on the API
 	var assetMetadataDiffs *hosting.AssetMetadataDiffs
+	var hostingErr error
+	var hostingWG sync.WaitGroup
+	defer hostingWG.Wait()
 	rootDir, dirErr := filepath.Abs(filepath.Join(appPath, utils.HostingFilesDirectory))
 	if dirErr != nil {
 		return dirErr
 	}
 	if ic.flagIncludeHosting {
-		assetDescs, fileErr := hosting.MetadataFileToAssetDescriptions(filepath.Join(appPath, utils.HostingAttributes))
-		if fileErr != nil {
-			return errIncludeHosting(fmt.Errorf("error loading metadata.json file: %v", fileErr))
+		hostingWG.Add(1)
+		go func() {
+			defer hostingWG.Done()
+			done := ic.timings.start(importPhaseHosting)
+			assetMetadataDiffs, hostingErr = diffHostingAssets(rootDir, appPath, appInstanceData.AppID(), ic.flagConfigPath, app, ic.flagStrategy == importStrategyMerge, ic.flagRebuildHostingCache, realmClient, ic.UI)
+			done()
+		}()
+	}
+	waitForHosting := func() error {
+		hostingWG.Wait()
+		if hostingErr != nil {
+			return errIncludeHosting(hostingErr)
 		}
+		return nil
+	}
 
-		cachePath, cPErr := getAssetCachePath(ic.flagConfigPath)
-		if cPErr != nil {
-			return cPErr
+	functionsDir, dirErr := filepath.Abs(filepath.Join(appPath, utils.FunctionsRoot))
+	if dirErr != nil {
+		return dirErr
+	}
+	var dependenciesHash string
+	uploadDependencies := ic.flagIncludeDependencies
+	if ic.flagIncludeDependencies {
+		var dependenciesChangedErr error
+		dependenciesHash, uploadDependencies, dependenciesChangedErr = dependenciesChanged(functionsDir, ic.flagConfigPath, app.ID, transpiling)
+		if dependenciesChangedErr != nil {
+			return dependenciesChangedErr
 		}
+		uploadDependencies = uploadDependencies || ic.flagForceDependencies
+	}
 
-		assetCache, cErr := hosting.CacheFileToAssetCache(cachePath)
-		if cErr != nil {
-			if !os.IsNotExist(cErr) {
-				return cErr
+	// Diff changes unless -y flag has been provided or if this is a new app.
+	// A dry run only shows the diff, so it is computed regardless of -y. A plan is always
+	// checked against the changes it was made from, a redeploy with --watch is skipped
+	// if nothing changed, and --detailed-exit-code tells whether anything did
+	diffed := (!ic.flagYes || dryRun || ic.plan != nil || ic.watching || ic.flagDetailedExitCode) && !skipDiff
+	if diffed {
+		done := ic.timings.start(importPhaseDiff)
+		diffs, appDiffs, diffErr := ic.diffApp(realmClient, app, loadedApp, appData)
+		done()
+		hostingDiffErr := waitForHosting()
+		if diffErr != nil {
+			if hostingDiffErr != nil {
+				return fmt.Errorf("failed to diff app with currently deployed instance: %s; %s", diffErr, hostingDiffErr)
 			}
-			assetCache = hosting.NewAssetCache()
+			return fmt.Errorf("failed to diff app with currently deployed instance: %s", diffErr)
 		}
-
-		localAssetMetadata, aMErr :=
-			hosting.ListLocalAssetMetadata(appInstanceData.AppID(), rootDir, assetDescs, assetCache)
-
-		if aMErr != nil {
-			return errIncludeHosting(fmt.Errorf("error processing local assets %s: %s", rootDir, aMErr))
+		if hostingDiffErr != nil {
+			return hostingDiffErr
 		}
 
-		if assetCache.Dirty() {
-			if uError := hosting.UpdateCacheFile(cachePath, assetCache); uError != nil {
-				ic.UI.Error(uError.Error())
-			}
+		schemaChanges, schemaErr := ic.destructiveSchemaChanges(realmClient, app, loadedApp, appDiffs)
+		if schemaErr != nil {
+			return schemaErr
 		}
-
-		remoteAssetMetadata, rAMErr := realmClient.ListAssetsForAppID(app.GroupID, app.ID)
-		if rAMErr != nil {
-			return errIncludeHosting(fmt.Errorf("error retrieving remote assets: %s", rAMErr))
+		if appDiffs == nil {
+			// the client diff lists them already
+			diffs = append(append([]string(nil), diffs...), utils.DestructiveSchemaChangesDiff(schemaChanges)...)
 		}
 
-		assetMetadataDiffs = hosting.DiffAssetMetadata(localAssetMetadata, remoteAssetMetadata, ic.flagStrategy == importStrategyMerge)
-	}
+		configDiffs := diffs
+		var hostingDiffs, dependencyDiffs []string
 
-	// Diff changes unless -y flag has been provided or if this is a new app
-	if !ic.flagYes && !skipDiff {
-		diffs, diffErr := realmClient.Diff(app.GroupID, app.ID, appData, ic.flagStrategy)
-		if diffErr != nil {
-			return fmt.Errorf("failed to diff app with currently deployed instance: %s", diffErr)
+		if ic.flagIncludeHosting && assetMetadataDiffs != nil {
+			hostingDiffs = assetMetadataDiffs.Diff()
+			diffs = append(append([]string(nil), diffs...), hostingDiffs...)
 		}
 
-		if ic.flagIncludeHosting && assetMetadataDiffs != nil {
-			hostingDiff := assetMetadataDiffs.Diff()
-			diffs = append(diffs, hostingDiff...)
+		if uploadDependencies {
+			var dependenciesErr error
+			if dependencyDiffs, dependenciesErr = diffDependencies(functionsDir, realmClient, app); dependenciesErr != nil {
+				return fmt.Errorf("failed to diff dependencies with the deployed ones: %s", dependenciesErr)
+			}
+			if len(dependencyDiffs) == 0 {
+				// e.g. only the scripts of package.json changed
+				dependencyDiffs = []string{"Import dependencies (no package was added, removed or changed)"}
+			}
+			diffs = append(append([]string(nil), diffs...), dependencyDiffs...)
+		}
+
+		if ic.plan != nil {
+			if err := ic.plan.check(app, appData, diffs); err != nil {
+				return err
+			}
 		}
 
-		if ic.flagIncludeDependencies {
-			diffs = append(diffs, "Import dependencies")
+		if dryRun && ic.flagDiffOutput == diffOutputJSON {
+			ic.noChanges = len(diffs) == 0
+			return ic.printDiffJSON(appPath, configDiffs, hostingDiffs, dependencyDiffs, appDiffs, assetMetadataDiffs)
 		}
 
 		if len(diffs) == 0 {
+			ic.noChanges = true
+			if !dryRun {
+				ic.importedApp = app
+			}
 			ic.UI.Info("Deployed app is identical to proposed version, nothing to do.")
 			return nil
 		}
@@ -314,21 +1046,64 @@ func (ic *ImportCommand) importApp(dryRun bool) error {
 		}
 
 		if dryRun {
+			if assetMetadataDiffs != nil {
+				ic.UI.Info(newHostingChanges(assetMetadataDiffs).summary())
+			}
 			return nil
 		}
 
-		confirm, confirmErr := ic.AskYesNo("Please confirm the changes shown above:")
-		if confirmErr != nil {
-			return confirmErr
+		if len(schemaChanges) > 0 && !ic.flagAllowDestructive {
+			return errDestructiveSchemaChanges(len(schemaChanges))
 		}
 
-		if !confirm {
+		if ic.flagPlanFile != "" {
+			plan, err := ic.newImportPlan(app, appInstanceData.AppID(), appPath, appData, diffs, assetMetadataDiffs != nil && len(assetMetadataDiffs.Diff()) > 0, uploadDependencies)
+			if err != nil {
+				return err
+			}
+			if err := writeImportPlan(ic.flagPlanFile, plan); err != nil {
+				return err
+			}
+			ic.UI.Info(fmt.Sprintf("Saved the plan to %s, apply it with 'realm-cli apply --%s=%s'", ic.flagPlanFile, applyFlagPlan, ic.flagPlanFile))
 			return nil
 		}
+
+		if ic.plan == nil && !ic.watching {
+			confirm, confirmErr := ic.AskYesNo("Please confirm the changes shown above:")
+			if confirmErr != nil {
+				return confirmErr
+			}
+
+			if !confirm {
+				return nil
+			}
+		}
+	}
+
+	// the changes were not shown with -y, but destructive ones are still refused
+	if ic.flagYes && !diffed && !skipDiff && !ic.flagAllowDestructive {
+		schemaChanges, err := ic.destructiveSchemaChanges(realmClient, app, loadedApp, nil)
+		if err != nil {
+			return err
+		}
+		if len(schemaChanges) > 0 {
+			for _, diff := range utils.DestructiveSchemaChangesDiff(schemaChanges) {
+				ic.UI.Info(diff)
+			}
+			return errDestructiveSchemaChanges(len(schemaChanges))
+		}
+	}
+
+	if err := waitForHosting(); err != nil {
+		return err
 	}
 
 	ic.UI.Info("Creating draft for app...")
+	draftDone := ic.timings.start(importPhaseDraft)
 	draft, err := realmClient.CreateDraft(app.GroupID, app.ID)
+	if err != nil && ic.flagRetryOnConflict {
+		draft, err = ic.retryCreateDraft(realmClient, app, err)
+	}
 	if err != nil {
 		if e, ok := err.(api.ErrRealmResponse); !ok || e.ErrorCode() != "DraftAlreadyExists" {
 			return fmt.Errorf("failed to create draft for import: %s", err)
@@ -382,23 +1157,47 @@ func (ic *ImportCommand) importApp(dryRun bool) error {
 		}
 	}
 
+	draftDone()
 	ic.UI.Info("Draft created successfully...")
 	ic.UI.Info("Importing app...")
-	if importErr := realmClient.Import(app.GroupID, app.ID, appData, ic.flagStrategy); importErr != nil {
+	importDone := ic.timings.start(importPhaseImport)
+	importCtx, cancelImport := context.Background(), func() {}
+	if ic.flagImportTimeout > 0 {
+		importCtx, cancelImport = context.WithTimeout(importCtx, ic.flagImportTimeout)
+	}
+	importErr := realmClient.Import(importCtx, app.GroupID, app.ID, appData, ic.flagStrategy)
+	timedOut := importCtx.Err() == context.DeadlineExceeded
+	cancelImport()
+	importDone()
+	if importErr != nil {
 		ic.discardDraftAndWarnOnFailure(app.GroupID, app.ID, draft.ID)
+		if timedOut {
+			return errImportTimeout(ic.flagImportTimeout)
+		}
 		return fmt.Errorf("failed to import app: %s", importErr)
 	}
 
 	ic.UI.Info("Deploying app...")
+	deployDone := ic.timings.start(importPhaseDeploy)
 	deployment, err := realmClient.DeployDraft(app.GroupID, app.ID, draft.ID)
 	if err != nil {
 		ic.discardDraftAndWarnOnFailure(app.GroupID, app.ID, draft.ID)
 		return fmt.Errorf("failed to deploy draft: %s", err)
 	}
 
+	deployStarted := time.Now()
 	for deployment.Status == models.DeploymentStatusCreated || deployment.Status == models.DeploymentStatusPending {
-		time.Sleep(time.Second * 1)
-		ic.UI.Info("Deploying app...")
+		if ic.flagDeployTimeout > 0 && time.Since(deployStarted) >= ic.flagDeployTimeout {
+			ic.discardDraftAndWarnOnFailure(app.GroupID, app.ID, draft.ID)
+			return errDeployTimeout(ic.flagDeployTimeout)
+		}
+
+		time.Sleep(ic.deployPollInterval)
+		if waited := time.Since(deployStarted); waited >= deployWaitedThreshold {
+			ic.UI.Info(fmt.Sprintf("Deploying app... (waiting for %s)", waited.Round(time.Second)))
+		} else {
+			ic.UI.Info("Deploying app...")
+		}
 
 		deployment, err = realmClient.GetDeployment(app.GroupID, app.ID, deployment.ID)
 		if err != nil {
@@ -407,27 +1206,44 @@ func (ic *ImportCommand) importApp(dryRun bool) error {
 		}
 	}
 
+	deployDone()
+	ic.deployedApp, ic.deployedAt = app, time.Now()
+
+	// the deployed app has changed, so any diff computed against it is stale
+	ic.diffCache.invalidate()
 	ic.UI.Info("Done.")
 
 	if ic.flagIncludeHosting && assetMetadataDiffs != nil {
 		ic.UI.Info("Importing hosting assets...")
-		if hostingImportErr := ImportHosting(app.GroupID, app.ID, rootDir, assetMetadataDiffs, ic.flagResetCDNCache, realmClient, ic.UI); hostingImportErr != nil {
+		done := ic.timings.start(importPhaseHosting)
+		hostingImportErr := ImportHosting(app.GroupID, app.ID, rootDir, assetMetadataDiffs, ic.flagHostingConcurrency, ic.flagHostingFailFast, realmClient, ic.UI)
+		forgetRemoteAssets(ic.flagConfigPath, app, ic.UI)
+		if len(cachePaths) == 0 && ic.flagResetCDNCache {
+			cachePaths = changedCDNCachePaths(assetMetadataDiffs, ic.flagCacheThreshold)
+		}
+		if hostingImportErr == nil && len(cachePaths) > 0 {
+			hostingImportErr = InvalidateHostingCache(app.GroupID, app.ID, cachePaths, ic.cacheInvalidationPollInterval, cacheInvalidationTimeout, realmClient, ic.UI)
+		}
+		done()
+		if hostingImportErr != nil {
 			return fmt.Errorf("failed to import hosting assets %s", hostingImportErr)
 		}
 		ic.UI.Info("Done.")
 	}
 
-	if ic.flagIncludeDependencies {
-		functionsDir, dirErr := filepath.Abs(filepath.Join(appPath, utils.FunctionsRoot))
-		if dirErr != nil {
-			return dirErr
-		}
-
-		importErr := ImportDependencies(ic.UI, app.GroupID, app.ID, functionsDir, realmClient)
+	if uploadDependencies {
+		done := ic.timings.start(importPhaseDependencies)
+		importErr := ImportDependencies(ic.UI, app.GroupID, app.ID, functionsDir, transpiling, realmClient)
+		done()
 		if importErr != nil {
 			return importErr
 		}
+		if cacheErr := recordDependenciesHash(ic.flagConfigPath, app.ID, dependenciesHash); cacheErr != nil {
+			ic.UI.Error(cacheErr.Error())
+		}
 		ic.UI.Info("Done.")
+	} else if ic.flagIncludeDependencies {
+		ic.UI.Info("dependencies unchanged, skipping upload")
 	}
 
 	exportStrategy := api.ExportStrategyNone
@@ -442,33 +1258,220 @@ func (ic *ImportCommand) importApp(dryRun bool) error {
 
 	defer body.Close()
 
-	if err := ic.writeToDirectory(appPath, body, true); err != nil {
+	// the environments keep referencing the variables rather than being overwritten with their values
+	environmentPaths := make([]string, 0, len(expandedEnvironments))
+	for _, relPath := range expandedEnvironments {
+		environmentPaths = append(environmentPaths, filepath.Join(appPath, filepath.FromSlash(relPath)))
+	}
+	if err := keepingFiles(environmentPaths, func() error {
+		return ic.writeToDirectory(appPath, body, true)
+	}); err != nil {
 		return errImportAppSyncFailure(err)
 	}
 
-	ic.UI.Info(fmt.Sprintf("Successfully imported '%s'", app.ClientAppID))
+	ic.importedApp = app
+	ic.UI.Info(fmt.Sprintf("Successfully imported '%s' (ObjectID %s in Project %s)", app.ClientAppID, app.ID, app.GroupID))
+
+	return nil
+}
+
+// keepingFiles runs write, which overwrites the app directory, and then restores the files at the given paths
+func keepingFiles(paths []string, write func() error) error {
+	type keptFile struct {
+		data []byte
+		mode os.FileMode
+	}
+
+	files := make(map[string]keptFile, len(paths))
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		files[path] = keptFile{data, info.Mode().Perm()}
+	}
+
+	if err := write(); err != nil {
+		return err
+	}
 
+	for path, file := range files {
+		if err := ioutil.WriteFile(path, file.data, file.mode); err != nil {
+			return err
+		}
+		// the mode given to WriteFile only applies to new files
+		if err := os.Chmod(path, file.mode); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// fetchAppByClientAppID finds the app to import to, in the project given by --project-id or else in any
+// project of the user. When several apps match, the user is asked to choose one, unless the import is not
+// interactive in which case the apps are listed in the error
 func (ic *ImportCommand) fetchAppByClientAppID(clientAppID string) (*models.App, error) {
+	if clientAppID == "" {
+		return nil, api.ErrAppNotFound{ClientAppID: clientAppID}
+	}
+
+	// a client app ID is unique within a project, so there is no need to list its apps
+	if ic.flagGroupID != "" {
+		realmClient, err := ic.RealmClient()
+		if err != nil {
+			return nil, err
+		}
+		return realmClient.FetchAppByGroupIDAndClientAppID(ic.flagGroupID, clientAppID)
+	}
+
+	return ic.findApp(api.AppFilter{ClientAppID: clientAppID}, clientAppID)
+}
+
+// resolveToApp finds the app given by --to-app, either by its ObjectID or by its client app ID, and
+// targets the import at it as if it had been given by --app-id and --project-id. Unlike an App ID from
+// the app config, an app which cannot be found is not offered to be created
+func (ic *ImportCommand) resolveToApp() error {
+	var app *models.App
+	var err error
+	if isObjectIDHex(ic.flagToApp) {
+		app, err = ic.findApp(api.AppFilter{GroupID: ic.flagGroupID, AppID: ic.flagToApp}, ic.flagToApp)
+	} else {
+		app, err = ic.fetchAppByClientAppID(ic.flagToApp)
+	}
+	if err != nil {
+		return err
+	}
+
+	// the app is looked up once, even when watching
+	ic.flagAppID, ic.flagGroupID, ic.flagToApp = app.ClientAppID, app.GroupID, ""
+	return nil
+}
+
+// findAppByName finds the app named by --to-name in the project given by --project-id, or else chosen as
+// when creating an app, and returns nil if there is none. The app is created in that project if missing
+func (ic *ImportCommand) findAppByName(name string) (*models.App, error) {
+	groupID, err := ic.resolveGroupID()
+	if err != nil {
+		return nil, err
+	}
+	ic.flagGroupID = groupID
+
 	realmClient, err := ic.RealmClient()
 	if err != nil {
 		return nil, err
 	}
 
-	if ic.flagGroupID == "" {
-		return realmClient.FetchAppByClientAppID(clientAppID)
+	apps, err := realmClient.FindApps(api.AppFilter{GroupID: groupID, Name: name})
+	if err != nil || len(apps) == 0 {
+		return nil, err
 	}
 
-	return realmClient.FetchAppByGroupIDAndClientAppID(ic.flagGroupID, clientAppID)
+	// app names are unique within a project
+	return apps[0], nil
 }
 
+// findApp finds the app matching the filter, the target of the import as given by the user
+func (ic *ImportCommand) findApp(filter api.AppFilter, target string) (*models.App, error) {
+	realmClient, err := ic.RealmClient()
+	if err != nil {
+		return nil, err
+	}
+
+	apps, err := realmClient.FindApps(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	switch len(apps) {
+	case 0:
+		return nil, api.ErrAppNotFound{ClientAppID: target}
+	case 1:
+		return apps[0], nil
+	}
+
+	if ic.flagYes {
+		return nil, errAmbiguousApp(target, apps)
+	}
+	return ic.askApp(target, apps)
+}
+
+// askApp lists the apps matching the target of the import and asks the user to choose one
+func (ic *ImportCommand) askApp(target string, apps []*models.App) (*models.App, error) {
+	ic.UI.Info(fmt.Sprintf("Apps matching %q:", target))
+
+	options := make([]string, len(apps))
+	for i, app := range apps {
+		options[i] = fmt.Sprint(i + 1)
+		ic.UI.Info(fmt.Sprintf("%s. %s", options[i], describeMatchingApp(app)))
+	}
+
+	choice, err := ic.AskWithOptions("Which app would you like to use", "", options)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, option := range options {
+		if option == choice {
+			return apps[i], nil
+		}
+	}
+	return nil, errAmbiguousApp(target, apps)
+}
+
+func errAmbiguousApp(target string, apps []*models.App) error {
+	candidates := make([]string, len(apps))
+	for i, app := range apps {
+		candidates[i] = "\t" + describeMatchingApp(app)
+	}
+	return fmt.Errorf(
+		"%d apps match %q, pass --%s to choose one:\n%s",
+		len(apps),
+		target,
+		flagProjectIDName,
+		strings.Join(candidates, "\n"),
+	)
+}
+
+func describeMatchingApp(app *models.App) string {
+	return fmt.Sprintf("%s in Project %s (%s)", app.ClientAppID, app.GroupID, app.ID)
+}
+
+// resolveGroupID returns the Atlas Project ID to create the app in. Unless --project-id is given, the project
+// chosen the last time is reused as long as the user is logged in as the same account, which saves
+// listing the projects again. The user is asked to choose a project with --refresh-project
 func (ic *ImportCommand) resolveGroupID() (string, error) {
 	if ic.flagGroupID != "" {
 		return ic.flagGroupID, nil
 	}
 
+	user, err := ic.User()
+	if err != nil {
+		return "", err
+	}
+
+	if groupID, ok := user.CachedProjectID(); ok && !ic.flagRefreshProject {
+		ic.UI.Info(fmt.Sprintf("Using the Atlas Project %s chosen before, pass --%s to choose another", groupID, importFlagRefreshProject))
+		return groupID, nil
+	}
+
+	groupID, err := ic.askGroupID()
+	if err != nil {
+		return "", err
+	}
+
+	user.CacheProjectID(groupID)
+	if err := ic.storage.WriteUserConfig(user); err != nil {
+		return "", err
+	}
+	return groupID, nil
+}
+
+// askGroupID lists the Atlas Projects of the user and asks them to choose one
+func (ic *ImportCommand) askGroupID() (string, error) {
 	atlasClient, err := ic.AtlasClient()
 	if err != nil {
 		return "", fmt.Errorf("an unexpected error occurred: %s", err)
@@ -531,6 +1534,12 @@ func (ic *ImportCommand) askCreateEmptyApp(query, defaultAppName, defaultLocatio
 	if ic.flagAppName != "" {
 		defaultAppName = ic.flagAppName
 	}
+	if ic.flagLocation != "" {
+		defaultLocation = ic.flagLocation
+	}
+	if ic.flagDeploymentModel != "" {
+		defaultDeploymentModel = ic.flagDeploymentModel
+	}
 
 	confirm, err := ic.AskYesNo(fmt.Sprintf("%s: would you like to create a new app?", query))
 	if err != nil {
@@ -551,17 +1560,10 @@ func (ic *ImportCommand) askCreateEmptyApp(query, defaultAppName, defaultLocatio
 		return nil, false, err
 	}
 
-	apps, err := realmClient.FetchAppsByGroupID(groupID)
-	if err != nil {
+	if err := checkAppNameAvailable(realmClient, groupID, appName); err != nil {
 		return nil, false, err
 	}
 
-	for _, app := range apps {
-		if app.Name == appName {
-			return nil, false, fmt.Errorf("app already exists with name %q", appName)
-		}
-	}
-
 	location, err := ic.AskWithOptions("Location", defaultLocation, locationOptions)
 	if err != nil {
 		return nil, false, err
@@ -581,6 +1583,290 @@ func (ic *ImportCommand) askCreateEmptyApp(query, defaultAppName, defaultLocatio
 	return app, true, nil
 }
 
+// createEmptyApp creates the app without prompting, taking each of its settings from the flags
+// or else the app config, and fails if any of them is missing
+func (ic *ImportCommand) createEmptyApp(defaultAppName, defaultLocation, defaultDeploymentModel string, realmClient api.RealmClient) (*models.App, error) {
+	createFlagName := importFlagCreateIfMissing
+	if ic.flagToName != "" {
+		createFlagName = importFlagToName
+	}
+
+	if ic.flagGroupID == "" {
+		return nil, fmt.Errorf("a Project ID (--%s=[string]) must be supplied to create the app with --%s", flagProjectIDName, createFlagName)
+	}
+
+	appName, location, deploymentModel := defaultAppName, defaultLocation, defaultDeploymentModel
+	if ic.flagAppName != "" {
+		appName = ic.flagAppName
+	}
+	if ic.flagLocation != "" {
+		location = ic.flagLocation
+	}
+	if ic.flagDeploymentModel != "" {
+		deploymentModel = ic.flagDeploymentModel
+	}
+
+	if appName == "" {
+		return nil, errCreateIfMissingRequires(importFlagAppName, "an app name", createFlagName)
+	}
+
+	if location == "" {
+		return nil, errCreateIfMissingRequires(importFlagLocation, "a location", createFlagName)
+	}
+	if !isOneOf(location, locationOptions) {
+		return nil, errUnknownOption("location", location, locationOptions)
+	}
+
+	if deploymentModel == "" {
+		return nil, errCreateIfMissingRequires(importFlagDeploymentModel, "a deployment model", createFlagName)
+	}
+	if !isOneOf(deploymentModel, deploymentModelOptions) {
+		return nil, errUnknownOption("deployment model", deploymentModel, deploymentModelOptions)
+	}
+
+	if err := checkAppNameAvailable(realmClient, ic.flagGroupID, appName); err != nil {
+		return nil, err
+	}
+
+	app, err := realmClient.CreateEmptyApp(ic.flagGroupID, appName, location, deploymentModel)
+	if err != nil {
+		return nil, err
+	}
+
+	ic.UI.Info(fmt.Sprintf("New app created: %s", app.ClientAppID))
+	return app, nil
+}
+
+func checkAppNameAvailable(realmClient api.RealmClient, groupID, appName string) error {
+	apps, err := realmClient.FetchAppsByGroupID(groupID)
+	if err != nil {
+		return err
+	}
+
+	for _, app := range apps {
+		if app.Name == appName {
+			return fmt.Errorf("app already exists with name %q", appName)
+		}
+	}
+
+	return nil
+}
+
+// diffApp computes the changes the import would make to the deployed app using the selected diff algorithm.
+// The structural diff is only available with the client diff algorithm
+func (ic *ImportCommand) diffApp(realmClient api.RealmClient, app *models.App, loadedApp map[string]interface{}, appData []byte) ([]string, *utils.AppDiffs, error) {
+	if ic.flagBaseline != "" {
+		diffs, err := ic.diffAppThreeWay(realmClient, app, loadedApp)
+		return diffs, nil, err
+	}
+
+	cacheKey := appDiffCacheKey(app, ic.flagStrategy, ic.flagDiffAlgorithm, ic.renameThreshold(), ic.ignoredFields, appData)
+	if diffs, appDiffs, ok := ic.diffCache.get(cacheKey); ok {
+		return diffs, appDiffs, nil
+	}
+
+	if ic.flagDiffAlgorithm != diffAlgorithmClient {
+		diffs, err := realmClient.Diff(app.GroupID, app.ID, appData, ic.flagStrategy)
+		if err != nil {
+			return nil, nil, err
+		}
+		ic.diffCache.set(cacheKey, diffs, nil)
+		return diffs, nil, nil
+	}
+
+	deployedApp, err := exportDeployedApp(realmClient, app)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	appDiffs := utils.DiffApps(
+		utils.IgnoreFields(loadedApp, ic.ignoredFields),
+		utils.IgnoreFields(deployedApp, ic.ignoredFields),
+		ic.flagStrategy == importStrategyMerge,
+	)
+	if threshold := ic.renameThreshold(); threshold > 0 {
+		appDiffs.DetectRenames(loadedApp, deployedApp, threshold)
+	}
+	diffs := appDiffs.Diff()
+	ic.diffCache.set(cacheKey, diffs, appDiffs)
+	return diffs, appDiffs, nil
+}
+
+// destructiveSchemaChanges returns the changes to the schemas of the deployed app which may delete data,
+// taking them from appDiffs when the client diffed the app already. With the merge strategy, schemas
+// cannot change unless the local app has rules, so the deployed app is only exported when it does
+func (ic *ImportCommand) destructiveSchemaChanges(realmClient api.RealmClient, app *models.App, loadedApp map[string]interface{}, appDiffs *utils.AppDiffs) ([]utils.DestructiveSchemaChange, error) {
+	if appDiffs != nil {
+		return appDiffs.SchemaChanges, nil
+	}
+
+	merge := ic.flagStrategy == importStrategyMerge
+	if merge && !utils.HasCollectionRules(loadedApp) {
+		return nil, nil
+	}
+
+	deployedApp, err := exportDeployedApp(realmClient, app)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for destructive schema changes: %s", err)
+	}
+
+	return utils.DiffDestructiveSchemaChanges(
+		utils.IgnoreFields(loadedApp, ic.ignoredFields),
+		utils.IgnoreFields(deployedApp, ic.ignoredFields),
+		merge,
+	), nil
+}
+
+// renameThreshold returns how similar resources must be to be diffed as a rename, or 0 if renames are not detected
+func (ic *ImportCommand) renameThreshold() float64 {
+	if ic.flagNoRenameDetection {
+		return 0
+	}
+	return ic.flagRenameThreshold
+}
+
+// diffAppThreeWay compares the local and deployed app against the --baseline export they both started from
+func (ic *ImportCommand) diffAppThreeWay(realmClient api.RealmClient, app *models.App, loadedApp map[string]interface{}) ([]string, error) {
+	baselinePath, err := homedir.Expand(ic.flagBaseline)
+	if err != nil {
+		return nil, err
+	}
+
+	baselineFile, err := os.Open(baselinePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open baseline: %s", err)
+	}
+	defer baselineFile.Close()
+
+	baselineApp, err := utils.UnmarshalFromZip(baselineFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load baseline %q: %s", ic.flagBaseline, err)
+	}
+
+	deployedApp, err := exportDeployedApp(realmClient, app)
+	if err != nil {
+		return nil, err
+	}
+
+	ic.threeWayDiffs = utils.DiffAppsThreeWay(
+		utils.IgnoreFields(loadedApp, ic.ignoredFields),
+		utils.IgnoreFields(deployedApp, ic.ignoredFields),
+		utils.IgnoreFields(baselineApp, ic.ignoredFields),
+	)
+	return ic.threeWayDiffs.Diff(), nil
+}
+
+// exportDeployedApp downloads the configuration of the deployed app and loads it like a local app
+func exportDeployedApp(realmClient api.RealmClient, app *models.App) (map[string]interface{}, error) {
+	_, body, err := realmClient.Export(app.GroupID, app.ID, api.ExportStrategyNone)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	return utils.UnmarshalFromZip(body)
+}
+
+// diffOutput is the JSON representation of a diff
+type diffOutput struct {
+	Changed bool `json:"changed"`
+	// Diffs lists every change, while the app configuration, hosting and dependency changes
+	// are also listed apart so that each can be checked on its own
+	Diffs           []string          `json:"diffs"`
+	AppDiffs        []string          `json:"app_diffs"`
+	HostingDiffs    []string          `json:"hosting_diffs"`
+	DependencyDiffs []string          `json:"dependency_diffs"`
+	Changes         []utils.AppChange `json:"changes"`
+	// HostingChanges breaks the hosting changes down by path with --include-hosting
+	HostingChanges *hostingChanges `json:"hosting_changes,omitempty"`
+	Timings        []phaseTiming   `json:"timings,omitempty"`
+	// Baseline splits the changes by the side which made them when diffing against a --baseline
+	Baseline *threeWayDiffOutput `json:"baseline,omitempty"`
+}
+
+// threeWayDiffOutput is the JSON representation of a three-way diff
+type threeWayDiffOutput struct {
+	Mine        []utils.AppChange `json:"mine"`
+	Theirs      []utils.AppChange `json:"theirs"`
+	Conflicting []utils.AppChange `json:"conflicting"`
+}
+
+// printDiffJSON prints the diff as JSON, linking each change to the local file that produced it when known
+func (ic *ImportCommand) printDiffJSON(appPath string, configDiffs, hostingDiffs, dependencyDiffs []string, appDiffs *utils.AppDiffs, assetMetadataDiffs *hosting.AssetMetadataDiffs) error {
+	nonNil := func(diffs []string) []string {
+		if diffs == nil {
+			return []string{}
+		}
+		return diffs
+	}
+
+	diffs := append(append(append([]string{}, configDiffs...), hostingDiffs...), dependencyDiffs...)
+	output := diffOutput{
+		Changed:         len(diffs) > 0,
+		Diffs:           diffs,
+		AppDiffs:        nonNil(configDiffs),
+		HostingDiffs:    nonNil(hostingDiffs),
+		DependencyDiffs: nonNil(dependencyDiffs),
+		Changes:         []utils.AppChange{},
+	}
+	if assetMetadataDiffs != nil {
+		output.HostingChanges = newHostingChanges(assetMetadataDiffs)
+	}
+	if ic.flagTimings {
+		output.Timings = ic.timings.phases
+	}
+
+	if appDiffs != nil || ic.threeWayDiffs != nil {
+		paths, err := utils.AppResourcePaths(appPath)
+		if err != nil {
+			return err
+		}
+
+		if appDiffs != nil {
+			output.Changes = appDiffs.Changes(paths)
+		}
+		if ic.threeWayDiffs != nil {
+			output.Baseline = &threeWayDiffOutput{
+				Mine:        ic.threeWayDiffs.Mine.Changes(paths),
+				Theirs:      ic.threeWayDiffs.Theirs.Changes(paths),
+				Conflicting: ic.threeWayDiffs.Conflicting.Changes(paths),
+			}
+		}
+	}
+
+	data, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	ic.UI.Output(string(data))
+	return nil
+}
+
+// retryCreateDraft waits for an existing draft to go away and retries creating a draft,
+// returning errDraftConflict if one still exists after draftConflictRetries attempts
+func (ic *ImportCommand) retryCreateDraft(realmClient api.RealmClient, app *models.App, err error) (*models.AppDraft, error) {
+	for attempt := 1; attempt <= draftConflictRetries; attempt++ {
+		if e, ok := err.(api.ErrRealmResponse); !ok || e.ErrorCode() != "DraftAlreadyExists" {
+			return nil, err
+		}
+
+		ic.UI.Info(fmt.Sprintf("A draft already exists for your app, retrying in %s (attempt %d of %d)...", ic.draftRetryInterval, attempt, draftConflictRetries))
+		time.Sleep(ic.draftRetryInterval)
+
+		var draft *models.AppDraft
+		draft, err = realmClient.CreateDraft(app.GroupID, app.ID)
+		if err == nil {
+			return draft, nil
+		}
+	}
+
+	if e, ok := err.(api.ErrRealmResponse); ok && e.ErrorCode() == "DraftAlreadyExists" {
+		return nil, errDraftConflict(draftConflictRetries)
+	}
+	return nil, err
+}
+
 func (ic *ImportCommand) discardDraftAndWarnOnFailure(groupID, appID, draftID string) {
 	err := ic.realmClient.DiscardDraft(groupID, appID, draftID)
 	if err != nil {
@@ -597,3 +1883,12 @@ func isObjectIDHex(s string) bool {
 	_, err := hex.DecodeString(s)
 	return err == nil
 }
+
+func isOneOf(s string, options []string) bool {
+	for _, option := range options {
+		if s == option {
+			return true
+		}
+	}
+	return false
+}
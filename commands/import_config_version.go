@@ -0,0 +1,81 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/10gen/realm-cli/api"
+	"github.com/10gen/realm-cli/models"
+	"github.com/10gen/realm-cli/utils"
+
+	"github.com/mitchellh/cli"
+)
+
+const appConfigVersionField = "config_version"
+
+// appConfigVersion returns the config version declared by the loaded app, if any
+func appConfigVersion(app map[string]interface{}) (string, bool) {
+	switch version := app[appConfigVersionField].(type) {
+	case float64:
+		return strconv.FormatFloat(version, 'f', -1, 64), true
+	case string:
+		return version, version != ""
+	}
+	return "", false
+}
+
+// deployedConfigVersion returns the config version the app is deployed with, as declared by the app
+// exported in that version
+func deployedConfigVersion(realmClient api.RealmClient, app *models.App) (string, error) {
+	_, body, err := realmClient.Export(app.GroupID, app.ID, api.ExportStrategyDeployedVersion)
+	if err != nil {
+		return "", fmt.Errorf("failed to export the deployed app to check its %s: %s", appConfigVersionField, err)
+	}
+	defer body.Close()
+
+	deployedApp, err := utils.UnmarshalFromZip(body)
+	if err != nil {
+		return "", fmt.Errorf("failed to export the deployed app to check its %s: %s", appConfigVersionField, err)
+	}
+
+	version, ok := appConfigVersion(deployedApp)
+	if !ok {
+		return "", fmt.Errorf("the deployed app does not declare its %s", appConfigVersionField)
+	}
+	return version, nil
+}
+
+// checkConfigVersion makes sure the loaded app declares the config version the deployed app is deployed with,
+// since an app written in another version may be imported incompletely or incorrectly. The check is only made
+// with --strict-config-version. A mismatch is only warned about if allowMismatch is set
+func checkConfigVersion(app map[string]interface{}, deployedVersion string, allowMismatch bool, ui cli.Ui) error {
+	version, ok := appConfigVersion(app)
+	if !ok {
+		return fmt.Errorf("the app does not declare its %s, which must be %s with --%s", appConfigVersionField, deployedVersion, importFlagStrictConfigVersion)
+	}
+
+	if version == deployedVersion {
+		return nil
+	}
+
+	if allowMismatch {
+		ui.Warn(fmt.Sprintf("The app declares %s %s, but the deployed app is version %s. Importing anyway as --%s is set", appConfigVersionField, version, deployedVersion, importFlagVersionMismatch))
+		return nil
+	}
+
+	return fmt.Errorf(
+		"the app declares %s %s, but the deployed app is version %s. Migrate the app to version %s, "+
+			"e.g. by passing --%s=%s, or pass --%s to import it anyway",
+		appConfigVersionField, version, deployedVersion, deployedVersion, importFlagConfigVersion, deployedVersion, importFlagVersionMismatch,
+	)
+}
+
+// convertConfigVersion returns the loaded app, which is laid out the way realm-cli imports apps, migrated into
+// the given config version by utils.MigrateApp. Apps which use anything that cannot be expressed in that version
+// are rejected rather than imported without it
+func convertConfigVersion(app map[string]interface{}, version string) (map[string]interface{}, error) {
+	if version == utils.AppConfigVersion20210101 {
+		return nil, fmt.Errorf("cannot import the app as %s %s, which lays apps out differently than realm-cli reads them", appConfigVersionField, version)
+	}
+	return utils.MigrateApp(app, version)
+}
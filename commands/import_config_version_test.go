@@ -0,0 +1,278 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/10gen/realm-cli/api"
+	"github.com/10gen/realm-cli/models"
+	"github.com/10gen/realm-cli/user"
+	u "github.com/10gen/realm-cli/utils/test"
+	gc "github.com/smartystreets/goconvey/convey"
+
+	"github.com/mitchellh/cli"
+)
+
+func TestCheckConfigVersion(t *testing.T) {
+	for _, tc := range []struct {
+		Description     string
+		App             map[string]interface{}
+		DeployedVersion string
+		AllowMismatch   bool
+		ExpectedError   string
+		ExpectedWarning string
+	}{
+		{
+			Description:     "it accepts the config version the app is deployed with",
+			App:             map[string]interface{}{"config_version": float64(20200603)},
+			DeployedVersion: "20200603",
+		},
+		{
+			Description:     "it rejects apps which do not declare their config version",
+			App:             map[string]interface{}{},
+			DeployedVersion: "20200603",
+			ExpectedError:   "the app does not declare its config_version, which must be 20200603 with --strict-config-version",
+		},
+		{
+			Description:     "it rejects another config version",
+			App:             map[string]interface{}{"config_version": float64(20200603)},
+			DeployedVersion: "20180301",
+			ExpectedError:   "the app declares config_version 20200603, but the deployed app is version 20180301. Migrate the app to version 20180301, e.g. by passing --config-version=20180301",
+		},
+		{
+			Description:     "it warns about another config version when mismatches are allowed",
+			App:             map[string]interface{}{"config_version": float64(20200603)},
+			DeployedVersion: "20180301",
+			AllowMismatch:   true,
+			ExpectedWarning: "Importing anyway as --allow-version-mismatch is set",
+		},
+	} {
+		t.Run(tc.Description, func(t *testing.T) {
+			mockUI := cli.NewMockUi()
+
+			err := checkConfigVersion(tc.App, tc.DeployedVersion, tc.AllowMismatch, mockUI)
+			if tc.ExpectedError == "" {
+				u.So(t, err, gc.ShouldBeNil)
+			} else {
+				u.So(t, err, gc.ShouldNotBeNil)
+				u.So(t, err.Error(), gc.ShouldContainSubstring, tc.ExpectedError)
+			}
+			u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, tc.ExpectedWarning)
+		})
+	}
+
+	t.Run("it fails the import before contacting Realm when the app must declare its config version", func(t *testing.T) {
+		importCommand, mockUI := setUpBasicCommand()
+		importCommand.user = &user.User{
+			APIKey:      "my-api-key",
+			AccessToken: u.GenerateValidAccessToken(),
+		}
+
+		exitCode := importCommand.Run([]string{"--app-id=my-app-abcdef", "--path=../testdata/simple_app_empty_config_json", "--strict-config-version"})
+		u.So(t, exitCode, gc.ShouldEqual, 1)
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, "the app does not declare its config_version")
+	})
+
+	// deployedAppDir holds an app deployed with config version 20180301
+	deployedAppDir, err := ioutil.TempDir("", "realm-deployed-app-")
+	u.So(t, err, gc.ShouldBeNil)
+	defer os.RemoveAll(deployedAppDir)
+	u.So(t, ioutil.WriteFile(filepath.Join(deployedAppDir, "config.json"), []byte(`{"config_version": 20180301, "name": "simple-app"}`), 0600), gc.ShouldBeNil)
+
+	newRealmClient := func(imported *bool) *u.MockRealmClient {
+		return &u.MockRealmClient{
+			FetchAppByClientAppIDFn: func(clientAppID string) (*models.App, error) {
+				return &models.App{GroupID: "group-id", ID: "app-id", ClientAppID: clientAppID}, nil
+			},
+			ExportFn: func(groupID, appID string, strategy api.ExportStrategy) (string, io.ReadCloser, error) {
+				if strategy == api.ExportStrategyDeployedVersion {
+					return "", u.NewZipResponseBody(deployedAppDir), nil
+				}
+				return "", u.NewResponseBody(bytes.NewReader([]byte{})), nil
+			},
+			ImportFn: func(ctx context.Context, groupID, appID string, appData []byte, strategy string) error {
+				*imported = true
+				return nil
+			},
+		}
+	}
+
+	t.Run("it fails the import when the app declares another config version than the deployed app", func(t *testing.T) {
+		importCommand, mockUI := setUpBasicCommand()
+		importCommand.user = &user.User{
+			APIKey:      "my-api-key",
+			AccessToken: u.GenerateValidAccessToken(),
+		}
+		var imported bool
+		importCommand.realmClient = newRealmClient(&imported)
+
+		exitCode := importCommand.Run([]string{"--app-id=my-app-abcdef", "--path=../testdata/simple_app", "--strict-config-version", "-y"})
+		u.So(t, exitCode, gc.ShouldEqual, 1)
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, "the app declares config_version 20200603, but the deployed app is version 20180301")
+		u.So(t, imported, gc.ShouldBeFalse)
+
+		t.Run("unless mismatches are allowed", func(t *testing.T) {
+			importCommand, mockUI := setUpBasicCommand()
+			importCommand.user = &user.User{
+				APIKey:      "my-api-key",
+				AccessToken: u.GenerateValidAccessToken(),
+			}
+			var imported bool
+			importCommand.realmClient = newRealmClient(&imported)
+
+			exitCode := importCommand.Run([]string{"--app-id=my-app-abcdef", "--path=../testdata/simple_app", "--strict-config-version", "--allow-version-mismatch", "-y"})
+			u.So(t, exitCode, gc.ShouldEqual, 0)
+			u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, "Importing anyway as --allow-version-mismatch is set")
+			u.So(t, imported, gc.ShouldBeTrue)
+		})
+	})
+
+	t.Run("it fails the diff when the app declares another config version than the deployed app", func(t *testing.T) {
+		mockUI := cli.NewMockUi()
+		cmd, err := NewDiffCommandFactory(mockUI)()
+		u.So(t, err, gc.ShouldBeNil)
+
+		diffCommand := cmd.(*DiffCommand)
+		diffCommand.storage = u.NewEmptyStorage()
+		diffCommand.user = &user.User{
+			APIKey:      "my-api-key",
+			AccessToken: u.GenerateValidAccessToken(),
+		}
+		var imported bool
+		diffCommand.realmClient = newRealmClient(&imported)
+
+		exitCode := diffCommand.Run([]string{"--app-id=my-app-abcdef", "--path=../testdata/simple_app", "--strict-config-version"})
+		u.So(t, exitCode, gc.ShouldEqual, 1)
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, "the app declares config_version 20200603, but the deployed app is version 20180301")
+	})
+
+	t.Run("it does not check the config version without --strict-config-version", func(t *testing.T) {
+		importCommand, mockUI := setUpBasicCommand()
+		importCommand.user = &user.User{
+			APIKey:      "my-api-key",
+			AccessToken: u.GenerateValidAccessToken(),
+		}
+		var imported bool
+		importCommand.realmClient = newRealmClient(&imported)
+
+		exitCode := importCommand.Run([]string{"--app-id=my-app-abcdef", "--path=../testdata/simple_app_empty_config_json", "-y"})
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldNotContainSubstring, "config_version")
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+		u.So(t, imported, gc.ShouldBeTrue)
+	})
+}
+
+func TestConvertConfigVersion(t *testing.T) {
+	loadApp := func(graphQL map[string]interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"config_version": float64(20200603),
+			"name":           "my-app",
+			"graphql":        graphQL,
+		}
+	}
+	emptyGraphQL := map[string]interface{}{"custom_resolvers": []interface{}{}}
+
+	for _, tc := range []struct {
+		Description   string
+		App           map[string]interface{}
+		Version       string
+		ExpectedApp   map[string]interface{}
+		ExpectedError string
+	}{
+		{
+			Description: "it leaves an app converted to the version realm-cli imports apps as alone",
+			App:         loadApp(emptyGraphQL),
+			Version:     "20200603",
+			ExpectedApp: loadApp(emptyGraphQL),
+		},
+		{
+			Description: "it declares the older version and drops the unused GraphQL configuration",
+			App:         loadApp(emptyGraphQL),
+			Version:     "20180301",
+			ExpectedApp: map[string]interface{}{"config_version": float64(20180301), "name": "my-app"},
+		},
+		{
+			Description: "it migrates the app level fields of an app declaring another config version",
+			App: map[string]interface{}{
+				"config_version":          float64(20210101),
+				"name":                    "my-app",
+				"allowed_request_origins": []interface{}{"https://example.com"},
+			},
+			Version: "20200603",
+			ExpectedApp: map[string]interface{}{
+				"config_version": float64(20200603),
+				"name":           "my-app",
+				"security":       map[string]interface{}{"allowed_request_origins": []interface{}{"https://example.com"}},
+			},
+		},
+		{
+			Description:   "it rejects losing the custom resolvers of the app",
+			App:           loadApp(map[string]interface{}{"custom_resolvers": []interface{}{map[string]interface{}{"field_name": "data"}}}),
+			Version:       "20180301",
+			ExpectedError: "cannot migrate the app to config_version 20180301 without losing its GraphQL configuration",
+		},
+		{
+			Description:   "it rejects losing the GraphQL config of the app",
+			App:           loadApp(map[string]interface{}{"config": map[string]interface{}{"use_natural_pluralization": true}}),
+			Version:       "20180301",
+			ExpectedError: "cannot migrate the app to config_version 20180301 without losing its GraphQL configuration",
+		},
+		{
+			Description:   "it rejects the config version with another app layout",
+			App:           loadApp(emptyGraphQL),
+			Version:       "20210101",
+			ExpectedError: "cannot import the app as config_version 20210101",
+		},
+		{
+			Description:   "it rejects an unknown config version",
+			App:           loadApp(emptyGraphQL),
+			Version:       "2020",
+			ExpectedError: `unknown config version "2020"; accepted values are [20180301|20200603|20210101]`,
+		},
+	} {
+		t.Run(tc.Description, func(t *testing.T) {
+			app, err := convertConfigVersion(tc.App, tc.Version)
+			if tc.ExpectedError != "" {
+				u.So(t, err, gc.ShouldNotBeNil)
+				u.So(t, err.Error(), gc.ShouldContainSubstring, tc.ExpectedError)
+				return
+			}
+
+			u.So(t, err, gc.ShouldBeNil)
+			u.So(t, app, gc.ShouldResemble, tc.ExpectedApp)
+		})
+	}
+
+	t.Run("it imports the app as the version set by --config-version", func(t *testing.T) {
+		importCommand, mockUI := setUpBasicCommand()
+		importCommand.user = &user.User{
+			APIKey:      "my-api-key",
+			AccessToken: u.GenerateValidAccessToken(),
+		}
+
+		var imported map[string]interface{}
+		importCommand.realmClient = &u.MockRealmClient{
+			FetchAppByClientAppIDFn: func(clientAppID string) (*models.App, error) {
+				return &models.App{GroupID: "group-id", ID: "app-id", ClientAppID: clientAppID}, nil
+			},
+			ImportFn: func(ctx context.Context, groupID, appID string, appData []byte, strategy string) error {
+				return json.Unmarshal(appData, &imported)
+			},
+			ExportFn: func(groupID, appID string, strategy api.ExportStrategy) (string, io.ReadCloser, error) {
+				return "", u.NewResponseBody(bytes.NewReader([]byte{})), nil
+			},
+		}
+
+		exitCode := importCommand.Run([]string{"--app-id=my-app-abcdef", "--path=../testdata/simple_app", "--config-version=20180301", "-y"})
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+		u.So(t, imported["config_version"], gc.ShouldEqual, float64(20180301))
+		u.So(t, imported, gc.ShouldNotContainKey, "graphql")
+	})
+}
@@ -2,49 +2,132 @@ package commands
 
 import (
 	"archive/zip"
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/10gen/realm-cli/api"
 	"github.com/10gen/realm-cli/dependency/transpiler"
+	"github.com/10gen/realm-cli/models"
 	"github.com/10gen/realm-cli/utils"
 	"github.com/mitchellh/cli"
 )
 
-func ImportDependencies(ui cli.Ui, groupID, appID, dir string, client api.RealmClient) error {
-	fullPath, err := findDependenciesLocation(dir)
+// dependencyTranspiling decides which files of the node_modules archive are transpiled before they are uploaded.
+// Packages which are already compiled can break when they are transpiled again, so they can be uploaded as they are
+type dependencyTranspiling struct {
+	// none uploads every file as it is
+	none bool
+
+	// rawPackages are patterns, as for path.Match, of the names of the packages whose files are uploaded as they are
+	rawPackages []string
+}
+
+// newDependencyTranspiling returns the dependencyTranspiling of --no-transpile-dependencies and the comma
+// separated patterns of --no-transpile-packages
+func newDependencyTranspiling(none bool, rawPackages string) (dependencyTranspiling, error) {
+	dt := dependencyTranspiling{none: none}
+	if rawPackages == "" {
+		return dt, nil
+	}
+
+	for _, pattern := range strings.Split(rawPackages, ",") {
+		pattern = strings.TrimSpace(pattern)
+		if _, err := path.Match(pattern, ""); err != nil || pattern == "" {
+			return dependencyTranspiling{}, fmt.Errorf("invalid --%s pattern %q", importFlagNoTranspilePackages, pattern)
+		}
+		dt.rawPackages = append(dt.rawPackages, pattern)
+	}
+	return dt, nil
+}
+
+// transpiles reports whether the JavaScript file at filePath in the node_modules archive is transpiled
+func (dt dependencyTranspiling) transpiles(filePath string) bool {
+	if dt.none {
+		return false
+	}
+
+	name, ok := utils.DependencyPackageOf(filePath)
+	if !ok {
+		return true
+	}
+	for _, pattern := range dt.rawPackages {
+		if matched, _ := path.Match(pattern, name); matched {
+			return false
+		}
+	}
+	return true
+}
+
+// String describes which files are left as they are, and is empty when every file is transpiled
+func (dt dependencyTranspiling) String() string {
+	if dt.none {
+		return "none"
+	}
+	return strings.Join(dt.rawPackages, ",")
+}
+
+// ImportDependencies uploads the node_modules archive found in dir, transpiling its files as decided by transpiling
+func ImportDependencies(ui cli.Ui, groupID, appID, dir string, transpiling dependencyTranspiling, client api.RealmClient) error {
+	tr := transpiler.NewExternalTranspiler(transpiler.DefaultTranspilerCommand)
+
+	uploadPath, err := prepareDependenciesUpload(ui, dir, transpiling, tr)
 	if err != nil {
 		return err
 	}
 
+	// clean up after ourselves
+	defer os.Remove(uploadPath)
+
+	return client.UploadDependencies(groupID, appID, uploadPath)
+}
+
+// prepareDependenciesUpload transpiles the node_modules archive found in dir into a zip file ready to be
+// uploaded and returns its path. Every call writes to its own temporary file so that concurrent imports
+// do not collide, and the file is removed again if it could not be prepared, even on a panic
+func prepareDependenciesUpload(ui cli.Ui, dir string, transpiling dependencyTranspiling, tr transpiler.Transpiler) (uploadPath string, err error) {
+	fullPath, err := findDependenciesLocation(dir)
+	if err != nil {
+		return "", err
+	}
+
 	file, err := os.Open(fullPath)
 	if err != nil {
-		return fmt.Errorf("failed to open the dependencies file '%s': %s", fullPath, err)
+		return "", fmt.Errorf("failed to open the dependencies file '%s': %s", fullPath, err)
 	}
 
 	defer file.Close()
 	fileInfo, err := file.Stat()
 	if err != nil {
-		return errors.New("failed to read dependencies from " + fullPath)
+		return "", errors.New("failed to read dependencies from " + fullPath)
 	}
 
 	archive, err := utils.NewArchiveReader(file, fullPath, fileInfo.Size())
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	tr := transpiler.NewExternalTranspiler(transpiler.DefaultTranspilerCommand)
-
-	outFile, err := os.Create(filepath.Join(os.TempDir(), "node_modules.zip"))
+	outFile, err := ioutil.TempFile("", "node_modules-*.zip")
 	if err != nil {
-		return err
+		return "", err
 	}
-	defer outFile.Close()
+	defer func() {
+		outFile.Close()
+		if uploadPath == "" {
+			os.Remove(outFile.Name())
+		}
+	}()
 
 	w := zip.NewWriter(outFile)
 
@@ -56,7 +139,7 @@ func ImportDependencies(ui cli.Ui, groupID, appID, dir string, client api.RealmC
 			break
 		}
 		if err != nil {
-			return fmt.Errorf("failed to advance to the next entry in the archive: %s", err)
+			return "", fmt.Errorf("failed to advance to the next entry in the archive: %s", err)
 		}
 
 		if header.FileInfo().IsDir() {
@@ -71,18 +154,18 @@ func ImportDependencies(ui cli.Ui, groupID, appID, dir string, client api.RealmC
 
 		fileContents, err := ioutil.ReadAll(archive)
 		if err != nil {
-			return fmt.Errorf("failed to read file '%s' in the archive: %s", fullpath, err)
+			return "", fmt.Errorf("failed to read file '%s' in the archive: %s", fullpath, err)
 		}
 
 		ext := filepath.Ext(fullpath)
-		if ext != ".js" {
+		if ext != ".js" || !transpiling.transpiles(fullpath) {
 			f, err := w.Create(fullpath)
 			if err != nil {
-				return err
+				return "", err
 			}
 			_, err = f.Write(fileContents)
 			if err != nil {
-				return err
+				return "", err
 			}
 			continue
 		}
@@ -93,55 +176,250 @@ func ImportDependencies(ui cli.Ui, groupID, appID, dir string, client api.RealmC
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	ui.Info("transpiling dependencies started.")
-	transpiled, err := tr.Transpile(ctx, sources...)
+	if len(sources) > 0 {
+		ui.Info("transpiling dependencies started.")
+		transpiled, err := tr.Transpile(ctx, sources...)
+		if transpileErrs, ok := err.(transpiler.TranspileErrors); ok {
+			return "", dependencyTranspileErrors(ui, transpileErrs, fullNames)
+		}
+		if err != nil {
+			return "", err
+		}
+		for i, t := range transpiled {
+			f, err := w.Create(fullNames[i])
+			if err != nil {
+				return "", err
+			}
+			_, err = f.Write([]byte(t.Code))
+			if err != nil {
+				return "", err
+			}
+		}
+		ui.Info("transpiling dependencies finished.")
+	}
+
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+
+	if err := outFile.Close(); err != nil {
+		return "", err
+	}
+
+	return filepath.Abs(outFile.Name())
+}
+
+// dependencyTranspileErrors warns about each file of the node_modules archive which failed to transpile,
+// naming the package it belongs to, and returns an error listing every package that failed, so that
+// one broken package does not hide the others
+func dependencyTranspileErrors(ui cli.Ui, transpileErrs transpiler.TranspileErrors, fullNames []string) error {
+	type fileError struct {
+		path string
+		err  *transpiler.TranspileError
+	}
+
+	fileErrs := make([]fileError, 0, len(transpileErrs))
+	for _, transpileErr := range transpileErrs {
+		filePath := "unknown file"
+		if transpileErr.Index >= 0 && transpileErr.Index < len(fullNames) {
+			filePath = filepath.ToSlash(fullNames[transpileErr.Index])
+		}
+		fileErrs = append(fileErrs, fileError{filePath, transpileErr})
+	}
+	sort.SliceStable(fileErrs, func(i, j int) bool { return fileErrs[i].path < fileErrs[j].path })
+
+	var packages []string
+	seen := map[string]bool{}
+	for _, fileErr := range fileErrs {
+		name, ok := utils.DependencyPackageOf(fileErr.path)
+		if !ok {
+			name = fileErr.path
+		}
+		if !seen[name] {
+			seen[name] = true
+			packages = append(packages, name)
+		}
+
+		ui.Warn(fmt.Sprintf(
+			"Failed to transpile %s (line %d, column %d) of dependency %q: %s",
+			fileErr.path,
+			fileErr.err.Line,
+			fileErr.err.Column,
+			name,
+			fileErr.err.Message,
+		))
+	}
+	sort.Strings(packages)
+
+	return fmt.Errorf(
+		"failed to transpile %d file(s) of the dependencies %s, use --%s to upload them as they are",
+		len(fileErrs),
+		strings.Join(packages, ", "),
+		importFlagNoTranspilePackages,
+	)
+}
+
+func findDependenciesLocation(dir string) (string, error) {
+	archFile := filepath.Join(dir, "node_modules*")
+
+	matches, err := filepath.Glob(archFile)
+
 	if err != nil {
-		return err
+		return "", fmt.Errorf("failed to find a node_modules archive in the '%s' directory: %s", dir, err)
+	}
+	if len(matches) == 0 {
+		return "", fmt.Errorf("node_modules archive not found in the '%s' directory", dir)
+	}
+
+	return filepath.Abs(matches[0])
+}
+
+// dependenciesCache maps an app ID to the hash of the dependencies last uploaded for it
+type dependenciesCache map[string]string
+
+func loadDependenciesCache(path string) (dependenciesCache, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return dependenciesCache{}, nil
+		}
+		return nil, err
+	}
+
+	cache := dependenciesCache{}
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return nil, fmt.Errorf("failed to read the dependencies cache '%s': %s", path, err)
+	}
+	return cache, nil
+}
+
+// hashDependencies hashes the files in dir which make up the uploaded dependencies:
+// the node_modules archive and, if present, the package.json it was installed from.
+// Files left as they are rather than transpiled upload differently, so they are part of the hash too
+func hashDependencies(dir string, transpiling dependencyTranspiling) (string, error) {
+	archivePath, err := findDependenciesLocation(dir)
+	if err != nil {
+		return "", err
 	}
-	for i, t := range transpiled {
-		f, err := w.Create(fullNames[i])
+
+	hash := sha256.New()
+	if raw := transpiling.String(); raw != "" {
+		hash.Write([]byte("no-transpile:" + raw + "\n"))
+	}
+	for _, path := range []string{archivePath, filepath.Join(dir, "package.json")} {
+		file, err := os.Open(path)
 		if err != nil {
-			return err
+			if os.IsNotExist(err) && path != archivePath {
+				continue
+			}
+			return "", err
 		}
-		_, err = f.Write([]byte(t.Code))
+
+		_, err = io.Copy(hash, file)
+		file.Close()
 		if err != nil {
-			return err
+			return "", err
 		}
 	}
-	ui.Info("transpiling dependencies finished.")
 
-	err = w.Close()
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// dependenciesChanged reports whether the dependencies in dir differ from the ones last uploaded
+// for the app, along with their hash so that it can be recorded once they are uploaded
+func dependenciesChanged(dir, configPath, appID string, transpiling dependencyTranspiling) (string, bool, error) {
+	hash, err := hashDependencies(dir, transpiling)
 	if err != nil {
-		return err
+		return "", false, err
 	}
 
-	fp, err := filepath.Abs(outFile.Name())
+	cachePath, err := getCacheFilePath(configPath, utils.DependenciesCacheFileName)
+	if err != nil {
+		return "", false, err
+	}
+
+	cache, err := loadDependenciesCache(cachePath)
+	if err != nil {
+		return "", false, err
+	}
+
+	return hash, cache[appID] != hash, nil
+}
+
+// recordDependenciesHash remembers the hash of the dependencies uploaded for the app
+func recordDependenciesHash(configPath, appID, hash string) error {
+	cachePath, err := getCacheFilePath(configPath, utils.DependenciesCacheFileName)
 	if err != nil {
 		return err
 	}
 
-	// clean up after ourselves
-	defer os.Remove(fp)
+	cache, err := loadDependenciesCache(cachePath)
+	if err != nil {
+		return err
+	}
+	cache[appID] = hash
 
-	err = client.UploadDependencies(groupID, appID, fp)
+	data, err := json.Marshal(cache)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return ioutil.WriteFile(cachePath, data, 0600)
 }
 
-func findDependenciesLocation(dir string) (string, error) {
-	archFile := filepath.Join(dir, "node_modules*")
+// diffDependencies lists the packages of the node_modules archive in dir which were added, removed or
+// changed compared to the dependencies deployed with the app. Every package is new if none are deployed yet
+func diffDependencies(dir string, realmClient api.RealmClient, app *models.App) ([]string, error) {
+	localPath, err := findDependenciesLocation(dir)
+	if err != nil {
+		return nil, err
+	}
 
-	matches, err := filepath.Glob(archFile)
+	file, err := os.Open(localPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
 
+	fileInfo, err := file.Stat()
 	if err != nil {
-		return "", fmt.Errorf("failed to find a node_modules archive in the '%s' directory: %s", dir, err)
+		return nil, err
 	}
-	if len(matches) == 0 {
-		return "", fmt.Errorf("node_modules archive not found in the '%s' directory", dir)
+
+	localArchive, err := utils.NewArchiveReader(file, localPath, fileInfo.Size())
+	if err != nil {
+		return nil, err
 	}
 
-	return filepath.Abs(matches[0])
+	local, err := utils.ReadDependencyPackages(localArchive)
+	if err != nil {
+		return nil, err
+	}
+
+	remote := utils.DependencyPackages{}
+	filename, body, err := realmClient.ExportDependencies(app.GroupID, app.ID)
+	if err != nil {
+		// there is no archive until dependencies are first uploaded
+		if err != api.ErrNoDependencies {
+			return nil, err
+		}
+	} else if body != nil {
+		defer body.Close()
+
+		data, err := ioutil.ReadAll(body)
+		if err != nil {
+			return nil, err
+		}
+
+		remoteArchive, err := utils.NewArchiveReader(bytes.NewReader(data), filename, int64(len(data)))
+		if err != nil {
+			return nil, err
+		}
+
+		if remote, err = utils.ReadDependencyPackages(remoteArchive); err != nil {
+			return nil, err
+		}
+	}
+
+	return utils.DiffDependencyPackages(local, remote), nil
 }
@@ -1,10 +1,23 @@
 package commands
 
 import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 
+	"github.com/10gen/realm-cli/api"
+	"github.com/10gen/realm-cli/dependency/transpiler"
+	"github.com/10gen/realm-cli/models"
+	"github.com/10gen/realm-cli/user"
 	u "github.com/10gen/realm-cli/utils/test"
 	"github.com/mitchellh/cli"
 	gc "github.com/smartystreets/goconvey/convey"
@@ -20,18 +33,215 @@ func TestImportDependencies(t *testing.T) {
 			UploadDependenciesFn: func(groupID, appID, fullPath string) error {
 				u.So(t, groupID, gc.ShouldEqual, expectedGroupID)
 				u.So(t, appID, gc.ShouldEqual, expectedAppID)
-				u.So(t, fullPath, gc.ShouldContainSubstring, "node_modules.zip")
+				u.So(t, filepath.Base(fullPath), gc.ShouldStartWith, "node_modules-")
+				u.So(t, fullPath, gc.ShouldEndWith, ".zip")
 				return nil
 			},
 		}
 
 		mockUI := cli.NewMockUi()
-		err := ImportDependencies(mockUI, expectedGroupID, expectedAppID, dir, realmClient)
+		err := ImportDependencies(mockUI, expectedGroupID, expectedAppID, dir, dependencyTranspiling{}, realmClient)
 		u.So(t, err, gc.ShouldBeNil)
 	})
 
 }
 
+type fakeTranspiler struct {
+	err    error
+	prefix string
+}
+
+func (ft fakeTranspiler) Transpile(ctx context.Context, codes ...string) ([]transpiler.TranspileResult, error) {
+	if ft.err != nil {
+		return nil, ft.err
+	}
+
+	results := make([]transpiler.TranspileResult, len(codes))
+	for i, code := range codes {
+		results[i] = transpiler.TranspileResult{Code: ft.prefix + code}
+	}
+	return results, nil
+}
+
+func TestPrepareDependenciesUpload(t *testing.T) {
+	dir := "../testdata/app_with_dependencies/functions"
+
+	withTempDir := func(t *testing.T) (string, func()) {
+		tempDir, err := ioutil.TempDir("", "realm-cli-deps-")
+		u.So(t, err, gc.ShouldBeNil)
+
+		oldTempDir, hadTempDir := os.LookupEnv("TMPDIR")
+		os.Setenv("TMPDIR", tempDir)
+		return tempDir, func() {
+			if hadTempDir {
+				os.Setenv("TMPDIR", oldTempDir)
+			} else {
+				os.Unsetenv("TMPDIR")
+			}
+			os.RemoveAll(tempDir)
+		}
+	}
+
+	t.Run("should write concurrent uploads to separate files", func(t *testing.T) {
+		_, cleanup := withTempDir(t)
+		defer cleanup()
+
+		const concurrency = 2
+		var wg sync.WaitGroup
+		paths := make([]string, concurrency)
+		errs := make([]error, concurrency)
+		for i := 0; i < concurrency; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				paths[i], errs[i] = prepareDependenciesUpload(cli.NewMockUi(), dir, dependencyTranspiling{}, fakeTranspiler{})
+			}(i)
+		}
+		wg.Wait()
+
+		for i := 0; i < concurrency; i++ {
+			u.So(t, errs[i], gc.ShouldBeNil)
+			defer os.Remove(paths[i])
+
+			r, err := zip.OpenReader(paths[i])
+			u.So(t, err, gc.ShouldBeNil)
+			u.So(t, len(r.File), gc.ShouldBeGreaterThan, 0)
+			r.Close()
+		}
+		u.So(t, paths[0], gc.ShouldNotEqual, paths[1])
+	})
+
+	t.Run("should remove the upload file when it cannot be prepared", func(t *testing.T) {
+		tempDir, cleanup := withTempDir(t)
+		defer cleanup()
+
+		_, err := prepareDependenciesUpload(cli.NewMockUi(), dir, dependencyTranspiling{}, fakeTranspiler{err: errors.New("something bad happened")})
+		u.So(t, err, gc.ShouldNotBeNil)
+
+		leftovers, err := filepath.Glob(filepath.Join(tempDir, "node_modules-*"))
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, leftovers, gc.ShouldBeEmpty)
+	})
+
+	t.Run("should upload the files of the packages which are not transpiled as they are", func(t *testing.T) {
+		_, cleanup := withTempDir(t)
+		defer cleanup()
+
+		uploadPath, err := prepareDependenciesUpload(
+			cli.NewMockUi(),
+			dir,
+			dependencyTranspiling{rawPackages: []string{"debug"}},
+			fakeTranspiler{prefix: "// transpiled\n"},
+		)
+		u.So(t, err, gc.ShouldBeNil)
+		defer os.Remove(uploadPath)
+
+		r, err := zip.OpenReader(uploadPath)
+		u.So(t, err, gc.ShouldBeNil)
+		defer r.Close()
+
+		transpiled := map[string]bool{}
+		for _, file := range r.File {
+			if filepath.Ext(file.Name) != ".js" {
+				continue
+			}
+			rc, err := file.Open()
+			u.So(t, err, gc.ShouldBeNil)
+			data, err := ioutil.ReadAll(rc)
+			rc.Close()
+			u.So(t, err, gc.ShouldBeNil)
+			transpiled[file.Name] = strings.HasPrefix(string(data), "// transpiled\n")
+		}
+
+		u.So(t, transpiled["node_modules/debug/src/index.js"], gc.ShouldBeFalse)
+		u.So(t, transpiled["node_modules/debug/node_modules/ms/index.js"], gc.ShouldBeTrue)
+		u.So(t, transpiled["node_modules/axios/index.js"], gc.ShouldBeTrue)
+	})
+
+	t.Run("should not transpile anything with none", func(t *testing.T) {
+		_, cleanup := withTempDir(t)
+		defer cleanup()
+
+		uploadPath, err := prepareDependenciesUpload(
+			cli.NewMockUi(),
+			dir,
+			dependencyTranspiling{none: true},
+			fakeTranspiler{err: errors.New("should not be called")},
+		)
+		u.So(t, err, gc.ShouldBeNil)
+		os.Remove(uploadPath)
+	})
+}
+
+func TestNewDependencyTranspiling(t *testing.T) {
+	t.Run("should parse the packages which are not transpiled", func(t *testing.T) {
+		dt, err := newDependencyTranspiling(false, "lodash, @aws-sdk/*")
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, dt.rawPackages, gc.ShouldResemble, []string{"lodash", "@aws-sdk/*"})
+		u.So(t, dt.String(), gc.ShouldEqual, "lodash,@aws-sdk/*")
+
+		u.So(t, dt.transpiles("node_modules/lodash/index.js"), gc.ShouldBeFalse)
+		u.So(t, dt.transpiles("node_modules/@aws-sdk/client-s3/dist/index.js"), gc.ShouldBeFalse)
+		u.So(t, dt.transpiles("node_modules/lodash-es/index.js"), gc.ShouldBeTrue)
+		u.So(t, dt.transpiles("node_modules/@aws/client/index.js"), gc.ShouldBeTrue)
+	})
+
+	t.Run("should transpile everything by default", func(t *testing.T) {
+		dt, err := newDependencyTranspiling(false, "")
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, dt.String(), gc.ShouldBeEmpty)
+		u.So(t, dt.transpiles("node_modules/lodash/index.js"), gc.ShouldBeTrue)
+	})
+
+	t.Run("should transpile nothing with none", func(t *testing.T) {
+		dt, err := newDependencyTranspiling(true, "")
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, dt.transpiles("node_modules/lodash/index.js"), gc.ShouldBeFalse)
+	})
+
+	for _, packages := range []string{"lodash,", "[a-"} {
+		t.Run("should reject the invalid pattern list "+packages, func(t *testing.T) {
+			_, err := newDependencyTranspiling(false, packages)
+			u.So(t, err, gc.ShouldNotBeNil)
+		})
+	}
+}
+
+func TestDependencyTranspileErrors(t *testing.T) {
+	fullNames := []string{
+		"node_modules/axios/index.js",
+		"node_modules/debug/src/index.js",
+		"node_modules/debug/src/node.js",
+		"node_modules/@scope/pkg/index.js",
+	}
+
+	mockUI := cli.NewMockUi()
+	err := dependencyTranspileErrors(mockUI, transpiler.TranspileErrors{
+		{Index: 2, Message: "Unexpected token", Line: 3, Column: 4},
+		{Index: 3, Message: "Missing semicolon", Line: 1, Column: 9},
+		{Index: 1, Message: "Unexpected token", Line: 7, Column: 2},
+	}, fullNames)
+
+	u.So(t, err, gc.ShouldNotBeNil)
+	u.So(t, err.Error(), gc.ShouldEqual, "failed to transpile 3 file(s) of the dependencies @scope/pkg, debug, use --no-transpile-packages to upload them as they are")
+	u.So(t, mockUI.ErrorWriter.String(), gc.ShouldEqual, strings.Join([]string{
+		`Failed to transpile node_modules/@scope/pkg/index.js (line 1, column 9) of dependency "@scope/pkg": Missing semicolon`,
+		`Failed to transpile node_modules/debug/src/index.js (line 7, column 2) of dependency "debug": Unexpected token`,
+		`Failed to transpile node_modules/debug/src/node.js (line 3, column 4) of dependency "debug": Unexpected token`,
+		"",
+	}, "\n"))
+
+	t.Run("should fail the upload with every transpile error", func(t *testing.T) {
+		mockUI := cli.NewMockUi()
+		_, err := prepareDependenciesUpload(mockUI, "../testdata/app_with_dependencies/functions", dependencyTranspiling{}, fakeTranspiler{
+			err: transpiler.TranspileErrors{{Index: 0, Message: "Unexpected token", Line: 1, Column: 1}},
+		})
+		u.So(t, err, gc.ShouldNotBeNil)
+		u.So(t, err.Error(), gc.ShouldStartWith, "failed to transpile 1 file(s) of the dependencies")
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, "Failed to transpile node_modules/")
+	})
+}
+
 func TestFindDependenciesLocation(t *testing.T) {
 	dirAbsPath, dirErr := filepath.Abs("../testdata/app_with_dependencies/functions")
 	u.So(t, dirErr, gc.ShouldBeNil)
@@ -85,3 +295,113 @@ func TestFindDependenciesLocation(t *testing.T) {
 		})
 	}
 }
+
+func TestDependenciesChanged(t *testing.T) {
+	dir := "../testdata/app_with_dependencies/functions"
+
+	configDir, err := ioutil.TempDir("", "realm-cli-config-")
+	u.So(t, err, gc.ShouldBeNil)
+	defer os.RemoveAll(configDir)
+	configPath := filepath.Join(configDir, "config.json")
+
+	hash, changed, err := dependenciesChanged(dir, configPath, "app-id", dependencyTranspiling{})
+	u.So(t, err, gc.ShouldBeNil)
+	u.So(t, changed, gc.ShouldBeTrue)
+	u.So(t, hash, gc.ShouldNotBeEmpty)
+
+	u.So(t, recordDependenciesHash(configPath, "app-id", hash), gc.ShouldBeNil)
+
+	t.Run("should report unchanged dependencies once their hash is recorded", func(t *testing.T) {
+		_, changed, err := dependenciesChanged(dir, configPath, "app-id", dependencyTranspiling{})
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, changed, gc.ShouldBeFalse)
+	})
+
+	t.Run("should report changed dependencies when they are transpiled differently", func(t *testing.T) {
+		_, changed, err := dependenciesChanged(dir, configPath, "app-id", dependencyTranspiling{none: true})
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, changed, gc.ShouldBeTrue)
+	})
+
+	t.Run("should track the dependencies of each app separately", func(t *testing.T) {
+		_, changed, err := dependenciesChanged(dir, configPath, "other-app-id", dependencyTranspiling{})
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, changed, gc.ShouldBeTrue)
+	})
+
+	t.Run("should skip the upload when importing unchanged dependencies", func(t *testing.T) {
+		var uploads int
+		realmClient := &u.MockRealmClient{
+			ExportFn: func(groupID, appID string, strategy api.ExportStrategy) (string, io.ReadCloser, error) {
+				return "", u.NewResponseBody(bytes.NewReader([]byte{})), nil
+			},
+			ImportFn: func(ctx context.Context, groupID, appID string, appData []byte, strategy string) error {
+				return nil
+			},
+			DiffFn: func(groupID, appID string, appData []byte, strategy string) ([]string, error) {
+				return []string{}, nil
+			},
+			FetchAppByClientAppIDFn: func(clientAppID string) (*models.App, error) {
+				return &models.App{GroupID: "group-id", ID: "app-id"}, nil
+			},
+			UploadDependenciesFn: func(groupID, appID, fullPath string) error {
+				uploads++
+				return nil
+			},
+		}
+
+		for _, tc := range []struct {
+			desc           string
+			args           []string
+			expectedOutput string
+		}{
+			{
+				desc:           "without any changes to import",
+				args:           []string{"--include-dependencies"},
+				expectedOutput: "Deployed app is identical to proposed version, nothing to do.",
+			},
+			{
+				desc:           "when importing the app",
+				args:           []string{"--include-dependencies", "-y"},
+				expectedOutput: "dependencies unchanged, skipping upload",
+			},
+		} {
+			t.Run(tc.desc, func(t *testing.T) {
+				importCommand, mockUI := setUpBasicCommand()
+				importCommand.user = &user.User{
+					APIKey:      "my-api-key",
+					AccessToken: u.GenerateValidAccessToken(),
+				}
+				importCommand.realmClient = realmClient
+				importCommand.syntaxChecker = nil
+				mockUI.InputReader = strings.NewReader("y\n")
+
+				args := append([]string{"--app-id=my-app-abcdef", "--path=../testdata/full_app", "--config-path=" + configPath}, tc.args...)
+				u.So(t, recordDependenciesHash(configPath, "app-id", mustHashDependencies(t, "../testdata/full_app/functions")), gc.ShouldBeNil)
+
+				exitCode := importCommand.Run(args)
+				u.So(t, exitCode, gc.ShouldEqual, 0)
+				u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, tc.expectedOutput)
+				u.So(t, uploads, gc.ShouldEqual, 0)
+			})
+		}
+	})
+}
+
+func TestImportDependenciesTranspileFlags(t *testing.T) {
+	for _, flag := range []string{"--no-transpile-dependencies", "--no-transpile-packages=lodash"} {
+		t.Run("should require --include-dependencies with "+flag, func(t *testing.T) {
+			importCommand, mockUI := setUpBasicCommand()
+
+			exitCode := importCommand.Run([]string{"--app-id=my-app-abcdef", "--path=../testdata/full_app", flag})
+			u.So(t, exitCode, gc.ShouldEqual, 1)
+			u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, "requires --include-dependencies")
+		})
+	}
+}
+
+func mustHashDependencies(t *testing.T, dir string) string {
+	hash, err := hashDependencies(dir, dependencyTranspiling{})
+	u.So(t, err, gc.ShouldBeNil)
+	return hash
+}
@@ -0,0 +1,57 @@
+package commands
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+
+	"github.com/10gen/realm-cli/models"
+	"github.com/10gen/realm-cli/utils"
+)
+
+// appDiffCache remembers the last diff computed for an app, so that diffing an unchanged local
+// app again, e.g. on every filesystem event while watching a directory, does not call out to Realm.
+// The Admin API does not expose a version of the deployed app to key on, so the cache must be
+// invalidated whenever this command changes the deployed app
+type appDiffCache struct {
+	key      string
+	diffs    []string
+	appDiffs *utils.AppDiffs
+}
+
+// appDiffCacheKey hashes everything the computed diff depends on
+func appDiffCacheKey(app *models.App, strategy, diffAlgorithm string, renameThreshold float64, ignoredFields []string, appData []byte) string {
+	hash := sha256.New()
+	for _, part := range []string{
+		app.GroupID,
+		app.ID,
+		strategy,
+		diffAlgorithm,
+		strconv.FormatFloat(renameThreshold, 'g', -1, 64),
+		strings.Join(ignoredFields, "\n"),
+	} {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	hash.Write(appData)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// get returns the cached diff if it was computed for the given key
+func (c *appDiffCache) get(key string) ([]string, *utils.AppDiffs, bool) {
+	if c.key == "" || c.key != key {
+		return nil, nil, false
+	}
+	return append([]string(nil), c.diffs...), c.appDiffs, true
+}
+
+func (c *appDiffCache) set(key string, diffs []string, appDiffs *utils.AppDiffs) {
+	c.key = key
+	c.diffs = append([]string(nil), diffs...)
+	c.appDiffs = appDiffs
+}
+
+func (c *appDiffCache) invalidate() {
+	*c = appDiffCache{}
+}
@@ -0,0 +1,75 @@
+package commands
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/10gen/realm-cli/models"
+	"github.com/10gen/realm-cli/user"
+	u "github.com/10gen/realm-cli/utils/test"
+	gc "github.com/smartystreets/goconvey/convey"
+)
+
+func TestImportDiffCache(t *testing.T) {
+	app := &models.App{GroupID: "group-id", ID: "app-id"}
+
+	setup := func() (*ImportCommand, *int) {
+		importCommand, _ := setUpBasicCommand()
+		importCommand.flagStrategy = importStrategyMerge
+		importCommand.flagDiffAlgorithm = diffAlgorithmServer
+
+		var diffCalls int
+		importCommand.realmClient = &u.MockRealmClient{
+			DiffFn: func(groupID, appID string, appData []byte, strategy string) ([]string, error) {
+				diffCalls++
+				return []string{"sample-diff-contents"}, nil
+			},
+			ImportFn: func(ctx context.Context, groupID, appID string, appData []byte, strategy string) error {
+				return nil
+			},
+		}
+		return importCommand, &diffCalls
+	}
+
+	t.Run("it reuses the last diff while the app data is unchanged", func(t *testing.T) {
+		importCommand, diffCalls := setup()
+
+		for i := 0; i < 2; i++ {
+			diffs, _, err := importCommand.diffApp(importCommand.realmClient, app, nil, []byte(`{"name":"my-app"}`))
+			u.So(t, err, gc.ShouldBeNil)
+			u.So(t, diffs, gc.ShouldResemble, []string{"sample-diff-contents"})
+		}
+		u.So(t, *diffCalls, gc.ShouldEqual, 1)
+
+		_, _, err := importCommand.diffApp(importCommand.realmClient, app, nil, []byte(`{"name":"my-other-app"}`))
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, *diffCalls, gc.ShouldEqual, 2)
+	})
+
+	t.Run("it does not reuse a diff computed with another strategy", func(t *testing.T) {
+		importCommand, diffCalls := setup()
+
+		_, _, err := importCommand.diffApp(importCommand.realmClient, app, nil, []byte(`{"name":"my-app"}`))
+		u.So(t, err, gc.ShouldBeNil)
+
+		importCommand.flagStrategy = importStrategyReplace
+		_, _, err = importCommand.diffApp(importCommand.realmClient, app, nil, []byte(`{"name":"my-app"}`))
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, *diffCalls, gc.ShouldEqual, 2)
+	})
+
+	t.Run("it invalidates the cached diff once the app is deployed", func(t *testing.T) {
+		importCommand, mockUI := setUpBasicCommand()
+		importCommand.user = &user.User{
+			APIKey:      "my-api-key",
+			AccessToken: u.GenerateValidAccessToken(),
+		}
+		mockUI.InputReader = strings.NewReader("y\n")
+
+		exitCode := importCommand.Run([]string{"--app-id=my-app-abcdef", "--path=../testdata/full_app"})
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+		u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, "sample-diff-contents")
+		u.So(t, importCommand.diffCache, gc.ShouldResemble, appDiffCache{})
+	})
+}
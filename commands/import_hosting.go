@@ -1,57 +1,275 @@
 package commands
 
 import (
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/10gen/realm-cli/api"
 	"github.com/10gen/realm-cli/hosting"
+	"github.com/10gen/realm-cli/models"
 	"github.com/10gen/realm-cli/utils"
 
 	"github.com/mitchellh/cli"
 	"github.com/mitchellh/go-homedir"
 )
 
+// How often the status of a CDN cache invalidation is checked
+const cacheInvalidationPollInterval = time.Second
+
+// How long to wait for the CDN cache invalidations to complete before giving up on them
+const cacheInvalidationTimeout = 10 * time.Minute
+
+// allCDNCachePaths invalidates the cached copy of every hosted asset
+const allCDNCachePaths = "/*"
+
+// defaultCacheInvalidationThreshold is how many changed assets are invalidated one by one with --reset-cdn-cache
+// before the whole CDN cache is invalidated instead
+const defaultCacheInvalidationThreshold = 20
+
+// hostingOpFailure is a hosting operation which failed, along with the path of its asset
+type hostingOpFailure struct {
+	path string
+	err  error
+}
+
 // checkErrs builds a list of errors from the error channel errChan and logs them
-func checkErrs(errChan <-chan error, errDoneChan chan<- struct{}, ui cli.Ui, errors *[]error) {
-	for err := range errChan {
-		*errors = append(*errors, err)
-		ui.Error(err.Error())
+func checkErrs(errChan <-chan hostingOpFailure, errDoneChan chan<- struct{}, ui cli.Ui, failures *[]hostingOpFailure) {
+	for failure := range errChan {
+		*failures = append(*failures, failure)
+		ui.Error(failure.err.Error())
 	}
 	errDoneChan <- struct{}{}
 }
 
-// ImportHosting will push local Realm hosting assets to the server
-func ImportHosting(groupID, appID, rootDir string, assetMetadataDiffs *hosting.AssetMetadataDiffs, resetCache bool, client api.RealmClient, ui cli.Ui) error {
+// hostingOpStop keeps hosting operations from being started once one has failed, if enabled
+type hostingOpStop struct {
+	enabled bool
+	once    sync.Once
+	stopped chan struct{}
+}
+
+func newHostingOpStop(enabled bool) *hostingOpStop {
+	return &hostingOpStop{enabled: enabled, stopped: make(chan struct{})}
+}
+
+func (s *hostingOpStop) stop() {
+	if s.enabled {
+		s.once.Do(func() { close(s.stopped) })
+	}
+}
+
+func (s *hostingOpStop) isStopped() bool {
+	select {
+	case <-s.stopped:
+		return true
+	default:
+		return false
+	}
+}
+
+// diffHostingAssets compares the static assets in rootDir against those deployed for the app,
+// refreshing the local asset cache along the way. If merge is true, we ignore deleted assets.
+// If rebuildCache is true, the local asset cache is discarded and every asset is hashed again
+func diffHostingAssets(rootDir, appPath, clientAppID, configPath string, app *models.App, merge, rebuildCache bool, client api.RealmClient, ui cli.Ui) (*hosting.AssetMetadataDiffs, error) {
+	assetDescs, fileErr := hosting.MetadataFileToAssetDescriptions(filepath.Join(appPath, utils.HostingAttributes))
+	if fileErr != nil {
+		return nil, fmt.Errorf("error loading metadata.json file: %v", fileErr)
+	}
+
+	cachePath, cPErr := getCacheFilePath(configPath, utils.HostingCacheFileName)
+	if cPErr != nil {
+		return nil, cPErr
+	}
+
+	assetCache := loadAssetCache(cachePath, rebuildCache, ui)
+
+	realmIgnore, rIErr := utils.ReadRealmIgnoreFile(appPath)
+	if rIErr != nil {
+		return nil, fmt.Errorf("error loading %s file: %s", utils.RealmIgnoreFileName, rIErr)
+	}
+
+	localAssetMetadata, aMErr := hosting.ListLocalAssetMetadata(clientAppID, rootDir, assetDescs, assetCache, realmIgnore)
+	if aMErr != nil {
+		return nil, fmt.Errorf("error processing local assets %s: %s", rootDir, aMErr)
+	}
+
+	if assetCache.Dirty() {
+		if uError := hosting.UpdateCacheFile(cachePath, assetCache); uError != nil {
+			ui.Error(uError.Error())
+		}
+	}
+
+	remoteAssetMetadata, rAMErr := listRemoteAssets(configPath, app, rebuildCache, client, ui)
+	if rAMErr != nil {
+		return nil, fmt.Errorf("error retrieving remote assets: %s", rAMErr)
+	}
+
+	if err := hosting.CheckCaseCollisions(localAssetMetadata, remoteAssetMetadata, merge); err != nil {
+		return nil, err
+	}
+
+	return hosting.DiffAssetMetadata(localAssetMetadata, remoteAssetMetadata, merge), nil
+}
+
+// remoteAssetListing is a listing of the deployed hosting assets of an app, along with its ETag
+type remoteAssetListing struct {
+	ETag   string                  `json:"etag"`
+	Assets []hosting.AssetMetadata `json:"assets"`
+}
+
+// listRemoteAssets lists the deployed hosting assets of the app. The last listing of each app is kept alongside
+// the CLI config, and only downloaded again if it has changed since. If rebuildCache is true, the listing is
+// downloaded again regardless
+func listRemoteAssets(configPath string, app *models.App, rebuildCache bool, client api.RealmClient, ui cli.Ui) ([]hosting.AssetMetadata, error) {
+	cachePath, err := getCacheFilePath(configPath, utils.HostingListingCacheFileName)
+	if err != nil {
+		return nil, err
+	}
+
+	listings := loadRemoteAssetListings(cachePath, ui)
+
+	var etag string
+	if cached, ok := listings[app.ID]; ok && !rebuildCache {
+		etag = cached.ETag
+	}
+
+	assetMetadata, newETag, err := client.ListAssetsForAppIDIfChanged(app.GroupID, app.ID, etag)
+	if err == api.ErrNotModified {
+		return listings[app.ID].Assets, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	_, cached := listings[app.ID]
+	if newETag == "" && !cached {
+		return assetMetadata, nil
+	}
+
+	if newETag == "" {
+		delete(listings, app.ID)
+	} else {
+		listings[app.ID] = remoteAssetListing{ETag: newETag, Assets: assetMetadata}
+	}
+	writeRemoteAssetListings(cachePath, listings, ui)
+
+	return assetMetadata, nil
+}
+
+// forgetRemoteAssets drops the listing kept for the app, as its hosting assets have been changed
+func forgetRemoteAssets(configPath string, app *models.App, ui cli.Ui) {
+	cachePath, err := getCacheFilePath(configPath, utils.HostingListingCacheFileName)
+	if err != nil {
+		return
+	}
+
+	listings := loadRemoteAssetListings(cachePath, ui)
+	if _, ok := listings[app.ID]; !ok {
+		return
+	}
+
+	delete(listings, app.ID)
+	writeRemoteAssetListings(cachePath, listings, ui)
+}
+
+// loadRemoteAssetListings reads the listings kept at cachePath. Listings which cannot be read are treated as
+// missing, so that they are downloaded again
+func loadRemoteAssetListings(cachePath string, ui cli.Ui) map[string]remoteAssetListing {
+	listings := map[string]remoteAssetListing{}
+
+	data, err := ioutil.ReadFile(cachePath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			ui.Warn(fmt.Sprintf("Failed to read the hosting asset listings at %s: %s", cachePath, err))
+		}
+		return listings
+	}
+
+	if err := json.Unmarshal(data, &listings); err != nil {
+		ui.Warn(fmt.Sprintf("Failed to read the hosting asset listings at %s, downloading them again: %s", cachePath, err))
+		return map[string]remoteAssetListing{}
+	}
+	return listings
+}
+
+// writeRemoteAssetListings writes the listings to cachePath, warning if they cannot be written
+func writeRemoteAssetListings(cachePath string, listings map[string]remoteAssetListing, ui cli.Ui) {
+	data, err := json.Marshal(listings)
+	if err == nil {
+		err = ioutil.WriteFile(cachePath, data, 0600)
+	}
+	if err != nil {
+		ui.Warn(fmt.Sprintf("Failed to write the hosting asset listings at %s: %s", cachePath, err))
+	}
+}
+
+// loadAssetCache reads the local asset cache at cachePath. A cache that cannot be read is treated
+// as empty, so that its assets are hashed again and the cache file is rewritten afterwards
+func loadAssetCache(cachePath string, rebuildCache bool, ui cli.Ui) hosting.AssetCache {
+	if rebuildCache {
+		return hosting.NewAssetCache()
+	}
+
+	assetCache, err := hosting.CacheFileToAssetCache(cachePath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			ui.Warn(fmt.Sprintf("Failed to read the hosting asset cache at %s, rebuilding it: %s", cachePath, err))
+		}
+		return hosting.NewAssetCache()
+	}
+	return assetCache
+}
+
+// ImportHosting will push local Realm hosting assets to the server, making up to concurrency changes at once.
+// A change which fails is reported without stopping the others, unless failFast is true in which case no more
+// changes are started. Either way, the error returned names the path of every change which failed
+func ImportHosting(groupID, appID, rootDir string, assetMetadataDiffs *hosting.AssetMetadataDiffs, concurrency int, failFast bool, client api.RealmClient, ui cli.Ui) error {
 	// build a channel of hosting operations
 	var opWG sync.WaitGroup
 	opChan := make(chan hostingOp)
-	errChan := make(chan error)
+	errChan := make(chan hostingOpFailure)
 	errDoneChan := make(chan struct{})
 
-	var errors []error
-	go checkErrs(errChan, errDoneChan, ui, &errors)
+	stop := newHostingOpStop(failFast)
+	var started int32
+
+	var failures []hostingOpFailure
+	go checkErrs(errChan, errDoneChan, ui, &failures)
 
 	// create workers
-	for n := 0; n < numWorkers; n++ {
+	for n := 0; n < concurrency; n++ {
 		opWG.Add(1)
-		go hostingOpHandler(opChan, &opWG, errChan)
+		go hostingOpHandler(opChan, &opWG, errChan, stop, &started)
 	}
 
 	baseOp := baseHostingOp{groupID, appID, rootDir, client}
 	// create hosting Ops to be handled
+	var ops []hostingOp
 	for _, added := range assetMetadataDiffs.AddedLocally {
-		opChan <- &addOp{baseOp, added}
+		ops = append(ops, &addOp{baseOp, added})
 	}
 
 	for _, deleted := range assetMetadataDiffs.DeletedLocally {
-		opChan <- &deleteOp{baseOp, deleted}
+		ops = append(ops, &deleteOp{baseOp, deleted})
 	}
 
 	for _, modified := range assetMetadataDiffs.ModifiedLocally {
-		opChan <- &modifyOp{baseOp, modified}
+		ops = append(ops, &modifyOp{baseOp, modified})
+	}
+
+	for _, op := range ops {
+		if stop.isStopped() {
+			break
+		}
+		opChan <- op
 	}
 
 	close(opChan)
@@ -59,25 +277,123 @@ func ImportHosting(groupID, appID, rootDir string, assetMetadataDiffs *hosting.A
 	close(errChan)
 	<-errDoneChan
 
-	if len(errors) > 0 {
-		return fmt.Errorf("%v error(s) occurred while importing hosting assets", len(errors))
+	total := len(ops)
+	summary := fmt.Sprintf("Imported %d of %d hosting asset changes, %d failed", int(started)-len(failures), total, len(failures))
+	if skipped := total - int(started); skipped > 0 {
+		summary += fmt.Sprintf(", %d skipped after the first failure", skipped)
 	}
+	ui.Info(summary)
 
-	if resetCache {
-		if err := client.InvalidateCache(groupID, appID, "/*"); err != nil {
-			return err
+	if len(failures) > 0 {
+		paths := make([]string, 0, len(failures))
+		for _, failure := range failures {
+			paths = append(paths, failure.path)
 		}
+		sort.Strings(paths)
+
+		return fmt.Errorf("%d of %d hosting asset changes failed:\n\t%s", len(failures), total, strings.Join(paths, "\n\t"))
 	}
 
 	return nil
 }
 
-func hostingOpHandler(opChan <-chan hostingOp, opWG *sync.WaitGroup, errChan chan<- error) {
+// cdnCachePaths returns the paths of the CDN cache to invalidate after hosting assets are uploaded, given
+// as a comma separated list with --cache-paths
+func cdnCachePaths(cachePaths string) ([]string, error) {
+	if cachePaths == "" {
+		return nil, nil
+	}
+
+	var paths []string
+	for _, path := range strings.Split(cachePaths, ",") {
+		path = strings.TrimSpace(path)
+		if !strings.HasPrefix(path, "/") {
+			return nil, fmt.Errorf("invalid --%s path %q, each path must start with \"/\"", importFlagCachePaths, path)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// changedCDNCachePaths returns the paths of the CDN cache to invalidate for --reset-cdn-cache, which are those
+// of the assets added, modified or removed. Past threshold changed assets every path is invalidated at once instead
+func changedCDNCachePaths(assetMetadataDiffs *hosting.AssetMetadataDiffs, threshold int) []string {
+	var paths []string
+	for _, added := range assetMetadataDiffs.AddedLocally {
+		paths = append(paths, added.FilePath)
+	}
+	for _, deleted := range assetMetadataDiffs.DeletedLocally {
+		paths = append(paths, deleted.FilePath)
+	}
+	for _, modified := range assetMetadataDiffs.ModifiedLocally {
+		paths = append(paths, modified.AssetMetadata.FilePath)
+	}
+
+	if len(paths) > threshold {
+		return []string{allCDNCachePaths}
+	}
+
+	sort.Strings(paths)
+	return paths
+}
+
+// InvalidateHostingCache invalidates the CDN cache of the given paths, checking every pollInterval
+// until each invalidation has completed, and failing if they have not all completed within timeout
+func InvalidateHostingCache(groupID, appID string, paths []string, pollInterval, timeout time.Duration, client api.RealmClient, ui cli.Ui) error {
+	started := time.Now()
+
+	var jobIDs []string
+	for _, path := range paths {
+		jobID, err := client.InvalidateCache(groupID, appID, path)
+		if err != nil {
+			return fmt.Errorf("failed to invalidate the CDN cache of %s: %s", path, err)
+		}
+		// the invalidation of a path which is not tracked completes on its own
+		if jobID != "" {
+			jobIDs = append(jobIDs, jobID)
+		}
+	}
+
+	if len(jobIDs) > 0 {
+		ui.Info("Invalidating CDN cache...")
+	}
+
+	for _, jobID := range jobIDs {
+		for {
+			invalidation, err := client.GetCacheInvalidation(groupID, appID, jobID)
+			if err != nil {
+				return fmt.Errorf("failed to check the CDN cache invalidation %s: %s", jobID, err)
+			}
+
+			if invalidation.Status == models.CacheInvalidationStatusFailed {
+				return fmt.Errorf("failed to invalidate the CDN cache of %s", invalidation.Path)
+			}
+			if invalidation.Status != models.CacheInvalidationStatusInProgress {
+				break
+			}
+
+			if time.Since(started) >= timeout {
+				return fmt.Errorf("failed to invalidate the CDN cache of %s: the invalidation did not complete within %s", invalidation.Path, timeout)
+			}
+			time.Sleep(pollInterval)
+		}
+	}
+
+	return nil
+}
+
+func hostingOpHandler(opChan <-chan hostingOp, opWG *sync.WaitGroup, errChan chan<- hostingOpFailure, stop *hostingOpStop, started *int32) {
 	defer opWG.Done()
 
 	for op := range opChan {
+		if stop.isStopped() {
+			continue
+		}
+
+		atomic.AddInt32(started, 1)
 		if doErr := op.Do(); doErr != nil {
-			errChan <- doErr
+			stop.stop()
+			errChan <- hostingOpFailure{op.Path(), doErr}
 			continue
 		}
 	}
@@ -93,6 +409,7 @@ type baseHostingOp struct {
 // hostingOp represents an import operation done with hosting assets
 type hostingOp interface {
 	Do() error
+	Path() string
 }
 
 type addOp struct {
@@ -105,6 +422,11 @@ func (op *addOp) Do() error {
 	return doUpload(op.groupID, op.appID, op.rootDir, op.client, op.assetMetadata)
 }
 
+// Path returns the path of the added asset
+func (op *addOp) Path() string {
+	return op.assetMetadata.FilePath
+}
+
 type deleteOp struct {
 	baseHostingOp
 	assetMetadata hosting.AssetMetadata
@@ -119,6 +441,11 @@ func (op *deleteOp) Do() error {
 	return nil
 }
 
+// Path returns the path of the deleted asset
+func (op *deleteOp) Path() string {
+	return op.assetMetadata.FilePath
+}
+
 type modifyOp struct {
 	baseHostingOp
 	modifiedAssetMetadata hosting.ModifiedAssetMetadata
@@ -149,6 +476,11 @@ func (op *modifyOp) Do() error {
 	return nil
 }
 
+// Path returns the path of the modified asset
+func (op *modifyOp) Path() string {
+	return op.modifiedAssetMetadata.AssetMetadata.FilePath
+}
+
 func doUpload(groupID, appID, rootDir string, client api.RealmClient, am hosting.AssetMetadata) error {
 	errStrF := "uploading '%s' failed => %s"
 
@@ -165,7 +497,64 @@ func doUpload(groupID, appID, rootDir string, client api.RealmClient, am hosting
 	return nil
 }
 
-func getAssetCachePath(configPath string) (string, error) {
+// hostingChanges breaks the hosting changes down by path, each sorted
+type hostingChanges struct {
+	Added    []string               `json:"added"`
+	Removed  []string               `json:"removed"`
+	Modified []modifiedHostingAsset `json:"modified"`
+}
+
+// modifiedHostingAsset tells what changed of a modified hosting asset
+type modifiedHostingAsset struct {
+	Path       string `json:"path"`
+	Body       bool   `json:"body"`
+	Attributes bool   `json:"attributes"`
+}
+
+func newHostingChanges(assetMetadataDiffs *hosting.AssetMetadataDiffs) *hostingChanges {
+	changes := hostingChanges{Added: []string{}, Removed: []string{}, Modified: []modifiedHostingAsset{}}
+	for _, added := range assetMetadataDiffs.AddedLocally {
+		changes.Added = append(changes.Added, added.FilePath)
+	}
+	for _, deleted := range assetMetadataDiffs.DeletedLocally {
+		changes.Removed = append(changes.Removed, deleted.FilePath)
+	}
+	for _, modified := range assetMetadataDiffs.ModifiedLocally {
+		changes.Modified = append(changes.Modified, modifiedHostingAsset{
+			Path:       modified.AssetMetadata.FilePath,
+			Body:       modified.BodyModified,
+			Attributes: modified.AttrModified,
+		})
+	}
+
+	sort.Strings(changes.Added)
+	sort.Strings(changes.Removed)
+	sort.SliceStable(changes.Modified, func(i, j int) bool { return changes.Modified[i].Path < changes.Modified[j].Path })
+	return &changes
+}
+
+// summary counts the hosting changes, e.g. to close the diff of a dry run
+func (hc *hostingChanges) summary() string {
+	if len(hc.Added)+len(hc.Removed)+len(hc.Modified) == 0 {
+		return "Hosting: no changes."
+	}
+
+	var attributesOnly int
+	for _, modified := range hc.Modified {
+		if modified.Attributes && !modified.Body {
+			attributesOnly++
+		}
+	}
+
+	summary := fmt.Sprintf("Hosting: %d to add, %d to remove, %d to modify", len(hc.Added), len(hc.Removed), len(hc.Modified))
+	if attributesOnly > 0 {
+		summary += fmt.Sprintf(" (%d attributes only)", attributesOnly)
+	}
+	return summary + "."
+}
+
+// getCacheFilePath returns the path of the named cache file, which is kept alongside the CLI config
+func getCacheFilePath(configPath, fileName string) (string, error) {
 	cachePath, eErr := homedir.Expand(configPath)
 	if eErr != nil {
 		return "", eErr
@@ -181,5 +570,5 @@ func getAssetCachePath(configPath string) (string, error) {
 		cachePath = filepath.Dir(cachePath)
 	}
 
-	return filepath.Join(cachePath, utils.HostingCacheFileName), nil
+	return filepath.Join(cachePath, fileName), nil
 }
@@ -1,15 +1,24 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"path/filepath"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/10gen/realm-cli/api"
 	"github.com/10gen/realm-cli/hosting"
+	"github.com/10gen/realm-cli/models"
+	"github.com/10gen/realm-cli/user"
+	"github.com/10gen/realm-cli/utils"
 	u "github.com/10gen/realm-cli/utils/test"
 	gc "github.com/smartystreets/goconvey/convey"
 
@@ -52,7 +61,7 @@ func TestImportHosting(t *testing.T) {
 		}
 		testServer := httptest.NewServer(http.HandlerFunc(testHandler))
 		testClient := api.NewRealmClient(api.NewClient(testServer.URL))
-		u.So(t, ImportHosting("groupID", "appID", rootDir, assetMetadataDiffs, false, testClient, cli.NewMockUi()), gc.ShouldBeNil)
+		u.So(t, ImportHosting("groupID", "appID", rootDir, assetMetadataDiffs, numWorkers, false, testClient, cli.NewMockUi()), gc.ShouldBeNil)
 	})
 
 	t.Run("should log errors correctly", func(t *testing.T) {
@@ -63,11 +72,182 @@ func TestImportHosting(t *testing.T) {
 		testClient := api.NewRealmClient(api.NewClient(testServer.URL))
 
 		mockUI := cli.NewMockUi()
-		importErr := ImportHosting("groupID", "appID", rootDir, assetMetadataDiffs, false, testClient, mockUI)
+		importErr := ImportHosting("groupID", "appID", rootDir, assetMetadataDiffs, numWorkers, false, testClient, mockUI)
 		u.So(t, importErr, gc.ShouldNotBeNil)
 		u.So(t, importErr.Error(), gc.ShouldContainSubstring, "3")
 		u.So(t, len(strings.Split(mockUI.ErrorWriter.String(), "\n"))-1, gc.ShouldEqual, 3)
 	})
+
+	t.Run("should count the changes that failed while others succeeded concurrently", func(t *testing.T) {
+		testHandler := func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodDelete {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}
+		testServer := httptest.NewServer(http.HandlerFunc(testHandler))
+		testClient := api.NewRealmClient(api.NewClient(testServer.URL))
+
+		for _, concurrency := range []int{1, 3} {
+			mockUI := cli.NewMockUi()
+			importErr := ImportHosting("groupID", "appID", rootDir, assetMetadataDiffs, concurrency, false, testClient, mockUI)
+			u.So(t, importErr, gc.ShouldNotBeNil)
+			u.So(t, importErr.Error(), gc.ShouldEqual, "1 of 3 hosting asset changes failed:\n\t/deleteMe")
+			u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, "Imported 2 of 3 hosting asset changes, 1 failed")
+		}
+	})
+
+	t.Run("should stop starting changes after the first failure with fail fast", func(t *testing.T) {
+		var requests int32
+		testHandler := func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		testServer := httptest.NewServer(http.HandlerFunc(testHandler))
+		testClient := api.NewRealmClient(api.NewClient(testServer.URL))
+
+		mockUI := cli.NewMockUi()
+		importErr := ImportHosting("groupID", "appID", rootDir, assetMetadataDiffs, 1, true, testClient, mockUI)
+		u.So(t, importErr, gc.ShouldNotBeNil)
+		u.So(t, importErr.Error(), gc.ShouldStartWith, "1 of 3 hosting asset changes failed:")
+		u.So(t, atomic.LoadInt32(&requests), gc.ShouldEqual, 1)
+		u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, "Imported 0 of 3 hosting asset changes, 1 failed, 2 skipped after the first failure")
+	})
+}
+
+func TestCDNCachePaths(t *testing.T) {
+	for _, tc := range []struct {
+		Description   string
+		CachePaths    string
+		ExpectedPaths []string
+		ExpectedError string
+	}{
+		{
+			Description: "it invalidates nothing by default",
+		},
+		{
+			Description:   "it invalidates the paths given with --cache-paths",
+			CachePaths:    "/index.html, /static/*",
+			ExpectedPaths: []string{"/index.html", "/static/*"},
+		},
+		{
+			Description:   "it rejects a path which does not start with a slash",
+			CachePaths:    "/index.html,static/*",
+			ExpectedError: `invalid --cache-paths path "static/*", each path must start with "/"`,
+		},
+	} {
+		t.Run(tc.Description, func(t *testing.T) {
+			paths, err := cdnCachePaths(tc.CachePaths)
+			if tc.ExpectedError != "" {
+				u.So(t, err, gc.ShouldBeError, tc.ExpectedError)
+				return
+			}
+			u.So(t, err, gc.ShouldBeNil)
+			u.So(t, paths, gc.ShouldResemble, tc.ExpectedPaths)
+		})
+	}
+}
+
+func TestChangedCDNCachePaths(t *testing.T) {
+	assetMetadataDiffs := &hosting.AssetMetadataDiffs{
+		AddedLocally:   []hosting.AssetMetadata{{FilePath: "/new.html"}},
+		DeletedLocally: []hosting.AssetMetadata{{FilePath: "/old.html"}},
+		ModifiedLocally: []hosting.ModifiedAssetMetadata{
+			{AssetMetadata: hosting.AssetMetadata{FilePath: "/index.html"}, BodyModified: true},
+			{AssetMetadata: hosting.AssetMetadata{FilePath: "/app.js"}, AttrModified: true},
+		},
+	}
+
+	t.Run("should invalidate the paths of the changed assets", func(t *testing.T) {
+		paths := changedCDNCachePaths(assetMetadataDiffs, 4)
+		u.So(t, paths, gc.ShouldResemble, []string{"/app.js", "/index.html", "/new.html", "/old.html"})
+	})
+
+	t.Run("should invalidate every path past the threshold", func(t *testing.T) {
+		u.So(t, changedCDNCachePaths(assetMetadataDiffs, 3), gc.ShouldResemble, []string{"/*"})
+	})
+
+	t.Run("should invalidate nothing when no assets changed", func(t *testing.T) {
+		u.So(t, changedCDNCachePaths(&hosting.AssetMetadataDiffs{}, 0), gc.ShouldBeEmpty)
+	})
+}
+
+func TestInvalidateHostingCache(t *testing.T) {
+	t.Run("should wait for every invalidation to complete", func(t *testing.T) {
+		var invalidated []string
+		checks := map[string]int{}
+		client := &u.MockRealmClient{
+			InvalidateCacheFn: func(groupID, appID, path string) (string, error) {
+				invalidated = append(invalidated, path)
+				return "job" + path, nil
+			},
+			GetCacheInvalidationFn: func(groupID, appID, jobID string) (*models.CacheInvalidation, error) {
+				checks[jobID]++
+				status := models.CacheInvalidationStatusInProgress
+				if checks[jobID] == 2 {
+					status = models.CacheInvalidationStatusCompleted
+				}
+				return &models.CacheInvalidation{ID: jobID, Status: status}, nil
+			},
+		}
+
+		mockUI := cli.NewMockUi()
+		err := InvalidateHostingCache("groupID", "appID", []string{"/index.html", "/static/*"}, 0, time.Minute, client, mockUI)
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, invalidated, gc.ShouldResemble, []string{"/index.html", "/static/*"})
+		u.So(t, checks, gc.ShouldResemble, map[string]int{"job/index.html": 2, "job/static/*": 2})
+		u.So(t, mockUI.OutputWriter.String(), gc.ShouldEqual, "Invalidating CDN cache...\n")
+	})
+
+	t.Run("should not wait for an invalidation which is not tracked", func(t *testing.T) {
+		client := &u.MockRealmClient{
+			GetCacheInvalidationFn: func(groupID, appID, jobID string) (*models.CacheInvalidation, error) {
+				return nil, fmt.Errorf("unexpected check of invalidation %s", jobID)
+			},
+		}
+
+		u.So(t, InvalidateHostingCache("groupID", "appID", []string{"/*"}, 0, time.Minute, client, cli.NewMockUi()), gc.ShouldBeNil)
+	})
+
+	t.Run("should fail when an invalidation fails", func(t *testing.T) {
+		client := &u.MockRealmClient{
+			InvalidateCacheFn: func(groupID, appID, path string) (string, error) {
+				return "job", nil
+			},
+			GetCacheInvalidationFn: func(groupID, appID, jobID string) (*models.CacheInvalidation, error) {
+				return &models.CacheInvalidation{ID: jobID, Path: "/*", Status: models.CacheInvalidationStatusFailed}, nil
+			},
+		}
+
+		err := InvalidateHostingCache("groupID", "appID", []string{"/*"}, 0, time.Minute, client, cli.NewMockUi())
+		u.So(t, err, gc.ShouldBeError, "failed to invalidate the CDN cache of /*")
+	})
+
+	t.Run("should fail when the invalidation does not complete in time", func(t *testing.T) {
+		client := &u.MockRealmClient{
+			InvalidateCacheFn: func(groupID, appID, path string) (string, error) {
+				return "job", nil
+			},
+			GetCacheInvalidationFn: func(groupID, appID, jobID string) (*models.CacheInvalidation, error) {
+				return &models.CacheInvalidation{ID: jobID, Path: "/*", Status: models.CacheInvalidationStatusInProgress}, nil
+			},
+		}
+
+		err := InvalidateHostingCache("groupID", "appID", []string{"/*"}, time.Millisecond, 5*time.Millisecond, client, cli.NewMockUi())
+		u.So(t, err, gc.ShouldBeError, "failed to invalidate the CDN cache of /*: the invalidation did not complete within 5ms")
+	})
+
+	t.Run("should fail when the invalidation cannot be requested", func(t *testing.T) {
+		client := &u.MockRealmClient{
+			InvalidateCacheFn: func(groupID, appID, path string) (string, error) {
+				return "", fmt.Errorf("oh noes")
+			},
+		}
+
+		err := InvalidateHostingCache("groupID", "appID", []string{"/*"}, 0, time.Minute, client, cli.NewMockUi())
+		u.So(t, err, gc.ShouldBeError, "failed to invalidate the CDN cache of /*: oh noes")
+	})
 }
 
 func TestHostingOp(t *testing.T) {
@@ -241,3 +421,142 @@ func TestHostingOp(t *testing.T) {
 	})
 
 }
+
+func TestImportDiffsHostingConcurrently(t *testing.T) {
+	// the hosting asset cache is kept next to the config
+	configDir, err := ioutil.TempDir("", "realm-import-hosting-")
+	u.So(t, err, gc.ShouldBeNil)
+	defer os.RemoveAll(configDir)
+
+	args := []string{"--app-id=my-app-abcdef", "--path=../testdata/full_app", "--include-hosting", "--config-path=" + filepath.Join(configDir, "realm")}
+
+	setup := func(listAssets func(groupID, appID string) ([]hosting.AssetMetadata, error)) (*ImportCommand, *u.MockRealmClient, *cli.MockUi) {
+		importCommand, mockUI := setUpBasicCommand()
+		importCommand.user = &user.User{
+			APIKey:      "my-api-key",
+			AccessToken: u.GenerateValidAccessToken(),
+		}
+
+		realmClient := setUpBasicRealmClient()
+		realmClient.ListAssetsForAppIDFn = listAssets
+		realmClient.UploadAssetFn = func(groupID, appID, path, hash string, size int64, body io.Reader, attributes ...hosting.AssetAttribute) error {
+			return nil
+		}
+		importCommand.realmClient = realmClient
+
+		return importCommand, realmClient, mockUI
+	}
+
+	t.Run("it lists the hosting assets while the app is diffed", func(t *testing.T) {
+		listed := make(chan struct{})
+		importCommand, realmClient, mockUI := setup(func(groupID, appID string) ([]hosting.AssetMetadata, error) {
+			close(listed)
+			return nil, nil
+		})
+		realmClient.DiffFn = func(groupID, appID string, appData []byte, strategy string) ([]string, error) {
+			select {
+			case <-listed:
+				return []string{"sample-diff-contents"}, nil
+			case <-time.After(5 * time.Second):
+				return nil, errors.New("the hosting assets were not listed while diffing")
+			}
+		}
+		mockUI.InputReader = strings.NewReader("y\n")
+
+		exitCode := importCommand.Run(args)
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+	})
+
+	t.Run("it reports both failures when both diffs fail", func(t *testing.T) {
+		importCommand, realmClient, mockUI := setup(func(groupID, appID string) ([]hosting.AssetMetadata, error) {
+			return nil, errors.New("assets unavailable")
+		})
+		realmClient.DiffFn = func(groupID, appID string, appData []byte, strategy string) ([]string, error) {
+			return nil, errors.New("diff unavailable")
+		}
+
+		exitCode := importCommand.Run(args)
+		u.So(t, exitCode, gc.ShouldEqual, 1)
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring,
+			"failed to diff app with currently deployed instance: diff unavailable; --include-hosting error: error retrieving remote assets: assets unavailable")
+	})
+
+	t.Run("it reports a failure to list the hosting assets", func(t *testing.T) {
+		importCommand, _, mockUI := setup(func(groupID, appID string) ([]hosting.AssetMetadata, error) {
+			return nil, errors.New("assets unavailable")
+		})
+
+		exitCode := importCommand.Run(args)
+		u.So(t, exitCode, gc.ShouldEqual, 1)
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, "--include-hosting error: error retrieving remote assets: assets unavailable")
+	})
+}
+
+func TestListRemoteAssets(t *testing.T) {
+	configDir, err := ioutil.TempDir("", "realm-hosting-listing-")
+	u.So(t, err, gc.ShouldBeNil)
+	defer os.RemoveAll(configDir)
+
+	configPath := filepath.Join(configDir, "realm")
+	app := &models.App{GroupID: "group-id", ID: "app-id"}
+	listing := []hosting.AssetMetadata{{AppID: "app-id", FilePath: "/foo.txt", FileHash: "abc", FileSize: 3}}
+
+	var sentETags []string
+	realmClient := &u.MockRealmClient{
+		ListAssetsForAppIDIfChangedFn: func(groupID, appID, etag string) ([]hosting.AssetMetadata, string, error) {
+			sentETags = append(sentETags, etag)
+			if etag == "listing-1" {
+				return nil, "", api.ErrNotModified
+			}
+			return listing, "listing-1", nil
+		},
+	}
+
+	t.Run("it downloads the listing when none is kept", func(t *testing.T) {
+		mockUI := cli.NewMockUi()
+		assetMetadata, err := listRemoteAssets(configPath, app, false, realmClient, mockUI)
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, assetMetadata, gc.ShouldResemble, listing)
+		u.So(t, sentETags, gc.ShouldResemble, []string{""})
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+	})
+
+	t.Run("it reuses the kept listing when it has not changed", func(t *testing.T) {
+		sentETags = nil
+		assetMetadata, err := listRemoteAssets(configPath, app, false, realmClient, cli.NewMockUi())
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, assetMetadata, gc.ShouldResemble, listing)
+		u.So(t, sentETags, gc.ShouldResemble, []string{"listing-1"})
+	})
+
+	t.Run("it downloads the listing again when the cache is rebuilt", func(t *testing.T) {
+		sentETags = nil
+		_, err := listRemoteAssets(configPath, app, true, realmClient, cli.NewMockUi())
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, sentETags, gc.ShouldResemble, []string{""})
+	})
+
+	t.Run("it downloads the listing again once the assets are uploaded", func(t *testing.T) {
+		forgetRemoteAssets(configPath, app, cli.NewMockUi())
+
+		sentETags = nil
+		_, err := listRemoteAssets(configPath, app, false, realmClient, cli.NewMockUi())
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, sentETags, gc.ShouldResemble, []string{""})
+	})
+
+	t.Run("it ignores a listing which cannot be read", func(t *testing.T) {
+		cachePath, err := getCacheFilePath(configPath, utils.HostingListingCacheFileName)
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, ioutil.WriteFile(cachePath, []byte("not json"), 0600), gc.ShouldBeNil)
+
+		mockUI := cli.NewMockUi()
+		sentETags = nil
+		assetMetadata, err := listRemoteAssets(configPath, app, false, realmClient, mockUI)
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, assetMetadata, gc.ShouldResemble, listing)
+		u.So(t, sentETags, gc.ShouldResemble, []string{""})
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, "Failed to read the hosting asset listings")
+	})
+}
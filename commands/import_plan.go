@@ -0,0 +1,184 @@
+package commands
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+
+	"github.com/10gen/realm-cli/models"
+
+	"github.com/mitchellh/go-homedir"
+)
+
+const importPlanVersion = 1
+
+// importPlan records the changes an import computed for an app along with everything they depend on,
+// so that the import can be reviewed and then applied separately, but only as it was reviewed
+type importPlan struct {
+	Version int `json:"version"`
+
+	GroupID     string `json:"group_id"`
+	AppID       string `json:"app_id"`
+	ClientAppID string `json:"client_app_id"`
+	AppPath     string `json:"app_path"`
+
+	Strategy            string   `json:"strategy"`
+	DiffAlgorithm       string   `json:"diff_algorithm"`
+	NoRenameDetection   bool     `json:"no_rename_detection,omitempty"`
+	RenameThreshold     float64  `json:"rename_threshold"`
+	Baseline            string   `json:"baseline,omitempty"`
+	IgnoreFields        []string `json:"ignore_fields,omitempty"`
+	IncludeHosting      bool     `json:"include_hosting,omitempty"`
+	IncludeDependencies bool     `json:"include_dependencies,omitempty"`
+	Only                string   `json:"only,omitempty"`
+	ConfigVersion       string   `json:"config_version,omitempty"`
+	Strict              bool     `json:"strict,omitempty"`
+	StrictConfigVersion bool     `json:"strict_config_version,omitempty"`
+
+	AllowVersionMismatch bool `json:"allow_version_mismatch,omitempty"`
+	AllowDestructive     bool `json:"allow_destructive,omitempty"`
+
+	// AppDataHash identifies the local app the plan was made from
+	AppDataHash string `json:"app_data_hash"`
+
+	Diffs []string `json:"diffs"`
+	Steps []string `json:"steps"`
+}
+
+// newImportPlan returns the plan to import appData into the app with the given diffs
+func (ic *ImportCommand) newImportPlan(app *models.App, clientAppID, appPath string, appData []byte, diffs []string, includesHosting, uploadDependencies bool) (*importPlan, error) {
+	// the directory a zipped app is extracted into is removed once planned, so it is extracted again to apply
+	if ic.appArchivePath != "" {
+		archivePath, err := homedir.Expand(ic.appArchivePath)
+		if err != nil {
+			return nil, err
+		}
+		appPath = archivePath
+	}
+
+	absAppPath, err := filepath.Abs(appPath)
+	if err != nil {
+		return nil, err
+	}
+
+	steps := []string{
+		"Create a draft of the app",
+		fmt.Sprintf("Import the app with the %s strategy", ic.flagStrategy),
+		"Deploy the draft",
+	}
+	if includesHosting {
+		steps = append(steps, "Upload the changed hosting assets")
+	}
+	if uploadDependencies {
+		steps = append(steps, "Upload the dependencies")
+	}
+
+	return &importPlan{
+		Version:             importPlanVersion,
+		GroupID:             app.GroupID,
+		AppID:               app.ID,
+		ClientAppID:         clientAppID,
+		AppPath:             absAppPath,
+		Strategy:            ic.flagStrategy,
+		DiffAlgorithm:       ic.flagDiffAlgorithm,
+		NoRenameDetection:   ic.flagNoRenameDetection,
+		RenameThreshold:     ic.flagRenameThreshold,
+		Baseline:            ic.flagBaseline,
+		IgnoreFields:        ic.flagIgnoreFields,
+		IncludeHosting:      ic.flagIncludeHosting,
+		IncludeDependencies: ic.flagIncludeDependencies,
+		Only:                ic.flagOnly,
+		ConfigVersion:       ic.flagConfigVersion,
+		Strict:              ic.flagStrict,
+		StrictConfigVersion: ic.flagStrictConfigVersion,
+
+		AllowVersionMismatch: ic.flagAllowMismatch,
+		AllowDestructive:     ic.flagAllowDestructive,
+		AppDataHash:          hashAppData(appData),
+		Diffs:                diffs,
+		Steps:                steps,
+	}, nil
+}
+
+// importCommand returns an ImportCommand set up to make the same import the plan was made for
+func (p *importPlan) importCommand(ic *ImportCommand) *ImportCommand {
+	ic.flagAppID = p.ClientAppID
+	ic.flagAppPath = p.AppPath
+	ic.flagGroupID = p.GroupID
+	ic.flagStrategy = p.Strategy
+	ic.flagDiffAlgorithm = p.DiffAlgorithm
+	ic.flagNoRenameDetection = p.NoRenameDetection
+	ic.flagRenameThreshold = p.RenameThreshold
+	ic.flagBaseline = p.Baseline
+	ic.flagIgnoreFields = p.IgnoreFields
+	ic.flagIncludeHosting = p.IncludeHosting
+	ic.flagIncludeDependencies = p.IncludeDependencies
+	ic.flagOnly = p.Only
+	ic.flagConfigVersion = p.ConfigVersion
+	ic.flagStrict = p.Strict
+	ic.flagStrictConfigVersion = p.StrictConfigVersion
+	ic.flagAllowMismatch = p.AllowVersionMismatch
+	ic.flagAllowDestructive = p.AllowDestructive
+	ic.plan = p
+	return ic
+}
+
+// check returns an error unless importing appData into the app would still make the planned changes
+func (p *importPlan) check(app *models.App, appData []byte, diffs []string) error {
+	if app.GroupID != p.GroupID || app.ID != p.AppID {
+		return fmt.Errorf("the plan was made for the app %s in project %s", p.ClientAppID, p.GroupID)
+	}
+
+	if hashAppData(appData) != p.AppDataHash {
+		return fmt.Errorf("the local app at %s has changed since the plan was made, make a new plan with 'realm-cli import --%s'", p.AppPath, importFlagPlanFile)
+	}
+
+	if len(diffs) != len(p.Diffs) || (len(diffs) > 0 && !reflect.DeepEqual(diffs, p.Diffs)) {
+		return fmt.Errorf("the deployed app has changed since the plan was made, make a new plan with 'realm-cli import --%s'", importFlagPlanFile)
+	}
+
+	return nil
+}
+
+func hashAppData(appData []byte) string {
+	hash := sha256.Sum256(appData)
+	return hex.EncodeToString(hash[:])
+}
+
+func writeImportPlan(path string, plan *importPlan) error {
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write plan: %s", err)
+	}
+	return nil
+}
+
+func readImportPlan(path string) (*importPlan, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read plan: %s does not exist", path)
+		}
+		return nil, fmt.Errorf("failed to read plan: %s", err)
+	}
+
+	var plan importPlan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("failed to read plan: %s", err)
+	}
+
+	if plan.Version != importPlanVersion {
+		return nil, fmt.Errorf("failed to read plan: unsupported version %d, make a new plan with this version of realm-cli", plan.Version)
+	}
+
+	return &plan, nil
+}
@@ -0,0 +1,87 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/10gen/realm-cli/dependency/transpiler"
+	"github.com/mitchellh/cli"
+)
+
+// functionSource is the source code of a function or incoming webhook in the app configuration
+type functionSource struct {
+	name   string
+	source string
+}
+
+// functionSources returns the source code of every function and incoming webhook in the loaded app
+func functionSources(app map[string]interface{}) []functionSource {
+	var sources []functionSource
+
+	addSources := func(prefix string, fns interface{}) {
+		fnList, _ := fns.([]interface{})
+		for _, fn := range fnList {
+			fnMap, _ := fn.(map[string]interface{})
+			source, ok := fnMap["source"].(string)
+			if !ok {
+				continue
+			}
+			config, _ := fnMap["config"].(map[string]interface{})
+			name, _ := config["name"].(string)
+			sources = append(sources, functionSource{prefix + name, source})
+		}
+	}
+
+	addSources("", app["functions"])
+
+	services, _ := app["services"].([]interface{})
+	for _, svc := range services {
+		svcMap, _ := svc.(map[string]interface{})
+		config, _ := svcMap["config"].(map[string]interface{})
+		name, _ := config["name"].(string)
+		addSources(name+"/", svcMap["incoming_webhooks"])
+	}
+
+	return sources
+}
+
+// checkFunctionSyntax parses the source of every function in the loaded app with the transpiler,
+// returning an error describing each function that could not be parsed. If the transpiler itself
+// cannot be run, the check is skipped
+func checkFunctionSyntax(tr transpiler.Transpiler, app map[string]interface{}, ui cli.Ui) error {
+	fns := functionSources(app)
+	if len(fns) == 0 {
+		return nil
+	}
+
+	sources := make([]string, 0, len(fns))
+	for _, fn := range fns {
+		sources = append(sources, fn.source)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, err := tr.Transpile(ctx, sources...)
+	if err == nil {
+		return nil
+	}
+
+	transpileErrs, ok := err.(transpiler.TranspileErrors)
+	if !ok {
+		ui.Info(fmt.Sprintf("Skipping function syntax check, use --%s to silence this message: %s", importFlagNoSyntaxCheck, err))
+		return nil
+	}
+
+	msgs := make([]string, 0, len(transpileErrs))
+	for _, transpileErr := range transpileErrs {
+		name := "unknown function"
+		if transpileErr.Index >= 0 && transpileErr.Index < len(fns) {
+			name = fmt.Sprintf("function %q", fns[transpileErr.Index].name)
+		}
+		msgs = append(msgs, fmt.Sprintf("\t%s: %s (line %d, column %d)", name, transpileErr.Message, transpileErr.Line, transpileErr.Column))
+	}
+
+	return fmt.Errorf("failed to parse function source:\n%s", strings.Join(msgs, "\n"))
+}
@@ -0,0 +1,74 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/10gen/realm-cli/dependency/transpiler"
+	"github.com/10gen/realm-cli/user"
+	u "github.com/10gen/realm-cli/utils/test"
+	gc "github.com/smartystreets/goconvey/convey"
+
+	"github.com/mitchellh/cli"
+)
+
+type fakeSyntaxChecker struct {
+	calls int
+	err   error
+}
+
+func (fsc *fakeSyntaxChecker) Transpile(ctx context.Context, codes ...string) ([]transpiler.TranspileResult, error) {
+	fsc.calls++
+	return nil, fsc.err
+}
+
+func TestImportSyntaxCheck(t *testing.T) {
+	args := []string{"--app-id=my-app-abcdef", "--path=../testdata/full_app", "-y"}
+
+	setup := func(checker *fakeSyntaxChecker) *ImportCommand {
+		importCommand, _ := setUpBasicCommand()
+		importCommand.user = &user.User{
+			APIKey:      "my-api-key",
+			AccessToken: u.GenerateValidAccessToken(),
+		}
+		importCommand.syntaxChecker = checker
+		return importCommand
+	}
+
+	t.Run("it fails before importing when a function cannot be parsed", func(t *testing.T) {
+		checker := &fakeSyntaxChecker{err: transpiler.TranspileErrors{
+			{Index: 1, Message: "Unexpected token", Line: 2, Column: 3},
+		}}
+		importCommand := setup(checker)
+		mockUI := importCommand.UI.(*cli.MockUi)
+
+		exitCode := importCommand.Run(args)
+		u.So(t, exitCode, gc.ShouldEqual, 1)
+		u.So(t, checker.calls, gc.ShouldEqual, 1)
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, "failed to parse function source")
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, "Unexpected token (line 2, column 3)")
+
+		mockClient := importCommand.realmClient.(*u.MockRealmClient)
+		u.So(t, len(mockClient.ImportFnCalls), gc.ShouldEqual, 0)
+	})
+
+	t.Run("it skips the check when the transpiler cannot be run", func(t *testing.T) {
+		checker := &fakeSyntaxChecker{err: errors.New("executable file not found")}
+		importCommand := setup(checker)
+		mockUI := importCommand.UI.(*cli.MockUi)
+
+		exitCode := importCommand.Run(args)
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+		u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, "Skipping function syntax check")
+	})
+
+	t.Run("it does not check the functions with --no-syntax-check", func(t *testing.T) {
+		checker := &fakeSyntaxChecker{err: transpiler.TranspileErrors{{Index: 0, Message: "Unexpected token"}}}
+		importCommand := setup(checker)
+
+		exitCode := importCommand.Run(append([]string{"--no-syntax-check"}, args...))
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+		u.So(t, checker.calls, gc.ShouldEqual, 0)
+	})
+}
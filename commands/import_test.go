@@ -2,6 +2,8 @@ package commands
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -11,6 +13,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 
@@ -43,14 +46,20 @@ func setUpBasicCommand() (*ImportCommand, *cli.MockUi) {
 		return nil
 	}
 
-	mockRealmClient := &u.MockRealmClient{
+	importCommand.realmClient = setUpBasicRealmClient()
+	return importCommand, mockUI
+}
+
+// setUpBasicRealmClient returns a client for the app "app-id" that imports successfully
+func setUpBasicRealmClient() *u.MockRealmClient {
+	return &u.MockRealmClient{
 		ExportFn: func(groupID, appID string, strategy api.ExportStrategy) (string, io.ReadCloser, error) {
 			return "", u.NewResponseBody(bytes.NewReader([]byte{})), nil
 		},
 		DiffFn: func(groupID, appID string, appData []byte, strategy string) ([]string, error) {
 			return []string{"sample-diff-contents"}, nil
 		},
-		ImportFn: func(groupID, appID string, appData []byte, strategy string) error {
+		ImportFn: func(ctx context.Context, groupID, appID string, appData []byte, strategy string) error {
 			return nil
 		},
 		FetchAppByClientAppIDFn: func(clientAppID string) (*models.App, error) {
@@ -60,8 +69,6 @@ func setUpBasicCommand() (*ImportCommand, *cli.MockUi) {
 			}, nil
 		},
 	}
-	importCommand.realmClient = mockRealmClient
-	return importCommand, mockUI
 }
 
 func TestImportNewApp(t *testing.T) {
@@ -497,6 +504,161 @@ func TestImportNewApp(t *testing.T) {
 				u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, tc.ExpectedOutput)
 			})
 		}
+
+		t.Run("with --create-if-missing", func(t *testing.T) {
+			validArgs := []string{"--create-if-missing", "--path=../testdata/new_app", "--project-id=59dbcb07127ab4131c54e810"}
+
+			for _, tc := range []struct {
+				Description             string
+				Args                    []string
+				ExpectedError           string
+				ExpectedAppName         string
+				ExpectedLocation        string
+				ExpectedDeploymentModel string
+			}{
+				{
+					Description:             "it creates the app without prompting",
+					Args:                    append([]string{"--app-name=My-Test-app", "--location=IE", "--deployment-model=LOCAL"}, validArgs...),
+					ExpectedAppName:         "My-Test-app",
+					ExpectedLocation:        "IE",
+					ExpectedDeploymentModel: "LOCAL",
+				},
+				{
+					Description:   "it fails without a project id instead of prompting for one",
+					Args:          []string{"--create-if-missing", "--path=../testdata/new_app", "--app-name=My-Test-app", "--location=IE", "--deployment-model=LOCAL"},
+					ExpectedError: "a Project ID (--project-id=[string]) must be supplied to create the app with --create-if-missing",
+				},
+				{
+					Description:   "it fails without an app name instead of prompting for one",
+					Args:          append([]string{"--location=IE", "--deployment-model=LOCAL"}, validArgs...),
+					ExpectedError: "an app name must be supplied with --app-name or in the app config to create the app with --create-if-missing",
+				},
+				{
+					Description:   "it fails without a location instead of prompting for one",
+					Args:          append([]string{"--app-name=My-Test-app", "--deployment-model=LOCAL"}, validArgs...),
+					ExpectedError: "a location must be supplied with --location or in the app config",
+				},
+				{
+					Description:   "it fails without a deployment model instead of prompting for one",
+					Args:          append([]string{"--app-name=My-Test-app", "--location=IE"}, validArgs...),
+					ExpectedError: "a deployment model must be supplied with --deployment-model or in the app config",
+				},
+				{
+					Description:   "it fails with an unknown location",
+					Args:          append([]string{"--app-name=My-Test-app", "--location=MARS", "--deployment-model=LOCAL"}, validArgs...),
+					ExpectedError: `unknown location "MARS"; accepted values are [US-VA|US-OR|IE|AU]`,
+				},
+			} {
+				t.Run(tc.Description, func(t *testing.T) {
+					var createdApp []string
+					realmClient := u.MockRealmClient{
+						ExportFn: func(groupID, appID string, strategy api.ExportStrategy) (string, io.ReadCloser, error) {
+							return "", u.NewResponseBody(bytes.NewReader([]byte{})), nil
+						},
+						CreateEmptyAppFn: func(groupID, appName, locationName, deploymentModelName string) (*models.App, error) {
+							createdApp = []string{groupID, appName, locationName, deploymentModelName}
+							return &models.App{Name: appName, ClientAppID: appName + "-abcdef"}, nil
+						},
+						FetchAppsByGroupIDFn: func(groupID string) ([]*models.App, error) {
+							return []*models.App{}, nil
+						},
+						FetchAppByClientAppIDFn: func(clientAppID string) (*models.App, error) {
+							return nil, api.ErrAppNotFound{ClientAppID: clientAppID}
+						},
+					}
+
+					importCommand, mockUI := setup()
+					importCommand.realmClient = &realmClient
+
+					exitCode := importCommand.Run(tc.Args)
+					u.So(t, mockUI.OutputWriter.String(), gc.ShouldNotContainSubstring, "would you like to create a new app?")
+
+					if tc.ExpectedError != "" {
+						u.So(t, exitCode, gc.ShouldEqual, 1)
+						u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, tc.ExpectedError)
+						u.So(t, createdApp, gc.ShouldBeNil)
+						return
+					}
+
+					u.So(t, exitCode, gc.ShouldEqual, 0)
+					u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+					u.So(t, createdApp, gc.ShouldResemble, []string{"59dbcb07127ab4131c54e810", tc.ExpectedAppName, tc.ExpectedLocation, tc.ExpectedDeploymentModel})
+					u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, "Successfully imported '"+tc.ExpectedAppName+"-abcdef'")
+				})
+			}
+		})
+
+		t.Run("with --as-new", func(t *testing.T) {
+			setupAsNew := func(existingApps ...*models.App) (*ImportCommand, *cli.MockUi, *[]string, *int) {
+				var createdApp []string
+				var configWrites int
+				realmClient := u.MockRealmClient{
+					ExportFn: func(groupID, appID string, strategy api.ExportStrategy) (string, io.ReadCloser, error) {
+						return "", u.NewResponseBody(bytes.NewReader([]byte{})), nil
+					},
+					CreateEmptyAppFn: func(groupID, appName, locationName, deploymentModelName string) (*models.App, error) {
+						createdApp = []string{groupID, appName, locationName, deploymentModelName}
+						return &models.App{Name: appName, ClientAppID: appName + "-abcdef"}, nil
+					},
+					FetchAppsByGroupIDFn: func(groupID string) ([]*models.App, error) {
+						return existingApps, nil
+					},
+					FetchAppByClientAppIDFn: func(clientAppID string) (*models.App, error) {
+						return &models.App{Name: "full-app", ClientAppID: clientAppID}, nil
+					},
+				}
+
+				importCommand, mockUI := setup()
+				importCommand.realmClient = &realmClient
+				importCommand.writeAppConfigToFile = func(dest string, app models.AppInstanceData) error {
+					configWrites++
+					return nil
+				}
+				return importCommand, mockUI, &createdApp, &configWrites
+			}
+			args := []string{"--as-new", "--app-id=full-app-abcde", "--path=../testdata/full_app", "--project-id=59dbcb07127ab4131c54e810"}
+
+			t.Run("it creates a new app from the flags without prompting with --yes", func(t *testing.T) {
+				importCommand, mockUI, createdApp, configWrites := setupAsNew(&models.App{Name: "full-app"})
+
+				exitCode := importCommand.Run(append([]string{"-y", "--app-name=full-app-copy", "--location=IE", "--deployment-model=LOCAL"}, args...))
+				u.So(t, exitCode, gc.ShouldEqual, 0)
+				u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+				u.So(t, *createdApp, gc.ShouldResemble, []string{"59dbcb07127ab4131c54e810", "full-app-copy", "IE", "LOCAL"})
+				u.So(t, *configWrites, gc.ShouldEqual, 0)
+				u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, "Successfully imported 'full-app-copy-abcdef'")
+			})
+
+			t.Run("it prefills the prompts with the flags", func(t *testing.T) {
+				importCommand, mockUI, createdApp, _ := setupAsNew()
+				mockUI.InputReader = strings.NewReader("y\nfull-app-copy\nAU\nLOCAL\n")
+
+				exitCode := importCommand.Run(append([]string{"--app-name=full-app-copy", "--location=AU", "--deployment-model=LOCAL"}, args...))
+				u.So(t, exitCode, gc.ShouldEqual, 0)
+				u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, "importing with --as-new: would you like to create a new app?")
+				u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, "App name [full-app-copy]")
+				u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, "Location [AU]")
+				u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, "Deployment Model [LOCAL]")
+				u.So(t, *createdApp, gc.ShouldResemble, []string{"59dbcb07127ab4131c54e810", "full-app-copy", "AU", "LOCAL"})
+			})
+
+			t.Run("it fails when an app already has the name", func(t *testing.T) {
+				importCommand, mockUI, createdApp, _ := setupAsNew(&models.App{Name: "full-app"})
+
+				exitCode := importCommand.Run(append([]string{"-y"}, args...))
+				u.So(t, exitCode, gc.ShouldEqual, 1)
+				u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, `app already exists with name "full-app"`)
+				u.So(t, *createdApp, gc.ShouldBeNil)
+			})
+
+			t.Run("it cannot be used with --watch", func(t *testing.T) {
+				importCommand, mockUI, _, _ := setupAsNew()
+
+				exitCode := importCommand.Run(append([]string{"--watch"}, args...))
+				u.So(t, exitCode, gc.ShouldEqual, 1)
+				u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, "--as-new cannot be used with --watch or --plan-file")
+			})
+		})
 	})
 }
 
@@ -534,7 +696,7 @@ func TestImportCommand(t *testing.T) {
 
 		t.Run("it does not import if the user does not confirm the diff", func(t *testing.T) {
 			realmClient := u.MockRealmClient{
-				ImportFn: func(groupID, appID string, appData []byte, strategy string) error {
+				ImportFn: func(ctx context.Context, groupID, appID string, appData []byte, strategy string) error {
 					return nil
 				},
 				DiffFn: func(groupID, appID string, appData []byte, strategy string) ([]string, error) {
@@ -568,7 +730,7 @@ func TestImportCommand(t *testing.T) {
 			realmClient := mock_api.NewMockRealmClient(ctrl)
 			defer ctrl.Finish()
 
-			realmClient.EXPECT().FetchAppByClientAppID("my-app-abcdef").Return(&models.App{GroupID: "group-id", ID: "app-id"}, nil)
+			realmClient.EXPECT().FindApps(api.AppFilter{ClientAppID: "my-app-abcdef"}).Return([]*models.App{{GroupID: "group-id", ID: "app-id"}}, nil)
 			realmClient.EXPECT().Diff("group-id", "app-id", gomock.Any(), gomock.Any()).Return([]string{"changes"}, nil)
 			realmClient.EXPECT().CreateDraft("group-id", "app-id").Return(nil, api.UnmarshalRealmError(&http.Response{
 				Body: u.NewResponseBody(strings.NewReader(`{ "error_code": "DraftAlreadyExists" }`)),
@@ -593,7 +755,7 @@ func TestImportCommand(t *testing.T) {
 			realmClient := mock_api.NewMockRealmClient(ctrl)
 			defer ctrl.Finish()
 
-			realmClient.EXPECT().FetchAppByClientAppID("my-app-abcdef").Return(&models.App{GroupID: "group-id", ID: "app-id"}, nil)
+			realmClient.EXPECT().FindApps(api.AppFilter{ClientAppID: "my-app-abcdef"}).Return([]*models.App{{GroupID: "group-id", ID: "app-id"}}, nil)
 			realmClient.EXPECT().Diff("group-id", "app-id", gomock.Any(), gomock.Any()).Return([]string{"changes"}, nil)
 			realmClient.EXPECT().CreateDraft("group-id", "app-id").Return(nil, api.UnmarshalRealmError(&http.Response{
 				Body: u.NewResponseBody(strings.NewReader(`{ "error_code": "DraftAlreadyExists" }`)),
@@ -619,7 +781,7 @@ func TestImportCommand(t *testing.T) {
 			realmClient := mock_api.NewMockRealmClient(ctrl)
 			defer ctrl.Finish()
 
-			realmClient.EXPECT().FetchAppByClientAppID("my-app-abcdef").Return(&models.App{GroupID: "group-id", ID: "app-id"}, nil)
+			realmClient.EXPECT().FindApps(api.AppFilter{ClientAppID: "my-app-abcdef"}).Return([]*models.App{{GroupID: "group-id", ID: "app-id"}}, nil)
 			realmClient.EXPECT().Diff("group-id", "app-id", gomock.Any(), gomock.Any()).Return([]string{"changes"}, nil)
 			realmClient.EXPECT().CreateDraft("group-id", "app-id").Return(nil, api.UnmarshalRealmError(&http.Response{
 				Body: u.NewResponseBody(strings.NewReader(`{ "error_code": "DraftAlreadyExists" }`)),
@@ -632,7 +794,7 @@ func TestImportCommand(t *testing.T) {
 			}, nil)
 			realmClient.EXPECT().DiscardDraft("group-id", "app-id", "draft-id").Return(nil)
 			realmClient.EXPECT().CreateDraft("group-id", "app-id").Return(&models.AppDraft{ID: "draft-id-2"}, nil)
-			realmClient.EXPECT().Import("group-id", "app-id", gomock.Any(), gomock.Any()).Return(nil)
+			realmClient.EXPECT().Import(gomock.Any(), "group-id", "app-id", gomock.Any(), gomock.Any()).Return(nil)
 			realmClient.EXPECT().DeployDraft("group-id", "app-id", "draft-id-2").Return(&models.Deployment{
 				Status: models.DeploymentStatusSuccessful,
 			}, nil)
@@ -652,7 +814,7 @@ func TestImportCommand(t *testing.T) {
 			realmClient := mock_api.NewMockRealmClient(ctrl)
 			defer ctrl.Finish()
 
-			realmClient.EXPECT().FetchAppByClientAppID("my-app-abcdef").Return(&models.App{GroupID: "group-id", ID: "app-id"}, nil)
+			realmClient.EXPECT().FindApps(api.AppFilter{ClientAppID: "my-app-abcdef"}).Return([]*models.App{{GroupID: "group-id", ID: "app-id"}}, nil)
 			realmClient.EXPECT().Diff("group-id", "app-id", gomock.Any(), gomock.Any()).Return([]string{"changes"}, nil)
 			realmClient.EXPECT().CreateDraft("group-id", "app-id").Return(nil, api.UnmarshalRealmError(&http.Response{
 				Body: u.NewResponseBody(strings.NewReader(`{ "error_code": "DraftAlreadyExists" }`)),
@@ -663,7 +825,7 @@ func TestImportCommand(t *testing.T) {
 			realmClient.EXPECT().DraftDiff("group-id", "app-id", "draft-id").Return(&models.DraftDiff{}, nil) // empty diff
 			realmClient.EXPECT().DiscardDraft("group-id", "app-id", "draft-id").Return(nil)
 			realmClient.EXPECT().CreateDraft("group-id", "app-id").Return(&models.AppDraft{ID: "draft-id-2"}, nil)
-			realmClient.EXPECT().Import("group-id", "app-id", gomock.Any(), gomock.Any()).Return(nil)
+			realmClient.EXPECT().Import(gomock.Any(), "group-id", "app-id", gomock.Any(), gomock.Any()).Return(nil)
 			realmClient.EXPECT().DeployDraft("group-id", "app-id", "draft-id-2").Return(&models.Deployment{
 				Status: models.DeploymentStatusSuccessful,
 			}, nil)
@@ -677,6 +839,248 @@ func TestImportCommand(t *testing.T) {
 			u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, "An empty draft already exists for your app, would you like to discard it first?")
 		})
 
+		t.Run("with --detailed-exit-code", func(t *testing.T) {
+			for _, tc := range []struct {
+				Description      string
+				Args             []string
+				Diffs            []string
+				ExpectedExitCode int
+				ExpectedImports  int
+			}{
+				{
+					Description:      "it exits with a distinct code when the app is identical to the deployed version",
+					ExpectedExitCode: exitCodeNoChanges,
+				},
+				{
+					Description:      "it exits with 0 when the app is imported",
+					Diffs:            []string{"sample-diff-contents"},
+					ExpectedExitCode: 0,
+					ExpectedImports:  1,
+				},
+				{
+					Description:      "it exits with a distinct code with -y when the app is identical to the deployed version",
+					Args:             []string{"-y"},
+					ExpectedExitCode: exitCodeNoChanges,
+				},
+				{
+					Description:      "it exits with 0 with -y when the app is imported",
+					Args:             []string{"-y"},
+					Diffs:            []string{"sample-diff-contents"},
+					ExpectedExitCode: 0,
+					ExpectedImports:  1,
+				},
+			} {
+				t.Run(tc.Description, func(t *testing.T) {
+					importCommand, mockUI := setup()
+					mockUI.InputReader = strings.NewReader("y\n")
+					var imports int
+					importCommand.realmClient = &u.MockRealmClient{
+						FetchAppByClientAppIDFn: func(clientAppID string) (*models.App, error) {
+							return &models.App{GroupID: "group-id", ID: "app-id"}, nil
+						},
+						DiffFn: func(groupID, appID string, appData []byte, strategy string) ([]string, error) {
+							return tc.Diffs, nil
+						},
+						ImportFn: func(ctx context.Context, groupID, appID string, appData []byte, strategy string) error {
+							imports++
+							return nil
+						},
+						ExportFn: func(groupID, appID string, strategy api.ExportStrategy) (string, io.ReadCloser, error) {
+							return "", u.NewResponseBody(bytes.NewReader([]byte{})), nil
+						},
+					}
+
+					args := append([]string{"--path=../testdata/full_app", "--detailed-exit-code"}, tc.Args...)
+					exitCode := importCommand.Run(append(args, validArgs...))
+					u.So(t, exitCode, gc.ShouldEqual, tc.ExpectedExitCode)
+					u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+					u.So(t, imports, gc.ShouldEqual, tc.ExpectedImports)
+				})
+			}
+		})
+
+		t.Run("with --retry-on-conflict", func(t *testing.T) {
+			draftAlreadyExists := func() error {
+				return api.UnmarshalRealmError(&http.Response{
+					Body: u.NewResponseBody(strings.NewReader(`{ "error_code": "DraftAlreadyExists" }`)),
+				})
+			}
+
+			t.Run("it retries creating the draft instead of discarding the existing one", func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				realmClient := mock_api.NewMockRealmClient(ctrl)
+				defer ctrl.Finish()
+
+				realmClient.EXPECT().FindApps(api.AppFilter{ClientAppID: "my-app-abcdef"}).Return([]*models.App{{GroupID: "group-id", ID: "app-id"}}, nil)
+				gomock.InOrder(
+					realmClient.EXPECT().CreateDraft("group-id", "app-id").Return(nil, draftAlreadyExists()).Times(2),
+					realmClient.EXPECT().CreateDraft("group-id", "app-id").Return(&models.AppDraft{ID: "draft-id"}, nil),
+				)
+				realmClient.EXPECT().Import(gomock.Any(), "group-id", "app-id", gomock.Any(), gomock.Any()).Return(nil)
+				realmClient.EXPECT().DeployDraft("group-id", "app-id", "draft-id").Return(&models.Deployment{
+					Status: models.DeploymentStatusSuccessful,
+				}, nil)
+				realmClient.EXPECT().Export("group-id", "app-id", api.ExportStrategyNone).Return("", u.NewResponseBody(bytes.NewReader([]byte{})), nil)
+
+				importCommand, mockUI := setup()
+				importCommand.realmClient = realmClient
+				importCommand.draftRetryInterval = 0
+				exitCode := importCommand.Run(append([]string{"--path=../testdata/full_app", "--retry-on-conflict", "-y"}, validArgs...))
+
+				u.So(t, exitCode, gc.ShouldEqual, 0)
+				u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, "A draft already exists for your app, retrying")
+				u.So(t, mockUI.OutputWriter.String(), gc.ShouldNotContainSubstring, "Discarding existing draft...")
+			})
+
+			t.Run("it gives up and leaves the existing draft in place after too many attempts", func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				realmClient := mock_api.NewMockRealmClient(ctrl)
+				defer ctrl.Finish()
+
+				realmClient.EXPECT().FindApps(api.AppFilter{ClientAppID: "my-app-abcdef"}).Return([]*models.App{{GroupID: "group-id", ID: "app-id"}}, nil)
+				realmClient.EXPECT().CreateDraft("group-id", "app-id").Return(nil, draftAlreadyExists()).Times(draftConflictRetries + 1)
+
+				importCommand, mockUI := setup()
+				importCommand.realmClient = realmClient
+				importCommand.draftRetryInterval = 0
+				exitCode := importCommand.Run(append([]string{"--path=../testdata/full_app", "--retry-on-conflict", "-y"}, validArgs...))
+
+				u.So(t, exitCode, gc.ShouldEqual, 1)
+				u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, errDraftConflict(draftConflictRetries).Error())
+			})
+		})
+
+		t.Run("it discards the draft when the import phase exceeds the import timeout", func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			realmClient := mock_api.NewMockRealmClient(ctrl)
+			defer ctrl.Finish()
+
+			realmClient.EXPECT().FindApps(api.AppFilter{ClientAppID: "my-app-abcdef"}).Return([]*models.App{{GroupID: "group-id", ID: "app-id"}}, nil)
+			realmClient.EXPECT().CreateDraft("group-id", "app-id").Return(&models.AppDraft{ID: "draft-id"}, nil)
+			realmClient.EXPECT().Import(gomock.Any(), "group-id", "app-id", gomock.Any(), gomock.Any()).DoAndReturn(
+				func(ctx context.Context, groupID, appID string, appData []byte, strategy string) error {
+					<-ctx.Done()
+					return ctx.Err()
+				},
+			)
+			realmClient.EXPECT().DiscardDraft("group-id", "app-id", "draft-id").Return(nil)
+
+			importCommand, mockUI := setup()
+			importCommand.realmClient = realmClient
+			exitCode := importCommand.Run(append([]string{"--path=../testdata/full_app", "--import-timeout=10ms", "-y"}, validArgs...))
+
+			u.So(t, exitCode, gc.ShouldEqual, 1)
+			u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, "import phase timed out after 10ms")
+		})
+
+		t.Run("it waits for the deployment without a timeout by default", func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			realmClient := mock_api.NewMockRealmClient(ctrl)
+			defer ctrl.Finish()
+
+			pending := &models.Deployment{ID: "deployment-id", Status: models.DeploymentStatusPending}
+			successful := &models.Deployment{ID: "deployment-id", Status: models.DeploymentStatusSuccessful}
+			realmClient.EXPECT().FindApps(api.AppFilter{ClientAppID: "my-app-abcdef"}).Return([]*models.App{{GroupID: "group-id", ID: "app-id"}}, nil)
+			realmClient.EXPECT().CreateDraft("group-id", "app-id").Return(&models.AppDraft{ID: "draft-id"}, nil)
+			realmClient.EXPECT().Import(gomock.Any(), "group-id", "app-id", gomock.Any(), gomock.Any()).Return(nil)
+			realmClient.EXPECT().DeployDraft("group-id", "app-id", "draft-id").Return(pending, nil)
+			gomock.InOrder(
+				realmClient.EXPECT().GetDeployment("group-id", "app-id", "deployment-id").Return(pending, nil).Times(3),
+				realmClient.EXPECT().GetDeployment("group-id", "app-id", "deployment-id").Return(successful, nil),
+			)
+			realmClient.EXPECT().Export("group-id", "app-id", api.ExportStrategyNone).Return("", u.NewResponseBody(bytes.NewReader([]byte{})), nil)
+
+			importCommand, mockUI := setup()
+			importCommand.realmClient = realmClient
+			importCommand.deployPollInterval = 10 * time.Millisecond
+			importCommand.Run(append([]string{"--path=../testdata/full_app", "-y"}, validArgs...))
+
+			u.So(t, importCommand.flagDeployTimeout, gc.ShouldEqual, 0)
+			u.So(t, mockUI.ErrorWriter.String(), gc.ShouldNotContainSubstring, "stopped waiting for the deployment")
+		})
+
+		t.Run("it stops waiting for the deployment when it exceeds the deploy timeout", func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			realmClient := mock_api.NewMockRealmClient(ctrl)
+			defer ctrl.Finish()
+
+			pending := &models.Deployment{ID: "deployment-id", Status: models.DeploymentStatusPending}
+			realmClient.EXPECT().FindApps(api.AppFilter{ClientAppID: "my-app-abcdef"}).Return([]*models.App{{GroupID: "group-id", ID: "app-id"}}, nil)
+			realmClient.EXPECT().CreateDraft("group-id", "app-id").Return(&models.AppDraft{ID: "draft-id"}, nil)
+			realmClient.EXPECT().Import(gomock.Any(), "group-id", "app-id", gomock.Any(), gomock.Any()).Return(nil)
+			realmClient.EXPECT().DeployDraft("group-id", "app-id", "draft-id").Return(pending, nil)
+			realmClient.EXPECT().GetDeployment("group-id", "app-id", "deployment-id").Return(pending, nil).MinTimes(1)
+			realmClient.EXPECT().DiscardDraft("group-id", "app-id", "draft-id").Return(nil)
+
+			importCommand, mockUI := setup()
+			importCommand.realmClient = realmClient
+			importCommand.deployPollInterval = time.Millisecond
+			exitCode := importCommand.Run(append([]string{"--path=../testdata/full_app", "--deploy-timeout=20ms", "-y"}, validArgs...))
+
+			u.So(t, exitCode, gc.ShouldEqual, 1)
+			u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, errDeployTimeout(20*time.Millisecond).Error())
+		})
+
+		t.Run("it follows the function and trigger logs from the deployment with --follow", func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			realmClient := mock_api.NewMockRealmClient(ctrl)
+			defer ctrl.Finish()
+
+			importCommand, mockUI := setup()
+			importCommand.realmClient = realmClient
+			importCommand.followInterval = 0
+			importCommand.stopFollowing = make(chan struct{})
+
+			realmClient.EXPECT().FindApps(api.AppFilter{ClientAppID: "my-app-abcdef"}).Return([]*models.App{{GroupID: "group-id", ID: "app-id"}}, nil)
+			realmClient.EXPECT().CreateDraft("group-id", "app-id").Return(&models.AppDraft{ID: "draft-id"}, nil)
+			realmClient.EXPECT().Import(gomock.Any(), "group-id", "app-id", gomock.Any(), gomock.Any()).Return(nil)
+			realmClient.EXPECT().DeployDraft("group-id", "app-id", "draft-id").Return(&models.Deployment{ID: "deployment-id", Status: models.DeploymentStatusSuccessful}, nil)
+			realmClient.EXPECT().Export("group-id", "app-id", api.ExportStrategyNone).Return("", u.NewResponseBody(bytes.NewReader([]byte{})), nil)
+			realmClient.EXPECT().ListLogs("group-id", "app-id", gomock.Any()).DoAndReturn(
+				func(groupID, appID string, options api.LogsOptions) ([]models.Log, error) {
+					u.So(t, options.Types, gc.ShouldResemble, []string{"FUNCTION", "TRIGGER_DATABASE", "TRIGGER_AUTHENTICATION", "TRIGGER_SCHEDULED"})
+					u.So(t, options.Start, gc.ShouldEqual, importCommand.deployedAt)
+					close(importCommand.stopFollowing)
+					return []models.Log{{ID: "1", Type: "FUNCTION", Started: options.Start.Add(time.Second), FunctionName: "sum"}}, nil
+				},
+			)
+
+			exitCode := importCommand.Run(append([]string{"--path=../testdata/full_app", "--follow", "-y"}, validArgs...))
+
+			u.So(t, exitCode, gc.ShouldEqual, 0)
+			u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, "Following function and trigger logs, press Ctrl-C to stop...")
+			u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, "FUNCTION sum OK")
+		})
+
+		t.Run("it rejects unknown keys with --strict before contacting Realm", func(t *testing.T) {
+			appPath, err := ioutil.TempDir("", "realm-app-")
+			u.So(t, err, gc.ShouldBeNil)
+			defer os.RemoveAll(appPath)
+
+			webhookPath := filepath.Join(appPath, "services", "http", "incoming_webhooks", "hook")
+			u.So(t, os.MkdirAll(webhookPath, 0755), gc.ShouldBeNil)
+			u.So(t, ioutil.WriteFile(filepath.Join(appPath, "config.json"), []byte(`{"name": "my-app"}`), 0644), gc.ShouldBeNil)
+			u.So(t, ioutil.WriteFile(filepath.Join(appPath, "services", "http", "config.json"), []byte(`{"name": "http", "type": "http"}`), 0644), gc.ShouldBeNil)
+			u.So(t, ioutil.WriteFile(filepath.Join(webhookPath, "config.json"), []byte(`{"name": "hook", "options": {"httpMethd": "POST"}}`), 0644), gc.ShouldBeNil)
+			u.So(t, ioutil.WriteFile(filepath.Join(webhookPath, "source.js"), []byte(`exports = () => {}`), 0644), gc.ShouldBeNil)
+
+			importCommand, mockUI := setup()
+			importCommand.realmClient = &u.MockRealmClient{}
+			exitCode := importCommand.Run(append([]string{"--path=" + appPath, "--strict", "-y"}, validArgs...))
+
+			u.So(t, exitCode, gc.ShouldEqual, 1)
+			u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, "found keys which realm-cli does not recognize, which --strict rejects")
+			u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, "services/http/incoming_webhooks/hook/config.json: options.httpMethd: unknown key")
+		})
+
+		t.Run("it rejects --follow along with --watch", func(t *testing.T) {
+			importCommand, mockUI := setup()
+			exitCode := importCommand.Run(append([]string{"--path=../testdata/full_app", "--follow", "--watch"}, validArgs...))
+
+			u.So(t, exitCode, gc.ShouldEqual, 1)
+			u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, "--follow cannot be used with --watch or --plan-file")
+		})
+
 		for _, tc := range []testCase{
 			{
 				Description:      "it fails if given an invalid flagAppPath",
@@ -692,7 +1096,7 @@ func TestImportCommand(t *testing.T) {
 					ExportFn: func(groupID, appID string, strategy api.ExportStrategy) (string, io.ReadCloser, error) {
 						return "", u.NewResponseBody(bytes.NewReader([]byte{})), nil
 					},
-					ImportFn: func(groupID, appID string, appData []byte, strategy string) error {
+					ImportFn: func(ctx context.Context, groupID, appID string, appData []byte, strategy string) error {
 						return nil
 					},
 					DiffFn: func(groupID, appID string, appData []byte, strategy string) ([]string, error) {
@@ -715,7 +1119,7 @@ func TestImportCommand(t *testing.T) {
 					ExportFn: func(groupID, appID string, strategy api.ExportStrategy) (string, io.ReadCloser, error) {
 						return "", nil, fmt.Errorf("oh no")
 					},
-					ImportFn: func(groupID, appID string, appData []byte, strategy string) error {
+					ImportFn: func(ctx context.Context, groupID, appID string, appData []byte, strategy string) error {
 						return nil
 					},
 					DiffFn: func(groupID, appID string, appData []byte, strategy string) ([]string, error) {
@@ -734,7 +1138,7 @@ func TestImportCommand(t *testing.T) {
 					ExportFn: func(groupID, appID string, strategy api.ExportStrategy) (string, io.ReadCloser, error) {
 						return "", u.NewResponseBody(strings.NewReader("export response")), nil
 					},
-					ImportFn: func(groupID, appID string, appData []byte, strategy string) error {
+					ImportFn: func(ctx context.Context, groupID, appID string, appData []byte, strategy string) error {
 						return nil
 					},
 					DiffFn: func(groupID, appID string, appData []byte, strategy string) ([]string, error) {
@@ -754,7 +1158,7 @@ func TestImportCommand(t *testing.T) {
 				ExpectedExitCode: 1,
 				ExpectedError:    "oh noes",
 				RealmClient: u.MockRealmClient{
-					ImportFn: func(groupID, appID string, appData []byte, strategy string) error {
+					ImportFn: func(ctx context.Context, groupID, appID string, appData []byte, strategy string) error {
 						return fmt.Errorf("oh noes")
 					},
 					DiffFn: func(groupID, appID string, appData []byte, strategy string) ([]string, error) {
@@ -776,7 +1180,7 @@ func TestImportCommand(t *testing.T) {
 					ExportFn: func(groupID, appID string, strategy api.ExportStrategy) (string, io.ReadCloser, error) {
 						return "", u.NewResponseBody(strings.NewReader("export response")), nil
 					},
-					ImportFn: func(groupID, appID string, appData []byte, strategy string) error {
+					ImportFn: func(ctx context.Context, groupID, appID string, appData []byte, strategy string) error {
 						return nil
 					},
 					DiffFn: func(groupID, appID string, appData []byte, strategy string) ([]string, error) {
@@ -798,7 +1202,7 @@ func TestImportCommand(t *testing.T) {
 					ExportFn: func(groupID, appID string, strategy api.ExportStrategy) (string, io.ReadCloser, error) {
 						return "", u.NewResponseBody(strings.NewReader("export response")), nil
 					},
-					ImportFn: func(groupID, appID string, appData []byte, strategy string) error {
+					ImportFn: func(ctx context.Context, groupID, appID string, appData []byte, strategy string) error {
 						return nil
 					},
 					DiffFn: func(groupID, appID string, appData []byte, strategy string) ([]string, error) {
@@ -824,7 +1228,7 @@ func TestImportCommand(t *testing.T) {
 					ExportFn: func(groupID, appID string, strategy api.ExportStrategy) (string, io.ReadCloser, error) {
 						return "", u.NewResponseBody(strings.NewReader("export response")), nil
 					},
-					ImportFn: func(groupID, appID string, appData []byte, strategy string) error {
+					ImportFn: func(ctx context.Context, groupID, appID string, appData []byte, strategy string) error {
 						return nil
 					},
 					DiffFn: func(groupID, appID string, appData []byte, strategy string) ([]string, error) {
@@ -847,7 +1251,7 @@ func TestImportCommand(t *testing.T) {
 					ExportFn: func(groupID, appID string, strategy api.ExportStrategy) (string, io.ReadCloser, error) {
 						return "", nil, fmt.Errorf("oh no")
 					},
-					ImportFn: func(groupID, appID string, appData []byte, strategy string) error {
+					ImportFn: func(ctx context.Context, groupID, appID string, appData []byte, strategy string) error {
 						return nil
 					},
 					DiffFn: func(groupID, appID string, appData []byte, strategy string) ([]string, error) {
@@ -886,7 +1290,7 @@ func TestImportCommand(t *testing.T) {
 					ExportFn: func(groupID, appID string, strategy api.ExportStrategy) (string, io.ReadCloser, error) {
 						return "", u.NewResponseBody(bytes.NewReader([]byte{})), nil
 					},
-					ImportFn: func(groupID, appID string, appData []byte, strategy string) error {
+					ImportFn: func(ctx context.Context, groupID, appID string, appData []byte, strategy string) error {
 						return nil
 					},
 					DiffFn: func(groupID, appID string, appData []byte, strategy string) ([]string, error) {
@@ -911,7 +1315,7 @@ func TestImportCommand(t *testing.T) {
 					ExportFn: func(groupID, appID string, strategy api.ExportStrategy) (string, io.ReadCloser, error) {
 						return "", u.NewResponseBody(bytes.NewReader([]byte{})), nil
 					},
-					ImportFn: func(groupID, appID string, appData []byte, strategy string) error {
+					ImportFn: func(ctx context.Context, groupID, appID string, appData []byte, strategy string) error {
 						return nil
 					},
 					DiffFn: func(groupID, appID string, appData []byte, strategy string) ([]string, error) {
@@ -926,8 +1330,8 @@ func TestImportCommand(t *testing.T) {
 							ID:      "app-id",
 						}, nil
 					},
-					InvalidateCacheFn: func(groupID, appID, path string) error {
-						return nil
+					InvalidateCacheFn: func(groupID, appID, path string) (string, error) {
+						return "", nil
 					},
 				},
 			},
@@ -970,7 +1374,7 @@ func TestImportCommand(t *testing.T) {
 					ExportFn: func(groupID, appID string, strategy api.ExportStrategy) (string, io.ReadCloser, error) {
 						return "", u.NewResponseBody(bytes.NewReader([]byte{})), nil
 					},
-					ImportFn: func(groupID, appID string, appData []byte, strategy string) error {
+					ImportFn: func(ctx context.Context, groupID, appID string, appData []byte, strategy string) error {
 						return nil
 					},
 					DiffFn: func(groupID, appID string, appData []byte, strategy string) ([]string, error) {
@@ -1037,12 +1441,18 @@ func TestImportCommand(t *testing.T) {
 						mockUI.InputReader = strings.NewReader("y\n")
 						importCommand.workingDirectory = tc.workingDirectory
 						var exportStrategy api.ExportStrategy
+						var imported bool
 						mockRealmClient := &u.MockRealmClient{
 							ExportFn: func(groupID, appID string, strategy api.ExportStrategy) (string, io.ReadCloser, error) {
+								if !imported {
+									// replacing the app checks its schemas against the deployed ones first
+									return "", u.NewZipResponseBody("../testdata/simple_app"), nil
+								}
 								exportStrategy = strategy
 								return "", u.NewResponseBody(strings.NewReader("export response")), nil
 							},
-							ImportFn: func(groupID, appID string, appData []byte, strategy string) error {
+							ImportFn: func(ctx context.Context, groupID, appID string, appData []byte, strategy string) error {
+								imported = true
 								return nil
 							},
 							DiffFn: func(groupID, appID string, appData []byte, strategy string) ([]string, error) {
@@ -1090,3 +1500,621 @@ func abs(path string) string {
 	}
 	return p
 }
+
+func TestImportOnly(t *testing.T) {
+	setup := func(imported *map[string]interface{}) (*ImportCommand, *cli.MockUi) {
+		importCommand, mockUI := setUpBasicCommand()
+		importCommand.user = &user.User{
+			APIKey:      "my-api-key",
+			AccessToken: u.GenerateValidAccessToken(),
+		}
+		importCommand.realmClient = &u.MockRealmClient{
+			FetchAppByClientAppIDFn: func(clientAppID string) (*models.App, error) {
+				return &models.App{GroupID: "group-id", ID: "app-id", ClientAppID: clientAppID}, nil
+			},
+			ImportFn: func(ctx context.Context, groupID, appID string, appData []byte, strategy string) error {
+				return json.Unmarshal(appData, imported)
+			},
+			ExportFn: func(groupID, appID string, strategy api.ExportStrategy) (string, io.ReadCloser, error) {
+				return "", u.NewZipResponseBody("../testdata/full_app"), nil
+			},
+		}
+		return importCommand, mockUI
+	}
+
+	t.Run("should only import the given groups over the deployed app", func(t *testing.T) {
+		var imported map[string]interface{}
+		importCommand, mockUI := setup(&imported)
+
+		exitCode := importCommand.Run([]string{"--app-id=my-app-abcdef", "--path=../testdata/simple_app", "--only=functions,values", "--strategy=replace", "-y"})
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+
+		deployedApp, err := utils.UnmarshalFromDir("../testdata/full_app")
+		u.So(t, err, gc.ShouldBeNil)
+
+		// the local app has neither functions nor values, which replaces the deployed ones
+		u.So(t, imported, gc.ShouldNotContainKey, "functions")
+		u.So(t, imported, gc.ShouldNotContainKey, "values")
+		u.So(t, imported["triggers"], gc.ShouldHaveLength, len(deployedApp["triggers"].([]interface{})))
+		u.So(t, imported["services"], gc.ShouldHaveLength, len(deployedApp["services"].([]interface{})))
+	})
+
+	t.Run("should fail with an unknown group", func(t *testing.T) {
+		var imported map[string]interface{}
+		importCommand, mockUI := setup(&imported)
+
+		exitCode := importCommand.Run([]string{"--app-id=my-app-abcdef", "--path=../testdata/simple_app", "--only=functions,http_endpoints", "-y"})
+		u.So(t, exitCode, gc.ShouldEqual, 1)
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, `unknown entity group "http_endpoints"; accepted values are [auth_providers|functions|graphql|services|triggers|values]`)
+		u.So(t, imported, gc.ShouldBeNil)
+	})
+}
+
+func TestImportProjectCache(t *testing.T) {
+	const cachedGroupID = "59dbcb07127ab4131c54e810"
+	const listedGroupID = "87aabc17127ab4229c54e742"
+
+	setup := func(publicAPIKey string, groupsCalls *int, createdIn *string) (*ImportCommand, *cli.MockUi) {
+		importCommand, mockUI := setUpBasicCommand()
+		importCommand.user = &user.User{
+			PublicAPIKey:    publicAPIKey,
+			AccessToken:     u.GenerateValidAccessToken(),
+			ProjectID:       cachedGroupID,
+			ProjectIDAPIKey: "my-public-key",
+		}
+		importCommand.realmClient = &u.MockRealmClient{
+			ExportFn: func(groupID, appID string, strategy api.ExportStrategy) (string, io.ReadCloser, error) {
+				return "", u.NewResponseBody(bytes.NewReader([]byte{})), nil
+			},
+			CreateEmptyAppFn: func(groupID, appName, locationName, deploymentModelName string) (*models.App, error) {
+				*createdIn = groupID
+				return &models.App{GroupID: groupID, Name: appName, ClientAppID: appName + "-abcdef"}, nil
+			},
+			FetchAppsByGroupIDFn: func(groupID string) ([]*models.App, error) {
+				return []*models.App{}, nil
+			},
+			FetchAppByClientAppIDFn: func(clientAppID string) (*models.App, error) {
+				return nil, api.ErrAppNotFound{ClientAppID: clientAppID}
+			},
+		}
+		importCommand.atlasClient = &u.MockMDBClient{
+			GroupsFn: func() ([]mdbcloud.Group, error) {
+				*groupsCalls++
+				return []mdbcloud.Group{{ID: listedGroupID, Name: "My-Group"}}, nil
+			},
+		}
+		return importCommand, mockUI
+	}
+
+	t.Run("should reuse the project chosen before by the same account", func(t *testing.T) {
+		var groupsCalls int
+		var createdIn string
+		importCommand, mockUI := setup("my-public-key", &groupsCalls, &createdIn)
+		mockUI.InputReader = strings.NewReader("y\nMy-Test-app\nUS-VA\nGLOBAL\n")
+
+		exitCode := importCommand.Run([]string{"--path=../testdata/new_app"})
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+		u.So(t, groupsCalls, gc.ShouldEqual, 0)
+		u.So(t, createdIn, gc.ShouldEqual, cachedGroupID)
+		u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, "Using the Atlas Project "+cachedGroupID+" chosen before")
+	})
+
+	for _, tc := range []struct {
+		Description  string
+		PublicAPIKey string
+		Args         []string
+	}{
+		{
+			Description:  "with --refresh-project",
+			PublicAPIKey: "my-public-key",
+			Args:         []string{"--path=../testdata/new_app", "--refresh-project"},
+		},
+		{
+			Description:  "when logged in as another account",
+			PublicAPIKey: "other-public-key",
+			Args:         []string{"--path=../testdata/new_app"},
+		},
+	} {
+		t.Run("should ask for the project again "+tc.Description, func(t *testing.T) {
+			var groupsCalls int
+			var createdIn string
+			importCommand, mockUI := setup(tc.PublicAPIKey, &groupsCalls, &createdIn)
+			mockUI.InputReader = strings.NewReader("y\nMy-Test-app\nMy-Group\nUS-VA\nGLOBAL\n")
+
+			exitCode := importCommand.Run(tc.Args)
+			u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+			u.So(t, exitCode, gc.ShouldEqual, 0)
+			u.So(t, groupsCalls, gc.ShouldEqual, 1)
+			u.So(t, createdIn, gc.ShouldEqual, listedGroupID)
+
+			cachedUser, err := importCommand.storage.ReadUserConfig()
+			u.So(t, err, gc.ShouldBeNil)
+			projectID, ok := cachedUser.CachedProjectID()
+			u.So(t, ok, gc.ShouldBeTrue)
+			u.So(t, projectID, gc.ShouldEqual, listedGroupID)
+		})
+	}
+}
+
+func TestImportDestructiveSchemaChanges(t *testing.T) {
+	// the deployed app has a property the local app removes
+	deployedDir, err := ioutil.TempDir("", "realm-import-schemas-")
+	u.So(t, err, gc.ShouldBeNil)
+	defer os.RemoveAll(deployedDir)
+
+	u.So(t, copyDirectory("../testdata/app_with_schemas", deployedDir), gc.ShouldBeNil)
+	u.So(t, ioutil.WriteFile(filepath.Join(deployedDir, "services", "mongodb-atlas", "rules", "store.items.json"), []byte(`{
+    "database": "store",
+    "collection": "items",
+    "roles": [],
+    "schema": {
+        "title": "Item",
+        "properties": {
+            "_id": {"bsonType": "objectId"},
+            "name": {"bsonType": "string"},
+            "price": {"bsonType": "double"}
+        }
+    }
+}`), 0600), gc.ShouldBeNil)
+
+	setup := func(imported *bool) (*ImportCommand, *cli.MockUi) {
+		importCommand, mockUI := setUpBasicCommand()
+		importCommand.user = &user.User{
+			APIKey:      "my-api-key",
+			AccessToken: u.GenerateValidAccessToken(),
+		}
+		realmClient := importCommand.realmClient.(*u.MockRealmClient)
+		realmClient.ExportFn = func(groupID, appID string, strategy api.ExportStrategy) (string, io.ReadCloser, error) {
+			return "", u.NewZipResponseBody(deployedDir), nil
+		}
+		realmClient.ImportFn = func(ctx context.Context, groupID, appID string, appData []byte, strategy string) error {
+			*imported = true
+			return nil
+		}
+		return importCommand, mockUI
+	}
+
+	args := []string{"--app-id=my-app-abcdef", "--path=../testdata/app_with_schemas"}
+
+	t.Run("should refuse the changes even with --yes", func(t *testing.T) {
+		var imported bool
+		importCommand, mockUI := setup(&imported)
+
+		exitCode := importCommand.Run(append([]string{"-y"}, args...))
+		u.So(t, exitCode, gc.ShouldEqual, 1)
+		u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, "\t!! mongodb-atlas: store.items properties.price\n")
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, "the import makes 1 destructive schema change(s) which may delete data, import anyway with --allow-destructive")
+		u.So(t, imported, gc.ShouldBeFalse)
+	})
+
+	t.Run("should refuse the changes after showing them with the diff", func(t *testing.T) {
+		var imported bool
+		importCommand, mockUI := setup(&imported)
+		mockUI.InputReader = strings.NewReader("y\n")
+
+		exitCode := importCommand.Run(append([]string{"--diff-algorithm=client"}, args...))
+		u.So(t, exitCode, gc.ShouldEqual, 1)
+		u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, strings.Join([]string{
+			"Destructive Schema Changes (these may delete data):",
+			"\t!! mongodb-atlas: store.items properties.price",
+			"\t\tthe property is removed, which may delete its data",
+		}, "\n"))
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, "--allow-destructive")
+		u.So(t, imported, gc.ShouldBeFalse)
+	})
+
+	t.Run("should import the changes with --allow-destructive", func(t *testing.T) {
+		var imported bool
+		importCommand, mockUI := setup(&imported)
+
+		exitCode := importCommand.Run(append([]string{"-y", "--allow-destructive"}, args...))
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+		u.So(t, imported, gc.ShouldBeTrue)
+	})
+}
+
+func TestImportAmbiguousApp(t *testing.T) {
+	setup := func() (*ImportCommand, *cli.MockUi, *u.MockRealmClient) {
+		importCommand, mockUI := setUpBasicCommand()
+		importCommand.user = &user.User{
+			APIKey:      "my-api-key",
+			AccessToken: u.GenerateValidAccessToken(),
+		}
+		realmClient := importCommand.realmClient.(*u.MockRealmClient)
+		realmClient.FindAppsFn = func(filter api.AppFilter) ([]*models.App, error) {
+			u.So(t, filter, gc.ShouldResemble, api.AppFilter{ClientAppID: "my-app-abcdef"})
+			return []*models.App{
+				{GroupID: "group-1", ID: "app-1", ClientAppID: "my-app-abcdef"},
+				{GroupID: "group-2", ID: "app-2", ClientAppID: "my-app-abcdef"},
+			}, nil
+		}
+		return importCommand, mockUI, realmClient
+	}
+
+	args := []string{"--app-id=my-app-abcdef", "--path=../testdata/simple_app"}
+
+	t.Run("should ask which app to import to", func(t *testing.T) {
+		importCommand, mockUI, realmClient := setup()
+		mockUI.InputReader = strings.NewReader("3\n2\ny\n")
+
+		exitCode := importCommand.Run(args)
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+		u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, strings.Join([]string{
+			`Apps matching "my-app-abcdef":`,
+			"1. my-app-abcdef in Project group-1 (app-1)",
+			"2. my-app-abcdef in Project group-2 (app-2)",
+			"Which app would you like to use:",
+		}, "\n"))
+		u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, "Could not understand response, valid values are 1, 2:")
+		u.So(t, realmClient.ImportFnCalls, gc.ShouldResemble, [][]string{{"group-2", "app-2"}})
+	})
+
+	t.Run("should list the apps instead of asking with --yes", func(t *testing.T) {
+		importCommand, mockUI, realmClient := setup()
+
+		exitCode := importCommand.Run(append([]string{"-y"}, args...))
+		u.So(t, exitCode, gc.ShouldEqual, 1)
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, strings.Join([]string{
+			`2 apps match "my-app-abcdef", pass --project-id to choose one:`,
+			"\tmy-app-abcdef in Project group-1 (app-1)",
+			"\tmy-app-abcdef in Project group-2 (app-2)",
+		}, "\n"))
+		u.So(t, realmClient.ImportFnCalls, gc.ShouldBeEmpty)
+	})
+}
+
+func TestImportToApp(t *testing.T) {
+	apps := []*models.App{
+		{GroupID: "group-1", ID: "5f0c1b2e9d3a4c5b6a7e8f90", ClientAppID: "my-app-abcdef"},
+		{GroupID: "group-2", ID: "5f0c1b2e9d3a4c5b6a7e8f91", ClientAppID: "my-app-abcdef"},
+	}
+
+	setup := func() (*ImportCommand, *cli.MockUi, *u.MockRealmClient, *[]api.AppFilter) {
+		importCommand, mockUI := setUpBasicCommand()
+		importCommand.user = &user.User{
+			APIKey:      "my-api-key",
+			AccessToken: u.GenerateValidAccessToken(),
+		}
+
+		var filters []api.AppFilter
+		realmClient := importCommand.realmClient.(*u.MockRealmClient)
+		realmClient.FindAppsFn = func(filter api.AppFilter) ([]*models.App, error) {
+			filters = append(filters, filter)
+			found := []*models.App{}
+			for _, app := range apps {
+				if (filter.GroupID == "" || app.GroupID == filter.GroupID) &&
+					(filter.AppID == "" || app.ID == filter.AppID) &&
+					(filter.ClientAppID == "" || app.ClientAppID == filter.ClientAppID) {
+					found = append(found, app)
+				}
+			}
+			return found, nil
+		}
+		realmClient.FetchAppByGroupIDAndClientAppIDFn = func(groupID, clientAppID string) (*models.App, error) {
+			for _, app := range apps {
+				if app.GroupID == groupID && app.ClientAppID == clientAppID {
+					return app, nil
+				}
+			}
+			return nil, api.ErrAppNotFound{ClientAppID: clientAppID}
+		}
+		return importCommand, mockUI, realmClient, &filters
+	}
+
+	t.Run("should import to the app with the ObjectID", func(t *testing.T) {
+		importCommand, mockUI, realmClient, filters := setup()
+
+		exitCode := importCommand.Run([]string{"-y", "--to-app=5f0c1b2e9d3a4c5b6a7e8f91", "--path=../testdata/simple_app"})
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+		u.So(t, (*filters)[0], gc.ShouldResemble, api.AppFilter{AppID: "5f0c1b2e9d3a4c5b6a7e8f91"})
+		u.So(t, realmClient.ImportFnCalls, gc.ShouldResemble, [][]string{{"group-2", "5f0c1b2e9d3a4c5b6a7e8f91"}})
+	})
+
+	t.Run("should import to the app with the client app ID in the project", func(t *testing.T) {
+		importCommand, mockUI, realmClient, filters := setup()
+
+		exitCode := importCommand.Run([]string{"-y", "--to-app=my-app-abcdef", "--project-id=group-1", "--path=../testdata/simple_app"})
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+		u.So(t, *filters, gc.ShouldBeEmpty)
+		u.So(t, realmClient.ImportFnCalls, gc.ShouldResemble, [][]string{{"group-1", "5f0c1b2e9d3a4c5b6a7e8f90"}})
+	})
+
+	t.Run("should list the apps matching the client app ID with --yes", func(t *testing.T) {
+		importCommand, mockUI, realmClient, _ := setup()
+
+		exitCode := importCommand.Run([]string{"-y", "--to-app=my-app-abcdef", "--path=../testdata/simple_app"})
+		u.So(t, exitCode, gc.ShouldEqual, 1)
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, strings.Join([]string{
+			`2 apps match "my-app-abcdef", pass --project-id to choose one:`,
+			"\tmy-app-abcdef in Project group-1 (5f0c1b2e9d3a4c5b6a7e8f90)",
+			"\tmy-app-abcdef in Project group-2 (5f0c1b2e9d3a4c5b6a7e8f91)",
+		}, "\n"))
+		u.So(t, realmClient.ImportFnCalls, gc.ShouldBeEmpty)
+	})
+
+	t.Run("should fail rather than create an app which does not exist", func(t *testing.T) {
+		importCommand, mockUI, realmClient, _ := setup()
+		realmClient.CreateEmptyAppFn = func(groupID, appName, locationName, deploymentModelName string) (*models.App, error) {
+			t.Fatal("the app should not be created")
+			return nil, nil
+		}
+
+		exitCode := importCommand.Run([]string{"-y", "--to-app=missing-app-abcdef", "--path=../testdata/simple_app"})
+		u.So(t, exitCode, gc.ShouldEqual, 1)
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, `Unable to find app with ID: "missing-app-abcdef"`)
+	})
+
+	t.Run("should not be used with --app-id", func(t *testing.T) {
+		importCommand, mockUI, _, _ := setup()
+
+		exitCode := importCommand.Run([]string{"--to-app=my-app-abcdef", "--app-id=my-app-abcdef"})
+		u.So(t, exitCode, gc.ShouldEqual, 1)
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, "--to-app cannot be used with --app-id or --as-new")
+	})
+}
+
+func TestImportToName(t *testing.T) {
+	setup := func(existing []*models.App) (*ImportCommand, *cli.MockUi, *u.MockRealmClient, *[]string) {
+		importCommand, mockUI := setUpBasicCommand()
+		importCommand.user = &user.User{
+			APIKey:      "my-api-key",
+			AccessToken: u.GenerateValidAccessToken(),
+		}
+
+		var createdApp []string
+		realmClient := importCommand.realmClient.(*u.MockRealmClient)
+		realmClient.FindAppsFn = func(filter api.AppFilter) ([]*models.App, error) {
+			u.So(t, filter, gc.ShouldResemble, api.AppFilter{GroupID: "group-id", Name: "my-app"})
+			return existing, nil
+		}
+		realmClient.FetchAppsByGroupIDFn = func(groupID string) ([]*models.App, error) {
+			return existing, nil
+		}
+		realmClient.CreateEmptyAppFn = func(groupID, appName, locationName, deploymentModelName string) (*models.App, error) {
+			createdApp = []string{groupID, appName, locationName, deploymentModelName}
+			return &models.App{GroupID: groupID, ID: "new-app-id", Name: appName, ClientAppID: appName + "-abcdef"}, nil
+		}
+		return importCommand, mockUI, realmClient, &createdApp
+	}
+
+	args := []string{"-y", "--to-name=my-app", "--project-id=group-id", "--path=../testdata/simple_app_with_deployment_config"}
+
+	t.Run("should update the app with the name if it exists", func(t *testing.T) {
+		importCommand, mockUI, realmClient, createdApp := setup([]*models.App{{GroupID: "group-id", ID: "app-id", Name: "my-app"}})
+
+		exitCode := importCommand.Run(args)
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+		u.So(t, *createdApp, gc.ShouldBeEmpty)
+		u.So(t, realmClient.ImportFnCalls, gc.ShouldResemble, [][]string{{"group-id", "app-id"}})
+	})
+
+	t.Run("should create the app with the config's location and deployment model without prompting", func(t *testing.T) {
+		importCommand, mockUI, realmClient, createdApp := setup([]*models.App{})
+
+		exitCode := importCommand.Run(args)
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+		u.So(t, mockUI.OutputWriter.String(), gc.ShouldNotContainSubstring, "would you like to create a new app?")
+		u.So(t, *createdApp, gc.ShouldResemble, []string{"group-id", "my-app", "IE", "LOCAL"})
+		u.So(t, realmClient.ImportFnCalls, gc.ShouldResemble, [][]string{{"group-id", "new-app-id"}})
+	})
+
+	t.Run("should ask before creating the app without --yes", func(t *testing.T) {
+		importCommand, mockUI, realmClient, createdApp := setup([]*models.App{})
+		mockUI.InputReader = strings.NewReader("n\n")
+
+		exitCode := importCommand.Run(args[1:])
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+		u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, `no app named "my-app" exists in project group-id: would you like to create a new app?`)
+		u.So(t, *createdApp, gc.ShouldBeEmpty)
+		u.So(t, realmClient.ImportFnCalls, gc.ShouldBeEmpty)
+	})
+
+	t.Run("should not be used with --app-id", func(t *testing.T) {
+		importCommand, mockUI, _, _ := setup(nil)
+
+		exitCode := importCommand.Run(append([]string{"--app-id=my-app-abcdef"}, args...))
+		u.So(t, exitCode, gc.ShouldEqual, 1)
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, "--to-name cannot be used with --app-id, --to-app, --as-new or --app-name")
+	})
+}
+
+func TestImportOutput(t *testing.T) {
+	setup := func() (*ImportCommand, *cli.MockUi) {
+		importCommand, mockUI := setUpBasicCommand()
+		importCommand.user = &user.User{
+			APIKey:      "my-api-key",
+			AccessToken: u.GenerateValidAccessToken(),
+		}
+		realmClient := importCommand.realmClient.(*u.MockRealmClient)
+		realmClient.FindAppsFn = func(filter api.AppFilter) ([]*models.App, error) {
+			return []*models.App{}, nil
+		}
+		realmClient.FetchAppsByGroupIDFn = func(groupID string) ([]*models.App, error) {
+			return []*models.App{}, nil
+		}
+		realmClient.CreateEmptyAppFn = func(groupID, appName, locationName, deploymentModelName string) (*models.App, error) {
+			return &models.App{GroupID: groupID, ID: "new-app-id", Name: appName, ClientAppID: appName + "-abcdef"}, nil
+		}
+		return importCommand, mockUI
+	}
+
+	args := []string{"-y", "--to-name=my-app", "--project-id=group-id", "--path=../testdata/simple_app_with_deployment_config"}
+
+	t.Run("should name both IDs of the imported app", func(t *testing.T) {
+		importCommand, mockUI := setup()
+
+		exitCode := importCommand.Run(args)
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+		u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, "Successfully imported 'my-app-abcdef' (ObjectID new-app-id in Project group-id)")
+	})
+
+	t.Run("should only print the IDs of the imported app as JSON with --output=json", func(t *testing.T) {
+		importCommand, mockUI := setup()
+
+		exitCode := importCommand.Run(append([]string{"--output=json"}, args...))
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, "New app created: my-app-abcdef")
+
+		var result map[string]interface{}
+		u.So(t, json.Unmarshal(mockUI.OutputWriter.Bytes(), &result), gc.ShouldBeNil)
+		u.So(t, result, gc.ShouldResemble, map[string]interface{}{
+			"group_id":      "group-id",
+			"app_id":        "new-app-id",
+			"client_app_id": "my-app-abcdef",
+			"created":       true,
+			"changed":       true,
+		})
+	})
+
+	t.Run("should include the timings in the JSON result with --timings", func(t *testing.T) {
+		importCommand, mockUI := setup()
+
+		exitCode := importCommand.Run(append([]string{"--output=json", "--timings"}, args...))
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldNotContainSubstring, "Timings:")
+
+		var result importResult
+		u.So(t, json.Unmarshal(mockUI.OutputWriter.Bytes(), &result), gc.ShouldBeNil)
+		u.So(t, result.Timings, gc.ShouldNotBeEmpty)
+		phases := make([]string, 0, len(result.Timings))
+		for _, timing := range result.Timings {
+			phases = append(phases, timing.Phase)
+		}
+		u.So(t, phases, gc.ShouldContain, importPhaseImport)
+	})
+
+	t.Run("should print the app as unchanged when it is already identical", func(t *testing.T) {
+		importCommand, mockUI := setup()
+		realmClient := importCommand.realmClient.(*u.MockRealmClient)
+		realmClient.FindAppsFn = func(filter api.AppFilter) ([]*models.App, error) {
+			return []*models.App{{GroupID: "group-id", ID: "app-id", Name: "my-app", ClientAppID: "my-app-abcdef"}}, nil
+		}
+		realmClient.DiffFn = func(groupID, appID string, appData []byte, strategy string) ([]string, error) {
+			return []string{}, nil
+		}
+
+		exitCode := importCommand.Run([]string{"--output=json", "--to-name=my-app", "--project-id=group-id", "--path=../testdata/simple_app_with_deployment_config"})
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, "Deployed app is identical to proposed version")
+
+		var result map[string]interface{}
+		u.So(t, json.Unmarshal(mockUI.OutputWriter.Bytes(), &result), gc.ShouldBeNil)
+		u.So(t, result, gc.ShouldResemble, map[string]interface{}{
+			"group_id":      "group-id",
+			"app_id":        "app-id",
+			"client_app_id": "my-app-abcdef",
+			"created":       false,
+			"changed":       false,
+		})
+	})
+
+	t.Run("should print nothing as JSON when the import is not confirmed", func(t *testing.T) {
+		importCommand, mockUI := setup()
+		realmClient := importCommand.realmClient.(*u.MockRealmClient)
+		realmClient.FindAppsFn = func(filter api.AppFilter) ([]*models.App, error) {
+			return []*models.App{{GroupID: "group-id", ID: "app-id", Name: "my-app", ClientAppID: "my-app-abcdef"}}, nil
+		}
+		realmClient.DiffFn = func(groupID, appID string, appData []byte, strategy string) ([]string, error) {
+			return []string{"sample-diff-contents"}, nil
+		}
+		mockUI.InputReader = strings.NewReader("n\n")
+
+		exitCode := importCommand.Run([]string{"--output=json", "--to-name=my-app", "--project-id=group-id", "--path=../testdata/simple_app_with_deployment_config"})
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, "sample-diff-contents")
+		u.So(t, mockUI.OutputWriter.String(), gc.ShouldNotContainSubstring, "client_app_id")
+	})
+
+	t.Run("should reject an unknown output format", func(t *testing.T) {
+		importCommand, mockUI := setup()
+
+		exitCode := importCommand.Run(append([]string{"-o", "yaml"}, args...))
+		u.So(t, exitCode, gc.ShouldEqual, 1)
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, `unknown output format "yaml"; accepted values are [text|json]`)
+	})
+}
+
+func TestImportEnvironmentVariables(t *testing.T) {
+	const template = `{"values": {"greeting": "${REALM_CLI_TEST_GREETING}"}}`
+
+	setup := func(t *testing.T) (*ImportCommand, *cli.MockUi, string, *[]byte) {
+		appPath, err := ioutil.TempDir("", "realm-import-environments-")
+		u.So(t, err, gc.ShouldBeNil)
+
+		u.So(t, copyDirectory("../testdata/simple_app_with_deployment_config", appPath), gc.ShouldBeNil)
+		u.So(t, ioutil.WriteFile(filepath.Join(appPath, "environments", "production.json"), []byte(template), 0644), gc.ShouldBeNil)
+
+		importCommand, mockUI := setUpBasicCommand()
+		importCommand.user = &user.User{
+			APIKey:      "my-api-key",
+			AccessToken: u.GenerateValidAccessToken(),
+		}
+
+		var imported []byte
+		realmClient := importCommand.realmClient.(*u.MockRealmClient)
+		realmClient.FetchAppByClientAppIDFn = func(clientAppID string) (*models.App, error) {
+			return &models.App{GroupID: "group-id", ID: "app-id", ClientAppID: clientAppID}, nil
+		}
+		realmClient.ImportFn = func(ctx context.Context, groupID, appID string, appData []byte, strategy string) error {
+			imported = appData
+			return nil
+		}
+		// the deployed app is written back with the values the variables were replaced with
+		importCommand.writeToDirectory = func(dest string, r io.Reader, overwrite bool) error {
+			path := filepath.Join(dest, "environments", "production.json")
+			if err := os.Remove(path); err != nil {
+				return err
+			}
+			return ioutil.WriteFile(path, []byte(`{"values": {"greeting": "hi"}}`), 0600)
+		}
+		return importCommand, mockUI, appPath, &imported
+	}
+
+	t.Run("should fail before importing if a variable is not set", func(t *testing.T) {
+		importCommand, mockUI, appPath, imported := setup(t)
+		defer os.RemoveAll(appPath)
+
+		exitCode := importCommand.Run([]string{"-y", "--app-id=my-app-abcdef", "--path=" + appPath})
+		u.So(t, exitCode, gc.ShouldEqual, 1)
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, strings.Join([]string{
+			"failed to resolve the values of the environments:",
+			"\tenvironments/production.json: values.greeting: environment variable REALM_CLI_TEST_GREETING is not set",
+		}, "\n"))
+		u.So(t, *imported, gc.ShouldBeNil)
+	})
+
+	t.Run("should import the values of the variables and keep referencing them", func(t *testing.T) {
+		importCommand, mockUI, appPath, imported := setup(t)
+		defer os.RemoveAll(appPath)
+
+		os.Setenv("REALM_CLI_TEST_GREETING", "hi")
+		defer os.Unsetenv("REALM_CLI_TEST_GREETING")
+
+		exitCode := importCommand.Run([]string{"-y", "--app-id=my-app-abcdef", "--path=" + appPath})
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+
+		var app map[string]interface{}
+		u.So(t, json.Unmarshal(*imported, &app), gc.ShouldBeNil)
+		u.So(t, app["environments"].(map[string]interface{})["production.json"], gc.ShouldResemble, map[string]interface{}{
+			"values": map[string]interface{}{"greeting": "hi"},
+		})
+
+		data, err := ioutil.ReadFile(filepath.Join(appPath, "environments", "production.json"))
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, string(data), gc.ShouldEqual, template)
+
+		info, err := os.Stat(filepath.Join(appPath, "environments", "production.json"))
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, info.Mode().Perm(), gc.ShouldEqual, os.FileMode(0644))
+	})
+}
@@ -0,0 +1,64 @@
+package commands
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mitchellh/cli"
+)
+
+// The phases of an import which are timed with --timings
+const (
+	importPhaseDiff         = "diff"
+	importPhaseDraft        = "draft"
+	importPhaseImport       = "import"
+	importPhaseDeploy       = "deploy"
+	importPhaseHosting      = "hosting"
+	importPhaseDependencies = "dependencies"
+)
+
+// phaseTiming is how long a single phase of an import took
+type phaseTiming struct {
+	Phase    string        `json:"phase"`
+	Duration time.Duration `json:"-"`
+	Millis   int64         `json:"duration_ms"`
+}
+
+// importTimings records how long each phase of an import took, in the order the phases first ran.
+// Phases may run concurrently, e.g. diffing the app and its hosting assets
+type importTimings struct {
+	mu     sync.Mutex
+	phases []phaseTiming
+}
+
+// start begins timing the named phase and returns a func which ends it. A phase which runs
+// more than once, e.g. diffing and then uploading hosting assets, accumulates its durations
+func (it *importTimings) start(phase string) func() {
+	started := time.Now()
+	return func() {
+		elapsed := time.Since(started)
+
+		it.mu.Lock()
+		defer it.mu.Unlock()
+		for i := range it.phases {
+			if it.phases[i].Phase == phase {
+				it.phases[i].Duration += elapsed
+				it.phases[i].Millis = it.phases[i].Duration.Milliseconds()
+				return
+			}
+		}
+		it.phases = append(it.phases, phaseTiming{Phase: phase, Duration: elapsed, Millis: elapsed.Milliseconds()})
+	}
+}
+
+func (it *importTimings) print(ui cli.Ui) {
+	if len(it.phases) == 0 {
+		return
+	}
+
+	ui.Info("Timings:")
+	for _, phase := range it.phases {
+		ui.Info(fmt.Sprintf("\t%s: %s", phase.Phase, phase.Duration.Round(time.Millisecond)))
+	}
+}
@@ -0,0 +1,69 @@
+package commands
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/10gen/realm-cli/user"
+	u "github.com/10gen/realm-cli/utils/test"
+	gc "github.com/smartystreets/goconvey/convey"
+)
+
+func TestImportTimings(t *testing.T) {
+	loggedInUser := &user.User{
+		APIKey:      "my-api-key",
+		AccessToken: u.GenerateValidAccessToken(),
+	}
+	args := []string{"--app-id=my-app-abcdef", "--path=../testdata/full_app", "--timings"}
+
+	t.Run("import prints the duration of each phase once it finishes", func(t *testing.T) {
+		importCommand, mockUI := setUpBasicCommand()
+		importCommand.user = loggedInUser
+		importCommand.syntaxChecker = nil
+
+		exitCode := importCommand.Run(append([]string{"-y"}, args...))
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+
+		output := mockUI.OutputWriter.String()
+		u.So(t, output, gc.ShouldContainSubstring, "Timings:\n")
+		for _, phase := range []string{importPhaseDraft, importPhaseImport, importPhaseDeploy} {
+			u.So(t, output, gc.ShouldContainSubstring, "\t"+phase+": ")
+		}
+		u.So(t, output, gc.ShouldNotContainSubstring, "\t"+importPhaseDiff+": ")
+	})
+
+	t.Run("import does not print timings without --timings", func(t *testing.T) {
+		importCommand, mockUI := setUpBasicCommand()
+		importCommand.user = loggedInUser
+		importCommand.syntaxChecker = nil
+
+		exitCode := importCommand.Run([]string{"-y", "--app-id=my-app-abcdef", "--path=../testdata/full_app"})
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+		u.So(t, mockUI.OutputWriter.String(), gc.ShouldNotContainSubstring, "Timings:")
+	})
+
+	t.Run("diff includes the timings in its json output", func(t *testing.T) {
+		diffCommand, mockUI := setUpBasicDiffCommand()
+		diffCommand.user = loggedInUser
+
+		exitCode := diffCommand.Run(append([]string{"-o", "json"}, args...))
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+		u.So(t, mockUI.OutputWriter.String(), gc.ShouldNotContainSubstring, "Timings:")
+
+		var output diffOutput
+		u.So(t, json.Unmarshal(mockUI.OutputWriter.Bytes(), &output), gc.ShouldBeNil)
+		u.So(t, output.Timings, gc.ShouldHaveLength, 1)
+		u.So(t, output.Timings[0].Phase, gc.ShouldEqual, importPhaseDiff)
+	})
+}
+
+func TestImportTimingsStart(t *testing.T) {
+	var timings importTimings
+	for _, phase := range []string{importPhaseHosting, importPhaseDiff, importPhaseHosting} {
+		timings.start(phase)()
+	}
+
+	u.So(t, timings.phases, gc.ShouldHaveLength, 2)
+	u.So(t, timings.phases[0].Phase, gc.ShouldEqual, importPhaseHosting)
+	u.So(t, timings.phases[1].Phase, gc.ShouldEqual, importPhaseDiff)
+}
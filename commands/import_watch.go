@@ -0,0 +1,211 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/10gen/realm-cli/utils"
+)
+
+// How often the app directory is checked for changes with --watch, and how long it must stay
+// unchanged before the app is redeployed, so that a burst of saves results in a single redeploy.
+// The directory is polled rather than watched with file system notifications, which would take a
+// watch on every directory of the app, running into the limit on watches of large hosting or
+// node_modules trees, and are not delivered for network or container mounts. Each check walks the
+// whole app, so it is not made more often than needed to pick up a save
+const (
+	importWatchInterval = time.Second
+	importWatchDebounce = 500 * time.Millisecond
+)
+
+// importWatchTimeFormat is how the time of each redeploy is printed
+const importWatchTimeFormat = "15:04:05"
+
+// watchedFile is the state of a file that tells whether it has changed
+type watchedFile struct {
+	size    int64
+	modTime time.Time
+}
+
+// appSnapshot maps the path of each watched file of an app to its state
+type appSnapshot map[string]watchedFile
+
+// snapshotApp records the state of the files of the app at appPath. The hosting assets and the
+// dependencies, including the node_modules directory they are installed into, are only watched
+// when they are imported as well, and the files left out by the .realmignore are never watched
+func snapshotApp(appPath string, includeHosting, includeDependencies bool) (appSnapshot, error) {
+	hostingDir := filepath.Join(appPath, utils.HostingFilesDirectory)
+	functionsDir := filepath.Join(appPath, utils.FunctionsRoot)
+	nodeModulesDir := filepath.Join(functionsDir, "node_modules")
+
+	realmIgnore, err := utils.ReadRealmIgnoreFile(appPath)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := appSnapshot{}
+	err = filepath.Walk(appPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			// editors remove the files they save through, e.g. swap files, between listing and reading them
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+
+		if info.IsDir() {
+			if info.Name() == ".git" || (!includeHosting && path == hostingDir) ||
+				(!includeDependencies && path == nodeModulesDir) ||
+				(path != appPath && realmIgnore.Ignored(path, true)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if realmIgnore.Ignored(path, false) {
+			return nil
+		}
+
+		if !includeDependencies && filepath.Dir(path) == functionsDir &&
+			(strings.Contains(info.Name(), "node_modules") || info.Name() == "package.json") {
+			return nil
+		}
+
+		snapshot[path] = watchedFile{info.Size(), info.ModTime()}
+		return nil
+	})
+	return snapshot, err
+}
+
+func (as appSnapshot) equal(other appSnapshot) bool {
+	if len(as) != len(other) {
+		return false
+	}
+	for path, file := range as {
+		otherFile, ok := other[path]
+		if !ok || otherFile.size != file.size || !otherFile.modTime.Equal(file.modTime) {
+			return false
+		}
+	}
+	return true
+}
+
+// watchApp checks the files of the app at appPath for changes every interval, and calls redeploy
+// once they have stayed unchanged for the debounce duration, until stop is closed. The files are
+// checked again after an error, e.g. from a half-edited .realmignore, which is passed to report
+// unless it is the same as the previous one
+func watchApp(appPath string, includeHosting, includeDependencies bool, interval, debounce time.Duration, stop <-chan struct{}, report func(error), redeploy func()) error {
+	last, err := snapshotApp(appPath, includeHosting, includeDependencies)
+	if err != nil {
+		return err
+	}
+
+	var lastErr string
+	snapshot := func() (appSnapshot, bool) {
+		current, err := snapshotApp(appPath, includeHosting, includeDependencies)
+		if err != nil {
+			if err.Error() != lastErr {
+				report(err)
+			}
+			lastErr = err.Error()
+			return nil, false
+		}
+		lastErr = ""
+		return current, true
+	}
+
+	wait := func(d time.Duration) bool {
+		select {
+		case <-stop:
+			return false
+		case <-time.After(d):
+			return true
+		}
+	}
+
+	for {
+		if !wait(interval) {
+			return nil
+		}
+
+		current, ok := snapshot()
+		if !ok || current.equal(last) {
+			continue
+		}
+
+		for {
+			if !wait(debounce) {
+				return nil
+			}
+
+			next, ok := snapshot()
+			if !ok {
+				continue
+			}
+			if next.equal(current) {
+				break
+			}
+			current = next
+		}
+
+		redeploy()
+
+		// the import itself may write to the app, e.g. its app ID once it is created
+		last = current
+		if next, ok := snapshot(); ok {
+			last = next
+		}
+	}
+}
+
+// watch redeploys the app whenever its files change, until interrupted
+func (ic *ImportCommand) watch() error {
+	appPath, err := utils.ResolveAppDirectory(ic.flagAppPath, ic.workingDirectory)
+	if err != nil {
+		return err
+	}
+
+	stop := make(chan struct{})
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	defer signal.Stop(interrupts)
+	go func() {
+		<-interrupts
+		close(stop)
+	}()
+
+	ic.UI.Info(fmt.Sprintf("Watching %s for changes, press Ctrl-C to stop...", appPath))
+
+	// redeploys are neither confirmed nor made when nothing changed
+	ic.watching = true
+	report := func(err error) {
+		ic.UI.Error(fmt.Sprintf("[%s] failed to check %s for changes, checking again: %s", time.Now().Format(importWatchTimeFormat), appPath, err))
+	}
+	err = watchApp(appPath, ic.flagIncludeHosting, ic.flagIncludeDependencies, importWatchInterval, importWatchDebounce, stop, report, func() {
+		ic.UI.Info(fmt.Sprintf("[%s] Changes detected, redeploying...", time.Now().Format(importWatchTimeFormat)))
+
+		ic.noChanges = false
+		ic.timings = importTimings{}
+		dryRun := false
+		if err := ic.importApp(dryRun); err != nil {
+			ic.UI.Error(fmt.Sprintf("[%s] %s", time.Now().Format(importWatchTimeFormat), err))
+			return
+		}
+		if ic.flagTimings {
+			ic.timings.print(ic.UI)
+		}
+		if !ic.noChanges {
+			ic.UI.Info(fmt.Sprintf("[%s] Redeployed app", time.Now().Format(importWatchTimeFormat)))
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	ic.UI.Info("Stopped watching for changes.")
+	return nil
+}
@@ -0,0 +1,157 @@
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/10gen/realm-cli/utils"
+	u "github.com/10gen/realm-cli/utils/test"
+	gc "github.com/smartystreets/goconvey/convey"
+)
+
+// watchReporting runs watchApp while calling change, and returns how many times the app was redeployed
+// along with the errors reported while watching it
+func watchReporting(t *testing.T, appPath string, includeHosting bool, change func()) (int, []error) {
+	stop := make(chan struct{})
+	redeploys := make(chan struct{}, 10)
+	reported := make(chan error, 10)
+	done := make(chan error)
+	go func() {
+		report := func(err error) { reported <- err }
+		done <- watchApp(appPath, includeHosting, false, 5*time.Millisecond, 20*time.Millisecond, stop, report, func() {
+			redeploys <- struct{}{}
+		})
+	}()
+
+	// give the watcher time to take its first snapshot
+	time.Sleep(20 * time.Millisecond)
+	change()
+	time.Sleep(200 * time.Millisecond)
+
+	close(stop)
+	u.So(t, <-done, gc.ShouldBeNil)
+	close(reported)
+
+	var errs []error
+	for err := range reported {
+		errs = append(errs, err)
+	}
+	return len(redeploys), errs
+}
+
+func TestWatchApp(t *testing.T) {
+	setup := func(t *testing.T) string {
+		appPath, err := ioutil.TempDir("", "realm-cli-watch")
+		u.So(t, err, gc.ShouldBeNil)
+
+		for _, dir := range []string{utils.FunctionsRoot, utils.HostingFilesDirectory} {
+			u.So(t, os.MkdirAll(filepath.Join(appPath, dir), os.ModePerm), gc.ShouldBeNil)
+		}
+		u.So(t, ioutil.WriteFile(filepath.Join(appPath, "config.json"), []byte("{}"), 0600), gc.ShouldBeNil)
+		return appPath
+	}
+
+	// watch runs watchApp while calling change, and returns how many times the app was redeployed
+	watch := func(t *testing.T, appPath string, includeHosting bool, change func()) int {
+		redeploys, _ := watchReporting(t, appPath, includeHosting, change)
+		return redeploys
+	}
+
+	t.Run("should redeploy once after a burst of changes", func(t *testing.T) {
+		appPath := setup(t)
+		defer os.RemoveAll(appPath)
+
+		redeploys := watch(t, appPath, false, func() {
+			for i, source := range []string{"exports = 1", "exports = 2", "exports = 3"} {
+				path := filepath.Join(appPath, utils.FunctionsRoot, "source.js")
+				u.So(t, ioutil.WriteFile(path, []byte(source), 0600), gc.ShouldBeNil)
+				if i < 2 {
+					time.Sleep(5 * time.Millisecond)
+				}
+			}
+		})
+		u.So(t, redeploys, gc.ShouldEqual, 1)
+	})
+
+	t.Run("should not redeploy when nothing changed", func(t *testing.T) {
+		appPath := setup(t)
+		defer os.RemoveAll(appPath)
+
+		u.So(t, watch(t, appPath, false, func() {}), gc.ShouldEqual, 0)
+	})
+
+	t.Run("should ignore hosting assets unless they are included", func(t *testing.T) {
+		for _, tc := range []struct {
+			includeHosting    bool
+			expectedRedeploys int
+		}{
+			{false, 0},
+			{true, 1},
+		} {
+			appPath := setup(t)
+			defer os.RemoveAll(appPath)
+
+			redeploys := watch(t, appPath, tc.includeHosting, func() {
+				path := filepath.Join(appPath, utils.HostingFilesDirectory, "index.html")
+				u.So(t, ioutil.WriteFile(path, []byte("<html></html>"), 0600), gc.ShouldBeNil)
+			})
+			u.So(t, redeploys, gc.ShouldEqual, tc.expectedRedeploys)
+		}
+	})
+
+	t.Run("should keep watching after the app could not be checked", func(t *testing.T) {
+		appPath := setup(t)
+		defer os.RemoveAll(appPath)
+
+		realmIgnorePath := filepath.Join(appPath, utils.RealmIgnoreFileName)
+		redeploys, errs := watchReporting(t, appPath, false, func() {
+			// a half-edited .realmignore is reported once, however often the app is checked
+			u.So(t, ioutil.WriteFile(realmIgnorePath, []byte("[]"), 0600), gc.ShouldBeNil)
+			time.Sleep(50 * time.Millisecond)
+			u.So(t, ioutil.WriteFile(realmIgnorePath, []byte("*.swp"), 0600), gc.ShouldBeNil)
+		})
+		u.So(t, redeploys, gc.ShouldEqual, 1)
+		u.So(t, errs, gc.ShouldHaveLength, 1)
+		u.So(t, errs[0].Error(), gc.ShouldContainSubstring, "failed to read .realmignore")
+	})
+}
+
+func TestSnapshotApp(t *testing.T) {
+	t.Run("should leave out the dependencies unless they are included", func(t *testing.T) {
+		appPath := "../testdata/app_with_dependencies"
+		dependencies := filepath.Join(appPath, utils.FunctionsRoot, "node_modules.tar")
+
+		snapshot, err := snapshotApp(appPath, false, false)
+		u.So(t, err, gc.ShouldBeNil)
+		_, ok := snapshot[dependencies]
+		u.So(t, ok, gc.ShouldBeFalse)
+
+		snapshot, err = snapshotApp(appPath, false, true)
+		u.So(t, err, gc.ShouldBeNil)
+		_, ok = snapshot[dependencies]
+		u.So(t, ok, gc.ShouldBeTrue)
+	})
+
+	t.Run("should not walk the installed node_modules unless the dependencies are included", func(t *testing.T) {
+		appPath, err := ioutil.TempDir("", "realm-cli-watch")
+		u.So(t, err, gc.ShouldBeNil)
+		defer os.RemoveAll(appPath)
+
+		module := filepath.Join(appPath, utils.FunctionsRoot, "node_modules", "axios", "index.js")
+		u.So(t, os.MkdirAll(filepath.Dir(module), os.ModePerm), gc.ShouldBeNil)
+		u.So(t, ioutil.WriteFile(module, []byte("module.exports = {}"), 0600), gc.ShouldBeNil)
+
+		snapshot, err := snapshotApp(appPath, false, false)
+		u.So(t, err, gc.ShouldBeNil)
+		_, ok := snapshot[module]
+		u.So(t, ok, gc.ShouldBeFalse)
+
+		snapshot, err = snapshotApp(appPath, false, true)
+		u.So(t, err, gc.ShouldBeNil)
+		_, ok = snapshot[module]
+		u.So(t, ok, gc.ShouldBeTrue)
+	})
+}
@@ -0,0 +1,78 @@
+package commands
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/10gen/realm-cli/models"
+	"github.com/10gen/realm-cli/utils"
+
+	"github.com/mitchellh/go-homedir"
+)
+
+const appArchiveExt = ".zip"
+
+// isAppArchive reports whether the --path given is a zipped app, such as one written by "export --format=zip",
+// rather than an app directory
+func isAppArchive(appPath string) bool {
+	if !strings.EqualFold(filepath.Ext(appPath), appArchiveExt) {
+		return false
+	}
+
+	path, err := homedir.Expand(appPath)
+	if err != nil {
+		return false
+	}
+
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+// extractAppArchive unpacks the zipped app at archivePath into a temporary directory, returning the path of
+// the app within it along with a func which removes the directory once it is no longer needed. The app may be
+// at the root of the archive or within a single directory, as when an app directory is zipped by hand
+func extractAppArchive(archivePath string) (string, func(), error) {
+	path, err := homedir.Expand(archivePath)
+	if err != nil {
+		return "", nil, err
+	}
+
+	archive, err := os.Open(path)
+	if err != nil {
+		return "", nil, err
+	}
+	defer archive.Close()
+
+	dir, err := ioutil.TempDir("", "realm-app-archive-")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+
+	if err := utils.WriteZipToDir(dir, archive, true); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("failed to extract %q: %s", archivePath, err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, models.AppConfigFileName)); err == nil {
+		return dir, cleanup, nil
+	}
+
+	fileInfos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	if len(fileInfos) == 1 && fileInfos[0].IsDir() {
+		appDir := filepath.Join(dir, fileInfos[0].Name())
+		if _, err := os.Stat(filepath.Join(appDir, models.AppConfigFileName)); err == nil {
+			return appDir, cleanup, nil
+		}
+	}
+
+	cleanup()
+	return "", nil, fmt.Errorf("no app found in %q: missing %s", archivePath, models.AppConfigFileName)
+}
@@ -0,0 +1,144 @@
+package commands
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/10gen/realm-cli/user"
+	"github.com/10gen/realm-cli/utils"
+	u "github.com/10gen/realm-cli/utils/test"
+	gc "github.com/smartystreets/goconvey/convey"
+
+	"github.com/mitchellh/cli"
+)
+
+// writeAppArchive zips the files into a temporary archive, returning its path
+func writeAppArchive(t *testing.T, files map[string]string) string {
+	archive, err := ioutil.TempFile("", "realm-app-*.zip")
+	u.So(t, err, gc.ShouldBeNil)
+	defer archive.Close()
+
+	w := zip.NewWriter(archive)
+	for name, contents := range files {
+		f, err := w.Create(name)
+		u.So(t, err, gc.ShouldBeNil)
+		_, err = f.Write([]byte(contents))
+		u.So(t, err, gc.ShouldBeNil)
+	}
+	u.So(t, w.Close(), gc.ShouldBeNil)
+	return archive.Name()
+}
+
+func TestIsAppArchive(t *testing.T) {
+	archivePath := writeAppArchive(t, map[string]string{"config.json": "{}"})
+	defer os.Remove(archivePath)
+
+	u.So(t, isAppArchive(archivePath), gc.ShouldBeTrue)
+	u.So(t, isAppArchive("../testdata/simple_app"), gc.ShouldBeFalse)
+	u.So(t, isAppArchive("../testdata/missing_app.zip"), gc.ShouldBeFalse)
+	u.So(t, isAppArchive(""), gc.ShouldBeFalse)
+}
+
+func TestExtractAppArchive(t *testing.T) {
+	for _, tc := range []struct {
+		Description string
+		Files       map[string]string
+	}{
+		{
+			Description: "should extract an app at the root of the archive",
+			Files:       map[string]string{"config.json": `{"name":"my-app"}`, "values/greeting.json": `{"name":"greeting"}`},
+		},
+		{
+			Description: "should extract an app within a single directory of the archive",
+			Files:       map[string]string{"my-app/config.json": `{"name":"my-app"}`, "my-app/values/greeting.json": `{"name":"greeting"}`},
+		},
+	} {
+		t.Run(tc.Description, func(t *testing.T) {
+			archivePath := writeAppArchive(t, tc.Files)
+			defer os.Remove(archivePath)
+
+			appPath, cleanup, err := extractAppArchive(archivePath)
+			u.So(t, err, gc.ShouldBeNil)
+
+			app, err := utils.UnmarshalFromDir(appPath)
+			u.So(t, err, gc.ShouldBeNil)
+			u.So(t, app["name"], gc.ShouldEqual, "my-app")
+			u.So(t, app["values"], gc.ShouldHaveLength, 1)
+
+			cleanup()
+			_, err = os.Stat(appPath)
+			u.So(t, os.IsNotExist(err), gc.ShouldBeTrue)
+		})
+	}
+
+	t.Run("should fail without an app in the archive", func(t *testing.T) {
+		archivePath := writeAppArchive(t, map[string]string{"README.md": "hello"})
+		defer os.Remove(archivePath)
+
+		_, _, err := extractAppArchive(archivePath)
+		u.So(t, err, gc.ShouldBeError, `no app found in "`+archivePath+`": missing config.json`)
+	})
+
+	t.Run("should fail with an incomplete archive", func(t *testing.T) {
+		archivePath := writeAppArchive(t, map[string]string{"config.json": `{"name":"my-app"}`})
+		defer os.Remove(archivePath)
+
+		data, err := ioutil.ReadFile(archivePath)
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, ioutil.WriteFile(archivePath, data[:len(data)-10], 0644), gc.ShouldBeNil)
+
+		_, _, err = extractAppArchive(archivePath)
+		u.So(t, err, gc.ShouldNotBeNil)
+		u.So(t, err.Error(), gc.ShouldStartWith, `failed to extract "`+archivePath+`"`)
+	})
+}
+
+func TestImportZippedApp(t *testing.T) {
+	zipped, err := ioutil.TempFile("", "realm-app-*.zip")
+	u.So(t, err, gc.ShouldBeNil)
+	defer os.Remove(zipped.Name())
+
+	_, err = io.Copy(zipped, u.NewZipResponseBody("../testdata/simple_app"))
+	zipped.Close()
+	u.So(t, err, gc.ShouldBeNil)
+
+	setup := func(imported *map[string]interface{}) (*ImportCommand, *cli.MockUi) {
+		importCommand, mockUI := setUpBasicCommand()
+		importCommand.user = &user.User{
+			APIKey:      "my-api-key",
+			AccessToken: u.GenerateValidAccessToken(),
+		}
+
+		realmClient := setUpBasicRealmClient()
+		realmClient.ImportFn = func(ctx context.Context, groupID, appID string, appData []byte, strategy string) error {
+			return json.Unmarshal(appData, imported)
+		}
+		importCommand.realmClient = realmClient
+		return importCommand, mockUI
+	}
+
+	t.Run("should import the app in the archive", func(t *testing.T) {
+		var imported map[string]interface{}
+		importCommand, mockUI := setup(&imported)
+
+		exitCode := importCommand.Run([]string{"--app-id=my-app-abcdef", "--path=" + zipped.Name(), "-y"})
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+		u.So(t, imported["name"], gc.ShouldEqual, "simple-app")
+	})
+
+	t.Run("should not watch a zipped app", func(t *testing.T) {
+		var imported map[string]interface{}
+		importCommand, mockUI := setup(&imported)
+
+		exitCode := importCommand.Run([]string{"--app-id=my-app-abcdef", "--path=" + zipped.Name(), "--watch"})
+		u.So(t, exitCode, gc.ShouldEqual, 1)
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, "--watch cannot be used with a zipped app")
+		u.So(t, imported, gc.ShouldBeNil)
+	})
+}
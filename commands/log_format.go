@@ -0,0 +1,80 @@
+package commands
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/cli"
+)
+
+const (
+	flagLogFormatName = "log-format"
+
+	logFormatText = "text"
+	logFormatJSON = "json"
+)
+
+var logFormats = []string{logFormatText, logFormatJSON}
+
+// the levels of the messages printed with --log-format=json
+const (
+	logLevelInfo  = "info"
+	logLevelWarn  = "warn"
+	logLevelError = "error"
+)
+
+// logEntry is a single message printed with --log-format=json
+type logEntry struct {
+	Level     string          `json:"level"`
+	Timestamp time.Time       `json:"timestamp"`
+	Message   string          `json:"message,omitempty"`
+	Data      json.RawMessage `json:"data,omitempty"`
+}
+
+// jsonUi is a cli.Ui which prints each message as a JSON object on its own line, e.g. for log systems
+// to ingest. Messages which are JSON objects or arrays themselves, such as the output of "diff --output json",
+// are kept as the data of the entry rather than quoted. Prompts are asked as they are
+type jsonUi struct {
+	cli.Ui
+
+	now func() time.Time
+}
+
+// Output prints the message as an info entry
+func (ui *jsonUi) Output(message string) {
+	ui.Ui.Output(ui.entry(logLevelInfo, message))
+}
+
+// Info prints the message as an info entry
+func (ui *jsonUi) Info(message string) {
+	ui.Ui.Info(ui.entry(logLevelInfo, message))
+}
+
+// Warn prints the message as a warn entry
+func (ui *jsonUi) Warn(message string) {
+	ui.Ui.Warn(ui.entry(logLevelWarn, message))
+}
+
+// Error prints the message as an error entry
+func (ui *jsonUi) Error(message string) {
+	ui.Ui.Error(ui.entry(logLevelError, message))
+}
+
+func (ui *jsonUi) entry(level, message string) string {
+	entry := logEntry{Level: level, Timestamp: ui.now().UTC()}
+
+	trimmed := strings.TrimSpace(message)
+	if (strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[")) && json.Valid([]byte(trimmed)) {
+		entry.Data = json.RawMessage(trimmed)
+	} else {
+		entry.Message = message
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		// a log entry only holds strings and valid JSON, so this should not happen
+		return message
+	}
+	return string(data)
+}
@@ -0,0 +1,75 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	u "github.com/10gen/realm-cli/utils/test"
+	gc "github.com/smartystreets/goconvey/convey"
+
+	"github.com/mitchellh/cli"
+)
+
+func TestJSONUi(t *testing.T) {
+	now := time.Date(2020, time.June, 3, 12, 30, 0, 0, time.UTC)
+
+	t.Run("prints each message as a JSON line with its level", func(t *testing.T) {
+		mockUI := cli.NewMockUi()
+		ui := &jsonUi{Ui: mockUI, now: func() time.Time { return now }}
+
+		ui.Info("Deploying app...")
+		ui.Output(`a "quoted" message`)
+		ui.Warn("careful")
+		ui.Error("failed")
+
+		u.So(t, mockUI.OutputWriter.String(), gc.ShouldEqual,
+			`{"level":"info","timestamp":"2020-06-03T12:30:00Z","message":"Deploying app..."}`+"\n"+
+				`{"level":"info","timestamp":"2020-06-03T12:30:00Z","message":"a \"quoted\" message"}`+"\n")
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldEqual,
+			`{"level":"warn","timestamp":"2020-06-03T12:30:00Z","message":"careful"}`+"\n"+
+				`{"level":"error","timestamp":"2020-06-03T12:30:00Z","message":"failed"}`+"\n")
+	})
+
+	t.Run("keeps JSON messages as the data of the entry", func(t *testing.T) {
+		mockUI := cli.NewMockUi()
+		ui := &jsonUi{Ui: mockUI, now: func() time.Time { return now }}
+
+		ui.Info("{\n  \"changed\": true\n}")
+		ui.Info("[1, 2]")
+		ui.Info("{not json")
+
+		u.So(t, mockUI.OutputWriter.String(), gc.ShouldEqual,
+			`{"level":"info","timestamp":"2020-06-03T12:30:00Z","data":{"changed":true}}`+"\n"+
+				`{"level":"info","timestamp":"2020-06-03T12:30:00Z","data":[1,2]}`+"\n"+
+				`{"level":"info","timestamp":"2020-06-03T12:30:00Z","message":"{not json"}`+"\n")
+	})
+}
+
+func TestLogFormatFlag(t *testing.T) {
+	setup := func() (*ValidateCommand, *cli.MockUi) {
+		mockUI := cli.NewMockUi()
+		cmd, err := NewValidateCommandFactory(mockUI)()
+		if err != nil {
+			panic(err)
+		}
+
+		validateCommand := cmd.(*ValidateCommand)
+		validateCommand.storage = u.NewEmptyStorage()
+		return validateCommand, mockUI
+	}
+
+	t.Run("prints the messages of a command as JSON with --log-format=json", func(t *testing.T) {
+		validateCommand, mockUI := setup()
+		exitCode := validateCommand.Run([]string{"--path=../testdata/full_app", "--log-format=json"})
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+		u.So(t, mockUI.OutputWriter.String(), gc.ShouldStartWith, `{"level":"info","timestamp":"`)
+		u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, `"message":"No problems found in the app at ../testdata/full_app"}`)
+	})
+
+	t.Run("rejects an unknown log format", func(t *testing.T) {
+		validateCommand, mockUI := setup()
+		exitCode := validateCommand.Run([]string{"--path=../testdata/full_app", "--log-format=yaml"})
+		u.So(t, exitCode, gc.ShouldEqual, 1)
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, `unknown log format "yaml"; accepted values are [text|json]`)
+	})
+}
@@ -36,6 +36,22 @@ func TestLogoutCommand(t *testing.T) {
 		u.So(t, storedUser, gc.ShouldResemble, &user.User{})
 	})
 
+	t.Run("keeps the defaults set with config set", func(t *testing.T) {
+		logoutCommand, _ := setup(u.NewEmptyStorage())
+		u.So(t, logoutCommand.storage.WriteUserConfig(&user.User{
+			PublicAPIKey:  "user.name",
+			PrivateAPIKey: "apikey",
+			Defaults:      map[string]string{"project": "5f3c2b1a0d9e8f7a6b5c4d3e"},
+		}), gc.ShouldBeNil)
+
+		res := logoutCommand.Run([]string{})
+		u.So(t, res, gc.ShouldEqual, 0)
+
+		storedUser, err := logoutCommand.storage.ReadUserConfig()
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, storedUser, gc.ShouldResemble, &user.User{Defaults: map[string]string{"project": "5f3c2b1a0d9e8f7a6b5c4d3e"}})
+	})
+
 	t.Run("plays nicely when the user is not logged in", func(t *testing.T) {
 		logoutCommand, _ := setup(u.NewEmptyStorage())
 
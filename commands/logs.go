@@ -0,0 +1,373 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/10gen/realm-cli/api"
+	"github.com/10gen/realm-cli/models"
+
+	"github.com/mitchellh/cli"
+)
+
+const (
+	logsFlagType       = "type"
+	logsFlagStart      = "start"
+	logsFlagEnd        = "end"
+	logsFlagErrorsOnly = "errors-only"
+	logsFlagTail       = "tail"
+
+	// logsPollInterval is how often new log entries are fetched with --tail
+	logsPollInterval = 5 * time.Second
+
+	// logTimeFormat is how the time each log entry started at is printed
+	logTimeFormat = "2006-01-02T15:04:05.000Z07:00"
+)
+
+// logTypes maps each --type to the log types of the Realm Admin API it covers
+var logTypes = map[string][]string{
+	"auth":     {"AUTH"},
+	"function": {"FUNCTION"},
+	"graphql":  {"GRAPHQL"},
+	"push":     {"PUSH"},
+	"schema":   {"SCHEMA_ADDITIVE_CHANGE", "SCHEMA_GENERATION", "SCHEMA_VALIDATION"},
+	"service":  {"SERVICE", "WEBHOOK", "STREAM_FUNCTION"},
+	"sync": {
+		"SYNC_CONNECTION_START", "SYNC_CONNECTION_END", "SYNC_SESSION_START", "SYNC_SESSION_END",
+		"SYNC_CLIENT_WRITE", "SYNC_ERROR", "SYNC_OTHER",
+	},
+	"trigger": {"TRIGGER_DATABASE", "TRIGGER_AUTHENTICATION", "TRIGGER_SCHEDULED"},
+}
+
+// logTimeLayouts are the layouts accepted by --start and --end, besides durations
+var logTimeLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02"}
+
+var (
+	errLogsAppIDRequired = fmt.Errorf("an App ID (--%s=[string]) must be supplied to view logs", flagAppIDName)
+	errLogsTailWithEnd   = fmt.Errorf("--%s cannot be used with --%s", logsFlagTail, logsFlagEnd)
+)
+
+// NewLogsCommandFactory returns a new cli.CommandFactory given a cli.Ui
+func NewLogsCommandFactory(ui cli.Ui) cli.CommandFactory {
+	return func() (cli.Command, error) {
+		workingDirectory, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+
+		return &LogsCommand{
+			BaseCommand: &BaseCommand{
+				Name: "logs",
+				UI:   ui,
+			},
+			workingDirectory: workingDirectory,
+			pollInterval:     logsPollInterval,
+			now:              time.Now,
+		}, nil
+	}
+}
+
+// LogsCommand is used to view the logs of a Realm App
+type LogsCommand struct {
+	*BaseCommand
+
+	workingDirectory string
+	pollInterval     time.Duration
+	now              func() time.Time
+
+	// stop ends following the logs with --tail, which otherwise goes on until interrupted
+	stop chan struct{}
+
+	flagAppID      string
+	flagAppPath    string
+	flagProjectID  string
+	flagTypes      stringSliceFlag
+	flagStart      string
+	flagEnd        string
+	flagErrorsOnly bool
+	flagTail       bool
+}
+
+// Synopsis returns a one-liner description for this command
+func (lc *LogsCommand) Synopsis() string {
+	return "View the logs of your Realm App."
+}
+
+// Help returns long-form help information for this command
+func (lc *LogsCommand) Help() string {
+	return `View the most recent logs of your Realm App, oldest first and one line per entry, so that
+they can be piped into tools like grep. The lines logged by a function follow its entry, each
+prefixed with the entry it belongs to.
+
+Usage: realm-cli logs [options]
+
+OPTIONS:
+  --path [string]
+	A path to the local directory containing your app.
+
+  --app-id [string]
+	The App ID for your app (i.e. the name of your app followed by a unique suffix, like "my-app-nysja").
+	Required if the local app does not specify it.
+
+  --project-id [string]
+	The Atlas Project ID.
+
+  --type [auth|function|graphql|push|schema|service|sync|trigger]
+	Only show the logs of this type. Can be given more than once.
+
+  --start [string]
+	Only show the logs from this time on, either a date like "2020-09-13" or "2020-09-13T12:00:00Z",
+	or a duration like "30m" meaning that long ago.
+
+  --end [string]
+	Only show the logs until this time, in the same formats as --start.
+
+  --errors-only
+	Only show the logs of failed requests and operations.
+
+  -f, --tail
+	Keep fetching new log entries every few seconds and print them as they come, until interrupted.
+	` +
+		lc.BaseCommand.Help()
+}
+
+// Run executes the command
+func (lc *LogsCommand) Run(args []string) int {
+	flags := lc.NewFlagSet()
+
+	flags.StringVar(&lc.flagAppID, flagAppIDName, "", "")
+	flags.StringVar(&lc.flagAppPath, importFlagPath, "", "")
+	flags.StringVar(&lc.flagProjectID, flagProjectIDName, "", "")
+	flags.Var(&lc.flagTypes, logsFlagType, "")
+	flags.StringVar(&lc.flagStart, logsFlagStart, "", "")
+	flags.StringVar(&lc.flagEnd, logsFlagEnd, "", "")
+	flags.BoolVar(&lc.flagErrorsOnly, logsFlagErrorsOnly, false, "")
+	flags.BoolVar(&lc.flagTail, logsFlagTail, false, "")
+	flags.BoolVar(&lc.flagTail, "f", false, "")
+
+	if err := lc.BaseCommand.run(args); err != nil {
+		lc.UI.Error(err.Error())
+		return 1
+	}
+
+	if err := lc.logs(); err != nil {
+		lc.UI.Error(err.Error())
+		return 1
+	}
+
+	return 0
+}
+
+func (lc *LogsCommand) logs() error {
+	options, err := lc.logsOptions()
+	if err != nil {
+		return err
+	}
+
+	app, realmClient, err := lc.resolveApp()
+	if err != nil {
+		return err
+	}
+
+	if !lc.flagTail {
+		logs, err := realmClient.ListLogs(app.GroupID, app.ID, options)
+		if err != nil {
+			return fmt.Errorf("failed to fetch logs: %s", err)
+		}
+		printLogs(lc.UI, logs)
+		return nil
+	}
+
+	return lc.tail(realmClient, app, options)
+}
+
+// logsOptions builds the filters of the logs to fetch from the flags
+func (lc *LogsCommand) logsOptions() (api.LogsOptions, error) {
+	var options api.LogsOptions
+
+	for _, logType := range lc.flagTypes {
+		types, ok := logTypes[strings.ToLower(logType)]
+		if !ok {
+			names := make([]string, 0, len(logTypes))
+			for name := range logTypes {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return options, errUnknownOption("log type", logType, names)
+		}
+		options.Types = append(options.Types, types...)
+	}
+
+	if lc.flagTail && lc.flagEnd != "" {
+		return options, errLogsTailWithEnd
+	}
+
+	var err error
+	if options.Start, err = lc.parseLogTime(logsFlagStart, lc.flagStart); err != nil {
+		return options, err
+	}
+	if options.End, err = lc.parseLogTime(logsFlagEnd, lc.flagEnd); err != nil {
+		return options, err
+	}
+	if !options.Start.IsZero() && !options.End.IsZero() && options.End.Before(options.Start) {
+		return options, fmt.Errorf("--%s must not be before --%s", logsFlagEnd, logsFlagStart)
+	}
+
+	options.ErrorsOnly = lc.flagErrorsOnly
+	return options, nil
+}
+
+// parseLogTime parses the value of a time flag, either a date or how long ago
+func (lc *LogsCommand) parseLogTime(flagName, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if ago, err := time.ParseDuration(value); err == nil {
+		return lc.now().Add(-ago), nil
+	}
+
+	for _, layout := range logTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf(`invalid --%s %q: expected a date like "2020-09-13T12:00:00Z" or a duration like "30m"`, flagName, value)
+}
+
+func (lc *LogsCommand) resolveApp() (*models.App, api.RealmClient, error) {
+	_, app, realmClient, err := lc.BaseCommand.resolveApp(appTarget{
+		appID:            lc.flagAppID,
+		appPath:          lc.flagAppPath,
+		workingDirectory: lc.workingDirectory,
+		projectID:        lc.flagProjectID,
+		errAppIDRequired: errLogsAppIDRequired,
+	})
+	return app, realmClient, err
+}
+
+// tail prints the logs, then polls for new entries and prints them until interrupted
+func (lc *LogsCommand) tail(realmClient api.RealmClient, app *models.App, options api.LogsOptions) error {
+	var stop <-chan struct{} = lc.stop
+	if stop == nil {
+		var release func()
+		stop, release = stopOnInterrupt()
+		defer release()
+	}
+
+	return followLogs(lc.UI, realmClient, app, options, lc.pollInterval, stop)
+}
+
+// stopOnInterrupt returns a channel which is closed once the process is interrupted, e.g. with Ctrl-C,
+// along with a function to stop listening for interrupts
+func stopOnInterrupt() (<-chan struct{}, func()) {
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	go func() {
+		select {
+		case <-interrupts:
+			close(stop)
+		case <-done:
+		}
+	}()
+
+	return stop, func() {
+		signal.Stop(interrupts)
+		close(done)
+	}
+}
+
+// followLogs prints the logs of the app matching the options, then polls for new entries every interval
+// and prints them, until stop is closed
+func followLogs(ui cli.Ui, realmClient api.RealmClient, app *models.App, options api.LogsOptions, interval time.Duration, stop <-chan struct{}) error {
+	// entries started at the same time as the newest one printed are fetched again by the next poll
+	printed := map[string]bool{}
+	for {
+		logs, err := realmClient.ListLogs(app.GroupID, app.ID, options)
+		if err != nil {
+			return fmt.Errorf("failed to fetch logs: %s", err)
+		}
+
+		var fresh []models.Log
+		for _, log := range logs {
+			if !printed[log.ID] {
+				fresh = append(fresh, log)
+			}
+		}
+		printLogs(ui, fresh)
+
+		for _, log := range fresh {
+			if log.Started.After(options.Start) {
+				options.Start = log.Started
+				printed = map[string]bool{}
+			}
+		}
+		for _, log := range logs {
+			if log.Started.Equal(options.Start) {
+				printed[log.ID] = true
+			}
+		}
+
+		select {
+		case <-stop:
+			return nil
+		default:
+		}
+		select {
+		case <-stop:
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+// printLogs prints the logs oldest first
+func printLogs(ui cli.Ui, logs []models.Log) {
+	sorted := append([]models.Log(nil), logs...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Started.Before(sorted[j].Started)
+	})
+
+	for _, log := range sorted {
+		for _, line := range logLines(log) {
+			ui.Output(line)
+		}
+	}
+}
+
+// logLines formats a log entry as a line describing its outcome, followed by a line for each message it logged
+func logLines(log models.Log) []string {
+	prefix := log.Started.UTC().Format(logTimeFormat) + " " + log.Type
+	if log.FunctionName != "" {
+		prefix += " " + log.FunctionName
+	} else if log.EventSubscriptionName != "" {
+		prefix += " " + log.EventSubscriptionName
+	}
+
+	outcome := "OK"
+	if log.Error != "" {
+		outcome = "ERROR"
+		if log.ErrorCode != "" {
+			outcome += " " + log.ErrorCode
+		}
+		outcome += ": " + strings.Join(strings.Fields(log.Error), " ")
+	}
+
+	lines := []string{prefix + " " + outcome}
+	if !log.Completed.IsZero() {
+		lines[0] += fmt.Sprintf(" (%s)", log.Completed.Sub(log.Started).Round(time.Millisecond))
+	}
+	for _, message := range log.Messages {
+		for _, line := range strings.Split(strings.TrimRight(message, "\n"), "\n") {
+			lines = append(lines, prefix+" > "+line)
+		}
+	}
+	return lines
+}
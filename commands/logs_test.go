@@ -0,0 +1,206 @@
+package commands
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/10gen/realm-cli/api"
+	"github.com/10gen/realm-cli/models"
+	"github.com/10gen/realm-cli/user"
+	u "github.com/10gen/realm-cli/utils/test"
+	gc "github.com/smartystreets/goconvey/convey"
+
+	"github.com/mitchellh/cli"
+)
+
+func TestLogsCommand(t *testing.T) {
+	now := time.Date(2020, 9, 13, 12, 30, 0, 0, time.UTC)
+
+	setup := func() (*LogsCommand, *cli.MockUi) {
+		mockUI := cli.NewMockUi()
+		cmd, err := NewLogsCommandFactory(mockUI)()
+		if err != nil {
+			panic(err)
+		}
+
+		logsCommand := cmd.(*LogsCommand)
+		logsCommand.storage = u.NewEmptyStorage()
+		logsCommand.now = func() time.Time { return now }
+		logsCommand.pollInterval = 0
+		return logsCommand, mockUI
+	}
+
+	t.Run("should require the user to be logged in", func(t *testing.T) {
+		logsCommand, mockUI := setup()
+		exitCode := logsCommand.Run([]string{"--app-id=my-app-abcdef"})
+		u.So(t, exitCode, gc.ShouldEqual, 1)
+
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, user.ErrNotLoggedIn.Error())
+	})
+
+	t.Run("when the user is logged in", func(t *testing.T) {
+		started := func(seconds int) time.Time {
+			return now.Add(time.Duration(seconds) * time.Second)
+		}
+
+		setupLoggedIn := func(listLogs func(options api.LogsOptions) ([]models.Log, error)) (*LogsCommand, *cli.MockUi) {
+			logsCommand, mockUI := setup()
+			logsCommand.user = &user.User{
+				APIKey:      "my-api-key",
+				AccessToken: u.GenerateValidAccessToken(),
+			}
+			logsCommand.realmClient = &u.MockRealmClient{
+				FetchAppByClientAppIDFn: func(clientAppID string) (*models.App, error) {
+					return &models.App{GroupID: "group-id", ID: "app-id", ClientAppID: clientAppID}, nil
+				},
+				ListLogsFn: func(groupID, appID string, options api.LogsOptions) ([]models.Log, error) {
+					u.So(t, groupID, gc.ShouldEqual, "group-id")
+					u.So(t, appID, gc.ShouldEqual, "app-id")
+					return listLogs(options)
+				},
+			}
+			return logsCommand, mockUI
+		}
+
+		t.Run("should print one line per entry and message, oldest first", func(t *testing.T) {
+			logsCommand, mockUI := setupLoggedIn(func(options api.LogsOptions) ([]models.Log, error) {
+				return []models.Log{
+					{
+						ID:        "2",
+						Type:      "FUNCTION",
+						Started:   started(2),
+						Completed: started(2).Add(15 * time.Millisecond),
+						Error:     "TypeError: 'x' is\nundefined",
+						ErrorCode: "FunctionExecutionError",
+					},
+					{
+						ID:           "1",
+						Type:         "FUNCTION",
+						Started:      started(1),
+						Completed:    started(1).Add(120 * time.Millisecond),
+						FunctionName: "sum",
+						Messages:     []string{"adding", "two\nlines"},
+					},
+					{ID: "0", Type: "AUTH", Started: started(0)},
+				}, nil
+			})
+			exitCode := logsCommand.Run([]string{"--app-id=my-app-abcdef"})
+			u.So(t, exitCode, gc.ShouldEqual, 0)
+
+			u.So(t, mockUI.OutputWriter.String(), gc.ShouldEqual, strings.Join([]string{
+				"2020-09-13T12:30:00.000Z AUTH OK",
+				"2020-09-13T12:30:01.000Z FUNCTION sum OK (120ms)",
+				"2020-09-13T12:30:01.000Z FUNCTION sum > adding",
+				"2020-09-13T12:30:01.000Z FUNCTION sum > two",
+				"2020-09-13T12:30:01.000Z FUNCTION sum > lines",
+				"2020-09-13T12:30:02.000Z FUNCTION ERROR FunctionExecutionError: TypeError: 'x' is undefined (15ms)",
+				"",
+			}, "\n"))
+		})
+
+		t.Run("should filter the logs as told", func(t *testing.T) {
+			var options api.LogsOptions
+			logsCommand, _ := setupLoggedIn(func(opts api.LogsOptions) ([]models.Log, error) {
+				options = opts
+				return nil, nil
+			})
+			exitCode := logsCommand.Run([]string{
+				"--app-id=my-app-abcdef",
+				"--type=function",
+				"--type=trigger",
+				"--start=90m",
+				"--end=2020-09-13T12:00:00Z",
+				"--errors-only",
+			})
+			u.So(t, exitCode, gc.ShouldEqual, 0)
+
+			u.So(t, options, gc.ShouldResemble, api.LogsOptions{
+				Types:      []string{"FUNCTION", "TRIGGER_DATABASE", "TRIGGER_AUTHENTICATION", "TRIGGER_SCHEDULED"},
+				ErrorsOnly: true,
+				Start:      now.Add(-90 * time.Minute),
+				End:        time.Date(2020, 9, 13, 12, 0, 0, 0, time.UTC),
+			})
+		})
+
+		for _, tc := range []struct {
+			description   string
+			args          []string
+			expectedError string
+		}{
+			{
+				description:   "should reject an unknown log type",
+				args:          []string{"--type=everything"},
+				expectedError: `unknown log type "everything"; accepted values are [auth|function|graphql|push|schema|service|sync|trigger]`,
+			},
+			{
+				description:   "should reject an invalid time",
+				args:          []string{"--start=yesterday"},
+				expectedError: `invalid --start "yesterday"`,
+			},
+			{
+				description:   "should reject an end before the start",
+				args:          []string{"--start=2020-09-13", "--end=2020-09-12"},
+				expectedError: "--end must not be before --start",
+			},
+			{
+				description:   "should reject following the logs up to an end",
+				args:          []string{"--tail", "--end=2020-09-12"},
+				expectedError: errLogsTailWithEnd.Error(),
+			},
+		} {
+			t.Run(tc.description, func(t *testing.T) {
+				logsCommand, mockUI := setupLoggedIn(func(options api.LogsOptions) ([]models.Log, error) {
+					return nil, nil
+				})
+				exitCode := logsCommand.Run(append([]string{"--app-id=my-app-abcdef"}, tc.args...))
+				u.So(t, exitCode, gc.ShouldEqual, 1)
+
+				u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, tc.expectedError)
+			})
+		}
+
+		t.Run("should report a failure to fetch the logs", func(t *testing.T) {
+			logsCommand, mockUI := setupLoggedIn(func(options api.LogsOptions) ([]models.Log, error) {
+				return nil, errors.New("oh no")
+			})
+			exitCode := logsCommand.Run([]string{"--app-id=my-app-abcdef"})
+			u.So(t, exitCode, gc.ShouldEqual, 1)
+
+			u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, "failed to fetch logs: oh no")
+		})
+
+		t.Run("with --tail should print each new entry once", func(t *testing.T) {
+			polls := [][]models.Log{
+				{{ID: "1", Type: "AUTH", Started: started(1)}, {ID: "0", Type: "AUTH", Started: started(0)}},
+				{{ID: "2", Type: "AUTH", Started: started(1)}, {ID: "1", Type: "AUTH", Started: started(1)}},
+				{{ID: "3", Type: "FUNCTION", Started: started(2)}, {ID: "2", Type: "AUTH", Started: started(1)}},
+			}
+
+			var starts []time.Time
+			var logsCommand *LogsCommand
+			logsCommand, mockUI := setupLoggedIn(func(options api.LogsOptions) ([]models.Log, error) {
+				starts = append(starts, options.Start)
+				logs := polls[len(starts)-1]
+				if len(starts) == len(polls) {
+					close(logsCommand.stop)
+				}
+				return logs, nil
+			})
+			logsCommand.stop = make(chan struct{})
+
+			exitCode := logsCommand.Run([]string{"--app-id=my-app-abcdef", "-f"})
+			u.So(t, exitCode, gc.ShouldEqual, 0)
+
+			u.So(t, starts, gc.ShouldResemble, []time.Time{{}, started(1), started(1)})
+			u.So(t, mockUI.OutputWriter.String(), gc.ShouldEqual, strings.Join([]string{
+				"2020-09-13T12:30:00.000Z AUTH OK",
+				"2020-09-13T12:30:01.000Z AUTH OK",
+				"2020-09-13T12:30:01.000Z AUTH OK",
+				"2020-09-13T12:30:02.000Z FUNCTION OK",
+				"",
+			}, "\n"))
+		})
+	})
+}
@@ -0,0 +1,380 @@
+package commands
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/10gen/realm-cli/api"
+	"github.com/10gen/realm-cli/utils"
+
+	"github.com/mitchellh/cli"
+)
+
+const (
+	pullFlagDryRun = "dry-run"
+	pullFlagMerge  = "merge"
+)
+
+var (
+	errPullAppIDRequired = fmt.Errorf("an App ID (--%s=[string]) must be supplied to pull an app", flagAppIDName)
+)
+
+// NewPullCommandFactory returns a new cli.CommandFactory given a cli.Ui
+func NewPullCommandFactory(ui cli.Ui) cli.CommandFactory {
+	return func() (cli.Command, error) {
+		workingDirectory, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+
+		base := &BaseCommand{
+			Name: "pull",
+			UI:   ui,
+		}
+
+		return &PullCommand{
+			BaseCommand:          base,
+			workingDirectory:     workingDirectory,
+			writeFileToDirectory: utils.WriteFileToDir,
+			getAssetAtURL:        base.getAssetAtURL,
+		}, nil
+	}
+}
+
+// PullCommand is used to refresh a local app from the deployed Realm App
+type PullCommand struct {
+	*BaseCommand
+
+	workingDirectory     string
+	writeFileToDirectory func(dest string, data io.Reader) error
+	getAssetAtURL        func(url string) (io.ReadCloser, error)
+
+	flagAppID               string
+	flagAppPath             string
+	flagProjectID           string
+	flagIncludeHosting      bool
+	flagIncludeDependencies bool
+	flagDryRun              bool
+	flagMerge               bool
+}
+
+// Synopsis returns a one-liner description for this command
+func (pc *PullCommand) Synopsis() string {
+	return "Update your local Realm App with the deployed one."
+}
+
+// Help returns long-form help information for this command
+func (pc *PullCommand) Help() string {
+	return `Update the configuration of a local app with that of the deployed Realm Application, the reverse of import.
+Resources which exist locally but not in the deployed app are removed, unless --merge is set. The local changes
+which would be overwritten or removed are listed, and you are asked to confirm them unless -y is set. The files
+in the "environments" directory which reference environment variables are kept, rather than being overwritten
+with the values the variables had on import.
+
+Usage: realm-cli pull [options]
+
+REQUIRED:
+  --app-id [string]
+	The App ID for your app (i.e. the name of your app followed by a unique suffix, like "my-app-nysja").
+	Required if the local app config does not have one.
+
+OPTIONS:
+  --path [string]
+	A path to the local directory containing your app.
+
+  --project-id [string]
+	The Atlas Project ID.
+
+  --include-hosting
+	Download static assets into the "/hosting" directory.
+
+  --include-dependencies
+	Download the dependencies archive into the "/functions" directory.
+
+  --merge
+	Keep the resources which only exist locally, rather than removing them.
+
+  --dry-run
+	Print the files which would be added, overwritten or removed without changing them.
+	` +
+		pc.BaseCommand.Help()
+}
+
+// Run executes the command
+func (pc *PullCommand) Run(args []string) int {
+	flags := pc.NewFlagSet()
+
+	flags.StringVar(&pc.flagAppID, flagAppIDName, "", "")
+	flags.StringVar(&pc.flagAppPath, importFlagPath, "", "")
+	flags.StringVar(&pc.flagProjectID, flagProjectIDName, "", "")
+	flags.BoolVar(&pc.flagIncludeHosting, importFlagIncludeHosting, false, "")
+	flags.BoolVar(&pc.flagIncludeDependencies, importFlagIncludeDependencies, false, "")
+	flags.BoolVar(&pc.flagDryRun, pullFlagDryRun, false, "")
+	flags.BoolVar(&pc.flagMerge, pullFlagMerge, false, "")
+
+	if err := pc.BaseCommand.run(args); err != nil {
+		pc.UI.Error(err.Error())
+		return 1
+	}
+
+	if err := pc.pull(); err != nil {
+		pc.UI.Error(err.Error())
+		return 1
+	}
+
+	return 0
+}
+
+func (pc *PullCommand) pull() error {
+	appPath, app, realmClient, err := pc.resolveApp(appTarget{
+		appID:            pc.flagAppID,
+		appPath:          pc.flagAppPath,
+		workingDirectory: pc.workingDirectory,
+		projectID:        pc.flagProjectID,
+		localApp:         true,
+		errAppIDRequired: errPullAppIDRequired,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, body, err := realmClient.Export(app.GroupID, app.ID, api.ExportStrategyNone)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	exportData, err := ioutil.ReadAll(body)
+	if err != nil {
+		return fmt.Errorf("failed to read the exported app: %s", err)
+	}
+
+	files, err := readZipFiles(appPath, exportData)
+	if err != nil {
+		return fmt.Errorf("failed to read the exported app: %s", err)
+	}
+
+	// the local environments which reference environment variables are kept rather than overwritten with
+	// the values the variables were expanded to on import
+	templates, err := utils.TemplatedEnvironments(appPath)
+	if err != nil {
+		return fmt.Errorf("failed to read the local environments: %s", err)
+	}
+	kept := make([]string, 0, len(templates))
+	for relPath, data := range templates {
+		if _, ok := files[relPath]; ok {
+			files[relPath] = data
+			kept = append(kept, relPath)
+		}
+	}
+	sort.Strings(kept)
+
+	added, modified, err := diffLocalFiles(appPath, files)
+	if err != nil {
+		return err
+	}
+
+	overwritten, localOnly, err := localAppChanges(appPath, exportData, files, kept)
+	if err != nil {
+		return err
+	}
+	if pc.flagMerge {
+		localOnly = nil
+	}
+
+	for _, relPath := range kept {
+		pc.UI.Info(fmt.Sprintf("Keeping %s as it references environment variables", relPath))
+	}
+
+	if len(added) == 0 && len(modified) == 0 && len(localOnly) == 0 {
+		pc.UI.Info("The local app is identical to the deployed one.")
+	}
+
+	if pc.flagDryRun {
+		for _, name := range added {
+			pc.UI.Info(fmt.Sprintf("Would add %s", name))
+		}
+		for _, name := range modified {
+			pc.UI.Info(fmt.Sprintf("Would overwrite %s", name))
+		}
+		for _, path := range localOnly {
+			pc.UI.Info(fmt.Sprintf("Would remove %s", path))
+		}
+		if pc.flagIncludeHosting {
+			pc.UI.Info("Would download the static hosting assets")
+		}
+		if pc.flagIncludeDependencies {
+			pc.UI.Info("Would download the dependencies")
+		}
+		return nil
+	}
+
+	// files which only differ in formatting are overwritten without asking, there is no work to lose
+	if len(overwritten) > 0 || len(localOnly) > 0 {
+		pc.UI.Warn("The local app has changes which pulling the deployed app would lose:")
+		for _, change := range overwritten {
+			pc.UI.Warn(fmt.Sprintf("\t* %s %s: %s (%s)", change.Change, change.Kind, change.Name, change.Path))
+		}
+		for _, path := range localOnly {
+			pc.UI.Warn(fmt.Sprintf("\t- %s only exists locally, pass --%s to keep it", path, pullFlagMerge))
+		}
+
+		confirm, err := pc.AskYesNo("Are you sure you want to overwrite the local changes with the deployed app?")
+		if err != nil {
+			return err
+		}
+		if !confirm {
+			return nil
+		}
+	}
+
+	changed := append(append([]string{}, added...), modified...)
+	for _, name := range changed {
+		if err := pc.writeFileToDirectory(filepath.Join(appPath, filepath.FromSlash(name)), bytes.NewReader(files[name])); err != nil {
+			return err
+		}
+	}
+	for _, path := range localOnly {
+		if err := os.RemoveAll(filepath.Join(appPath, filepath.FromSlash(path))); err != nil {
+			return fmt.Errorf("failed to remove %s: %s", path, err)
+		}
+	}
+	if len(changed) > 0 || len(localOnly) > 0 {
+		pc.UI.Info(fmt.Sprintf("Pulled %d added, %d modified and %d removed files into %s", len(added), len(modified), len(localOnly), appPath))
+	}
+
+	// the assets are downloaded the same way as they are for export
+	ec := &ExportCommand{
+		BaseCommand:          pc.BaseCommand,
+		writeFileToDirectory: pc.writeFileToDirectory,
+		getAssetAtURL:        pc.getAssetAtURL,
+	}
+
+	if pc.flagIncludeDependencies {
+		if err := ec.exportDependencies(realmClient, app, appPath); err != nil {
+			return err
+		}
+	}
+
+	if pc.flagIncludeHosting {
+		if err := exportStaticHostingAssets(realmClient, ec, appPath, app); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readZipFiles returns the contents of the files in the zip data, by their names within it. Every entry
+// is checked to be within appPath, as it is when extracting an archive, before any file is pulled into it
+func readZipFiles(appPath string, data []byte) (map[string][]byte, error) {
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string][]byte, len(r.File))
+	for _, file := range r.File {
+		if _, err := utils.ZipEntryPath(appPath, file.Name); err != nil {
+			return nil, err
+		}
+		if file.FileInfo().IsDir() {
+			continue
+		}
+
+		rc, err := file.Open()
+		if err != nil {
+			return nil, err
+		}
+		contents, err := ioutil.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+		files[file.Name] = contents
+	}
+	return files, nil
+}
+
+// diffLocalFiles returns the names of the files which are missing from the app at appPath
+// and of those whose contents differ from the given ones, both sorted
+func diffLocalFiles(appPath string, files map[string][]byte) ([]string, []string, error) {
+	var added, modified []string
+	for name, contents := range files {
+		local, err := ioutil.ReadFile(filepath.Join(appPath, filepath.FromSlash(name)))
+		if os.IsNotExist(err) {
+			added = append(added, name)
+			continue
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read %s: %s", name, err)
+		}
+
+		if !bytes.Equal(local, contents) {
+			modified = append(modified, name)
+		}
+	}
+
+	sort.Strings(added)
+	sort.Strings(modified)
+	return added, modified, nil
+}
+
+// pullRemovableKinds are the kinds of resources kept in files of their own, which pulling removes
+// when they only exist locally
+var pullRemovableKinds = map[string]bool{
+	"function": true,
+	"service":  true,
+	"trigger":  true,
+	"value":    true,
+}
+
+// localAppChanges compares the local app at appPath with the exported one, returning the changes made to the
+// local app which pulling overwrites, and the paths of the resources which only exist locally. The environment
+// files at the kept paths are not overwritten, so they are compared as they are locally
+func localAppChanges(appPath string, exportData []byte, files map[string][]byte, kept []string) ([]utils.AppChange, []string, error) {
+	localApp, err := utils.UnmarshalFromDir(appPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load the local app: %s", err)
+	}
+
+	deployedApp, err := utils.UnmarshalFromZip(bytes.NewReader(exportData))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load the exported app: %s", err)
+	}
+	utils.KeepAppEnvironments(deployedApp, localApp, kept)
+
+	paths, err := utils.AppResourcePaths(appPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var overwritten []utils.AppChange
+	var localOnly []string
+	for _, change := range utils.DiffApps(localApp, deployedApp, false).Changes(paths) {
+		switch change.Change {
+		case utils.AppChangeModified:
+			if change.Kind != utils.SyncChangeKind {
+				overwritten = append(overwritten, change)
+			}
+		case utils.AppChangeAdded:
+			// e.g. a field only set in the local config.json, which the exported one replaces
+			if _, ok := files[change.Path]; ok || change.Path == "" {
+				overwritten = append(overwritten, change)
+				continue
+			}
+			// the rest of the local app, e.g. its environments, is left alone as it is not a resource of its own
+			if !pullRemovableKinds[change.Kind] {
+				continue
+			}
+			localOnly = append(localOnly, utils.ResourceRootPath(utils.AppResource{Kind: change.Kind, Name: change.Name}, change.Path))
+		}
+	}
+	return overwritten, localOnly, nil
+}
@@ -0,0 +1,272 @@
+package commands
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/10gen/realm-cli/api"
+	"github.com/10gen/realm-cli/models"
+	"github.com/10gen/realm-cli/user"
+	u "github.com/10gen/realm-cli/utils/test"
+	gc "github.com/smartystreets/goconvey/convey"
+
+	"github.com/mitchellh/cli"
+)
+
+func TestPullCommand(t *testing.T) {
+	writeApp := func(t *testing.T, files map[string]string) string {
+		dir, err := ioutil.TempDir("", "realm-pull-")
+		u.So(t, err, gc.ShouldBeNil)
+
+		for name, contents := range files {
+			path := filepath.Join(dir, filepath.FromSlash(name))
+			u.So(t, os.MkdirAll(filepath.Dir(path), os.ModePerm), gc.ShouldBeNil)
+			u.So(t, ioutil.WriteFile(path, []byte(contents), 0644), gc.ShouldBeNil)
+		}
+		return dir
+	}
+
+	readFile := func(t *testing.T, path string) string {
+		data, err := ioutil.ReadFile(path)
+		u.So(t, err, gc.ShouldBeNil)
+		return string(data)
+	}
+
+	localConfig := `{"app_id":"my-app-abcde","name":"my-app"}`
+	deployedFiles := map[string]string{
+		"config.json":                        `{"app_id":"my-app-abcde","name":"my-app"}`,
+		"values/greeting.json":               `{"name":"greeting","value":"hello"}`,
+		"functions/sayHello/config.json":     `{"name":"sayHello"}`,
+		"functions/sayHello/source.js":       `exports = () => "hello";`,
+		"auth_providers/api-key.json":        `{"name":"api-key","type":"api-key"}`,
+		"services/mongodb-atlas/config.json": `{"name":"mongodb-atlas"}`,
+	}
+
+	setup := func(t *testing.T, localFiles map[string]string) (*PullCommand, *cli.MockUi, string) {
+		localDir := writeApp(t, localFiles)
+		deployedDir := writeApp(t, deployedFiles)
+		defer os.RemoveAll(deployedDir)
+
+		mockUI := cli.NewMockUi()
+		cmd, err := NewPullCommandFactory(mockUI)()
+		u.So(t, err, gc.ShouldBeNil)
+
+		pullCommand := cmd.(*PullCommand)
+		pullCommand.workingDirectory = localDir
+		pullCommand.storage = u.NewEmptyStorage()
+		pullCommand.user = &user.User{
+			APIKey:      "my-api-key",
+			AccessToken: u.GenerateValidAccessToken(),
+		}
+
+		exportBody := u.NewZipResponseBody(deployedDir)
+		pullCommand.realmClient = &u.MockRealmClient{
+			FetchAppByClientAppIDFn: func(clientAppID string) (*models.App, error) {
+				return &models.App{GroupID: "group-id", ID: "app-id", ClientAppID: clientAppID}, nil
+			},
+			ExportFn: func(groupID, appID string, strategy api.ExportStrategy) (string, io.ReadCloser, error) {
+				return "my-app_20200101", exportBody, nil
+			},
+		}
+		return pullCommand, mockUI, localDir
+	}
+
+	t.Run("should require the user to be logged in", func(t *testing.T) {
+		pullCommand, mockUI, localDir := setup(t, map[string]string{"config.json": localConfig})
+		defer os.RemoveAll(localDir)
+		pullCommand.user = nil
+
+		exitCode := pullCommand.Run([]string{})
+		u.So(t, exitCode, gc.ShouldEqual, 1)
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, user.ErrNotLoggedIn.Error())
+	})
+
+	t.Run("should require an app id", func(t *testing.T) {
+		pullCommand, mockUI, localDir := setup(t, map[string]string{"config.json": `{"name":"my-app"}`})
+		defer os.RemoveAll(localDir)
+
+		exitCode := pullCommand.Run([]string{})
+		u.So(t, exitCode, gc.ShouldEqual, 1)
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, errPullAppIDRequired.Error())
+	})
+
+	t.Run("should add the files missing locally without asking", func(t *testing.T) {
+		pullCommand, mockUI, localDir := setup(t, map[string]string{"config.json": localConfig})
+		defer os.RemoveAll(localDir)
+
+		exitCode := pullCommand.Run([]string{})
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+		u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, "Pulled 5 added, 0 modified and 0 removed files into "+localDir)
+
+		for name, contents := range deployedFiles {
+			u.So(t, readFile(t, filepath.Join(localDir, filepath.FromSlash(name))), gc.ShouldEqual, contents)
+		}
+	})
+
+	t.Run("should overwrite the local changes and remove the local only resources once confirmed", func(t *testing.T) {
+		pullCommand, mockUI, localDir := setup(t, map[string]string{
+			"config.json":                  localConfig,
+			"values/greeting.json":         `{"name":"greeting","value":"howdy"}`,
+			"values/local.json":            `{"name":"local","value":"only here"}`,
+			"functions/local/config.json":  `{"name":"local"}`,
+			"functions/local/source.js":    `exports = () => "local";`,
+			"environments/production.json": `{"values":{"key":"${SECRET}"}}`,
+		})
+		defer os.RemoveAll(localDir)
+		mockUI.InputReader = strings.NewReader("y\n")
+
+		exitCode := pullCommand.Run([]string{})
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldEqual, strings.Join([]string{
+			"The local app has changes which pulling the deployed app would lose:",
+			"\t* modified value: greeting (values/greeting.json)",
+			"\t- functions/local only exists locally, pass --merge to keep it",
+			"\t- values/local.json only exists locally, pass --merge to keep it",
+			"",
+		}, "\n"))
+		u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, "Pulled 4 added, 1 modified and 2 removed files")
+
+		u.So(t, readFile(t, filepath.Join(localDir, "values", "greeting.json")), gc.ShouldEqual, deployedFiles["values/greeting.json"])
+		for _, path := range []string{filepath.Join("values", "local.json"), filepath.Join("functions", "local")} {
+			_, err := os.Stat(filepath.Join(localDir, path))
+			u.So(t, os.IsNotExist(err), gc.ShouldBeTrue)
+		}
+		u.So(t, readFile(t, filepath.Join(localDir, "environments", "production.json")), gc.ShouldEqual, `{"values":{"key":"${SECRET}"}}`)
+	})
+
+	t.Run("should keep the local only resources with --merge", func(t *testing.T) {
+		pullCommand, mockUI, localDir := setup(t, map[string]string{
+			"config.json":       localConfig,
+			"values/local.json": `{"name":"local","value":"only here"}`,
+		})
+		defer os.RemoveAll(localDir)
+
+		exitCode := pullCommand.Run([]string{"--merge"})
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+		u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, "Pulled 5 added, 0 modified and 0 removed files")
+
+		u.So(t, readFile(t, filepath.Join(localDir, "values", "local.json")), gc.ShouldEqual, `{"name":"local","value":"only here"}`)
+	})
+
+	t.Run("should overwrite files which only differ in formatting without asking", func(t *testing.T) {
+		pullCommand, mockUI, localDir := setup(t, map[string]string{
+			"config.json":          localConfig,
+			"values/greeting.json": `{ "name": "greeting", "value": "hello" }`,
+		})
+		defer os.RemoveAll(localDir)
+
+		exitCode := pullCommand.Run([]string{})
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+		u.So(t, readFile(t, filepath.Join(localDir, "values", "greeting.json")), gc.ShouldEqual, deployedFiles["values/greeting.json"])
+	})
+
+	t.Run("should leave the local app alone when overwriting is not confirmed", func(t *testing.T) {
+		pullCommand, mockUI, localDir := setup(t, map[string]string{
+			"config.json":          localConfig,
+			"values/greeting.json": `{"name":"greeting","value":"howdy"}`,
+		})
+		defer os.RemoveAll(localDir)
+		mockUI.InputReader = strings.NewReader("n\n")
+
+		exitCode := pullCommand.Run([]string{})
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+
+		u.So(t, readFile(t, filepath.Join(localDir, "values", "greeting.json")), gc.ShouldEqual, `{"name":"greeting","value":"howdy"}`)
+		_, err := os.Stat(filepath.Join(localDir, "functions"))
+		u.So(t, os.IsNotExist(err), gc.ShouldBeTrue)
+	})
+
+	t.Run("should list the files which would change with --dry-run", func(t *testing.T) {
+		pullCommand, mockUI, localDir := setup(t, map[string]string{
+			"config.json":          localConfig,
+			"values/greeting.json": `{"name":"greeting","value":"howdy"}`,
+		})
+		defer os.RemoveAll(localDir)
+
+		exitCode := pullCommand.Run([]string{"--dry-run", "--include-hosting"})
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+		u.So(t, mockUI.OutputWriter.String(), gc.ShouldEqual, strings.Join([]string{
+			"Would add auth_providers/api-key.json",
+			"Would add functions/sayHello/config.json",
+			"Would add functions/sayHello/source.js",
+			"Would add services/mongodb-atlas/config.json",
+			"Would overwrite values/greeting.json",
+			"Would download the static hosting assets",
+			"",
+		}, "\n"))
+
+		u.So(t, readFile(t, filepath.Join(localDir, "values", "greeting.json")), gc.ShouldEqual, `{"name":"greeting","value":"howdy"}`)
+	})
+
+	t.Run("should keep the local environments which reference environment variables", func(t *testing.T) {
+		const template = `{"values":{"greeting":"${GREETING}"}}`
+		deployedFiles["environments/production.json"] = `{"values":{"greeting":"hi"}}`
+		deployedFiles["environments/testing.json"] = `{"values":{"greeting":"hey"}}`
+		defer delete(deployedFiles, "environments/production.json")
+		defer delete(deployedFiles, "environments/testing.json")
+
+		pullCommand, mockUI, localDir := setup(t, map[string]string{
+			"config.json":                  localConfig,
+			"environments/production.json": template,
+			"environments/testing.json":    `{"values":{"greeting":"hello"}}`,
+		})
+		defer os.RemoveAll(localDir)
+
+		exitCode := pullCommand.Run([]string{"-y"})
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+		u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, "Keeping environments/production.json as it references environment variables")
+		u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, "Pulled 5 added, 1 modified and 0 removed files")
+
+		u.So(t, readFile(t, filepath.Join(localDir, "environments", "production.json")), gc.ShouldEqual, template)
+		u.So(t, readFile(t, filepath.Join(localDir, "environments", "testing.json")), gc.ShouldEqual, `{"values":{"greeting":"hey"}}`)
+	})
+
+	t.Run("should not pull anything from an export with a file outside of the app directory", func(t *testing.T) {
+		for _, name := range []string{"../evil.json", "functions/../../evil.json", "/evil.json"} {
+			pullCommand, mockUI, localDir := setup(t, map[string]string{"config.json": localConfig})
+			defer os.RemoveAll(localDir)
+
+			var buf bytes.Buffer
+			w := zip.NewWriter(&buf)
+			for _, entry := range []string{"config.json", "values/greeting.json", name} {
+				f, err := w.Create(entry)
+				u.So(t, err, gc.ShouldBeNil)
+				_, err = f.Write([]byte(deployedFiles["values/greeting.json"]))
+				u.So(t, err, gc.ShouldBeNil)
+			}
+			u.So(t, w.Close(), gc.ShouldBeNil)
+			pullCommand.realmClient.(*u.MockRealmClient).ExportFn = func(groupID, appID string, strategy api.ExportStrategy) (string, io.ReadCloser, error) {
+				return "my-app_20200101", u.NewResponseBody(bytes.NewReader(buf.Bytes())), nil
+			}
+
+			exitCode := pullCommand.Run([]string{"-y"})
+			u.So(t, exitCode, gc.ShouldEqual, 1)
+			u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, fmt.Sprintf("failed to read the exported app: failed to extract file %q: it is outside of the directory", name))
+
+			_, err := os.Stat(filepath.Join(filepath.Dir(localDir), "evil.json"))
+			u.So(t, os.IsNotExist(err), gc.ShouldBeTrue)
+			_, err = os.Stat(filepath.Join(localDir, "values", "greeting.json"))
+			u.So(t, os.IsNotExist(err), gc.ShouldBeTrue)
+			u.So(t, readFile(t, filepath.Join(localDir, "config.json")), gc.ShouldEqual, localConfig)
+		}
+	})
+
+	t.Run("should report a local app identical to the deployed one", func(t *testing.T) {
+		pullCommand, mockUI, localDir := setup(t, deployedFiles)
+		defer os.RemoveAll(localDir)
+
+		exitCode := pullCommand.Run([]string{})
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+		u.So(t, mockUI.OutputWriter.String(), gc.ShouldEqual, "The local app is identical to the deployed one.\n")
+	})
+}
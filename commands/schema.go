@@ -0,0 +1,469 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/10gen/realm-cli/api"
+	"github.com/10gen/realm-cli/models"
+	"github.com/10gen/realm-cli/utils"
+
+	"github.com/mitchellh/cli"
+)
+
+const (
+	schemaFlagDeployed   = "deployed"
+	schemaFlagDataSource = "data-source"
+	schemaFlagCollection = "collection"
+	schemaFlagOutput     = "output"
+	schemaOutputText     = "text"
+	schemaOutputJSON     = "json"
+)
+
+var (
+	errSchemaAppIDRequired      = fmt.Errorf("an App ID (--%s=[string]) must be supplied to show the schemas of the deployed app", flagAppIDName)
+	errSchemaGetAppIDRequired   = fmt.Errorf("an App ID (--%s=[string]) must be supplied to get the schema of a collection", flagAppIDName)
+	errSchemaDataSourceRequired = fmt.Errorf("a data source (--%s=[string]) must be supplied", schemaFlagDataSource)
+	errSchemaCollectionRequired = fmt.Errorf("a collection (--%s=[database].[collection]) must be supplied", schemaFlagCollection)
+)
+
+// NewSchemaCommandFactory returns a new cli.CommandFactory given a cli.Ui
+func NewSchemaCommandFactory(ui cli.Ui) cli.CommandFactory {
+	return func() (cli.Command, error) {
+		return &SchemaCommand{
+			BaseCommand: &BaseCommand{
+				Name: "schema",
+				UI:   ui,
+			},
+		}, nil
+	}
+}
+
+// SchemaCommand is used to inspect the data model of a Realm App
+type SchemaCommand struct {
+	*BaseCommand
+}
+
+// Synopsis returns a one-liner description for this command
+func (sc *SchemaCommand) Synopsis() string {
+	return "Inspect the schemas of your Realm App's data sources."
+}
+
+// Help returns long-form help information for this command
+func (sc *SchemaCommand) Help() string {
+	return sc.Synopsis()
+}
+
+// Run executes the command
+func (sc *SchemaCommand) Run(args []string) int {
+	return cli.RunResultHelp
+}
+
+// NewSchemaShowCommandFactory returns a new cli.CommandFactory given a cli.Ui
+func NewSchemaShowCommandFactory(ui cli.Ui) cli.CommandFactory {
+	return func() (cli.Command, error) {
+		workingDirectory, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+
+		return &SchemaShowCommand{
+			BaseCommand: &BaseCommand{
+				Name: "show",
+				UI:   ui,
+			},
+			workingDirectory: workingDirectory,
+		}, nil
+	}
+}
+
+// SchemaShowCommand is used to print the schemas of every collection across the app's data sources
+type SchemaShowCommand struct {
+	*BaseCommand
+
+	workingDirectory string
+
+	flagAppID     string
+	flagAppPath   string
+	flagProjectID string
+	flagDeployed  bool
+	flagOutput    string
+}
+
+// Synopsis returns a one-liner description for this command
+func (ssc *SchemaShowCommand) Synopsis() string {
+	return "Show the schemas of the collections across your Realm App's data sources."
+}
+
+// Help returns long-form help information for this command
+func (ssc *SchemaShowCommand) Help() string {
+	return `Show the JSON schemas of the collections across the data sources of your Realm Application
+in a single view, e.g. to document its data model. Collections without a schema are left out.
+
+Usage: realm-cli schema show [options]
+
+OPTIONS:
+  --path [string]
+	A path to the local directory containing your app.
+
+  --deployed
+	Show the schemas of the deployed app instead of the local directory.
+
+  --app-id [string]
+	The App ID for your app (i.e. the name of your app followed by a unique suffix, like "my-app-nysja").
+	Required with --deployed if not being run from within a realm project directory.
+
+  --project-id [string]
+	The Atlas Project ID.
+
+  -o [text|json], --output [text|json] (default: text)
+	How the schemas should be printed.
+	json - print a JSON object mapping each data source to its databases, collections and their schema.
+	` +
+		ssc.BaseCommand.Help()
+}
+
+// Run executes the command
+func (ssc *SchemaShowCommand) Run(args []string) int {
+	flags := ssc.NewFlagSet()
+
+	flags.StringVar(&ssc.flagAppID, flagAppIDName, "", "")
+	flags.StringVar(&ssc.flagAppPath, importFlagPath, "", "")
+	flags.StringVar(&ssc.flagProjectID, flagProjectIDName, "", "")
+	flags.BoolVar(&ssc.flagDeployed, schemaFlagDeployed, false, "")
+	flags.StringVar(&ssc.flagOutput, schemaFlagOutput, schemaOutputText, "")
+	flags.StringVar(&ssc.flagOutput, "o", schemaOutputText, "")
+
+	if err := ssc.BaseCommand.run(args); err != nil {
+		ssc.UI.Error(err.Error())
+		return 1
+	}
+
+	if err := ssc.show(); err != nil {
+		ssc.UI.Error(err.Error())
+		return 1
+	}
+
+	return 0
+}
+
+func (ssc *SchemaShowCommand) show() error {
+	switch ssc.flagOutput {
+	case schemaOutputText, schemaOutputJSON:
+	default:
+		return fmt.Errorf("unknown output format %q; accepted values are [%s|%s]", ssc.flagOutput, schemaOutputText, schemaOutputJSON)
+	}
+
+	app, err := ssc.loadApp()
+	if err != nil {
+		return err
+	}
+
+	schemas := utils.AppSchemas(app)
+
+	if ssc.flagOutput == schemaOutputJSON {
+		data, err := json.MarshalIndent(utils.SchemasByDataSource(schemas), "", "  ")
+		if err != nil {
+			return err
+		}
+		ssc.UI.Output(string(data))
+		return nil
+	}
+
+	if len(schemas) == 0 {
+		ssc.UI.Info("No schemas found for this app")
+		return nil
+	}
+
+	var dataSource string
+	for _, schema := range schemas {
+		if schema.DataSource != dataSource {
+			dataSource = schema.DataSource
+			ssc.UI.Output(dataSource + ":")
+		}
+
+		data, err := json.MarshalIndent(schema.Schema, "\t\t", "  ")
+		if err != nil {
+			return err
+		}
+		ssc.UI.Output(fmt.Sprintf("\t%s.%s:\n\t\t%s", schema.Database, schema.Collection, data))
+	}
+
+	return nil
+}
+
+// loadApp loads the local app, or else exports the deployed app with --deployed
+func (ssc *SchemaShowCommand) loadApp() (map[string]interface{}, error) {
+	if !ssc.flagDeployed {
+		appPath, err := utils.ResolveAppDirectory(ssc.flagAppPath, ssc.workingDirectory)
+		if err != nil {
+			return nil, err
+		}
+		return utils.UnmarshalFromDir(appPath)
+	}
+
+	_, app, realmClient, err := ssc.resolveApp(appTarget{
+		appID:            ssc.flagAppID,
+		appPath:          ssc.flagAppPath,
+		workingDirectory: ssc.workingDirectory,
+		projectID:        ssc.flagProjectID,
+		errAppIDRequired: errSchemaAppIDRequired,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return exportDeployedApp(realmClient, app)
+}
+
+// NewSchemaGetCommandFactory returns a new cli.CommandFactory given a cli.Ui
+func NewSchemaGetCommandFactory(ui cli.Ui) cli.CommandFactory {
+	return func() (cli.Command, error) {
+		workingDirectory, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+
+		return &SchemaGetCommand{
+			BaseCommand: &BaseCommand{
+				Name: "get",
+				UI:   ui,
+			},
+			workingDirectory: workingDirectory,
+		}, nil
+	}
+}
+
+// SchemaGetCommand is used to fetch the deployed schema of a single collection
+type SchemaGetCommand struct {
+	*BaseCommand
+
+	workingDirectory string
+
+	flagAppID      string
+	flagAppPath    string
+	flagProjectID  string
+	flagDataSource string
+	flagCollection string
+}
+
+// Synopsis returns a one-liner description for this command
+func (sgc *SchemaGetCommand) Synopsis() string {
+	return "Get the deployed schema of a collection of your Realm App."
+}
+
+// Help returns long-form help information for this command
+func (sgc *SchemaGetCommand) Help() string {
+	return `Print the JSON schema the rules of a data source of your deployed Realm App define for a collection,
+without exporting the whole app, e.g. to compare it against the local schema while iterating on it.
+
+Usage: realm-cli schema get --data-source [string] --collection [string] [options]
+
+REQUIRED:
+  --data-source [string]
+	The name of the data source, e.g. "mongodb-atlas".
+
+  --collection [string]
+	The collection, given as its database and name separated by a dot, e.g. "store.items".
+
+OPTIONS:
+  --path [string]
+	A path to the local directory containing your app.
+
+  --app-id [string]
+	The App ID for your app (i.e. the name of your app followed by a unique suffix, like "my-app-nysja").
+	Required if not being run from within a realm project directory.
+
+  --project-id [string]
+	The Atlas Project ID.
+	` +
+		sgc.BaseCommand.Help()
+}
+
+// Run executes the command
+func (sgc *SchemaGetCommand) Run(args []string) int {
+	flags := sgc.NewFlagSet()
+
+	flags.StringVar(&sgc.flagAppID, flagAppIDName, "", "")
+	flags.StringVar(&sgc.flagAppPath, importFlagPath, "", "")
+	flags.StringVar(&sgc.flagProjectID, flagProjectIDName, "", "")
+	flags.StringVar(&sgc.flagDataSource, schemaFlagDataSource, "", "")
+	flags.StringVar(&sgc.flagCollection, schemaFlagCollection, "", "")
+
+	if err := sgc.BaseCommand.run(args); err != nil {
+		sgc.UI.Error(err.Error())
+		return 1
+	}
+
+	if err := sgc.get(); err != nil {
+		sgc.UI.Error(err.Error())
+		return 1
+	}
+
+	return 0
+}
+
+func (sgc *SchemaGetCommand) get() error {
+	if sgc.flagDataSource == "" {
+		return errSchemaDataSourceRequired
+	}
+
+	database, collection, err := splitCollectionName(sgc.flagCollection)
+	if err != nil {
+		return err
+	}
+
+	_, app, realmClient, err := sgc.resolveApp(appTarget{
+		appID:            sgc.flagAppID,
+		appPath:          sgc.flagAppPath,
+		workingDirectory: sgc.workingDirectory,
+		projectID:        sgc.flagProjectID,
+		errAppIDRequired: errSchemaGetAppIDRequired,
+	})
+	if err != nil {
+		return err
+	}
+
+	services, err := realmClient.ListServices(app.GroupID, app.ID)
+	if err != nil {
+		return fmt.Errorf("failed to list the data sources of %s: %s", app.ClientAppID, err)
+	}
+
+	var dataSource *models.Service
+	for i, service := range services {
+		if service.Name == sgc.flagDataSource {
+			dataSource = &services[i]
+			break
+		}
+	}
+	if dataSource == nil {
+		return fmt.Errorf("data source %q not found in %s", sgc.flagDataSource, app.ClientAppID)
+	}
+	if !dataSource.IsDataSource() {
+		return fmt.Errorf("service %q is not a data source", sgc.flagDataSource)
+	}
+
+	schema, err := realmClient.GetCollectionSchema(app.GroupID, app.ID, dataSource.ID, database, collection)
+	if err == api.ErrNoCollectionSchema {
+		return fmt.Errorf("the collection %s.%s of data source %q has no schema", database, collection, sgc.flagDataSource)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get the schema of %s.%s: %s", database, collection, err)
+	}
+
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return err
+	}
+	sgc.UI.Output(string(data))
+	return nil
+}
+
+// splitCollectionName splits a collection given as [database].[collection] into its database and name.
+// Collection names may contain dots, database names may not
+func splitCollectionName(name string) (string, string, error) {
+	if name == "" {
+		return "", "", errSchemaCollectionRequired
+	}
+
+	parts := strings.SplitN(name, ".", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("--%s must be given as [database].[collection], got %q", schemaFlagCollection, name)
+	}
+	return parts[0], parts[1], nil
+}
+
+// NewSchemaValidateCommandFactory returns a new cli.CommandFactory given a cli.Ui
+func NewSchemaValidateCommandFactory(ui cli.Ui) cli.CommandFactory {
+	return func() (cli.Command, error) {
+		workingDirectory, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+
+		return &SchemaValidateCommand{
+			BaseCommand: &BaseCommand{
+				Name: "validate",
+				UI:   ui,
+			},
+			workingDirectory: workingDirectory,
+		}, nil
+	}
+}
+
+// SchemaValidateCommand is used to check the schemas of a local Realm App before importing it
+type SchemaValidateCommand struct {
+	*BaseCommand
+
+	workingDirectory string
+
+	flagAppPath string
+}
+
+// Synopsis returns a one-liner description for this command
+func (svc *SchemaValidateCommand) Synopsis() string {
+	return "Check the schemas of your local Realm App without importing it."
+}
+
+// Help returns long-form help information for this command
+func (svc *SchemaValidateCommand) Help() string {
+	return `Check that the rules files of the data sources of your local Realm App are well-formed JSON, and
+that the schema each defines is a valid JSON Schema, e.g. that "properties" holds a schema for each
+property and "bsonType" names a known BSON type. Every problem is listed along with the file and field
+it was found in. Does not require logging in.
+
+Usage: realm-cli schema validate [options]
+
+OPTIONS:
+  --path [string]
+	A path to the local directory containing your app.
+	` +
+		svc.BaseCommand.Help()
+}
+
+// Run executes the command
+func (svc *SchemaValidateCommand) Run(args []string) int {
+	flags := svc.NewFlagSet()
+
+	flags.StringVar(&svc.flagAppPath, importFlagPath, "", "")
+
+	if err := svc.BaseCommand.run(args); err != nil {
+		svc.UI.Error(err.Error())
+		return 1
+	}
+
+	if err := svc.validate(); err != nil {
+		svc.UI.Error(err.Error())
+		return 1
+	}
+
+	return 0
+}
+
+func (svc *SchemaValidateCommand) validate() error {
+	appPath, err := utils.ResolveAppDirectory(svc.flagAppPath, svc.workingDirectory)
+	if err != nil {
+		return err
+	}
+
+	problems, err := utils.ValidateAppSchemas(appPath)
+	if err != nil {
+		return err
+	}
+
+	if len(problems) == 0 {
+		svc.UI.Info(fmt.Sprintf("No problems found in the schemas of the app at %s", appPath))
+		return nil
+	}
+
+	for _, problem := range problems {
+		svc.UI.Error(problem.String())
+	}
+
+	if len(problems) == 1 {
+		return fmt.Errorf("found 1 problem in the schemas of the app at %s", appPath)
+	}
+	return fmt.Errorf("found %d problems in the schemas of the app at %s", len(problems), appPath)
+}
@@ -0,0 +1,241 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/10gen/realm-cli/api"
+	"github.com/10gen/realm-cli/models"
+	"github.com/10gen/realm-cli/user"
+	u "github.com/10gen/realm-cli/utils/test"
+	gc "github.com/smartystreets/goconvey/convey"
+
+	"github.com/mitchellh/cli"
+)
+
+func TestSchemaShowCommand(t *testing.T) {
+	setup := func() (*SchemaShowCommand, *cli.MockUi) {
+		mockUI := cli.NewMockUi()
+		cmd, err := NewSchemaShowCommandFactory(mockUI)()
+		if err != nil {
+			panic(err)
+		}
+
+		showCommand := cmd.(*SchemaShowCommand)
+		showCommand.storage = u.NewEmptyStorage()
+		return showCommand, mockUI
+	}
+
+	t.Run("prints the schemas of the local app", func(t *testing.T) {
+		showCommand, mockUI := setup()
+
+		exitCode := showCommand.Run([]string{"--path=../testdata/app_with_schemas"})
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+
+		output := mockUI.OutputWriter.String()
+		u.So(t, output, gc.ShouldContainSubstring, "analytics:\n\treports.daily:\n\t\t{")
+		u.So(t, output, gc.ShouldContainSubstring, "mongodb-atlas:\n\tstore.items:\n\t\t{")
+		u.So(t, output, gc.ShouldContainSubstring, `"title": "Item"`)
+		u.So(t, output, gc.ShouldNotContainSubstring, "store.orders")
+	})
+
+	t.Run("prints a map of data source to database, collection and schema with -o json", func(t *testing.T) {
+		showCommand, mockUI := setup()
+
+		exitCode := showCommand.Run([]string{"--path=../testdata/app_with_schemas", "-o", "json"})
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+
+		var schemas map[string]map[string]map[string]map[string]interface{}
+		u.So(t, json.Unmarshal(mockUI.OutputWriter.Bytes(), &schemas), gc.ShouldBeNil)
+		u.So(t, schemas["mongodb-atlas"]["store"]["items"]["title"], gc.ShouldEqual, "Item")
+		u.So(t, schemas["analytics"]["reports"]["daily"]["title"], gc.ShouldEqual, "DailyReport")
+		u.So(t, schemas["mongodb-atlas"]["store"], gc.ShouldNotContainKey, "orders")
+	})
+
+	t.Run("reports an app without schemas", func(t *testing.T) {
+		showCommand, mockUI := setup()
+
+		exitCode := showCommand.Run([]string{"--path=../testdata/simple_app"})
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+		u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, "No schemas found for this app")
+	})
+
+	t.Run("fails with an unknown output format", func(t *testing.T) {
+		showCommand, mockUI := setup()
+
+		exitCode := showCommand.Run([]string{"--path=../testdata/app_with_schemas", "--output=yaml"})
+		u.So(t, exitCode, gc.ShouldEqual, 1)
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, `unknown output format "yaml"`)
+	})
+
+	t.Run("with --deployed", func(t *testing.T) {
+		t.Run("should require the user to be logged in", func(t *testing.T) {
+			showCommand, mockUI := setup()
+
+			exitCode := showCommand.Run([]string{"--deployed", "--app-id=app-with-schemas-abcde"})
+			u.So(t, exitCode, gc.ShouldEqual, 1)
+			u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, user.ErrNotLoggedIn.Error())
+		})
+
+		t.Run("prints the schemas of the deployed app", func(t *testing.T) {
+			showCommand, mockUI := setup()
+			showCommand.user = &user.User{APIKey: "my-api-key", AccessToken: u.GenerateValidAccessToken()}
+			showCommand.realmClient = &u.MockRealmClient{
+				FetchAppByClientAppIDFn: func(clientAppID string) (*models.App, error) {
+					return &models.App{GroupID: "group-id", ID: "app-id", ClientAppID: clientAppID}, nil
+				},
+				ExportFn: func(groupID, appID string, strategy api.ExportStrategy) (string, io.ReadCloser, error) {
+					return "", u.NewZipResponseBody("../testdata/app_with_schemas"), nil
+				},
+			}
+
+			exitCode := showCommand.Run([]string{"--deployed", "--app-id=app-with-schemas-abcde"})
+			u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+			u.So(t, exitCode, gc.ShouldEqual, 0)
+			u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, "mongodb-atlas:\n\tstore.items:")
+		})
+	})
+}
+
+func TestSchemaGetCommand(t *testing.T) {
+	setup := func() (*SchemaGetCommand, *cli.MockUi) {
+		mockUI := cli.NewMockUi()
+		cmd, err := NewSchemaGetCommandFactory(mockUI)()
+		if err != nil {
+			panic(err)
+		}
+
+		getCommand := cmd.(*SchemaGetCommand)
+		getCommand.storage = u.NewEmptyStorage()
+		return getCommand, mockUI
+	}
+
+	setupLoggedIn := func(getSchema func(groupID, appID, serviceID, database, collection string) (interface{}, error)) (*SchemaGetCommand, *cli.MockUi) {
+		getCommand, mockUI := setup()
+		getCommand.user = &user.User{APIKey: "my-api-key", AccessToken: u.GenerateValidAccessToken()}
+		getCommand.realmClient = &u.MockRealmClient{
+			FetchAppByClientAppIDFn: func(clientAppID string) (*models.App, error) {
+				return &models.App{GroupID: "group-id", ID: "app-id", ClientAppID: clientAppID}, nil
+			},
+			ListServicesFn: func(groupID, appID string) ([]models.Service, error) {
+				return []models.Service{
+					{ID: "svc-1", Name: "http", Type: "http"},
+					{ID: "svc-2", Name: "mongodb-atlas", Type: "mongodb-atlas"},
+				}, nil
+			},
+			GetCollectionSchemaFn: getSchema,
+		}
+		return getCommand, mockUI
+	}
+
+	args := []string{"--app-id=app-with-schemas-abcde", "--data-source=mongodb-atlas", "--collection=store.items"}
+
+	t.Run("should require the user to be logged in", func(t *testing.T) {
+		getCommand, mockUI := setup()
+
+		exitCode := getCommand.Run(args)
+		u.So(t, exitCode, gc.ShouldEqual, 1)
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, user.ErrNotLoggedIn.Error())
+	})
+
+	t.Run("should print the deployed schema of the collection", func(t *testing.T) {
+		getCommand, mockUI := setupLoggedIn(func(groupID, appID, serviceID, database, collection string) (interface{}, error) {
+			u.So(t, []string{groupID, appID, serviceID, database, collection}, gc.ShouldResemble, []string{"group-id", "app-id", "svc-2", "store", "items"})
+			return map[string]interface{}{"title": "Item"}, nil
+		})
+
+		exitCode := getCommand.Run(args)
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+		u.So(t, mockUI.OutputWriter.String(), gc.ShouldEqual, "{\n  \"title\": \"Item\"\n}\n")
+	})
+
+	for _, tc := range []struct {
+		description   string
+		args          []string
+		getSchemaErr  error
+		expectedError string
+	}{
+		{
+			description:   "should require a data source",
+			args:          []string{"--app-id=app-with-schemas-abcde", "--collection=store.items"},
+			expectedError: "a data source (--data-source=[string]) must be supplied",
+		},
+		{
+			description:   "should require the database of the collection",
+			args:          []string{"--app-id=app-with-schemas-abcde", "--data-source=mongodb-atlas", "--collection=items"},
+			expectedError: `--collection must be given as [database].[collection], got "items"`,
+		},
+		{
+			description:   "should fail for a data source the app does not have",
+			args:          []string{"--app-id=app-with-schemas-abcde", "--data-source=analytics", "--collection=store.items"},
+			expectedError: `data source "analytics" not found in app-with-schemas-abcde`,
+		},
+		{
+			description:   "should fail for a service which is not a data source",
+			args:          []string{"--app-id=app-with-schemas-abcde", "--data-source=http", "--collection=store.items"},
+			expectedError: `service "http" is not a data source`,
+		},
+		{
+			description:   "should report a collection without a schema",
+			args:          args,
+			getSchemaErr:  api.ErrNoCollectionSchema,
+			expectedError: `the collection store.items of data source "mongodb-atlas" has no schema`,
+		},
+		{
+			description:   "should report a failure to get the schema",
+			args:          args,
+			getSchemaErr:  errors.New("something bad happened"),
+			expectedError: "failed to get the schema of store.items: something bad happened",
+		},
+	} {
+		t.Run(tc.description, func(t *testing.T) {
+			getCommand, mockUI := setupLoggedIn(func(groupID, appID, serviceID, database, collection string) (interface{}, error) {
+				return nil, tc.getSchemaErr
+			})
+
+			exitCode := getCommand.Run(tc.args)
+			u.So(t, exitCode, gc.ShouldEqual, 1)
+			u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, tc.expectedError)
+		})
+	}
+}
+
+func TestSchemaValidateCommand(t *testing.T) {
+	setup := func() (*SchemaValidateCommand, *cli.MockUi) {
+		mockUI := cli.NewMockUi()
+		cmd, err := NewSchemaValidateCommandFactory(mockUI)()
+		if err != nil {
+			panic(err)
+		}
+
+		validateCommand := cmd.(*SchemaValidateCommand)
+		validateCommand.storage = u.NewEmptyStorage()
+		return validateCommand, mockUI
+	}
+
+	t.Run("should succeed without logging in when the schemas have no problems", func(t *testing.T) {
+		validateCommand, mockUI := setup()
+
+		exitCode := validateCommand.Run([]string{"--path=../testdata/app_with_schemas"})
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+		u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, "No problems found in the schemas of the app at ../testdata/app_with_schemas")
+	})
+
+	t.Run("should list every problem with the schemas", func(t *testing.T) {
+		validateCommand, mockUI := setup()
+
+		exitCode := validateCommand.Run([]string{"--path=../testdata/app_with_invalid_schemas"})
+		u.So(t, exitCode, gc.ShouldEqual, 1)
+
+		errors := mockUI.ErrorWriter.String()
+		u.So(t, errors, gc.ShouldContainSubstring, `services/mongodb-atlas/rules/store.items.json: schema.properties.name.bsonType: unknown type "str"`)
+		u.So(t, errors, gc.ShouldContainSubstring, "services/mongodb-atlas/rules/store.orders.json: invalid JSON")
+		u.So(t, errors, gc.ShouldContainSubstring, "found 5 problems in the schemas of the app at ../testdata/app_with_invalid_schemas")
+	})
+}
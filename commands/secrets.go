@@ -1,9 +1,18 @@
 package commands
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
 
+	"github.com/10gen/realm-cli/api"
 	"github.com/10gen/realm-cli/models"
 	"github.com/10gen/realm-cli/secrets"
 	u "github.com/10gen/realm-cli/user"
@@ -46,16 +55,31 @@ func (sc *SecretsCommand) Run(args []string) int {
 const (
 	flagSecretName                     = "name"
 	flagSecretValue                    = "value"
+	flagSecretValueFile                = "value-file"
+	flagSecretNoTrim                   = "no-trim"
 	flagSecretID                       = "id"
 	flagSecretNameIdentifier           = "name"
 	flagSecretIDDeprecated             = "secret-id"
 	flagSecretNameIdentifierDeprecated = "secret-name"
+	flagSecretService                  = "service"
+	flagSecretField                    = "field"
+	flagSecretOutput                   = "output"
+)
+
+// Set of formats the secrets commands can print their result in
+const (
+	secretsOutputText = "text"
+	secretsOutputJSON = "json"
 )
 
 var (
 	errSecretNameRequired     = fmt.Errorf("a name (--%s=[string]) is required", flagSecretName)
 	errSecretValueRequired    = fmt.Errorf("a value (--%s=[string]) is required", flagSecretValue)
 	errSecretIDOrNameRequired = fmt.Errorf("a Secret name or ID (--%s=[string] or --%s=[string]) is required", flagSecretNameIdentifier, flagSecretID)
+	errSecretFieldRequired    = fmt.Errorf("a service config field (--%s=[string]) is required when adding a service secret", flagSecretField)
+	errSecretNameWithService  = fmt.Errorf("--%s cannot be used with --%s; the secret name is derived from the service and field", flagSecretName, flagSecretService)
+	errSecretValueBoth        = fmt.Errorf("only one of --%s and --%s may be supplied", flagSecretValue, flagSecretValueFile)
+	errSecretsAppIDRequired   = fmt.Errorf("an App ID (--%s=[string]) must be supplied to manage secrets", flagAppIDName)
 )
 
 // NewSecretsBaseCommand returns a new *SecretsBaseCommand
@@ -72,7 +96,14 @@ type SecretsBaseCommand struct {
 
 	workingDirectory string
 
-	flagAppID string
+	flagAppID  string
+	flagOutput string
+}
+
+// secretOutput is the JSON representation of a secret, which never includes its value
+type secretOutput struct {
+	Name string `json:"name"`
+	ID   string `json:"id,omitempty"`
 }
 
 // Help returns long-form help information for the SecretsBaseCommand command
@@ -81,7 +112,11 @@ func (sbc *SecretsBaseCommand) Help() string {
 OPTIONAL:
   --app-id [string]
 	The App ID for your app (i.e. the name of your app followed by a unique suffix, like "my-app-nysja").
-	Required if not being run from within a realm project directory.` +
+	Required if not being run from within a realm project directory.
+
+  -o [text|json], --output [text|json] (default: text)
+	How the result should be printed.
+	json - print the name and ID of each affected secret as JSON, for use in scripts. Secret values are never printed.` +
 		sbc.ProjectCommand.Help()
 }
 
@@ -91,11 +126,19 @@ func (sbc *SecretsBaseCommand) run(args []string) error {
 	}
 
 	sbc.FlagSet.StringVar(&sbc.flagAppID, flagAppIDName, "", "")
+	sbc.FlagSet.StringVar(&sbc.flagOutput, flagSecretOutput, secretsOutputText, "")
+	sbc.FlagSet.StringVar(&sbc.flagOutput, "o", secretsOutputText, "")
 
 	if err := sbc.ProjectCommand.run(args); err != nil {
 		return err
 	}
 
+	switch sbc.flagOutput {
+	case secretsOutputText, secretsOutputJSON:
+	default:
+		return fmt.Errorf("unknown output format %q; accepted values are [%s|%s]", sbc.flagOutput, secretsOutputText, secretsOutputJSON)
+	}
+
 	user, err := sbc.User()
 	if err != nil {
 		return err
@@ -109,39 +152,50 @@ func (sbc *SecretsBaseCommand) run(args []string) error {
 }
 
 func (sbc *SecretsBaseCommand) resolveApp() (*models.App, error) {
-	appID := sbc.flagAppID
-	if sbc.flagAppID == "" {
-		appPath, err := utils.ResolveAppDirectory("", sbc.workingDirectory)
-		if err != nil {
-			return nil, err
-		}
+	_, app, _, err := sbc.BaseCommand.resolveApp(appTarget{
+		appID:            sbc.flagAppID,
+		workingDirectory: sbc.workingDirectory,
+		projectID:        sbc.flagProjectID,
+		errAppIDRequired: errSecretsAppIDRequired,
+	})
+	return app, err
+}
 
-		appInstanceData, err := utils.ResolveAppInstanceData(sbc.flagAppID, appPath)
-		if err != nil {
-			return nil, err
-		}
-		appID = appInstanceData.AppID()
+// info prints a human readable message, which is left out of the JSON output
+func (sbc *SecretsBaseCommand) info(msg string) {
+	if sbc.flagOutput != secretsOutputJSON {
+		sbc.UI.Info(msg)
 	}
+}
 
-	realmClient, err := sbc.RealmClient()
+// printJSON prints the secrets as JSON when the JSON output is requested
+func (sbc *SecretsBaseCommand) printJSON(v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	var app *models.App
-	if sbc.flagProjectID == "" {
-		app, err = realmClient.FetchAppByClientAppID(appID)
-		if err != nil {
-			return nil, err
-		}
-	} else {
-		app, err = realmClient.FetchAppByGroupIDAndClientAppID(sbc.flagProjectID, appID)
-		if err != nil {
-			return nil, err
+	sbc.UI.Output(string(data))
+	return nil
+}
+
+// findSecret looks up the secret with the given ID, or else the given name
+func findSecret(realmClient api.RealmClient, app *models.App, secretID, secretName string) (secrets.Secret, error) {
+	appSecrets, err := realmClient.ListSecrets(app.GroupID, app.ID)
+	if err != nil {
+		return secrets.Secret{}, err
+	}
+
+	for _, secret := range appSecrets {
+		if (secretID != "" && secret.ID == secretID) || (secretID == "" && secret.Name == secretName) {
+			return secret, nil
 		}
 	}
 
-	return app, nil
+	if secretID != "" {
+		return secrets.Secret{}, fmt.Errorf("secret not found: %s", secretID)
+	}
+	return secrets.Secret{}, fmt.Errorf("secret not found: %s", secretName)
 }
 
 // NewSecretsListCommandFactory returns a new cli.CommandFactory given a cli.Ui
@@ -190,17 +244,44 @@ func (slc *SecretsListCommand) Run(args []string) int {
 		return 1
 	}
 
+	if slc.flagOutput == secretsOutputJSON {
+		output := make([]secretOutput, 0, len(secrets))
+		for _, secret := range secrets {
+			output = append(output, secretOutput{Name: secret.Name, ID: secret.ID})
+		}
+		if err := slc.printJSON(output); err != nil {
+			slc.UI.Error(err.Error())
+			return 1
+		}
+		return 0
+	}
+
 	if len(secrets) == 0 {
 		slc.UI.Info("No secrets found for this app")
 		return 0
 	}
 
-	slc.UI.Info("ID                       Name")
-	for _, secret := range secrets {
-		slc.UI.Info(fmt.Sprintf("%s %s", secret.ID, secret.Name))
+	slc.UI.Output(secretsTable(secrets))
+	return 0
+}
+
+// secretsTable lays out the name and ID of each secret as a table sorted by name.
+// Secret values cannot be fetched, so they are never shown
+func secretsTable(appSecrets []secrets.Secret) string {
+	sorted := append([]secrets.Secret(nil), appSecrets...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	var table strings.Builder
+	w := tabwriter.NewWriter(&table, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tID")
+	for _, secret := range sorted {
+		fmt.Fprintf(w, "%s\t%s\n", secret.Name, secret.ID)
 	}
+	w.Flush()
 
-	return 0
+	return strings.TrimSuffix(table.String(), "\n")
 }
 
 func (slc *SecretsListCommand) listSecrets() ([]secrets.Secret, error) {
@@ -227,6 +308,7 @@ func NewSecretsAddCommandFactory(ui cli.Ui) cli.CommandFactory {
 
 		return &SecretsAddCommand{
 			SecretsBaseCommand: NewSecretsBaseCommand("add", workingDirectory, ui),
+			stdin:              os.Stdin,
 		}, nil
 	}
 }
@@ -235,8 +317,15 @@ func NewSecretsAddCommandFactory(ui cli.Ui) cli.CommandFactory {
 type SecretsAddCommand struct {
 	*SecretsBaseCommand
 
-	flagSecretName  string
-	flagSecretValue string
+	// stdin is read for the value with --value-file=-
+	stdin io.Reader
+
+	flagSecretName      string
+	flagSecretValue     string
+	flagSecretValueFile string
+	flagSecretNoTrim    bool
+	flagSecretService   string
+	flagSecretField     string
 }
 
 // Synopsis returns a one-liner description for this command
@@ -248,14 +337,25 @@ func (sac *SecretsAddCommand) Synopsis() string {
 func (sac *SecretsAddCommand) Help() string {
 	return `Add a secret to your Realm Application.
 
-Usage: realm-cli secrets add --name [string] --value [string] [options]
+Usage:
+  realm-cli secrets add --name [string] --value [string] [options]
+  realm-cli secrets add --name [string] --value-file [string] [options]
+  realm-cli secrets add --service [string] --field [string] --value [string] [options]
 
 REQUIRED:
-  --name [string]
+  --name [string] OR --service [string] --field [string]
 	The name of your secret.
+	For a service secret, the name is derived from the service and the config field it backs
+	(e.g. "__twilio_svc_auth_token"), and the field is added to the service's "secret_config"
+	in the local app directory.
 
-  --value [string]
-	The value of your secret.
+  --value [string] OR --value-file [string]
+	The value of your secret, or a path to a file containing it, e.g. for a key or a certificate.
+	Use --value-file=- to read the value from stdin.
+
+OPTIONS:
+  --no-trim
+	Keep the trailing newlines of the value read with --value-file, which are trimmed by default.
 ` +
 		sac.SecretsBaseCommand.Help()
 }
@@ -266,6 +366,10 @@ func (sac *SecretsAddCommand) Run(args []string) int {
 
 	sac.FlagSet.StringVar(&sac.flagSecretName, flagSecretName, "", "")
 	sac.FlagSet.StringVar(&sac.flagSecretValue, flagSecretValue, "", "")
+	sac.FlagSet.StringVar(&sac.flagSecretValueFile, flagSecretValueFile, "", "")
+	sac.FlagSet.BoolVar(&sac.flagSecretNoTrim, flagSecretNoTrim, false, "")
+	sac.FlagSet.StringVar(&sac.flagSecretService, flagSecretService, "", "")
+	sac.FlagSet.StringVar(&sac.flagSecretField, flagSecretField, "", "")
 
 	if err := sac.SecretsBaseCommand.run(args); err != nil {
 		sac.UI.Error(err.Error())
@@ -281,14 +385,49 @@ func (sac *SecretsAddCommand) Run(args []string) int {
 }
 
 func (sac *SecretsAddCommand) addSecret() error {
+	if sac.flagSecretService != "" {
+		if sac.flagSecretName != "" {
+			return errSecretNameWithService
+		}
+		if sac.flagSecretField == "" {
+			return errSecretFieldRequired
+		}
+		sac.flagSecretName = utils.ServiceSecretName(sac.flagSecretService, sac.flagSecretField)
+	}
+
 	if sac.flagSecretName == "" {
 		return errSecretNameRequired
 	}
 
+	if sac.flagSecretValueFile != "" {
+		if sac.flagSecretValue != "" {
+			return errSecretValueBoth
+		}
+
+		value, err := sac.readValueFile()
+		if err != nil {
+			return err
+		}
+		sac.flagSecretValue = value
+	}
+
 	if sac.flagSecretValue == "" {
 		return errSecretValueRequired
 	}
 
+	var serviceConfigPath string
+	if sac.flagSecretService != "" {
+		appPath, err := utils.ResolveAppDirectory("", sac.workingDirectory)
+		if err != nil {
+			return err
+		}
+
+		serviceConfigPath, err = utils.FindServiceConfig(appPath, sac.flagSecretService)
+		if err != nil {
+			return err
+		}
+	}
+
 	app, err := sac.resolveApp()
 	if err != nil {
 		return err
@@ -306,10 +445,49 @@ func (sac *SecretsAddCommand) addSecret() error {
 		return addErr
 	}
 
-	sac.UI.Info(fmt.Sprintf("New secret created: %s", sac.flagSecretName))
+	sac.info(fmt.Sprintf("New secret created: %s", sac.flagSecretName))
+
+	if serviceConfigPath != "" {
+		if err := utils.SetServiceSecretConfig(serviceConfigPath, sac.flagSecretField, sac.flagSecretName); err != nil {
+			return err
+		}
+		sac.info(fmt.Sprintf("Updated secret_config of service %s: %s", sac.flagSecretService, serviceConfigPath))
+	}
+
+	if sac.flagOutput == secretsOutputJSON {
+		// the ID is assigned by Realm, so look the secret up once it is created. The secret exists even if
+		// it cannot be looked up, so it is still printed, only without its ID
+		output := secretOutput{Name: sac.flagSecretName}
+		if secret, err := findSecret(realmClient, app, "", sac.flagSecretName); err != nil {
+			sac.UI.Warn(fmt.Sprintf("New secret created: %s, but failed to look up its ID: %s", sac.flagSecretName, err))
+		} else {
+			output.ID = secret.ID
+		}
+		return sac.printJSON(output)
+	}
+
 	return nil
 }
 
+// readValueFile reads the value set by --value-file, from stdin if it is "-"
+func (sac *SecretsAddCommand) readValueFile() (string, error) {
+	var data []byte
+	var err error
+	if sac.flagSecretValueFile == "-" {
+		data, err = ioutil.ReadAll(sac.stdin)
+	} else {
+		data, err = ioutil.ReadFile(sac.flagSecretValueFile)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read --%s: %s", flagSecretValueFile, err)
+	}
+
+	if sac.flagSecretNoTrim {
+		return string(data), nil
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
 // NewSecretsUpdateCommandFactory returns a new cli.CommandFactory given a cli.Ui
 func NewSecretsUpdateCommandFactory(ui cli.Ui) cli.CommandFactory {
 	return func() (cli.Command, error) {
@@ -352,6 +530,7 @@ REQUIRED:
 
   --value [string]
 	The value that your secret is being updated to.
+	You are prompted for it without echoing it when it is omitted, unless -y or --output json is set.
 ` +
 		suc.SecretsBaseCommand.Help()
 }
@@ -384,6 +563,21 @@ func (suc *SecretsUpdateCommand) updateSecret() error {
 		return errSecretIDOrNameRequired
 	}
 
+	if suc.flagSecretValue == "" {
+		if suc.flagYes || suc.flagOutput == secretsOutputJSON {
+			return errSecretValueRequired
+		}
+
+		value, err := suc.UI.AskSecret("Value:")
+		if err != nil {
+			return err
+		}
+		if value == "" {
+			return errSecretValueRequired
+		}
+		suc.flagSecretValue = value
+	}
+
 	app, err := suc.resolveApp()
 	if err != nil {
 		return err
@@ -394,16 +588,28 @@ func (suc *SecretsUpdateCommand) updateSecret() error {
 		return err
 	}
 
+	// look the secret up before it is updated, so that the update is not reported as failed once it is made
+	var updated secrets.Secret
+	if suc.flagOutput == secretsOutputJSON {
+		if updated, err = findSecret(realmClient, app, suc.flagSecretID, suc.flagSecretName); err != nil {
+			return err
+		}
+	}
+
 	if suc.flagSecretID != "" {
 		if updateErr := realmClient.UpdateSecretByID(app.GroupID, app.ID, suc.flagSecretID, suc.flagSecretValue); updateErr != nil {
 			return updateErr
 		}
-		suc.UI.Info(fmt.Sprintf("Secret updated: %s", suc.flagSecretID))
+		suc.info(fmt.Sprintf("Secret updated: %s", suc.flagSecretID))
 	} else {
 		if updateErr := realmClient.UpdateSecretByName(app.GroupID, app.ID, suc.flagSecretName, suc.flagSecretValue); updateErr != nil {
 			return updateErr
 		}
-		suc.UI.Info(fmt.Sprintf("Secret updated: %s", suc.flagSecretName))
+		suc.info(fmt.Sprintf("Secret updated: %s", suc.flagSecretName))
+	}
+
+	if suc.flagOutput == secretsOutputJSON {
+		return suc.printJSON(secretOutput{Name: updated.Name, ID: updated.ID})
 	}
 
 	return nil
@@ -423,10 +629,29 @@ func NewSecretsRemoveCommandFactory(ui cli.Ui) cli.CommandFactory {
 	}
 }
 
+// NewSecretsDeleteCommandFactory returns a new cli.CommandFactory given a cli.Ui
+func NewSecretsDeleteCommandFactory(ui cli.Ui) cli.CommandFactory {
+	return func() (cli.Command, error) {
+		workingDirectory, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+
+		return &SecretsRemoveCommand{
+			SecretsBaseCommand: NewSecretsBaseCommand("delete", workingDirectory, ui),
+			prompt:             true,
+		}, nil
+	}
+}
+
 // SecretsRemoveCommand is used to remove secrets from a Realm app
 type SecretsRemoveCommand struct {
 	*SecretsBaseCommand
 
+	// prompt is set for "secrets delete", which lets the user choose the secrets to remove when neither
+	// a name nor an ID is given, and confirms before removing them. "secrets remove" never prompts
+	prompt bool
+
 	flagSecretID   string
 	flagSecretName string
 }
@@ -438,6 +663,23 @@ func (src *SecretsRemoveCommand) Synopsis() string {
 
 // Help returns long-form help information for this command
 func (src *SecretsRemoveCommand) Help() string {
+	if src.prompt {
+		return `Delete secrets from your Realm Application.
+The secrets to delete are confirmed first, unless -y is set.
+
+Usage:
+  realm-cli secrets delete [options]
+  realm-cli secrets delete --name [string] [options]
+  realm-cli secrets delete --id [string] [options]
+
+OPTIONS:
+  --name [string] OR --id [string]
+	The name or ID of your secret.
+	When neither is given, choose one or more secrets to delete from the secrets of your app.
+` +
+			src.SecretsBaseCommand.Help()
+	}
+
 	return `Remove a secret from your Realm Application.
 
 Usage:
@@ -474,7 +716,7 @@ func (src *SecretsRemoveCommand) Run(args []string) int {
 }
 
 func (src *SecretsRemoveCommand) removeSecret() error {
-	if src.flagSecretID == "" && src.flagSecretName == "" {
+	if src.flagSecretID == "" && src.flagSecretName == "" && (!src.prompt || src.flagYes || src.flagOutput == secretsOutputJSON) {
 		return errSecretIDOrNameRequired
 	}
 
@@ -488,17 +730,123 @@ func (src *SecretsRemoveCommand) removeSecret() error {
 		return err
 	}
 
+	if src.prompt {
+		return src.deleteSecrets(realmClient, app)
+	}
+
+	// the secret can no longer be looked up once it is removed
+	var removed secrets.Secret
+	if src.flagOutput == secretsOutputJSON {
+		if removed, err = findSecret(realmClient, app, src.flagSecretID, src.flagSecretName); err != nil {
+			return err
+		}
+	}
+
 	if src.flagSecretID != "" {
 		if removeErr := realmClient.RemoveSecretByID(app.GroupID, app.ID, src.flagSecretID); removeErr != nil {
 			return removeErr
 		}
-		src.UI.Info(fmt.Sprintf("Secret removed: %s", src.flagSecretID))
+		src.info(fmt.Sprintf("Secret removed: %s", src.flagSecretID))
 	} else {
 		if removeErr := realmClient.RemoveSecretByName(app.GroupID, app.ID, src.flagSecretName); removeErr != nil {
 			return removeErr
 		}
-		src.UI.Info(fmt.Sprintf("Secret removed: %s", src.flagSecretName))
+		src.info(fmt.Sprintf("Secret removed: %s", src.flagSecretName))
+	}
+
+	if src.flagOutput == secretsOutputJSON {
+		return src.printJSON(secretOutput{Name: removed.Name, ID: removed.ID})
 	}
 
 	return nil
 }
+
+// deleteSecrets removes the secret given by name or ID, or else the secrets the user chooses, once confirmed
+func (src *SecretsRemoveCommand) deleteSecrets(realmClient api.RealmClient, app *models.App) error {
+	var selected []secrets.Secret
+	if src.flagSecretID != "" || src.flagSecretName != "" {
+		secret, err := findSecret(realmClient, app, src.flagSecretID, src.flagSecretName)
+		if err != nil {
+			return err
+		}
+		selected = []secrets.Secret{secret}
+	} else {
+		var err error
+		if selected, err = src.selectSecrets(realmClient, app); err != nil {
+			return err
+		}
+	}
+
+	names := make([]string, 0, len(selected))
+	for _, secret := range selected {
+		names = append(names, secret.Name)
+	}
+
+	confirm, err := src.AskYesNo(fmt.Sprintf("Are you sure you want to delete %s?", strings.Join(names, ", ")))
+	if err != nil {
+		return err
+	}
+	if !confirm {
+		return nil
+	}
+
+	output := make([]secretOutput, 0, len(selected))
+	for _, secret := range selected {
+		if err := realmClient.RemoveSecretByID(app.GroupID, app.ID, secret.ID); err != nil {
+			return fmt.Errorf("failed to delete secret %s: %s", secret.Name, err)
+		}
+		src.info(fmt.Sprintf("Secret deleted: %s", secret.Name))
+		output = append(output, secretOutput{Name: secret.Name, ID: secret.ID})
+	}
+
+	if src.flagOutput == secretsOutputJSON {
+		return src.printJSON(output)
+	}
+	return nil
+}
+
+// selectSecrets lists the secrets of the app and asks the user to choose among them by number
+func (src *SecretsRemoveCommand) selectSecrets(realmClient api.RealmClient, app *models.App) ([]secrets.Secret, error) {
+	appSecrets, err := realmClient.ListSecrets(app.GroupID, app.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(appSecrets) == 0 {
+		return nil, errors.New("no secrets found for this app")
+	}
+
+	sort.SliceStable(appSecrets, func(i, j int) bool {
+		return appSecrets[i].Name < appSecrets[j].Name
+	})
+	for i, secret := range appSecrets {
+		src.UI.Info(fmt.Sprintf("%d) %s (%s)", i+1, secret.Name, secret.ID))
+	}
+
+	answer, err := src.Ask("Which secrets would you like to delete? Enter their numbers separated by commas", "")
+	if err != nil {
+		return nil, err
+	}
+
+	var selected []secrets.Secret
+	chosen := map[int]bool{}
+	for _, part := range strings.Split(answer, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 1 || n > len(appSecrets) {
+			return nil, fmt.Errorf("invalid choice %q: expected numbers from 1 to %d", part, len(appSecrets))
+		}
+		if !chosen[n] {
+			chosen[n] = true
+			selected = append(selected, appSecrets[n-1])
+		}
+	}
+
+	if len(selected) == 0 {
+		return nil, errors.New("no secrets were chosen")
+	}
+	return selected, nil
+}
@@ -1,7 +1,13 @@
 package commands
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/10gen/realm-cli/models"
@@ -476,6 +482,30 @@ func TestSecretsCommand(t *testing.T) {
 			u.So(t, mockUI.OutputWriter.String(), gc.ShouldNotContainSubstring, "there")
 		})
 
+		t.Run("listing secrets prints a table sorted by name", func(t *testing.T) {
+			mockUI := cli.NewMockUi()
+			cmd, err := NewSecretsListCommandFactory(mockUI)()
+			u.So(t, err, gc.ShouldBeNil)
+
+			listCommand := cmd.(*SecretsListCommand)
+			setup(listCommand.SecretsBaseCommand, &mockClientFunctions{
+				listSecretsFn: func(appID, groupID string) ([]secrets.Secret, error) {
+					return []secrets.Secret{
+						{ID: "5e8d1e7f3c", Name: "twilio_token"},
+						{ID: "5e8d1e7f3a", Name: "aws_key"},
+					}, nil
+				},
+			})
+			exitCode := listCommand.Run(validListArgs)
+			u.So(t, exitCode, gc.ShouldEqual, 0)
+			u.So(t, mockUI.OutputWriter.String(), gc.ShouldEqual, strings.Join([]string{
+				"NAME          ID",
+				"aws_key       5e8d1e7f3a",
+				"twilio_token  5e8d1e7f3c",
+				"",
+			}, "\n"))
+		})
+
 		t.Run("adding a secret works", func(t *testing.T) {
 			mockUI := cli.NewMockUi()
 			cmd, err := NewSecretsAddCommandFactory(mockUI)()
@@ -489,6 +519,171 @@ func TestSecretsCommand(t *testing.T) {
 			u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, "New secret created")
 		})
 
+		t.Run("adding a secret with --value-file", func(t *testing.T) {
+			valueFile, err := ioutil.TempFile("", "realm-cli-secret")
+			u.So(t, err, gc.ShouldBeNil)
+			defer os.Remove(valueFile.Name())
+
+			_, err = valueFile.WriteString("-----BEGIN KEY-----\nabc\n-----END KEY-----\n\n")
+			u.So(t, err, gc.ShouldBeNil)
+			u.So(t, valueFile.Close(), gc.ShouldBeNil)
+
+			for _, tc := range []struct {
+				description   string
+				args          []string
+				stdin         string
+				expectedValue string
+				expectedError string
+			}{
+				{
+					description:   "reads the value from the file and trims its trailing newlines",
+					args:          []string{"--value-file=" + valueFile.Name()},
+					expectedValue: "-----BEGIN KEY-----\nabc\n-----END KEY-----",
+				},
+				{
+					description:   "keeps the trailing newlines with --no-trim",
+					args:          []string{"--value-file=" + valueFile.Name(), "--no-trim"},
+					expectedValue: "-----BEGIN KEY-----\nabc\n-----END KEY-----\n\n",
+				},
+				{
+					description:   "reads the value from stdin",
+					args:          []string{"--value-file=-"},
+					stdin:         "from stdin\n",
+					expectedValue: "from stdin",
+				},
+				{
+					description:   "fails if the value is given as well",
+					args:          []string{"--value-file=" + valueFile.Name(), "--value=bar"},
+					expectedError: errSecretValueBoth.Error(),
+				},
+				{
+					description:   "fails if the file is missing",
+					args:          []string{"--value-file=" + valueFile.Name() + ".missing"},
+					expectedError: "failed to read --value-file",
+				},
+				{
+					description:   "fails if the file is empty",
+					args:          []string{"--value-file=-"},
+					stdin:         "\n",
+					expectedError: errSecretValueRequired.Error(),
+				},
+			} {
+				t.Run(tc.description, func(t *testing.T) {
+					mockUI := cli.NewMockUi()
+					cmd, err := NewSecretsAddCommandFactory(mockUI)()
+					u.So(t, err, gc.ShouldBeNil)
+
+					var value string
+					addCommand := cmd.(*SecretsAddCommand)
+					addCommand.stdin = strings.NewReader(tc.stdin)
+					setup(addCommand.SecretsBaseCommand, &mockClientFunctions{
+						addSecretFn: func(groupID, appID string, secret secrets.Secret) error {
+							value = secret.Value
+							return nil
+						},
+					})
+
+					exitCode := addCommand.Run(append([]string{"--app-id=my-app-abcdef", "--name=key"}, tc.args...))
+					if tc.expectedError != "" {
+						u.So(t, exitCode, gc.ShouldEqual, 1)
+						u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, tc.expectedError)
+					} else {
+						u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+						u.So(t, exitCode, gc.ShouldEqual, 0)
+					}
+					u.So(t, value, gc.ShouldEqual, tc.expectedValue)
+				})
+			}
+		})
+
+		t.Run("adding a service secret", func(t *testing.T) {
+			setupAppDir := func(t *testing.T) string {
+				appDir, err := ioutil.TempDir("", "realm-cli-secrets")
+				u.So(t, err, gc.ShouldBeNil)
+
+				serviceDir := filepath.Join(appDir, "services", "twilio_svc")
+				u.So(t, os.MkdirAll(serviceDir, os.ModePerm), gc.ShouldBeNil)
+				u.So(t, ioutil.WriteFile(filepath.Join(appDir, "config.json"), []byte(`{"app_id": "my-app-abcdef"}`), 0600), gc.ShouldBeNil)
+				u.So(t, ioutil.WriteFile(filepath.Join(serviceDir, "config.json"), []byte(`{"name": "twilio_svc", "type": "twilio", "config": {"sid": "abcdefgh"}}`), 0600), gc.ShouldBeNil)
+
+				return appDir
+			}
+
+			t.Run("derives the secret name and wires it into the service secret_config", func(t *testing.T) {
+				appDir := setupAppDir(t)
+				defer os.RemoveAll(appDir)
+
+				mockUI := cli.NewMockUi()
+				cmd, err := NewSecretsAddCommandFactory(mockUI)()
+				u.So(t, err, gc.ShouldBeNil)
+
+				var secretName string
+				addCommand := cmd.(*SecretsAddCommand)
+				addCommand.workingDirectory = appDir
+				setup(addCommand.SecretsBaseCommand, &mockClientFunctions{
+					addSecretFn: func(appID, groupID string, secret secrets.Secret) error {
+						secretName = secret.Name
+						return nil
+					},
+				})
+
+				exitCode := addCommand.Run([]string{"--app-id=my-app-abcdef", "--service=twilio_svc", "--field=auth_token", "--value=bar"})
+				u.So(t, exitCode, gc.ShouldEqual, 0)
+				u.So(t, secretName, gc.ShouldEqual, "__twilio_svc_auth_token")
+
+				var config map[string]interface{}
+				data, err := ioutil.ReadFile(filepath.Join(appDir, "services", "twilio_svc", "config.json"))
+				u.So(t, err, gc.ShouldBeNil)
+				u.So(t, json.Unmarshal(data, &config), gc.ShouldBeNil)
+				u.So(t, config["secret_config"], gc.ShouldResemble, map[string]interface{}{"auth_token": "__twilio_svc_auth_token"})
+				u.So(t, config["config"], gc.ShouldResemble, map[string]interface{}{"sid": "abcdefgh"})
+			})
+
+			for _, tc := range []struct {
+				description   string
+				args          []string
+				expectedError string
+			}{
+				{
+					description:   "fails if the field is missing",
+					args:          []string{"--app-id=my-app-abcdef", "--service=twilio_svc", "--value=bar"},
+					expectedError: errSecretFieldRequired.Error(),
+				},
+				{
+					description:   "fails if a name is also provided",
+					args:          []string{"--app-id=my-app-abcdef", "--service=twilio_svc", "--field=auth_token", "--name=foo", "--value=bar"},
+					expectedError: errSecretNameWithService.Error(),
+				},
+				{
+					description:   "fails without creating the secret if the service does not exist locally",
+					args:          []string{"--app-id=my-app-abcdef", "--service=other_svc", "--field=auth_token", "--value=bar"},
+					expectedError: `could not find service "other_svc"`,
+				},
+			} {
+				t.Run(tc.description, func(t *testing.T) {
+					appDir := setupAppDir(t)
+					defer os.RemoveAll(appDir)
+
+					mockUI := cli.NewMockUi()
+					cmd, err := NewSecretsAddCommandFactory(mockUI)()
+					u.So(t, err, gc.ShouldBeNil)
+
+					addCommand := cmd.(*SecretsAddCommand)
+					addCommand.workingDirectory = appDir
+					setup(addCommand.SecretsBaseCommand, &mockClientFunctions{
+						addSecretFn: func(appID, groupID string, secret secrets.Secret) error {
+							t.Error("the secret should not have been created")
+							return nil
+						},
+					})
+
+					exitCode := addCommand.Run(tc.args)
+					u.So(t, exitCode, gc.ShouldEqual, 1)
+					u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, tc.expectedError)
+				})
+			}
+		})
+
 		t.Run("updating a secret by id works", func(t *testing.T) {
 			mockUI := cli.NewMockUi()
 			cmd, err := NewSecretsUpdateCommandFactory(mockUI)()
@@ -540,5 +735,308 @@ func TestSecretsCommand(t *testing.T) {
 			u.So(t, exitCode, gc.ShouldEqual, 0)
 			u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, "Secret removed: thisisaname")
 		})
+
+		t.Run("updating a secret", func(t *testing.T) {
+			for _, tc := range []struct {
+				description   string
+				args          []string
+				input         string
+				expectedValue string
+				expectedError string
+			}{
+				{
+					description:   "prompts for the value when it is omitted",
+					args:          []string{"--app-id=my-app-abcdef", "--name=thisisaname"},
+					input:         "rotated\n",
+					expectedValue: "rotated",
+				},
+				{
+					description:   "requires the value with -y",
+					args:          []string{"--app-id=my-app-abcdef", "--name=thisisaname", "-y"},
+					expectedError: errSecretValueRequired.Error(),
+				},
+				{
+					description:   "fails if the secret does not exist",
+					args:          []string{"--app-id=my-app-abcdef", "--name=missing", "--value=rotated"},
+					expectedError: "secret not found: missing",
+				},
+			} {
+				t.Run(tc.description, func(t *testing.T) {
+					mockUI := cli.NewMockUi()
+					mockUI.InputReader = strings.NewReader(tc.input)
+					cmd, err := NewSecretsUpdateCommandFactory(mockUI)()
+					u.So(t, err, gc.ShouldBeNil)
+
+					var value string
+					updateCommand := cmd.(*SecretsUpdateCommand)
+					setup(updateCommand.SecretsBaseCommand, &mockClientFunctions{
+						updateSecretByNameFn: func(groupID, appID, secretName, secretValue string) error {
+							if secretName != "thisisaname" {
+								return fmt.Errorf("secret not found: %s", secretName)
+							}
+							value = secretValue
+							return nil
+						},
+					})
+
+					exitCode := updateCommand.Run(tc.args)
+					if tc.expectedError != "" {
+						u.So(t, exitCode, gc.ShouldEqual, 1)
+						u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, tc.expectedError)
+					} else {
+						u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+						u.So(t, exitCode, gc.ShouldEqual, 0)
+						u.So(t, mockUI.OutputWriter.String(), gc.ShouldNotContainSubstring, tc.expectedValue)
+					}
+					u.So(t, value, gc.ShouldEqual, tc.expectedValue)
+				})
+			}
+		})
+
+		t.Run("deleting secrets", func(t *testing.T) {
+			listSecrets := func(groupID, appID string) ([]secrets.Secret, error) {
+				return []secrets.Secret{{ID: "id-c", Name: "charlie"}, {ID: "id-a", Name: "alpha"}, {ID: "id-b", Name: "bravo"}}, nil
+			}
+
+			for _, tc := range []struct {
+				description     string
+				args            []string
+				input           string
+				expectedDeletes []string
+				expectedOutput  string
+				expectedError   string
+			}{
+				{
+					description:     "deletes the secret given by name once confirmed",
+					args:            []string{"--app-id=my-app-abcdef", "--name=bravo"},
+					input:           "y\n",
+					expectedDeletes: []string{"id-b"},
+					expectedOutput:  "Secret deleted: bravo",
+				},
+				{
+					description:     "deletes the secret given by name without confirming with -y",
+					args:            []string{"--app-id=my-app-abcdef", "--name=bravo", "-y"},
+					expectedDeletes: []string{"id-b"},
+					expectedOutput:  "Secret deleted: bravo",
+				},
+				{
+					description:    "does not delete anything if not confirmed",
+					args:           []string{"--app-id=my-app-abcdef", "--id=id-b"},
+					input:          "n\n",
+					expectedOutput: "Are you sure you want to delete bravo? [y/n]:",
+				},
+				{
+					description:     "deletes the secrets chosen by number",
+					args:            []string{"--app-id=my-app-abcdef"},
+					input:           "3,1,3\ny\n",
+					expectedDeletes: []string{"id-c", "id-a"},
+					expectedOutput:  "Are you sure you want to delete charlie, alpha? [y/n]:",
+				},
+				{
+					description:   "fails if a chosen number is out of range",
+					args:          []string{"--app-id=my-app-abcdef"},
+					input:         "4\n",
+					expectedError: `invalid choice "4": expected numbers from 1 to 3`,
+				},
+				{
+					description:   "fails if the secret does not exist",
+					args:          []string{"--app-id=my-app-abcdef", "--name=delta", "-y"},
+					expectedError: "secret not found: delta",
+				},
+				{
+					description:   "requires a name or id with -y",
+					args:          []string{"--app-id=my-app-abcdef", "-y"},
+					expectedError: errSecretIDOrNameRequired.Error(),
+				},
+			} {
+				t.Run(tc.description, func(t *testing.T) {
+					mockUI := cli.NewMockUi()
+					mockUI.InputReader = strings.NewReader(tc.input)
+					cmd, err := NewSecretsDeleteCommandFactory(mockUI)()
+					u.So(t, err, gc.ShouldBeNil)
+
+					var deletes []string
+					deleteCommand := cmd.(*SecretsRemoveCommand)
+					setup(deleteCommand.SecretsBaseCommand, &mockClientFunctions{
+						listSecretsFn: listSecrets,
+						removeSecretByIDFn: func(groupID, appID, secretID string) error {
+							deletes = append(deletes, secretID)
+							return nil
+						},
+					})
+
+					exitCode := deleteCommand.Run(tc.args)
+					if tc.expectedError != "" {
+						u.So(t, exitCode, gc.ShouldEqual, 1)
+						u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, tc.expectedError)
+					} else {
+						u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+						u.So(t, exitCode, gc.ShouldEqual, 0)
+						u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, tc.expectedOutput)
+					}
+					u.So(t, deletes, gc.ShouldResemble, tc.expectedDeletes)
+				})
+			}
+		})
+
+		t.Run("with --output json", func(t *testing.T) {
+			listSecrets := func(groupID, appID string) ([]secrets.Secret, error) {
+				return []secrets.Secret{{ID: "thisisanid", Name: "thisisaname"}, {ID: "123", Name: "foo"}}, nil
+			}
+
+			for _, tc := range []struct {
+				description    string
+				factory        func(ui cli.Ui) cli.CommandFactory
+				args           []string
+				expectedOutput string
+			}{
+				{
+					description:    "listing secrets prints their names and ids",
+					factory:        NewSecretsListCommandFactory,
+					args:           validListArgs,
+					expectedOutput: `[{"name": "thisisaname", "id": "thisisanid"}, {"name": "foo", "id": "123"}]`,
+				},
+				{
+					description:    "adding a secret prints the id it was assigned",
+					factory:        NewSecretsAddCommandFactory,
+					args:           validAddArgs,
+					expectedOutput: `{"name": "foo", "id": "123"}`,
+				},
+				{
+					description:    "updating a secret by id prints its name",
+					factory:        NewSecretsUpdateCommandFactory,
+					args:           validUpdateByIDArgs,
+					expectedOutput: `{"name": "thisisaname", "id": "thisisanid"}`,
+				},
+				{
+					description:    "updating a secret by name prints its id",
+					factory:        NewSecretsUpdateCommandFactory,
+					args:           validUpdateByNameArgs,
+					expectedOutput: `{"name": "thisisaname", "id": "thisisanid"}`,
+				},
+				{
+					description:    "removing a secret by id prints its name",
+					factory:        NewSecretsRemoveCommandFactory,
+					args:           validRemoveByIDArgs,
+					expectedOutput: `{"name": "thisisaname", "id": "thisisanid"}`,
+				},
+				{
+					description:    "removing a secret by name prints its id",
+					factory:        NewSecretsRemoveCommandFactory,
+					args:           validRemoveByNameArgs,
+					expectedOutput: `{"name": "thisisaname", "id": "thisisanid"}`,
+				},
+			} {
+				t.Run(tc.description, func(t *testing.T) {
+					mockUI := cli.NewMockUi()
+					cmd, err := tc.factory(mockUI)()
+					u.So(t, err, gc.ShouldBeNil)
+
+					var baseCommand *SecretsBaseCommand
+					switch c := cmd.(type) {
+					case *SecretsListCommand:
+						baseCommand = c.SecretsBaseCommand
+					case *SecretsAddCommand:
+						baseCommand = c.SecretsBaseCommand
+					case *SecretsUpdateCommand:
+						baseCommand = c.SecretsBaseCommand
+					case *SecretsRemoveCommand:
+						baseCommand = c.SecretsBaseCommand
+					}
+					setup(baseCommand, &mockClientFunctions{listSecretsFn: listSecrets})
+
+					exitCode := cmd.Run(append([]string{"-o", "json"}, tc.args...))
+					u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+					u.So(t, exitCode, gc.ShouldEqual, 0)
+
+					var output, expectedOutput interface{}
+					u.So(t, json.Unmarshal(mockUI.OutputWriter.Bytes(), &output), gc.ShouldBeNil)
+					u.So(t, json.Unmarshal([]byte(tc.expectedOutput), &expectedOutput), gc.ShouldBeNil)
+					u.So(t, output, gc.ShouldResemble, expectedOutput)
+				})
+			}
+
+			t.Run("adding a secret succeeds even if its id cannot be looked up", func(t *testing.T) {
+				mockUI := cli.NewMockUi()
+				cmd, err := NewSecretsAddCommandFactory(mockUI)()
+				u.So(t, err, gc.ShouldBeNil)
+
+				var added bool
+				addCommand := cmd.(*SecretsAddCommand)
+				setup(addCommand.SecretsBaseCommand, &mockClientFunctions{
+					listSecretsFn: func(groupID, appID string) ([]secrets.Secret, error) {
+						return nil, errors.New("something went wrong")
+					},
+					addSecretFn: func(groupID, appID string, secret secrets.Secret) error {
+						added = true
+						return nil
+					},
+				})
+
+				exitCode := addCommand.Run(append([]string{"-o", "json"}, validAddArgs...))
+				u.So(t, exitCode, gc.ShouldEqual, 0)
+				u.So(t, added, gc.ShouldBeTrue)
+				u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, "New secret created: foo, but failed to look up its ID: something went wrong")
+
+				var output map[string]interface{}
+				u.So(t, json.Unmarshal(mockUI.OutputWriter.Bytes(), &output), gc.ShouldBeNil)
+				u.So(t, output, gc.ShouldResemble, map[string]interface{}{"name": "foo"})
+			})
+
+			t.Run("updating a secret fails before updating it if the secret does not exist", func(t *testing.T) {
+				mockUI := cli.NewMockUi()
+				cmd, err := NewSecretsUpdateCommandFactory(mockUI)()
+				u.So(t, err, gc.ShouldBeNil)
+
+				var updated bool
+				updateCommand := cmd.(*SecretsUpdateCommand)
+				setup(updateCommand.SecretsBaseCommand, &mockClientFunctions{
+					listSecretsFn: listSecrets,
+					updateSecretByNameFn: func(groupID, appID, secretName, secretValue string) error {
+						updated = true
+						return nil
+					},
+				})
+
+				exitCode := updateCommand.Run([]string{"--app-id=my-app-abcdef", "--name=missing", "--value=newvalue", "-o", "json"})
+				u.So(t, exitCode, gc.ShouldEqual, 1)
+				u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, "secret not found: missing")
+				u.So(t, updated, gc.ShouldBeFalse)
+			})
+
+			t.Run("removing a secret fails if the secret does not exist", func(t *testing.T) {
+				mockUI := cli.NewMockUi()
+				cmd, err := NewSecretsRemoveCommandFactory(mockUI)()
+				u.So(t, err, gc.ShouldBeNil)
+
+				var removed bool
+				removeCommand := cmd.(*SecretsRemoveCommand)
+				setup(removeCommand.SecretsBaseCommand, &mockClientFunctions{
+					listSecretsFn: listSecrets,
+					removeSecretByNameFn: func(groupID, appID, secretName string) error {
+						removed = true
+						return nil
+					},
+				})
+
+				exitCode := removeCommand.Run([]string{"--app-id=my-app-abcdef", "--name=missing", "-o", "json"})
+				u.So(t, exitCode, gc.ShouldEqual, 1)
+				u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, "secret not found: missing")
+				u.So(t, removed, gc.ShouldBeFalse)
+			})
+
+			t.Run("fails with an unknown output format", func(t *testing.T) {
+				mockUI := cli.NewMockUi()
+				cmd, err := NewSecretsListCommandFactory(mockUI)()
+				u.So(t, err, gc.ShouldBeNil)
+
+				listCommand := cmd.(*SecretsListCommand)
+				setup(listCommand.SecretsBaseCommand, nil)
+
+				exitCode := listCommand.Run(append([]string{"--output=yaml"}, validListArgs...))
+				u.So(t, exitCode, gc.ShouldEqual, 1)
+				u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, `unknown output format "yaml"`)
+			})
+		})
 	})
 }
@@ -0,0 +1,587 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/10gen/realm-cli/api"
+	"github.com/10gen/realm-cli/models"
+	"github.com/10gen/realm-cli/utils"
+
+	"github.com/mitchellh/cli"
+)
+
+const (
+	triggersFlagName   = "name"
+	triggersFlagOutput = "output"
+	triggersOutputText = "text"
+	triggersOutputJSON = "json"
+)
+
+var (
+	errTriggersAppIDRequired = fmt.Errorf("an App ID (--%s=[string]) must be supplied to manage triggers", flagAppIDName)
+	errTriggerNameRequired   = fmt.Errorf("a trigger name (--%s=[string]) must be supplied", triggersFlagName)
+)
+
+// NewTriggersCommandFactory returns a new cli.CommandFactory given a cli.Ui
+func NewTriggersCommandFactory(ui cli.Ui) cli.CommandFactory {
+	return func() (cli.Command, error) {
+		return &TriggersCommand{
+			BaseCommand: &BaseCommand{
+				Name: "triggers",
+				UI:   ui,
+			},
+		}, nil
+	}
+}
+
+// TriggersCommand is used to manage the triggers of a Realm App separately from the rest of the app
+type TriggersCommand struct {
+	*BaseCommand
+}
+
+// Synopsis returns a one-liner description for this command
+func (tc *TriggersCommand) Synopsis() string {
+	return "List, enable, disable, export or import the triggers of your Realm App."
+}
+
+// Help returns long-form help information for this command
+func (tc *TriggersCommand) Help() string {
+	return tc.Synopsis()
+}
+
+// Run executes the command
+func (tc *TriggersCommand) Run(args []string) int {
+	return cli.RunResultHelp
+}
+
+// TriggersBaseCommand holds what the triggers commands share: resolving the local app and the deployed app it belongs to
+type TriggersBaseCommand struct {
+	*BaseCommand
+
+	workingDirectory string
+
+	flagAppID     string
+	flagAppPath   string
+	flagProjectID string
+}
+
+func newTriggersBaseCommand(name string, ui cli.Ui) (*TriggersBaseCommand, error) {
+	workingDirectory, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+
+	return &TriggersBaseCommand{
+		BaseCommand: &BaseCommand{
+			Name: name,
+			UI:   ui,
+		},
+		workingDirectory: workingDirectory,
+	}, nil
+}
+
+// Help returns long-form help information for the options shared by the triggers commands
+func (tbc *TriggersBaseCommand) Help() string {
+	return `
+OPTIONS:
+  --path [string]
+	A path to the local directory containing your app.
+
+  --app-id [string]
+	The App ID for your app (i.e. the name of your app followed by a unique suffix, like "my-app-nysja").
+	Required if the local app does not specify it.
+
+  --project-id [string]
+	The Atlas Project ID.
+	` +
+		tbc.BaseCommand.Help()
+}
+
+func (tbc *TriggersBaseCommand) run(args []string) error {
+	if tbc.FlagSet == nil {
+		tbc.NewFlagSet()
+	}
+
+	tbc.FlagSet.StringVar(&tbc.flagAppID, flagAppIDName, "", "")
+	tbc.FlagSet.StringVar(&tbc.flagAppPath, importFlagPath, "", "")
+	tbc.FlagSet.StringVar(&tbc.flagProjectID, flagProjectIDName, "", "")
+
+	return tbc.BaseCommand.run(args)
+}
+
+// resolveApp returns the path of the local app along with the deployed app it belongs to
+func (tbc *TriggersBaseCommand) resolveApp() (string, *models.App, api.RealmClient, error) {
+	return tbc.BaseCommand.resolveApp(tbc.appTarget(true))
+}
+
+// resolveDeployedApp returns the deployed app given by --app-id, or else the one the local app belongs to
+func (tbc *TriggersBaseCommand) resolveDeployedApp() (*models.App, api.RealmClient, error) {
+	_, app, realmClient, err := tbc.BaseCommand.resolveApp(tbc.appTarget(false))
+	return app, realmClient, err
+}
+
+func (tbc *TriggersBaseCommand) appTarget(localApp bool) appTarget {
+	return appTarget{
+		appID:            tbc.flagAppID,
+		appPath:          tbc.flagAppPath,
+		workingDirectory: tbc.workingDirectory,
+		projectID:        tbc.flagProjectID,
+		localApp:         localApp,
+		errAppIDRequired: errTriggersAppIDRequired,
+	}
+}
+
+// NewTriggersExportCommandFactory returns a new cli.CommandFactory given a cli.Ui
+func NewTriggersExportCommandFactory(ui cli.Ui) cli.CommandFactory {
+	return func() (cli.Command, error) {
+		baseCommand, err := newTriggersBaseCommand("export", ui)
+		if err != nil {
+			return nil, err
+		}
+
+		return &TriggersExportCommand{baseCommand}, nil
+	}
+}
+
+// TriggersExportCommand is used to write the deployed triggers of a Realm App into the local app
+type TriggersExportCommand struct {
+	*TriggersBaseCommand
+}
+
+// Synopsis returns a one-liner description for this command
+func (tec *TriggersExportCommand) Synopsis() string {
+	return "Export the triggers of your Realm App."
+}
+
+// Help returns long-form help information for this command
+func (tec *TriggersExportCommand) Help() string {
+	return `Export the triggers of your deployed Realm Application into the "/triggers" directory of the local app,
+without exporting the rest of the app configuration. Existing files of exported triggers are overwritten.
+
+Usage: realm-cli triggers export [options]
+` +
+		tec.TriggersBaseCommand.Help()
+}
+
+// Run executes the command
+func (tec *TriggersExportCommand) Run(args []string) int {
+	if err := tec.TriggersBaseCommand.run(args); err != nil {
+		tec.UI.Error(err.Error())
+		return 1
+	}
+
+	if err := tec.export(); err != nil {
+		tec.UI.Error(err.Error())
+		return 1
+	}
+
+	return 0
+}
+
+func (tec *TriggersExportCommand) export() error {
+	appPath, app, realmClient, err := tec.resolveApp()
+	if err != nil {
+		return err
+	}
+
+	triggers, err := realmClient.ListTriggers(app.GroupID, app.ID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch triggers: %s", err)
+	}
+
+	if err := utils.WriteTriggers(appPath, triggers); err != nil {
+		return fmt.Errorf("failed to write triggers: %s", err)
+	}
+
+	tec.UI.Info(fmt.Sprintf("Exported %d triggers to %s", len(triggers), filepath.Join(appPath, "triggers")))
+	return nil
+}
+
+// NewTriggersImportCommandFactory returns a new cli.CommandFactory given a cli.Ui
+func NewTriggersImportCommandFactory(ui cli.Ui) cli.CommandFactory {
+	return func() (cli.Command, error) {
+		baseCommand, err := newTriggersBaseCommand("import", ui)
+		if err != nil {
+			return nil, err
+		}
+
+		return &TriggersImportCommand{baseCommand}, nil
+	}
+}
+
+// TriggersImportCommand is used to create or update the triggers of a Realm App from the local app
+type TriggersImportCommand struct {
+	*TriggersBaseCommand
+}
+
+// Synopsis returns a one-liner description for this command
+func (tic *TriggersImportCommand) Synopsis() string {
+	return "Import the triggers of your Realm App."
+}
+
+// Help returns long-form help information for this command
+func (tic *TriggersImportCommand) Help() string {
+	return `Import the triggers from the "/triggers" directory of the local app into your deployed Realm Application,
+without importing the rest of the app configuration. Triggers are matched by name; deployed triggers
+missing from the local app are left alone. The function each trigger calls must already be deployed.
+
+Usage: realm-cli triggers import [options]
+` +
+		tic.TriggersBaseCommand.Help()
+}
+
+// Run executes the command
+func (tic *TriggersImportCommand) Run(args []string) int {
+	if err := tic.TriggersBaseCommand.run(args); err != nil {
+		tic.UI.Error(err.Error())
+		return 1
+	}
+
+	if err := tic.importTriggers(); err != nil {
+		tic.UI.Error(err.Error())
+		return 1
+	}
+
+	return 0
+}
+
+func (tic *TriggersImportCommand) importTriggers() error {
+	appPath, app, realmClient, err := tic.resolveApp()
+	if err != nil {
+		return err
+	}
+
+	localTriggers, err := utils.LoadTriggers(appPath)
+	if err != nil {
+		return err
+	}
+
+	functions, err := realmClient.ListFunctions(app.GroupID, app.ID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch functions: %s", err)
+	}
+
+	if err := utils.ValidateTriggerFunctions(localTriggers, functions); err != nil {
+		return err
+	}
+
+	remoteTriggers, err := realmClient.ListTriggers(app.GroupID, app.ID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch triggers: %s", err)
+	}
+
+	triggerDiffs := utils.DiffTriggers(localTriggers, remoteTriggers)
+
+	diffs := triggerDiffs.Diff()
+	if len(diffs) == 0 {
+		tic.UI.Info("Deployed triggers are identical to the local ones, nothing to do.")
+		return nil
+	}
+
+	for _, diff := range diffs {
+		tic.UI.Info(diff)
+	}
+
+	confirm, err := tic.AskYesNo("Please confirm the changes shown above:")
+	if err != nil {
+		return err
+	}
+	if !confirm {
+		return nil
+	}
+
+	functionIDs := make(map[string]string, len(functions))
+	for _, function := range functions {
+		functionIDs[function.Name] = function.ID
+	}
+
+	withFunctionID := func(trigger models.Trigger) models.Trigger {
+		payload := make(models.Trigger, len(trigger)+1)
+		for field, value := range trigger {
+			payload[field] = value
+		}
+		if functionName := trigger.FunctionName(); functionName != "" {
+			payload[models.TriggerFunctionIDField] = functionIDs[functionName]
+		}
+		return payload
+	}
+
+	for _, added := range triggerDiffs.AddedLocally {
+		if err := realmClient.CreateTrigger(app.GroupID, app.ID, withFunctionID(added)); err != nil {
+			return fmt.Errorf("failed to create trigger %s: %s", added.Name(), err)
+		}
+	}
+
+	for _, modified := range triggerDiffs.ModifiedLocally {
+		payload := withFunctionID(modified.Local)
+		payload[models.TriggerIDField] = modified.Remote.ID()
+		if err := realmClient.UpdateTrigger(app.GroupID, app.ID, modified.Remote.ID(), payload); err != nil {
+			return fmt.Errorf("failed to update trigger %s: %s", modified.Local.Name(), err)
+		}
+	}
+
+	tic.UI.Info(fmt.Sprintf("Successfully imported %d triggers", len(triggerDiffs.AddedLocally)+len(triggerDiffs.ModifiedLocally)))
+	return nil
+}
+
+// NewTriggersListCommandFactory returns a new cli.CommandFactory given a cli.Ui
+func NewTriggersListCommandFactory(ui cli.Ui) cli.CommandFactory {
+	return func() (cli.Command, error) {
+		baseCommand, err := newTriggersBaseCommand("list", ui)
+		if err != nil {
+			return nil, err
+		}
+
+		return &TriggersListCommand{TriggersBaseCommand: baseCommand}, nil
+	}
+}
+
+// TriggersListCommand is used to list the deployed triggers of a Realm App
+type TriggersListCommand struct {
+	*TriggersBaseCommand
+
+	flagOutput string
+}
+
+// triggerSummary is a trigger as it is listed
+type triggerSummary struct {
+	Name         string `json:"name"`
+	Type         string `json:"type"`
+	FunctionName string `json:"function_name,omitempty"`
+	Disabled     bool   `json:"disabled"`
+}
+
+// Synopsis returns a one-liner description for this command
+func (tlc *TriggersListCommand) Synopsis() string {
+	return "List the triggers of your Realm App."
+}
+
+// Help returns long-form help information for this command
+func (tlc *TriggersListCommand) Help() string {
+	return `List the name, type and function of each trigger deployed to your Realm Application, along with
+whether it is enabled.
+
+Usage: realm-cli triggers list [options]
+
+OPTIONS:
+  -o [text|json], --output [text|json] (default: text)
+	How the triggers should be printed.
+	json - print the triggers as a JSON array, e.g. for scripts to read.
+
+` +
+		strings.TrimPrefix(tlc.TriggersBaseCommand.Help(), "\nOPTIONS:\n")
+}
+
+// Run executes the command
+func (tlc *TriggersListCommand) Run(args []string) int {
+	flags := tlc.NewFlagSet()
+
+	flags.StringVar(&tlc.flagOutput, triggersFlagOutput, triggersOutputText, "")
+	flags.StringVar(&tlc.flagOutput, "o", triggersOutputText, "")
+
+	if err := tlc.TriggersBaseCommand.run(args); err != nil {
+		tlc.UI.Error(err.Error())
+		return 1
+	}
+
+	if err := tlc.list(); err != nil {
+		tlc.UI.Error(err.Error())
+		return 1
+	}
+
+	return 0
+}
+
+func (tlc *TriggersListCommand) list() error {
+	switch tlc.flagOutput {
+	case triggersOutputText, triggersOutputJSON:
+	default:
+		return fmt.Errorf("unknown output format %q; accepted values are [%s|%s]", tlc.flagOutput, triggersOutputText, triggersOutputJSON)
+	}
+
+	app, realmClient, err := tlc.resolveDeployedApp()
+	if err != nil {
+		return err
+	}
+
+	triggers, err := realmClient.ListTriggers(app.GroupID, app.ID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch triggers: %s", err)
+	}
+
+	summaries := make([]triggerSummary, 0, len(triggers))
+	for _, trigger := range triggers {
+		summaries = append(summaries, triggerSummary{
+			Name:         trigger.Name(),
+			Type:         trigger.Type(),
+			FunctionName: trigger.FunctionName(),
+			Disabled:     trigger.Disabled(),
+		})
+	}
+	sort.SliceStable(summaries, func(i, j int) bool { return summaries[i].Name < summaries[j].Name })
+
+	if tlc.flagOutput == triggersOutputJSON {
+		data, err := json.MarshalIndent(summaries, "", "  ")
+		if err != nil {
+			return err
+		}
+		tlc.UI.Output(string(data))
+		return nil
+	}
+
+	if len(summaries) == 0 {
+		tlc.UI.Info(fmt.Sprintf("No triggers found for %s.", app.ClientAppID))
+		return nil
+	}
+
+	tlc.UI.Output(triggersTable(summaries))
+	return nil
+}
+
+// triggersTable formats the triggers as a table
+func triggersTable(summaries []triggerSummary) string {
+	var table strings.Builder
+	w := tabwriter.NewWriter(&table, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tTYPE\tFUNCTION\tSTATUS")
+	for _, summary := range summaries {
+		status := "enabled"
+		if summary.Disabled {
+			status = "disabled"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", summary.Name, valueOrDash(summary.Type), valueOrDash(summary.FunctionName), status)
+	}
+	w.Flush()
+
+	return strings.TrimSuffix(table.String(), "\n")
+}
+
+// NewTriggersEnableCommandFactory returns a new cli.CommandFactory given a cli.Ui
+func NewTriggersEnableCommandFactory(ui cli.Ui) cli.CommandFactory {
+	return newTriggersSetDisabledCommandFactory("enable", false, ui)
+}
+
+// NewTriggersDisableCommandFactory returns a new cli.CommandFactory given a cli.Ui
+func NewTriggersDisableCommandFactory(ui cli.Ui) cli.CommandFactory {
+	return newTriggersSetDisabledCommandFactory("disable", true, ui)
+}
+
+func newTriggersSetDisabledCommandFactory(name string, disable bool, ui cli.Ui) cli.CommandFactory {
+	return func() (cli.Command, error) {
+		baseCommand, err := newTriggersBaseCommand(name, ui)
+		if err != nil {
+			return nil, err
+		}
+
+		return &TriggersSetDisabledCommand{TriggersBaseCommand: baseCommand, disable: disable}, nil
+	}
+}
+
+// TriggersSetDisabledCommand is used to disable or enable a single deployed trigger of a Realm App
+// without importing the app
+type TriggersSetDisabledCommand struct {
+	*TriggersBaseCommand
+
+	// disable is true for "triggers disable" and false for "triggers enable"
+	disable bool
+
+	flagName string
+}
+
+// Synopsis returns a one-liner description for this command
+func (tsc *TriggersSetDisabledCommand) Synopsis() string {
+	if tsc.disable {
+		return "Disable a trigger of your Realm App."
+	}
+	return "Enable a trigger of your Realm App."
+}
+
+// Help returns long-form help information for this command
+func (tsc *TriggersSetDisabledCommand) Help() string {
+	description := `Disable a trigger deployed to your Realm Application right away, without importing the app, e.g. to
+pause a noisy scheduled trigger during an incident. The local app is left alone, so importing it
+again gives the trigger back the "disabled" value of its local config.`
+	if !tsc.disable {
+		description = `Enable a trigger deployed to your Realm Application right away, without importing the app, e.g. to
+resume a trigger disabled with "realm-cli triggers disable". The local app is left alone, so
+importing it again gives the trigger back the "disabled" value of its local config.`
+	}
+
+	return description + fmt.Sprintf(`
+
+Usage: realm-cli triggers %s --name [string] [options]
+
+REQUIRED:
+  --name [string]
+	The name of the trigger.
+`, tsc.Name) +
+		tsc.TriggersBaseCommand.Help()
+}
+
+// Run executes the command
+func (tsc *TriggersSetDisabledCommand) Run(args []string) int {
+	flags := tsc.NewFlagSet()
+
+	flags.StringVar(&tsc.flagName, triggersFlagName, "", "")
+
+	if err := tsc.TriggersBaseCommand.run(args); err != nil {
+		tsc.UI.Error(err.Error())
+		return 1
+	}
+
+	if err := tsc.setDisabled(); err != nil {
+		tsc.UI.Error(err.Error())
+		return 1
+	}
+
+	return 0
+}
+
+func (tsc *TriggersSetDisabledCommand) setDisabled() error {
+	if tsc.flagName == "" {
+		return errTriggerNameRequired
+	}
+
+	app, realmClient, err := tsc.resolveDeployedApp()
+	if err != nil {
+		return err
+	}
+
+	triggers, err := realmClient.ListTriggers(app.GroupID, app.ID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch triggers: %s", err)
+	}
+
+	var trigger models.Trigger
+	for _, t := range triggers {
+		if t.Name() == tsc.flagName {
+			trigger = t
+			break
+		}
+	}
+	if trigger == nil {
+		return fmt.Errorf("trigger %q not found in %s", tsc.flagName, app.ClientAppID)
+	}
+
+	state := "enabled"
+	if tsc.disable {
+		state = "disabled"
+	}
+
+	if trigger.Disabled() == tsc.disable {
+		tsc.UI.Info(fmt.Sprintf("Trigger %q is already %s.", tsc.flagName, state))
+		return nil
+	}
+
+	if err := realmClient.SetTriggerDisabled(app.GroupID, app.ID, trigger.ID(), tsc.disable); err != nil {
+		return fmt.Errorf("failed to %s trigger %q: %s", tsc.Name, tsc.flagName, err)
+	}
+
+	tsc.UI.Info(fmt.Sprintf("Trigger %q is now %s.", tsc.flagName, state))
+	return nil
+}
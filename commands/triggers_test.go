@@ -0,0 +1,312 @@
+package commands
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/10gen/realm-cli/models"
+	"github.com/10gen/realm-cli/user"
+	u "github.com/10gen/realm-cli/utils/test"
+	gc "github.com/smartystreets/goconvey/convey"
+
+	"github.com/mitchellh/cli"
+)
+
+func TestTriggersCommands(t *testing.T) {
+	setUpApp := func(t *testing.T) (string, func()) {
+		tmpDir, err := ioutil.TempDir("", "realm-triggers-")
+		u.So(t, err, gc.ShouldBeNil)
+
+		appPath := filepath.Join(tmpDir, "full_app")
+		u.So(t, copyDirectory("../testdata/full_app", appPath), gc.ShouldBeNil)
+		return appPath, func() { os.RemoveAll(tmpDir) }
+	}
+
+	remoteTriggers := func() []models.Trigger {
+		return []models.Trigger{
+			{"_id": "trigger-1", "name": "authEventSubscription", "type": "AUTHENTICATION", "config": map[string]interface{}{"action_type": "LOGIN"}, "function_id": "function-a-id", "function_name": "function_a", "disabled": false},
+			{"_id": "trigger-2", "name": "nightly", "type": "SCHEDULED", "config": map[string]interface{}{"schedule": "0 3 * * *"}, "function_id": "function-b-id", "function_name": "function_b", "disabled": false},
+		}
+	}
+
+	newRealmClient := func() *u.MockRealmClient {
+		return &u.MockRealmClient{
+			FetchAppByClientAppIDFn: func(clientAppID string) (*models.App, error) {
+				return &models.App{GroupID: "group-id", ID: "app-id", ClientAppID: clientAppID}, nil
+			},
+			ListTriggersFn: func(groupID, appID string) ([]models.Trigger, error) {
+				return remoteTriggers(), nil
+			},
+			ListFunctionsFn: func(groupID, appID string) ([]models.Function, error) {
+				return []models.Function{{ID: "function-a-id", Name: "function_a"}, {ID: "function-b-id", Name: "function_b"}}, nil
+			},
+		}
+	}
+
+	loggedInUser := func() *user.User {
+		return &user.User{APIKey: "my-api-key", AccessToken: u.GenerateValidAccessToken()}
+	}
+
+	t.Run("triggers export", func(t *testing.T) {
+		setup := func() (*TriggersExportCommand, *cli.MockUi) {
+			mockUI := cli.NewMockUi()
+			cmd, err := NewTriggersExportCommandFactory(mockUI)()
+			if err != nil {
+				panic(err)
+			}
+
+			exportCommand := cmd.(*TriggersExportCommand)
+			exportCommand.storage = u.NewEmptyStorage()
+			return exportCommand, mockUI
+		}
+
+		t.Run("should require the user to be logged in", func(t *testing.T) {
+			exportCommand, mockUI := setup()
+			exitCode := exportCommand.Run([]string{"--app-id=full-app-abcde", "--path=../testdata/full_app"})
+			u.So(t, exitCode, gc.ShouldEqual, 1)
+			u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, user.ErrNotLoggedIn.Error())
+		})
+
+		t.Run("writes the deployed triggers into the local app", func(t *testing.T) {
+			appPath, cleanup := setUpApp(t)
+			defer cleanup()
+
+			exportCommand, mockUI := setup()
+			exportCommand.user = loggedInUser()
+			exportCommand.realmClient = newRealmClient()
+
+			exitCode := exportCommand.Run([]string{"--app-id=full-app-abcde", "--path=" + appPath})
+			u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+			u.So(t, exitCode, gc.ShouldEqual, 0)
+			u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, "Exported 2 triggers to "+filepath.Join(appPath, "triggers"))
+
+			data, err := ioutil.ReadFile(filepath.Join(appPath, "triggers", "nightly.json"))
+			u.So(t, err, gc.ShouldBeNil)
+			u.So(t, string(data), gc.ShouldContainSubstring, `"function_name": "function_b"`)
+			u.So(t, string(data), gc.ShouldNotContainSubstring, "_id")
+
+			_, err = os.Stat(filepath.Join(appPath, "triggers", "dbEventSubscription.json"))
+			u.So(t, err, gc.ShouldBeNil)
+		})
+	})
+
+	t.Run("triggers import", func(t *testing.T) {
+		setup := func() (*TriggersImportCommand, *cli.MockUi) {
+			mockUI := cli.NewMockUi()
+			cmd, err := NewTriggersImportCommandFactory(mockUI)()
+			if err != nil {
+				panic(err)
+			}
+
+			importCommand := cmd.(*TriggersImportCommand)
+			importCommand.storage = u.NewEmptyStorage()
+			importCommand.user = loggedInUser()
+			return importCommand, mockUI
+		}
+
+		t.Run("creates and updates the triggers that differ by name", func(t *testing.T) {
+			appPath, cleanup := setUpApp(t)
+			defer cleanup()
+			u.So(t, ioutil.WriteFile(
+				filepath.Join(appPath, "triggers", "authEventSubscription.json"),
+				[]byte(`{"name": "authEventSubscription", "type": "AUTHENTICATION", "config": {"action_type": "CREATE"}, "function_name": "function_b", "disabled": false}`),
+				0600,
+			), gc.ShouldBeNil)
+
+			importCommand, mockUI := setup()
+
+			var created, updated []models.Trigger
+			var updatedID string
+			realmClient := newRealmClient()
+			realmClient.CreateTriggerFn = func(groupID, appID string, trigger models.Trigger) error {
+				created = append(created, trigger)
+				return nil
+			}
+			realmClient.UpdateTriggerFn = func(groupID, appID, triggerID string, trigger models.Trigger) error {
+				updatedID = triggerID
+				updated = append(updated, trigger)
+				return nil
+			}
+			importCommand.realmClient = realmClient
+
+			exitCode := importCommand.Run([]string{"-y", "--app-id=full-app-abcde", "--path=" + appPath})
+			u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+			u.So(t, exitCode, gc.ShouldEqual, 0)
+
+			output := mockUI.OutputWriter.String()
+			u.So(t, output, gc.ShouldContainSubstring, "New Triggers:\n\t+ dbEventSubscription\n")
+			u.So(t, output, gc.ShouldContainSubstring, strings.Join([]string{
+				"Modified Triggers:",
+				"\t* authEventSubscription",
+				`		- $.config.action_type: "LOGIN"`,
+				`		+ $.config.action_type: "CREATE"`,
+				`		- $.function_name: "function_a"`,
+				`		+ $.function_name: "function_b"`,
+			}, "\n"))
+			u.So(t, output, gc.ShouldContainSubstring, "Successfully imported 2 triggers")
+
+			u.So(t, created, gc.ShouldHaveLength, 1)
+			u.So(t, created[0].Name(), gc.ShouldEqual, "dbEventSubscription")
+			u.So(t, created[0]["function_id"], gc.ShouldEqual, "function-a-id")
+
+			u.So(t, updatedID, gc.ShouldEqual, "trigger-1")
+			u.So(t, updated, gc.ShouldHaveLength, 1)
+			u.So(t, updated[0]["function_id"], gc.ShouldEqual, "function-b-id")
+		})
+
+		t.Run("does nothing if the triggers are identical", func(t *testing.T) {
+			appPath, cleanup := setUpApp(t)
+			defer cleanup()
+			u.So(t, os.Remove(filepath.Join(appPath, "triggers", "dbEventSubscription.json")), gc.ShouldBeNil)
+
+			importCommand, mockUI := setup()
+			importCommand.realmClient = newRealmClient()
+
+			exitCode := importCommand.Run([]string{"-y", "--app-id=full-app-abcde", "--path=" + appPath})
+			u.So(t, exitCode, gc.ShouldEqual, 0)
+			u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, "Deployed triggers are identical to the local ones, nothing to do.")
+		})
+
+		t.Run("fails before importing anything if a trigger references a missing function", func(t *testing.T) {
+			appPath, cleanup := setUpApp(t)
+			defer cleanup()
+
+			importCommand, mockUI := setup()
+			realmClient := newRealmClient()
+			realmClient.ListFunctionsFn = func(groupID, appID string) ([]models.Function, error) {
+				return []models.Function{{ID: "function-b-id", Name: "function_b"}}, nil
+			}
+			realmClient.CreateTriggerFn = func(groupID, appID string, trigger models.Trigger) error {
+				t.Errorf("unexpected creation of trigger %s", trigger.Name())
+				return nil
+			}
+			importCommand.realmClient = realmClient
+
+			exitCode := importCommand.Run([]string{"-y", "--app-id=full-app-abcde", "--path=" + appPath})
+			u.So(t, exitCode, gc.ShouldEqual, 1)
+			u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, `dbEventSubscription references function "function_a", which does not exist in the app`)
+		})
+	})
+
+	t.Run("triggers list", func(t *testing.T) {
+		setup := func() (*TriggersListCommand, *cli.MockUi) {
+			mockUI := cli.NewMockUi()
+			cmd, err := NewTriggersListCommandFactory(mockUI)()
+			if err != nil {
+				panic(err)
+			}
+
+			listCommand := cmd.(*TriggersListCommand)
+			listCommand.storage = u.NewEmptyStorage()
+			listCommand.user = loggedInUser()
+			listCommand.realmClient = newRealmClient()
+			return listCommand, mockUI
+		}
+
+		t.Run("lists the deployed triggers without a local app", func(t *testing.T) {
+			listCommand, mockUI := setup()
+			realmClient := listCommand.realmClient.(*u.MockRealmClient)
+			realmClient.ListTriggersFn = func(groupID, appID string) ([]models.Trigger, error) {
+				triggers := remoteTriggers()
+				triggers[1]["disabled"] = true
+				return triggers, nil
+			}
+
+			exitCode := listCommand.Run([]string{"--app-id=full-app-abcde", "--path=../testdata/missing_app"})
+			u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+			u.So(t, exitCode, gc.ShouldEqual, 0)
+			u.So(t, mockUI.OutputWriter.String(), gc.ShouldEqual, strings.Join([]string{
+				"NAME                   TYPE            FUNCTION    STATUS",
+				"authEventSubscription  AUTHENTICATION  function_a  enabled",
+				"nightly                SCHEDULED       function_b  disabled",
+				"",
+			}, "\n"))
+		})
+
+		t.Run("prints the triggers as JSON", func(t *testing.T) {
+			listCommand, mockUI := setup()
+
+			exitCode := listCommand.Run([]string{"--app-id=full-app-abcde", "-o", "json"})
+			u.So(t, exitCode, gc.ShouldEqual, 0)
+
+			var summaries []triggerSummary
+			u.So(t, json.Unmarshal(mockUI.OutputWriter.Bytes(), &summaries), gc.ShouldBeNil)
+			u.So(t, summaries, gc.ShouldResemble, []triggerSummary{
+				{Name: "authEventSubscription", Type: "AUTHENTICATION", FunctionName: "function_a"},
+				{Name: "nightly", Type: "SCHEDULED", FunctionName: "function_b"},
+			})
+		})
+
+		t.Run("fails with an unknown output format", func(t *testing.T) {
+			listCommand, mockUI := setup()
+
+			exitCode := listCommand.Run([]string{"--app-id=full-app-abcde", "--output=yaml"})
+			u.So(t, exitCode, gc.ShouldEqual, 1)
+			u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, `unknown output format "yaml"`)
+		})
+	})
+
+	t.Run("triggers disable and enable", func(t *testing.T) {
+		setup := func(factory func(cli.Ui) cli.CommandFactory, setDisabled *[]interface{}) (*TriggersSetDisabledCommand, *cli.MockUi) {
+			mockUI := cli.NewMockUi()
+			cmd, err := factory(mockUI)()
+			if err != nil {
+				panic(err)
+			}
+
+			setDisabledCommand := cmd.(*TriggersSetDisabledCommand)
+			setDisabledCommand.storage = u.NewEmptyStorage()
+			setDisabledCommand.user = loggedInUser()
+			realmClient := newRealmClient()
+			realmClient.SetTriggerDisabledFn = func(groupID, appID, triggerID string, disabled bool) error {
+				*setDisabled = append(*setDisabled, triggerID, disabled)
+				return nil
+			}
+			setDisabledCommand.realmClient = realmClient
+			return setDisabledCommand, mockUI
+		}
+
+		t.Run("disables the trigger with the given name", func(t *testing.T) {
+			var setDisabled []interface{}
+			disableCommand, mockUI := setup(NewTriggersDisableCommandFactory, &setDisabled)
+
+			exitCode := disableCommand.Run([]string{"--app-id=full-app-abcde", "--name=nightly"})
+			u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+			u.So(t, exitCode, gc.ShouldEqual, 0)
+			u.So(t, setDisabled, gc.ShouldResemble, []interface{}{"trigger-2", true})
+			u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, `Trigger "nightly" is now disabled.`)
+		})
+
+		t.Run("leaves a trigger which is already enabled alone", func(t *testing.T) {
+			var setDisabled []interface{}
+			enableCommand, mockUI := setup(NewTriggersEnableCommandFactory, &setDisabled)
+
+			exitCode := enableCommand.Run([]string{"--app-id=full-app-abcde", "--name=nightly"})
+			u.So(t, exitCode, gc.ShouldEqual, 0)
+			u.So(t, setDisabled, gc.ShouldBeEmpty)
+			u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, `Trigger "nightly" is already enabled.`)
+		})
+
+		t.Run("requires the name of the trigger", func(t *testing.T) {
+			var setDisabled []interface{}
+			disableCommand, mockUI := setup(NewTriggersDisableCommandFactory, &setDisabled)
+
+			exitCode := disableCommand.Run([]string{"--app-id=full-app-abcde"})
+			u.So(t, exitCode, gc.ShouldEqual, 1)
+			u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, "a trigger name (--name=[string]) must be supplied")
+		})
+
+		t.Run("fails for a trigger the app does not have", func(t *testing.T) {
+			var setDisabled []interface{}
+			disableCommand, mockUI := setup(NewTriggersDisableCommandFactory, &setDisabled)
+
+			exitCode := disableCommand.Run([]string{"--app-id=full-app-abcde", "--name=yell"})
+			u.So(t, exitCode, gc.ShouldEqual, 1)
+			u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, `trigger "yell" not found in full-app-abcde`)
+		})
+	})
+}
@@ -0,0 +1,127 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/10gen/realm-cli/api"
+	"github.com/10gen/realm-cli/utils"
+
+	"github.com/mitchellh/cli"
+)
+
+// NewValidateCommandFactory returns a new cli.CommandFactory given a cli.Ui
+func NewValidateCommandFactory(ui cli.Ui) cli.CommandFactory {
+	return func() (cli.Command, error) {
+		workingDirectory, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+
+		return &ValidateCommand{
+			BaseCommand: &BaseCommand{
+				Name: "app validate",
+				UI:   ui,
+			},
+			workingDirectory: workingDirectory,
+		}, nil
+	}
+}
+
+// ValidateCommand is used to check the configuration of a local Realm App before importing it
+type ValidateCommand struct {
+	*BaseCommand
+
+	workingDirectory string
+
+	flagAppPath string
+	flagStrict  bool
+}
+
+// Synopsis returns a one-liner description for this command
+func (vc *ValidateCommand) Synopsis() string {
+	return "Check the configuration of your local Realm App without importing it."
+}
+
+// Help returns long-form help information for this command
+func (vc *ValidateCommand) Help() string {
+	return `Check the configuration of your local Realm App for mistakes without contacting Realm,
+e.g. malformed JSON, resources missing required fields, triggers calling functions which do not exist,
+or a config version which realm-cli does not import. Every problem is listed along with the file
+and field it was found in. Does not require logging in.
+
+Usage: realm-cli app validate [options]
+
+OPTIONS:
+  --path [string]
+	A path to the local directory containing your app.
+
+  --strict
+	Also report the keys of functions, incoming webhooks, services and triggers which realm-cli
+	does not recognize, as "realm-cli import --strict" would.
+	` +
+		vc.BaseCommand.Help()
+}
+
+// Run executes the command
+func (vc *ValidateCommand) Run(args []string) int {
+	flags := vc.NewFlagSet()
+
+	flags.StringVar(&vc.flagAppPath, importFlagPath, "", "")
+	flags.BoolVar(&vc.flagStrict, importFlagStrict, false, "")
+
+	if err := vc.BaseCommand.run(args); err != nil {
+		vc.UI.Error(err.Error())
+		return 1
+	}
+
+	if err := vc.validate(); err != nil {
+		vc.UI.Error(err.Error())
+		return 1
+	}
+
+	return 0
+}
+
+func (vc *ValidateCommand) validate() error {
+	appPath, err := utils.ResolveAppDirectory(vc.flagAppPath, vc.workingDirectory)
+	if err != nil {
+		return err
+	}
+
+	problems, err := utils.ValidateAppDir(appPath, api.ConfigVersion)
+	if err != nil {
+		return err
+	}
+
+	if vc.flagStrict {
+		unknownKeys, err := utils.FindUnknownKeys(appPath)
+		if err != nil {
+			return err
+		}
+		for _, problem := range unknownKeys {
+			// problems with whole files, such as malformed JSON, are already reported
+			if problem.Field != "" {
+				problems = append(problems, problem)
+			}
+		}
+		sort.SliceStable(problems, func(i, j int) bool {
+			return problems[i].Path < problems[j].Path
+		})
+	}
+
+	if len(problems) == 0 {
+		vc.UI.Info(fmt.Sprintf("No problems found in the app at %s", appPath))
+		return nil
+	}
+
+	for _, problem := range problems {
+		vc.UI.Error(problem.String())
+	}
+
+	if len(problems) == 1 {
+		return fmt.Errorf("found 1 problem in the app at %s", appPath)
+	}
+	return fmt.Errorf("found %d problems in the app at %s", len(problems), appPath)
+}
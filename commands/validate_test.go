@@ -0,0 +1,60 @@
+package commands
+
+import (
+	"testing"
+
+	u "github.com/10gen/realm-cli/utils/test"
+	gc "github.com/smartystreets/goconvey/convey"
+
+	"github.com/mitchellh/cli"
+)
+
+func TestValidateCommand(t *testing.T) {
+	setup := func() (*ValidateCommand, *cli.MockUi) {
+		mockUI := cli.NewMockUi()
+		cmd, err := NewValidateCommandFactory(mockUI)()
+		if err != nil {
+			panic(err)
+		}
+
+		validateCommand := cmd.(*ValidateCommand)
+		validateCommand.storage = u.NewEmptyStorage()
+		return validateCommand, mockUI
+	}
+
+	t.Run("should succeed without logging in when the app has no problems", func(t *testing.T) {
+		validateCommand, mockUI := setup()
+		exitCode := validateCommand.Run([]string{"--path=../testdata/full_app"})
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+
+		u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, "No problems found in the app at ../testdata/full_app")
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+	})
+
+	t.Run("should find no unknown keys in the app with --strict", func(t *testing.T) {
+		validateCommand, mockUI := setup()
+		exitCode := validateCommand.Run([]string{"--path=../testdata/full_app", "--strict"})
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldBeEmpty)
+	})
+
+	t.Run("should list every problem with the app", func(t *testing.T) {
+		validateCommand, mockUI := setup()
+		exitCode := validateCommand.Run([]string{"--path=../testdata/app_with_duplicate_names"})
+		u.So(t, exitCode, gc.ShouldEqual, 1)
+
+		errors := mockUI.ErrorWriter.String()
+		u.So(t, errors, gc.ShouldContainSubstring, `functions/function_a/config.json: name: function "function_a" is also defined in functions/function_a_copy/config.json`)
+		u.So(t, errors, gc.ShouldContainSubstring, "triggers/trigger_a.json: function_name: is required")
+		u.So(t, errors, gc.ShouldContainSubstring, "found 6 problems in the app at ../testdata/app_with_duplicate_names")
+	})
+
+	t.Run("should fail outside of an app directory", func(t *testing.T) {
+		validateCommand, mockUI := setup()
+		exitCode := validateCommand.Run([]string{"--path=../testdata/missing_app"})
+		u.So(t, exitCode, gc.ShouldEqual, 1)
+
+		u.So(t, mockUI.ErrorWriter.String(), gc.ShouldNotBeEmpty)
+	})
+}
@@ -3,9 +3,13 @@ package commands
 import (
 	"fmt"
 
+	u "github.com/10gen/realm-cli/user"
+
 	"github.com/mitchellh/cli"
 )
 
+const whoamiFlagVerify = "verify"
+
 // NewWhoamiCommandFactory returns a new cli.CommandFactory given a cli.Ui
 func NewWhoamiCommandFactory(ui cli.Ui) cli.CommandFactory {
 	return func() (cli.Command, error) {
@@ -18,9 +22,12 @@ func NewWhoamiCommandFactory(ui cli.Ui) cli.CommandFactory {
 	}
 }
 
-// WhoamiCommand is used to print the name and API key of the current user
+// WhoamiCommand is used to print the name and API key of the current user, along with the project and
+// Realm API it is working against
 type WhoamiCommand struct {
 	*BaseCommand
+
+	flagVerify bool
 }
 
 // Synopsis returns a one-liner description for this command
@@ -30,13 +37,21 @@ func (whoami *WhoamiCommand) Synopsis() string {
 
 // Help returns long-form help information for this command
 func (whoami *WhoamiCommand) Help() string {
-	return `Print the name and API key associated with the current user.
+	return `Print the name and API key associated with the current user, along with the profile, the Atlas Project
+used when --project-id is not given and the Realm API base URL. Only the stored credentials are read, unless
+--verify is given.
 
-OPTIONS:` + whoami.BaseCommand.Help()
+OPTIONS:
+  --verify
+	Check with the Realm API that the session is still valid.` + whoami.BaseCommand.Help()
 }
 
 // Run executes the command
 func (whoami *WhoamiCommand) Run(args []string) int {
+	flags := whoami.NewFlagSet()
+
+	flags.BoolVar(&whoami.flagVerify, whoamiFlagVerify, false, "")
+
 	if err := whoami.BaseCommand.run(args); err != nil {
 		whoami.UI.Error(err.Error())
 		return 1
@@ -54,5 +69,50 @@ func (whoami *WhoamiCommand) Run(args []string) int {
 	}
 
 	whoami.UI.Info(message)
+
+	if whoami.flagProfile != "" {
+		whoami.UI.Info(fmt.Sprintf("Profile: %s", whoami.flagProfile))
+	}
+	whoami.UI.Info(fmt.Sprintf("Project: %s", whoamiProject(user)))
+	whoami.UI.Info(fmt.Sprintf("Base URL: %s", whoami.flagBaseURL))
+
+	if whoami.flagVerify {
+		if err := whoami.verify(user); err != nil {
+			whoami.UI.Error(err.Error())
+			return 1
+		}
+	}
+
 	return 0
 }
+
+// whoamiProject describes the Atlas Project used when --project-id is not given
+func whoamiProject(user *u.User) string {
+	if project, ok := user.Defaults["project"]; ok {
+		return fmt.Sprintf("%s (set with config set)", project)
+	}
+	if project, ok := user.CachedProjectID(); ok {
+		return fmt.Sprintf("%s (last used)", project)
+	}
+	return "not set"
+}
+
+// verify checks the session of the user is still valid by fetching their profile
+func (whoami *WhoamiCommand) verify(user *u.User) error {
+	if !user.LoggedIn() {
+		return u.ErrNotLoggedIn
+	}
+
+	realmClient, err := whoami.RealmClient()
+	if err != nil {
+		return fmt.Errorf("failed to verify the session: %s", err)
+	}
+
+	profile, err := realmClient.GetUserProfile()
+	if err != nil {
+		return fmt.Errorf("failed to verify the session: %s", err)
+	}
+
+	whoami.UI.Info(fmt.Sprintf("Session: valid, with access to %d project(s)", len(profile.AllGroupIDs())))
+	return nil
+}
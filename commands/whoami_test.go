@@ -1,9 +1,12 @@
 package commands
 
 import (
+	"encoding/json"
+	"errors"
 	"net/http"
 	"testing"
 
+	"github.com/10gen/realm-cli/models"
 	"github.com/10gen/realm-cli/storage"
 	"github.com/10gen/realm-cli/user"
 	u "github.com/10gen/realm-cli/utils/test"
@@ -82,3 +85,92 @@ func TestWhoamiCommand(t *testing.T) {
 		})
 	}
 }
+
+func TestWhoamiCommandDetails(t *testing.T) {
+	setup := func(inMemoryUser *user.User) (*WhoamiCommand, *cli.MockUi) {
+		mockUI := cli.NewMockUi()
+		cmd, err := NewWhoamiCommandFactory(mockUI)()
+		if err != nil {
+			panic(err)
+		}
+
+		whoamiCommand := cmd.(*WhoamiCommand)
+		whoamiCommand.user = inMemoryUser
+		whoamiCommand.storage = u.NewEmptyStorage()
+
+		return whoamiCommand, mockUI
+	}
+
+	t.Run("it displays the project and base URL without calling the API", func(t *testing.T) {
+		whoamiCommand, mockUI := setup(&user.User{
+			PublicAPIKey:    "my.username",
+			PrivateAPIKey:   "my-api-key",
+			ProjectID:       "5f3c2b1a0d9e8f7a6b5c4d3e",
+			ProjectIDAPIKey: "my.username",
+		})
+		whoamiCommand.realmClient = &u.MockRealmClient{
+			GetUserProfileFn: func() (*models.UserProfile, error) {
+				return nil, errors.New("the API should not be called")
+			},
+		}
+
+		exitCode := whoamiCommand.Run([]string{"--base-url=https://realm-staging.mongodb.com"})
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+		u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, "Project: 5f3c2b1a0d9e8f7a6b5c4d3e (last used)\n")
+		u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, "Base URL: https://realm-staging.mongodb.com\n")
+		u.So(t, mockUI.OutputWriter.String(), gc.ShouldNotContainSubstring, "Profile:")
+	})
+
+	t.Run("it prefers the default project over the last used one", func(t *testing.T) {
+		whoamiCommand, mockUI := setup(&user.User{
+			Defaults:  map[string]string{"project": "5f3c2b1a0d9e8f7a6b5c4d3f"},
+			ProjectID: "5f3c2b1a0d9e8f7a6b5c4d3e",
+		})
+
+		exitCode := whoamiCommand.Run([]string{})
+		u.So(t, exitCode, gc.ShouldEqual, 0)
+		u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, "Project: 5f3c2b1a0d9e8f7a6b5c4d3f (set with config set)\n")
+	})
+
+	t.Run("with --verify", func(t *testing.T) {
+		t.Run("it requires the user to be logged in", func(t *testing.T) {
+			whoamiCommand, mockUI := setup(&user.User{PublicAPIKey: "my.username", PrivateAPIKey: "my-api-key"})
+
+			exitCode := whoamiCommand.Run([]string{"--verify"})
+			u.So(t, exitCode, gc.ShouldEqual, 1)
+			u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, user.ErrNotLoggedIn.Error())
+		})
+
+		t.Run("it reports a valid session", func(t *testing.T) {
+			whoamiCommand, mockUI := setup(&user.User{
+				PublicAPIKey:  "my.username",
+				PrivateAPIKey: "my-api-key",
+				AccessToken:   u.GenerateValidAccessToken(),
+			})
+			whoamiCommand.realmClient = &u.MockRealmClient{
+				GetUserProfileFn: func() (*models.UserProfile, error) {
+					var profile models.UserProfile
+					err := json.Unmarshal([]byte(`{"roles":[{"group_id":"group-1"},{"group_id":"group-2"}]}`), &profile)
+					return &profile, err
+				},
+			}
+
+			exitCode := whoamiCommand.Run([]string{"--verify"})
+			u.So(t, exitCode, gc.ShouldEqual, 0)
+			u.So(t, mockUI.OutputWriter.String(), gc.ShouldContainSubstring, "Session: valid, with access to 2 project(s)\n")
+		})
+
+		t.Run("it reports a session which is no longer valid", func(t *testing.T) {
+			whoamiCommand, mockUI := setup(&user.User{AccessToken: u.GenerateValidAccessToken()})
+			whoamiCommand.realmClient = &u.MockRealmClient{
+				GetUserProfileFn: func() (*models.UserProfile, error) {
+					return nil, errors.New("invalid session")
+				},
+			}
+
+			exitCode := whoamiCommand.Run([]string{"--verify"})
+			u.So(t, exitCode, gc.ShouldEqual, 1)
+			u.So(t, mockUI.ErrorWriter.String(), gc.ShouldContainSubstring, "failed to verify the session: invalid session")
+		})
+	})
+}
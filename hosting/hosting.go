@@ -4,19 +4,22 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/10gen/realm-cli/utils"
 )
 
 // ListLocalAssetMetadata walks all files from the rootDirectory
-// and builds []AssetMetadata from those files
+// and builds []AssetMetadata from those files, skipping those ignored by realmIgnore
 // returns the assetMetadata and possibly alters the assetCache
-func ListLocalAssetMetadata(appID, rootDirectory string, assetDescriptions map[string]AssetDescription, assetCache AssetCache) ([]AssetMetadata, error) {
+func ListLocalAssetMetadata(appID, rootDirectory string, assetDescriptions map[string]AssetDescription, assetCache AssetCache, realmIgnore *utils.IgnoreRules) ([]AssetMetadata, error) {
 	var assetMetadata []AssetMetadata
+	ignoredAssets := map[string]bool{}
 
-	err := filepath.Walk(rootDirectory, buildAssetMetadata(appID, &assetMetadata, rootDirectory, assetDescriptions, assetCache))
+	err := filepath.Walk(rootDirectory, buildAssetMetadata(appID, &assetMetadata, ignoredAssets, rootDirectory, assetDescriptions, assetCache, realmIgnore))
 	if err != nil {
 		return nil, err
 	}
@@ -28,7 +31,7 @@ func ListLocalAssetMetadata(appID, rootDirectory string, assetDescriptions map[s
 	}
 
 	for key := range assetDescriptions {
-		if _, ok := metadataOnDisk[key]; !ok {
+		if _, ok := metadataOnDisk[key]; !ok && !isIgnoredAsset(key, ignoredAssets) {
 			return nil, fmt.Errorf("file '%s' has an entry in metadata file, but does not appear in files directory", key)
 		}
 	}
@@ -36,11 +39,33 @@ func ListLocalAssetMetadata(appID, rootDirectory string, assetDescriptions map[s
 	return assetMetadata, nil
 }
 
-func buildAssetMetadata(appID string, assetMetadata *[]AssetMetadata, rootDir string, assetDescriptions map[string]AssetDescription, assetCache AssetCache) filepath.WalkFunc {
+// isIgnoredAsset reports whether the asset or one of the directories it is in was ignored
+func isIgnoredAsset(assetPath string, ignoredAssets map[string]bool) bool {
+	for p := assetPath; p != "/" && p != "."; p = path.Dir(p) {
+		if ignoredAssets[p] {
+			return true
+		}
+	}
+	return false
+}
+
+func buildAssetMetadata(appID string, assetMetadata *[]AssetMetadata, ignoredAssets map[string]bool, rootDir string, assetDescriptions map[string]AssetDescription, assetCache AssetCache, realmIgnore *utils.IgnoreRules) filepath.WalkFunc {
 	return func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
+		if path != rootDir && realmIgnore.Ignored(path, info.IsDir()) {
+			relPath, pathErr := filepath.Rel(rootDir, path)
+			if pathErr != nil {
+				return pathErr
+			}
+			ignoredAssets[fmt.Sprintf("/%s", replacePathSeparator(relPath))] = true
+
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
 		if !info.IsDir() {
 			relPath, pathErr := filepath.Rel(rootDir, path)
 			if pathErr != nil {
@@ -87,9 +112,9 @@ func FileToAssetMetadata(appID, path, assetPath string, info os.FileInfo, desc *
 		}
 	}
 
-	// check cache for file hash
+	// check cache for file hash, entries cached without the nanoseconds of the modification time are hashed again
 	if ace, ok := assetCache.Get(appID, assetPath); ok {
-		if ace.FileSize == info.Size() && ace.LastModified == info.ModTime().Unix() {
+		if ace.FileSize == info.Size() && ace.LastModifiedNano != 0 && ace.LastModifiedNano == info.ModTime().UnixNano() {
 			return NewAssetMetadata(appID, assetPath, ace.FileHash, info.Size(), attrs, info.ModTime().Unix()), nil
 		}
 	}
@@ -101,10 +126,11 @@ func FileToAssetMetadata(appID, path, assetPath string, info os.FileInfo, desc *
 	}
 
 	assetCache.Set(appID, AssetCacheEntry{
-		assetPath,
-		info.ModTime().Unix(),
-		info.Size(),
-		generated,
+		FilePath:         assetPath,
+		LastModified:     info.ModTime().Unix(),
+		FileSize:         info.Size(),
+		FileHash:         generated,
+		LastModifiedNano: info.ModTime().UnixNano(),
 	})
 
 	return NewAssetMetadata(appID, assetPath, generated, info.Size(), attrs, info.ModTime().Unix()), nil
@@ -129,12 +155,30 @@ func MetadataFileToAssetDescriptions(path string) (map[string]AssetDescription,
 	descM := make(map[string]AssetDescription, len(descs))
 	for _, desc := range descs {
 		descFilePath := replacePathSeparator(desc.FilePath)
+		for i, attr := range desc.Attrs {
+			name, ok := canonicalAttributeName(attr.Name)
+			if !ok {
+				return nil, fmt.Errorf("file '%s' has an unsupported attribute '%s' in metadata file", descFilePath, attr.Name)
+			}
+			desc.Attrs[i].Name = name
+		}
 		descM[descFilePath] = desc
 	}
 
 	return descM, nil
 }
 
+// canonicalAttributeName returns the name of the supported attribute matching name regardless of case,
+// so that e.g. "cache-control" is compared against and uploaded as the "Cache-Control" Realm lists
+func canonicalAttributeName(name string) (string, bool) {
+	for validName := range ValidAttributeNames {
+		if strings.EqualFold(name, validName) {
+			return validName, true
+		}
+	}
+	return "", false
+}
+
 // CacheFileToAssetCache attempts to open the file at the path given
 // and build a map of appID to a map of file path strings a AssetCache
 func CacheFileToAssetCache(path string) (AssetCache, error) {
@@ -209,6 +253,43 @@ func DiffAssetMetadata(local, remote []AssetMetadata, merge bool) *AssetMetadata
 	return NewAssetMetadataDiffs(addedLocally, deletedLocally, modifiedLocally)
 }
 
+// CheckCaseCollisions returns an error listing the assets whose paths only differ by case once the local
+// assets are uploaded, since case-insensitive filesystems and CDNs cannot tell them apart.
+// If the merge parameter is true, the remote assets missing locally are kept and so are also checked
+func CheckCaseCollisions(local, remote []AssetMetadata, merge bool) error {
+	paths := map[string]string{}
+	for _, am := range local {
+		paths[am.FilePath] = am.FilePath
+	}
+	if merge {
+		for _, am := range remote {
+			if _, ok := paths[am.FilePath]; !ok && !am.IsDir() {
+				paths[am.FilePath] = am.FilePath + " (deployed)"
+			}
+		}
+	}
+
+	byFoldedPath := map[string][]string{}
+	for path, description := range paths {
+		folded := strings.ToLower(path)
+		byFoldedPath[folded] = append(byFoldedPath[folded], description)
+	}
+
+	var collisions []string
+	for _, descriptions := range byFoldedPath {
+		if len(descriptions) > 1 {
+			sort.Strings(descriptions)
+			collisions = append(collisions, strings.Join(descriptions, ", "))
+		}
+	}
+	if len(collisions) == 0 {
+		return nil
+	}
+	sort.Strings(collisions)
+
+	return fmt.Errorf("found hosting assets whose paths only differ by case:\n\t%s", strings.Join(collisions, "\n\t"))
+}
+
 // Diff returns a list of strings representing the diff
 func (amd *AssetMetadataDiffs) Diff() []string {
 	var diff []string
@@ -231,6 +312,10 @@ func (amd *AssetMetadataDiffs) Diff() []string {
 		diff = append(diff, "Modified Files:")
 	}
 	for _, modified := range amd.ModifiedLocally {
+		if modified.AttrModified && !modified.BodyModified {
+			diff = append(diff, fmt.Sprintf("\t* %s (attributes only)", modified.AssetMetadata.FilePath))
+			continue
+		}
 		diff = append(diff, fmt.Sprintf("\t* %s", modified.AssetMetadata.FilePath))
 	}
 
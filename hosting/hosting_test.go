@@ -3,9 +3,11 @@ package hosting_test
 import (
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/10gen/realm-cli/hosting"
 	"github.com/10gen/realm-cli/utils"
@@ -56,7 +58,7 @@ func TestListLocalAssetMetadata(t *testing.T) {
 			},
 		},
 	}
-	assetMetadata, listErr := hosting.ListLocalAssetMetadata(appID, rootDir, assetDescriptions, assetCache)
+	assetMetadata, listErr := hosting.ListLocalAssetMetadata(appID, rootDir, assetDescriptions, assetCache, nil)
 	u.So(t, listErr, gc.ShouldBeNil)
 
 	localPath0, localPath1, localPath2 := filepath.Join(filesRoot, path0), filepath.Join(filesRoot, path1), filepath.Join(filesRoot, path2)
@@ -107,10 +109,26 @@ func TestListLocalAssetMetadata(t *testing.T) {
 			Attrs:    []hosting.AssetAttribute{jsonAttr},
 		},
 	}
-	_, listErr = hosting.ListLocalAssetMetadata(appID, rootDir, assetDescriptions, assetCache)
+	_, listErr = hosting.ListLocalAssetMetadata(appID, rootDir, assetDescriptions, assetCache, nil)
 	expectedError := fmt.Sprintf("file '%s' has an entry in metadata file, but does not appear in files directory", path3)
 	u.So(t, listErr.Error(), gc.ShouldEqual, expectedError)
 
+	t.Run("should skip the assets ignored by the .realmignore", func(t *testing.T) {
+		realmIgnore, err := utils.NewIgnoreRules(filepath.Dir(filepath.Dir(rootDir)), "*.html", "hosting/files/ships/")
+		u.So(t, err, gc.ShouldBeNil)
+		assetDescriptions := map[string]hosting.AssetDescription{
+			path1: {
+				FilePath: path1,
+				Attrs:    []hosting.AssetAttribute{jsonAttr},
+			},
+		}
+
+		assetMetadata, err := hosting.ListLocalAssetMetadata(appID, rootDir, assetDescriptions, hosting.NewAssetCache(), realmIgnore)
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, assetMetadata, gc.ShouldHaveLength, 1)
+		u.So(t, assetMetadata[0].FilePath, gc.ShouldEqual, path0)
+	})
+
 	t.Run("asset cache should be updated from local listing", func(t *testing.T) {
 		entry, ok := assetCache.Get(testAppID, path0)
 		u.So(t, ok, gc.ShouldBeTrue)
@@ -119,10 +137,11 @@ func TestListLocalAssetMetadata(t *testing.T) {
 			entry,
 			gc.ShouldResemble,
 			hosting.AssetCacheEntry{
-				FilePath:     path0,
-				LastModified: fileInfo0.ModTime().Unix(),
-				FileSize:     fileInfo0.Size(),
-				FileHash:     mustGenerateFileHash(localPath0),
+				FilePath:         path0,
+				LastModified:     fileInfo0.ModTime().Unix(),
+				FileSize:         fileInfo0.Size(),
+				FileHash:         mustGenerateFileHash(localPath0),
+				LastModifiedNano: fileInfo0.ModTime().UnixNano(),
 			},
 		)
 		entry, ok = assetCache.Get(testAppID, path1)
@@ -132,10 +151,11 @@ func TestListLocalAssetMetadata(t *testing.T) {
 			entry,
 			gc.ShouldResemble,
 			hosting.AssetCacheEntry{
-				FilePath:     path1,
-				LastModified: fileInfo1.ModTime().Unix(),
-				FileSize:     fileInfo1.Size(),
-				FileHash:     mustGenerateFileHash(localPath1),
+				FilePath:         path1,
+				LastModified:     fileInfo1.ModTime().Unix(),
+				FileSize:         fileInfo1.Size(),
+				FileHash:         mustGenerateFileHash(localPath1),
+				LastModifiedNano: fileInfo1.ModTime().UnixNano(),
 			},
 		)
 
@@ -146,10 +166,11 @@ func TestListLocalAssetMetadata(t *testing.T) {
 			entry,
 			gc.ShouldResemble,
 			hosting.AssetCacheEntry{
-				FilePath:     path2,
-				LastModified: fileInfo2.ModTime().Unix(),
-				FileSize:     fileInfo2.Size(),
-				FileHash:     mustGenerateFileHash(localPath2),
+				FilePath:         path2,
+				LastModified:     fileInfo2.ModTime().Unix(),
+				FileSize:         fileInfo2.Size(),
+				FileHash:         mustGenerateFileHash(localPath2),
+				LastModifiedNano: fileInfo2.ModTime().UnixNano(),
 			},
 		)
 	})
@@ -164,6 +185,64 @@ var xmlAttr = hosting.AssetAttribute{
 	Value: "xml",
 }
 
+func TestFileToAssetMetadataHashesSameSizeEdits(t *testing.T) {
+	dir, err := ioutil.TempDir("", "realm-hosting-hash-")
+	u.So(t, err, gc.ShouldBeNil)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "index.html")
+	modTime := time.Unix(1600000000, 100)
+	writeAsset := func(contents string, modTime time.Time) os.FileInfo {
+		u.So(t, ioutil.WriteFile(path, []byte(contents), 0600), gc.ShouldBeNil)
+		u.So(t, os.Chtimes(path, modTime, modTime), gc.ShouldBeNil)
+		return mustGetFileInfo(path)
+	}
+
+	assetCache := hosting.NewAssetCache()
+	before, err := hosting.FileToAssetMetadata("app-id", path, "/index.html", writeAsset("before", modTime), nil, assetCache)
+	u.So(t, err, gc.ShouldBeNil)
+	u.So(t, before.FileHash, gc.ShouldEqual, mustGenerateFileHash(path))
+
+	t.Run("it hashes a file edited within the same second again", func(t *testing.T) {
+		info := writeAsset("after!", modTime.Add(time.Millisecond))
+
+		after, err := hosting.FileToAssetMetadata("app-id", path, "/index.html", info, nil, assetCache)
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, after.FileSize, gc.ShouldEqual, before.FileSize)
+		u.So(t, after.FileHash, gc.ShouldEqual, mustGenerateFileHash(path))
+		u.So(t, after.FileHash, gc.ShouldNotEqual, before.FileHash)
+	})
+
+	t.Run("it reuses the hash of an unchanged file", func(t *testing.T) {
+		info := mustGetFileInfo(path)
+		assetCache.Set("app-id", hosting.AssetCacheEntry{
+			FilePath:         "/index.html",
+			LastModified:     info.ModTime().Unix(),
+			FileSize:         info.Size(),
+			FileHash:         "cached-hash",
+			LastModifiedNano: info.ModTime().UnixNano(),
+		})
+
+		cached, err := hosting.FileToAssetMetadata("app-id", path, "/index.html", info, nil, assetCache)
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, cached.FileHash, gc.ShouldEqual, "cached-hash")
+	})
+
+	t.Run("it hashes a file cached without the nanoseconds of its modification time again", func(t *testing.T) {
+		info := mustGetFileInfo(path)
+		assetCache.Set("app-id", hosting.AssetCacheEntry{
+			FilePath:     "/index.html",
+			LastModified: info.ModTime().Unix(),
+			FileSize:     info.Size(),
+			FileHash:     "cached-hash",
+		})
+
+		rehashed, err := hosting.FileToAssetMetadata("app-id", path, "/index.html", info, nil, assetCache)
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, rehashed.FileHash, gc.ShouldEqual, mustGenerateFileHash(path))
+	})
+}
+
 func TestGetModifiedAssetMetadata(t *testing.T) {
 	for _, tc := range []struct {
 		local        hosting.AssetMetadata
@@ -240,6 +319,7 @@ func assertAssetCacheEntryEqual(t *testing.T, actual, expected hosting.AssetCach
 	u.So(t, actual.LastModified, gc.ShouldEqual, expected.LastModified)
 	u.So(t, actual.FileSize, gc.ShouldEqual, expected.FileSize)
 	u.So(t, actual.FileHash, gc.ShouldEqual, expected.FileHash)
+	u.So(t, actual.LastModifiedNano, gc.ShouldEqual, expected.LastModifiedNano)
 }
 
 func TestUpdateCacheFile(t *testing.T) {
@@ -257,6 +337,7 @@ func TestUpdateCacheFile(t *testing.T) {
 		lastModified,
 		fileSize,
 		fileHash,
+		lastModified * int64(time.Second),
 	}
 
 	assetCache := hosting.NewAssetCache()
@@ -285,6 +366,7 @@ func TestUpdateCacheFile(t *testing.T) {
 			lastModified,
 			fileSize,
 			fileHash,
+			lastModified * int64(time.Second),
 		}
 		updatedCache.Set(appID, newAssetCacheEntry)
 
@@ -308,6 +390,7 @@ func TestAssetCache(t *testing.T) {
 		lastModified,
 		fileSize,
 		fileHash,
+		lastModified * int64(time.Second),
 	}
 	assetCache := hosting.NewAssetCache()
 	assetCache.Set(appID, assetCacheEntry)
@@ -334,6 +417,7 @@ func TestAssetCache(t *testing.T) {
 		int64(10887),
 		int64(66),
 		"0rd3r",
+		int64(10887) * int64(time.Second),
 	}
 
 	t.Run("Set should work for an existing appID", func(t *testing.T) {
@@ -358,6 +442,7 @@ func TestRoundTripAssetCacheEntry(t *testing.T) {
 		int64(10887),
 		int64(12),
 		"l3in5h1p",
+		int64(10887) * int64(time.Second),
 	}
 
 	md, mErr := json.Marshal(cacheEntry)
@@ -608,6 +693,119 @@ func TestMetadataFileToAssetDescriptions(t *testing.T) {
 			[]hosting.AssetAttribute{},
 		},
 	})
+
+	writeMetadataFile := func(t *testing.T, contents string) string {
+		dir, err := ioutil.TempDir("", "realm-hosting-metadata-")
+		u.So(t, err, gc.ShouldBeNil)
+
+		path := filepath.Join(dir, "metadata.json")
+		u.So(t, ioutil.WriteFile(path, []byte(contents), 0600), gc.ShouldBeNil)
+		return path
+	}
+
+	t.Run("should match the attribute names regardless of case", func(t *testing.T) {
+		path := writeMetadataFile(t, `[{"path":"/app.3f2a.js","attrs":[{"name":"cache-control","value":"public, max-age=31536000, immutable"}]}]`)
+		defer os.RemoveAll(filepath.Dir(path))
+
+		assetDescriptions, err := hosting.MetadataFileToAssetDescriptions(path)
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, assetDescriptions["/app.3f2a.js"].Attrs, gc.ShouldResemble, []hosting.AssetAttribute{
+			{Name: hosting.AttributeCacheControl, Value: "public, max-age=31536000, immutable"},
+		})
+	})
+
+	t.Run("should reject an unsupported attribute", func(t *testing.T) {
+		path := writeMetadataFile(t, `[{"path":"/index.html","attrs":[{"name":"X-Frame-Options","value":"DENY"}]}]`)
+		defer os.RemoveAll(filepath.Dir(path))
+
+		_, err := hosting.MetadataFileToAssetDescriptions(path)
+		u.So(t, err, gc.ShouldNotBeNil)
+		u.So(t, err.Error(), gc.ShouldEqual, "file '/index.html' has an unsupported attribute 'X-Frame-Options' in metadata file")
+	})
+}
+
+func TestAssetMetadataToAssetDescriptions(t *testing.T) {
+	assetDescriptions := hosting.AssetMetadataToAssetDescriptions([]hosting.AssetMetadata{
+		{FilePath: "/"},
+		{FilePath: "/ships/"},
+		{FilePath: "/index.html", Attrs: []hosting.AssetAttribute{{hosting.AttributeContentType, "text/html"}}},
+		{FilePath: "/plain.html"},
+		{FilePath: "/README"},
+		{FilePath: "/ships/nostromo.json", Attrs: []hosting.AssetAttribute{{hosting.AttributeCacheControl, "no-cache"}, {"X-Unknown", "dropped"}}},
+	})
+
+	u.So(t, assetDescriptions, gc.ShouldResemble, []hosting.AssetDescription{
+		{"/plain.html", []hosting.AssetAttribute{}},
+		{"/ships/nostromo.json", []hosting.AssetAttribute{{hosting.AttributeCacheControl, "no-cache"}}},
+	})
+
+	t.Run("an asset without attributes keeps none when imported again", func(t *testing.T) {
+		data, err := json.Marshal(assetDescriptions[0])
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, string(data), gc.ShouldEqual, `{"path":"/plain.html","attrs":[]}`)
+	})
+
+	t.Run("sorts the assets by path and their attributes by name however they are listed", func(t *testing.T) {
+		u.So(t, hosting.AssetMetadataToAssetDescriptions([]hosting.AssetMetadata{
+			{FilePath: "/b.txt", Attrs: []hosting.AssetAttribute{{hosting.AttributeContentType, "text/csv"}, {hosting.AttributeCacheControl, "no-cache"}}},
+			{FilePath: "/a.html"},
+		}), gc.ShouldResemble, []hosting.AssetDescription{
+			{"/a.html", []hosting.AssetAttribute{}},
+			{"/b.txt", []hosting.AssetAttribute{{hosting.AttributeCacheControl, "no-cache"}, {hosting.AttributeContentType, "text/csv"}}},
+		})
+	})
+}
+
+func TestCheckCaseCollisions(t *testing.T) {
+	assets := func(paths ...string) []hosting.AssetMetadata {
+		var assetMetadata []hosting.AssetMetadata
+		for _, path := range paths {
+			assetMetadata = append(assetMetadata, hosting.AssetMetadata{FilePath: path})
+		}
+		return assetMetadata
+	}
+
+	for _, tc := range []struct {
+		description   string
+		local         []hosting.AssetMetadata
+		remote        []hosting.AssetMetadata
+		merge         bool
+		expectedError string
+	}{
+		{
+			description: "accepts paths which differ by more than case",
+			local:       assets("/styles.css", "/css/styles.css", "/index.html"),
+			remote:      assets("/", "/styles.css", "/old.css"),
+			merge:       true,
+		},
+		{
+			description:   "reports local paths which only differ by case",
+			local:         assets("/index.html", "/Styles.css", "/styles.css", "/img/Logo.png", "/IMG/logo.png"),
+			expectedError: "found hosting assets whose paths only differ by case:\n\t/IMG/logo.png, /img/Logo.png\n\t/Styles.css, /styles.css",
+		},
+		{
+			description:   "reports local paths colliding with deployed paths kept by a merge",
+			local:         assets("/Styles.css"),
+			remote:        assets("/", "/styles.css"),
+			merge:         true,
+			expectedError: "found hosting assets whose paths only differ by case:\n\t/Styles.css, /styles.css (deployed)",
+		},
+		{
+			description: "ignores deployed paths which are removed without merging",
+			local:       assets("/Styles.css"),
+			remote:      assets("/", "/styles.css"),
+		},
+	} {
+		t.Run(tc.description, func(t *testing.T) {
+			err := hosting.CheckCaseCollisions(tc.local, tc.remote, tc.merge)
+			if tc.expectedError == "" {
+				u.So(t, err, gc.ShouldBeNil)
+				return
+			}
+			u.So(t, err, gc.ShouldNotBeNil)
+			u.So(t, err.Error(), gc.ShouldEqual, tc.expectedError)
+		})
+	}
 }
 
 func TestAssetMetadataDiff(t *testing.T) {
@@ -681,6 +879,18 @@ func TestAssetMetadataDiff(t *testing.T) {
 		u.So(t, amd.Diff(), gc.ShouldResemble, modifyDiff)
 	})
 
+	t.Run("with attributes modified only", func(t *testing.T) {
+		amd := hosting.NewAssetMetadataDiffs(nil, nil, []hosting.ModifiedAssetMetadata{
+			{AssetMetadata: hosting.AssetMetadata{FilePath: m1Path}, AttrModified: true},
+			{AssetMetadata: hosting.AssetMetadata{FilePath: m2Path}, BodyModified: true, AttrModified: true},
+		})
+		u.So(t, amd.Diff(), gc.ShouldResemble, []string{
+			"Modified Files:",
+			fmt.Sprintf("\t* %s (attributes only)", m1Path),
+			fmt.Sprintf("\t* %s", m2Path),
+		})
+	})
+
 	t.Run("with additions, deletions, and modifcations", func(t *testing.T) {
 		amd := hosting.NewAssetMetadataDiffs(added, deleted, modified)
 		u.So(t, amd.Diff(), gc.ShouldResemble, append(append(addDiff, deleteDiff...), modifyDiff...))
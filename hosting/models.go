@@ -118,13 +118,19 @@ type AssetDescription struct {
 }
 
 // AssetMetadataToAssetDescriptions takes AssetMetadata and outputs the slice of AssetDescriptions
-// that should be written into the metadata file
+// that should be written into the metadata file, sorted by path with the attributes of each sorted by name
 func AssetMetadataToAssetDescriptions(assetMetadata []AssetMetadata) []AssetDescription {
 	assetDescriptions := make([]AssetDescription, 0, len(assetMetadata))
 	for _, amd := range assetMetadata {
 
-		// If there are no attributes for the asset, we dont need to add it to the assetDescription file
+		// If there are no attributes for the asset, we dont need to add it to the assetDescription file,
+		// unless the default type of its file extension would otherwise be assigned to it when it is imported
 		if len(amd.Attrs) == 0 {
+			if extension := path.Ext(amd.FilePath); extension != "" && !amd.IsDir() {
+				if _, found := utils.GetContentTypeByExtension(extension[1:]); found {
+					assetDescriptions = append(assetDescriptions, AssetDescription{FilePath: amd.FilePath, Attrs: []AssetAttribute{}})
+				}
+			}
 			continue
 		}
 
@@ -145,8 +151,17 @@ func AssetMetadataToAssetDescriptions(assetMetadata []AssetMetadata) []AssetDesc
 				assetAttributes = append(assetAttributes, attribute)
 			}
 		}
+		sort.SliceStable(assetAttributes, func(i, j int) bool {
+			return assetAttributes[i].Name < assetAttributes[j].Name
+		})
 		assetDescriptions = append(assetDescriptions, AssetDescription{FilePath: amd.FilePath, Attrs: assetAttributes})
 	}
+
+	// Sort by path so that exporting the same assets always writes the same metadata file,
+	// however Realm happens to list them
+	sort.SliceStable(assetDescriptions, func(i, j int) bool {
+		return assetDescriptions[i].FilePath < assetDescriptions[j].FilePath
+	})
 	return assetDescriptions
 }
 
@@ -187,12 +202,15 @@ func NewAssetMetadataDiffs(added, deleted []AssetMetadata, modified []ModifiedAs
 	}
 }
 
-// AssetCacheEntry represents the relevant data for caching
+// AssetCacheEntry represents the relevant data for caching. The hash of a file is only reused while the file
+// keeps both its size and its modification time, which is compared to the nanosecond so that a same-size edit
+// made within the same second is still hashed again
 type AssetCacheEntry struct {
-	FilePath     string `json:"path"`
-	LastModified int64  `json:"last_modified,omitempty"`
-	FileSize     int64  `json:"size,omitempty"`
-	FileHash     string `json:"hash,omitempty"`
+	FilePath         string `json:"path"`
+	LastModified     int64  `json:"last_modified,omitempty"`
+	FileSize         int64  `json:"size,omitempty"`
+	FileHash         string `json:"hash,omitempty"`
+	LastModifiedNano int64  `json:"last_modified_nano,omitempty"`
 }
 
 // entryMap is a map of appID to filePath to AssetCacheEntry
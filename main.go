@@ -22,19 +22,60 @@ func main() {
 	}
 
 	c.Commands = map[string]cli.CommandFactory{
-		"whoami":         commands.NewWhoamiCommandFactory(ui),
-		"login":          commands.NewLoginCommandFactory(ui),
-		"logout":         commands.NewLogoutCommandFactory(ui),
-		"export":         commands.NewExportCommandFactory(ui),
-		"import":         commands.NewImportCommandFactory(ui),
-		"diff":           commands.NewDiffCommandFactory(ui),
-		"secrets":        commands.NewSecretsCommandFactory(ui),
-		"secrets list":   commands.NewSecretsListCommandFactory(ui),
-		"secrets add":    commands.NewSecretsAddCommandFactory(ui),
-		"secrets update": commands.NewSecretsUpdateCommandFactory(ui),
-		"secrets remove": commands.NewSecretsRemoveCommandFactory(ui),
+		"whoami":            commands.NewWhoamiCommandFactory(ui),
+		"login":             commands.NewLoginCommandFactory(ui),
+		"logout":            commands.NewLogoutCommandFactory(ui),
+		"export":            commands.NewExportCommandFactory(ui),
+		"import":            commands.NewImportCommandFactory(ui),
+		"apply":             commands.NewApplyCommandFactory(ui),
+		"pull":              commands.NewPullCommandFactory(ui),
+		"diff":              commands.NewDiffCommandFactory(ui),
+		"secrets":           commands.NewSecretsCommandFactory(ui),
+		"secrets list":      commands.NewSecretsListCommandFactory(ui),
+		"secrets add":       commands.NewSecretsAddCommandFactory(ui),
+		"secrets update":    commands.NewSecretsUpdateCommandFactory(ui),
+		"secrets remove":    commands.NewSecretsRemoveCommandFactory(ui),
+		"secrets delete":    commands.NewSecretsDeleteCommandFactory(ui),
+		"hosting":           commands.NewHostingCommandFactory(ui),
+		"hosting upload":    commands.NewHostingUploadCommandFactory(ui),
+		"hosting list":      commands.NewHostingListCommandFactory(ui),
+		"hosting remove":    commands.NewHostingRemoveCommandFactory(ui),
+		"hosting rm":        commands.NewHostingRemoveCommandFactory(ui),
+		"hosting ls":        commands.NewHostingListCommandFactory(ui),
+		"deployments":       commands.NewDeploymentsCommandFactory(ui),
+		"deployments list":  commands.NewDeploymentsListCommandFactory(ui),
+		"dependencies":      commands.NewDependenciesCommandFactory(ui),
+		"dependencies list": commands.NewDependenciesListCommandFactory(ui),
+		"functions":         commands.NewFunctionsCommandFactory(ui),
+		"functions run":     commands.NewFunctionsRunCommandFactory(ui),
+		"logs":              commands.NewLogsCommandFactory(ui),
+		"triggers":          commands.NewTriggersCommandFactory(ui),
+		"triggers export":   commands.NewTriggersExportCommandFactory(ui),
+		"triggers import":   commands.NewTriggersImportCommandFactory(ui),
+		"triggers list":     commands.NewTriggersListCommandFactory(ui),
+		"triggers enable":   commands.NewTriggersEnableCommandFactory(ui),
+		"triggers disable":  commands.NewTriggersDisableCommandFactory(ui),
+		"schema":            commands.NewSchemaCommandFactory(ui),
+		"schema show":       commands.NewSchemaShowCommandFactory(ui),
+		"schema get":        commands.NewSchemaGetCommandFactory(ui),
+		"schema validate":   commands.NewSchemaValidateCommandFactory(ui),
+		"config":            commands.NewConfigCommandFactory(ui),
+		"config set":        commands.NewConfigSetCommandFactory(ui),
+		"config get":        commands.NewConfigGetCommandFactory(ui),
+		"config list":       commands.NewConfigListCommandFactory(ui),
+		"app":               commands.NewAppCommandFactory(ui),
+		"app delete":        commands.NewAppDeleteCommandFactory(ui),
+		"app describe":      commands.NewAppDescribeCommandFactory(ui),
+		"app init":          commands.NewAppInitCommandFactory(ui),
+		"app list":          commands.NewAppListCommandFactory(ui),
+		"app prune-drafts":  commands.NewAppPruneDraftsCommandFactory(ui),
+		"app rename":        commands.NewAppRenameCommandFactory(ui),
+		"app validate":      commands.NewValidateCommandFactory(ui),
 	}
 
+	// shorthands for "hosting list" and "hosting remove"
+	c.HiddenCommands = []string{"hosting ls", "hosting rm"}
+
 	exitStatus, err := c.Run()
 	if err != nil {
 		ui.Error(err.Error())
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"io/ioutil"
 	"path/filepath"
+	"strconv"
+	"time"
 
 	"fmt"
 )
@@ -40,6 +42,16 @@ const (
 	AppDeploymentModelField string = "deployment_model"
 )
 
+// Trigger field identifiers
+const (
+	TriggerIDField           string = "_id"
+	TriggerNameField         string = "name"
+	TriggerTypeField         string = "type"
+	TriggerDisabledField     string = "disabled"
+	TriggerFunctionIDField   string = "function_id"
+	TriggerFunctionNameField string = "function_name"
+)
+
 const (
 	prettyPrintPrefix = ""
 	prettyPrintIndent = "    "
@@ -145,10 +157,12 @@ type role struct {
 
 // App represents basic Realm App data
 type App struct {
-	ID          string `json:"_id"`
-	GroupID     string `json:"group_id"`
-	ClientAppID string `json:"client_app_id"`
-	Name        string `json:"name"`
+	ID              string `json:"_id"`
+	GroupID         string `json:"group_id"`
+	ClientAppID     string `json:"client_app_id"`
+	Name            string `json:"name"`
+	Location        string `json:"location,omitempty"`
+	DeploymentModel string `json:"deployment_model,omitempty"`
 }
 
 // AppDraft represents a Realm App Draft
@@ -156,10 +170,68 @@ type AppDraft struct {
 	ID string `json:"_id"`
 }
 
+// CreatedAt returns when the draft was created, as recorded in the timestamp of its ObjectID
+func (d AppDraft) CreatedAt() (time.Time, bool) {
+	if len(d.ID) != 24 {
+		return time.Time{}, false
+	}
+
+	seconds, err := strconv.ParseUint(d.ID[:8], 16, 32)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return time.Unix(int64(seconds), 0), true
+}
+
 // Deployment represents a Realm Deployment
 type Deployment struct {
-	ID     string           `json:"_id"`
-	Status DeploymentStatus `json:"status"`
+	ID        string           `json:"_id"`
+	Status    DeploymentStatus `json:"status"`
+	DraftID   string           `json:"draft_id,omitempty"`
+	CreatedAt int64            `json:"created_at,omitempty"`
+}
+
+// CacheInvalidationStatus is the enumeration of values which can be provided in a CacheInvalidation's status field
+type CacheInvalidationStatus string
+
+const (
+	// CacheInvalidationStatusInProgress indicates the CDN is still invalidating the cached assets
+	CacheInvalidationStatusInProgress CacheInvalidationStatus = "in_progress"
+
+	// CacheInvalidationStatusCompleted indicates the cached assets were invalidated
+	CacheInvalidationStatusCompleted CacheInvalidationStatus = "completed"
+
+	// CacheInvalidationStatusFailed indicates the cached assets could not be invalidated
+	CacheInvalidationStatusFailed CacheInvalidationStatus = "failed"
+)
+
+// CacheInvalidation represents a job invalidating the CDN cache of the hosted assets of a Realm App
+type CacheInvalidation struct {
+	ID     string                  `json:"_id"`
+	Path   string                  `json:"path,omitempty"`
+	Status CacheInvalidationStatus `json:"status"`
+}
+
+// Dependencies represents the dependencies deployed to a Realm App
+type Dependencies struct {
+	ID           string              `json:"_id"`
+	Location     string              `json:"location,omitempty"`
+	UserID       string              `json:"user_id,omitempty"`
+	LastModified int64               `json:"last_modified,omitempty"`
+	Packages     []DependencyPackage `json:"dependencies_list"`
+}
+
+// DependencyPackage is a package installed in the dependencies of a Realm App
+type DependencyPackage struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// Template represents an app which is offered for new Realm Apps to start from
+type Template struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
 }
 
 // DraftDiff represents the diff of an AppDraft
@@ -176,6 +248,94 @@ func (d *DraftDiff) HasChanges() bool {
 		len(d.HostingFilesDiff.Modified) != 0
 }
 
+// FunctionExecution represents the outcome of running a Realm Function
+type FunctionExecution struct {
+	Result    interface{} `json:"result"`
+	Logs      []string    `json:"logs"`
+	ErrorLogs []string    `json:"error_logs"`
+	Error     string      `json:"error,omitempty"`
+	ErrorCode string      `json:"error_code,omitempty"`
+}
+
+// Function represents basic Realm Function data
+type Function struct {
+	ID   string `json:"_id"`
+	Name string `json:"name"`
+}
+
+// AuthProvider represents basic Realm Auth Provider data
+type AuthProvider struct {
+	ID       string `json:"_id"`
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Disabled bool   `json:"disabled"`
+}
+
+// dataSourceServiceTypes are the types of the services which are data sources
+var dataSourceServiceTypes = map[string]bool{
+	"mongodb":       true,
+	"mongodb-atlas": true,
+	"datalake":      true,
+}
+
+// Service represents basic Realm Service data
+type Service struct {
+	ID   string `json:"_id"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// IsDataSource reports whether the service is a data source, i.e. a linked cluster or Data Lake
+func (s Service) IsDataSource() bool {
+	return dataSourceServiceTypes[s.Type]
+}
+
+// Log represents an entry of the logs of a Realm App, e.g. a function call or an authentication request
+type Log struct {
+	ID                    string    `json:"_id"`
+	Type                  string    `json:"type"`
+	Started               time.Time `json:"started"`
+	Completed             time.Time `json:"completed"`
+	FunctionName          string    `json:"function_name,omitempty"`
+	EventSubscriptionName string    `json:"event_subscription_name,omitempty"`
+	Error                 string    `json:"error,omitempty"`
+	ErrorCode             string    `json:"error_code,omitempty"`
+	Messages              []string  `json:"logs,omitempty"`
+}
+
+// Trigger represents the definition of a Realm Trigger, including any fields not described here
+type Trigger map[string]interface{}
+
+// ID returns the trigger's ID
+func (t Trigger) ID() string {
+	id, _ := t[TriggerIDField].(string)
+	return id
+}
+
+// Name returns the trigger's name
+func (t Trigger) Name() string {
+	name, _ := t[TriggerNameField].(string)
+	return name
+}
+
+// Type returns the trigger's type, e.g. "SCHEDULED"
+func (t Trigger) Type() string {
+	triggerType, _ := t[TriggerTypeField].(string)
+	return triggerType
+}
+
+// Disabled reports whether the trigger is disabled
+func (t Trigger) Disabled() bool {
+	disabled, _ := t[TriggerDisabledField].(bool)
+	return disabled
+}
+
+// FunctionName returns the name of the function the trigger calls, if any
+func (t Trigger) FunctionName() string {
+	name, _ := t[TriggerFunctionNameField].(string)
+	return name
+}
+
 // HostingDiff represents the hosting files section of a DraftDiff
 type HostingDiff struct {
 	Added    []string `json:"added"`
@@ -65,9 +65,13 @@ func (s *Storage) ReadUserConfig() (*user.User, error) {
 	return &user, nil
 }
 
-// Clear clears out a user's data from Storage
+// Clear clears out a user's credentials from Storage, keeping the defaults they have set
 func (s *Storage) Clear() error {
-	return s.WriteUserConfig(&user.User{})
+	u, err := s.ReadUserConfig()
+	if err != nil {
+		return s.WriteUserConfig(&user.User{})
+	}
+	return s.WriteUserConfig(&user.User{Defaults: u.Defaults})
 }
 
 // FileStrategy is a Storage that reads/persists data to/from a file at the provided path
@@ -22,6 +22,14 @@ type User struct {
 
 	RefreshToken string `yaml:"refresh_token"`
 	AccessToken  string `yaml:"access_token"`
+
+	// Defaults are the values set with "config set", by key, which are used for the flags they stand for when not given
+	Defaults map[string]string `yaml:"defaults,omitempty"`
+
+	// ProjectID is the Atlas Project ID last chosen when one had to be resolved, along with the public API key
+	// it was chosen with, so that it is only reused while logged in as the same account
+	ProjectID       string `yaml:"project_id,omitempty"`
+	ProjectIDAPIKey string `yaml:"project_id_api_key,omitempty"`
 }
 
 // LoggedIn returns a boolean representing whether the user is logged in or not
@@ -29,6 +37,21 @@ func (u *User) LoggedIn() bool {
 	return auth.ValidAccessToken(u.AccessToken)
 }
 
+// CachedProjectID returns the Atlas Project ID last chosen by the user, unless they have logged in
+// as another account since
+func (u *User) CachedProjectID() (string, bool) {
+	if u.ProjectID == "" || u.ProjectIDAPIKey != u.PublicAPIKey {
+		return "", false
+	}
+	return u.ProjectID, true
+}
+
+// CacheProjectID remembers the Atlas Project ID chosen by the user for the account they are logged in as
+func (u *User) CacheProjectID(projectID string) {
+	u.ProjectID = projectID
+	u.ProjectIDAPIKey = u.PublicAPIKey
+}
+
 // TokenIsExpired returns a boolean representing whether or not the token is expired
 // or an error if the token is invalid
 func (u *User) TokenIsExpired() (bool, error) {
@@ -0,0 +1,559 @@
+package utils
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// appConfigKind is the kind of resource used for the fields of the app configuration
+// that are not kept in a list of named resources
+const appConfigKind = "app config"
+
+// AppResource identifies a single resource within a Realm app configuration
+type AppResource struct {
+	Kind string
+	Name string
+}
+
+func (ar AppResource) String() string {
+	return fmt.Sprintf("%s: %s", ar.Kind, ar.Name)
+}
+
+// AppDiffs represents the structural differences between a local and a remote Realm app configuration
+type AppDiffs struct {
+	AddedLocally    []AppResource
+	DeletedLocally  []AppResource
+	ModifiedLocally []AppResource
+	RenamedLocally  []AppRename
+
+	// SyncChanges lists the changes to the Sync configuration of the app and its services field by field,
+	// on top of the resources they belong to being reported as modified
+	SyncChanges []SyncChange
+
+	// SchemaChanges lists the changes to the schemas of the collections which may delete data
+	SchemaChanges []DestructiveSchemaChange
+}
+
+// The set of changes an AppChange can describe
+const (
+	AppChangeAdded    = "added"
+	AppChangeRemoved  = "removed"
+	AppChangeModified = "modified"
+	AppChangeRenamed  = "renamed"
+)
+
+// SyncChangeKind is the kind of AppChange used for a change to a field of a Sync configuration
+const SyncChangeKind = "sync"
+
+// AppChange describes a single change to a resource, along with the path of the local
+// file which defines it, relative to the app directory
+type AppChange struct {
+	Change string `json:"change"`
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	Path   string `json:"path,omitempty"`
+
+	// PreviousName is the name a renamed resource had before
+	PreviousName string `json:"previous_name,omitempty"`
+
+	// Modified is set for a renamed resource whose contents changed along with its name
+	Modified bool `json:"modified,omitempty"`
+
+	// Risk explains how a change to the Sync configuration may disrupt clients, or how a change to a schema
+	// may delete data
+	Risk string `json:"risk,omitempty"`
+
+	// Destructive is true for the changes to a schema which may delete data
+	Destructive bool `json:"destructive,omitempty"`
+}
+
+// appResourceList describes how to find, name and rename the resources kept in a list in the app configuration
+type appResourceList struct {
+	kind    string
+	lookup  func(app map[string]interface{}) []interface{}
+	name    func(resource map[string]interface{}) string
+	setName func(resource map[string]interface{}, name string)
+}
+
+var appResourceLists = []appResourceList{
+	{"value", topLevelList(valuesName), nameField, setNameField},
+	{"auth provider", topLevelList(authProvidersName), nameField, setNameField},
+	{"function", topLevelList(FunctionsRoot), configNameField, setConfigNameField},
+	{"trigger", topLevelList(triggersName), nameField, setNameField},
+	{"service", topLevelList(servicesName), configNameField, setConfigNameField},
+	{"custom resolver", customResolversList, customResolverName, setCustomResolverName},
+}
+
+func topLevelList(key string) func(app map[string]interface{}) []interface{} {
+	return func(app map[string]interface{}) []interface{} {
+		list, _ := app[key].([]interface{})
+		return list
+	}
+}
+
+func customResolversList(app map[string]interface{}) []interface{} {
+	gql, _ := app[graphQLName].(map[string]interface{})
+	list, _ := gql[customResolversName].([]interface{})
+	return list
+}
+
+func nameField(resource map[string]interface{}) string {
+	name, _ := resource["name"].(string)
+	return name
+}
+
+func configNameField(resource map[string]interface{}) string {
+	config, _ := resource[configName].(map[string]interface{})
+	return nameField(config)
+}
+
+func customResolverName(resource map[string]interface{}) string {
+	onType, _ := resource["on_type"].(string)
+	fieldName, _ := resource["field_name"].(string)
+	return onType + "." + fieldName
+}
+
+func setNameField(resource map[string]interface{}, name string) {
+	resource["name"] = name
+}
+
+func setConfigNameField(resource map[string]interface{}, name string) {
+	if config, ok := resource[configName].(map[string]interface{}); ok {
+		setNameField(config, name)
+	}
+}
+
+func setCustomResolverName(resource map[string]interface{}, name string) {
+	parts := strings.SplitN(name, ".", 2)
+	resource["on_type"] = parts[0]
+	if len(parts) == 2 {
+		resource["field_name"] = parts[1]
+	}
+}
+
+// DiffApps compares a local and remote app configuration, as loaded by UnmarshalFromDir,
+// and returns an AppDiffs which contains information about the differences between the two.
+// Resources are matched by name rather than by _id so that newly created local resources line up.
+// If the merge parameter is true, we ignore deleted resources
+func DiffApps(local, remote map[string]interface{}, merge bool) *AppDiffs {
+	diffs := &AppDiffs{}
+
+	for _, resourceList := range appResourceLists {
+		remoteByName := map[string]interface{}{}
+		for _, r := range resourceList.lookup(remote) {
+			if resource, ok := r.(map[string]interface{}); ok {
+				remoteByName[resourceList.name(resource)] = resource
+			}
+		}
+
+		for _, l := range resourceList.lookup(local) {
+			resource, ok := l.(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			name := resourceList.name(resource)
+			appResource := AppResource{resourceList.kind, name}
+			if remoteResource, ok := remoteByName[name]; !ok {
+				diffs.AddedLocally = append(diffs.AddedLocally, appResource)
+			} else {
+				if !reflect.DeepEqual(resource, remoteResource) {
+					diffs.ModifiedLocally = append(diffs.ModifiedLocally, appResource)
+				}
+				delete(remoteByName, name)
+			}
+		}
+
+		// at this point remoteByName only contains resources that were deleted locally
+		if !merge {
+			for name := range remoteByName {
+				diffs.DeletedLocally = append(diffs.DeletedLocally, AppResource{resourceList.kind, name})
+			}
+		}
+	}
+
+	// everything else in the app configuration is compared field by field
+	localFields, remoteFields := appConfigFields(local), appConfigFields(remote)
+	for key, localValue := range localFields {
+		remoteValue, ok := remoteFields[key]
+		if !ok {
+			diffs.AddedLocally = append(diffs.AddedLocally, AppResource{appConfigKind, key})
+		} else if !reflect.DeepEqual(localValue, remoteValue) {
+			diffs.ModifiedLocally = append(diffs.ModifiedLocally, AppResource{appConfigKind, key})
+		}
+	}
+	if !merge {
+		for key := range remoteFields {
+			if _, ok := localFields[key]; !ok {
+				diffs.DeletedLocally = append(diffs.DeletedLocally, AppResource{appConfigKind, key})
+			}
+		}
+	}
+
+	sortAppResources(diffs.AddedLocally)
+	sortAppResources(diffs.DeletedLocally)
+	sortAppResources(diffs.ModifiedLocally)
+
+	diffs.SyncChanges = diffSync(local, remote, merge)
+	diffs.SchemaChanges = DiffDestructiveSchemaChanges(local, remote, merge)
+
+	return diffs
+}
+
+// appConfigFields returns the parts of the app configuration that are not covered by appResourceLists
+func appConfigFields(app map[string]interface{}) map[string]interface{} {
+	fields := map[string]interface{}{}
+	for key, value := range app {
+		switch key {
+		case valuesName, authProvidersName, FunctionsRoot, triggersName, servicesName:
+			continue
+		case graphQLName:
+			gql, _ := value.(map[string]interface{})
+			if gqlConfig, ok := gql[configName]; ok {
+				fields[graphQLName+"."+configName] = gqlConfig
+			}
+			continue
+		}
+		fields[key] = value
+	}
+	return fields
+}
+
+func sortAppResources(resources []AppResource) {
+	sort.Slice(resources, func(i, j int) bool {
+		if resources[i].Kind != resources[j].Kind {
+			return resources[i].Kind < resources[j].Kind
+		}
+		return resources[i].Name < resources[j].Name
+	})
+}
+
+// Diff returns a list of strings representing the diff
+func (ad *AppDiffs) Diff() []string {
+	var diff []string
+
+	if len(ad.AddedLocally) > 0 {
+		diff = append(diff, "New Resources:")
+	}
+	for _, added := range ad.AddedLocally {
+		diff = append(diff, fmt.Sprintf("\t+ %s", added))
+	}
+
+	if len(ad.DeletedLocally) > 0 {
+		diff = append(diff, "Removed Resources:")
+	}
+	for _, deleted := range ad.DeletedLocally {
+		diff = append(diff, fmt.Sprintf("\t- %s", deleted))
+	}
+
+	if len(ad.ModifiedLocally) > 0 {
+		diff = append(diff, "Modified Resources:")
+	}
+	for _, modified := range ad.ModifiedLocally {
+		diff = append(diff, fmt.Sprintf("\t* %s", modified))
+	}
+
+	if len(ad.RenamedLocally) > 0 {
+		diff = append(diff, "Renamed Resources:")
+	}
+	for _, renamed := range ad.RenamedLocally {
+		diff = append(diff, fmt.Sprintf("\t~ %s", renamed))
+	}
+
+	if len(ad.SyncChanges) > 0 {
+		diff = append(diff, "Sync Changes (these may disrupt connected clients):")
+	}
+	for _, change := range ad.SyncChanges {
+		diff = append(diff, fmt.Sprintf("\t! %s", change), "\t\t"+change.Risk)
+	}
+
+	return append(diff, DestructiveSchemaChangesDiff(ad.SchemaChanges)...)
+}
+
+// Changes returns the diff as a list of AppChanges, using paths to link each change to the local
+// file which produced it. Resources which were removed locally have no path
+func (ad *AppDiffs) Changes(paths map[AppResource]string) []AppChange {
+	changes := []AppChange{}
+
+	for _, diff := range []struct {
+		change    string
+		resources []AppResource
+	}{
+		{AppChangeAdded, ad.AddedLocally},
+		{AppChangeRemoved, ad.DeletedLocally},
+		{AppChangeModified, ad.ModifiedLocally},
+	} {
+		for _, resource := range diff.resources {
+			change := AppChange{Change: diff.change, Kind: resource.Kind, Name: resource.Name}
+			if diff.change != AppChangeRemoved {
+				change.Path = paths[resource]
+			}
+			changes = append(changes, change)
+		}
+	}
+
+	for _, renamed := range ad.RenamedLocally {
+		changes = append(changes, AppChange{
+			Change:       AppChangeRenamed,
+			Kind:         renamed.Kind,
+			Name:         renamed.To,
+			Path:         paths[AppResource{renamed.Kind, renamed.To}],
+			PreviousName: renamed.From,
+			Modified:     renamed.Modified,
+		})
+	}
+
+	for _, change := range ad.SyncChanges {
+		changes = append(changes, AppChange{
+			Change: AppChangeModified,
+			Kind:   SyncChangeKind,
+			Name:   change.Field,
+			Path:   paths[change.Resource],
+			Risk:   change.Risk,
+		})
+	}
+
+	for _, change := range ad.SchemaChanges {
+		changeKind := AppChangeModified
+		if change.Field == "" {
+			changeKind = AppChangeRemoved
+		}
+		changes = append(changes, AppChange{
+			Change:      changeKind,
+			Kind:        SchemaChangeKind,
+			Name:        change.Name(),
+			Path:        paths[change.Resource],
+			Risk:        change.Risk,
+			Destructive: true,
+		})
+	}
+
+	return changes
+}
+
+// AppResourcePaths returns the path of the local file which defines each resource of the app
+// in the given directory, as named by DiffApps. Paths are relative to the app directory and
+// use forward slashes so they are portable across platforms
+func AppResourcePaths(appPath string) (map[AppResource]string, error) {
+	paths := map[AppResource]string{}
+
+	add := func(kind, name string, elem ...string) {
+		paths[AppResource{kind, name}] = filepath.ToSlash(filepath.Join(elem...))
+	}
+
+	var appConfig map[string]interface{}
+	if err := readAndUnmarshalJSONInto(filepath.Join(appPath, appConfigName+jsonExt), &appConfig); err != nil {
+		return nil, err
+	}
+
+	realmIgnore, err := ReadRealmIgnoreFile(appPath)
+	if err != nil {
+		return nil, err
+	}
+	for key := range appConfig {
+		add(appConfigKind, key, appConfigName+jsonExt)
+	}
+	if _, err := os.Stat(filepath.Join(appPath, secretsName+jsonExt)); err == nil {
+		add(appConfigKind, secretsName, secretsName+jsonExt)
+	}
+	if _, err := os.Stat(filepath.Join(appPath, environmentsName)); err == nil {
+		add(appConfigKind, environmentsName, environmentsName)
+	}
+	if _, err := os.Stat(filepath.Join(appPath, graphQLName, configName+jsonExt)); err == nil {
+		add(appConfigKind, graphQLName+"."+configName, graphQLName, configName+jsonExt)
+	}
+
+	for _, files := range []struct {
+		kind string
+		dir  string
+		name func(resource map[string]interface{}) string
+	}{
+		{"value", valuesName, nameField},
+		{"auth provider", authProvidersName, nameField},
+		{"trigger", triggersName, nameField},
+		{"custom resolver", filepath.Join(graphQLName, customResolversName), customResolverName},
+	} {
+		fileInfos, err := ioutil.ReadDir(filepath.Join(appPath, files.dir))
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		fileInfos = realmIgnore.filter(filepath.Join(appPath, files.dir), fileInfos)
+
+		for _, fileInfo := range fileInfos {
+			if fileInfo.IsDir() || filepath.Ext(fileInfo.Name()) != jsonExt {
+				continue
+			}
+
+			var resource map[string]interface{}
+			if err := readAndUnmarshalJSONInto(filepath.Join(appPath, files.dir, fileInfo.Name()), &resource); err != nil {
+				return nil, err
+			}
+			add(files.kind, files.name(resource), files.dir, fileInfo.Name())
+		}
+	}
+
+	for _, dirs := range []struct {
+		kind string
+		dir  string
+		file string
+	}{
+		{"function", FunctionsRoot, sourceName + jsExt},
+		{"service", servicesName, configName + jsonExt},
+	} {
+		fileInfos, err := ioutil.ReadDir(filepath.Join(appPath, dirs.dir))
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		fileInfos = realmIgnore.filter(filepath.Join(appPath, dirs.dir), fileInfos)
+
+		err = iterDirectories(func(info os.FileInfo, path string) error {
+			var config map[string]interface{}
+			if err := readAndUnmarshalJSONInto(filepath.Join(path, configName+jsonExt), &config); err != nil {
+				if os.IsNotExist(err) {
+					// e.g. node_modules, which is not a resource of its own
+					return nil
+				}
+				return err
+			}
+			add(dirs.kind, nameField(config), dirs.dir, info.Name(), dirs.file)
+			return nil
+		}, filepath.Join(appPath, dirs.dir), fileInfos)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return paths, nil
+}
+
+// ResourceRootPath returns the path of the file or directory which holds all of the given resource, given
+// the path of the file which defines it as returned by AppResourcePaths. Functions and services are kept
+// in directories of their own, while the other resources are single files
+func ResourceRootPath(resource AppResource, path string) string {
+	switch resource.Kind {
+	case "function", "service":
+		return filepath.ToSlash(filepath.Dir(filepath.FromSlash(path)))
+	}
+	return path
+}
+
+// UnmarshalFromZip unpacks an exported Realm app archive into a temporary directory
+// and unmarshals it the same way as UnmarshalFromDir
+func UnmarshalFromZip(zipData io.Reader) (map[string]interface{}, error) {
+	dir, err := ioutil.TempDir("", "realm-app-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	if err := WriteZipToDir(dir, zipData, true); err != nil {
+		return nil, err
+	}
+
+	return UnmarshalFromDir(dir)
+}
+
+// AppThreeWayDiffs splits the changes made to an app since a common baseline into those made
+// only locally, those made only remotely, and those made on both sides in different ways
+type AppThreeWayDiffs struct {
+	Mine        *AppDiffs
+	Theirs      *AppDiffs
+	Conflicting *AppDiffs
+}
+
+// DiffAppsThreeWay compares a local and remote app configuration against the baseline they
+// both started from, e.g. a previous export of the app. Changes are described relative to
+// the baseline, and resources changed the same way on both sides are not reported
+func DiffAppsThreeWay(local, remote, baseline map[string]interface{}) *AppThreeWayDiffs {
+	mine, theirs := DiffApps(local, baseline, false), DiffApps(remote, baseline, false)
+	localResources, remoteResources := indexAppResources(local), indexAppResources(remote)
+
+	changedLocally, changedRemotely := mine.resources(), theirs.resources()
+
+	diffs := &AppThreeWayDiffs{&AppDiffs{}, &AppDiffs{}, &AppDiffs{}}
+	for _, lists := range []struct {
+		mine, theirs                 []AppResource
+		toMine, toTheirs, toConflict *[]AppResource
+	}{
+		{mine.AddedLocally, theirs.AddedLocally, &diffs.Mine.AddedLocally, &diffs.Theirs.AddedLocally, &diffs.Conflicting.AddedLocally},
+		{mine.DeletedLocally, theirs.DeletedLocally, &diffs.Mine.DeletedLocally, &diffs.Theirs.DeletedLocally, &diffs.Conflicting.DeletedLocally},
+		{mine.ModifiedLocally, theirs.ModifiedLocally, &diffs.Mine.ModifiedLocally, &diffs.Theirs.ModifiedLocally, &diffs.Conflicting.ModifiedLocally},
+	} {
+		for _, resource := range lists.mine {
+			if !changedRemotely[resource] {
+				*lists.toMine = append(*lists.toMine, resource)
+			} else if !reflect.DeepEqual(localResources[resource], remoteResources[resource]) {
+				*lists.toConflict = append(*lists.toConflict, resource)
+			}
+		}
+		for _, resource := range lists.theirs {
+			if !changedLocally[resource] {
+				*lists.toTheirs = append(*lists.toTheirs, resource)
+			}
+		}
+	}
+
+	return diffs
+}
+
+// Diff returns a list of strings representing the three-way diff
+func (ad *AppThreeWayDiffs) Diff() []string {
+	var diff []string
+
+	for _, bucket := range []struct {
+		title string
+		diffs *AppDiffs
+	}{
+		{"Local Changes:", ad.Mine},
+		{"Remote Changes:", ad.Theirs},
+		{"Conflicting Changes:", ad.Conflicting},
+	} {
+		lines := bucket.diffs.Diff()
+		if len(lines) == 0 {
+			continue
+		}
+
+		diff = append(diff, bucket.title)
+		for _, line := range lines {
+			diff = append(diff, "\t"+line)
+		}
+	}
+
+	return diff
+}
+
+// resources returns the set of resources changed in any way
+func (ad *AppDiffs) resources() map[AppResource]bool {
+	resources := map[AppResource]bool{}
+	for _, list := range [][]AppResource{ad.AddedLocally, ad.DeletedLocally, ad.ModifiedLocally} {
+		for _, resource := range list {
+			resources[resource] = true
+		}
+	}
+	return resources
+}
+
+// indexAppResources returns every resource of the app configuration, named the same way as by DiffApps
+func indexAppResources(app map[string]interface{}) map[AppResource]interface{} {
+	resources := map[AppResource]interface{}{}
+
+	for _, resourceList := range appResourceLists {
+		for _, r := range resourceList.lookup(app) {
+			if resource, ok := r.(map[string]interface{}); ok {
+				resources[AppResource{resourceList.kind, resourceList.name(resource)}] = resource
+			}
+		}
+	}
+
+	for key, value := range appConfigFields(app) {
+		resources[AppResource{appConfigKind, key}] = value
+	}
+
+	return resources
+}
@@ -0,0 +1,345 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/10gen/realm-cli/utils"
+	u "github.com/10gen/realm-cli/utils/test"
+	gc "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDiffApps(t *testing.T) {
+	loadApps := func(t *testing.T) (map[string]interface{}, map[string]interface{}) {
+		local, err := utils.UnmarshalFromDir("../testdata/full_app")
+		u.So(t, err, gc.ShouldBeNil)
+
+		remote, err := utils.UnmarshalFromDir("../testdata/full_app")
+		u.So(t, err, gc.ShouldBeNil)
+
+		return local, remote
+	}
+
+	t.Run("reports no changes for identical apps", func(t *testing.T) {
+		local, remote := loadApps(t)
+
+		diffs := utils.DiffApps(local, remote, false)
+		u.So(t, diffs.Diff(), gc.ShouldBeEmpty)
+	})
+
+	t.Run("reports added, removed and modified resources by name", func(t *testing.T) {
+		local, remote := loadApps(t)
+
+		remoteFunctions := remote["functions"].([]interface{})
+		remote["functions"] = remoteFunctions[:1]
+
+		localValues := local["values"].([]interface{})
+		local["values"] = localValues[1:]
+
+		localTriggers := local["triggers"].([]interface{})
+		localTrigger := localTriggers[0].(map[string]interface{})
+		localTrigger["disabled"] = localTrigger["disabled"] != true
+
+		local["name"] = "renamed-app"
+
+		removedValue := localValues[0].(map[string]interface{})["name"].(string)
+		addedFunction := remoteFunctions[1].(map[string]interface{})["config"].(map[string]interface{})["name"].(string)
+		modifiedTrigger := localTrigger["name"].(string)
+
+		diffs := utils.DiffApps(local, remote, false)
+		u.So(t, diffs.AddedLocally, gc.ShouldResemble, []utils.AppResource{{Kind: "function", Name: addedFunction}})
+		u.So(t, diffs.DeletedLocally, gc.ShouldResemble, []utils.AppResource{{Kind: "value", Name: removedValue}})
+		u.So(t, diffs.ModifiedLocally, gc.ShouldResemble, []utils.AppResource{{Kind: "app config", Name: "name"}, {Kind: "trigger", Name: modifiedTrigger}})
+
+		u.So(t, diffs.Diff(), gc.ShouldResemble, []string{
+			"New Resources:",
+			"\t+ function: " + addedFunction,
+			"Removed Resources:",
+			"\t- value: " + removedValue,
+			"Modified Resources:",
+			"\t* app config: name",
+			"\t* trigger: " + modifiedTrigger,
+		})
+	})
+
+	t.Run("ignores removed resources when merging", func(t *testing.T) {
+		local, remote := loadApps(t)
+
+		localValues := local["values"].([]interface{})
+		local["values"] = localValues[1:]
+
+		diffs := utils.DiffApps(local, remote, true)
+		u.So(t, diffs.Diff(), gc.ShouldBeEmpty)
+	})
+}
+
+func TestDiffAppsSync(t *testing.T) {
+	loadApps := func(t *testing.T) (map[string]interface{}, map[string]interface{}) {
+		local, err := utils.UnmarshalFromDir("../testdata/template_app_with_cluster")
+		u.So(t, err, gc.ShouldBeNil)
+
+		remote, err := utils.UnmarshalFromDir("../testdata/template_app_with_cluster")
+		u.So(t, err, gc.ShouldBeNil)
+
+		return local, remote
+	}
+
+	serviceConfig := func(app map[string]interface{}) map[string]interface{} {
+		service := app["services"].([]interface{})[0].(map[string]interface{})
+		return service["config"].(map[string]interface{})["config"].(map[string]interface{})
+	}
+
+	t.Run("reports toggling development mode as a sync change", func(t *testing.T) {
+		local, remote := loadApps(t)
+
+		local["sync"].(map[string]interface{})["development_mode_enabled"] = true
+
+		diffs := utils.DiffApps(local, remote, false)
+		u.So(t, diffs.ModifiedLocally, gc.ShouldResemble, []utils.AppResource{{Kind: "app config", Name: "sync"}})
+		u.So(t, diffs.SyncChanges, gc.ShouldHaveLength, 1)
+		u.So(t, diffs.SyncChanges[0].Field, gc.ShouldEqual, "sync.development_mode_enabled")
+		u.So(t, diffs.Diff(), gc.ShouldResemble, []string{
+			"Modified Resources:",
+			"\t* app config: sync",
+			"Sync Changes (these may disrupt connected clients):",
+			"\t! sync.development_mode_enabled: false -> true",
+			"\t\ttoggles whether clients may change the schema of synced collections",
+		})
+
+		u.So(t, diffs.Changes(map[utils.AppResource]string{{Kind: "app config", Name: "sync"}: "config.json"}), gc.ShouldContain, utils.AppChange{
+			Change: utils.AppChangeModified,
+			Kind:   utils.SyncChangeKind,
+			Name:   "sync.development_mode_enabled",
+			Path:   "config.json",
+			Risk:   "toggles whether clients may change the schema of synced collections",
+		})
+	})
+
+	t.Run("reports changes to the sync configuration of a service", func(t *testing.T) {
+		local, remote := loadApps(t)
+
+		serviceConfig(local)["sync"] = map[string]interface{}{"state": "enabled", "partition": map[string]interface{}{"key": "owner_id"}}
+		serviceConfig(remote)["sync"] = map[string]interface{}{"state": "enabled", "partition": map[string]interface{}{"key": "_partition"}}
+
+		diffs := utils.DiffApps(local, remote, false)
+		u.So(t, diffs.SyncChanges, gc.ShouldResemble, []utils.SyncChange{{
+			Resource: utils.AppResource{Kind: "service", Name: "mongodb-atlas"},
+			Field:    "services.mongodb-atlas.config.sync.partition",
+			From:     map[string]interface{}{"key": "_partition"},
+			To:       map[string]interface{}{"key": "owner_id"},
+			Risk:     "changing the partition key terminates Sync, which requires clients to reset",
+		}})
+	})
+
+	t.Run("leaves sync configurations missing locally alone when merging", func(t *testing.T) {
+		local, remote := loadApps(t)
+
+		delete(local, "sync")
+
+		u.So(t, utils.DiffApps(local, remote, true).SyncChanges, gc.ShouldBeEmpty)
+		u.So(t, utils.DiffApps(local, remote, false).SyncChanges, gc.ShouldHaveLength, 1)
+	})
+}
+
+func TestDetectRenames(t *testing.T) {
+	loadApps := func(t *testing.T) (map[string]interface{}, map[string]interface{}, string) {
+		local, err := utils.UnmarshalFromDir("../testdata/full_app")
+		u.So(t, err, gc.ShouldBeNil)
+
+		remote, err := utils.UnmarshalFromDir("../testdata/full_app")
+		u.So(t, err, gc.ShouldBeNil)
+
+		localFunction := local["functions"].([]interface{})[0].(map[string]interface{})
+		localConfig := localFunction["config"].(map[string]interface{})
+		name := localConfig["name"].(string)
+		localConfig["name"] = "renamed_function"
+
+		return local, remote, name
+	}
+
+	t.Run("reports a removed and an added resource with the same contents as a rename", func(t *testing.T) {
+		local, remote, name := loadApps(t)
+
+		diffs := utils.DiffApps(local, remote, false)
+		diffs.DetectRenames(local, remote, utils.DefaultRenameThreshold)
+
+		u.So(t, diffs.AddedLocally, gc.ShouldBeEmpty)
+		u.So(t, diffs.DeletedLocally, gc.ShouldBeEmpty)
+		u.So(t, diffs.RenamedLocally, gc.ShouldResemble, []utils.AppRename{{Kind: "function", From: name, To: "renamed_function"}})
+		u.So(t, diffs.Diff(), gc.ShouldResemble, []string{
+			"Renamed Resources:",
+			"\t~ function: " + name + " -> renamed_function",
+		})
+
+		u.So(t, diffs.Changes(nil), gc.ShouldResemble, []utils.AppChange{
+			{Change: utils.AppChangeRenamed, Kind: "function", Name: "renamed_function", PreviousName: name},
+		})
+	})
+
+	t.Run("reports a rename whose contents changed as well as modified", func(t *testing.T) {
+		local, remote, name := loadApps(t)
+
+		localFunction := local["functions"].([]interface{})[0].(map[string]interface{})
+		localFunction["source"] = localFunction["source"].(string) + "\n// renamed\n"
+
+		diffs := utils.DiffApps(local, remote, false)
+		diffs.DetectRenames(local, remote, utils.DefaultRenameThreshold)
+
+		u.So(t, diffs.RenamedLocally, gc.ShouldResemble, []utils.AppRename{{Kind: "function", From: name, To: "renamed_function", Modified: true}})
+		u.So(t, diffs.Diff(), gc.ShouldResemble, []string{
+			"Renamed Resources:",
+			"\t~ function: " + name + " -> renamed_function (modified)",
+		})
+
+		u.So(t, diffs.Changes(nil), gc.ShouldResemble, []utils.AppChange{
+			{Change: utils.AppChangeRenamed, Kind: "function", Name: "renamed_function", PreviousName: name, Modified: true},
+		})
+	})
+
+	t.Run("leaves resources whose contents are not similar enough as added and removed", func(t *testing.T) {
+		local, remote, name := loadApps(t)
+
+		localFunction := local["functions"].([]interface{})[0].(map[string]interface{})
+		localFunction["source"] = "exports = function() {\n  return 'something else entirely';\n};\n"
+
+		diffs := utils.DiffApps(local, remote, false)
+		diffs.DetectRenames(local, remote, utils.DefaultRenameThreshold)
+
+		u.So(t, diffs.RenamedLocally, gc.ShouldBeEmpty)
+		u.So(t, diffs.AddedLocally, gc.ShouldResemble, []utils.AppResource{{Kind: "function", Name: "renamed_function"}})
+		u.So(t, diffs.DeletedLocally, gc.ShouldResemble, []utils.AppResource{{Kind: "function", Name: name}})
+	})
+}
+
+func TestDiffAppsThreeWay(t *testing.T) {
+	loadApp := func(t *testing.T) map[string]interface{} {
+		app, err := utils.UnmarshalFromDir("../testdata/full_app")
+		u.So(t, err, gc.ShouldBeNil)
+		return app
+	}
+
+	firstTrigger := func(app map[string]interface{}) map[string]interface{} {
+		return app["triggers"].([]interface{})[0].(map[string]interface{})
+	}
+
+	t.Run("reports no changes when neither side changed", func(t *testing.T) {
+		diffs := utils.DiffAppsThreeWay(loadApp(t), loadApp(t), loadApp(t))
+		u.So(t, diffs.Diff(), gc.ShouldBeEmpty)
+	})
+
+	t.Run("splits changes by the side that made them", func(t *testing.T) {
+		local, remote, baseline := loadApp(t), loadApp(t), loadApp(t)
+
+		// only changed locally
+		localValues := local["values"].([]interface{})
+		local["values"] = localValues[1:]
+		removedValue := localValues[0].(map[string]interface{})["name"].(string)
+
+		// only changed remotely
+		remoteTrigger := firstTrigger(remote)
+		remoteTrigger["disabled"] = remoteTrigger["disabled"] != true
+
+		// changed on both sides in different ways
+		local["name"] = "local-name"
+		remote["name"] = "remote-name"
+
+		// changed on both sides in the same way
+		local["config_version"] = 1
+		remote["config_version"] = 1
+
+		diffs := utils.DiffAppsThreeWay(local, remote, baseline)
+		u.So(t, diffs.Mine, gc.ShouldResemble, &utils.AppDiffs{
+			DeletedLocally: []utils.AppResource{{Kind: "value", Name: removedValue}},
+		})
+		u.So(t, diffs.Theirs, gc.ShouldResemble, &utils.AppDiffs{
+			ModifiedLocally: []utils.AppResource{{Kind: "trigger", Name: remoteTrigger["name"].(string)}},
+		})
+		u.So(t, diffs.Conflicting, gc.ShouldResemble, &utils.AppDiffs{
+			ModifiedLocally: []utils.AppResource{{Kind: "app config", Name: "name"}},
+		})
+
+		u.So(t, diffs.Diff(), gc.ShouldResemble, []string{
+			"Local Changes:",
+			"\tRemoved Resources:",
+			"\t\t- value: " + removedValue,
+			"Remote Changes:",
+			"\tModified Resources:",
+			"\t\t* trigger: " + remoteTrigger["name"].(string),
+			"Conflicting Changes:",
+			"\tModified Resources:",
+			"\t\t* app config: name",
+		})
+	})
+
+	t.Run("reports a resource removed on one side and modified on the other as conflicting", func(t *testing.T) {
+		local, remote, baseline := loadApp(t), loadApp(t), loadApp(t)
+
+		trigger := firstTrigger(remote)
+		trigger["disabled"] = trigger["disabled"] != true
+		local["triggers"] = local["triggers"].([]interface{})[1:]
+
+		diffs := utils.DiffAppsThreeWay(local, remote, baseline)
+		u.So(t, diffs.Mine.DeletedLocally, gc.ShouldBeEmpty)
+		u.So(t, diffs.Theirs.ModifiedLocally, gc.ShouldBeEmpty)
+		u.So(t, diffs.Conflicting.DeletedLocally, gc.ShouldResemble, []utils.AppResource{{Kind: "trigger", Name: trigger["name"].(string)}})
+	})
+}
+
+func TestUnmarshalFromZip(t *testing.T) {
+	t.Run("loads the same app as UnmarshalFromDir", func(t *testing.T) {
+		fromDir, err := utils.UnmarshalFromDir("../testdata/full_app")
+		u.So(t, err, gc.ShouldBeNil)
+
+		fromZip, err := utils.UnmarshalFromZip(u.NewZipResponseBody("../testdata/full_app"))
+		u.So(t, err, gc.ShouldBeNil)
+
+		u.So(t, fromZip, gc.ShouldResemble, fromDir)
+	})
+}
+
+func TestAppResourcePaths(t *testing.T) {
+	paths, err := utils.AppResourcePaths("../testdata/full_app")
+	u.So(t, err, gc.ShouldBeNil)
+
+	for _, tc := range []struct {
+		resource     utils.AppResource
+		expectedPath string
+	}{
+		{utils.AppResource{Kind: "app config", Name: "name"}, "config.json"},
+		{utils.AppResource{Kind: "app config", Name: "graphql.config"}, "graphql/config.json"},
+		{utils.AppResource{Kind: "function", Name: "function_a"}, "functions/function_a/source.js"},
+		{utils.AppResource{Kind: "service", Name: "service a"}, "services/service_a/config.json"},
+		{utils.AppResource{Kind: "trigger", Name: "dbEventSubscription"}, "triggers/dbEventSubscription.json"},
+		{utils.AppResource{Kind: "custom resolver", Name: "Query.data"}, "graphql/custom_resolvers/query_data.json"},
+	} {
+		u.So(t, paths[tc.resource], gc.ShouldEqual, tc.expectedPath)
+	}
+
+	t.Run("links changes to the local files that produced them", func(t *testing.T) {
+		diffs := &utils.AppDiffs{
+			AddedLocally:    []utils.AppResource{{Kind: "function", Name: "function_a"}},
+			DeletedLocally:  []utils.AppResource{{Kind: "function", Name: "function_c"}},
+			ModifiedLocally: []utils.AppResource{{Kind: "service", Name: "service a"}},
+		}
+
+		u.So(t, diffs.Changes(paths), gc.ShouldResemble, []utils.AppChange{
+			{Change: utils.AppChangeAdded, Kind: "function", Name: "function_a", Path: "functions/function_a/source.js"},
+			{Change: utils.AppChangeRemoved, Kind: "function", Name: "function_c"},
+			{Change: utils.AppChangeModified, Kind: "service", Name: "service a", Path: "services/service_a/config.json"},
+		})
+	})
+}
+
+func TestResourceRootPath(t *testing.T) {
+	for _, tc := range []struct {
+		resource     utils.AppResource
+		path         string
+		expectedPath string
+	}{
+		{utils.AppResource{Kind: "function", Name: "function_a"}, "functions/function_a/source.js", "functions/function_a"},
+		{utils.AppResource{Kind: "service", Name: "service a"}, "services/service_a/config.json", "services/service_a"},
+		{utils.AppResource{Kind: "trigger", Name: "dbEventSubscription"}, "triggers/dbEventSubscription.json", "triggers/dbEventSubscription.json"},
+		{utils.AppResource{Kind: "app config", Name: "name"}, "config.json", "config.json"},
+	} {
+		u.So(t, utils.ResourceRootPath(tc.resource, tc.path), gc.ShouldEqual, tc.expectedPath)
+	}
+}
@@ -0,0 +1,189 @@
+package utils
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// AppEnvironments are the environments an app can have a file of values for in its environments directory
+var AppEnvironments = []string{"no-environment", "development", "testing", "qa", "production"}
+
+// environmentValuesField is the object holding the values of an environment
+const environmentValuesField = "values"
+
+// environmentVariablePattern matches a ${NAME} reference to an environment variable in the values of an
+// environment, along with the $${ escape which leaves a literal ${ in the value
+var environmentVariablePattern = regexp.MustCompile(`\$\$\{|\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// validateEnvironment reports the environment file if it is not named after an environment, or does not
+// hold its values in an object
+func validateEnvironment(relPath string, environment map[string]interface{}) []AppProblem {
+	var problems []AppProblem
+
+	name := strings.TrimSuffix(path.Base(relPath), jsonExt)
+	if !isAppEnvironment(name) {
+		problems = append(problems, AppProblem{
+			Path:    relPath,
+			Message: fmt.Sprintf("%q is not an environment; expected one of [%s]", name, strings.Join(AppEnvironments, "|")),
+		})
+	}
+
+	values, ok := environment[environmentValuesField]
+	if !ok {
+		problems = append(problems, AppProblem{Path: relPath, Field: environmentValuesField, Message: "is required"})
+	} else if _, ok := values.(map[string]interface{}); !ok {
+		problems = append(problems, AppProblem{Path: relPath, Field: environmentValuesField, Message: "must be an object"})
+	}
+
+	return problems
+}
+
+func isAppEnvironment(name string) bool {
+	for _, environment := range AppEnvironments {
+		if name == environment {
+			return true
+		}
+	}
+	return false
+}
+
+// ExpandAppEnvironments checks the environments of the app loaded by UnmarshalFromDir, then replaces each
+// ${NAME} in the string values of the environments with the value lookup gives for the variable NAME, e.g.
+// to inject a secret from the shell at import time. Every problem found is returned, including the variables
+// lookup cannot resolve, in which case the app must not be imported. The paths of the environment files which
+// were expanded are returned as well, relative to the app directory, as they no longer match the app
+func ExpandAppEnvironments(app map[string]interface{}, lookup func(name string) (string, bool)) ([]string, []AppProblem) {
+	environments, _ := app[environmentsName].(map[string]interface{})
+
+	var expanded []string
+	var problems []AppProblem
+	for fileName, raw := range environments {
+		relPath := path.Join(environmentsName, fileName)
+
+		environment, ok := raw.(map[string]interface{})
+		if !ok {
+			problems = append(problems, AppProblem{Path: relPath, Message: "must contain a JSON object"})
+			continue
+		}
+
+		if environmentProblems := validateEnvironment(relPath, environment); len(environmentProblems) > 0 {
+			problems = append(problems, environmentProblems...)
+			continue
+		}
+
+		e := environmentExpansion{relPath: relPath, lookup: lookup}
+		environment[environmentValuesField] = e.expand(environmentValuesField, environment[environmentValuesField])
+		if e.expanded {
+			expanded = append(expanded, relPath)
+		}
+		problems = append(problems, e.problems...)
+	}
+
+	sort.Strings(expanded)
+	sort.SliceStable(problems, func(i, j int) bool {
+		if problems[i].Path != problems[j].Path {
+			return problems[i].Path < problems[j].Path
+		}
+		return problems[i].Field < problems[j].Field
+	})
+	return expanded, problems
+}
+
+// environmentExpansion replaces the variables referenced in the values of an environment
+type environmentExpansion struct {
+	relPath  string
+	lookup   func(name string) (string, bool)
+	expanded bool
+	problems []AppProblem
+}
+
+// expand returns the value with the variables of its strings replaced, along with those of the objects and
+// arrays nested within it
+func (e *environmentExpansion) expand(field string, value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return e.expandString(field, v)
+	case map[string]interface{}:
+		for key, nested := range v {
+			v[key] = e.expand(field+"."+key, nested)
+		}
+	case []interface{}:
+		for i, nested := range v {
+			v[i] = e.expand(fmt.Sprintf("%s.%d", field, i), nested)
+		}
+	}
+	return value
+}
+
+func (e *environmentExpansion) expandString(field, s string) string {
+	return environmentVariablePattern.ReplaceAllStringFunc(s, func(match string) string {
+		e.expanded = true
+		if match == "$${" {
+			return "${"
+		}
+
+		name := environmentVariablePattern.FindStringSubmatch(match)[1]
+		value, ok := e.lookup(name)
+		if !ok {
+			e.problems = append(e.problems, AppProblem{
+				Path:    e.relPath,
+				Field:   field,
+				Message: fmt.Sprintf("environment variable %s is not set", name),
+			})
+		}
+		return value
+	})
+}
+
+// TemplatedEnvironments returns the contents of the environment files of the app at appPath which reference
+// environment variables, by their paths relative to the app directory. The deployed app holds the values the
+// variables were expanded to on import, e.g. secrets, so these files are kept as they are when it is written
+// over the local one
+func TemplatedEnvironments(appPath string) (map[string][]byte, error) {
+	fileInfos, err := ioutil.ReadDir(filepath.Join(appPath, environmentsName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	templates := map[string][]byte{}
+	for _, fileInfo := range fileInfos {
+		if fileInfo.IsDir() || filepath.Ext(fileInfo.Name()) != jsonExt {
+			continue
+		}
+
+		data, err := ioutil.ReadFile(filepath.Join(appPath, environmentsName, fileInfo.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if environmentVariablePattern.Match(data) {
+			templates[path.Join(environmentsName, fileInfo.Name())] = data
+		}
+	}
+	return templates, nil
+}
+
+// KeepAppEnvironments replaces the environments of app, as loaded by UnmarshalFromDir, at the given paths
+// relative to the app directory with those of the local app, so that comparing them finds no change
+func KeepAppEnvironments(app, localApp map[string]interface{}, relPaths []string) {
+	environments, _ := app[environmentsName].(map[string]interface{})
+	localEnvironments, _ := localApp[environmentsName].(map[string]interface{})
+	if environments == nil || localEnvironments == nil {
+		return
+	}
+
+	for _, relPath := range relPaths {
+		fileName := path.Base(relPath)
+		if local, ok := localEnvironments[fileName]; ok {
+			environments[fileName] = local
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package utils_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/10gen/realm-cli/utils"
+	u "github.com/10gen/realm-cli/utils/test"
+	gc "github.com/smartystreets/goconvey/convey"
+)
+
+func TestExpandAppEnvironments(t *testing.T) {
+	variables := map[string]string{"API_KEY": "s3cr3t", "HOST": "example.com"}
+	lookup := func(name string) (string, bool) {
+		value, ok := variables[name]
+		return value, ok
+	}
+
+	t.Run("replaces the variables in the values of each environment", func(t *testing.T) {
+		app := map[string]interface{}{
+			"environments": map[string]interface{}{
+				"production.json": map[string]interface{}{
+					"values": map[string]interface{}{
+						"apiKey":  "${API_KEY}",
+						"urls":    []interface{}{"https://${HOST}/api", "$${HOST}"},
+						"retries": 3.0,
+					},
+				},
+				"development.json": map[string]interface{}{
+					"values": map[string]interface{}{"apiKey": "dev"},
+				},
+			},
+		}
+
+		expanded, problems := utils.ExpandAppEnvironments(app, lookup)
+		u.So(t, problems, gc.ShouldBeEmpty)
+		u.So(t, expanded, gc.ShouldResemble, []string{"environments/production.json"})
+		u.So(t, app["environments"], gc.ShouldResemble, map[string]interface{}{
+			"production.json": map[string]interface{}{
+				"values": map[string]interface{}{
+					"apiKey":  "s3cr3t",
+					"urls":    []interface{}{"https://example.com/api", "${HOST}"},
+					"retries": 3.0,
+				},
+			},
+			"development.json": map[string]interface{}{
+				"values": map[string]interface{}{"apiKey": "dev"},
+			},
+		})
+	})
+
+	t.Run("reports every variable which is not set", func(t *testing.T) {
+		app := map[string]interface{}{
+			"environments": map[string]interface{}{
+				"qa.json": map[string]interface{}{
+					"values": map[string]interface{}{
+						"db": map[string]interface{}{"password": "${DB_PASSWORD}", "user": "${DB_USER}"},
+					},
+				},
+			},
+		}
+
+		_, problems := utils.ExpandAppEnvironments(app, lookup)
+		u.So(t, problems, gc.ShouldResemble, []utils.AppProblem{
+			{Path: "environments/qa.json", Field: "values.db.password", Message: "environment variable DB_PASSWORD is not set"},
+			{Path: "environments/qa.json", Field: "values.db.user", Message: "environment variable DB_USER is not set"},
+		})
+	})
+
+	t.Run("reports environments without values or with an unknown name", func(t *testing.T) {
+		app := map[string]interface{}{
+			"environments": map[string]interface{}{
+				"staging.json":    map[string]interface{}{"values": map[string]interface{}{}},
+				"testing.json":    map[string]interface{}{"values": []interface{}{}},
+				"production.json": map[string]interface{}{},
+			},
+		}
+
+		_, problems := utils.ExpandAppEnvironments(app, lookup)
+		u.So(t, problems, gc.ShouldResemble, []utils.AppProblem{
+			{Path: "environments/production.json", Field: "values", Message: "is required"},
+			{Path: "environments/staging.json", Message: `"staging" is not an environment; expected one of [no-environment|development|testing|qa|production]`},
+			{Path: "environments/testing.json", Field: "values", Message: "must be an object"},
+		})
+	})
+
+	t.Run("leaves an app without environments alone", func(t *testing.T) {
+		expanded, problems := utils.ExpandAppEnvironments(map[string]interface{}{}, lookup)
+		u.So(t, expanded, gc.ShouldBeEmpty)
+		u.So(t, problems, gc.ShouldBeEmpty)
+	})
+}
+
+func TestTemplatedEnvironments(t *testing.T) {
+	t.Run("returns the environment files which reference variables", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "realm-environments-")
+		u.So(t, err, gc.ShouldBeNil)
+		defer os.RemoveAll(dir)
+
+		u.So(t, os.Mkdir(filepath.Join(dir, "environments"), os.ModePerm), gc.ShouldBeNil)
+		for name, contents := range map[string]string{
+			"production.json":  `{"values":{"apiKey":"${API_KEY}"}}`,
+			"testing.json":     `{"values":{"price":"$${PRICE}"}}`,
+			"development.json": `{"values":{"apiKey":"dev"}}`,
+		} {
+			u.So(t, ioutil.WriteFile(filepath.Join(dir, "environments", name), []byte(contents), 0644), gc.ShouldBeNil)
+		}
+
+		templates, err := utils.TemplatedEnvironments(dir)
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, templates, gc.ShouldResemble, map[string][]byte{
+			"environments/production.json": []byte(`{"values":{"apiKey":"${API_KEY}"}}`),
+			"environments/testing.json":    []byte(`{"values":{"price":"$${PRICE}"}}`),
+		})
+	})
+
+	t.Run("returns nothing for an app without environments", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "realm-environments-")
+		u.So(t, err, gc.ShouldBeNil)
+		defer os.RemoveAll(dir)
+
+		templates, err := utils.TemplatedEnvironments(dir)
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, templates, gc.ShouldBeEmpty)
+	})
+}
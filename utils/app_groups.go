@@ -0,0 +1,44 @@
+package utils
+
+// AppGroups are the groups of entities of an app which can be imported on their own
+var AppGroups = []string{authProvidersName, FunctionsRoot, graphQLName, servicesName, triggersName, valuesName}
+
+// IsAppGroup returns whether the name is one of the AppGroups
+func IsAppGroup(name string) bool {
+	for _, group := range AppGroups {
+		if group == name {
+			return true
+		}
+	}
+	return false
+}
+
+// SelectAppGroups returns the deployed app with the given groups of entities taken from the local app instead,
+// so that importing it only changes those groups. The secrets of the local app are kept as well, since its
+// services may refer to them. Without a deployed app, the local app is returned without the other groups
+func SelectAppGroups(local, deployed map[string]interface{}, groups []string) map[string]interface{} {
+	selected := make(map[string]interface{}, len(local))
+	if deployed != nil {
+		for field, value := range deployed {
+			selected[field] = value
+		}
+		if secrets, ok := local[secretsName]; ok {
+			selected[secretsName] = secrets
+		}
+	} else {
+		for field, value := range local {
+			if !IsAppGroup(field) {
+				selected[field] = value
+			}
+		}
+	}
+
+	for _, group := range groups {
+		if value, ok := local[group]; ok {
+			selected[group] = value
+		} else {
+			delete(selected, group)
+		}
+	}
+	return selected
+}
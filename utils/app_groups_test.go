@@ -0,0 +1,42 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/10gen/realm-cli/utils"
+	u "github.com/10gen/realm-cli/utils/test"
+	gc "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSelectAppGroups(t *testing.T) {
+	local := map[string]interface{}{
+		"name":      "local-app",
+		"secrets":   map[string]interface{}{"values": map[string]interface{}{}},
+		"functions": []interface{}{"local-function"},
+		"values":    []interface{}{"local-value"},
+	}
+
+	t.Run("takes the given groups from the local app and the rest from the deployed app", func(t *testing.T) {
+		selected := utils.SelectAppGroups(local, map[string]interface{}{
+			"name":      "deployed-app",
+			"functions": []interface{}{"deployed-function"},
+			"triggers":  []interface{}{"deployed-trigger"},
+			"values":    []interface{}{"deployed-value"},
+		}, []string{"functions", "triggers"})
+
+		u.So(t, selected, gc.ShouldResemble, map[string]interface{}{
+			"name":      "deployed-app",
+			"secrets":   map[string]interface{}{"values": map[string]interface{}{}},
+			"functions": []interface{}{"local-function"},
+			"values":    []interface{}{"deployed-value"},
+		})
+	})
+
+	t.Run("leaves out the other groups without a deployed app", func(t *testing.T) {
+		u.So(t, utils.SelectAppGroups(local, nil, []string{"values"}), gc.ShouldResemble, map[string]interface{}{
+			"name":    "local-app",
+			"secrets": map[string]interface{}{"values": map[string]interface{}{}},
+			"values":  []interface{}{"local-value"},
+		})
+	})
+}
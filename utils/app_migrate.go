@@ -0,0 +1,221 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The config versions an app can be migrated between with MigrateApp
+const (
+	AppConfigVersion20180301 = "20180301"
+	AppConfigVersion20200603 = "20200603"
+	AppConfigVersion20210101 = "20210101"
+)
+
+const (
+	appConfigVersionField         = "config_version"
+	appSecurityField              = "security"
+	appAllowedRequestOriginsField = "allowed_request_origins"
+	appAuthField                  = "auth"
+	appAuthProvidersField         = "providers"
+	appCustomUserDataField        = "custom_user_data_config"
+	appAuthCustomUserDataField    = "custom_user_data"
+	appSyncField                  = "sync"
+	appSyncConfigField            = "config"
+)
+
+// AppConfigVersions are the config versions an app can be migrated between, oldest first
+var AppConfigVersions = []string{AppConfigVersion20180301, AppConfigVersion20200603, AppConfigVersion20210101}
+
+// MigrateApp converts the configuration of a loaded app from the config version it declares into the given one,
+// returning a copy of the app. The app level fields which differ between versions are mapped onto one another:
+//   - the "auth_providers" list of 20180301 and 20200603 and the "auth.providers" map of 20210101, keyed by name
+//   - "security.allowed_request_origins" and the "allowed_request_origins" of 20210101
+//   - "custom_user_data_config" and the "auth.custom_user_data" of 20210101
+//   - the "sync" settings and the "sync.config" of 20210101
+//
+// Any other resources of the app are carried over as they are. An app which uses something the target version
+// cannot express, such as GraphQL before 20200603, is rejected rather than migrated without it.
+// Apps which do not declare their config version are taken to be 20200603
+func MigrateApp(app map[string]interface{}, target string) (map[string]interface{}, error) {
+	if !isAppConfigVersion(target) {
+		return nil, fmt.Errorf("unknown config version %q; accepted values are [%s]", target, strings.Join(AppConfigVersions, "|"))
+	}
+
+	source := AppConfigVersion20200603
+	switch version := app[appConfigVersionField].(type) {
+	case nil:
+	case float64:
+		source = strconv.FormatFloat(version, 'f', -1, 64)
+	case string:
+		source = version
+	}
+	if !isAppConfigVersion(source) {
+		return nil, fmt.Errorf("cannot migrate an app declaring %s %s", appConfigVersionField, source)
+	}
+
+	migrated, err := copyApp(app)
+	if err != nil {
+		return nil, err
+	}
+
+	if source == AppConfigVersion20210101 && target != AppConfigVersion20210101 {
+		if err := migrateAppFromRealmConfig(migrated); err != nil {
+			return nil, err
+		}
+	}
+
+	if target == AppConfigVersion20180301 {
+		if graphQL, ok := migrated[graphQLName].(map[string]interface{}); ok {
+			config, _ := graphQL[configName].(map[string]interface{})
+			customResolvers, _ := graphQL[customResolversName].([]interface{})
+			if len(config) > 0 || len(customResolvers) > 0 {
+				return nil, fmt.Errorf("cannot migrate the app to %s %s without losing its GraphQL configuration, which that version does not support", appConfigVersionField, target)
+			}
+		}
+		delete(migrated, graphQLName)
+	}
+
+	if target == AppConfigVersion20210101 && source != AppConfigVersion20210101 {
+		if err := migrateAppToRealmConfig(migrated); err != nil {
+			return nil, err
+		}
+	}
+
+	number, err := strconv.ParseFloat(target, 64)
+	if err != nil {
+		return nil, err
+	}
+	migrated[appConfigVersionField] = number
+	return migrated, nil
+}
+
+func isAppConfigVersion(version string) bool {
+	for _, v := range AppConfigVersions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// copyApp deep copies the app, so that migrating it leaves the original alone
+func copyApp(app map[string]interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(app)
+	if err != nil {
+		return nil, err
+	}
+
+	var copied map[string]interface{}
+	if err := json.Unmarshal(data, &copied); err != nil {
+		return nil, err
+	}
+	return copied, nil
+}
+
+// migrateAppToRealmConfig moves the app level fields of a 20180301 or 20200603 app to where 20210101 has them
+func migrateAppToRealmConfig(app map[string]interface{}) error {
+	if security, ok := app[appSecurityField].(map[string]interface{}); ok {
+		for field := range security {
+			if field != appAllowedRequestOriginsField {
+				return fmt.Errorf("cannot migrate the app to %s %s without losing %s.%s", appConfigVersionField, AppConfigVersion20210101, appSecurityField, field)
+			}
+		}
+		if origins, ok := security[appAllowedRequestOriginsField]; ok {
+			app[appAllowedRequestOriginsField] = origins
+		}
+		delete(app, appSecurityField)
+	}
+
+	auth := map[string]interface{}{}
+	if providers, ok := app[authProvidersName].([]interface{}); ok {
+		byName := make(map[string]interface{}, len(providers))
+		for _, provider := range providers {
+			name := nameField(asObject(provider))
+			if name == "" {
+				return fmt.Errorf("cannot migrate an auth provider without a name")
+			}
+			if _, ok := byName[name]; ok {
+				return fmt.Errorf("cannot migrate auth provider %q, which is defined more than once", name)
+			}
+			byName[name] = provider
+		}
+		auth[appAuthProvidersField] = byName
+		delete(app, authProvidersName)
+	}
+	if customUserData, ok := app[appCustomUserDataField]; ok {
+		auth[appAuthCustomUserDataField] = customUserData
+		delete(app, appCustomUserDataField)
+	}
+	if len(auth) > 0 {
+		app[appAuthField] = auth
+	}
+
+	if sync, ok := app[appSyncField].(map[string]interface{}); ok {
+		app[appSyncField] = map[string]interface{}{appSyncConfigField: sync}
+	}
+	return nil
+}
+
+// migrateAppFromRealmConfig moves the app level fields of a 20210101 app to where 20180301 and 20200603 have them
+func migrateAppFromRealmConfig(app map[string]interface{}) error {
+	if origins, ok := app[appAllowedRequestOriginsField]; ok {
+		app[appSecurityField] = map[string]interface{}{appAllowedRequestOriginsField: origins}
+		delete(app, appAllowedRequestOriginsField)
+	}
+
+	if auth, ok := app[appAuthField].(map[string]interface{}); ok {
+		for field := range auth {
+			if field != appAuthProvidersField && field != appAuthCustomUserDataField {
+				return fmt.Errorf("cannot migrate the app from %s %s without losing %s.%s", appConfigVersionField, AppConfigVersion20210101, appAuthField, field)
+			}
+		}
+
+		if providers, ok := auth[appAuthProvidersField].(map[string]interface{}); ok {
+			names := make([]string, 0, len(providers))
+			for name := range providers {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			list := make([]interface{}, 0, len(providers))
+			for _, name := range names {
+				provider := asObject(providers[name])
+				if provider == nil {
+					return fmt.Errorf("cannot migrate auth provider %q, which is not an object", name)
+				}
+				if _, ok := provider["name"]; !ok {
+					provider["name"] = name
+				}
+				list = append(list, provider)
+			}
+			app[authProvidersName] = list
+		}
+		if customUserData, ok := auth[appAuthCustomUserDataField]; ok {
+			app[appCustomUserDataField] = customUserData
+		}
+		delete(app, appAuthField)
+	}
+
+	if sync, ok := app[appSyncField].(map[string]interface{}); ok {
+		for field := range sync {
+			if field != appSyncConfigField {
+				return fmt.Errorf("cannot migrate the app from %s %s without losing %s.%s", appConfigVersionField, AppConfigVersion20210101, appSyncField, field)
+			}
+		}
+		if config, ok := sync[appSyncConfigField]; ok {
+			app[appSyncField] = config
+		} else {
+			delete(app, appSyncField)
+		}
+	}
+	return nil
+}
+
+func asObject(value interface{}) map[string]interface{} {
+	object, _ := value.(map[string]interface{})
+	return object
+}
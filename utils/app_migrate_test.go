@@ -0,0 +1,129 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/10gen/realm-cli/utils"
+	u "github.com/10gen/realm-cli/utils/test"
+	gc "github.com/smartystreets/goconvey/convey"
+)
+
+func TestMigrateApp(t *testing.T) {
+	stitchApp := func() map[string]interface{} {
+		return map[string]interface{}{
+			"config_version": float64(20200603),
+			"name":           "my-app",
+			"security": map[string]interface{}{
+				"allowed_request_origins": []interface{}{"http://localhost:8080"},
+			},
+			"custom_user_data_config": map[string]interface{}{"enabled": false},
+			"sync":                    map[string]interface{}{"development_mode_enabled": true},
+			"auth_providers": []interface{}{
+				map[string]interface{}{"name": "local-userpass", "type": "local-userpass"},
+				map[string]interface{}{"name": "anon-user", "type": "anon-user"},
+			},
+			"graphql": map[string]interface{}{
+				"config":           map[string]interface{}{},
+				"custom_resolvers": []interface{}{},
+			},
+		}
+	}
+
+	realmConfigApp := map[string]interface{}{
+		"config_version":          float64(20210101),
+		"name":                    "my-app",
+		"allowed_request_origins": []interface{}{"http://localhost:8080"},
+		"auth": map[string]interface{}{
+			"custom_user_data": map[string]interface{}{"enabled": false},
+			"providers": map[string]interface{}{
+				"local-userpass": map[string]interface{}{"name": "local-userpass", "type": "local-userpass"},
+				"anon-user":      map[string]interface{}{"name": "anon-user", "type": "anon-user"},
+			},
+		},
+		"sync": map[string]interface{}{
+			"config": map[string]interface{}{"development_mode_enabled": true},
+		},
+		"graphql": map[string]interface{}{
+			"config":           map[string]interface{}{},
+			"custom_resolvers": []interface{}{},
+		},
+	}
+
+	t.Run("moves the app level fields to where 20210101 has them", func(t *testing.T) {
+		app := stitchApp()
+		migrated, err := utils.MigrateApp(app, utils.AppConfigVersion20210101)
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, migrated, gc.ShouldResemble, realmConfigApp)
+
+		u.So(t, app, gc.ShouldResemble, stitchApp())
+	})
+
+	t.Run("moves the app level fields back from where 20210101 has them", func(t *testing.T) {
+		migrated, err := utils.MigrateApp(realmConfigApp, utils.AppConfigVersion20200603)
+		u.So(t, err, gc.ShouldBeNil)
+
+		expected := stitchApp()
+		expected["auth_providers"] = []interface{}{
+			map[string]interface{}{"name": "anon-user", "type": "anon-user"},
+			map[string]interface{}{"name": "local-userpass", "type": "local-userpass"},
+		}
+		u.So(t, migrated, gc.ShouldResemble, expected)
+	})
+
+	t.Run("drops an unused GraphQL configuration when migrating to 20180301", func(t *testing.T) {
+		migrated, err := utils.MigrateApp(realmConfigApp, utils.AppConfigVersion20180301)
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, migrated["config_version"], gc.ShouldEqual, float64(20180301))
+		u.So(t, migrated, gc.ShouldNotContainKey, "graphql")
+	})
+
+	for _, tc := range []struct {
+		Description string
+		App         map[string]interface{}
+		Version     string
+		Err         string
+	}{
+		{
+			Description: "an unknown config version",
+			App:         stitchApp(),
+			Version:     "20190101",
+			Err:         `unknown config version "20190101"; accepted values are [20180301|20200603|20210101]`,
+		},
+		{
+			Description: "an app declaring an unknown config version",
+			App:         map[string]interface{}{"config_version": float64(1)},
+			Version:     utils.AppConfigVersion20210101,
+			Err:         "cannot migrate an app declaring config_version 1",
+		},
+		{
+			Description: "security settings which 20210101 does not have",
+			App: map[string]interface{}{
+				"security": map[string]interface{}{"allowed_request_origins": []interface{}{}, "other": true},
+			},
+			Version: utils.AppConfigVersion20210101,
+			Err:     "cannot migrate the app to config_version 20210101 without losing security.other",
+		},
+		{
+			Description: "auth providers without a name",
+			App: map[string]interface{}{
+				"auth_providers": []interface{}{map[string]interface{}{"type": "anon-user"}},
+			},
+			Version: utils.AppConfigVersion20210101,
+			Err:     "cannot migrate an auth provider without a name",
+		},
+		{
+			Description: "a GraphQL configuration which 20180301 does not support",
+			App: map[string]interface{}{
+				"graphql": map[string]interface{}{"config": map[string]interface{}{"use_natural_pluralization": true}},
+			},
+			Version: utils.AppConfigVersion20180301,
+			Err:     "cannot migrate the app to config_version 20180301 without losing its GraphQL configuration, which that version does not support",
+		},
+	} {
+		t.Run("rejects "+tc.Description, func(t *testing.T) {
+			_, err := utils.MigrateApp(tc.App, tc.Version)
+			u.So(t, err, gc.ShouldNotBeNil)
+			u.So(t, err.Error(), gc.ShouldEqual, tc.Err)
+		})
+	}
+}
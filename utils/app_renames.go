@@ -0,0 +1,173 @@
+package utils
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// DefaultRenameThreshold is how similar a removed and an added resource must be to be reported as a rename
+const DefaultRenameThreshold = 0.5
+
+// AppRename describes a resource which was removed and added again under another name,
+// and whether its contents were modified as well
+type AppRename struct {
+	Kind     string
+	From     string
+	To       string
+	Modified bool
+}
+
+func (ar AppRename) String() string {
+	if ar.Modified {
+		return ar.Kind + ": " + ar.From + " -> " + ar.To + " (modified)"
+	}
+	return ar.Kind + ": " + ar.From + " -> " + ar.To
+}
+
+// DetectRenames pairs up resources of the same kind which were removed and added locally, and moves
+// the pairs whose content is at least threshold similar (from 0 to 1) into RenamedLocally.
+// Functions are compared by their source, every other resource by its configuration. A rename is
+// marked as modified unless the resource is otherwise identical to the one it was renamed from
+func (ad *AppDiffs) DetectRenames(local, remote map[string]interface{}, threshold float64) {
+	localResources, remoteResources := indexAppResources(local), indexAppResources(remote)
+
+	type candidate struct {
+		removed, added AppResource
+		similarity     float64
+	}
+
+	var candidates []candidate
+	for _, removed := range ad.DeletedLocally {
+		if removed.Kind == appConfigKind {
+			continue
+		}
+		for _, added := range ad.AddedLocally {
+			if added.Kind != removed.Kind {
+				continue
+			}
+
+			similarity := contentSimilarity(resourceContent(remoteResources[removed]), resourceContent(localResources[added]))
+			if similarity >= threshold {
+				candidates = append(candidates, candidate{removed, added, similarity})
+			}
+		}
+	}
+
+	// the most similar pairs are matched first; ties are broken by name to keep the result stable
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].similarity != candidates[j].similarity {
+			return candidates[i].similarity > candidates[j].similarity
+		}
+		if candidates[i].removed.Name != candidates[j].removed.Name {
+			return candidates[i].removed.Name < candidates[j].removed.Name
+		}
+		return candidates[i].added.Name < candidates[j].added.Name
+	})
+
+	renamed := map[AppResource]bool{}
+	for _, c := range candidates {
+		if renamed[c.removed] || renamed[c.added] {
+			continue
+		}
+		renamed[c.removed], renamed[c.added] = true, true
+		ad.RenamedLocally = append(ad.RenamedLocally, AppRename{
+			Kind:     c.removed.Kind,
+			From:     c.removed.Name,
+			To:       c.added.Name,
+			Modified: !equalRenamed(c.removed.Kind, c.added.Name, remoteResources[c.removed], localResources[c.added]),
+		})
+	}
+
+	if len(renamed) == 0 {
+		return
+	}
+
+	without := func(resources []AppResource) []AppResource {
+		var kept []AppResource
+		for _, resource := range resources {
+			if !renamed[resource] {
+				kept = append(kept, resource)
+			}
+		}
+		return kept
+	}
+	ad.AddedLocally, ad.DeletedLocally = without(ad.AddedLocally), without(ad.DeletedLocally)
+
+	sort.Slice(ad.RenamedLocally, func(i, j int) bool {
+		if ad.RenamedLocally[i].Kind != ad.RenamedLocally[j].Kind {
+			return ad.RenamedLocally[i].Kind < ad.RenamedLocally[j].Kind
+		}
+		return ad.RenamedLocally[i].From < ad.RenamedLocally[j].From
+	})
+}
+
+// equalRenamed reports whether the removed resource, once given the name of the added one, is identical to it
+func equalRenamed(kind, name string, removed, added interface{}) bool {
+	data, err := json.Marshal(removed)
+	if err != nil {
+		return false
+	}
+
+	var renamed map[string]interface{}
+	if err := json.Unmarshal(data, &renamed); err != nil {
+		return false
+	}
+
+	for _, resourceList := range appResourceLists {
+		if resourceList.kind == kind {
+			resourceList.setName(renamed, name)
+		}
+	}
+	return reflect.DeepEqual(renamed, added)
+}
+
+// resourceContent returns what a resource is compared by when detecting renames
+func resourceContent(resource interface{}) string {
+	if r, ok := resource.(map[string]interface{}); ok {
+		if source, ok := r[sourceName].(string); ok {
+			return source
+		}
+	}
+
+	data, err := json.MarshalIndent(resource, "", "  ")
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// contentSimilarity returns the share of lines two texts have in common, from 0 for none to 1 for all,
+// ignoring the order of the lines, their indentation and blank lines
+func contentSimilarity(a, b string) float64 {
+	linesA, linesB := contentLines(a), contentLines(b)
+	if len(linesA)+len(linesB) == 0 {
+		return 1
+	}
+
+	counts := make(map[string]int, len(linesA))
+	for _, line := range linesA {
+		counts[line]++
+	}
+
+	var common int
+	for _, line := range linesB {
+		if counts[line] > 0 {
+			counts[line]--
+			common++
+		}
+	}
+
+	return float64(2*common) / float64(len(linesA)+len(linesB))
+}
+
+func contentLines(content string) []string {
+	var lines []string
+	for _, line := range strings.Split(content, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,184 @@
+package utils
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// SchemaChangeKind is the kind of AppChange used for a destructive change to the schema of a collection
+const SchemaChangeKind = "schema"
+
+// The reasons a change to the schema of a collection is destructive
+const (
+	schemaRemovedRisk        = "the schema of the collection is removed, which may delete its synced data"
+	schemaRulesRemovedRisk   = "the rules of the collection are removed along with its schema, which may delete its synced data"
+	schemaPropertyRemoved    = "the property is removed, which may delete its data"
+	schemaPropertyTypeChange = "the type of the property changes from %s to %s, which may delete its data"
+)
+
+// DestructiveSchemaChange is a change to the schema of a collection which may delete data, such as removing
+// the schema or one of its properties, or changing the type of a property. They are reported on their own so
+// that they are not imported by accident
+type DestructiveSchemaChange struct {
+	// Resource is the data source whose rules define the schema
+	Resource   AppResource
+	Database   string
+	Collection string
+	// Field is the path of the changed property within the schema, or empty if the whole schema is removed
+	Field string
+	Risk  string
+}
+
+func (sc DestructiveSchemaChange) String() string {
+	name := fmt.Sprintf("%s: %s.%s", sc.Resource.Name, sc.Database, sc.Collection)
+	if sc.Field != "" {
+		name += " " + sc.Field
+	}
+	return name
+}
+
+// Name names the changed schema, or property within it, in an AppChange
+func (sc DestructiveSchemaChange) Name() string {
+	name := fmt.Sprintf("%s.%s.%s", sc.Resource.Name, sc.Database, sc.Collection)
+	if sc.Field != "" {
+		name += "." + sc.Field
+	}
+	return name
+}
+
+// DiffDestructiveSchemaChanges compares the schemas defined by the rules of the local and remote apps and
+// returns the changes which may delete data. When merging, rules missing from the local app are left alone
+func DiffDestructiveSchemaChanges(local, remote map[string]interface{}, merge bool) []DestructiveSchemaChange {
+	localRules := collectionRules(local)
+
+	var changes []DestructiveSchemaChange
+	for _, remoteSchema := range AppSchemas(remote) {
+		key := collectionRuleKey{remoteSchema.DataSource, remoteSchema.Database, remoteSchema.Collection}
+		change := DestructiveSchemaChange{
+			Resource:   AppResource{"service", remoteSchema.DataSource},
+			Database:   remoteSchema.Database,
+			Collection: remoteSchema.Collection,
+		}
+
+		localRule, ok := localRules[key]
+		if !ok {
+			if !merge {
+				change.Risk = schemaRulesRemovedRisk
+				changes = append(changes, change)
+			}
+			continue
+		}
+
+		localSchema, ok := localRule[schemaName]
+		if !ok {
+			change.Risk = schemaRemovedRisk
+			changes = append(changes, change)
+			continue
+		}
+
+		changes = append(changes, diffSchemaProperties(change, "", localSchema, remoteSchema.Schema)...)
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Name() < changes[j].Name()
+	})
+	return changes
+}
+
+// diffSchemaProperties compares the properties of a local and remote schema, along with those of the objects
+// and arrays nested within them
+func diffSchemaProperties(change DestructiveSchemaChange, prefix string, local, remote interface{}) []DestructiveSchemaChange {
+	localSchema, _ := local.(map[string]interface{})
+	remoteSchema, _ := remote.(map[string]interface{})
+
+	var changes []DestructiveSchemaChange
+
+	localProperties, _ := localSchema["properties"].(map[string]interface{})
+	remoteProperties, _ := remoteSchema["properties"].(map[string]interface{})
+	for name, remoteProperty := range remoteProperties {
+		propertyChange := change
+		propertyChange.Field = prefix + "properties." + name
+
+		localProperty, ok := localProperties[name]
+		if !ok {
+			propertyChange.Risk = schemaPropertyRemoved
+			changes = append(changes, propertyChange)
+			continue
+		}
+
+		localType, remoteType := schemaBSONType(localProperty), schemaBSONType(remoteProperty)
+		// a type removed locally accepts any type, so keeps the data
+		if remoteType != nil && localType != nil && !reflect.DeepEqual(localType, remoteType) {
+			propertyChange.Risk = fmt.Sprintf(schemaPropertyTypeChange, marshalJSONValue(remoteType), marshalJSONValue(localType))
+			changes = append(changes, propertyChange)
+			continue
+		}
+
+		changes = append(changes, diffSchemaProperties(change, propertyChange.Field+".", localProperty, remoteProperty)...)
+	}
+
+	if remoteItems, ok := remoteSchema["items"]; ok {
+		changes = append(changes, diffSchemaProperties(change, prefix+"items.", localSchema["items"], remoteItems)...)
+	}
+
+	return changes
+}
+
+func schemaBSONType(schema interface{}) interface{} {
+	fields, _ := schema.(map[string]interface{})
+	return fields["bsonType"]
+}
+
+// collectionRuleKey identifies the rule of a collection by its data source, database and collection
+type collectionRuleKey struct {
+	dataSource string
+	database   string
+	collection string
+}
+
+// collectionRules returns the rules of the app's data sources by data source, database and collection
+func collectionRules(app map[string]interface{}) map[collectionRuleKey]map[string]interface{} {
+	rules := map[collectionRuleKey]map[string]interface{}{}
+	for _, s := range topLevelList(servicesName)(app) {
+		svc, ok := s.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		svcRules, _ := svc[rulesName].([]interface{})
+		for _, r := range svcRules {
+			rule, ok := r.(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			database, _ := rule["database"].(string)
+			collection, _ := rule["collection"].(string)
+			if database == "" || collection == "" {
+				continue
+			}
+			rules[collectionRuleKey{configNameField(svc), database, collection}] = rule
+		}
+	}
+	return rules
+}
+
+// HasCollectionRules reports whether any data source of the app defines rules for a collection, without which
+// importing the app with the merge strategy cannot change any schema
+func HasCollectionRules(app map[string]interface{}) bool {
+	return len(collectionRules(app)) > 0
+}
+
+// DestructiveSchemaChangesDiff lists the destructive schema changes the way AppDiffs.Diff does
+func DestructiveSchemaChangesDiff(changes []DestructiveSchemaChange) []string {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	diff := []string{"Destructive Schema Changes (these may delete data):"}
+	for _, change := range changes {
+		diff = append(diff, fmt.Sprintf("\t!! %s", change), "\t\t"+change.Risk)
+	}
+	return diff
+}
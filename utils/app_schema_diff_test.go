@@ -0,0 +1,145 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/10gen/realm-cli/utils"
+	u "github.com/10gen/realm-cli/utils/test"
+	gc "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDiffAppsSchemas(t *testing.T) {
+	loadApps := func(t *testing.T) (map[string]interface{}, map[string]interface{}) {
+		local, err := utils.UnmarshalFromDir("../testdata/app_with_schemas")
+		u.So(t, err, gc.ShouldBeNil)
+
+		remote, err := utils.UnmarshalFromDir("../testdata/app_with_schemas")
+		u.So(t, err, gc.ShouldBeNil)
+
+		return local, remote
+	}
+
+	// rules returns the rules of the collection in the data source, and removes them when remove is set
+	rules := func(app map[string]interface{}, dataSource, collection string, remove bool) map[string]interface{} {
+		for _, s := range app["services"].([]interface{}) {
+			svc := s.(map[string]interface{})
+			if svc["config"].(map[string]interface{})["name"] != dataSource {
+				continue
+			}
+			svcRules := svc["rules"].([]interface{})
+			for i, r := range svcRules {
+				rule := r.(map[string]interface{})
+				if rule["collection"] == collection {
+					if remove {
+						svc["rules"] = append(svcRules[:i:i], svcRules[i+1:]...)
+					}
+					return rule
+				}
+			}
+		}
+		return nil
+	}
+
+	properties := func(app map[string]interface{}, dataSource, collection string) map[string]interface{} {
+		schema := rules(app, dataSource, collection, false)["schema"].(map[string]interface{})
+		return schema["properties"].(map[string]interface{})
+	}
+
+	t.Run("finds no destructive changes between identical apps", func(t *testing.T) {
+		local, remote := loadApps(t)
+
+		u.So(t, utils.DiffApps(local, remote, false).SchemaChanges, gc.ShouldBeEmpty)
+	})
+
+	t.Run("reports removing a property or changing its type", func(t *testing.T) {
+		local, remote := loadApps(t)
+
+		delete(properties(local, "mongodb-atlas", "items"), "name")
+		properties(local, "analytics", "daily")["day"] = map[string]interface{}{"bsonType": "string"}
+
+		diffs := utils.DiffApps(local, remote, false)
+		u.So(t, diffs.SchemaChanges, gc.ShouldResemble, []utils.DestructiveSchemaChange{
+			{
+				Resource:   utils.AppResource{Kind: "service", Name: "analytics"},
+				Database:   "reports",
+				Collection: "daily",
+				Field:      "properties.day",
+				Risk:       `the type of the property changes from "date" to "string", which may delete its data`,
+			},
+			{
+				Resource:   utils.AppResource{Kind: "service", Name: "mongodb-atlas"},
+				Database:   "store",
+				Collection: "items",
+				Field:      "properties.name",
+				Risk:       "the property is removed, which may delete its data",
+			},
+		})
+
+		diff := diffs.Diff()
+		u.So(t, diff[len(diff)-5:], gc.ShouldResemble, []string{
+			"Destructive Schema Changes (these may delete data):",
+			"\t!! analytics: reports.daily properties.day",
+			`		the type of the property changes from "date" to "string", which may delete its data`,
+			"\t!! mongodb-atlas: store.items properties.name",
+			"\t\tthe property is removed, which may delete its data",
+		})
+
+		u.So(t, diffs.Changes(map[utils.AppResource]string{{Kind: "service", Name: "mongodb-atlas"}: "services/mongodb-atlas"}), gc.ShouldContain, utils.AppChange{
+			Change:      utils.AppChangeModified,
+			Kind:        utils.SchemaChangeKind,
+			Name:        "mongodb-atlas.store.items.properties.name",
+			Path:        "services/mongodb-atlas",
+			Risk:        "the property is removed, which may delete its data",
+			Destructive: true,
+		})
+	})
+
+	t.Run("does not report adding a property or dropping its type", func(t *testing.T) {
+		local, remote := loadApps(t)
+
+		properties(local, "mongodb-atlas", "items")["price"] = map[string]interface{}{"bsonType": "double"}
+		properties(local, "mongodb-atlas", "items")["name"] = map[string]interface{}{}
+
+		u.So(t, utils.DiffApps(local, remote, false).SchemaChanges, gc.ShouldBeEmpty)
+	})
+
+	t.Run("reports removing a schema", func(t *testing.T) {
+		local, remote := loadApps(t)
+
+		delete(rules(local, "analytics", "daily", false), "schema")
+
+		diffs := utils.DiffApps(local, remote, true)
+		u.So(t, diffs.SchemaChanges, gc.ShouldHaveLength, 1)
+		u.So(t, diffs.SchemaChanges[0].String(), gc.ShouldEqual, "analytics: reports.daily")
+		u.So(t, diffs.Changes(nil), gc.ShouldContain, utils.AppChange{
+			Change:      utils.AppChangeRemoved,
+			Kind:        utils.SchemaChangeKind,
+			Name:        "analytics.reports.daily",
+			Risk:        "the schema of the collection is removed, which may delete its synced data",
+			Destructive: true,
+		})
+	})
+
+	t.Run("leaves rules missing locally alone when merging", func(t *testing.T) {
+		local, remote := loadApps(t)
+
+		rules(local, "mongodb-atlas", "items", true)
+
+		u.So(t, utils.DiffApps(local, remote, true).SchemaChanges, gc.ShouldBeEmpty)
+		u.So(t, utils.DiffApps(local, remote, false).SchemaChanges, gc.ShouldResemble, []utils.DestructiveSchemaChange{{
+			Resource:   utils.AppResource{Kind: "service", Name: "mongodb-atlas"},
+			Database:   "store",
+			Collection: "items",
+			Risk:       "the rules of the collection are removed along with its schema, which may delete its synced data",
+		}})
+	})
+
+	t.Run("tells whether an app has rules", func(t *testing.T) {
+		local, _ := loadApps(t)
+		u.So(t, utils.HasCollectionRules(local), gc.ShouldBeTrue)
+
+		app, err := utils.UnmarshalFromDir("../testdata/simple_app")
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, utils.HasCollectionRules(app), gc.ShouldBeFalse)
+	})
+}
@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/10gen/realm-cli/models"
+)
+
+// appSkeletonConfigVersion is the config version of the apps WriteAppSkeleton writes
+const appSkeletonConfigVersion = 20200603
+
+// appSkeletonDirectories are the directories of the resources of a new app
+var appSkeletonDirectories = []string{
+	authProvidersName,
+	FunctionsRoot,
+	servicesName,
+	triggersName,
+	valuesName,
+	filepath.Join(graphQLName, customResolversName),
+}
+
+// WriteAppSkeleton writes a minimal app named name into dir, which must not exist yet, laying out every
+// directory of the app along with a config for the app, its GraphQL API, and an API key auth provider which
+// is disabled. The app can be imported as it is, and gives the files to start editing from
+func WriteAppSkeleton(dir, name, location, deploymentModel string) error {
+	if _, err := os.Stat(dir); err == nil {
+		return fmt.Errorf("failed to create directory %q: directory already exists", dir)
+	}
+
+	for _, resourceDir := range appSkeletonDirectories {
+		if err := os.MkdirAll(filepath.Join(dir, resourceDir), os.ModePerm); err != nil {
+			return err
+		}
+	}
+
+	appConfig := map[string]interface{}{
+		"config_version":    appSkeletonConfigVersion,
+		models.AppNameField: name,
+		"security":          map[string]interface{}{},
+		"hosting":           map[string]interface{}{"enabled": false},
+		"custom_user_data_config": map[string]interface{}{
+			"enabled": false,
+		},
+		"sync": map[string]interface{}{
+			"development_mode_enabled": false,
+		},
+	}
+	if location != "" {
+		appConfig[models.AppLocationField] = location
+	}
+	if deploymentModel != "" {
+		appConfig[models.AppDeploymentModelField] = deploymentModel
+	}
+
+	for path, contents := range map[string]interface{}{
+		appConfigName + jsonExt: appConfig,
+		filepath.Join(authProvidersName, "api-key"+jsonExt): map[string]interface{}{
+			"name":     "api-key",
+			"type":     "api-key",
+			"disabled": true,
+		},
+		filepath.Join(graphQLName, configName+jsonExt): map[string]interface{}{
+			"use_natural_pluralization": true,
+		},
+	} {
+		data, err := json.MarshalIndent(contents, "", "    ")
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, path), data, 0600); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package utils_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/10gen/realm-cli/utils"
+	u "github.com/10gen/realm-cli/utils/test"
+	gc "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWriteAppSkeleton(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "realm-app-skeleton-")
+	u.So(t, err, gc.ShouldBeNil)
+	defer os.RemoveAll(tempDir)
+
+	dir := filepath.Join(tempDir, "my-app")
+	u.So(t, utils.WriteAppSkeleton(dir, "my-app", "IE", "LOCAL"), gc.ShouldBeNil)
+
+	t.Run("writes an app which loads and validates", func(t *testing.T) {
+		app, err := utils.UnmarshalFromDir(dir)
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, app["name"], gc.ShouldEqual, "my-app")
+		u.So(t, app["location"], gc.ShouldEqual, "IE")
+		u.So(t, app["deployment_model"], gc.ShouldEqual, "LOCAL")
+		u.So(t, app["auth_providers"], gc.ShouldHaveLength, 1)
+
+		problems, err := utils.ValidateAppDir(dir, "20200603")
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, problems, gc.ShouldBeEmpty)
+	})
+
+	t.Run("lays out every directory of the app", func(t *testing.T) {
+		for _, resourceDir := range []string{"auth_providers", "functions", "services", "triggers", "values", "graphql/custom_resolvers"} {
+			info, err := os.Stat(filepath.Join(dir, filepath.FromSlash(resourceDir)))
+			u.So(t, err, gc.ShouldBeNil)
+			u.So(t, info.IsDir(), gc.ShouldBeTrue)
+		}
+	})
+
+	t.Run("does not write into an existing directory", func(t *testing.T) {
+		err := utils.WriteAppSkeleton(dir, "my-app", "", "")
+		u.So(t, err, gc.ShouldNotBeNil)
+		u.So(t, err.Error(), gc.ShouldContainSubstring, "directory already exists")
+	})
+}
@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+const syncName = "sync"
+
+// The risks of changing the fields of a Sync configuration, by field.
+// Changing any other field is reported with defaultSyncRisk
+var syncRisks = map[string]string{
+	"development_mode_enabled": "toggles whether clients may change the schema of synced collections",
+	"state":                    "pausing or terminating Sync disconnects clients, and terminating it requires them to reset",
+	"partition":                "changing the partition key terminates Sync, which requires clients to reset",
+	"database_name":            "clients sync against a different database",
+}
+
+const defaultSyncRisk = "may disrupt connected clients"
+
+// SyncChange is a change to a single field of the Sync configuration of the app or one of its services,
+// which are reported on their own since they can disrupt the clients syncing with the app
+type SyncChange struct {
+	// Resource is the resource whose Sync configuration changed
+	Resource AppResource
+	// Field is the path of the changed field within the app configuration, as used by --ignore-field
+	Field string
+	From  interface{}
+	To    interface{}
+	Risk  string
+}
+
+func (sc SyncChange) String() string {
+	return fmt.Sprintf("%s: %s -> %s", sc.Field, marshalJSONValue(sc.From), marshalJSONValue(sc.To))
+}
+
+// diffSync compares the Sync configuration of the app and of each of its services field by field.
+// When merging, Sync configurations missing from the local app are left alone
+func diffSync(local, remote map[string]interface{}, merge bool) []SyncChange {
+	var changes []SyncChange
+
+	compare := func(resource AppResource, prefix string, localSync, remoteSync interface{}) {
+		if localSync == nil && merge {
+			return
+		}
+
+		localFields, _ := localSync.(map[string]interface{})
+		remoteFields, _ := remoteSync.(map[string]interface{})
+
+		fields := map[string]bool{}
+		for field := range localFields {
+			fields[field] = true
+		}
+		for field := range remoteFields {
+			fields[field] = true
+		}
+
+		for field := range fields {
+			from, to := remoteFields[field], localFields[field]
+			if reflect.DeepEqual(from, to) {
+				continue
+			}
+
+			risk, ok := syncRisks[field]
+			if !ok {
+				risk = defaultSyncRisk
+			}
+			changes = append(changes, SyncChange{resource, prefix + field, from, to, risk})
+		}
+	}
+
+	compare(AppResource{appConfigKind, syncName}, syncName+".", local[syncName], remote[syncName])
+
+	localServices, remoteServices := serviceSyncConfigs(local), serviceSyncConfigs(remote)
+	for name, localSync := range localServices {
+		compare(AppResource{"service", name}, servicesName+"."+name+"."+configName+"."+syncName+".", localSync, remoteServices[name])
+	}
+	for name, remoteSync := range remoteServices {
+		if _, ok := localServices[name]; !ok {
+			compare(AppResource{"service", name}, servicesName+"."+name+"."+configName+"."+syncName+".", nil, remoteSync)
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Field < changes[j].Field
+	})
+	return changes
+}
+
+// serviceSyncConfigs returns the Sync configuration of each service which has one, by service name
+func serviceSyncConfigs(app map[string]interface{}) map[string]interface{} {
+	configs := map[string]interface{}{}
+	for _, s := range topLevelList(servicesName)(app) {
+		service, ok := s.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		serviceConfig, _ := service[configName].(map[string]interface{})
+		config, _ := serviceConfig[configName].(map[string]interface{})
+		if sync, ok := config[syncName]; ok {
+			configs[configNameField(service)] = sync
+		}
+	}
+	return configs
+}
@@ -0,0 +1,122 @@
+package utils
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const webhookOptionsField = "options"
+
+// the keys which each kind of resource may set in the config version realm-cli imports apps as
+var (
+	functionKeys = keySet(
+		"_id", "id", "name", "private", "can_evaluate", "disable_arg_logs",
+		"run_as_system", "run_as_user_id", "run_as_user_id_script_source",
+	)
+	webhookKeys = keySet(
+		"_id", "id", "name", "options", "respond_result", "return_type", "can_evaluate", "disable_arg_logs",
+		"create_user_on_auth", "fetch_custom_user_data", "run_as_authed_user", "run_as_user_id", "run_as_user_id_script_source",
+	)
+	webhookOptionsKeys = keySet("httpMethod", "validationMethod", "secret", "secretAsQueryParam")
+	serviceKeys        = keySet("_id", "id", "name", "type", "config", "secret_config", "version")
+	triggerKeys        = keySet(
+		"_id", "id", "name", "type", "config", "function_id", "function_name", "disabled", "event_processors",
+	)
+
+	// triggerConfigKeys are the keys of the config of each type of trigger
+	triggerConfigKeys = map[string]map[string]bool{
+		"DATABASE": keySet(
+			"operation_types", "database", "collection", "service_id", "service_name", "match", "project",
+			"full_document", "full_document_before_change", "unordered", "skip_catchup_events",
+			"tolerate_resume_errors", "maximum_throughput",
+		),
+		"AUTHENTICATION": keySet("operation_type", "action_type", "providers"),
+		"SCHEDULED":      keySet("schedule", "skip_catchup_events"),
+	}
+)
+
+func keySet(keys ...string) map[string]bool {
+	set := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		set[key] = true
+	}
+	return set
+}
+
+// FindUnknownKeys looks for keys which realm-cli does not recognize in the configs of the functions,
+// incoming webhooks, services and triggers of the app in the given directory, e.g. misspelled options,
+// which Realm would otherwise silently drop. The config of a service is left alone since its keys depend
+// on the type of the service, as is the config of a trigger of an unknown type. Files which are not
+// well-formed JSON objects are reported as well
+func FindUnknownKeys(appPath string) ([]AppProblem, error) {
+	realmIgnore, err := ReadRealmIgnoreFile(appPath)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &appValidator{appPath: appPath, realmIgnore: realmIgnore}
+
+	for _, dir := range v.directories(FunctionsRoot) {
+		if strings.Contains(dir, "node_modules") {
+			continue
+		}
+		configPath := filepath.Join(dir, configName+jsonExt)
+		if function, ok := v.readResource(configPath, false); ok {
+			v.reportUnknownKeys(configPath, "", function, functionKeys)
+		}
+	}
+
+	for _, relPath := range v.jsonFiles(triggersName) {
+		trigger, ok := v.readResource(relPath, false)
+		if !ok {
+			continue
+		}
+		v.reportUnknownKeys(relPath, "", trigger, triggerKeys)
+
+		triggerType, _ := trigger["type"].(string)
+		config, _ := trigger[configName].(map[string]interface{})
+		if configKeys, ok := triggerConfigKeys[triggerType]; ok {
+			v.reportUnknownKeys(relPath, configName+".", config, configKeys)
+		}
+	}
+
+	for _, dir := range v.directories(servicesName) {
+		configPath := filepath.Join(dir, configName+jsonExt)
+		if service, ok := v.readResource(configPath, false); ok {
+			v.reportUnknownKeys(configPath, "", service, serviceKeys)
+		}
+
+		for _, webhookDir := range v.directories(filepath.Join(dir, incomingWebhooksName)) {
+			webhookPath := filepath.Join(webhookDir, configName+jsonExt)
+			webhook, ok := v.readResource(webhookPath, false)
+			if !ok {
+				continue
+			}
+			v.reportUnknownKeys(webhookPath, "", webhook, webhookKeys)
+
+			options, _ := webhook[webhookOptionsField].(map[string]interface{})
+			v.reportUnknownKeys(webhookPath, webhookOptionsField+".", options, webhookOptionsKeys)
+		}
+	}
+
+	sort.SliceStable(v.problems, func(i, j int) bool {
+		return v.problems[i].Path < v.problems[j].Path
+	})
+	return v.problems, nil
+}
+
+// reportUnknownKeys reports each key of the resource which is not among the known keys, in order
+func (v *appValidator) reportUnknownKeys(relPath, prefix string, resource map[string]interface{}, known map[string]bool) {
+	var unknown []string
+	for key := range resource {
+		if !known[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+
+	for _, key := range unknown {
+		v.report(relPath, prefix+key, "unknown key")
+	}
+}
@@ -0,0 +1,54 @@
+package utils_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/10gen/realm-cli/utils"
+	u "github.com/10gen/realm-cli/utils/test"
+	gc "github.com/smartystreets/goconvey/convey"
+)
+
+func TestFindUnknownKeys(t *testing.T) {
+	t.Run("finds no unknown keys in a valid app", func(t *testing.T) {
+		problems, err := utils.FindUnknownKeys("../testdata/full_app")
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, problems, gc.ShouldBeEmpty)
+	})
+
+	t.Run("reports every unknown key along with its file", func(t *testing.T) {
+		appPath, err := ioutil.TempDir("", "realm-app-")
+		u.So(t, err, gc.ShouldBeNil)
+		defer os.RemoveAll(appPath)
+
+		for path, contents := range map[string]string{
+			"config.json":                                      `{"name": "my-app"}`,
+			"functions/sum/config.json":                        `{"name": "sum", "privat": true}`,
+			"triggers/nightly.json":                            `{"name": "nightly", "type": "SCHEDULED", "config": {"schedule": "0 3 * * *", "timezone": "UTC"}, "function_name": "sum"}`,
+			"triggers/custom.json":                             `{"name": "custom", "type": "CUSTOM", "config": {"anything": true}, "function_name": "sum"}`,
+			"services/http/config.json":                        `{"name": "http", "type": "http", "config": {"anything": true}, "rules": []}`,
+			"services/http/incoming_webhooks/hook/config.json": `{"name": "hook", "options": {"httpMethd": "POST", "secret": "s"}, "respond_results": true}`,
+		} {
+			path = filepath.Join(appPath, filepath.FromSlash(path))
+			u.So(t, os.MkdirAll(filepath.Dir(path), 0755), gc.ShouldBeNil)
+			u.So(t, ioutil.WriteFile(path, []byte(contents), 0644), gc.ShouldBeNil)
+		}
+
+		problems, err := utils.FindUnknownKeys(appPath)
+		u.So(t, err, gc.ShouldBeNil)
+
+		var found []string
+		for _, problem := range problems {
+			found = append(found, problem.String())
+		}
+		u.So(t, found, gc.ShouldResemble, []string{
+			"functions/sum/config.json: privat: unknown key",
+			"services/http/config.json: rules: unknown key",
+			"services/http/incoming_webhooks/hook/config.json: respond_results: unknown key",
+			"services/http/incoming_webhooks/hook/config.json: options.httpMethd: unknown key",
+			"triggers/nightly.json: config.timezone: unknown key",
+		})
+	})
+}
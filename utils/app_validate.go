@@ -0,0 +1,312 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/10gen/realm-cli/models"
+)
+
+// AppProblem describes something wrong with one of the files of an app
+type AppProblem struct {
+	// Path is the file with the problem, relative to the app directory
+	Path string
+	// Field is the field of the file with the problem, if the problem is not with the file as a whole
+	Field   string
+	Message string
+}
+
+func (p AppProblem) String() string {
+	if p.Field == "" {
+		return fmt.Sprintf("%s: %s", p.Path, p.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", p.Path, p.Field, p.Message)
+}
+
+// ValidateAppDir checks the structure of the app in the given directory without contacting Realm:
+// that every file is well-formed JSON, that each resource has the fields it requires, and that
+// the functions referenced by triggers and custom resolvers exist. Unlike UnmarshalFromDir, which
+// stops at the first problem, every problem found is returned, ordered by path. An app declaring a
+// config version other than configVersion is reported as well
+func ValidateAppDir(appPath, configVersion string) ([]AppProblem, error) {
+	realmIgnore, err := ReadRealmIgnoreFile(appPath)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &appValidator{
+		appPath:     appPath,
+		realmIgnore: realmIgnore,
+		names:       map[string]map[string][]string{},
+	}
+
+	// the name of the app may be left out of its config, e.g. to be given when the app is created
+	if app, ok := v.readResource(appConfigName+jsonExt, true); ok {
+		v.checkConfigVersion(app, configVersion)
+	}
+	v.readResource(secretsName+jsonExt, false)
+
+	for _, relPath := range v.jsonFiles(valuesName) {
+		if value, ok := v.readResource(relPath, true); ok {
+			v.requireFields(relPath, value, "name")
+			v.recordName("value", relPath, value)
+		}
+	}
+	for _, relPath := range v.jsonFiles(authProvidersName) {
+		if provider, ok := v.readResource(relPath, true); ok {
+			v.requireFields(relPath, provider, "name", "type")
+		}
+	}
+	for _, relPath := range v.jsonFiles(environmentsName) {
+		if environment, ok := v.readResource(relPath, true); ok {
+			v.problems = append(v.problems, validateEnvironment(filepath.ToSlash(relPath), environment)...)
+		}
+	}
+
+	functionNames := map[string]bool{}
+	for _, dir := range v.directories(FunctionsRoot) {
+		// node_modules is uploaded as a single entity rather than as a function
+		if strings.Contains(dir, "node_modules") {
+			continue
+		}
+		if name, ok := v.validateFunctionDirectory(dir); ok {
+			functionNames[name] = true
+		}
+	}
+
+	for _, relPath := range v.jsonFiles(triggersName) {
+		trigger, ok := v.readResource(relPath, true)
+		if !ok {
+			continue
+		}
+		v.requireFields(relPath, trigger, "name", "type")
+		v.recordName("trigger", relPath, trigger)
+		if _, ok := trigger[configName].(map[string]interface{}); !ok {
+			v.report(relPath, configName, "is required and must be an object")
+		}
+		if _, ok := trigger[triggerEventProcessorsField]; !ok {
+			v.requireFunction(relPath, trigger, functionNames)
+		}
+	}
+
+	for _, dir := range v.directories(servicesName) {
+		configPath := filepath.Join(dir, configName+jsonExt)
+		if service, ok := v.readResource(configPath, true); ok {
+			v.requireFields(configPath, service, "name", "type")
+			v.recordName("service", configPath, service)
+		}
+		for _, relPath := range v.jsonFiles(filepath.Join(dir, rulesName)) {
+			v.readResource(relPath, true)
+		}
+		for _, webhookDir := range v.directories(filepath.Join(dir, incomingWebhooksName)) {
+			v.validateFunctionDirectory(webhookDir)
+		}
+	}
+
+	v.readResource(filepath.Join(graphQLName, configName+jsonExt), false)
+	for _, relPath := range v.jsonFiles(filepath.Join(graphQLName, customResolversName)) {
+		if resolver, ok := v.readResource(relPath, true); ok {
+			v.requireFields(relPath, resolver, "field_name", "on_type")
+			v.requireFunction(relPath, resolver, functionNames)
+		}
+	}
+
+	v.reportDuplicateNames()
+
+	sort.SliceStable(v.problems, func(i, j int) bool {
+		return v.problems[i].Path < v.problems[j].Path
+	})
+	return v.problems, nil
+}
+
+type appValidator struct {
+	appPath     string
+	realmIgnore *IgnoreRules
+	problems    []AppProblem
+
+	// names maps each kind of resource to the paths of the files defining each name
+	names map[string]map[string][]string
+}
+
+func (v *appValidator) report(relPath, field, format string, args ...interface{}) {
+	v.problems = append(v.problems, AppProblem{
+		Path:    filepath.ToSlash(relPath),
+		Field:   field,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// entries lists the files in the given directory of the app which are not left out by its .realmignore
+func (v *appValidator) entries(dir string) []os.FileInfo {
+	path := filepath.Join(v.appPath, dir)
+	fileInfos, err := ioutil.ReadDir(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			v.report(dir, "", "failed to read directory: %s", err)
+		}
+		return nil
+	}
+	return v.realmIgnore.filter(path, fileInfos)
+}
+
+// jsonFiles returns the paths of the JSON files in the given directory of the app
+func (v *appValidator) jsonFiles(dir string) []string {
+	var paths []string
+	for _, fileInfo := range v.entries(dir) {
+		if !fileInfo.IsDir() && filepath.Ext(fileInfo.Name()) == jsonExt {
+			paths = append(paths, filepath.Join(dir, fileInfo.Name()))
+		}
+	}
+	return paths
+}
+
+// directories returns the paths of the directories in the given directory of the app
+func (v *appValidator) directories(dir string) []string {
+	var paths []string
+	for _, fileInfo := range v.entries(dir) {
+		if fileInfo.IsDir() {
+			paths = append(paths, filepath.Join(dir, fileInfo.Name()))
+		}
+	}
+	return paths
+}
+
+// readResource reads the JSON object in the given file of the app, reporting it if it is missing and required,
+// or if it is not a well-formed JSON object
+func (v *appValidator) readResource(relPath string, required bool) (map[string]interface{}, bool) {
+	data, err := ioutil.ReadFile(filepath.Join(v.appPath, relPath))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			v.report(relPath, "", "failed to read file: %s", err)
+		} else if required {
+			v.report(relPath, "", "file is missing")
+		}
+		return nil, false
+	}
+
+	resource := map[string]interface{}{}
+	if len(data) == 0 {
+		return resource, true
+	}
+
+	var parsed interface{}
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		v.report(relPath, "", "invalid JSON: %s", err)
+		return nil, false
+	}
+	if resource, ok := parsed.(map[string]interface{}); ok {
+		return resource, true
+	}
+
+	v.report(relPath, "", "must contain a JSON object")
+	return nil, false
+}
+
+// requireFields reports each of the fields which the resource lacks or which is not a non-empty string
+func (v *appValidator) requireFields(relPath string, resource map[string]interface{}, fields ...string) {
+	for _, field := range fields {
+		value, ok := resource[field]
+		if !ok {
+			v.report(relPath, field, "is required")
+			continue
+		}
+		if s, ok := value.(string); !ok || s == "" {
+			v.report(relPath, field, "must be a non-empty string")
+		}
+	}
+}
+
+// requireFunction reports the resource if it does not reference a function, or references one not among functionNames
+func (v *appValidator) requireFunction(relPath string, resource map[string]interface{}, functionNames map[string]bool) {
+	v.requireFields(relPath, resource, models.TriggerFunctionNameField)
+	if name, _ := resource[models.TriggerFunctionNameField].(string); name != "" && !functionNames[name] {
+		v.report(relPath, models.TriggerFunctionNameField, "function %q does not exist in the app", name)
+	}
+}
+
+// validateFunctionDirectory checks a directory holding the config and source of a function or incoming webhook,
+// and returns the name of the function
+func (v *appValidator) validateFunctionDirectory(dir string) (string, bool) {
+	sourcePath := filepath.Join(dir, sourceName+jsExt)
+	if info, err := os.Stat(filepath.Join(v.appPath, sourcePath)); err != nil || info.IsDir() {
+		v.report(sourcePath, "", "file is missing")
+	}
+
+	configPath := filepath.Join(dir, configName+jsonExt)
+	config, ok := v.readResource(configPath, true)
+	if !ok {
+		return "", false
+	}
+
+	v.requireFields(configPath, config, "name")
+	if strings.HasPrefix(dir, FunctionsRoot) {
+		v.recordName("function", configPath, config)
+	}
+	name := nameField(config)
+	return name, name != ""
+}
+
+func (v *appValidator) checkConfigVersion(app map[string]interface{}, configVersion string) {
+	var version string
+	switch value := app["config_version"].(type) {
+	case nil:
+		return
+	case float64:
+		version = strconv.FormatFloat(value, 'f', -1, 64)
+	case string:
+		version = value
+	default:
+		v.report(appConfigName+jsonExt, "config_version", "must be a number")
+		return
+	}
+
+	if version != configVersion {
+		v.report(appConfigName+jsonExt, "config_version", "is %s, but realm-cli imports apps as version %s", version, configVersion)
+	}
+}
+
+func (v *appValidator) recordName(kind, relPath string, resource map[string]interface{}) {
+	name := nameField(resource)
+	if name == "" {
+		return
+	}
+	if v.names[kind] == nil {
+		v.names[kind] = map[string][]string{}
+	}
+	v.names[kind][name] = append(v.names[kind][name], filepath.ToSlash(relPath))
+}
+
+// reportDuplicateNames reports every resource which shares its name with another resource of the same kind
+func (v *appValidator) reportDuplicateNames() {
+	kinds := make([]string, 0, len(v.names))
+	for kind := range v.names {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+
+	for _, kind := range kinds {
+		paths := v.names[kind]
+		names := make([]string, 0, len(paths))
+		for name := range paths {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			relPaths := paths[name]
+			if len(relPaths) < 2 {
+				continue
+			}
+			for i, relPath := range relPaths {
+				others := append(append([]string{}, relPaths[:i]...), relPaths[i+1:]...)
+				v.report(relPath, "name", "%s %q is also defined in %s", kind, name, strings.Join(others, ", "))
+			}
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package utils_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/10gen/realm-cli/utils"
+	u "github.com/10gen/realm-cli/utils/test"
+	gc "github.com/smartystreets/goconvey/convey"
+)
+
+func TestValidateAppDir(t *testing.T) {
+	t.Run("finds no problems with a valid app", func(t *testing.T) {
+		problems, err := utils.ValidateAppDir("../testdata/full_app", "20200603")
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, problems, gc.ShouldBeEmpty)
+	})
+
+	t.Run("reports every problem along with its file and field", func(t *testing.T) {
+		appPath, err := ioutil.TempDir("", "realm-app-")
+		u.So(t, err, gc.ShouldBeNil)
+		defer os.RemoveAll(appPath)
+
+		for path, contents := range map[string]string{
+			"config.json":                          `{"config_version": 20180301, "name": "my-app"}`,
+			"values/a.json":                        `{"name": "a", "value": 1}`,
+			"values/b.json":                        `{"name": "a", "value": 2}`,
+			"auth_providers/anon.json":             `{"name": "anon-user"}`,
+			"functions/sum/config.json":            `{"name": "sum"}`,
+			"functions/sum/source.js":              `exports = (a, b) => a + b`,
+			"functions/broken/config.json":         `{"name": "broken",}`,
+			"functions/nameless/config.json":       `{"private": true}`,
+			"functions/nameless/source.js":         `exports = () => {}`,
+			"triggers/nightly.json":                `{"name": "nightly", "type": "SCHEDULED", "config": {}, "function_name": "cleanup"}`,
+			"triggers/forward.json":                `{"name": "forward", "type": "DATABASE", "config": {}, "event_processors": {}}`,
+			"triggers/array.json":                  `[]`,
+			"services/mongodb/config.json":         `{"name": "mongodb-atlas", "type": 1}`,
+			"services/mongodb/rules/db.coll.json":  `{"database": "db"`,
+			"services/http/config.json":            `{"name": "http", "type": "http"}`,
+			"graphql/custom_resolvers/query.json":  `{"on_type": "Query", "function_name": "sum"}`,
+			"services/http/incoming_webhooks/hook": "",
+			"environments/production.json":         `{"values": {"greeting": "hello"}}`,
+			"environments/staging.json":            `{"values": []}`,
+		} {
+			path = filepath.Join(appPath, filepath.FromSlash(path))
+			u.So(t, os.MkdirAll(filepath.Dir(path), 0755), gc.ShouldBeNil)
+			if filepath.Ext(path) == "" {
+				u.So(t, os.MkdirAll(path, 0755), gc.ShouldBeNil)
+				continue
+			}
+			u.So(t, ioutil.WriteFile(path, []byte(contents), 0644), gc.ShouldBeNil)
+		}
+
+		problems, err := utils.ValidateAppDir(appPath, "20200603")
+		u.So(t, err, gc.ShouldBeNil)
+
+		var found []string
+		for _, problem := range problems {
+			found = append(found, problem.String())
+		}
+		u.So(t, found, gc.ShouldResemble, []string{
+			"auth_providers/anon.json: type: is required",
+			"config.json: config_version: is 20180301, but realm-cli imports apps as version 20200603",
+			`environments/staging.json: "staging" is not an environment; expected one of [no-environment|development|testing|qa|production]`,
+			"environments/staging.json: values: must be an object",
+			"functions/broken/config.json: invalid JSON: invalid character '}' looking for beginning of object key string",
+			"functions/broken/source.js: file is missing",
+			"functions/nameless/config.json: name: is required",
+			"graphql/custom_resolvers/query.json: field_name: is required",
+			"services/http/incoming_webhooks/hook/config.json: file is missing",
+			"services/http/incoming_webhooks/hook/source.js: file is missing",
+			"services/mongodb/config.json: type: must be a non-empty string",
+			"services/mongodb/rules/db.coll.json: invalid JSON: unexpected end of JSON input",
+			"triggers/array.json: must contain a JSON object",
+			`triggers/nightly.json: function_name: function "cleanup" does not exist in the app`,
+			`values/a.json: name: value "a" is also defined in values/b.json`,
+			`values/b.json: name: value "a" is also defined in values/a.json`,
+		})
+	})
+
+	t.Run("reports the resources with duplicate names in order", func(t *testing.T) {
+		appPath, err := ioutil.TempDir("", "realm-app-")
+		u.So(t, err, gc.ShouldBeNil)
+		defer os.RemoveAll(appPath)
+
+		for path, contents := range map[string]string{
+			"config.json":   `{"config_version": 20200603, "name": "my-app"}`,
+			"values/a.json": `{"name": "x", "value": 1}`,
+			"values/b.json": `{"name": "x", "value": 2}`,
+			"values/c.json": `{"name": "y", "value": 3}`,
+			"values/d.json": `{"name": "y", "value": 4}`,
+			"values/e.json": `{"name": "x", "value": 5}`,
+		} {
+			path = filepath.Join(appPath, filepath.FromSlash(path))
+			u.So(t, os.MkdirAll(filepath.Dir(path), 0755), gc.ShouldBeNil)
+			u.So(t, ioutil.WriteFile(path, []byte(contents), 0644), gc.ShouldBeNil)
+		}
+
+		for i := 0; i < 10; i++ {
+			problems, err := utils.ValidateAppDir(appPath, "20200603")
+			u.So(t, err, gc.ShouldBeNil)
+
+			var found []string
+			for _, problem := range problems {
+				found = append(found, problem.String())
+			}
+			u.So(t, found, gc.ShouldResemble, []string{
+				`values/a.json: name: value "x" is also defined in values/b.json, values/e.json`,
+				`values/b.json: name: value "x" is also defined in values/a.json, values/e.json`,
+				`values/c.json: name: value "y" is also defined in values/d.json`,
+				`values/d.json: name: value "y" is also defined in values/c.json`,
+				`values/e.json: name: value "x" is also defined in values/a.json, values/b.json`,
+			})
+		}
+	})
+}
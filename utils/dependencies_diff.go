@@ -0,0 +1,162 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	nodeModulesName   = "node_modules"
+	packageJSONName   = "package.json"
+	dependencyPathSep = " > "
+)
+
+// DependencyPackages maps each package installed in a node_modules archive to its version. Packages
+// are named after the chain of packages they are nested in, e.g. "debug > ms" for the "ms" package
+// installed in the node_modules of "debug", so that changes to transitive dependencies are told apart
+type DependencyPackages map[string]string
+
+// ReadDependencyPackages reads the name and version of every package installed in the archive
+func ReadDependencyPackages(archive ArchiveReader) (DependencyPackages, error) {
+	packages := DependencyPackages{}
+	err := TraverseArchiveReader(archive, func(header *FileHeader) error {
+		if header.FileInfo().IsDir() {
+			return nil
+		}
+
+		name, ok := dependencyPackageName(header.FullPath)
+		if !ok {
+			return nil
+		}
+
+		data, err := ioutil.ReadAll(archive)
+		if err != nil {
+			return fmt.Errorf("failed to read '%s' in the archive: %s", header.FullPath, err)
+		}
+
+		var manifest struct {
+			Version string `json:"version"`
+		}
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			// a package.json which is not a package manifest, e.g. a test fixture, is not a package
+			return nil
+		}
+
+		packages[name] = manifest.Version
+		return nil
+	})
+	return packages, err
+}
+
+// dependencyPackageName returns the name of the package whose manifest is at filePath,
+// or false if the file is not the manifest of a package installed in node_modules
+func dependencyPackageName(filePath string) (string, bool) {
+	parts := strings.Split(path.Clean(filepath.ToSlash(filePath)), "/")
+	if len(parts) < 3 || parts[len(parts)-1] != packageJSONName {
+		return "", false
+	}
+	parts = parts[:len(parts)-1]
+
+	start := -1
+	for i, part := range parts {
+		if part == nodeModulesName {
+			start = i
+			break
+		}
+	}
+	if start == -1 {
+		return "", false
+	}
+
+	// the rest of the path must alternate between node_modules and a package, which is scoped if it starts with "@"
+	var chain []string
+	for i := start; i < len(parts); {
+		if parts[i] != nodeModulesName || i+1 >= len(parts) {
+			return "", false
+		}
+
+		name := parts[i+1]
+		i += 2
+		if strings.HasPrefix(name, "@") {
+			if i >= len(parts) {
+				return "", false
+			}
+			name += "/" + parts[i]
+			i++
+		}
+		chain = append(chain, name)
+	}
+
+	return strings.Join(chain, dependencyPathSep), true
+}
+
+// DependencyPackageOf returns the name of the package installed in node_modules which the file at filePath
+// belongs to, e.g. "ms" for "node_modules/debug/node_modules/ms/index.js", or false if it belongs to none
+func DependencyPackageOf(filePath string) (string, bool) {
+	parts := strings.Split(path.Clean(filepath.ToSlash(filePath)), "/")
+
+	// the package is the innermost one, which follows the last node_modules with a file beneath its package
+	for i := len(parts) - 1; i >= 0; i-- {
+		if parts[i] != nodeModulesName {
+			continue
+		}
+
+		if i+2 < len(parts) && !strings.HasPrefix(parts[i+1], "@") {
+			return parts[i+1], true
+		}
+		if i+3 < len(parts) && strings.HasPrefix(parts[i+1], "@") {
+			return parts[i+1] + "/" + parts[i+2], true
+		}
+	}
+	return "", false
+}
+
+// DiffDependencyPackages lists the packages added, removed and whose version changed
+// in the local dependencies compared to the deployed ones, sorted by name
+func DiffDependencyPackages(local, remote DependencyPackages) []string {
+	names := make([]string, 0, len(local)+len(remote))
+	for name := range local {
+		names = append(names, name)
+	}
+	for name := range remote {
+		if _, ok := local[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var added, removed, modified []string
+	for _, name := range names {
+		localVersion, inLocal := local[name]
+		remoteVersion, inRemote := remote[name]
+
+		switch {
+		case !inRemote:
+			added = append(added, fmt.Sprintf("\t+ dependency: %s@%s", name, localVersion))
+		case !inLocal:
+			removed = append(removed, fmt.Sprintf("\t- dependency: %s@%s", name, remoteVersion))
+		case localVersion != remoteVersion:
+			modified = append(modified, fmt.Sprintf("\t* dependency: %s %s -> %s", name, remoteVersion, localVersion))
+		}
+	}
+
+	var diff []string
+	if len(added) > 0 {
+		diff = append(diff, "New Dependencies:")
+		diff = append(diff, added...)
+	}
+	if len(removed) > 0 {
+		diff = append(diff, "Removed Dependencies:")
+		diff = append(diff, removed...)
+	}
+	if len(modified) > 0 {
+		diff = append(diff, "Modified Dependencies:")
+		diff = append(diff, modified...)
+	}
+	return diff
+}
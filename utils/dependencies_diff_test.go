@@ -0,0 +1,129 @@
+package utils_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/10gen/realm-cli/utils"
+	u "github.com/10gen/realm-cli/utils/test"
+	gc "github.com/smartystreets/goconvey/convey"
+)
+
+func TestReadDependencyPackages(t *testing.T) {
+	t.Run("reads the version of every package, naming nested ones after the packages they are installed in", func(t *testing.T) {
+		archivePath := "../testdata/app_with_dependencies/functions/node_modules.tar"
+		file, err := os.Open(archivePath)
+		u.So(t, err, gc.ShouldBeNil)
+		defer file.Close()
+
+		fileInfo, err := file.Stat()
+		u.So(t, err, gc.ShouldBeNil)
+
+		archive, err := utils.NewArchiveReader(file, archivePath, fileInfo.Size())
+		u.So(t, err, gc.ShouldBeNil)
+
+		packages, err := utils.ReadDependencyPackages(archive)
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, packages, gc.ShouldResemble, utils.DependencyPackages{
+			"axios":            "0.19.0",
+			"debug":            "3.1.0",
+			"debug > ms":       "2.0.0",
+			"follow-redirects": "1.5.10",
+			"is-buffer":        "2.0.4",
+		})
+	})
+}
+
+func TestDiffDependencyPackages(t *testing.T) {
+	remote := utils.DependencyPackages{
+		"axios":           "0.19.0",
+		"debug":           "3.1.0",
+		"debug > ms":      "2.0.0",
+		"moment":          "2.24.0",
+		"@realm/helpers":  "1.0.0",
+		"@realm/old-tool": "0.1.0",
+	}
+
+	for _, tc := range []struct {
+		description  string
+		local        utils.DependencyPackages
+		remote       utils.DependencyPackages
+		expectedDiff []string
+	}{
+		{
+			description: "lists every package as new when none are deployed yet",
+			local:       utils.DependencyPackages{"axios": "0.19.0", "@realm/helpers": "1.0.0"},
+			remote:      utils.DependencyPackages{},
+			expectedDiff: []string{
+				"New Dependencies:",
+				"\t+ dependency: @realm/helpers@1.0.0",
+				"\t+ dependency: axios@0.19.0",
+			},
+		},
+		{
+			description: "lists the packages added, removed and whose version changed",
+			local: utils.DependencyPackages{
+				"axios":          "0.21.1",
+				"debug":          "3.1.0",
+				"debug > ms":     "2.0.0",
+				"lodash":         "4.17.21",
+				"@realm/helpers": "1.0.0",
+			},
+			remote: remote,
+			expectedDiff: []string{
+				"New Dependencies:",
+				"\t+ dependency: lodash@4.17.21",
+				"Removed Dependencies:",
+				"\t- dependency: @realm/old-tool@0.1.0",
+				"\t- dependency: moment@2.24.0",
+				"Modified Dependencies:",
+				"\t* dependency: axios 0.19.0 -> 0.21.1",
+			},
+		},
+		{
+			description: "lists changes to transitive dependencies only",
+			local: utils.DependencyPackages{
+				"axios":           "0.19.0",
+				"debug":           "3.1.0",
+				"debug > ms":      "2.1.1",
+				"moment":          "2.24.0",
+				"@realm/helpers":  "1.0.0",
+				"@realm/old-tool": "0.1.0",
+			},
+			remote: remote,
+			expectedDiff: []string{
+				"Modified Dependencies:",
+				"\t* dependency: debug > ms 2.0.0 -> 2.1.1",
+			},
+		},
+		{
+			description: "lists nothing when the packages are identical",
+			local:       remote,
+			remote:      remote,
+		},
+	} {
+		t.Run(tc.description, func(t *testing.T) {
+			u.So(t, utils.DiffDependencyPackages(tc.local, tc.remote), gc.ShouldResemble, tc.expectedDiff)
+		})
+	}
+}
+
+func TestDependencyPackageOf(t *testing.T) {
+	for _, tc := range []struct {
+		filePath        string
+		expectedPackage string
+		expectedOK      bool
+	}{
+		{"node_modules/debug/src/index.js", "debug", true},
+		{"node_modules/debug/node_modules/ms/index.js", "ms", true},
+		{"node_modules/@babel/core/lib/index.js", "@babel/core", true},
+		{"node_modules/debug/node_modules/@babel/core/index.js", "@babel/core", true},
+		{"node_modules/debug/node_modules/.package-lock.json", "debug", true},
+		{"node_modules/.package-lock.json", "", false},
+		{"source.js", "", false},
+	} {
+		name, ok := utils.DependencyPackageOf(tc.filePath)
+		u.So(t, name, gc.ShouldEqual, tc.expectedPackage)
+		u.So(t, ok, gc.ShouldEqual, tc.expectedOK)
+	}
+}
@@ -0,0 +1,165 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// checkDuplicateNames looks for resources of the app in the given directory which share their name
+// with another resource of the same kind, and returns an error listing the files that define them
+func checkDuplicateNames(appPath string, realmIgnore *IgnoreRules) error {
+	var problems []string
+
+	for _, resources := range []struct {
+		kind        string
+		dir         string
+		directories bool
+	}{
+		{"function", FunctionsRoot, true},
+		{"service", servicesName, true},
+		{"trigger", triggersName, false},
+		{"value", valuesName, false},
+	} {
+		paths, err := namedResourcePaths(appPath, resources.dir, resources.directories, realmIgnore)
+		if err != nil {
+			return err
+		}
+		problems = append(problems, duplicateNameProblems(resources.kind, paths)...)
+	}
+
+	secretsPath := filepath.Join(appPath, secretsName+jsonExt)
+	if data, err := ioutil.ReadFile(secretsPath); err == nil {
+		duplicates, err := duplicateJSONKeys(data)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %s", secretsPath, err)
+		}
+		for _, key := range duplicates {
+			problems = append(problems, fmt.Sprintf("secret %q is defined more than once in %s", key, secretsName+jsonExt))
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+
+	return errors.New("found resources with duplicate names:\n\t" + strings.Join(problems, "\n\t"))
+}
+
+// namedResourcePaths maps the name of every resource in the given directory of the app to the paths
+// of the files which define it, relative to the app directory. Resources are either JSON files, or
+// directories holding a config.json file. Files left out of the app by its .realmignore are skipped
+func namedResourcePaths(appPath, dir string, directories bool, realmIgnore *IgnoreRules) (map[string][]string, error) {
+	fileInfos, err := ioutil.ReadDir(filepath.Join(appPath, dir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	fileInfos = realmIgnore.filter(filepath.Join(appPath, dir), fileInfos)
+
+	paths := map[string][]string{}
+	for _, fileInfo := range fileInfos {
+		relPath := filepath.Join(dir, fileInfo.Name())
+		if directories {
+			// node_modules is uploaded as a single entity rather than as a resource
+			if !fileInfo.IsDir() || strings.Contains(fileInfo.Name(), "node_modules") {
+				continue
+			}
+			relPath = filepath.Join(relPath, configName+jsonExt)
+		} else if fileInfo.IsDir() || filepath.Ext(fileInfo.Name()) != jsonExt {
+			continue
+		}
+
+		var resource map[string]interface{}
+		if err := readAndUnmarshalJSONInto(filepath.Join(appPath, relPath), &resource); err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+
+		name := nameField(resource)
+		paths[name] = append(paths[name], filepath.ToSlash(relPath))
+	}
+
+	return paths, nil
+}
+
+func duplicateNameProblems(kind string, paths map[string][]string) []string {
+	names := make([]string, 0, len(paths))
+	for name := range paths {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var problems []string
+	for _, name := range names {
+		if len(paths[name]) > 1 {
+			problems = append(problems, fmt.Sprintf("%s %q is defined more than once: %s", kind, name, strings.Join(paths[name], ", ")))
+		}
+	}
+	return problems
+}
+
+// duplicateJSONKeys returns the dotted path of every key which appears more than once within
+// the same object of the JSON document. These are otherwise silently dropped by json.Unmarshal
+func duplicateJSONKeys(data []byte) ([]string, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+
+	var duplicates []string
+	var walk func(prefix string) error
+	walk = func(prefix string) error {
+		token, err := dec.Token()
+		if err != nil {
+			return err
+		}
+
+		delim, ok := token.(json.Delim)
+		if !ok {
+			return nil
+		}
+
+		switch delim {
+		case '{':
+			seen := map[string]bool{}
+			for dec.More() {
+				keyToken, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				key := prefix + keyToken.(string)
+				if seen[key] {
+					duplicates = append(duplicates, key)
+				}
+				seen[key] = true
+				if err := walk(key + "."); err != nil {
+					return err
+				}
+			}
+		case '[':
+			for i := 0; dec.More(); i++ {
+				if err := walk(fmt.Sprintf("%s%d.", prefix, i)); err != nil {
+					return err
+				}
+			}
+		}
+
+		// consume the closing delimiter
+		_, err = dec.Token()
+		return err
+	}
+
+	if err := walk(""); err != nil && err != io.EOF {
+		return nil, err
+	}
+	return duplicates, nil
+}
@@ -0,0 +1,135 @@
+package utils
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// DiffIgnoreFileName is the file within an app directory listing the fields to ignore when diffing the app
+const DiffIgnoreFileName = ".diffignore"
+
+// ReadDiffIgnoreFile returns the field patterns listed in the app's DiffIgnoreFileName, one per line.
+// Blank lines and lines starting with "#" are skipped. An app without the file ignores nothing
+func ReadDiffIgnoreFile(appPath string) ([]string, error) {
+	file, err := os.Open(filepath.Join(appPath, DiffIgnoreFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+
+	return patterns, scanner.Err()
+}
+
+// IgnoreFields returns a copy of the app configuration, as loaded by UnmarshalFromDir, without the fields
+// matching any of the patterns. A pattern is a dot-separated path into the app configuration, where "*"
+// matches any single segment. Resources kept in a list are matched by the name shown in the diff, or else
+// by their index, and the fields of functions and services are also looked up in their config.
+// For example "functions.*.last_modified" or "services.mongodb-atlas.config.clusterName"
+func IgnoreFields(app map[string]interface{}, patterns []string) map[string]interface{} {
+	ignored := copyJSONValue(app)
+	for _, pattern := range patterns {
+		ignored = removeField(ignored, strings.Split(pattern, "."))
+	}
+
+	result, _ := ignored.(map[string]interface{})
+	return result
+}
+
+// removeField removes every field matching the path segments from the node, returning the updated node
+func removeField(node interface{}, segments []string) interface{} {
+	if len(segments) == 0 {
+		return node
+	}
+	segment, rest := segments[0], segments[1:]
+
+	switch n := node.(type) {
+	case map[string]interface{}:
+		for key, value := range n {
+			if segment != "*" && segment != key {
+				continue
+			}
+			if len(rest) == 0 {
+				delete(n, key)
+			} else {
+				n[key] = removeField(value, rest)
+			}
+		}
+		return n
+
+	case []interface{}:
+		kept := n[:0]
+		for i, element := range n {
+			if segment != "*" && segment != strconv.Itoa(i) && segment != listElementName(element) {
+				kept = append(kept, element)
+				continue
+			}
+			if len(rest) == 0 {
+				continue
+			}
+
+			element = removeField(element, rest)
+			if resource, ok := element.(map[string]interface{}); ok {
+				if config, ok := resource[configName]; ok {
+					resource[configName] = removeField(config, rest)
+				}
+			}
+			kept = append(kept, element)
+		}
+		return kept
+	}
+
+	return node
+}
+
+func listElementName(element interface{}) string {
+	resource, ok := element.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	if name := nameField(resource); name != "" {
+		return name
+	}
+	if name := configNameField(resource); name != "" {
+		return name
+	}
+	if _, ok := resource["on_type"]; ok {
+		return customResolverName(resource)
+	}
+	return ""
+}
+
+// copyJSONValue deeply copies a value made of JSON objects, arrays and scalars
+func copyJSONValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(v))
+		for key, elem := range v {
+			copied[key] = copyJSONValue(elem)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for i, elem := range v {
+			copied[i] = copyJSONValue(elem)
+		}
+		return copied
+	default:
+		return v
+	}
+}
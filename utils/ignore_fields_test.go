@@ -0,0 +1,79 @@
+package utils_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/10gen/realm-cli/utils"
+	u "github.com/10gen/realm-cli/utils/test"
+	gc "github.com/smartystreets/goconvey/convey"
+)
+
+func TestIgnoreFields(t *testing.T) {
+	loadApp := func(t *testing.T) map[string]interface{} {
+		app, err := utils.UnmarshalFromDir("../testdata/full_app")
+		u.So(t, err, gc.ShouldBeNil)
+		return app
+	}
+
+	functionConfig := func(app map[string]interface{}, i int) map[string]interface{} {
+		return app["functions"].([]interface{})[i].(map[string]interface{})["config"].(map[string]interface{})
+	}
+
+	t.Run("removes fields matching a wildcard from every resource", func(t *testing.T) {
+		app := loadApp(t)
+
+		ignored := utils.IgnoreFields(app, []string{"triggers.*.disabled"})
+		for _, trigger := range ignored["triggers"].([]interface{}) {
+			u.So(t, trigger, gc.ShouldNotContainKey, "disabled")
+		}
+		u.So(t, app["triggers"].([]interface{})[0], gc.ShouldContainKey, "disabled")
+	})
+
+	t.Run("matches resources by name and looks up fields in their config", func(t *testing.T) {
+		ignored := utils.IgnoreFields(loadApp(t), []string{"functions.function_a.private"})
+
+		u.So(t, functionConfig(ignored, 0), gc.ShouldNotContainKey, "private")
+		u.So(t, functionConfig(ignored, 1), gc.ShouldContainKey, "private")
+	})
+
+	t.Run("matches resources by index", func(t *testing.T) {
+		ignored := utils.IgnoreFields(loadApp(t), []string{"functions.1.config.private"})
+
+		u.So(t, functionConfig(ignored, 0), gc.ShouldContainKey, "private")
+		u.So(t, functionConfig(ignored, 1), gc.ShouldNotContainKey, "private")
+	})
+
+	t.Run("hides ignored changes from the diff", func(t *testing.T) {
+		local, remote := loadApp(t), loadApp(t)
+		functionConfig(remote, 0)["private"] = false
+		remote["name"] = "renamed-app"
+
+		patterns := []string{"functions.*.private", "name"}
+		diffs := utils.DiffApps(utils.IgnoreFields(local, patterns), utils.IgnoreFields(remote, patterns), false)
+		u.So(t, diffs.Diff(), gc.ShouldBeEmpty)
+	})
+}
+
+func TestReadDiffIgnoreFile(t *testing.T) {
+	appPath, err := ioutil.TempDir("", "realm-app-")
+	u.So(t, err, gc.ShouldBeNil)
+	defer os.RemoveAll(appPath)
+
+	t.Run("ignores nothing without the file", func(t *testing.T) {
+		patterns, err := utils.ReadDiffIgnoreFile(appPath)
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, patterns, gc.ShouldBeEmpty)
+	})
+
+	t.Run("reads one pattern per line skipping blank lines and comments", func(t *testing.T) {
+		contents := "# rewritten on every deploy\nfunctions.*.last_modified\n\n  services.*.config.clusterName  \n"
+		u.So(t, ioutil.WriteFile(filepath.Join(appPath, utils.DiffIgnoreFileName), []byte(contents), 0644), gc.ShouldBeNil)
+
+		patterns, err := utils.ReadDiffIgnoreFile(appPath)
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, patterns, gc.ShouldResemble, []string{"functions.*.last_modified", "services.*.config.clusterName"})
+	})
+}
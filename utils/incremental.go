@@ -0,0 +1,127 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// IncrementalManifestName is the file at the root of an incremental export which references its baseline
+const IncrementalManifestName = "incremental.json"
+
+// IncrementalManifest describes how an incremental export relates to the export it was taken against.
+// Restoring the app takes the files of the baseline, removes those listed as deleted and then
+// overlays the files of the incremental export
+type IncrementalManifest struct {
+	Baseline       string   `json:"baseline"`
+	BaselineSHA256 string   `json:"baseline_sha256"`
+	Changed        []string `json:"changed"`
+	Deleted        []string `json:"deleted"`
+}
+
+// WriteIncrementalZip writes a zip archive of the files in the export which are new or differ from
+// those in the baseline, along with an IncrementalManifest listing them and the files since deleted
+func WriteIncrementalZip(w io.Writer, export *zip.Reader, baseline *zip.Reader, baselineName, baselineSHA256 string) (*IncrementalManifest, error) {
+	baselineHashes, err := hashZipFiles(baseline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline: %s", err)
+	}
+
+	manifest := &IncrementalManifest{
+		Baseline:       baselineName,
+		BaselineSHA256: baselineSHA256,
+		Changed:        []string{},
+		Deleted:        []string{},
+	}
+
+	zw := zip.NewWriter(w)
+	exported := map[string]bool{}
+	for _, file := range export.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		exported[file.Name] = true
+
+		data, err := readZipFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		if hash, ok := baselineHashes[file.Name]; ok && hash == hashBytes(data) {
+			continue
+		}
+		manifest.Changed = append(manifest.Changed, file.Name)
+
+		header := file.FileHeader
+		f, err := zw.CreateHeader(&header)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := f.Write(data); err != nil {
+			return nil, err
+		}
+	}
+
+	for name := range baselineHashes {
+		if !exported[name] {
+			manifest.Deleted = append(manifest.Deleted, name)
+		}
+	}
+	sort.Strings(manifest.Deleted)
+
+	manifestData, err := json.MarshalIndent(manifest, "", "    ")
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := zw.Create(IncrementalManifestName)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := f.Write(manifestData); err != nil {
+		return nil, err
+	}
+
+	return manifest, zw.Close()
+}
+
+// hashZipFiles returns the content hash of every file in the archive, keyed by its name
+func hashZipFiles(r *zip.Reader) (map[string]string, error) {
+	hashes := map[string]string{}
+	for _, file := range r.File {
+		if file.FileInfo().IsDir() || file.Name == IncrementalManifestName {
+			continue
+		}
+
+		data, err := readZipFile(file)
+		if err != nil {
+			return nil, err
+		}
+		hashes[file.Name] = hashBytes(data)
+	}
+	return hashes, nil
+}
+
+func readZipFile(file *zip.File) ([]byte, error) {
+	rc, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract file %q: %s", file.Name, err)
+	}
+	defer rc.Close()
+
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, rc); err != nil {
+		return nil, fmt.Errorf("failed to extract file %q: %s", file.Name, err)
+	}
+	return buf.Bytes(), nil
+}
+
+func hashBytes(data []byte) string {
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// DiffJSON structurally compares two decoded JSON values and returns a line for each difference found,
+// prefixed with "-" for the expected value and "+" for the actual one. Object keys are compared regardless of their order
+func DiffJSON(expected, actual interface{}) []string {
+	var diffs []string
+	diffJSONValues("$", expected, actual, &diffs)
+	return diffs
+}
+
+func diffJSONValues(path string, expected, actual interface{}, diffs *[]string) {
+	switch e := expected.(type) {
+	case map[string]interface{}:
+		a, ok := actual.(map[string]interface{})
+		if !ok {
+			break
+		}
+
+		keys := make([]string, 0, len(e)+len(a))
+		for key := range e {
+			keys = append(keys, key)
+		}
+		for key := range a {
+			if _, ok := e[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			ev, hasExpected := e[key]
+			av, hasActual := a[key]
+			diffJSONElements(path+"."+key, ev, hasExpected, av, hasActual, diffs)
+		}
+		return
+
+	case []interface{}:
+		a, ok := actual.([]interface{})
+		if !ok {
+			break
+		}
+
+		for i := 0; i < len(e) || i < len(a); i++ {
+			var ev, av interface{}
+			if i < len(e) {
+				ev = e[i]
+			}
+			if i < len(a) {
+				av = a[i]
+			}
+			diffJSONElements(fmt.Sprintf("%s[%d]", path, i), ev, i < len(e), av, i < len(a), diffs)
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		*diffs = append(*diffs, fmt.Sprintf("- %s: %s", path, marshalJSONValue(expected)), fmt.Sprintf("+ %s: %s", path, marshalJSONValue(actual)))
+	}
+}
+
+// diffJSONElements compares an object field or list element which may be missing from either side
+func diffJSONElements(path string, expected interface{}, hasExpected bool, actual interface{}, hasActual bool, diffs *[]string) {
+	switch {
+	case !hasActual:
+		*diffs = append(*diffs, fmt.Sprintf("- %s: %s", path, marshalJSONValue(expected)))
+	case !hasExpected:
+		*diffs = append(*diffs, fmt.Sprintf("+ %s: %s", path, marshalJSONValue(actual)))
+	default:
+		diffJSONValues(path, expected, actual, diffs)
+	}
+}
+
+func marshalJSONValue(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
@@ -0,0 +1,57 @@
+package utils_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/10gen/realm-cli/utils"
+	u "github.com/10gen/realm-cli/utils/test"
+	gc "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDiffJSON(t *testing.T) {
+	for _, tc := range []struct {
+		Description   string
+		Expected      string
+		Actual        string
+		ExpectedDiffs []string
+	}{
+		{
+			Description: "it ignores the order of object keys",
+			Expected:    `{"a": 1, "b": {"c": [1, 2], "d": null}}`,
+			Actual:      `{"b": {"d": null, "c": [1, 2]}, "a": 1}`,
+		},
+		{
+			Description:   "it reports changed values by path",
+			Expected:      `{"status": "ok", "items": [{"qty": 1}, {"qty": 2}]}`,
+			Actual:        `{"status": "ok", "items": [{"qty": 1}, {"qty": 3}]}`,
+			ExpectedDiffs: []string{"- $.items[1].qty: 2", "+ $.items[1].qty: 3"},
+		},
+		{
+			Description:   "it reports missing and unexpected keys and elements",
+			Expected:      `{"a": 1, "list": [1, 2]}`,
+			Actual:        `{"b": 2, "list": [1]}`,
+			ExpectedDiffs: []string{"- $.a: 1", "+ $.b: 2", "- $.list[1]: 2"},
+		},
+		{
+			Description:   "it reports values of different types",
+			Expected:      `{"a": [1]}`,
+			Actual:        `{"a": {"0": 1}}`,
+			ExpectedDiffs: []string{"- $.a: [1]", `+ $.a: {"0":1}`},
+		},
+		{
+			Description:   "it compares top-level values",
+			Expected:      `"pong"`,
+			Actual:        `null`,
+			ExpectedDiffs: []string{`- $: "pong"`, "+ $: null"},
+		},
+	} {
+		t.Run(tc.Description, func(t *testing.T) {
+			var expected, actual interface{}
+			u.So(t, json.Unmarshal([]byte(tc.Expected), &expected), gc.ShouldBeNil)
+			u.So(t, json.Unmarshal([]byte(tc.Actual), &actual), gc.ShouldBeNil)
+
+			u.So(t, utils.DiffJSON(expected, actual), gc.ShouldResemble, tc.ExpectedDiffs)
+		})
+	}
+}
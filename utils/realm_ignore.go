@@ -0,0 +1,212 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// RealmIgnoreFileName is the file within an app directory listing the paths to leave out of the app,
+// in the gitignore syntax
+const RealmIgnoreFileName = ".realmignore"
+
+// IgnoreRules are the patterns of a RealmIgnoreFileName, matched against paths within the app directory.
+// A nil *IgnoreRules ignores nothing
+type IgnoreRules struct {
+	root  string
+	rules []ignoreRule
+}
+
+type ignoreRule struct {
+	pattern *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// ReadRealmIgnoreFile returns the rules listed in the RealmIgnoreFileName of the app at appPath.
+// An app without the file ignores nothing
+func ReadRealmIgnoreFile(appPath string) (*IgnoreRules, error) {
+	file, err := os.Open(filepath.Join(appPath, RealmIgnoreFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		patterns = append(patterns, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	rules, err := NewIgnoreRules(appPath, patterns...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %s", RealmIgnoreFileName, err)
+	}
+	return rules, nil
+}
+
+// NewIgnoreRules parses the gitignore patterns, matched against paths within root.
+// Blank lines and lines starting with "#" are skipped. A pattern starting with "!" re-includes
+// the paths matched by the patterns before it, and one ending with "/" only matches directories.
+// A pattern containing a "/" other than a trailing one is matched from root, and otherwise
+// against the name of a file or directory at any depth. "*" and "?" match within a single part
+// of a path, and "**" matches any number of directories. A pattern which cannot be parsed, e.g. with
+// an empty "[]" character class, fails naming its line
+func NewIgnoreRules(root string, patterns ...string) (*IgnoreRules, error) {
+	ir := &IgnoreRules{root: root}
+	for i, line := range patterns {
+		pattern := strings.TrimRight(line, " \t\r")
+		if pattern == "" || strings.HasPrefix(pattern, "#") {
+			continue
+		}
+
+		var rule ignoreRule
+		if strings.HasPrefix(pattern, "!") {
+			rule.negate = true
+			pattern = pattern[1:]
+		} else if strings.HasPrefix(pattern, `\`) {
+			// e.g. "\#file" or "\!file"
+			pattern = pattern[1:]
+		}
+		if strings.HasSuffix(pattern, "/") {
+			rule.dirOnly = true
+			pattern = strings.TrimRight(pattern, "/")
+		}
+		if pattern == "" {
+			continue
+		}
+
+		anchored := strings.Contains(pattern, "/")
+		pattern = strings.TrimPrefix(pattern, "/")
+
+		expr := globToRegexp(pattern)
+		if !anchored {
+			expr = "(.*/)?" + expr
+		}
+		compiled, err := regexp.Compile("^" + expr + "$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q on line %d", strings.TrimSpace(line), i+1)
+		}
+		rule.pattern = compiled
+
+		ir.rules = append(ir.rules, rule)
+	}
+	return ir, nil
+}
+
+// globToRegexp translates a gitignore glob into a regular expression matching the same paths
+func globToRegexp(glob string) string {
+	var expr strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case c == '*' && strings.HasPrefix(glob[i:], "**/"):
+			expr.WriteString("(.*/)?")
+			i += 2
+		case c == '*' && strings.HasPrefix(glob[i:], "**"):
+			expr.WriteString(".*")
+			i++
+		case c == '*':
+			expr.WriteString("[^/]*")
+		case c == '?':
+			expr.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end == -1 {
+				expr.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			expr.WriteString("[" + class + "]")
+			i += end + 1
+		case c == '\\' && i+1 < len(glob):
+			i++
+			expr.WriteString(regexp.QuoteMeta(string(glob[i])))
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return expr.String()
+}
+
+// Ignored reports whether the file or directory at path, which lies within the root of the rules,
+// is ignored. As with git, a path within an ignored directory is ignored even if
+// a later pattern re-includes it. The result only depends on the path and the order of the patterns
+func (ir *IgnoreRules) Ignored(path string, isDir bool) bool {
+	if ir == nil || len(ir.rules) == 0 {
+		return false
+	}
+
+	path, err := relativePath(ir.root, path)
+	if err != nil {
+		return false
+	}
+	path = filepath.ToSlash(path)
+	if path == "." || strings.HasPrefix(path, "../") {
+		return false
+	}
+
+	parts := strings.Split(path, "/")
+	for i := 1; i < len(parts); i++ {
+		if ir.matches(strings.Join(parts[:i], "/"), true) {
+			return true
+		}
+	}
+	return ir.matches(path, isDir)
+}
+
+// relativePath returns path relative to root, resolving both against the working directory
+// when only one of them is absolute
+func relativePath(root, path string) (string, error) {
+	if filepath.IsAbs(root) != filepath.IsAbs(path) {
+		var err error
+		if root, err = filepath.Abs(root); err != nil {
+			return "", err
+		}
+		if path, err = filepath.Abs(path); err != nil {
+			return "", err
+		}
+	}
+	return filepath.Rel(root, path)
+}
+
+// matches applies the rules in order to the path relative to root, the last matching one winning
+func (ir *IgnoreRules) matches(path string, isDir bool) bool {
+	var ignored bool
+	for _, rule := range ir.rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		if rule.pattern.MatchString(path) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+// filter leaves the ignored files and directories out of those read from dir
+func (ir *IgnoreRules) filter(dir string, fileInfos []os.FileInfo) []os.FileInfo {
+	if ir == nil || len(ir.rules) == 0 {
+		return fileInfos
+	}
+
+	filtered := make([]os.FileInfo, 0, len(fileInfos))
+	for _, fileInfo := range fileInfos {
+		if !ir.Ignored(filepath.Join(dir, fileInfo.Name()), fileInfo.IsDir()) {
+			filtered = append(filtered, fileInfo)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,105 @@
+package utils_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/10gen/realm-cli/utils"
+	u "github.com/10gen/realm-cli/utils/test"
+	gc "github.com/smartystreets/goconvey/convey"
+)
+
+func TestIgnoreRules(t *testing.T) {
+	rules, err := utils.NewIgnoreRules("app",
+		"# drafts are kept out of the app",
+		"",
+		"*.draft.json",
+		"!keep.draft.json",
+		"/functions/scratch*/",
+		"hosting/files/**/*.map",
+		"tmp/",
+		"!tmp/important.json",
+		`\#hash.json`,
+	)
+	u.So(t, err, gc.ShouldBeNil)
+
+	for _, tc := range []struct {
+		path     string
+		isDir    bool
+		expected bool
+	}{
+		{"values/value.json", false, false},
+		{"values/value.draft.json", false, true},
+		{"triggers/nested/trigger.draft.json", false, true},
+		{"values/keep.draft.json", false, false},
+		{"functions/scratch", true, true},
+		{"functions/scratch_b", true, true},
+		{"functions/scratch_b/source.js", false, true},
+		{"functions/scratch.js", false, false},
+		{"services/functions/scratch", true, false},
+		{"hosting/files/app.js.map", false, true},
+		{"hosting/files/js/vendor/app.js.map", false, true},
+		{"hosting/files/app.js", false, false},
+		{"tmp", true, true},
+		{"tmp", false, false},
+		{"services/tmp/rules/rule.json", false, true},
+		{"tmp/important.json", false, true},
+		{"#hash.json", false, true},
+	} {
+		t.Run(tc.path, func(t *testing.T) {
+			u.So(t, rules.Ignored(filepath.Join("app", tc.path), tc.isDir), gc.ShouldEqual, tc.expected)
+		})
+	}
+
+	t.Run("should ignore nothing without rules", func(t *testing.T) {
+		var rules *utils.IgnoreRules
+		u.So(t, rules.Ignored(filepath.Join("app", "values", "value.json"), false), gc.ShouldBeFalse)
+	})
+
+	for _, pattern := range []string{"[]", "values/[z-a].json"} {
+		t.Run("should fail on the invalid pattern "+pattern, func(t *testing.T) {
+			_, err := utils.NewIgnoreRules("app", "*.draft.json", "", pattern)
+			u.So(t, err, gc.ShouldNotBeNil)
+			u.So(t, err.Error(), gc.ShouldEqual, `invalid pattern "`+pattern+`" on line 3`)
+		})
+	}
+}
+
+func TestAppLoadWithRealmIgnore(t *testing.T) {
+	appPath, err := ioutil.TempDir("", "realm-cli-realmignore")
+	u.So(t, err, gc.ShouldBeNil)
+	defer os.RemoveAll(appPath)
+
+	for path, contents := range map[string]string{
+		"config.json":                             `{"name":"ignore-app"}`,
+		utils.RealmIgnoreFileName:                 "*.draft.json\nfunctions/wip_*/\n",
+		"values/value.json":                       `{"name":"value"}`,
+		"values/value.draft.json":                 `{"name":"value"}`,
+		"functions/function_a/config.json":        `{"name":"function_a"}`,
+		"functions/function_a/source.js":          "exports = () => 1",
+		"functions/wip_function_a/config.json":    `{"name":"function_a"}`,
+		"functions/wip_function_b/source.js":      "exports = () => 2",
+		"services/service_a/config.json":          `{"name":"service_a"}`,
+		"services/service_a/rules/rule.json":      `{"database":"db"}`,
+		"services/service_a/rules/old.draft.json": `{`,
+	} {
+		u.So(t, os.MkdirAll(filepath.Dir(filepath.Join(appPath, path)), os.ModePerm), gc.ShouldBeNil)
+		u.So(t, ioutil.WriteFile(filepath.Join(appPath, path), []byte(contents), 0600), gc.ShouldBeNil)
+	}
+
+	app, err := utils.UnmarshalFromDir(appPath)
+	u.So(t, err, gc.ShouldBeNil)
+
+	t.Run("should leave out the ignored files", func(t *testing.T) {
+		u.So(t, app["values"], gc.ShouldHaveLength, 1)
+		u.So(t, app["services"].([]interface{})[0].(map[string]interface{})["rules"], gc.ShouldHaveLength, 1)
+	})
+
+	t.Run("should leave out the ignored directories, even when they are incomplete", func(t *testing.T) {
+		functions := app["functions"].([]interface{})
+		u.So(t, functions, gc.ShouldHaveLength, 1)
+		u.So(t, functions[0].(map[string]interface{})["source"], gc.ShouldEqual, "exports = () => 1")
+	})
+}
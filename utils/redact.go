@@ -0,0 +1,188 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	// RedactedValue replaces every value removed from a redacted app configuration
+	RedactedValue = "<redacted>"
+	// RedactionManifestName is the file listing the fields removed from a redacted app configuration
+	RedactionManifestName = "redacted.json"
+)
+
+// RedactedField identifies a value removed from a redacted app configuration
+type RedactedField struct {
+	Path  string `json:"path"`
+	Field string `json:"field"`
+}
+
+// appRedaction describes the fields to redact from the files matching a glob within the app directory,
+// using dots to select nested fields. An empty list of fields redacts the whole file. Files in which the
+// boolean field unless is true are left as they are
+type appRedaction struct {
+	glob   string
+	fields []string
+	unless string
+}
+
+var appRedactions = []appRedaction{
+	{secretsName + jsonExt, nil, ""},
+	{appConfigName + jsonExt, []string{"security.allowed_request_origins"}, ""},
+	{filepath.Join(servicesName, "*", configName+jsonExt), []string{configName}, ""},
+	{filepath.Join(servicesName, "*", incomingWebhooksName, "*", configName+jsonExt), []string{"options.secret"}, ""},
+	{filepath.Join(environmentsName, "*"+jsonExt), []string{environmentValuesField}, ""},
+	// the value of a secret value is the name of the secret, which is kept to tell which secret it is
+	{filepath.Join(valuesName, "*"+jsonExt), []string{"value"}, "from_secret"},
+}
+
+// RedactAppConfig masks the secrets, values, environments, allowed request origins and service connection
+// details of the app in the given directory so that it can be shared safely, e.g. in a support ticket.
+// Every redacted value is replaced with RedactedValue and listed in a manifest written to
+// RedactionManifestName, which is also returned
+func RedactAppConfig(appPath string) ([]RedactedField, error) {
+	redacted := []RedactedField{}
+
+	for _, redaction := range appRedactions {
+		paths, err := filepath.Glob(filepath.Join(appPath, redaction.glob))
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(paths)
+
+		for _, path := range paths {
+			relPath, err := filepath.Rel(appPath, path)
+			if err != nil {
+				return nil, err
+			}
+
+			fields, err := redactFile(path, filepath.ToSlash(relPath), redaction.fields, redaction.unless)
+			if err != nil {
+				return nil, err
+			}
+			redacted = append(redacted, fields...)
+		}
+	}
+
+	manifest, err := marshalRedacted(redacted)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(appPath, RedactionManifestName), manifest, 0600); err != nil {
+		return nil, err
+	}
+
+	return redacted, nil
+}
+
+func redactFile(path, relPath string, fields []string, unless string) ([]RedactedField, error) {
+	var doc map[string]interface{}
+	if err := readAndUnmarshalJSONInto(path, &doc); err != nil {
+		return nil, err
+	}
+
+	if keep, _ := lookupField(doc, unless).(bool); unless != "" && keep {
+		return nil, nil
+	}
+
+	var redacted []RedactedField
+	record := func(field string) {
+		redacted = append(redacted, RedactedField{Path: relPath, Field: field})
+	}
+
+	if len(fields) == 0 {
+		for _, key := range sortedKeys(doc) {
+			doc[key] = redactValue(doc[key], key, record)
+		}
+	}
+	for _, field := range fields {
+		parent, key := doc, field
+		if i := strings.LastIndex(field, "."); i != -1 {
+			parent, _ = lookupField(doc, field[:i]).(map[string]interface{})
+			key = field[i+1:]
+		}
+		if value, ok := parent[key]; ok {
+			parent[key] = redactValue(value, field, record)
+		}
+	}
+
+	if len(redacted) == 0 {
+		return nil, nil
+	}
+
+	contents, err := marshalRedacted(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return redacted, ioutil.WriteFile(path, contents, info.Mode())
+}
+
+// redactValue replaces every value nested within value with RedactedValue, leaving the structure
+// of objects and arrays intact. Booleans and nulls are kept since they cannot leak any data
+func redactValue(value interface{}, field string, record func(field string)) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, key := range sortedKeys(v) {
+			v[key] = redactValue(v[key], field+"."+key, record)
+		}
+		return v
+	case []interface{}:
+		for i := range v {
+			v[i] = redactValue(v[i], fmt.Sprintf("%s[%d]", field, i), record)
+		}
+		return v
+	case bool, nil:
+		return v
+	default:
+		record(field)
+		return RedactedValue
+	}
+}
+
+// marshalRedacted indents the JSON like MarshalIndent, but leaves RedactedValue readable
+func marshalRedacted(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "    ")
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// lookupField returns the value of the dotted field within doc, or nil if it does not exist
+func lookupField(doc map[string]interface{}, field string) interface{} {
+	var value interface{} = doc
+	for _, key := range strings.Split(field, ".") {
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = obj[key]
+	}
+	return value
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
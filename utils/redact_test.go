@@ -0,0 +1,103 @@
+package utils_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/10gen/realm-cli/utils"
+	u "github.com/10gen/realm-cli/utils/test"
+	gc "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRedactAppConfig(t *testing.T) {
+	appPath, err := ioutil.TempDir("", "realm-app-")
+	u.So(t, err, gc.ShouldBeNil)
+	defer os.RemoveAll(appPath)
+
+	u.So(t, utils.WriteZipToDir(appPath, u.NewZipResponseBody("../testdata/full_app"), true), gc.ShouldBeNil)
+
+	for path, contents := range map[string]string{
+		"environments/production.json": `{"values": {"greeting": "hello", "db": {"password": "hunter2", "ports": [27017]}, "debug": false}}`,
+		"values/value_secret.json":     `{"name": "secret_value", "value": "service a auth_token", "from_secret": true}`,
+	} {
+		u.So(t, ioutil.WriteFile(filepath.Join(appPath, filepath.FromSlash(path)), []byte(contents), 0600), gc.ShouldBeNil)
+	}
+
+	redacted, err := utils.RedactAppConfig(appPath)
+	u.So(t, err, gc.ShouldBeNil)
+
+	for _, field := range []utils.RedactedField{
+		{Path: "environments/production.json", Field: "values.greeting"},
+		{Path: "environments/production.json", Field: "values.db.password"},
+		{Path: "environments/production.json", Field: "values.db.ports[0]"},
+		{Path: "values/value_a.json", Field: "value"},
+		{Path: "values/value_b.json", Field: "value"},
+		{Path: "secrets.json", Field: "services.service a.auth_token"},
+		{Path: "secrets.json", Field: "auth_providers.oauth2-google.clientSecret"},
+		{Path: "config.json", Field: "security.allowed_request_origins[0]"},
+		{Path: "config.json", Field: "security.allowed_request_origins[1]"},
+		{Path: "services/service_a/config.json", Field: "config.sid"},
+		{Path: "services/service_a/incoming_webhooks/webhook0/config.json", Field: "options.secret"},
+	} {
+		u.So(t, redacted, gc.ShouldContain, field)
+	}
+
+	t.Run("masks the redacted values in place", func(t *testing.T) {
+		app, err := utils.UnmarshalFromDir(appPath)
+		u.So(t, err, gc.ShouldBeNil)
+
+		u.So(t, app["name"], gc.ShouldEqual, "full-app")
+		u.So(t, app["security"], gc.ShouldResemble, map[string]interface{}{
+			"allowed_request_origins": []interface{}{utils.RedactedValue, utils.RedactedValue},
+		})
+
+		secrets := app["secrets"].(map[string]interface{})
+		serviceSecrets := secrets["services"].(map[string]interface{})
+		u.So(t, serviceSecrets["service b"], gc.ShouldResemble, map[string]interface{}{"auth_token": utils.RedactedValue})
+
+		for _, svc := range app["services"].([]interface{}) {
+			svcConfig := svc.(map[string]interface{})["config"].(map[string]interface{})
+			connectionConfig, _ := svcConfig["config"].(map[string]interface{})
+			for _, value := range connectionConfig {
+				u.So(t, value, gc.ShouldBeIn, utils.RedactedValue, true, false, nil)
+			}
+		}
+	})
+
+	t.Run("masks the values of the environments and values", func(t *testing.T) {
+		app, err := utils.UnmarshalFromDir(appPath)
+		u.So(t, err, gc.ShouldBeNil)
+
+		environments := app["environments"].(map[string]interface{})
+		u.So(t, environments["production.json"], gc.ShouldResemble, map[string]interface{}{
+			"values": map[string]interface{}{
+				"greeting": utils.RedactedValue,
+				"db":       map[string]interface{}{"password": utils.RedactedValue, "ports": []interface{}{utils.RedactedValue}},
+				"debug":    false,
+			},
+		})
+
+		values := map[string]interface{}{}
+		for _, value := range app["values"].([]interface{}) {
+			v := value.(map[string]interface{})
+			values[v["name"].(string)] = v["value"]
+		}
+		u.So(t, values, gc.ShouldResemble, map[string]interface{}{
+			"a":            utils.RedactedValue,
+			"b":            utils.RedactedValue,
+			"secret_value": "service a auth_token",
+		})
+	})
+
+	t.Run("writes a manifest of the redacted fields", func(t *testing.T) {
+		data, err := ioutil.ReadFile(filepath.Join(appPath, utils.RedactionManifestName))
+		u.So(t, err, gc.ShouldBeNil)
+
+		var manifest []utils.RedactedField
+		u.So(t, json.Unmarshal(data, &manifest), gc.ShouldBeNil)
+		u.So(t, manifest, gc.ShouldResemble, redacted)
+	})
+}
@@ -0,0 +1,248 @@
+package utils
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+)
+
+const schemaName = "schema"
+
+// CollectionSchema is the JSON schema a data source rule defines for a collection
+type CollectionSchema struct {
+	DataSource string
+	Database   string
+	Collection string
+	Schema     interface{}
+}
+
+// AppSchemas collects the JSON schemas defined by the rules of the app's data sources,
+// sorted by data source name, database and collection. Collections without a schema are left out
+func AppSchemas(app map[string]interface{}) []CollectionSchema {
+	var schemas []CollectionSchema
+
+	services, _ := app[servicesName].([]interface{})
+	for _, s := range services {
+		svc, ok := s.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		rules, _ := svc[rulesName].([]interface{})
+		for _, r := range rules {
+			rule, ok := r.(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			database, _ := rule["database"].(string)
+			collection, _ := rule["collection"].(string)
+			schema, hasSchema := rule[schemaName]
+			if database == "" || collection == "" || !hasSchema {
+				continue
+			}
+
+			schemas = append(schemas, CollectionSchema{
+				DataSource: configNameField(svc),
+				Database:   database,
+				Collection: collection,
+				Schema:     schema,
+			})
+		}
+	}
+
+	sort.Slice(schemas, func(i, j int) bool {
+		a, b := schemas[i], schemas[j]
+		if a.DataSource != b.DataSource {
+			return a.DataSource < b.DataSource
+		}
+		if a.Database != b.Database {
+			return a.Database < b.Database
+		}
+		return a.Collection < b.Collection
+	})
+
+	return schemas
+}
+
+// SchemasByDataSource nests the schemas by data source name, then database, then collection
+func SchemasByDataSource(schemas []CollectionSchema) map[string]map[string]map[string]interface{} {
+	nested := map[string]map[string]map[string]interface{}{}
+	for _, schema := range schemas {
+		if _, ok := nested[schema.DataSource]; !ok {
+			nested[schema.DataSource] = map[string]map[string]interface{}{}
+		}
+		if _, ok := nested[schema.DataSource][schema.Database]; !ok {
+			nested[schema.DataSource][schema.Database] = map[string]interface{}{}
+		}
+		nested[schema.DataSource][schema.Database][schema.Collection] = schema.Schema
+	}
+	return nested
+}
+
+// The types a schema may give with bsonType and type
+var (
+	schemaBSONTypes = keySet(
+		"object", "array", "objectId", "string", "bool", "int", "long", "double", "decimal", "number",
+		"date", "timestamp", "binData", "uuid", "mixed", "null", "regex", "javascript", "minKey", "maxKey",
+	)
+	schemaJSONTypes = keySet("object", "array", "string", "number", "integer", "boolean", "null")
+)
+
+// ValidateAppSchemas checks the JSON schemas of the collections in the rules of the data sources of the app
+// in the given directory without contacting Realm: that every rules file is a well-formed JSON object, and
+// that each schema uses the keywords it sets as JSON Schema expects, e.g. that "properties" holds a schema
+// for each property and "bsonType" names a known BSON type. Every problem found is returned, ordered by path
+func ValidateAppSchemas(appPath string) ([]AppProblem, error) {
+	realmIgnore, err := ReadRealmIgnoreFile(appPath)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &appValidator{appPath: appPath, realmIgnore: realmIgnore}
+
+	for _, dir := range v.directories(servicesName) {
+		for _, relPath := range v.jsonFiles(filepath.Join(dir, rulesName)) {
+			rule, ok := v.readResource(relPath, true)
+			if !ok {
+				continue
+			}
+			if schema, ok := rule[schemaName]; ok {
+				v.validateSchema(relPath, schemaName, schema)
+			}
+		}
+	}
+
+	sort.SliceStable(v.problems, func(i, j int) bool {
+		return v.problems[i].Path < v.problems[j].Path
+	})
+	return v.problems, nil
+}
+
+// validateSchema reports the keywords of the schema at field which do not hold what JSON Schema expects,
+// along with those of the schemas nested within it
+func (v *appValidator) validateSchema(relPath, field string, schema interface{}) {
+	fields, ok := schema.(map[string]interface{})
+	if !ok {
+		v.report(relPath, field, "must be an object")
+		return
+	}
+
+	keywords := make([]string, 0, len(fields))
+	for keyword := range fields {
+		keywords = append(keywords, keyword)
+	}
+	sort.Strings(keywords)
+
+	for _, keyword := range keywords {
+		value := fields[keyword]
+		keywordField := field + "." + keyword
+
+		switch keyword {
+		case "bsonType":
+			v.validateSchemaTypes(relPath, keywordField, value, schemaBSONTypes)
+		case "type":
+			v.validateSchemaTypes(relPath, keywordField, value, schemaJSONTypes)
+		case "title", "description", "pattern":
+			if _, ok := value.(string); !ok {
+				v.report(relPath, keywordField, "must be a string")
+			}
+		case "uniqueItems":
+			if _, ok := value.(bool); !ok {
+				v.report(relPath, keywordField, "must be a boolean")
+			}
+		case "minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum", "multipleOf":
+			if _, ok := value.(float64); !ok {
+				v.report(relPath, keywordField, "must be a number")
+			}
+		case "minLength", "maxLength", "minItems", "maxItems", "minProperties", "maxProperties":
+			if n, ok := value.(float64); !ok || n < 0 || n != float64(int64(n)) {
+				v.report(relPath, keywordField, "must be a non-negative integer")
+			}
+		case "required":
+			v.validateSchemaRequired(relPath, keywordField, value)
+		case "enum":
+			if values, ok := value.([]interface{}); !ok || len(values) == 0 {
+				v.report(relPath, keywordField, "must be a non-empty array")
+			}
+		case "properties", "patternProperties":
+			properties, ok := value.(map[string]interface{})
+			if !ok {
+				v.report(relPath, keywordField, "must be an object")
+				continue
+			}
+			for _, name := range sortedKeys(properties) {
+				v.validateSchema(relPath, keywordField+"."+name, properties[name])
+			}
+		case "items":
+			if items, ok := value.([]interface{}); ok {
+				for i, item := range items {
+					v.validateSchema(relPath, fmt.Sprintf("%s.%d", keywordField, i), item)
+				}
+				continue
+			}
+			v.validateSchema(relPath, keywordField, value)
+		case "additionalProperties", "additionalItems":
+			if _, ok := value.(bool); !ok {
+				v.validateSchema(relPath, keywordField, value)
+			}
+		case "not":
+			v.validateSchema(relPath, keywordField, value)
+		case "allOf", "anyOf", "oneOf":
+			schemas, ok := value.([]interface{})
+			if !ok || len(schemas) == 0 {
+				v.report(relPath, keywordField, "must be a non-empty array of schemas")
+				continue
+			}
+			for i, nested := range schemas {
+				v.validateSchema(relPath, fmt.Sprintf("%s.%d", keywordField, i), nested)
+			}
+		}
+	}
+}
+
+// validateSchemaTypes reports the value of bsonType or type unless it is one of the known types, or
+// a non-empty array of them
+func (v *appValidator) validateSchemaTypes(relPath, field string, value interface{}, known map[string]bool) {
+	types, ok := value.([]interface{})
+	if !ok {
+		types = []interface{}{value}
+	}
+	if len(types) == 0 {
+		v.report(relPath, field, "must name at least one type")
+		return
+	}
+
+	for _, t := range types {
+		name, ok := t.(string)
+		if !ok {
+			v.report(relPath, field, "must be a string or an array of strings")
+			return
+		}
+		if !known[name] {
+			v.report(relPath, field, "unknown type %q", name)
+		}
+	}
+}
+
+// validateSchemaRequired reports the value of required unless it is an array of distinct property names
+func (v *appValidator) validateSchemaRequired(relPath, field string, value interface{}) {
+	names, ok := value.([]interface{})
+	if !ok {
+		v.report(relPath, field, "must be an array of strings")
+		return
+	}
+
+	seen := map[string]bool{}
+	for _, n := range names {
+		name, ok := n.(string)
+		if !ok {
+			v.report(relPath, field, "must be an array of strings")
+			return
+		}
+		if seen[name] {
+			v.report(relPath, field, "lists %q more than once", name)
+		}
+		seen[name] = true
+	}
+}
@@ -0,0 +1,63 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/10gen/realm-cli/utils"
+	u "github.com/10gen/realm-cli/utils/test"
+	gc "github.com/smartystreets/goconvey/convey"
+)
+
+func TestAppSchemas(t *testing.T) {
+	app, err := utils.UnmarshalFromDir("../testdata/app_with_schemas")
+	u.So(t, err, gc.ShouldBeNil)
+
+	schemas := utils.AppSchemas(app)
+	u.So(t, schemas, gc.ShouldHaveLength, 2)
+
+	var collections []string
+	for _, schema := range schemas {
+		collections = append(collections, schema.DataSource+"/"+schema.Database+"."+schema.Collection)
+	}
+	u.So(t, collections, gc.ShouldResemble, []string{"analytics/reports.daily", "mongodb-atlas/store.items"})
+
+	t.Run("nests the schemas by data source, database and collection", func(t *testing.T) {
+		nested := utils.SchemasByDataSource(schemas)
+		u.So(t, nested, gc.ShouldHaveLength, 2)
+		u.So(t, nested["mongodb-atlas"]["store"], gc.ShouldContainKey, "items")
+		u.So(t, nested["mongodb-atlas"]["store"], gc.ShouldNotContainKey, "orders")
+		u.So(t, nested["analytics"]["reports"]["daily"].(map[string]interface{})["title"], gc.ShouldEqual, "DailyReport")
+	})
+
+	t.Run("finds no schemas in an app without any", func(t *testing.T) {
+		app, err := utils.UnmarshalFromDir("../testdata/simple_app")
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, utils.AppSchemas(app), gc.ShouldBeEmpty)
+	})
+}
+
+func TestValidateAppSchemas(t *testing.T) {
+	t.Run("finds no problems with valid schemas", func(t *testing.T) {
+		problems, err := utils.ValidateAppSchemas("../testdata/app_with_schemas")
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, problems, gc.ShouldBeEmpty)
+	})
+
+	t.Run("reports every problem with the schemas by file and field", func(t *testing.T) {
+		problems, err := utils.ValidateAppSchemas("../testdata/app_with_invalid_schemas")
+		u.So(t, err, gc.ShouldBeNil)
+
+		var messages []string
+		for _, problem := range problems {
+			messages = append(messages, problem.String())
+		}
+		u.So(t, messages, gc.ShouldHaveLength, 5)
+		u.So(t, messages[:4], gc.ShouldResemble, []string{
+			`services/mongodb-atlas/rules/store.items.json: schema.properties.name.bsonType: unknown type "str"`,
+			"services/mongodb-atlas/rules/store.items.json: schema.properties.price.minimum: must be a number",
+			"services/mongodb-atlas/rules/store.items.json: schema.properties.tags.items: must be an object",
+			`services/mongodb-atlas/rules/store.items.json: schema.required: lists "name" more than once`,
+		})
+		u.So(t, messages[4], gc.ShouldStartWith, "services/mongodb-atlas/rules/store.orders.json: invalid JSON:")
+	})
+}
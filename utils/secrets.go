@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+const secretConfigName = "secret_config"
+
+// ServiceSecretName returns the conventional name of the secret backing a service's config field
+func ServiceSecretName(serviceName, field string) string {
+	return fmt.Sprintf("__%s_%s", serviceName, field)
+}
+
+// FindServiceConfig returns the path to the config file of the named service within the app directory
+func FindServiceConfig(appPath, serviceName string) (string, error) {
+	servicesPath := filepath.Join(appPath, servicesName)
+
+	fileInfos, err := ioutil.ReadDir(servicesPath)
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+
+	for _, fileInfo := range fileInfos {
+		if !fileInfo.IsDir() {
+			continue
+		}
+
+		configPath := filepath.Join(servicesPath, fileInfo.Name(), configName+jsonExt)
+
+		var config map[string]interface{}
+		if err := readAndUnmarshalJSONInto(configPath, &config); err != nil {
+			continue
+		}
+
+		if name, _ := config["name"].(string); name == serviceName {
+			return configPath, nil
+		}
+	}
+
+	return "", fmt.Errorf("could not find service %q in %s", serviceName, servicesPath)
+}
+
+// SetServiceSecretConfig references the named secret from the given field of the
+// service's "secret_config" in the service config file at configPath
+func SetServiceSecretConfig(configPath, field, secretName string) error {
+	var config map[string]interface{}
+	if err := readAndUnmarshalJSONInto(configPath, &config); err != nil {
+		return err
+	}
+	if config == nil {
+		config = map[string]interface{}{}
+	}
+
+	secretConfig, ok := config[secretConfigName].(map[string]interface{})
+	if !ok {
+		secretConfig = map[string]interface{}{}
+	}
+	secretConfig[field] = secretName
+	config[secretConfigName] = secretConfig
+
+	contents, err := json.MarshalIndent(config, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(configPath, contents, 0600)
+}
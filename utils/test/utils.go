@@ -1,7 +1,9 @@
 package testutils
 
 import (
+	"archive/zip"
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
@@ -10,6 +12,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -127,6 +130,58 @@ func NewResponseBody(data io.Reader) *ResponseBody {
 	return &rb
 }
 
+// NewZipResponseBody returns a new ResponseBody containing a zip archive of the given directory,
+// similar to the one returned when exporting an app
+func NewZipResponseBody(dir string) *ResponseBody {
+	buf := new(bytes.Buffer)
+	w := zip.NewWriter(buf)
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || path == dir {
+			return err
+		}
+
+		relPath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		header, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(relPath)
+
+		if info.IsDir() {
+			header.Name += "/"
+			_, err = w.CreateHeader(header)
+			return err
+		}
+
+		f, err := w.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		_, err = f.Write(data)
+		return err
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	if err := w.Close(); err != nil {
+		panic(err)
+	}
+
+	return &ResponseBody{buf}
+}
+
 // NewEmptyStorage creates a new empty MemoryStrategy
 func NewEmptyStorage() *storage.Storage {
 	return storage.New(NewMemoryStrategy([]byte{}))
@@ -205,19 +260,29 @@ type MockRealmClient struct {
 	FetchAppByGroupIDAndClientAppIDFn func(groupID, clientAppID string) (*models.App, error)
 	FetchAppByClientAppIDFn           func(clientAppID string) (*models.App, error)
 	FetchAppsByGroupIDFn              func(groupID string) ([]*models.App, error)
-	ListAssetsForAppIDFn              func(groupID, appID string) ([]string, []hosting.AssetDescription, error)
+	FindAppsFn                        func(filter api.AppFilter) ([]*models.App, error)
+	GetAppFn                          func(groupID, appID string) (*models.App, error)
+	ListAuthProvidersFn               func(groupID, appID string) ([]models.AuthProvider, error)
+	ListServicesFn                    func(groupID, appID string) ([]models.Service, error)
+	ListAssetsForAppIDFn              func(groupID, appID string) ([]hosting.AssetMetadata, error)
+	ListAssetsForAppIDIfChangedFn     func(groupID, appID, etag string) ([]hosting.AssetMetadata, string, error)
 	UploadAssetFn                     func(groupID, appID, path, hash string, size int64, body io.Reader, attributes ...hosting.AssetAttribute) error
 	CopyAssetFn                       func(groupID, appID, fromPath, toPath string) error
 	MoveAssetFn                       func(groupID, appID, fromPath, toPath string) error
 	DeleteAssetFn                     func(groupID, appID, path string) error
+	DeleteAppFn                       func(groupID, appID string) error
+	RenameAppFn                       func(groupID, appID, name string) error
 	SetAssetAttributesFn              func(groupID, appID, path string, attributes ...hosting.AssetAttribute) error
 	ExportFn                          func(groupID, appID string, strategy api.ExportStrategy) (string, io.ReadCloser, error)
 	ExportDependencyFn                func(groupID, appID string) (string, io.ReadCloser, error)
 	ExportFnCalls                     [][]string
-	ImportFn                          func(groupID, appID string, appData []byte, strategy string) error
+	ImportFn                          func(ctx context.Context, groupID, appID string, appData []byte, strategy string) error
 	ImportFnCalls                     [][]string
 	DiffFn                            func(groupID, appID string, appData []byte, strategy string) ([]string, error)
-	InvalidateCacheFn                 func(groupID, appID, path string) error
+	InvalidateCacheFn                 func(groupID, appID, path string) (string, error)
+	GetCacheInvalidationFn            func(groupID, appID, jobID string) (*models.CacheInvalidation, error)
+	GetDependenciesFn                 func(groupID, appID string) (*models.Dependencies, error)
+	GetCollectionSchemaFn             func(groupID, appID, serviceID, database, collection string) (interface{}, error)
 	ListSecretsFn                     func(groupID, appID string) ([]secrets.Secret, error)
 	AddSecretFn                       func(groupID, appID string, secret secrets.Secret) error
 	UpdateSecretByIDFn                func(groupID, appID, secretID, secretValue string) error
@@ -225,6 +290,19 @@ type MockRealmClient struct {
 	RemoveSecretByIDFn                func(groupID, appID, secretID string) error
 	RemoveSecretByNameFn              func(groupID, appID, secretName string) error
 	UploadDependenciesFn              func(groupID, appID, fullPath string) error
+	GetDraftsFn                       func(groupID, appID string) ([]models.AppDraft, error)
+	GetUserProfileFn                  func() (*models.UserProfile, error)
+	DraftDiffFn                       func(groupID, appID, draftID string) (*models.DraftDiff, error)
+	DiscardDraftFn                    func(groupID, appID, draftID string) error
+	ExecuteFunctionFn                 func(ctx context.Context, groupID, appID, name string, args []interface{}) (*models.FunctionExecution, error)
+	ListFunctionsFn                   func(groupID, appID string) ([]models.Function, error)
+	ListDeploymentsFn                 func(groupID, appID string) ([]models.Deployment, error)
+	ListLogsFn                        func(groupID, appID string, options api.LogsOptions) ([]models.Log, error)
+	ListTemplatesFn                   func() ([]models.Template, error)
+	ListTriggersFn                    func(groupID, appID string) ([]models.Trigger, error)
+	CreateTriggerFn                   func(groupID, appID string, trigger models.Trigger) error
+	UpdateTriggerFn                   func(groupID, appID, triggerID string, trigger models.Trigger) error
+	SetTriggerDisabledFn              func(groupID, appID, triggerID string, disabled bool) error
 }
 
 var _ api.RealmClient = (*MockRealmClient)(nil)
@@ -263,26 +341,56 @@ func (msc *MockRealmClient) DeployDraft(groupID, appID, draftID string) (*models
 	return &models.Deployment{ID: "deployment-id"}, nil
 }
 
-// DiscardDraft does nothing
+// DiscardDraft discards a draft of the app
 func (msc *MockRealmClient) DiscardDraft(groupID, appID, draftID string) error {
+	if msc.DiscardDraftFn != nil {
+		return msc.DiscardDraftFn(groupID, appID, draftID)
+	}
+
 	return nil
 }
 
-// DraftDiff returns an empty DraftDiff
+// DraftDiff returns the diff of a draft, empty by default
 func (msc *MockRealmClient) DraftDiff(groupID, appID, draftID string) (*models.DraftDiff, error) {
+	if msc.DraftDiffFn != nil {
+		return msc.DraftDiffFn(groupID, appID, draftID)
+	}
+
 	return &models.DraftDiff{}, nil
 }
 
+// ExecuteFunction runs a function of the app, returning an undefined result by default
+func (msc *MockRealmClient) ExecuteFunction(ctx context.Context, groupID, appID, name string, args []interface{}) (*models.FunctionExecution, error) {
+	if msc.ExecuteFunctionFn != nil {
+		return msc.ExecuteFunctionFn(ctx, groupID, appID, name, args)
+	}
+
+	return &models.FunctionExecution{}, nil
+}
+
 // GetDeployment returns a mock Deployment
 func (msc *MockRealmClient) GetDeployment(groupID, appID, deploymentID string) (*models.Deployment, error) {
 	return &models.Deployment{ID: "deployment-id"}, nil
 }
 
-// GetDrafts returns an empty list of AppDrafts
+// GetDrafts returns the drafts of the app, none by default
 func (msc *MockRealmClient) GetDrafts(groupID, appID string) ([]models.AppDraft, error) {
+	if msc.GetDraftsFn != nil {
+		return msc.GetDraftsFn(groupID, appID)
+	}
+
 	return []models.AppDraft{}, nil
 }
 
+// GetUserProfile fetches the profile of the current user
+func (msc *MockRealmClient) GetUserProfile() (*models.UserProfile, error) {
+	if msc.GetUserProfileFn != nil {
+		return msc.GetUserProfileFn()
+	}
+
+	return &models.UserProfile{}, nil
+}
+
 // Diff will execute a dry-run of an import, returning a diff of proposed changes
 func (msc *MockRealmClient) Diff(groupID, appID string, appData []byte, strategy string) ([]string, error) {
 	if msc.DiffFn != nil {
@@ -311,10 +419,10 @@ func (msc *MockRealmClient) CreateEmptyApp(groupID, appName, locationName, deplo
 }
 
 // Import will push a local Realm app to the server
-func (msc *MockRealmClient) Import(groupID, appID string, appData []byte, strategy string) error {
+func (msc *MockRealmClient) Import(ctx context.Context, groupID, appID string, appData []byte, strategy string) error {
 	if msc.ImportFn != nil {
 		msc.ImportFnCalls = append(msc.ImportFnCalls, []string{groupID, appID})
-		return msc.ImportFn(groupID, appID, appData, strategy)
+		return msc.ImportFn(ctx, groupID, appID, appData, strategy)
 	}
 	return nil
 }
@@ -337,6 +445,34 @@ func (msc *MockRealmClient) FetchAppByClientAppID(clientAppID string) (*models.A
 	return nil, api.ErrAppNotFound{clientAppID}
 }
 
+// FindApps finds the apps matching the filter, falling back to the single app fetched by
+// FetchAppByGroupIDAndClientAppID or FetchAppByClientAppID when filtering by client app ID
+func (msc *MockRealmClient) FindApps(filter api.AppFilter) ([]*models.App, error) {
+	if msc.FindAppsFn != nil {
+		return msc.FindAppsFn(filter)
+	}
+
+	if filter.ClientAppID == "" {
+		return nil, errors.New("someone should test me")
+	}
+
+	var app *models.App
+	var err error
+	if filter.GroupID != "" {
+		app, err = msc.FetchAppByGroupIDAndClientAppID(filter.GroupID, filter.ClientAppID)
+	} else {
+		app, err = msc.FetchAppByClientAppID(filter.ClientAppID)
+	}
+
+	if _, ok := err.(api.ErrAppNotFound); ok {
+		return []*models.App{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []*models.App{app}, nil
+}
+
 // UploadAsset uploads an asset
 func (msc *MockRealmClient) UploadAsset(groupID, appID, path, hash string, size int64, body io.Reader, attributes ...hosting.AssetAttribute) error {
 	if msc.UploadAssetFn != nil {
@@ -364,6 +500,24 @@ func (msc *MockRealmClient) MoveAsset(groupID, appID, fromPath, toPath string) e
 	return nil
 }
 
+// DeleteApp deletes an app
+func (msc *MockRealmClient) DeleteApp(groupID, appID string) error {
+	if msc.DeleteAppFn != nil {
+		return msc.DeleteAppFn(groupID, appID)
+	}
+
+	return nil
+}
+
+// RenameApp renames an app
+func (msc *MockRealmClient) RenameApp(groupID, appID, name string) error {
+	if msc.RenameAppFn != nil {
+		return msc.RenameAppFn(groupID, appID, name)
+	}
+
+	return nil
+}
+
 // DeleteAsset deletes an asset
 func (msc *MockRealmClient) DeleteAsset(groupID, appID, path string) error {
 	if msc.DeleteAssetFn != nil {
@@ -384,6 +538,10 @@ func (msc *MockRealmClient) SetAssetAttributes(groupID, appID, path string, attr
 
 // ListAssetsForAppID fetches a Realm app given a clientAppID
 func (msc *MockRealmClient) ListAssetsForAppID(groupID, appID string) ([]hosting.AssetMetadata, error) {
+	if msc.ListAssetsForAppIDFn != nil {
+		return msc.ListAssetsForAppIDFn(groupID, appID)
+	}
+
 	assetMetadata := []hosting.AssetMetadata{
 		{
 			FilePath: "/bar/shouldRemainSame.txt",
@@ -437,12 +595,77 @@ func (msc *MockRealmClient) ListAssetsForAppID(groupID, appID string) ([]hosting
 }
 
 // InvalidateCache requests cache invalidation for the asset at the argued path
-func (msc *MockRealmClient) InvalidateCache(groupID, appID, path string) error {
+func (msc *MockRealmClient) InvalidateCache(groupID, appID, path string) (string, error) {
 	if msc.InvalidateCacheFn != nil {
 		return msc.InvalidateCacheFn(groupID, appID, path)
 	}
 
-	return nil
+	return "", nil
+}
+
+// GetCacheInvalidation returns a cache invalidation job
+func (msc *MockRealmClient) GetCacheInvalidation(groupID, appID, jobID string) (*models.CacheInvalidation, error) {
+	if msc.GetCacheInvalidationFn != nil {
+		return msc.GetCacheInvalidationFn(groupID, appID, jobID)
+	}
+
+	return &models.CacheInvalidation{ID: jobID, Status: models.CacheInvalidationStatusCompleted}, nil
+}
+
+// GetDependencies returns the dependencies deployed to an app
+func (msc *MockRealmClient) GetDependencies(groupID, appID string) (*models.Dependencies, error) {
+	if msc.GetDependenciesFn != nil {
+		return msc.GetDependenciesFn(groupID, appID)
+	}
+
+	return nil, api.ErrNoDependencies
+}
+
+// GetCollectionSchema fetches the schema of a collection
+func (msc *MockRealmClient) GetCollectionSchema(groupID, appID, serviceID, database, collection string) (interface{}, error) {
+	if msc.GetCollectionSchemaFn != nil {
+		return msc.GetCollectionSchemaFn(groupID, appID, serviceID, database, collection)
+	}
+
+	return nil, api.ErrNoCollectionSchema
+}
+
+// ListAssetsForAppIDIfChanged lists the hosting assets of an app unless they still have the etag given.
+// Without ListAssetsForAppIDIfChangedFn, the assets of ListAssetsForAppID are listed without an ETag
+func (msc *MockRealmClient) ListAssetsForAppIDIfChanged(groupID, appID, etag string) ([]hosting.AssetMetadata, string, error) {
+	if msc.ListAssetsForAppIDIfChangedFn != nil {
+		return msc.ListAssetsForAppIDIfChangedFn(groupID, appID, etag)
+	}
+
+	assetMetadata, err := msc.ListAssetsForAppID(groupID, appID)
+	return assetMetadata, "", err
+}
+
+// GetApp fetches an app
+func (msc *MockRealmClient) GetApp(groupID, appID string) (*models.App, error) {
+	if msc.GetAppFn != nil {
+		return msc.GetAppFn(groupID, appID)
+	}
+
+	return &models.App{GroupID: groupID, ID: appID}, nil
+}
+
+// ListAuthProviders lists the auth providers of an app
+func (msc *MockRealmClient) ListAuthProviders(groupID, appID string) ([]models.AuthProvider, error) {
+	if msc.ListAuthProvidersFn != nil {
+		return msc.ListAuthProvidersFn(groupID, appID)
+	}
+
+	return nil, nil
+}
+
+// ListServices lists the services of an app
+func (msc *MockRealmClient) ListServices(groupID, appID string) ([]models.Service, error) {
+	if msc.ListServicesFn != nil {
+		return msc.ListServicesFn(groupID, appID)
+	}
+
+	return nil, nil
 }
 
 // ListSecrets lists the secrets of an app
@@ -499,6 +722,78 @@ func (msc *MockRealmClient) RemoveSecretByName(groupID, appID, secretName string
 	return nil
 }
 
+// ListFunctions lists the functions of an app
+func (msc *MockRealmClient) ListFunctions(groupID, appID string) ([]models.Function, error) {
+	if msc.ListFunctionsFn != nil {
+		return msc.ListFunctionsFn(groupID, appID)
+	}
+
+	return nil, nil
+}
+
+// ListDeployments lists the deployments of an app
+func (msc *MockRealmClient) ListDeployments(groupID, appID string) ([]models.Deployment, error) {
+	if msc.ListDeploymentsFn != nil {
+		return msc.ListDeploymentsFn(groupID, appID)
+	}
+
+	return nil, nil
+}
+
+// ListLogs lists the logs of an app
+func (msc *MockRealmClient) ListLogs(groupID, appID string, options api.LogsOptions) ([]models.Log, error) {
+	if msc.ListLogsFn != nil {
+		return msc.ListLogsFn(groupID, appID, options)
+	}
+
+	return nil, nil
+}
+
+// ListTemplates lists the templates apps can be initialized from
+func (msc *MockRealmClient) ListTemplates() ([]models.Template, error) {
+	if msc.ListTemplatesFn != nil {
+		return msc.ListTemplatesFn()
+	}
+
+	return nil, nil
+}
+
+// ListTriggers lists the triggers of an app
+func (msc *MockRealmClient) ListTriggers(groupID, appID string) ([]models.Trigger, error) {
+	if msc.ListTriggersFn != nil {
+		return msc.ListTriggersFn(groupID, appID)
+	}
+
+	return nil, nil
+}
+
+// CreateTrigger creates a trigger for the app
+func (msc *MockRealmClient) CreateTrigger(groupID, appID string, trigger models.Trigger) error {
+	if msc.CreateTriggerFn != nil {
+		return msc.CreateTriggerFn(groupID, appID, trigger)
+	}
+
+	return nil
+}
+
+// UpdateTrigger updates a trigger of the app
+func (msc *MockRealmClient) UpdateTrigger(groupID, appID, triggerID string, trigger models.Trigger) error {
+	if msc.UpdateTriggerFn != nil {
+		return msc.UpdateTriggerFn(groupID, appID, triggerID, trigger)
+	}
+
+	return nil
+}
+
+// SetTriggerDisabled disables or enables a trigger of the app
+func (msc *MockRealmClient) SetTriggerDisabled(groupID, appID, triggerID string, disabled bool) error {
+	if msc.SetTriggerDisabledFn != nil {
+		return msc.SetTriggerDisabledFn(groupID, appID, triggerID, disabled)
+	}
+
+	return nil
+}
+
 func (msc *MockRealmClient) UploadDependencies(groupID, appID, fullPath string) error {
 	if msc.UploadDependenciesFn != nil {
 		return msc.UploadDependenciesFn(groupID, appID, fullPath)
@@ -0,0 +1,171 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/10gen/realm-cli/models"
+)
+
+const triggerEventProcessorsField = "event_processors"
+
+// LoadTriggers reads the definition of each trigger in the triggers directory of the app at appPath
+func LoadTriggers(appPath string) ([]models.Trigger, error) {
+	realmIgnore, err := ReadRealmIgnoreFile(appPath)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := unmarshalJSONFiles(filepath.Join(appPath, triggersName), true, realmIgnore)
+	if err != nil {
+		return nil, err
+	}
+
+	triggers := make([]models.Trigger, 0, len(files))
+	for _, file := range files {
+		trigger, ok := file.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("failed to load triggers: expected each file in %s to contain a JSON object", filepath.Join(appPath, triggersName))
+		}
+		triggers = append(triggers, trigger)
+	}
+	return triggers, nil
+}
+
+// WriteTriggers writes the definition of each trigger to a file named after it in the triggers directory
+// of the app at appPath, leaving out the IDs which only make sense within the deployed app
+func WriteTriggers(appPath string, triggers []models.Trigger) error {
+	triggersPath := filepath.Join(appPath, triggersName)
+	if err := os.MkdirAll(triggersPath, os.ModePerm); err != nil {
+		return err
+	}
+
+	for _, trigger := range triggers {
+		contents, err := json.MarshalIndent(localTrigger(trigger), "", "    ")
+		if err != nil {
+			return err
+		}
+
+		if err := ioutil.WriteFile(filepath.Join(triggersPath, trigger.Name()+jsonExt), contents, 0600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// localTrigger returns a copy of the trigger as it is defined in a local app
+func localTrigger(trigger models.Trigger) models.Trigger {
+	local := make(models.Trigger, len(trigger))
+	for field, value := range trigger {
+		if field == models.TriggerIDField || field == models.TriggerFunctionIDField {
+			continue
+		}
+		local[field] = value
+	}
+	return local
+}
+
+// ModifiedTrigger is a trigger whose local definition differs from the deployed one
+type ModifiedTrigger struct {
+	Local   models.Trigger
+	Remote  models.Trigger
+	Changes []string
+}
+
+// TriggerDiffs represents the differences between the local and deployed triggers of an app,
+// matched by name. Triggers which only exist in the deployed app are left alone and not reported
+type TriggerDiffs struct {
+	AddedLocally    []models.Trigger
+	ModifiedLocally []ModifiedTrigger
+}
+
+// DiffTriggers compares the local and deployed trigger definitions of an app
+func DiffTriggers(local, remote []models.Trigger) *TriggerDiffs {
+	remoteByName := make(map[string]models.Trigger, len(remote))
+	for _, trigger := range remote {
+		remoteByName[trigger.Name()] = trigger
+	}
+
+	diffs := &TriggerDiffs{}
+	for _, trigger := range local {
+		remoteTrigger, ok := remoteByName[trigger.Name()]
+		if !ok {
+			diffs.AddedLocally = append(diffs.AddedLocally, trigger)
+			continue
+		}
+
+		// DiffJSON only recognizes plain JSON objects, so the triggers are converted back to them
+		changes := DiffJSON(map[string]interface{}(localTrigger(remoteTrigger)), map[string]interface{}(localTrigger(trigger)))
+		if len(changes) > 0 {
+			diffs.ModifiedLocally = append(diffs.ModifiedLocally, ModifiedTrigger{trigger, remoteTrigger, changes})
+		}
+	}
+
+	sort.Slice(diffs.AddedLocally, func(i, j int) bool {
+		return diffs.AddedLocally[i].Name() < diffs.AddedLocally[j].Name()
+	})
+	sort.Slice(diffs.ModifiedLocally, func(i, j int) bool {
+		return diffs.ModifiedLocally[i].Local.Name() < diffs.ModifiedLocally[j].Local.Name()
+	})
+
+	return diffs
+}
+
+// Diff returns a list of strings representing the diff
+func (td *TriggerDiffs) Diff() []string {
+	var diff []string
+
+	if len(td.AddedLocally) > 0 {
+		diff = append(diff, "New Triggers:")
+	}
+	for _, added := range td.AddedLocally {
+		diff = append(diff, fmt.Sprintf("\t+ %s", added.Name()))
+	}
+
+	if len(td.ModifiedLocally) > 0 {
+		diff = append(diff, "Modified Triggers:")
+	}
+	for _, modified := range td.ModifiedLocally {
+		diff = append(diff, fmt.Sprintf("\t* %s", modified.Local.Name()))
+		for _, change := range modified.Changes {
+			diff = append(diff, "\t\t"+change)
+		}
+	}
+
+	return diff
+}
+
+// ValidateTriggerFunctions checks that every trigger calls a function which exists among the given functions,
+// unless it forwards its events with event processors instead
+func ValidateTriggerFunctions(triggers []models.Trigger, functions []models.Function) error {
+	functionNames := make(map[string]bool, len(functions))
+	for _, function := range functions {
+		functionNames[function.Name] = true
+	}
+
+	var problems []string
+	for _, trigger := range triggers {
+		functionName := trigger.FunctionName()
+		if functionName == "" {
+			if _, ok := trigger[triggerEventProcessorsField]; !ok {
+				problems = append(problems, fmt.Sprintf("\t%s does not reference a function", trigger.Name()))
+			}
+			continue
+		}
+
+		if !functionNames[functionName] {
+			problems = append(problems, fmt.Sprintf("\t%s references function %q, which does not exist in the app", trigger.Name(), functionName))
+		}
+	}
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("found triggers with invalid function references:\n%s", strings.Join(problems, "\n"))
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package utils_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/10gen/realm-cli/models"
+	"github.com/10gen/realm-cli/utils"
+	u "github.com/10gen/realm-cli/utils/test"
+	gc "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWriteAndLoadTriggers(t *testing.T) {
+	appPath, err := ioutil.TempDir("", "realm-triggers-")
+	u.So(t, err, gc.ShouldBeNil)
+	defer os.RemoveAll(appPath)
+
+	u.So(t, utils.WriteTriggers(appPath, []models.Trigger{
+		{"_id": "trigger-id", "name": "nightly", "type": "SCHEDULED", "function_id": "function-id", "function_name": "cleanup", "config": map[string]interface{}{"schedule": "0 3 * * *"}},
+	}), gc.ShouldBeNil)
+
+	_, err = os.Stat(filepath.Join(appPath, "triggers", "nightly.json"))
+	u.So(t, err, gc.ShouldBeNil)
+
+	triggers, err := utils.LoadTriggers(appPath)
+	u.So(t, err, gc.ShouldBeNil)
+	u.So(t, triggers, gc.ShouldResemble, []models.Trigger{
+		{"name": "nightly", "type": "SCHEDULED", "function_name": "cleanup", "config": map[string]interface{}{"schedule": "0 3 * * *"}},
+	})
+
+	t.Run("loads the triggers of an exported app", func(t *testing.T) {
+		triggers, err := utils.LoadTriggers("../testdata/full_app")
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, triggers, gc.ShouldHaveLength, 2)
+	})
+}
+
+func TestDiffTriggers(t *testing.T) {
+	remote := []models.Trigger{
+		{"_id": "1", "name": "nightly", "type": "SCHEDULED", "function_id": "f1", "function_name": "cleanup", "config": map[string]interface{}{"schedule": "0 3 * * *"}},
+		{"_id": "2", "name": "onLogin", "type": "AUTHENTICATION", "function_id": "f2", "function_name": "greet", "disabled": false},
+		{"_id": "3", "name": "remoteOnly", "type": "DATABASE", "function_id": "f1", "function_name": "cleanup"},
+	}
+	local := []models.Trigger{
+		{"name": "onLogin", "type": "AUTHENTICATION", "function_name": "greet", "disabled": false},
+		{"name": "nightly", "type": "SCHEDULED", "function_name": "cleanup", "config": map[string]interface{}{"schedule": "0 4 * * *"}},
+		{"name": "hourly", "type": "SCHEDULED", "function_name": "cleanup", "config": map[string]interface{}{"schedule": "0 * * * *"}},
+	}
+
+	diffs := utils.DiffTriggers(local, remote)
+	u.So(t, diffs.Diff(), gc.ShouldResemble, []string{
+		"New Triggers:",
+		"\t+ hourly",
+		"Modified Triggers:",
+		"\t* nightly",
+		`		- $.config.schedule: "0 3 * * *"`,
+		`		+ $.config.schedule: "0 4 * * *"`,
+	})
+	u.So(t, diffs.ModifiedLocally[0].Remote.ID(), gc.ShouldEqual, "1")
+
+	t.Run("finds no differences between identical triggers", func(t *testing.T) {
+		u.So(t, utils.DiffTriggers(local[:1], remote).Diff(), gc.ShouldBeEmpty)
+	})
+}
+
+func TestValidateTriggerFunctions(t *testing.T) {
+	functions := []models.Function{{ID: "f1", Name: "cleanup"}}
+
+	u.So(t, utils.ValidateTriggerFunctions([]models.Trigger{
+		{"name": "nightly", "function_name": "cleanup"},
+		{"name": "forwarder", "event_processors": map[string]interface{}{}},
+	}, functions), gc.ShouldBeNil)
+
+	err := utils.ValidateTriggerFunctions([]models.Trigger{
+		{"name": "nightly", "function_name": "cleanup"},
+		{"name": "onLogin", "function_name": "greet"},
+		{"name": "orphan"},
+	}, functions)
+	u.So(t, err, gc.ShouldNotBeNil)
+	u.So(t, err.Error(), gc.ShouldEqual, "found triggers with invalid function references:\n"+
+		"\tonLogin references function \"greet\", which does not exist in the app\n"+
+		"\torphan does not reference a function")
+}
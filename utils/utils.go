@@ -49,10 +49,18 @@ var (
 	HostingAttributes = fmt.Sprintf("%s/metadata.json", HostingRoot)
 	// HostingCacheFileName is the file that stores the cached hosting asset data
 	HostingCacheFileName = ".asset-cache.json"
+	// DependenciesCacheFileName is the file that stores the hash of the dependencies last uploaded for each app
+	DependenciesCacheFileName = ".dependencies-cache.json"
+	// HostingListingCacheFileName is the file that stores the last listing of the deployed hosting assets of each app, along with its ETag
+	HostingListingCacheFileName = ".asset-listing-cache.json"
 
 	errAppNotFound = errors.New("could not find realm app")
 )
 
+func errMissingResourceFile(dir, filename string) error {
+	return fmt.Errorf("failed to load %s: directory is missing its required %s file", dir, filename)
+}
+
 const maxDirectoryContainSearchDepth = 8
 
 // GetDirectoryContainingFile searches upwards for a valid Realm app directory
@@ -89,23 +97,71 @@ func WriteZipToDir(dest string, zipData io.Reader, overwrite bool) error {
 		return err
 	}
 
-	r, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	r, err := VerifyZip(b)
 	if err != nil {
 		return err
 	}
 
+	// every entry is checked before any is written, so that a crafted archive writes nothing
+	paths := make([]string, len(r.File))
+	for i, zipFile := range r.File {
+		if paths[i], err = ZipEntryPath(dest, zipFile.Name); err != nil {
+			return err
+		}
+	}
+
 	err = os.MkdirAll(dest, os.ModePerm)
 	if err != nil {
 		return fmt.Errorf("failed to create directory %q: %s", dest, err)
 	}
 
-	for _, zipFile := range r.File {
-		if processErr := processFile(filepath.Join(dest, zipFile.Name), zipFile); processErr != nil {
+	for i, zipFile := range r.File {
+		if processErr := processFile(paths[i], zipFile); processErr != nil {
 			return processErr
 		}
 	}
 
-	return err
+	return nil
+}
+
+// ZipEntryPath returns the path the entry of a zip archive is extracted to within dest, and fails if the entry
+// would be written outside of dest, as with a "../" or absolute name crafted to overwrite other files
+func ZipEntryPath(dest, name string) (string, error) {
+	root := filepath.Clean(dest)
+	entryPath := filepath.Join(root, name)
+	if filepath.IsAbs(name) || strings.HasPrefix(name, "/") || strings.HasPrefix(name, "\\") ||
+		(entryPath != root && !strings.HasPrefix(entryPath, root+string(filepath.Separator))) {
+		return "", fmt.Errorf("failed to extract file %q: it is outside of the directory %q", name, dest)
+	}
+	return entryPath, nil
+}
+
+// VerifyZip checks that the zip data is complete, reading every file in it so that a truncated or
+// corrupt archive is caught before any of it is written, and returns a reader for it
+func VerifyZip(data []byte) (*zip.Reader, error) {
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the zip archive, it may be incomplete: %s", err)
+	}
+
+	for _, zipFile := range r.File {
+		if zipFile.FileInfo().IsDir() {
+			continue
+		}
+
+		fileData, err := zipFile.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %q from the zip archive, it may be incomplete: %s", zipFile.Name, err)
+		}
+
+		_, err = io.Copy(ioutil.Discard, fileData)
+		fileData.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %q from the zip archive, it may be incomplete: %s", zipFile.Name, err)
+		}
+	}
+
+	return r, nil
 }
 
 // WriteFileToDir writes the data to dest and creates the necessary directories along the path
@@ -144,6 +200,11 @@ func processFile(path string, zipFile *zip.File) error {
 			return fmt.Errorf("failed to create sub-directory %q: %s", path, err)
 		}
 	} else {
+		// archives are not required to have entries for the directories of their files
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			return fmt.Errorf("failed to create sub-directory %q: %s", filepath.Dir(path), err)
+		}
+
 		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, zipFile.Mode())
 		if err != nil {
 			return fmt.Errorf("failed to create file %q: %s", path, err)
@@ -167,6 +228,11 @@ func UnmarshalFromDir(path string) (map[string]interface{}, error) {
 		return app, err
 	}
 
+	realmIgnore, err := ReadRealmIgnoreFile(path)
+	if err != nil {
+		return app, err
+	}
+
 	if _, err := os.Stat(filepath.Join(path, secretsName+jsonExt)); err == nil {
 		var secrets interface{}
 		if err := readAndUnmarshalJSONInto(filepath.Join(path, secretsName+jsonExt), &secrets); err != nil {
@@ -176,7 +242,7 @@ func UnmarshalFromDir(path string) (map[string]interface{}, error) {
 		app[secretsName] = secrets
 	}
 
-	values, err := unmarshalJSONFiles(filepath.Join(path, valuesName), true)
+	values, err := unmarshalJSONFiles(filepath.Join(path, valuesName), true, realmIgnore)
 	if err != nil {
 		return app, err
 	}
@@ -185,7 +251,7 @@ func UnmarshalFromDir(path string) (map[string]interface{}, error) {
 		app[valuesName] = values
 	}
 
-	authProviders, err := unmarshalJSONFiles(filepath.Join(path, authProvidersName), true)
+	authProviders, err := unmarshalJSONFiles(filepath.Join(path, authProvidersName), true, realmIgnore)
 	if err != nil {
 		return app, err
 	}
@@ -194,7 +260,7 @@ func UnmarshalFromDir(path string) (map[string]interface{}, error) {
 		app[authProvidersName] = authProviders
 	}
 
-	functions, err := unmarshalFunctionDirectories(filepath.Join(path, FunctionsRoot), true)
+	functions, err := unmarshalFunctionDirectories(filepath.Join(path, FunctionsRoot), true, realmIgnore)
 	if err != nil {
 		return app, err
 	}
@@ -203,7 +269,7 @@ func UnmarshalFromDir(path string) (map[string]interface{}, error) {
 		app[FunctionsRoot] = functions
 	}
 
-	triggers, err := unmarshalJSONFiles(filepath.Join(path, triggersName), true)
+	triggers, err := unmarshalJSONFiles(filepath.Join(path, triggersName), true, realmIgnore)
 	if err != nil {
 		return app, err
 	}
@@ -212,14 +278,14 @@ func UnmarshalFromDir(path string) (map[string]interface{}, error) {
 		app[triggersName] = triggers
 	}
 
-	graphQL, err := unmarshalGraphQLDirectories(filepath.Join(path, graphQLName), true)
+	graphQL, err := unmarshalGraphQLDirectories(filepath.Join(path, graphQLName), true, realmIgnore)
 	if err != nil {
 		return app, err
 	}
 
 	app[graphQLName] = graphQL
 
-	services, err := unmarshalServiceDirectories(filepath.Join(path, servicesName), true)
+	services, err := unmarshalServiceDirectories(filepath.Join(path, servicesName), true, realmIgnore)
 	if err != nil {
 		return app, err
 	}
@@ -229,21 +295,26 @@ func UnmarshalFromDir(path string) (map[string]interface{}, error) {
 	_, err = os.Stat(environmentsPath)
 	if err == nil {
 		// ignore environments folder if it's missing
-		environments, err := unmarshalJSONFilesWithFilenames(environmentsPath)
+		environments, err := unmarshalJSONFilesWithFilenames(environmentsPath, realmIgnore)
 		if err != nil {
 			return app, err
 		}
 		app[environmentsName] = environments
 	}
 
+	if err := checkDuplicateNames(path, realmIgnore); err != nil {
+		return app, err
+	}
+
 	return app, nil
 }
 
-func unmarshalJSONFiles(path string, ignoreDirErr bool) ([]interface{}, error) {
+func unmarshalJSONFiles(path string, ignoreDirErr bool, realmIgnore *IgnoreRules) ([]interface{}, error) {
 	fileInfos, err := ioutil.ReadDir(path)
 	if err != nil && !ignoreDirErr {
 		return []interface{}{}, err
 	}
+	fileInfos = realmIgnore.filter(path, fileInfos)
 	files := make([]interface{}, 0, len(fileInfos))
 
 	for _, fileInfo := range fileInfos {
@@ -263,11 +334,12 @@ func unmarshalJSONFiles(path string, ignoreDirErr bool) ([]interface{}, error) {
 	return files, nil
 }
 
-func unmarshalJSONFilesWithFilenames(path string) (map[string]interface{}, error) {
+func unmarshalJSONFilesWithFilenames(path string, realmIgnore *IgnoreRules) (map[string]interface{}, error) {
 	fileInfos, err := ioutil.ReadDir(path)
 	if err != nil {
 		return nil, err
 	}
+	fileInfos = realmIgnore.filter(path, fileInfos)
 	files := make(map[string]interface{}, len(fileInfos))
 
 	for _, fileInfo := range fileInfos {
@@ -287,11 +359,12 @@ func unmarshalJSONFilesWithFilenames(path string) (map[string]interface{}, error
 	return files, nil
 }
 
-func unmarshalFunctionDirectories(path string, ignoreDirErr bool) ([]interface{}, error) {
+func unmarshalFunctionDirectories(path string, ignoreDirErr bool, realmIgnore *IgnoreRules) ([]interface{}, error) {
 	fileInfos, err := ioutil.ReadDir(path)
 	if err != nil && !ignoreDirErr {
 		return []interface{}{}, err
 	}
+	fileInfos = realmIgnore.filter(path, fileInfos)
 	directories := []interface{}{}
 
 	err = iterDirectories(func(info os.FileInfo, path string) error {
@@ -299,12 +372,22 @@ func unmarshalFunctionDirectories(path string, ignoreDirErr bool) ([]interface{}
 		if strings.Contains(path, "node_modules") {
 			return nil
 		}
+		configPath, err := requireResourceFile(path, configName+jsonExt)
+		if err != nil {
+			return err
+		}
+
 		var config interface{}
-		if err := readAndUnmarshalJSONInto(filepath.Join(path, configName+jsonExt), &config); err != nil {
+		if err := readAndUnmarshalJSONInto(configPath, &config); err != nil {
+			return err
+		}
+
+		sourcePath, err := requireResourceFile(path, sourceName+jsExt)
+		if err != nil {
 			return err
 		}
 
-		sourceBytes, err := ioutil.ReadFile(filepath.Join(path, sourceName+jsExt))
+		sourceBytes, err := ioutil.ReadFile(sourcePath)
 		if err != nil {
 			return err
 		}
@@ -325,11 +408,12 @@ func unmarshalFunctionDirectories(path string, ignoreDirErr bool) ([]interface{}
 	return directories, nil
 }
 
-func unmarshalGraphQLDirectories(path string, ignoreDirErr bool) (map[string]interface{}, error) {
+func unmarshalGraphQLDirectories(path string, ignoreDirErr bool, realmIgnore *IgnoreRules) (map[string]interface{}, error) {
 	fileInfos, err := ioutil.ReadDir(path)
 	if err != nil && !ignoreDirErr {
 		return map[string]interface{}{}, err
 	}
+	fileInfos = realmIgnore.filter(path, fileInfos)
 
 	gqlServices := map[string]interface{}{}
 	gqlServices[customResolversName] = []interface{}{}
@@ -350,6 +434,7 @@ func unmarshalGraphQLDirectories(path string, ignoreDirErr bool) (map[string]int
 		if err != nil {
 			return err
 		}
+		gqlSvcFileInfos = realmIgnore.filter(path, gqlSvcFileInfos)
 
 		for _, fileInfo := range gqlSvcFileInfos {
 			var config map[string]interface{}
@@ -375,31 +460,37 @@ func unmarshalGraphQLDirectories(path string, ignoreDirErr bool) (map[string]int
 	return gqlServices, nil
 }
 
-func unmarshalServiceDirectories(path string, ignoreDirErr bool) ([]interface{}, error) {
+func unmarshalServiceDirectories(path string, ignoreDirErr bool, realmIgnore *IgnoreRules) ([]interface{}, error) {
 	fileInfos, err := ioutil.ReadDir(path)
 	if err != nil && !ignoreDirErr {
 		return []interface{}{}, err
 	}
+	fileInfos = realmIgnore.filter(path, fileInfos)
 	services := []interface{}{}
 
 	err = iterDirectories(func(info os.FileInfo, path string) error {
 		svc := map[string]interface{}{}
 
+		configPath, err := requireResourceFile(path, configName+jsonExt)
+		if err != nil {
+			return err
+		}
+
 		var config map[string]interface{}
-		if err := readAndUnmarshalJSONInto(filepath.Join(path, configName+jsonExt), &config); err != nil {
+		if err := readAndUnmarshalJSONInto(configPath, &config); err != nil {
 			return err
 		}
 
 		svc[configName] = config
 
-		incomingWebhooks, err := unmarshalFunctionDirectories(filepath.Join(path, incomingWebhooksName), true)
+		incomingWebhooks, err := unmarshalFunctionDirectories(filepath.Join(path, incomingWebhooksName), true, realmIgnore)
 		if err != nil {
 			return err
 		}
 
 		svc[incomingWebhooksName] = incomingWebhooks
 
-		rules, err := unmarshalJSONFiles(filepath.Join(path, rulesName), true)
+		rules, err := unmarshalJSONFiles(filepath.Join(path, rulesName), true, realmIgnore)
 		if err != nil {
 			return err
 		}
@@ -421,7 +512,7 @@ func unmarshalServiceDirectories(path string, ignoreDirErr bool) ([]interface{},
 func iterDirectories(iterFn func(info os.FileInfo, path string) error, path string, fileInfos []os.FileInfo) error {
 	for _, fileInfo := range fileInfos {
 		fileNamePath := filepath.Join(path, fileInfo.Name())
-		if info, err := os.Stat(fileNamePath); !info.IsDir() || err != nil {
+		if info, err := os.Stat(fileNamePath); err != nil || !info.IsDir() {
 			continue
 		}
 
@@ -433,6 +524,22 @@ func iterDirectories(iterFn func(info os.FileInfo, path string) error, path stri
 	return nil
 }
 
+// requireResourceFile returns the path to the named file within a resource directory,
+// or an error describing which file is missing
+func requireResourceFile(dir, filename string) (string, error) {
+	path := filepath.Join(dir, filename)
+
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) || (err == nil && info.IsDir()) {
+		return "", errMissingResourceFile(dir, filename)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
+
 func readAndUnmarshalJSONInto(path string, out interface{}) error {
 	data, err := ioutil.ReadFile(path)
 	if err != nil {
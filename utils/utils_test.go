@@ -1,6 +1,12 @@
 package utils_test
 
 import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/10gen/realm-cli/utils"
@@ -102,3 +108,133 @@ func TestAppLoadFromDirectory(t *testing.T) {
 		}
 	})
 }
+
+func TestAppLoadFromPartialDirectory(t *testing.T) {
+	t.Run("should fail when a function directory is missing its source", func(t *testing.T) {
+		_, err := utils.UnmarshalFromDir("../testdata/app_with_function_missing_source")
+		u.So(t, err, gc.ShouldNotBeNil)
+		u.So(t, err.Error(), gc.ShouldContainSubstring, filepath.Join("functions", "function_a"))
+		u.So(t, err.Error(), gc.ShouldContainSubstring, "missing its required source.js file")
+	})
+
+	t.Run("should fail when a service directory is missing its config", func(t *testing.T) {
+		_, err := utils.UnmarshalFromDir("../testdata/app_with_service_missing_config")
+		u.So(t, err, gc.ShouldNotBeNil)
+		u.So(t, err.Error(), gc.ShouldContainSubstring, filepath.Join("services", "service_a"))
+		u.So(t, err.Error(), gc.ShouldContainSubstring, "missing its required config.json file")
+	})
+}
+
+func TestAppLoadWithDuplicateNames(t *testing.T) {
+	_, err := utils.UnmarshalFromDir("../testdata/app_with_duplicate_names")
+	u.So(t, err, gc.ShouldNotBeNil)
+
+	for _, tc := range []struct {
+		description string
+		expected    string
+	}{
+		{
+			description: "should report functions with the same name",
+			expected:    `function "function_a" is defined more than once: functions/function_a/config.json, functions/function_a_copy/config.json`,
+		},
+		{
+			description: "should report services with the same name",
+			expected:    `service "service a" is defined more than once: services/service_a/config.json, services/service_a_copy/config.json`,
+		},
+		{
+			description: "should report secrets defined more than once",
+			expected:    `secret "services.service a.auth_token" is defined more than once in secrets.json`,
+		},
+	} {
+		t.Run(tc.description, func(t *testing.T) {
+			u.So(t, err.Error(), gc.ShouldContainSubstring, tc.expected)
+		})
+	}
+
+	t.Run("should not report resources with unique names", func(t *testing.T) {
+		u.So(t, err.Error(), gc.ShouldNotContainSubstring, "trigger_a")
+	})
+}
+
+func TestVerifyZip(t *testing.T) {
+	archive, err := ioutil.ReadAll(u.NewZipResponseBody("../testdata/full_app"))
+	u.So(t, err, gc.ShouldBeNil)
+
+	t.Run("should accept a complete archive", func(t *testing.T) {
+		r, err := utils.VerifyZip(archive)
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, r.File, gc.ShouldNotBeEmpty)
+	})
+
+	t.Run("should reject a truncated archive", func(t *testing.T) {
+		_, err := utils.VerifyZip(archive[:len(archive)/2])
+		u.So(t, err, gc.ShouldNotBeNil)
+		u.So(t, err.Error(), gc.ShouldStartWith, "failed to read the zip archive, it may be incomplete")
+	})
+
+	t.Run("should reject an archive with a corrupt file", func(t *testing.T) {
+		corrupt := append([]byte{}, archive...)
+		r, err := utils.VerifyZip(corrupt)
+		u.So(t, err, gc.ShouldBeNil)
+
+		// flip a byte of the first file with content, leaving the central directory intact
+		for _, file := range r.File {
+			if file.UncompressedSize64 == 0 {
+				continue
+			}
+			offset, err := file.DataOffset()
+			u.So(t, err, gc.ShouldBeNil)
+			corrupt[offset] ^= 0xff
+			break
+		}
+
+		_, err = utils.VerifyZip(corrupt)
+		u.So(t, err, gc.ShouldNotBeNil)
+		u.So(t, err.Error(), gc.ShouldContainSubstring, "from the zip archive, it may be incomplete")
+	})
+}
+
+func TestWriteZipToDir(t *testing.T) {
+	zipOf := func(names ...string) io.Reader {
+		var buf bytes.Buffer
+		w := zip.NewWriter(&buf)
+		for _, name := range names {
+			f, err := w.Create(name)
+			u.So(t, err, gc.ShouldBeNil)
+			_, err = f.Write([]byte("contents"))
+			u.So(t, err, gc.ShouldBeNil)
+		}
+		u.So(t, w.Close(), gc.ShouldBeNil)
+		return &buf
+	}
+
+	t.Run("should extract the files of the archive", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "realm-zip-")
+		u.So(t, err, gc.ShouldBeNil)
+		defer os.RemoveAll(dir)
+
+		u.So(t, utils.WriteZipToDir(dir, zipOf("config.json", "functions/sum/source.js"), true), gc.ShouldBeNil)
+
+		data, err := ioutil.ReadFile(filepath.Join(dir, "functions", "sum", "source.js"))
+		u.So(t, err, gc.ShouldBeNil)
+		u.So(t, string(data), gc.ShouldEqual, "contents")
+	})
+
+	t.Run("should not write anything for an entry outside of the directory", func(t *testing.T) {
+		parent, err := ioutil.TempDir("", "realm-zip-")
+		u.So(t, err, gc.ShouldBeNil)
+		defer os.RemoveAll(parent)
+		dir := filepath.Join(parent, "app")
+
+		for _, name := range []string{"../evil", "functions/../../evil", "/evil"} {
+			err := utils.WriteZipToDir(dir, zipOf("config.json", name), true)
+			u.So(t, err, gc.ShouldNotBeNil)
+			u.So(t, err.Error(), gc.ShouldContainSubstring, "is outside of the directory")
+
+			_, err = os.Stat(filepath.Join(parent, "evil"))
+			u.So(t, os.IsNotExist(err), gc.ShouldBeTrue)
+			_, err = os.Stat(filepath.Join(dir, "config.json"))
+			u.So(t, os.IsNotExist(err), gc.ShouldBeTrue)
+		}
+	})
+}